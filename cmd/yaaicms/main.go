@@ -13,18 +13,24 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"yaaicms/internal/ai"
+	"yaaicms/internal/audit"
 	"yaaicms/internal/cache"
+	"yaaicms/internal/cdn"
 	"yaaicms/internal/config"
 	"yaaicms/internal/database"
 	"yaaicms/internal/engine"
 	"yaaicms/internal/handlers"
 	"yaaicms/internal/imaging"
+	"yaaicms/internal/jobs"
+	"yaaicms/internal/prompts"
 	"yaaicms/internal/render"
 	"yaaicms/internal/router"
+	"yaaicms/internal/search"
 	"yaaicms/internal/session"
 	"yaaicms/internal/storage"
 	"yaaicms/internal/store"
@@ -99,6 +105,14 @@ func main() {
 	contentStore := store.NewContentStore(db)
 	templateStore := store.NewTemplateStore(db)
 	cacheLogStore := store.NewCacheLogStore(db)
+	aiUsageStore := store.NewAIUsageStore(db)
+	aiAuditStore := store.NewAIAuditStore(db)
+	aiBudgetStore := store.NewAIBudgetStore(db)
+	aiConversationStore := store.NewAIConversationStore(db)
+	aiJobStore := store.NewAIJobStore(db)
+	aiBulkStore := store.NewAIBulkStore(db)
+	translationStore := store.NewTranslationStore(db)
+	mediaDescriptionStore := store.NewMediaDescriptionStore(db)
 	mediaStore := store.NewMediaStore(db)
 	variantStore := store.NewVariantStore(db)
 	revisionStore := store.NewRevisionStore(db)
@@ -142,20 +156,67 @@ func main() {
 		eng.SetMediaDeps(mediaStore, variantStore, storageClient)
 	}
 
+	// Background workers (pub/sub listeners, etc.) run until this context
+	// is cancelled during shutdown.
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Share L1 template invalidations between replicas over Valkey pub/sub.
+	// Every replica applies events published by the others and flushes its
+	// whole L1 cache after any reconnect.
+	eng.SetInvalidationBus(cache.NewValkeyBus(valkeyClient, cache.DefaultInvalidationChannel))
+	if err := eng.ListenForInvalidations(bgCtx); err != nil {
+		slog.Error("failed to subscribe to template invalidations", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize the L2 page cache (full-page HTML in Valkey).
 	pageCache := cache.NewPageCache(valkeyClient, cache.DefaultPageTTL)
 
 	// Initialize the AI provider registry with all configured providers.
-	aiRegistry := ai.NewRegistry(cfg.AIProvider, map[string]ai.ProviderConfig{
-		"openai":  {APIKey: cfg.OpenAIKey, Model: cfg.OpenAIModel, ModelLight: cfg.OpenAIModelLight, ModelContent: cfg.OpenAIModelContent, ModelTemplate: cfg.OpenAIModelTemplate, ModelImage: cfg.OpenAIModelImage, BaseURL: cfg.OpenAIBaseURL},
-		"gemini":  {APIKey: cfg.GeminiKey, Model: cfg.GeminiModel, ModelLight: cfg.GeminiModelLight, ModelContent: cfg.GeminiModelContent, ModelTemplate: cfg.GeminiModelTemplate, ModelImage: cfg.GeminiModelImage, BaseURL: cfg.GeminiBaseURL},
+	aiConfigs := map[string]ai.ProviderConfig{
+		"openai":  {APIKey: cfg.OpenAIKey, Model: cfg.OpenAIModel, ModelLight: cfg.OpenAIModelLight, ModelContent: cfg.OpenAIModelContent, ModelTemplate: cfg.OpenAIModelTemplate, ModelImage: cfg.OpenAIModelImage, ModelEmbedding: cfg.OpenAIModelEmbedding, BaseURL: cfg.OpenAIBaseURL},
+		"gemini":  {APIKey: cfg.GeminiKey, Model: cfg.GeminiModel, ModelLight: cfg.GeminiModelLight, ModelContent: cfg.GeminiModelContent, ModelTemplate: cfg.GeminiModelTemplate, ModelImage: cfg.GeminiModelImage, ModelEmbedding: cfg.GeminiModelEmbedding, BaseURL: cfg.GeminiBaseURL},
 		"claude":  {APIKey: cfg.ClaudeKey, Model: cfg.ClaudeModel, ModelLight: cfg.ClaudeModelLight, ModelContent: cfg.ClaudeModelContent, ModelTemplate: cfg.ClaudeModelTemplate, BaseURL: cfg.ClaudeBaseURL},
-		"mistral": {APIKey: cfg.MistralKey, Model: cfg.MistralModel, ModelLight: cfg.MistralModelLight, ModelContent: cfg.MistralModelContent, ModelTemplate: cfg.MistralModelTemplate, BaseURL: cfg.MistralBaseURL},
-	})
+		"mistral": {APIKey: cfg.MistralKey, Model: cfg.MistralModel, ModelLight: cfg.MistralModelLight, ModelContent: cfg.MistralModelContent, ModelTemplate: cfg.MistralModelTemplate, ModelEmbedding: cfg.MistralModelEmbedding, BaseURL: cfg.MistralBaseURL},
+	}
+	for _, p := range cfg.AICustomProviders {
+		aiConfigs[p.Name] = ai.ProviderConfig{Kind: p.Kind, APIKey: p.APIKey, Model: p.Model, ModelLight: p.ModelLight, ModelContent: p.ModelContent, ModelTemplate: p.ModelTemplate, BaseURL: p.BaseURL}
+	}
+	aiRegistry := ai.NewRegistry(cfg.AIProvider, aiConfigs)
+
+	// AI_PROVIDER=fake answers every task with canned replies and uses a
+	// moderator that flags one word, so nothing reaches a real API.
+	if cfg.AIProvider == ai.FakeName {
+		fake := ai.NewFake(ai.FakeOptions{Latency: cfg.AIFakeLatency, FailEvery: cfg.AIFakeFailEvery, FlagWord: cfg.AIFakeFlagWord})
+		aiRegistry.Register(ai.FakeName, fake)
+		aiRegistry.SetModerator(fake.Moderator())
+		slog.Warn("using the fake AI provider: replies are canned and no API is called")
+	}
+
+	// Record token usage and estimated cost for every AI call.
+	priceOverrides, err := ai.ParsePrices(cfg.AIPrices)
+	if err != nil {
+		slog.Error("invalid AI_PRICES", "error", err)
+		os.Exit(1)
+	}
+	aiRegistry.SetUsageRecorder(handlers.NewUsageRecorder(aiUsageStore, ai.DefaultPrices().Merge(priceOverrides)))
+
+	// Fail over to other providers when the active one is unavailable.
+	aiRegistry.SetFallbacks(ai.TaskContent, strings.Split(cfg.AIFallbackContent, ","))
+	aiRegistry.SetFallbacks(ai.TaskTemplate, strings.Split(cfg.AIFallbackTemplate, ","))
+	aiRegistry.SetFallbacks(ai.TaskLight, strings.Split(cfg.AIFallbackLight, ","))
+
+	// Embed content for search and related posts.
+	if err := aiRegistry.SetEmbeddingProvider(cfg.AIEmbeddingProvider); err != nil {
+		slog.Error("invalid AI_EMBEDDING_PROVIDER", "error", err)
+		os.Exit(1)
+	}
 
 	slog.Info("ai providers initialized",
 		"active", aiRegistry.ActiveName(),
 		"available", aiRegistry.Available(),
+		"embeddings", aiRegistry.EmbeddingModel(),
 	)
 
 	// Build AI provider config for the admin settings page.
@@ -168,18 +229,87 @@ func main() {
 			{Name: "mistral", Label: "Mistral", HasKey: cfg.MistralKey != "", Active: cfg.AIProvider == "mistral", Model: cfg.MistralModel, KeyEnvVar: "MISTRAL_API_KEY"},
 		},
 	}
+	for _, p := range cfg.AICustomProviders {
+		aiCfg.Providers = append(aiCfg.Providers, handlers.AIProviderInfo{
+			Name: p.Name, Label: p.Label, HasKey: true, Active: cfg.AIProvider == p.Name, Model: p.Model, BaseURL: p.BaseURL,
+		})
+	}
+	if cfg.AIProvider == ai.FakeName {
+		aiCfg.Providers = append(aiCfg.Providers, handlers.AIProviderInfo{
+			Name: ai.FakeName, Label: "Fake (development)", HasKey: true, Active: true, Model: ai.FakeModel,
+		})
+	}
 
 	// Create handler groups with their dependencies.
 	adminHandlers := handlers.NewAdmin(renderer, sessionStore, contentStore, userStore, templateStore, mediaStore, variantStore, revisionStore, templateRevisionStore, themeStore, siteSettingStore, categoryStore, storageClient, eng, pageCache, cacheLogStore, aiRegistry, aiCfg)
+	adminHandlers.SetAIUsage(aiUsageStore)
+	adminHandlers.SetAIBudgets(aiBudgetStore)
+	adminHandlers.SetAIConversations(aiConversationStore)
+	adminHandlers.SetAIBulk(aiBulkStore)
+	adminHandlers.SetTranslations(translationStore)
+	adminHandlers.SetMediaDescriptions(mediaDescriptionStore)
 	authHandlers := handlers.NewAuth(renderer, sessionStore, userStore)
 	publicHandlers := handlers.NewPublic(eng, contentStore, mediaStore, variantStore, storageClient, pageCache)
+	publicHandlers.SetTranslations(translationStore, siteSettingStore, cfg.SiteURL)
+
+	// Admin-editable AI system prompts. Tasks without a saved prompt get
+	// their built-in default as version 1.
+	promptLibrary := prompts.NewLibrary(store.NewPromptStore(db))
+	if err := promptLibrary.Seed(); err != nil {
+		slog.Error("failed to seed prompts", "error", err)
+		os.Exit(1)
+	}
+	adminHandlers.SetPrompts(promptLibrary)
+	adminHandlers.SetTemplateRepairs(cfg.AITemplateRepairs)
+
+	// Log who asked the AI what, redacted as configured.
+	auditPolicy, err := audit.NewPolicy(cfg.AIAuditPrompts, cfg.AIAuditRedact, cfg.AIAuditRedactPattern)
+	if err != nil {
+		slog.Error("invalid AI audit settings", "error", err)
+		os.Exit(1)
+	}
+	adminHandlers.SetAIAudit(aiAuditStore, auditPolicy, cfg.AIAuditRetention)
+
+	// Site search and related posts, backed by content embeddings.
+	searchIndex := search.New(aiRegistry, store.NewEmbeddingStore(db), contentStore, translationStore)
+	adminHandlers.SetSearchIndex(searchIndex)
+	publicHandlers.SetSearch(searchIndex)
+
+	// Pre-render public pages into the L2 cache after template changes
+	// (and on a schedule if CACHE_WARM_INTERVAL is set).
+	warmer := handlers.NewCacheWarmer(publicHandlers, cfg.CacheWarmConcurrency, cfg.CacheWarmInterval)
+	warmer.Start(bgCtx)
+	adminHandlers.SetCacheWarmer(warmer)
+
+	// Forward page cache invalidations to the CDN through a batching,
+	// retrying background queue.
+	var purgeQueue *cdn.Queue
+	if cfg.CDNPurgeURL != "" {
+		purgeQueue = cdn.NewQueue(cdn.NewWebhookPurger(cfg.CDNPurgeURL, cfg.CDNPurgeToken, cfg.SiteURL), cdn.DefaultBatchWindow)
+		purgeQueue.Start(bgCtx)
+		adminHandlers.SetPurger(purgeQueue)
+		slog.Info("cdn purging enabled", "webhook", cfg.CDNPurgeURL)
+	}
+
+	// Run AI work that outlives a request (revision metadata, image
+	// generation) from the persistent job queue. Jobs pending at shutdown
+	// are picked up again on the next start.
+	jobQueue := jobs.NewQueue(aiJobStore, cfg.AIJobWorkers)
+	adminHandlers.SetJobQueue(jobQueue)
+	jobQueue.Start(bgCtx)
+	adminHandlers.BackfillEmbeddings()
+
+	// Prune old cache invalidation log and AI audit rows once a day.
+	go pruneCacheLog(bgCtx, cacheLogStore, cfg.CacheLogRetention)
+	go pruneAIAudit(bgCtx, aiAuditStore, cfg.AIAuditRetention)
 
 	// Set up the Chi router with all middleware and routes.
 	r := router.New(sessionStore, adminHandlers, authHandlers, publicHandlers, secureCookies)
 
 	// Create the HTTP server with sensible timeouts.
 	// WriteTimeout must accommodate AI endpoints that wait on LLM responses
-	// (typically 10-30s, up to 60s for complex prompts).
+	// (typically 10-30s, up to 60s for complex prompts). Streamed AI
+	// responses extend their own deadline (see handlers/sse.go).
 	srv := &http.Server{
 		Addr:         cfg.Addr(),
 		Handler:      r,
@@ -212,5 +342,63 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Stop background workers once no more requests are in flight and
+	// let an in-progress warm run finish its in-flight renders.
+	stopBackground()
+	warmer.Wait()
+	if purgeQueue != nil {
+		purgeQueue.Wait()
+	}
+
+	// Give running AI jobs up to 30 seconds to finish; jobs cut short are
+	// requeued for the next start.
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancelDrain()
+	jobQueue.Drain(drainCtx)
+
 	slog.Info("server stopped gracefully")
 }
+
+// pruneCacheLog deletes cache invalidation log rows older than retention,
+// once at startup and then daily, until ctx is cancelled.
+func pruneCacheLog(ctx context.Context, cacheLog *store.CacheLogStore, retention time.Duration) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		if n, err := cacheLog.Prune(ctx, retention); err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("cache log prune failed", "error", err)
+			}
+		} else if n > 0 {
+			slog.Info("cache log pruned", "rows", n, "retention", retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pruneAIAudit deletes AI audit log rows older than retention, once at
+// startup and then daily, until ctx is cancelled.
+func pruneAIAudit(ctx context.Context, auditLog *store.AIAuditStore, retention time.Duration) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		if n, err := auditLog.Prune(ctx, retention); err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("ai audit prune failed", "error", err)
+			}
+		} else if n > 0 {
+			slog.Info("ai audit log pruned", "rows", n, "retention", retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
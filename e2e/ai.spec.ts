@@ -0,0 +1,37 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+import { test, expect } from '@playwright/test';
+
+// These tests need the server started with AI_PROVIDER=fake, whose
+// replies are the same on every run. Run them with the same variable set:
+//   AI_PROVIDER=fake npx playwright test e2e/ai.spec.ts
+test.describe('AI assistant (fake provider)', () => {
+  test.skip(process.env.AI_PROVIDER !== 'fake', 'needs AI_PROVIDER=fake');
+
+  test('settings page shows the fake provider', async ({ page }) => {
+    await page.goto('/admin/settings');
+    await expect(page.locator('main').getByText('Fake (development)', { exact: true })).toBeVisible();
+  });
+
+  test('suggests titles', async ({ page }) => {
+    await page.goto('/admin/posts/new');
+    await page.fill('#title', 'Balcony tomatoes');
+    await page.fill('#body', 'Grow them in big pots on the sunniest side.');
+
+    await page.locator('button:has-text("Generate Title Ideas")').click();
+
+    const titles = page.locator('#ai-titles-result button');
+    await expect(titles).toHaveCount(5, { timeout: 10000 });
+    await expect(titles.first()).toHaveText("Balcony tomatoes: A Beginner's Guide");
+  });
+
+  test('blocks a prompt the moderator flags', async ({ page }) => {
+    await page.goto('/admin/posts/new');
+    await page.fill('#title', 'Something FAKE_FLAGGED');
+
+    await page.locator('button:has-text("Generate Title Ideas")').click();
+
+    await expect(page.locator('#ai-titles-result')).toContainText('flagged', { timeout: 10000 });
+  });
+});
@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// chat.go defines the message list used for multi-turn conversations and
+// its mapping to each vendor's request format.
+package ai
+
+import "strings"
+
+// Role is the author of a message in a conversation.
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+// Message is one turn of a conversation.
+type Message struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+}
+
+// Prompt returns the two-message conversation used by single-turn calls.
+func Prompt(systemPrompt, userPrompt string) []Message {
+	return []Message{
+		{Role: RoleSystem, Content: systemPrompt},
+		{Role: RoleUser, Content: userPrompt},
+	}
+}
+
+// splitSystem separates the system messages, joined by a blank line, from
+// the rest of the conversation. Claude and Gemini take the system prompt
+// outside the message list.
+func splitSystem(messages []Message) (string, []Message) {
+	var system []string
+	var turns []Message
+	for _, m := range messages {
+		if m.Role == RoleSystem {
+			system = append(system, m.Content)
+			continue
+		}
+		turns = append(turns, m)
+	}
+	return strings.Join(system, "\n\n"), turns
+}
+
+// openAIMessages maps a conversation to the chat completions format, which
+// uses the same role names. Also used by Mistral, compatible servers and
+// Ollama.
+func openAIMessages(messages []Message) []openAIMessage {
+	out := make([]openAIMessage, len(messages))
+	for i, m := range messages {
+		out[i] = openAIMessage{Role: string(m.Role), Content: m.Content}
+	}
+	return out
+}
+
+// claudeMessages maps a conversation to the Messages API format; the
+// system prompt goes in the request's top-level system field.
+func claudeMessages(messages []Message) (string, []claudeMessage) {
+	system, turns := splitSystem(messages)
+	out := make([]claudeMessage, len(turns))
+	for i, m := range turns {
+		out[i] = claudeMessage{Role: string(m.Role), Content: m.Content}
+	}
+	return system, out
+}
+
+// geminiContents maps a conversation to generateContent's format: the
+// system prompt becomes systemInstruction and assistant turns use the
+// "model" role.
+func geminiContents(messages []Message) (*geminiContent, []geminiContent) {
+	system, turns := splitSystem(messages)
+	out := make([]geminiContent, len(turns))
+	for i, m := range turns {
+		role := "user"
+		if m.Role == RoleAssistant {
+			role = "model"
+		}
+		out[i] = geminiContent{Role: role, Parts: []geminiPart{{Text: m.Content}}}
+	}
+	return &geminiContent{Parts: []geminiPart{{Text: system}}}, out
+}
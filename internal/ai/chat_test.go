@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// conversation is a two-turn thread used by the Chat tests.
+var conversation = []Message{
+	{Role: RoleSystem, Content: "You build templates."},
+	{Role: RoleUser, Content: "A dark header"},
+	{Role: RoleAssistant, Content: "<header>dark</header>"},
+	{Role: RoleUser, Content: "Add a logo"},
+}
+
+// newCaptureServer answers every request with body and decodes the
+// request body into v.
+func newCaptureServer(t *testing.T, body []byte, v any) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+}
+
+func TestSplitSystem(t *testing.T) {
+	system, turns := splitSystem([]Message{
+		{Role: RoleSystem, Content: "one"},
+		{Role: RoleUser, Content: "hi"},
+		{Role: RoleSystem, Content: "two"},
+	})
+	if system != "one\n\ntwo" {
+		t.Errorf("system: got %q", system)
+	}
+	if len(turns) != 1 || turns[0].Role != RoleUser {
+		t.Errorf("turns: got %+v", turns)
+	}
+}
+
+func TestOpenAIChat_SendsRoles(t *testing.T) {
+	var req openAIRequest
+	srv := newCaptureServer(t, openAISuccessBody("<header>logo</header>"), &req)
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+	res, err := p.Chat(context.Background(), "", conversation)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if res.Text != "<header>logo</header>" {
+		t.Errorf("text: got %q", res.Text)
+	}
+
+	want := []string{"system", "user", "assistant", "user"}
+	if len(req.Messages) != len(want) {
+		t.Fatalf("messages: got %+v", req.Messages)
+	}
+	for i, role := range want {
+		if req.Messages[i].Role != role || req.Messages[i].Content != conversation[i].Content {
+			t.Errorf("message %d: got %+v", i, req.Messages[i])
+		}
+	}
+}
+
+func TestClaudeChat_SystemIsTopLevel(t *testing.T) {
+	var req claudeRequest
+	srv := newCaptureServer(t, claudeSuccessBody("ok"), &req)
+	defer srv.Close()
+
+	p := newClaude(ProviderConfig{APIKey: "k", Model: "claude-sonnet", BaseURL: srv.URL})
+	if _, err := p.Chat(context.Background(), "", conversation); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if req.System != "You build templates." {
+		t.Errorf("system: got %q", req.System)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("messages: got %+v", req.Messages)
+	}
+	if req.Messages[0].Role != "user" || req.Messages[1].Role != "assistant" || req.Messages[2].Content != "Add a logo" {
+		t.Errorf("messages: got %+v", req.Messages)
+	}
+}
+
+func TestGeminiChat_AssistantIsModel(t *testing.T) {
+	var req geminiRequest
+	srv := newCaptureServer(t, geminiSuccessBody("ok"), &req)
+	defer srv.Close()
+
+	p := newGemini(ProviderConfig{APIKey: "k", Model: "gemini-2.5-pro", BaseURL: srv.URL})
+	if _, err := p.Chat(context.Background(), "", conversation); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if req.SystemInstruction == nil || req.SystemInstruction.Parts[0].Text != "You build templates." {
+		t.Errorf("system instruction: got %+v", req.SystemInstruction)
+	}
+	want := []string{"user", "model", "user"}
+	if len(req.Contents) != len(want) {
+		t.Fatalf("contents: got %+v", req.Contents)
+	}
+	for i, role := range want {
+		if req.Contents[i].Role != role {
+			t.Errorf("content %d: role %q, want %q", i, req.Contents[i].Role, role)
+		}
+	}
+}
+
+func TestOllamaChat_SendsRoles(t *testing.T) {
+	var req ollamaRequest
+	srv := newCaptureServer(t, []byte(`{"message":{"role":"assistant","content":"ok"},"done":true}`), &req)
+	defer srv.Close()
+
+	p := newOllama("ollama", ProviderConfig{Model: "llama3.1", BaseURL: srv.URL})
+	if _, err := p.Chat(context.Background(), "", conversation); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if len(req.Messages) != 4 || req.Messages[2].Role != "assistant" {
+		t.Errorf("messages: got %+v", req.Messages)
+	}
+}
+
+func TestRegistryChatForTask(t *testing.T) {
+	failing := &mockProvider{name: "primary", err: &APIError{Source: "primary", StatusCode: 401}}
+	backup := &mockProvider{name: "backup", response: "from backup"}
+	reg := newFailoverRegistry(failing, backup)
+
+	res, err := reg.ChatForTask(context.Background(), TaskTemplate, conversation)
+	if err != nil {
+		t.Fatalf("ChatForTask: %v", err)
+	}
+	if res.Text != "from backup" || res.FallbackFrom != "primary" {
+		t.Errorf("result: got %+v", res)
+	}
+	if len(backup.lastChat) != len(conversation) {
+		t.Errorf("backup got %d messages, want %d", len(backup.lastChat), len(conversation))
+	}
+
+	var deltas []string
+	res, err = reg.StreamChatForTask(context.Background(), TaskTemplate, conversation, collect(&deltas))
+	if err != nil || res.Text != "from backup" || len(deltas) != 1 {
+		t.Errorf("StreamChatForTask: got %+v, %v, deltas %q", res, err, deltas)
+	}
+}
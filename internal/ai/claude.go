@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -36,33 +37,123 @@ func (p *claudeProvider) Name() string { return "claude" }
 
 // Generate sends a message to the Anthropic Messages API using the default model.
 func (p *claudeProvider) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	return p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	res, err := p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	return res.Text, err
 }
 
 // GenerateWithModel sends a message using a specific model.
 // If model is empty, the provider's default model is used.
-func (p *claudeProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+func (p *claudeProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
+	return p.Chat(ctx, model, Prompt(systemPrompt, userPrompt))
+}
+
+// Chat sends a conversation to the Messages API. System messages are
+// joined into the top-level system prompt.
+func (p *claudeProvider) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
+	if model == "" {
+		model = p.config.Model
+	}
+	system, turns := claudeMessages(messages)
+	body := claudeRequest{
+		Model:     model,
+		MaxTokens: 4096,
+		System:    system,
+		Messages:  turns,
+	}
+
+	result, usage, err := p.send(ctx, body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Extract text from the first content block.
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			return Result{Text: block.Text, Usage: usage}, nil
+		}
+	}
+
+	return Result{}, fmt.Errorf("claude: no text content in response")
+}
+
+// ChatJSON sends a conversation with a single tool whose input schema is
+// schema and forces the model to call it. The Messages API has no JSON
+// mode; a forced tool call is its structured-output mechanism. The tool
+// input is returned as the reply text.
+func (p *claudeProvider) ChatJSON(ctx context.Context, model string, messages []Message, schema *Schema) (Result, error) {
 	if model == "" {
 		model = p.config.Model
 	}
+	system, turns := claudeMessages(messages)
 	body := claudeRequest{
 		Model:     model,
 		MaxTokens: 4096,
+		System:    system,
+		Messages:  turns,
+		Tools: []claudeTool{{
+			Name:        schema.Name,
+			Description: "Return the response as structured data.",
+			InputSchema: schema.Def,
+		}},
+		ToolChoice: &claudeToolChoice{Type: "tool", Name: schema.Name},
+	}
+
+	result, usage, err := p.send(ctx, body)
+	if err != nil {
+		return Result{}, err
+	}
+
+	for _, block := range result.Content {
+		if block.Type == "tool_use" && block.Name == schema.Name {
+			return Result{Text: string(block.Input), Usage: usage}, nil
+		}
+	}
+
+	return Result{}, fmt.Errorf("claude: no %s tool call in response", schema.Name)
+}
+
+// DescribeImage sends img with a text prompt as a single user turn.
+func (p *claudeProvider) DescribeImage(ctx context.Context, model, systemPrompt, prompt string, img Image) (Result, error) {
+	if model == "" {
+		model = p.config.Model
+	}
+	body := claudeRequest{
+		Model:     model,
+		MaxTokens: 1024,
 		System:    systemPrompt,
-		Messages: []claudeMessage{
-			{Role: "user", Content: userPrompt},
-		},
+		Messages: []claudeMessage{{
+			Role: string(RoleUser),
+			Blocks: []claudeInputBlock{
+				{Type: "image", Source: &claudeImageSource{Type: "base64", MediaType: img.MIMEType, Data: img.base64()}},
+				{Type: "text", Text: prompt},
+			},
+		}},
+	}
+
+	result, usage, err := p.send(ctx, body)
+	if err != nil {
+		return Result{}, err
+	}
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			return Result{Text: block.Text, Usage: usage}, nil
+		}
 	}
+	return Result{}, fmt.Errorf("claude: no text content in response")
+}
 
+// send posts a Messages API request and returns the response and its usage.
+func (p *claudeProvider) send(ctx context.Context, body claudeRequest) (claudeResponse, Usage, error) {
+	var result claudeResponse
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return "", fmt.Errorf("claude marshal: %w", err)
+		return result, Usage{}, fmt.Errorf("claude marshal: %w", err)
 	}
 
 	url := p.config.BaseURL + "/v1/messages"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
-		return "", fmt.Errorf("claude request: %w", err)
+		return result, Usage{}, fmt.Errorf("claude request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -71,32 +162,118 @@ func (p *claudeProvider) GenerateWithModel(ctx context.Context, model, systemPro
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("claude http: %w", err)
+		return result, Usage{}, fmt.Errorf("claude http: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("claude read body: %w", err)
+		return result, Usage{}, fmt.Errorf("claude read body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("claude API error (status %d): %s", resp.StatusCode, string(respBody))
+		return result, Usage{}, &APIError{Source: "claude", StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
-	var result claudeResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("claude unmarshal: %w", err)
+		return result, Usage{}, fmt.Errorf("claude unmarshal: %w", err)
 	}
 
-	// Extract text from the first content block.
-	for _, block := range result.Content {
-		if block.Type == "text" {
-			return block.Text, nil
+	usage := Usage{
+		Provider:     "claude",
+		Model:        result.Model,
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+	}
+	if usage.Model == "" {
+		usage.Model = body.Model
+	}
+	return result, usage, nil
+}
+
+// StreamGenerate streams a message from the Anthropic Messages API,
+// calling onDelta for each text delta. If model is empty, the provider's
+// default model is used.
+func (p *claudeProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	return p.StreamChat(ctx, model, Prompt(systemPrompt, userPrompt), onDelta)
+}
+
+// StreamChat streams the next turn of a conversation.
+func (p *claudeProvider) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
+	if model == "" {
+		model = p.config.Model
+	}
+	system, turns := claudeMessages(messages)
+	body := claudeRequest{
+		Model:     model,
+		MaxTokens: 4096,
+		System:    system,
+		Messages:  turns,
+		Stream:    true,
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Result{}, fmt.Errorf("claude marshal: %w", err)
+	}
+
+	newReq := func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.BaseURL+"/v1/messages", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
 		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-api-key", p.config.APIKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+		return req, nil
 	}
 
-	return "", fmt.Errorf("claude: no text content in response")
+	var full strings.Builder
+	usage := Usage{Provider: "claude", Model: model}
+	err = doStream(ctx, "claude", newReq, func(ev sseEvent) error {
+		switch ev.Event {
+		case "message_start":
+			// Carries the input token count; output is reported at the end.
+			var start claudeStreamStart
+			if err := json.Unmarshal([]byte(ev.Data), &start); err == nil {
+				if start.Message.Model != "" {
+					usage.Model = start.Message.Model
+				}
+				usage.InputTokens = start.Message.Usage.InputTokens
+				usage.OutputTokens = start.Message.Usage.OutputTokens
+			}
+		case "message_delta":
+			var md claudeStreamMessageDelta
+			if err := json.Unmarshal([]byte(ev.Data), &md); err == nil && md.Usage.OutputTokens > 0 {
+				usage.OutputTokens = md.Usage.OutputTokens
+			}
+		case "content_block_delta":
+			var chunk claudeStreamDelta
+			if err := json.Unmarshal([]byte(ev.Data), &chunk); err != nil {
+				return fmt.Errorf("unmarshal delta: %w", err)
+			}
+			if chunk.Delta.Type != "text_delta" || chunk.Delta.Text == "" {
+				return nil
+			}
+			full.WriteString(chunk.Delta.Text)
+			return onDelta(chunk.Delta.Text)
+		case "error":
+			var e claudeStreamError
+			json.Unmarshal([]byte(ev.Data), &e)
+			return fmt.Errorf("API error: %s: %s", e.Error.Type, e.Error.Message)
+		case "message_stop":
+			return errStreamDone
+		}
+		return nil
+	})
+	res := Result{Text: full.String(), Usage: usage}
+	if err != nil {
+		return res, err
+	}
+	if full.Len() == 0 {
+		return res, fmt.Errorf("claude: no text content in stream")
+	}
+	return res, nil
 }
 
 // --- Anthropic Messages API types ---
@@ -104,6 +281,35 @@ func (p *claudeProvider) GenerateWithModel(ctx context.Context, model, systemPro
 type claudeMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+
+	// Blocks replaces Content in requests that mix text and images.
+	Blocks []claudeInputBlock `json:"-"`
+}
+
+// MarshalJSON sends Blocks as the content array when set.
+func (m claudeMessage) MarshalJSON() ([]byte, error) {
+	if len(m.Blocks) == 0 {
+		type plain claudeMessage
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		Role    string             `json:"role"`
+		Content []claudeInputBlock `json:"content"`
+	}{m.Role, m.Blocks})
+}
+
+// claudeInputBlock is one content block of a request message: text or a
+// base64-encoded image.
+type claudeInputBlock struct {
+	Type   string             `json:"type"` // "text" or "image"
+	Text   string             `json:"text,omitempty"`
+	Source *claudeImageSource `json:"source,omitempty"`
+}
+
+type claudeImageSource struct {
+	Type      string `json:"type"` // "base64"
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
 }
 
 type claudeRequest struct {
@@ -111,13 +317,70 @@ type claudeRequest struct {
 	MaxTokens int             `json:"max_tokens"`
 	System    string          `json:"system,omitempty"`
 	Messages  []claudeMessage `json:"messages"`
+	Stream    bool            `json:"stream,omitempty"`
+
+	Tools      []claudeTool      `json:"tools,omitempty"`
+	ToolChoice *claudeToolChoice `json:"tool_choice,omitempty"`
 }
 
+type claudeTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type claudeToolChoice struct {
+	Type string `json:"type"` // "tool" forces the named tool
+	Name string `json:"name,omitempty"`
+}
+
+// claudeContentBlock is a "text" block, or a "tool_use" block carrying
+// the tool's name and input.
 type claudeContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+type claudeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
 type claudeResponse struct {
+	Model   string               `json:"model"`
 	Content []claudeContentBlock `json:"content"`
+	Usage   claudeUsage          `json:"usage"`
+}
+
+// claudeStreamDelta is the payload of a content_block_delta event.
+type claudeStreamDelta struct {
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+}
+
+// claudeStreamStart is the payload of a message_start event.
+type claudeStreamStart struct {
+	Message struct {
+		Model string      `json:"model"`
+		Usage claudeUsage `json:"usage"`
+	} `json:"message"`
+}
+
+// claudeStreamMessageDelta is the payload of a message_delta event, which
+// carries the cumulative output token count.
+type claudeStreamMessageDelta struct {
+	Usage claudeUsage `json:"usage"`
+}
+
+// claudeStreamError is the payload of an error event, sent when the API
+// fails after the stream has started (e.g. overloaded_error).
+type claudeStreamError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
 }
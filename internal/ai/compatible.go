@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// compatible.go implements providers configured by kind rather than by
+// name: any server speaking the OpenAI chat completions API (vLLM,
+// llama.cpp, LM Studio, LiteLLM...) and Ollama's native API.
+package ai
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Provider kinds for ProviderConfig.Kind. Built-in providers leave Kind
+// empty and are selected by name.
+const (
+	KindOpenAICompatible = "openai_compatible"
+	KindOllama           = "ollama"
+)
+
+// localTimeout is the request timeout for self-hosted models, which are
+// often much slower than hosted APIs on the same prompt.
+const localTimeout = 5 * time.Minute
+
+// compatibleProvider implements the Provider interface for a server that
+// speaks the OpenAI chat completions API. The API key is optional. It
+// doesn't implement JSONGenerator: support for response_format varies
+// between servers, so structured calls rely on the prompt alone.
+type compatibleProvider struct {
+	name  string
+	inner *openAIProvider
+}
+
+// newOpenAICompatible creates a provider for an OpenAI-compatible server.
+// cfg.BaseURL must include the API prefix, e.g. "http://vllm:8000/v1".
+func newOpenAICompatible(name string, cfg ProviderConfig) *compatibleProvider {
+	return &compatibleProvider{
+		name: name,
+		inner: &openAIProvider{
+			config: cfg,
+			client: &http.Client{Timeout: localTimeout},
+			prefix: name,
+		},
+	}
+}
+
+func (p *compatibleProvider) Name() string { return p.name }
+
+// Generate sends a chat completion request using the default model.
+func (p *compatibleProvider) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	res, err := p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	return res.Text, err
+}
+
+// GenerateWithModel sends a chat completion request using a specific model.
+// If model is empty, the provider's default model is used.
+func (p *compatibleProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
+	return p.Chat(ctx, model, Prompt(systemPrompt, userPrompt))
+}
+
+// Chat sends a conversation as a chat completion request.
+func (p *compatibleProvider) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
+	if model == "" {
+		model = p.inner.config.Model
+	}
+
+	body := openAIRequest{
+		Model:    model,
+		Messages: openAIMessages(messages),
+	}
+
+	return p.inner.doChat(ctx, p.name, body)
+}
+
+// StreamGenerate streams a chat completion. stream_options is not sent:
+// not every compatible server accepts it, so usage is only reported when
+// the server includes it unasked.
+func (p *compatibleProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	return p.StreamChat(ctx, model, Prompt(systemPrompt, userPrompt), onDelta)
+}
+
+// StreamChat streams the next turn of a conversation.
+func (p *compatibleProvider) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
+	if model == "" {
+		model = p.inner.config.Model
+	}
+
+	body := openAIRequest{
+		Model:    model,
+		Messages: openAIMessages(messages),
+		Stream:   true,
+	}
+
+	return p.inner.streamChat(ctx, p.name, body, onDelta)
+}
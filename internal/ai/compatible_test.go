@@ -0,0 +1,235 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// =====================================================================
+// OpenAI-compatible Provider Tests
+// =====================================================================
+
+func TestCompatibleGenerate_NoKey(t *testing.T) {
+	var gotAuth, gotPath string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"model":"llama3.1:8b","choices":[{"message":{"role":"assistant","content":"Draft"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`)
+	}))
+	defer srv.Close()
+
+	p := newOpenAICompatible("local", ProviderConfig{Kind: KindOpenAICompatible, Model: "llama3.1:8b", BaseURL: srv.URL + "/v1"})
+
+	res, err := p.GenerateWithModel(context.Background(), "", "sys", "user")
+	if err != nil {
+		t.Fatalf("GenerateWithModel: %v", err)
+	}
+	if res.Text != "Draft" {
+		t.Errorf("text: got %q", res.Text)
+	}
+	if gotAuth != "" {
+		t.Errorf("Authorization should be omitted without a key, got %q", gotAuth)
+	}
+	if gotPath != "/v1/chat/completions" {
+		t.Errorf("path: got %q", gotPath)
+	}
+	if body["model"] != "llama3.1:8b" {
+		t.Errorf("model: got %v", body["model"])
+	}
+	if res.Usage.Provider != "local" || res.Usage.InputTokens != 12 || res.Usage.OutputTokens != 3 {
+		t.Errorf("usage: got %+v", res.Usage)
+	}
+}
+
+func TestCompatibleGenerate_WithKey(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Write(openAISuccessBody("ok"))
+	}))
+	defer srv.Close()
+
+	p := newOpenAICompatible("vllm", ProviderConfig{Kind: KindOpenAICompatible, APIKey: "secret", Model: "qwen", BaseURL: srv.URL})
+	if _, err := p.Generate(context.Background(), "sys", "user"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization: got %q", gotAuth)
+	}
+}
+
+func TestCompatibleGenerate_ErrorsNameProvider(t *testing.T) {
+	srv := newTestServer(t, http.StatusServiceUnavailable, []byte(`{"error":"loading model"}`))
+	defer srv.Close()
+
+	p := newOpenAICompatible("local", ProviderConfig{Model: "m", BaseURL: srv.URL})
+	_, err := p.Generate(context.Background(), "sys", "user")
+	if err == nil || !strings.HasPrefix(err.Error(), "local API error (status 503)") {
+		t.Fatalf("expected a local 503 error, got %v", err)
+	}
+	if Classify(err) != ErrorRetryable {
+		t.Errorf("503 from a local server should be retryable")
+	}
+}
+
+func TestCompatibleStreamGenerate(t *testing.T) {
+	var body map[string]any
+	srv := newStreamServer(t, []string{
+		`data: {"choices":[{"delta":{"content":"Hel"}}]}` + "\n\n",
+		`data: {"choices":[{"delta":{"content":"lo"}}]}` + "\n\n",
+		"data: [DONE]\n\n",
+	}, &body)
+	defer srv.Close()
+
+	p := newOpenAICompatible("local", ProviderConfig{Model: "m", BaseURL: srv.URL})
+
+	var deltas []string
+	res, err := p.StreamGenerate(context.Background(), "m-light", "sys", "user", collect(&deltas))
+	if err != nil {
+		t.Fatalf("StreamGenerate: %v", err)
+	}
+	if res.Text != "Hello" || len(deltas) != 2 {
+		t.Errorf("got %q with deltas %q", res.Text, deltas)
+	}
+	if body["model"] != "m-light" {
+		t.Errorf("model: got %v", body["model"])
+	}
+	if _, ok := body["stream_options"]; ok {
+		t.Error("stream_options should not be sent to compatible servers")
+	}
+}
+
+// =====================================================================
+// Ollama Provider Tests
+// =====================================================================
+
+func TestOllamaGenerate(t *testing.T) {
+	var gotPath string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"model":"llama3.1","message":{"role":"assistant","content":"Hi there"},"done":true,"prompt_eval_count":20,"eval_count":4}`)
+	}))
+	defer srv.Close()
+
+	p := newOllama("ollama", ProviderConfig{Kind: KindOllama, Model: "llama3.1", BaseURL: srv.URL + "/"})
+
+	res, err := p.GenerateWithModel(context.Background(), "", "sys", "user")
+	if err != nil {
+		t.Fatalf("GenerateWithModel: %v", err)
+	}
+	if res.Text != "Hi there" {
+		t.Errorf("text: got %q", res.Text)
+	}
+	if gotPath != "/api/chat" {
+		t.Errorf("path: got %q", gotPath)
+	}
+	if body["stream"] != false {
+		t.Errorf("stream should be false, got %v", body["stream"])
+	}
+	if res.Usage.InputTokens != 20 || res.Usage.OutputTokens != 4 || res.Usage.Model != "llama3.1" {
+		t.Errorf("usage: got %+v", res.Usage)
+	}
+}
+
+func TestOllamaGenerate_ModelNotFound(t *testing.T) {
+	srv := newTestServer(t, http.StatusNotFound, []byte(`{"error":"model \"nope\" not found, try pulling it first"}`))
+	defer srv.Close()
+
+	p := newOllama("ollama", ProviderConfig{Model: "nope", BaseURL: srv.URL})
+	_, err := p.Generate(context.Background(), "sys", "user")
+	if err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Fatalf("expected the Ollama error body, got %v", err)
+	}
+	if Classify(err) != ErrorFatal {
+		t.Errorf("a missing model should not be retried")
+	}
+}
+
+func TestOllamaStreamGenerate(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range []string{
+			`{"model":"llama3.1","message":{"role":"assistant","content":"Bon"},"done":false}`,
+			`{"model":"llama3.1","message":{"role":"assistant","content":"jour"},"done":false}`,
+			`{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":9,"eval_count":2}`,
+		} {
+			fmt.Fprintln(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	p := newOllama("ollama", ProviderConfig{Model: "llama3.1", BaseURL: srv.URL})
+
+	var deltas []string
+	res, err := p.StreamGenerate(context.Background(), "llama3.1:70b", "sys", "user", collect(&deltas))
+	if err != nil {
+		t.Fatalf("StreamGenerate: %v", err)
+	}
+	if res.Text != "Bonjour" || len(deltas) != 2 {
+		t.Errorf("got %q with deltas %q", res.Text, deltas)
+	}
+	if body["model"] != "llama3.1:70b" || body["stream"] != true {
+		t.Errorf("request: model=%v stream=%v", body["model"], body["stream"])
+	}
+	if res.Usage.InputTokens != 9 || res.Usage.OutputTokens != 2 {
+		t.Errorf("usage: got %+v", res.Usage)
+	}
+}
+
+func TestOllamaStreamGenerate_ErrorLine(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"error":"out of memory"}`)
+	}))
+	defer srv.Close()
+
+	p := newOllama("ollama", ProviderConfig{Model: "llama3.1", BaseURL: srv.URL})
+	_, err := p.StreamGenerate(context.Background(), "", "sys", "user", func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "out of memory") {
+		t.Fatalf("expected the stream error, got %v", err)
+	}
+}
+
+// ---------- Registry ----------
+
+func TestNewRegistry_CustomProviders(t *testing.T) {
+	reg := NewRegistry("local", map[string]ProviderConfig{
+		"local":  {Kind: KindOllama, Model: "llama3.1"},
+		"vllm":   {Kind: KindOpenAICompatible, Model: "qwen", BaseURL: "http://vllm:8000/v1"},
+		"openai": {Model: "gpt-4o"}, // no key: skipped
+	})
+
+	for _, name := range []string{"local", "vllm"} {
+		if !reg.HasProvider(name) {
+			t.Errorf("%s should be registered without an API key", name)
+		}
+	}
+	if reg.HasProvider("openai") {
+		t.Error("built-in providers still need a key")
+	}
+	p, err := reg.Active()
+	if err != nil || p.Name() != "local" {
+		t.Fatalf("active: got %v, %v", p, err)
+	}
+}
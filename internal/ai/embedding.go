@@ -0,0 +1,398 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// embedding.go defines the optional Embedder capability, the provider
+// implementations (OpenAI, Gemini, Mistral) and a local stand-in used
+// when no provider can embed.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Embedder is an optional interface for providers that turn text into
+// vectors for semantic search.
+type Embedder interface {
+	// Embed returns one vector per text, in order. If model is empty the
+	// provider's default embedding model is used.
+	Embed(ctx context.Context, model string, texts []string) (Embeddings, error)
+}
+
+// Embeddings is the output of an Embed call together with its usage.
+type Embeddings struct {
+	Vectors [][]float32
+	Usage   Usage
+}
+
+// LocalEmbedder is the provider name of the built-in stand-in embedder.
+const LocalEmbedder = "local"
+
+// Default embedding models, used when ModelEmbedding is not set.
+const (
+	defaultOpenAIEmbedding  = "text-embedding-3-small"
+	defaultGeminiEmbedding  = "gemini-embedding-001"
+	defaultMistralEmbedding = "mistral-embed"
+)
+
+// embedderPreference is the order providers are picked in for embeddings
+// when none is configured. It doesn't follow the active provider, so
+// switching chat providers doesn't invalidate the stored vectors.
+var embedderPreference = []string{"openai", "gemini", "mistral"}
+
+// SetEmbeddingProvider selects the provider used for embeddings. An empty
+// name picks the first of OpenAI, Gemini and Mistral that is configured,
+// falling back to the local embedder; LocalEmbedder forces the latter.
+func (r *Registry) SetEmbeddingProvider(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name != "" && name != LocalEmbedder {
+		if _, ok := r.providers[name].(Embedder); !ok {
+			return fmt.Errorf("ai: provider %q is not configured or does not support embeddings", name)
+		}
+	}
+	r.embedder = name
+	return nil
+}
+
+// EmbeddingModel identifies the vectors Embed currently returns, as
+// "provider/model". Vectors from different models can't be compared, so
+// callers store it with each vector and only compare matching ones.
+func (r *Registry) EmbeddingModel() string {
+	name, _, model := r.resolveEmbedder()
+	return name + "/" + model
+}
+
+// Embed embeds texts with the embedding provider. Calls to a remote
+// provider are recorded as TaskEmbedding and don't fail over, since
+// another provider's vectors wouldn't match the stored ones.
+func (r *Registry) Embed(ctx context.Context, texts []string) (Embeddings, error) {
+	name, e, model := r.resolveEmbedder()
+	if name == LocalEmbedder {
+		return e.Embed(ctx, model, texts)
+	}
+
+	var out Embeddings
+	_, err := r.record(ctx, TaskEmbedding, name, model, func() (Result, error) {
+		var err error
+		out, err = e.Embed(ctx, model, texts)
+		return Result{Usage: out.Usage}, err
+	})
+	if err != nil {
+		return Embeddings{}, err
+	}
+	if len(out.Vectors) != len(texts) {
+		return Embeddings{}, fmt.Errorf("ai: %s returned %d embeddings for %d texts", name, len(out.Vectors), len(texts))
+	}
+	return out, nil
+}
+
+// resolveEmbedder returns the embedding provider's name, the provider and
+// the model it uses.
+func (r *Registry) resolveEmbedder() (string, Embedder, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := embedderPreference
+	if r.embedder != "" {
+		names = []string{r.embedder}
+	}
+	for _, name := range names {
+		e, ok := r.providers[name].(Embedder)
+		if !ok {
+			continue
+		}
+		model := r.configs[name].ModelForTask(TaskEmbedding)
+		if model == "" {
+			model = defaultEmbeddingModel(name)
+		}
+		return name, e, model
+	}
+	return LocalEmbedder, localEmbedder{}, localEmbeddingModel
+}
+
+// defaultEmbeddingModel returns a built-in provider's default embedding
+// model.
+func defaultEmbeddingModel(provider string) string {
+	switch provider {
+	case "openai":
+		return defaultOpenAIEmbedding
+	case "gemini":
+		return defaultGeminiEmbedding
+	case "mistral":
+		return defaultMistralEmbedding
+	}
+	return ""
+}
+
+// --- OpenAI and Mistral ---
+
+// openAIEmbeddingRequest is the body of POST /embeddings.
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// openAIEmbeddingResponse is the reply of POST /embeddings.
+type openAIEmbeddingResponse struct {
+	Model string `json:"model"`
+	Data  []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+// Embed embeds texts with OpenAI's embeddings API.
+func (p *openAIProvider) Embed(ctx context.Context, model string, texts []string) (Embeddings, error) {
+	if model == "" {
+		model = defaultOpenAIEmbedding
+	}
+	return p.doEmbed(ctx, "openai", model, texts)
+}
+
+// Embed embeds texts with Mistral's embeddings API, which mirrors
+// OpenAI's.
+func (p *mistralProvider) Embed(ctx context.Context, model string, texts []string) (Embeddings, error) {
+	if model == "" {
+		model = defaultMistralEmbedding
+	}
+	return p.inner.doEmbed(ctx, "mistral", model, texts)
+}
+
+// doEmbed sends an embeddings request to an OpenAI-style API. name is the
+// provider reported in usage and errors.
+func (p *openAIProvider) doEmbed(ctx context.Context, name, model string, texts []string) (Embeddings, error) {
+	payload, err := json.Marshal(openAIEmbeddingRequest{Model: model, Input: texts})
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("%s embeddings marshal: %w", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.BaseURL+"/embeddings", bytes.NewReader(payload))
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("%s embeddings request: %w", name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.authorize(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("%s embeddings http: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("%s embeddings read body: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Embeddings{}, &APIError{Source: name, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var result openAIEmbeddingResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return Embeddings{}, fmt.Errorf("%s embeddings unmarshal: %w", name, err)
+	}
+
+	out := Embeddings{
+		Vectors: make([][]float32, len(texts)),
+		Usage:   Usage{Provider: name, Model: result.Model},
+	}
+	if out.Usage.Model == "" {
+		out.Usage.Model = model
+	}
+	if result.Usage != nil {
+		out.Usage.InputTokens = result.Usage.PromptTokens
+	}
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return Embeddings{}, fmt.Errorf("%s embeddings: index %d out of range", name, d.Index)
+		}
+		out.Vectors[d.Index] = d.Embedding
+	}
+	for i, v := range out.Vectors {
+		if len(v) == 0 {
+			return Embeddings{}, fmt.Errorf("%s embeddings: no vector for input %d", name, i)
+		}
+	}
+	return out, nil
+}
+
+// --- Gemini ---
+
+// geminiEmbedRequest is the body of models/{model}:batchEmbedContents.
+type geminiEmbedRequest struct {
+	Requests []geminiEmbedContent `json:"requests"`
+}
+
+// geminiEmbedContent is one text to embed.
+type geminiEmbedContent struct {
+	Model   string        `json:"model"`
+	Content geminiContent `json:"content"`
+}
+
+// geminiEmbedResponse is the reply of batchEmbedContents.
+type geminiEmbedResponse struct {
+	Embeddings []struct {
+		Values []float32 `json:"values"`
+	} `json:"embeddings"`
+}
+
+// Embed embeds texts with Gemini's batchEmbedContents API. The API
+// doesn't report token usage.
+func (p *geminiProvider) Embed(ctx context.Context, model string, texts []string) (Embeddings, error) {
+	if model == "" {
+		model = defaultGeminiEmbedding
+	}
+
+	body := geminiEmbedRequest{Requests: make([]geminiEmbedContent, len(texts))}
+	for i, t := range texts {
+		body.Requests[i] = geminiEmbedContent{
+			Model:   "models/" + model,
+			Content: geminiContent{Parts: []geminiPart{{Text: t}}},
+		}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("gemini embeddings marshal: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1beta/models/%s:batchEmbedContents", p.config.BaseURL, model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("gemini embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", p.config.APIKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("gemini embeddings http: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Embeddings{}, fmt.Errorf("gemini embeddings read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Embeddings{}, &APIError{Source: "gemini", StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var result geminiEmbedResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return Embeddings{}, fmt.Errorf("gemini embeddings unmarshal: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return Embeddings{}, fmt.Errorf("gemini embeddings: got %d vectors for %d texts", len(result.Embeddings), len(texts))
+	}
+
+	out := Embeddings{
+		Vectors: make([][]float32, len(texts)),
+		Usage:   Usage{Provider: "gemini", Model: model},
+	}
+	for i, e := range result.Embeddings {
+		out.Vectors[i] = e.Values
+	}
+	return out, nil
+}
+
+// --- Local stand-in ---
+
+// localEmbeddingModel names the local embedder's vectors.
+const localEmbeddingModel = "hash-512"
+
+// localDims is the size of local vectors.
+const localDims = 512
+
+// localEmbedder hashes words and word pairs into a fixed-size vector
+// (feature hashing). It understands no meaning, so it only finds texts
+// that share words, but it needs no API key and costs nothing. It keeps
+// search and related posts working until a provider is configured.
+type localEmbedder struct{}
+
+// Embed returns a normalized term-frequency vector for each text.
+func (localEmbedder) Embed(_ context.Context, _ string, texts []string) (Embeddings, error) {
+	out := Embeddings{
+		Vectors: make([][]float32, len(texts)),
+		Usage:   Usage{Provider: LocalEmbedder, Model: localEmbeddingModel},
+	}
+	for i, t := range texts {
+		out.Vectors[i] = hashEmbed(t)
+	}
+	return out, nil
+}
+
+// hashEmbed builds the feature-hashed vector of a text. Each word adds to
+// one dimension and each pair of adjacent words to another, with a sign
+// from the hash so collisions tend to cancel out.
+func hashEmbed(text string) []float32 {
+	v := make([]float32, localDims)
+	words := embedWords(text)
+	add := func(feature string, weight float32) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		sign := float32(1)
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		v[sum%localDims] += sign * weight
+	}
+	for i, w := range words {
+		add(w, 1)
+		if i > 0 {
+			add(words[i-1]+" "+w, 0.5)
+		}
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm > 0 {
+		n := float32(math.Sqrt(norm))
+		for i := range v {
+			v[i] /= n
+		}
+	}
+	return v
+}
+
+// embedWords splits text into lowercase words, dropping very short ones
+// and common English stop words.
+func embedWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	words := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) < 2 || stopWords[f] {
+			continue
+		}
+		words = append(words, f)
+	}
+	return words
+}
+
+// stopWords are skipped by the local embedder: they appear everywhere and
+// would make unrelated texts look alike.
+var stopWords = func() map[string]bool {
+	list := []string{"an", "and", "are", "as", "at", "be", "by", "for", "from",
+		"has", "have", "how", "in", "is", "it", "its", "of", "on", "or", "that",
+		"the", "this", "to", "was", "we", "what", "when", "which", "with", "you", "your"}
+	m := make(map[string]bool, len(list))
+	for _, w := range list {
+		m[w] = true
+	}
+	return m
+}()
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIEmbed(t *testing.T) {
+	var req openAIEmbeddingRequest
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+		// Out of order on purpose: vectors are placed by index.
+		w.Write([]byte(`{"model":"text-embedding-3-small","data":[
+			{"index":1,"embedding":[0,1]},
+			{"index":0,"embedding":[1,0]}],
+			"usage":{"prompt_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL})
+	out, err := p.Embed(context.Background(), "", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if path != "/embeddings" || req.Model != defaultOpenAIEmbedding || len(req.Input) != 2 {
+		t.Errorf("request: path %q, body %+v", path, req)
+	}
+	if out.Vectors[0][0] != 1 || out.Vectors[1][1] != 1 {
+		t.Errorf("vectors: got %v", out.Vectors)
+	}
+	if out.Usage.InputTokens != 7 || out.Usage.Provider != "openai" {
+		t.Errorf("usage: got %+v", out.Usage)
+	}
+}
+
+func TestOpenAIEmbed_MissingVector(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, []byte(`{"data":[{"index":0,"embedding":[1]}]}`))
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL})
+	if _, err := p.Embed(context.Background(), "m", []string{"a", "b"}); err == nil {
+		t.Error("reply without a vector for every input accepted")
+	}
+}
+
+func TestGeminiEmbed(t *testing.T) {
+	var req geminiEmbedRequest
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+		w.Write([]byte(`{"embeddings":[{"values":[0.5,0.5]}]}`))
+	}))
+	defer srv.Close()
+
+	p := newGemini(ProviderConfig{APIKey: "k", BaseURL: srv.URL})
+	out, err := p.Embed(context.Background(), "", []string{"hello"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if !strings.HasSuffix(path, "/models/"+defaultGeminiEmbedding+":batchEmbedContents") {
+		t.Errorf("path: got %q", path)
+	}
+	if len(req.Requests) != 1 || req.Requests[0].Model != "models/"+defaultGeminiEmbedding {
+		t.Errorf("request: got %+v", req)
+	}
+	if len(out.Vectors) != 1 || out.Vectors[0][0] != 0.5 {
+		t.Errorf("vectors: got %v", out.Vectors)
+	}
+}
+
+func TestRegistryEmbeddingProvider(t *testing.T) {
+	reg := newFailoverRegistry(&mockProvider{name: "claude"})
+	if got := reg.EmbeddingModel(); got != LocalEmbedder+"/"+localEmbeddingModel {
+		t.Errorf("without an embedding provider: got %q", got)
+	}
+	if err := reg.SetEmbeddingProvider("claude"); err == nil {
+		t.Error("provider without embeddings accepted")
+	}
+
+	reg.providers["openai"] = newOpenAI(ProviderConfig{APIKey: "k"})
+	reg.configs["openai"] = ProviderConfig{ModelEmbedding: "text-embedding-3-large"}
+	if got := reg.EmbeddingModel(); got != "openai/text-embedding-3-large" {
+		t.Errorf("preferred provider: got %q", got)
+	}
+
+	if err := reg.SetEmbeddingProvider(LocalEmbedder); err != nil {
+		t.Fatalf("SetEmbeddingProvider: %v", err)
+	}
+	out, err := reg.Embed(context.Background(), []string{"x"})
+	if err != nil || len(out.Vectors[0]) != localDims {
+		t.Errorf("local Embed: %v, %d dims", err, len(out.Vectors))
+	}
+}
+
+func TestLocalEmbedderSimilarity(t *testing.T) {
+	out, _ := localEmbedder{}.Embed(context.Background(), "", []string{
+		"How to bake sourdough bread at home",
+		"Baking sourdough bread: a home guide",
+		"Quarterly tax filing deadlines",
+		"",
+	})
+	v := out.Vectors
+	related, unrelated := dot(v[0], v[1]), dot(v[0], v[2])
+	if related <= unrelated {
+		t.Errorf("related %.3f not above unrelated %.3f", related, unrelated)
+	}
+	if n := math.Sqrt(dot(v[0], v[0])); math.Abs(n-1) > 1e-5 {
+		t.Errorf("vector not normalized: norm %.6f", n)
+	}
+	if dot(v[3], v[3]) != 0 {
+		t.Error("empty text has a non-zero vector")
+	}
+}
+
+func dot(a, b []float32) float64 {
+	var s float64
+	for i := range a {
+		s += float64(a[i]) * float64(b[i])
+	}
+	return s
+}
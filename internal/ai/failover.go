@@ -0,0 +1,162 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// failover.go classifies provider errors and provides the per-provider
+// circuit breaker and backoff used by the Registry to fail over to the
+// next provider in a task's fallback chain.
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// APIError is a non-2xx response from a provider API.
+type APIError struct {
+	Source     string // Error prefix, e.g. "openai" or "gemini image"
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Source, e.StatusCode, e.Body)
+}
+
+// ErrorClass tells the Registry how to react to a failed completion.
+type ErrorClass int
+
+const (
+	// ErrorRetryable is a transient failure (rate limit, 5xx, timeout,
+	// network error, malformed response): retry with backoff, then fail
+	// over to the next provider.
+	ErrorRetryable ErrorClass = iota
+	// ErrorAuth means the provider rejected the credentials (401/403).
+	// Retrying won't help; fail over immediately.
+	ErrorAuth
+	// ErrorFatal means the request itself was rejected (other 4xx) or the
+	// caller cancelled it. Another provider would fail the same way.
+	ErrorFatal
+)
+
+// String returns the class name used in logs.
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorRetryable:
+		return "retryable"
+	case ErrorAuth:
+		return "auth"
+	default:
+		return "fatal"
+	}
+}
+
+// Classify sorts a provider error into an ErrorClass. Errors without a
+// status code are retryable unless they are a cancellation: a provider
+// that times out or returns an empty completion may well succeed on
+// the next try.
+func Classify(err error) ErrorClass {
+	if errors.Is(err, context.Canceled) {
+		return ErrorFatal
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch code := apiErr.StatusCode; {
+		case code == http.StatusUnauthorized, code == http.StatusForbidden:
+			return ErrorAuth
+		case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests, code >= 500:
+			return ErrorRetryable
+		default:
+			return ErrorFatal
+		}
+	}
+	return ErrorRetryable
+}
+
+const (
+	// retryAttempts is how many times a provider is tried for one request
+	// before moving on to the next provider in the chain.
+	retryAttempts = 2
+
+	// retryBaseDelay is the first backoff delay; it doubles per attempt.
+	retryBaseDelay = 500 * time.Millisecond
+
+	// breakerThreshold is the number of consecutive retryable failures
+	// that opens a provider's circuit.
+	breakerThreshold = 3
+
+	// breakerCooldown is how long an open circuit skips its provider
+	// before letting a trial request through.
+	breakerCooldown = 30 * time.Second
+
+	// authCooldown is how long a provider is skipped after an auth error.
+	// Keys don't fix themselves, but an admin may rotate one.
+	authCooldown = 10 * time.Minute
+)
+
+// breaker is a per-provider circuit breaker. After breakerThreshold
+// consecutive failures the circuit opens and the provider is skipped
+// until the cooldown ends; the next call is a trial, and a single
+// failure re-opens the circuit.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether the provider may be called at now.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+// success closes the circuit.
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// failure records a failed call of the given class.
+func (b *breaker) failure(now time.Time, class ErrorClass) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch class {
+	case ErrorAuth:
+		b.failures = breakerThreshold
+		b.openUntil = now.Add(authCooldown)
+	case ErrorRetryable:
+		b.failures++
+		if b.failures >= breakerThreshold {
+			b.openUntil = now.Add(breakerCooldown)
+		}
+	}
+}
+
+// backoff returns the delay before retry attempt n (1-based): base·2^(n-1)
+// plus up to 50% jitter so concurrent requests don't retry in lockstep.
+func backoff(base time.Duration, n int) time.Duration {
+	d := base << (n - 1)
+	return d + rand.N(d/2+1)
+}
+
+// sleepCtx waits for d or until ctx is done, returning ctx's error in
+// the latter case.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
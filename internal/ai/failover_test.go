@@ -0,0 +1,295 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"rate limited", &APIError{Source: "openai", StatusCode: 429}, ErrorRetryable},
+		{"server error", &APIError{Source: "claude", StatusCode: 503}, ErrorRetryable},
+		{"wrapped server error", fmt.Errorf("stream: %w", &APIError{StatusCode: 500}), ErrorRetryable},
+		{"unauthorized", &APIError{Source: "gemini", StatusCode: 401}, ErrorAuth},
+		{"forbidden", &APIError{Source: "openai", StatusCode: 403}, ErrorAuth},
+		{"bad request", &APIError{Source: "openai", StatusCode: 400}, ErrorFatal},
+		{"network", fmt.Errorf("openai http: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), ErrorRetryable},
+		{"deadline", fmt.Errorf("claude http: %w", context.DeadlineExceeded), ErrorRetryable},
+		{"stream idle", &timeoutError{"gemini stream: no data for 1m0s"}, ErrorRetryable},
+		{"cancelled", fmt.Errorf("openai http: %w", context.Canceled), ErrorFatal},
+		{"empty response", errors.New("openai: no choices returned"), ErrorRetryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	err := &APIError{Source: "openai", StatusCode: 429, Body: "slow down"}
+	if got := err.Error(); got != "openai API error (status 429): slow down" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+
+	t.Run("opens after consecutive failures and closes on success", func(t *testing.T) {
+		b := &breaker{}
+		for i := 0; i < breakerThreshold-1; i++ {
+			b.failure(now, ErrorRetryable)
+		}
+		if !b.allow(now) {
+			t.Fatal("circuit opened before the threshold")
+		}
+		b.failure(now, ErrorRetryable)
+		if b.allow(now) {
+			t.Fatal("circuit should be open at the threshold")
+		}
+		if !b.allow(now.Add(breakerCooldown)) {
+			t.Fatal("circuit should allow a trial after the cooldown")
+		}
+
+		// A failed trial re-opens the circuit straight away.
+		later := now.Add(breakerCooldown)
+		b.failure(later, ErrorRetryable)
+		if b.allow(later) {
+			t.Fatal("failed trial should re-open the circuit")
+		}
+
+		b.success()
+		if !b.allow(later) {
+			t.Fatal("success should close the circuit")
+		}
+	})
+
+	t.Run("auth errors open the circuit for longer", func(t *testing.T) {
+		b := &breaker{}
+		b.failure(now, ErrorAuth)
+		if b.allow(now.Add(breakerCooldown)) {
+			t.Error("auth failure should outlast the normal cooldown")
+		}
+		if !b.allow(now.Add(authCooldown)) {
+			t.Error("auth failure should expire after authCooldown")
+		}
+	})
+
+	t.Run("fatal errors don't count", func(t *testing.T) {
+		b := &breaker{}
+		for i := 0; i < breakerThreshold; i++ {
+			b.failure(now, ErrorFatal)
+		}
+		if !b.allow(now) {
+			t.Error("fatal errors should not open the circuit")
+		}
+	})
+}
+
+// partialStreamProvider streams one delta and then fails.
+type partialStreamProvider struct {
+	mockProvider
+}
+
+func (p *partialStreamProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	p.mu.Lock()
+	p.callCount++
+	p.mu.Unlock()
+	if err := onDelta("partial"); err != nil {
+		return Result{}, err
+	}
+	return Result{Text: "partial"}, &APIError{Source: p.name, StatusCode: 529}
+}
+
+// newFailoverRegistry returns a registry with the given providers, the
+// first one active and the rest as fallbacks for every task.
+func newFailoverRegistry(providers ...Provider) *Registry {
+	reg := &Registry{
+		providers: map[string]Provider{},
+		configs:   map[string]ProviderConfig{},
+		active:    providers[0].Name(),
+		retryBase: time.Millisecond,
+	}
+	var names []string
+	for _, p := range providers {
+		reg.providers[p.Name()] = p
+		reg.configs[p.Name()] = ProviderConfig{Model: p.Name() + "-model"}
+		names = append(names, p.Name())
+	}
+	for _, task := range []TaskType{TaskContent, TaskTemplate, TaskLight} {
+		reg.SetFallbacks(task, names[1:])
+	}
+	return reg
+}
+
+func TestRegistryFailover(t *testing.T) {
+	t.Run("retries then falls back on retryable errors", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: &APIError{Source: "claude", StatusCode: 529}}
+		openai := &mockProvider{name: "openai", response: "from openai"}
+		rec := &recordingUsageRecorder{}
+		reg := newFailoverRegistry(claude, openai)
+		reg.SetUsageRecorder(rec)
+
+		res, err := reg.CompleteForTask(context.Background(), TaskContent, "system", "user")
+		if err != nil {
+			t.Fatalf("CompleteForTask: %v", err)
+		}
+		if res.Text != "from openai" || res.Usage.Provider != "openai" || res.FallbackFrom != "claude" {
+			t.Errorf("got text=%q provider=%q fallbackFrom=%q", res.Text, res.Usage.Provider, res.FallbackFrom)
+		}
+		if claude.callCount != retryAttempts {
+			t.Errorf("claude called %d times, want %d", claude.callCount, retryAttempts)
+		}
+		if len(rec.records) != retryAttempts+1 {
+			t.Errorf("expected every attempt recorded, got %d records", len(rec.records))
+		}
+	})
+
+	t.Run("auth errors skip retries and open the circuit", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: &APIError{Source: "claude", StatusCode: 401}}
+		openai := &mockProvider{name: "openai", response: "ok"}
+		reg := newFailoverRegistry(claude, openai)
+
+		for i := 0; i < 2; i++ {
+			if _, err := reg.GenerateForTask(context.Background(), TaskLight, "system", "user"); err != nil {
+				t.Fatalf("GenerateForTask: %v", err)
+			}
+		}
+		if claude.callCount != 1 {
+			t.Errorf("claude called %d times, want 1 (circuit open after auth error)", claude.callCount)
+		}
+		if openai.callCount != 2 {
+			t.Errorf("openai called %d times, want 2", openai.callCount)
+		}
+	})
+
+	t.Run("fatal errors are not failed over", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: &APIError{Source: "claude", StatusCode: 400}}
+		openai := &mockProvider{name: "openai", response: "ok"}
+		reg := newFailoverRegistry(claude, openai)
+
+		_, err := reg.GenerateForTask(context.Background(), TaskContent, "system", "user")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+			t.Fatalf("expected the 400 error, got %v", err)
+		}
+		if claude.callCount != 1 || openai.callCount != 0 {
+			t.Errorf("calls: claude=%d openai=%d, want 1/0", claude.callCount, openai.callCount)
+		}
+	})
+
+	t.Run("returns the last error when every provider fails", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: &APIError{Source: "claude", StatusCode: 500}}
+		gemini := &mockProvider{name: "gemini", err: &APIError{Source: "gemini", StatusCode: 503}}
+		reg := newFailoverRegistry(claude, gemini)
+
+		_, err := reg.GenerateForTask(context.Background(), TaskContent, "system", "user")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Source != "gemini" {
+			t.Fatalf("expected gemini's error, got %v", err)
+		}
+	})
+
+	t.Run("skips providers with an open circuit", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: errors.New("claude: no text content in response")}
+		openai := &mockProvider{name: "openai", response: "ok"}
+		reg := newFailoverRegistry(claude, openai)
+
+		// Two attempts per request: the second request opens the circuit.
+		for i := 0; i < 3; i++ {
+			if _, err := reg.GenerateForTask(context.Background(), TaskLight, "system", "user"); err != nil {
+				t.Fatalf("GenerateForTask: %v", err)
+			}
+		}
+		if claude.callCount != breakerThreshold {
+			t.Errorf("claude called %d times, want %d", claude.callCount, breakerThreshold)
+		}
+	})
+
+	t.Run("errors when every circuit is open", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", response: "ok"}
+		reg := newFailoverRegistry(claude)
+		reg.breaker("claude").failure(time.Now(), ErrorAuth)
+
+		if _, err := reg.GenerateForTask(context.Background(), TaskLight, "system", "user"); err == nil {
+			t.Fatal("expected an error")
+		}
+		if claude.callCount != 0 {
+			t.Errorf("claude called %d times, want 0", claude.callCount)
+		}
+	})
+
+	t.Run("uses the per-task order and skips unconfigured providers", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: &APIError{Source: "claude", StatusCode: 503}}
+		openai := &mockProvider{name: "openai", response: "from openai"}
+		gemini := &mockProvider{name: "gemini", response: "from gemini"}
+		reg := newFailoverRegistry(claude, openai, gemini)
+		reg.SetFallbacks(TaskTemplate, []string{"mistral", " gemini ", "openai"})
+
+		res, err := reg.CompleteForTask(context.Background(), TaskTemplate, "system", "user")
+		if err != nil {
+			t.Fatalf("CompleteForTask: %v", err)
+		}
+		if res.Usage.Provider != "gemini" || res.Usage.Model != "gemini-model" {
+			t.Errorf("got provider=%q model=%q, want gemini/gemini-model", res.Usage.Provider, res.Usage.Model)
+		}
+	})
+
+	t.Run("stream fails over before the first delta only", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: &APIError{Source: "claude", StatusCode: 503}}
+		openai := &mockProvider{name: "openai", response: "streamed"}
+		reg := newFailoverRegistry(claude, openai)
+
+		var got string
+		res, err := reg.StreamForTask(context.Background(), TaskContent, "system", "user", func(d string) error {
+			got += d
+			return nil
+		})
+		if err != nil || got != "streamed" || res.FallbackFrom != "claude" {
+			t.Fatalf("got %q fallbackFrom=%q err=%v", got, res.FallbackFrom, err)
+		}
+
+		partial := &partialStreamProvider{mockProvider{name: "claude"}}
+		openai = &mockProvider{name: "openai", response: "streamed"}
+		reg = newFailoverRegistry(partial, openai)
+
+		_, err = reg.StreamForTask(context.Background(), TaskContent, "system", "user", func(string) error { return nil })
+		if err == nil {
+			t.Fatal("expected the mid-stream error")
+		}
+		if partial.callCount != 1 || openai.callCount != 0 {
+			t.Errorf("calls: claude=%d openai=%d, want 1/0", partial.callCount, openai.callCount)
+		}
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		claude := &mockProvider{name: "claude", err: &APIError{Source: "claude", StatusCode: 503}}
+		openai := &mockProvider{name: "openai", response: "ok"}
+		reg := newFailoverRegistry(claude, openai)
+		reg.retryBase = time.Hour
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := reg.GenerateForTask(ctx, TaskContent, "system", "user"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded during backoff, got %v", err)
+		}
+		if openai.callCount != 0 {
+			t.Errorf("openai called %d times after cancellation", openai.callCount)
+		}
+	})
+}
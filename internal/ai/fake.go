@@ -0,0 +1,589 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// fake.go implements the fake provider: canned, deterministic replies for
+// development, end-to-end tests and demos, with no API key or network
+// access. It answers every task the CMS sends in the shape the caller
+// expects, and can be made slow or unreliable on purpose.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FakeName is the provider name of the fake provider (AI_PROVIDER=fake).
+const FakeName = "fake"
+
+// Magic words the fake provider reacts to in user prompts.
+const (
+	// FakeFlagWord is flagged by the fake moderator unless FakeOptions
+	// names another word.
+	FakeFlagWord = "FAKE_FLAGGED"
+	// FakeErrorWord makes the call fail with a server error.
+	FakeErrorWord = "FAKE_ERROR"
+)
+
+// FakeModel is the model the fake provider reports when the caller names
+// none.
+const FakeModel = "fake-1"
+
+// FakeOptions configure the fake provider.
+type FakeOptions struct {
+	Latency   time.Duration // Delay before every reply
+	FailEvery int           // Every Nth call fails with a retryable error; 0 never
+	FlagWord  string        // Flagged by the fake moderator; empty means FakeFlagWord
+}
+
+// Fake is a provider whose replies depend only on the prompt. It
+// implements every optional capability: JSON output, vision, image
+// generation and editing. Replies that follow a JSON Schema are built from
+// the schema; template requests get a valid template for their type.
+type Fake struct {
+	opts  FakeOptions
+	calls atomic.Int64
+}
+
+// NewFake creates a fake provider.
+func NewFake(opts FakeOptions) *Fake {
+	if opts.FlagWord == "" {
+		opts.FlagWord = FakeFlagWord
+	}
+	return &Fake{opts: opts}
+}
+
+func (f *Fake) Name() string { return FakeName }
+
+// Moderator returns a moderator that flags any text containing the flag
+// word, ignoring case.
+func (f *Fake) Moderator() Moderator {
+	return fakeModerator{word: strings.ToLower(f.opts.FlagWord)}
+}
+
+// Generate replies using the default model.
+func (f *Fake) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	res, err := f.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	return res.Text, err
+}
+
+// GenerateWithModel replies to a single prompt.
+func (f *Fake) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
+	return f.Chat(ctx, model, Prompt(systemPrompt, userPrompt))
+}
+
+// StreamGenerate streams the reply to a single prompt word by word.
+func (f *Fake) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	return f.StreamChat(ctx, model, Prompt(systemPrompt, userPrompt), onDelta)
+}
+
+// Chat replies to the last user message of a conversation.
+func (f *Fake) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
+	return f.complete(ctx, model, messages, nil, nil)
+}
+
+// StreamChat is the streaming counterpart of Chat.
+func (f *Fake) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
+	return f.complete(ctx, model, messages, nil, onDelta)
+}
+
+// ChatJSON replies with a JSON object matching schema.
+func (f *Fake) ChatJSON(ctx context.Context, model string, messages []Message, schema *Schema) (Result, error) {
+	return f.complete(ctx, model, messages, schema, nil)
+}
+
+// DescribeImage replies as Chat does. The image is ignored; the schema
+// comes from the instructions in the system prompt.
+func (f *Fake) DescribeImage(ctx context.Context, model, systemPrompt, prompt string, img Image) (Result, error) {
+	return f.complete(ctx, model, Prompt(systemPrompt, prompt), nil, nil)
+}
+
+// GenerateImage returns opts.Count PNGs whose colours depend on the
+// prompt, the seed and the image's position.
+func (f *Fake) GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([]Image, error) {
+	if err := f.wait(ctx, prompt); err != nil {
+		return nil, err
+	}
+	return fakeImages(opts.prompt(prompt), opts)
+}
+
+// EditImage returns opts.Count PNGs as GenerateImage does, for the edit
+// prompt and the size of the source.
+func (f *Fake) EditImage(ctx context.Context, edit ImageEdit, opts ImageOptions) ([]Image, error) {
+	if err := f.wait(ctx, edit.Prompt); err != nil {
+		return nil, err
+	}
+	return fakeImages(fmt.Sprintf("%s/%d", opts.prompt(edit.Prompt), len(edit.Source.Data)), opts)
+}
+
+// complete builds the reply to messages, streaming it to onDelta when
+// set. Token counts are estimated at four characters per token.
+func (f *Fake) complete(ctx context.Context, model string, messages []Message, schema *Schema, onDelta DeltaFunc) (Result, error) {
+	_, turns := splitSystem(messages)
+	var asked strings.Builder
+	for _, m := range turns {
+		asked.WriteString(m.Content)
+	}
+	if err := f.wait(ctx, asked.String()); err != nil {
+		return Result{}, err
+	}
+
+	if model == "" {
+		model = FakeModel
+	}
+	text := fakeReply(messages, schema)
+	res := Result{Usage: Usage{Provider: FakeName, Model: model, OutputTokens: fakeTokens(text)}}
+	for _, m := range messages {
+		res.Usage.InputTokens += fakeTokens(m.Content)
+	}
+
+	if onDelta == nil {
+		res.Text = text
+		return res, nil
+	}
+	var sent strings.Builder
+	for _, delta := range strings.SplitAfter(text, " ") {
+		if err := onDelta(delta); err != nil {
+			res.Text = sent.String()
+			return res, err
+		}
+		sent.WriteString(delta)
+	}
+	res.Text = text
+	return res, nil
+}
+
+// wait applies the configured latency and error injection to a call.
+// prompt is checked for FakeErrorWord.
+func (f *Fake) wait(ctx context.Context, prompt string) error {
+	if f.opts.Latency > 0 {
+		if err := sleepCtx(ctx, f.opts.Latency); err != nil {
+			return err
+		}
+	}
+	n := f.calls.Add(1)
+	if f.opts.FailEvery > 0 && n%int64(f.opts.FailEvery) == 0 {
+		return &APIError{Source: FakeName, StatusCode: 503, Body: fmt.Sprintf("injected failure on call %d", n)}
+	}
+	if strings.Contains(prompt, FakeErrorWord) {
+		return &APIError{Source: FakeName, StatusCode: 500, Body: "the prompt asked for an error"}
+	}
+	return nil
+}
+
+// fakeTokens estimates the tokens in text.
+func fakeTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// fakeModerator flags text containing a word.
+type fakeModerator struct {
+	word string // lowercase
+}
+
+func (m fakeModerator) CheckSafety(_ context.Context, text string) (*ModerationResult, error) {
+	if m.word != "" && strings.Contains(strings.ToLower(text), m.word) {
+		return &ModerationResult{Safe: false, Categories: []string{"fake"}}, nil
+	}
+	return &ModerationResult{Safe: true}, nil
+}
+
+// fakeReply picks the reply for a conversation: JSON for a schema (given,
+// or found in the system prompt's instructions), a template for a
+// template request, then a numbered title list, a DESCRIPTION:/KEYWORDS:
+// block or a tag list when the system prompt asks for them as text, and
+// otherwise a short Markdown article.
+func fakeReply(messages []Message, schema *Schema) string {
+	system, turns := splitSystem(messages)
+	user := ""
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == RoleUser {
+			user = turns[i].Content
+			break
+		}
+	}
+	subject := fakeSubject(user)
+
+	if schema == nil {
+		schema = schemaFromInstructions(system)
+	}
+	if schema != nil {
+		out, _ := json.Marshal(fakeValue(schema.Def, "", subject, 0))
+		return string(out)
+	}
+
+	// Template requests name their type in the user prompt; the
+	// variables in the system prompt name it too, e.g. "TEMPLATE TYPE:
+	// Article Loop (post listing)".
+	tmplType := fakeField(user, "Template type:")
+	if tmplType == "" {
+		name, _, _ := strings.Cut(fakeField(system, "TEMPLATE TYPE:"), " (")
+		tmplType = strings.ReplaceAll(strings.ToLower(name), " ", "_")
+	}
+	if tmplType != "" {
+		return fakeTemplate(tmplType, subject)
+	}
+
+	lower := strings.ToLower(system)
+	switch {
+	case strings.Contains(lower, "title suggestions") || strings.Contains(lower, "headline"):
+		var sb strings.Builder
+		for i, t := range fakeTitles(subject) {
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, t)
+		}
+		return strings.TrimSuffix(sb.String(), "\n")
+	case strings.Contains(lower, "meta description") && strings.Contains(lower, "keywords"):
+		return "DESCRIPTION: " + fakeDescription(subject) + "\nKEYWORDS: " + strings.Join(fakeTags(subject), ", ")
+	case strings.Contains(lower, "tags"):
+		return strings.Join(fakeTags(subject), ", ")
+	}
+	return fakeArticle(subject)
+}
+
+// fakeField returns the value of the first line of text starting with
+// prefix, trimmed.
+func fakeField(text, prefix string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), prefix); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
+
+// fakeSubject is what a reply is about: the prompt's title or template
+// request, else its first line, shortened to a few words.
+func fakeSubject(prompt string) string {
+	subject := fakeField(prompt, "Title:")
+	if subject == "" {
+		subject = fakeField(prompt, "Request:")
+	}
+	if subject == "" {
+		for _, line := range strings.Split(prompt, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				subject = line
+				break
+			}
+		}
+	}
+	subject = strings.TrimRight(subject, ".!?:")
+	if words := strings.Fields(subject); len(words) > 6 {
+		subject = strings.Join(words[:6], " ")
+	}
+	if subject == "" {
+		return "your topic"
+	}
+	return subject
+}
+
+// schemaFromInstructions finds the JSON Schema that jsonInstructions put
+// in a system prompt.
+func schemaFromInstructions(system string) *Schema {
+	const marker = "JSON Schema, and nothing else:\n"
+	i := strings.LastIndex(system, marker)
+	if i < 0 {
+		return nil
+	}
+	var def map[string]any
+	if err := json.NewDecoder(strings.NewReader(system[i+len(marker):])).Decode(&def); err != nil {
+		return nil
+	}
+	return &Schema{Name: "reply", Def: def}
+}
+
+// fakeValue builds a value matching def. name is the property holding it
+// and picks the wording of strings; i is the position in a list.
+func fakeValue(def map[string]any, name, subject string, i int) any {
+	switch def["type"] {
+	case "object":
+		props, _ := def["properties"].(map[string]any)
+		out := make(map[string]any, len(props))
+		for key, p := range props {
+			propDef, _ := p.(map[string]any)
+			out[key] = fakeValue(propDef, key, subject, 0)
+		}
+		return out
+	case "array":
+		itemDef, _ := def["items"].(map[string]any)
+		n := 3
+		switch name {
+		case "titles", "tags", "keywords", "meta_keywords":
+			n = 5
+		case "links":
+			n = 1
+		}
+		out := make([]any, n)
+		for j := range out {
+			out[j] = fakeValue(itemDef, name, subject, j)
+		}
+		return out
+	case "string":
+		return fakeString(name, subject, i)
+	case "integer", "number":
+		return i + 1
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// fakeString is the text for a string property, by its name.
+func fakeString(name, subject string, i int) string {
+	switch name {
+	case "titles":
+		return fakeTitles(subject)[i%5]
+	case "title":
+		return subject
+	case "excerpt":
+		return "A short introduction to " + subject + "."
+	case "description", "meta_description":
+		return fakeDescription(subject)
+	case "tags", "keywords", "meta_keywords":
+		return fakeTags(subject)[i%5]
+	case "body":
+		return fakeArticle(subject)
+	case "alt_text":
+		return "A placeholder image made by the fake AI provider."
+	case "caption":
+		return "A placeholder caption about " + subject + "."
+	case "changelog":
+		return []string{"Update the wording", "Adjust the formatting", "Tidy the structure"}[i%3]
+	case "suggestions":
+		return []string{
+			"Open with a sentence that says who the piece is for.",
+			"Add a subheading before each main section.",
+			"Write a meta description of about 150 characters.",
+		}[i%3]
+	case "anchor":
+		return strings.ToLower(subject)
+	}
+	return fmt.Sprintf("Fake %s %d", strings.ReplaceAll(name, "_", " "), i+1)
+}
+
+// fakeTitles returns five titles about subject, each under 70 characters.
+func fakeTitles(subject string) []string {
+	s := fakeTruncate(fakeCapitalize(subject), 30)
+	return []string{
+		s + ": A Beginner's Guide",
+		"Everything You Need to Know About " + s,
+		"10 Practical Tips for " + s,
+		"Why " + s + " Matters",
+		s + ", Explained",
+	}
+}
+
+// fakeDescription returns a meta description of at most 160 characters.
+func fakeDescription(subject string) string {
+	return fakeTruncate("Learn about "+subject+": what it is, why it matters and how to get started.", 160)
+}
+
+// fakeTags returns five lowercase tags: the longer words of subject, then
+// fillers.
+func fakeTags(subject string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if len(tags) < 5 && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, w := range strings.FieldsFunc(strings.ToLower(subject), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(w) > 3 {
+			add(w)
+		}
+	}
+	for _, filler := range []string{"guide", "tips", "basics", "how to", "example"} {
+		add(filler)
+	}
+	return tags
+}
+
+// fakeArticle returns a short Markdown article about subject.
+func fakeArticle(subject string) string {
+	s := fakeCapitalize(subject)
+	return "## " + s + "\n\n" +
+		"This text was written by the fake AI provider. It is the same every time for the same request, " +
+		"so it is useful for development, tests and demos, but it says nothing real about " + subject + ".\n\n" +
+		"## Getting started\n\n" +
+		"- Decide what you want to achieve.\n" +
+		"- Start small and build on what works.\n" +
+		"- Write down what you learn.\n\n" +
+		"## Next steps\n\n" +
+		"Set AI_PROVIDER to a real provider to get useful content."
+}
+
+// fakeCapitalize upper-cases the first letter of s.
+func fakeCapitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// fakeTruncate shortens s to at most n runes, at a word boundary when
+// there is one.
+func fakeTruncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,:;")
+}
+
+// fakePalettes are the Tailwind colours fake templates are drawn in; the
+// request picks one.
+var fakePalettes = []string{"indigo", "emerald", "slate", "rose"}
+
+// fakeTemplate returns a valid template of the given type. The request
+// only picks the colour, so its text never ends up inside the template.
+func fakeTemplate(tmplType, subject string) string {
+	h := fnv.New32a()
+	h.Write([]byte(subject))
+	c := fakePalettes[h.Sum32()%uint32(len(fakePalettes))]
+
+	switch tmplType {
+	case "header":
+		return `<header class="bg-` + c + `-700 text-white sticky top-0 z-50">
+  <nav class="max-w-6xl mx-auto flex items-center justify-between px-4 py-4">
+    <a href="/" class="text-xl font-bold">{{.SiteName}}</a>
+    <div class="space-x-6 text-sm">
+      <a href="/" class="hover:text-` + c + `-200">Home</a>
+      <a href="/blog" class="hover:text-` + c + `-200">Blog</a>
+    </div>
+  </nav>
+</header>`
+	case "footer":
+		return `<footer class="bg-` + c + `-900 text-` + c + `-200 text-sm">
+  <div class="max-w-6xl mx-auto px-4 py-8 text-center">
+    <p>&copy; {{.Year}} {{.SiteName}}. All rights reserved.</p>
+  </div>
+</footer>`
+	case "article_loop":
+		return fakeDocument(c, `<h1 class="text-3xl font-bold mb-8">{{.Title}}</h1>
+    <div class="grid gap-8 md:grid-cols-2">
+      {{range .Posts}}
+      <article class="rounded-lg border border-gray-200 overflow-hidden">
+        {{if .FeaturedImageURL}}<img src="{{.FeaturedImageURL}}" alt="{{.FeaturedImageAlt}}" class="w-full h-48 object-cover">{{end}}
+        <div class="p-4">
+          <h2 class="text-xl font-semibold"><a href="/{{.Slug}}" class="hover:text-`+c+`-600">{{.Title}}</a></h2>
+          {{if .PublishedAt}}<p class="text-sm text-gray-500">{{.PublishedAt}}</p>{{end}}
+          {{if .Excerpt}}<p class="mt-2 text-gray-700">{{.Excerpt}}</p>{{end}}
+        </div>
+      </article>
+      {{else}}
+      <p class="text-gray-500">No posts yet.</p>
+      {{end}}
+    </div>`)
+	case "search":
+		return fakeDocument(c, `<h1 class="text-3xl font-bold mb-4">{{.Title}}</h1>
+    <form action="/search" method="get" class="mb-8">
+      <input type="search" name="q" value="{{.Query}}" class="w-full rounded border border-gray-300 px-3 py-2">
+    </form>
+    {{if .Query}}<p class="text-sm text-gray-500 mb-4">{{.Total}} results for "{{.Query}}"</p>{{end}}
+    {{range .Results}}
+    <article class="mb-6">
+      <h2 class="text-xl font-semibold"><a href="/{{.Slug}}" class="hover:text-`+c+`-600">{{.Title}}</a></h2>
+      {{if .Snippet}}<p class="mt-1 text-gray-700">{{.Snippet}}</p>{{end}}
+    </article>
+    {{end}}
+    <nav class="flex justify-between text-sm">
+      {{if .PrevURL}}<a href="{{.PrevURL}}" class="text-`+c+`-600">Previous</a>{{end}}
+      {{if .NextURL}}<a href="{{.NextURL}}" class="text-`+c+`-600">Next</a>{{end}}
+    </nav>`)
+	default:
+		return fakeDocument(c, `<article>
+      <h1 class="text-4xl font-bold">{{.Title}}</h1>
+      {{if .PublishedAt}}<p class="mt-2 text-sm text-gray-500">{{.PublishedAt}}</p>{{end}}
+      {{if .FeaturedImageURL}}<img src="{{.FeaturedImageURL}}" alt="{{.FeaturedImageAlt}}" class="mt-6 w-full rounded-lg">{{end}}
+      <div class="prose mt-8 max-w-none">{{.Body}}</div>
+    </article>`)
+	}
+}
+
+// fakeDocument wraps main content in a full page with the header and
+// footer, for page, article_loop and search templates.
+func fakeDocument(c, main string) string {
+	return `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>{{.Title}} | {{.SiteName}}</title>
+  <script src="https://cdn.tailwindcss.com"></script>
+</head>
+<body class="bg-` + c + `-50 text-gray-900">
+  {{.Header}}
+  <main class="max-w-6xl mx-auto px-4 py-12">
+    ` + main + `
+  </main>
+  {{.Footer}}
+</body>
+</html>`
+}
+
+// fakeImageSizes maps aspect ratios to the size of fake images.
+var fakeImageSizes = map[string][2]int{
+	"16:9": {320, 180},
+	"1:1":  {256, 256},
+	"4:3":  {320, 240},
+	"3:4":  {240, 320},
+	"9:16": {180, 320},
+}
+
+// fakeImages draws opts.Count gradient PNGs whose colours are derived
+// from key, the seed and each image's position.
+func fakeImages(key string, opts ImageOptions) ([]Image, error) {
+	size, ok := fakeImageSizes[opts.Aspect]
+	if !ok {
+		size = fakeImageSizes[ImageAspects[0]]
+	}
+	images := make([]Image, 0, opts.Count)
+	for n := range opts.Count {
+		h := fnv.New32a()
+		fmt.Fprintf(h, "%s/%d/%d", key, opts.Seed, n)
+		sum := h.Sum32()
+		from := color.RGBA{uint8(sum), uint8(sum >> 8), uint8(sum >> 16), 255}
+		to := color.RGBA{255 - from.R, 255 - from.G, 255 - from.B, 255}
+
+		w, ht := size[0], size[1]
+		img := image.NewRGBA(image.Rect(0, 0, w, ht))
+		for y := range ht {
+			for x := range w {
+				t := float64(x+y) / float64(w+ht-2)
+				img.Set(x, y, color.RGBA{
+					R: fakeMix(from.R, to.R, t),
+					G: fakeMix(from.G, to.G, t),
+					B: fakeMix(from.B, to.B, t),
+					A: 255,
+				})
+			}
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("fake image: %w", err)
+		}
+		images = append(images, Image{Data: buf.Bytes(), MIMEType: "image/png"})
+	}
+	return images, nil
+}
+
+// fakeMix interpolates between two colour channels.
+func fakeMix(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeRegistry returns a registry whose only provider is a fake.
+func newFakeRegistry(opts FakeOptions) *Registry {
+	r := NewRegistry(FakeName, nil)
+	r.retryBase = time.Millisecond
+	fake := NewFake(opts)
+	r.Register(FakeName, fake)
+	r.SetModerator(fake.Moderator())
+	return r
+}
+
+func TestFakeStructuredReplies(t *testing.T) {
+	r := newFakeRegistry(FakeOptions{})
+	ctx := context.Background()
+	prompt := "Title: Balcony tomatoes\n\nContent:\nGrow them in big pots."
+
+	var titles struct {
+		Titles []string `json:"titles"`
+	}
+	res, err := r.GenerateJSON(ctx, TaskLight, MustSchema("titles", titles), "Suggest titles.", prompt, &titles)
+	if err != nil {
+		t.Fatalf("titles: %v", err)
+	}
+	if len(titles.Titles) != 5 || titles.Titles[0] != "Balcony tomatoes: A Beginner's Guide" {
+		t.Errorf("titles: %q", titles.Titles)
+	}
+	if res.Usage.Provider != FakeName || res.Usage.Model != FakeModel || res.Usage.InputTokens == 0 || res.Usage.OutputTokens == 0 {
+		t.Errorf("usage: %+v", res.Usage)
+	}
+
+	var seo struct {
+		Description string   `json:"description"`
+		Keywords    []string `json:"keywords"`
+	}
+	if _, err := r.GenerateJSON(ctx, TaskLight, MustSchema("seo", seo), "SEO.", prompt, &seo); err != nil {
+		t.Fatalf("seo: %v", err)
+	}
+	if seo.Description == "" || len(seo.Description) > 160 || strings.Join(seo.Keywords, ",") != "balcony,tomatoes,guide,tips,basics" {
+		t.Errorf("seo: %+v", seo)
+	}
+
+	// Replies depend only on the prompt.
+	again, _ := r.CompleteForTask(ctx, TaskContent, "Write.", prompt)
+	first, _ := r.CompleteForTask(ctx, TaskContent, "Write.", prompt)
+	if again.Text != first.Text || !strings.HasPrefix(first.Text, "## Balcony tomatoes") {
+		t.Errorf("article: %q", first.Text)
+	}
+
+	// DescribeImage only has the schema in the system prompt.
+	var desc struct {
+		AltText string `json:"alt_text"`
+		Caption string `json:"caption"`
+	}
+	if _, err := r.DescribeImage(ctx, MustSchema("desc", desc), "Describe.", "An image.", Image{Data: []byte{1}, MIMEType: "image/png"}, &desc); err != nil || desc.AltText == "" {
+		t.Errorf("describe: %+v, %v", desc, err)
+	}
+}
+
+func TestFakeTextReplies(t *testing.T) {
+	r := newFakeRegistry(FakeOptions{})
+	ctx := context.Background()
+	prompt := "Title: Balcony tomatoes\n\nContent:\nGrow them in big pots."
+
+	tests := []struct {
+		system, want string
+	}{
+		{"Generate 5 title suggestions, one per line.", "1. Balcony tomatoes: A Beginner's Guide\n2. Everything You Need to Know About Balcony tomatoes\n"},
+		{"Write a meta description and keywords.", "DESCRIPTION: Learn about Balcony tomatoes"},
+		{"Extract tags from the content.", "balcony, tomatoes, guide, tips, basics"},
+	}
+	for _, tt := range tests {
+		text, err := r.GenerateForTask(ctx, TaskLight, tt.system, prompt)
+		if err != nil || !strings.HasPrefix(text, tt.want) {
+			t.Errorf("%q: got %q, %v", tt.system, text, err)
+		}
+	}
+	if text, _ := r.GenerateForTask(ctx, TaskLight, "Write a meta description and keywords.", prompt); !strings.Contains(text, "\nKEYWORDS: balcony, tomatoes,") {
+		t.Errorf("seo keywords: %q", text)
+	}
+
+	var streamed strings.Builder
+	res, err := r.StreamForTask(ctx, TaskContent, "Write.", prompt, func(d string) error {
+		streamed.WriteString(d)
+		return nil
+	})
+	if err != nil || streamed.String() != res.Text || res.Text == "" {
+		t.Errorf("stream: %q vs %q, %v", streamed.String(), res.Text, err)
+	}
+}
+
+func TestFakeTemplates(t *testing.T) {
+	r := newFakeRegistry(FakeOptions{})
+	for _, typ := range []string{"header", "footer", "page", "article_loop", "search"} {
+		text, err := r.GenerateForTask(context.Background(), TaskTemplate, "Design.", "Template type: "+typ+"\n\nRequest: a calm look")
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if _, err := template.New(typ).Parse(text); err != nil {
+			t.Errorf("%s does not parse: %v", typ, err)
+		}
+		full := strings.Contains(text, "<html")
+		if full != (typ != "header" && typ != "footer") {
+			t.Errorf("%s: full document %v", typ, full)
+		}
+	}
+
+	// The prompt editor's test run has no "Template type:" line; the
+	// variables in the system prompt name the type.
+	text, _ := r.GenerateForTask(context.Background(), TaskTemplate, "TEMPLATE TYPE: Article Loop (post listing)", "A calm look")
+	if !strings.Contains(text, "{{range .Posts}}") {
+		t.Errorf("article loop from system prompt: %q", text)
+	}
+}
+
+func TestFakeImages(t *testing.T) {
+	r := newFakeRegistry(FakeOptions{})
+	res, err := r.GenerateImage(context.Background(), "", "a red fox", ImageOptions{Count: 2, Aspect: "1:1"})
+	if err != nil || res.Provider != FakeName || len(res.Images) != 2 {
+		t.Fatalf("generate: %+v, %v", res, err)
+	}
+	img, err := png.Decode(bytes.NewReader(res.Images[0].Data))
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 256 {
+		t.Errorf("size %v", b)
+	}
+	if bytes.Equal(res.Images[0].Data, res.Images[1].Data) {
+		t.Error("both images are the same")
+	}
+	again, _ := r.GenerateImage(context.Background(), "", "a red fox", ImageOptions{Count: 1, Aspect: "1:1"})
+	if !bytes.Equal(again.Images[0].Data, res.Images[0].Data) {
+		t.Error("same prompt gave a different image")
+	}
+
+	edited, err := r.EditImage(context.Background(), "", ImageEdit{Source: res.Images[0]}, ImageOptions{})
+	if err != nil || len(edited.Images) != 1 {
+		t.Errorf("edit: %+v, %v", edited, err)
+	}
+}
+
+func TestFakeModerationAndErrors(t *testing.T) {
+	ctx := context.Background()
+	r := newFakeRegistry(FakeOptions{})
+	if res, _ := r.CheckPrompt(ctx, "please fake_flagged this"); res.Safe || res.Categories[0] != "fake" {
+		t.Errorf("flag word: %+v", res)
+	}
+	if res, _ := r.CheckOutput(ctx, "harmless"); !res.Safe {
+		t.Error("harmless text flagged")
+	}
+
+	_, err := r.GenerateForTask(ctx, TaskLight, "x", "Please "+FakeErrorWord)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 500 {
+		t.Errorf("error word: %v", err)
+	}
+
+	// Every second call fails; the registry retries and succeeds.
+	r = newFakeRegistry(FakeOptions{FailEvery: 2, FlagWord: "banana"})
+	for i := range 3 {
+		if _, err := r.GenerateForTask(ctx, TaskLight, "x", "y"); err != nil {
+			t.Errorf("call %d: %v", i, err)
+		}
+	}
+	if _, err := NewFake(FakeOptions{FailEvery: 1}).Generate(ctx, "x", "y"); Classify(err) != ErrorRetryable {
+		t.Errorf("injected failure: %v", err)
+	}
+	if res, _ := r.CheckPrompt(ctx, "A BANANA"); res.Safe {
+		t.Error("custom flag word not flagged")
+	}
+
+	slow := NewFake(FakeOptions{Latency: time.Hour})
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := slow.Generate(cancelled, "x", "y"); !errors.Is(err, context.Canceled) {
+		t.Errorf("latency ignores cancellation: %v", err)
+	}
+}
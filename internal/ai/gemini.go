@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -37,28 +38,58 @@ func (p *geminiProvider) Name() string { return "gemini" }
 
 // Generate sends a generateContent request to the Gemini API using the default model.
 func (p *geminiProvider) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	return p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	res, err := p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	return res.Text, err
 }
 
 // GenerateWithModel sends a generateContent request using a specific model.
 // If model is empty, the provider's default model is used.
-func (p *geminiProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+func (p *geminiProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
+	return p.Chat(ctx, model, Prompt(systemPrompt, userPrompt))
+}
+
+// Chat sends a conversation as a generateContent request. System
+// messages become the system instruction.
+func (p *geminiProvider) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
 	if model == "" {
 		model = p.config.Model
 	}
 
+	system, contents := geminiContents(messages)
 	body := geminiRequest{
-		SystemInstruction: &geminiContent{
-			Parts: []geminiPart{{Text: systemPrompt}},
-		},
-		Contents: []geminiContent{
-			{Parts: []geminiPart{{Text: userPrompt}}},
+		SystemInstruction: system,
+		Contents:          contents,
+	}
+
+	return p.generate(ctx, model, body)
+}
+
+// ChatJSON sends a conversation with responseMimeType application/json
+// and schema as the response JSON Schema.
+func (p *geminiProvider) ChatJSON(ctx context.Context, model string, messages []Message, schema *Schema) (Result, error) {
+	if model == "" {
+		model = p.config.Model
+	}
+
+	system, contents := geminiContents(messages)
+	body := geminiRequest{
+		SystemInstruction: system,
+		Contents:          contents,
+		GenerationConfig: &geminiGenerationConfig{
+			ResponseMimeType:   "application/json",
+			ResponseJSONSchema: schema.Def,
 		},
 	}
 
+	return p.generate(ctx, model, body)
+}
+
+// generate posts a generateContent request and returns the text of the
+// first candidate.
+func (p *geminiProvider) generate(ctx context.Context, model string, body geminiRequest) (Result, error) {
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return "", fmt.Errorf("gemini marshal: %w", err)
+		return Result{}, fmt.Errorf("gemini marshal: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/v1beta/models/%s:generateContent",
@@ -66,7 +97,7 @@ func (p *geminiProvider) GenerateWithModel(ctx context.Context, model, systemPro
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
-		return "", fmt.Errorf("gemini request: %w", err)
+		return Result{}, fmt.Errorf("gemini request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -74,59 +105,185 @@ func (p *geminiProvider) GenerateWithModel(ctx context.Context, model, systemPro
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("gemini http: %w", err)
+		return Result{}, fmt.Errorf("gemini http: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("gemini read body: %w", err)
+		return Result{}, fmt.Errorf("gemini read body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("gemini API error (status %d): %s", resp.StatusCode, string(respBody))
+		return Result{}, &APIError{Source: "gemini", StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result geminiResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("gemini unmarshal: %w", err)
+		return Result{}, fmt.Errorf("gemini unmarshal: %w", err)
 	}
 
 	if len(result.Candidates) == 0 {
-		return "", fmt.Errorf("gemini: no candidates returned")
+		return Result{}, fmt.Errorf("gemini: no candidates returned")
 	}
 
 	// Extract text from the first candidate's parts.
 	for _, part := range result.Candidates[0].Content.Parts {
 		if part.Text != "" {
-			return part.Text, nil
+			return Result{Text: part.Text, Usage: result.usage(model)}, nil
+		}
+	}
+
+	return Result{}, fmt.Errorf("gemini: no text in response")
+}
+
+// StreamGenerate streams a completion via streamGenerateContent with
+// alt=sse; each event carries a partial generateContent response. If
+// model is empty, the provider's default model is used.
+func (p *geminiProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	return p.StreamChat(ctx, model, Prompt(systemPrompt, userPrompt), onDelta)
+}
+
+// StreamChat streams the next turn of a conversation.
+func (p *geminiProvider) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
+	if model == "" {
+		model = p.config.Model
+	}
+
+	system, contents := geminiContents(messages)
+	body := geminiRequest{
+		SystemInstruction: system,
+		Contents:          contents,
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Result{}, fmt.Errorf("gemini marshal: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1beta/models/%s:streamGenerateContent?alt=sse",
+		p.config.BaseURL, model)
+
+	newReq := func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-goog-api-key", p.config.APIKey)
+		return req, nil
+	}
+
+	var full strings.Builder
+	usage := Usage{Provider: "gemini", Model: model}
+	err = doStream(ctx, "gemini", newReq, func(ev sseEvent) error {
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(ev.Data), &chunk); err != nil {
+			return fmt.Errorf("unmarshal chunk: %w", err)
 		}
+		// Every chunk carries cumulative usage; the last one is final.
+		if chunk.UsageMetadata != nil {
+			usage = chunk.usage(model)
+		}
+		if len(chunk.Candidates) == 0 {
+			return nil
+		}
+		for _, part := range chunk.Candidates[0].Content.Parts {
+			if part.Text == "" {
+				continue
+			}
+			full.WriteString(part.Text)
+			if err := onDelta(part.Text); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	res := Result{Text: full.String(), Usage: usage}
+	if err != nil {
+		return res, err
+	}
+	if full.Len() == 0 {
+		return res, fmt.Errorf("gemini: no text in stream")
 	}
+	return res, nil
+}
 
-	return "", fmt.Errorf("gemini: no text in response")
+// DescribeImage sends img with a text prompt as a single user turn.
+func (p *geminiProvider) DescribeImage(ctx context.Context, model, systemPrompt, prompt string, img Image) (Result, error) {
+	if model == "" {
+		model = p.config.Model
+	}
+
+	body := geminiRequest{
+		SystemInstruction: &geminiContent{Parts: []geminiPart{{Text: systemPrompt}}},
+		Contents: []geminiContent{{
+			Role: "user",
+			Parts: []geminiPart{
+				{InlineData: &geminiInlineData{MimeType: img.MIMEType, Data: img.base64()}},
+				{Text: prompt},
+			},
+		}},
+	}
+
+	return p.generate(ctx, model, body)
 }
 
-// GenerateImage creates an image using Gemini's native generateContent API
+// GenerateImage creates images using Gemini's native generateContent API
 // with responseModalities set to IMAGE. Uses ModelImage from config
-// (e.g., "gemini-2.5-flash-image"). Returns image bytes and the content type.
-func (p *geminiProvider) GenerateImage(ctx context.Context, prompt string) ([]byte, string, error) {
+// (e.g., "gemini-2.5-flash-image").
+func (p *geminiProvider) GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([]Image, error) {
+	return p.generateImages(ctx, []geminiPart{{Text: "Generate an image of: " + opts.prompt(prompt)}}, opts)
+}
+
+// EditImage sends the source image with the change to make, or with a
+// request for a variation.
+func (p *geminiProvider) EditImage(ctx context.Context, edit ImageEdit, opts ImageOptions) ([]Image, error) {
+	instruction := edit.Prompt
+	if edit.IsVariation() {
+		instruction = variationPrompt
+	}
+	return p.generateImages(ctx, []geminiPart{
+		{InlineData: &geminiInlineData{MimeType: edit.Source.MIMEType, Data: edit.Source.base64()}},
+		{Text: opts.prompt(instruction)},
+	}, opts)
+}
+
+// generateImages makes one request per image. With a seed, image n uses
+// seed+n, so the images differ but the set is repeatable.
+func (p *geminiProvider) generateImages(ctx context.Context, parts []geminiPart, opts ImageOptions) ([]Image, error) {
 	model := p.config.ModelImage
 	if model == "" {
-		return nil, "", fmt.Errorf("gemini: image generation requires GEMINI_MODEL_IMAGE to be set")
+		return nil, fmt.Errorf("gemini: image generation requires GEMINI_MODEL_IMAGE to be set")
 	}
 
-	body := geminiImageRequest{
-		Contents: []geminiContent{
-			{Parts: []geminiPart{{Text: "Generate an image of: " + prompt}}},
-		},
-		GenerationConfig: geminiImageConfig{
-			ResponseModalities: []string{"IMAGE", "TEXT"},
-		},
+	images := make([]Image, 0, opts.Count)
+	for i := range opts.Count {
+		body := geminiImageRequest{
+			Contents: []geminiContent{{Parts: parts}},
+			GenerationConfig: geminiImageConfig{
+				ResponseModalities: []string{"IMAGE", "TEXT"},
+				ImageConfig:        &geminiImageOptions{AspectRatio: opts.Aspect},
+			},
+		}
+		if opts.Seed != 0 {
+			body.GenerationConfig.Seed = opts.Seed + int64(i)
+		}
+		img, err := p.requestImage(ctx, model, body)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
 	}
+	return images, nil
+}
 
+// requestImage sends one image request and returns the first image in
+// the reply.
+func (p *geminiProvider) requestImage(ctx context.Context, model string, body geminiImageRequest) (Image, error) {
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return nil, "", fmt.Errorf("gemini image marshal: %w", err)
+		return Image{}, fmt.Errorf("gemini image marshal: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/v1beta/models/%s:generateContent?key=%s",
@@ -134,7 +291,7 @@ func (p *geminiProvider) GenerateImage(ctx context.Context, prompt string) ([]by
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
-		return nil, "", fmt.Errorf("gemini image request: %w", err)
+		return Image{}, fmt.Errorf("gemini image request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -142,22 +299,22 @@ func (p *geminiProvider) GenerateImage(ctx context.Context, prompt string) ([]by
 	imgClient := &http.Client{Timeout: 120 * time.Second}
 	resp, err := imgClient.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("gemini image http: %w", err)
+		return Image{}, fmt.Errorf("gemini image http: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, "", fmt.Errorf("gemini image read body: %w", err)
+		return Image{}, fmt.Errorf("gemini image read body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("gemini image API error (status %d): %s", resp.StatusCode, string(respBody))
+		return Image{}, &APIError{Source: "gemini image", StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result geminiImageResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, "", fmt.Errorf("gemini image unmarshal: %w", err)
+		return Image{}, fmt.Errorf("gemini image unmarshal: %w", err)
 	}
 
 	// Extract the image data from the response parts.
@@ -166,33 +323,42 @@ func (p *geminiProvider) GenerateImage(ctx context.Context, prompt string) ([]by
 			if part.InlineData != nil && part.InlineData.Data != "" {
 				imgBytes, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
 				if err != nil {
-					return nil, "", fmt.Errorf("gemini image decode base64: %w", err)
+					return Image{}, fmt.Errorf("gemini image decode base64: %w", err)
 				}
 				contentType := part.InlineData.MimeType
 				if contentType == "" {
 					contentType = "image/png"
 				}
-				return imgBytes, contentType, nil
+				return Image{Data: imgBytes, MIMEType: contentType}, nil
 			}
 		}
 	}
 
-	return nil, "", fmt.Errorf("gemini image: no image data in response")
+	return Image{}, fmt.Errorf("gemini image: no image data in response")
 }
 
 // --- Gemini API types ---
 
 type geminiPart struct {
-	Text string `json:"text"`
+	Text       string            `json:"text,omitempty"`
+	InlineData *geminiInlineData `json:"inlineData,omitempty"` // Image input
 }
 
 type geminiContent struct {
+	Role  string       `json:"role,omitempty"` // "user" or "model"; unset for the system instruction
 	Parts []geminiPart `json:"parts"`
 }
 
 type geminiRequest struct {
-	SystemInstruction *geminiContent  `json:"system_instruction,omitempty"`
-	Contents          []geminiContent `json:"contents"`
+	SystemInstruction *geminiContent          `json:"system_instruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// geminiGenerationConfig requests JSON output constrained to a schema.
+type geminiGenerationConfig struct {
+	ResponseMimeType   string         `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema map[string]any `json:"responseJsonSchema,omitempty"`
 }
 
 type geminiCandidate struct {
@@ -200,18 +366,46 @@ type geminiCandidate struct {
 }
 
 type geminiResponse struct {
-	Candidates []geminiCandidate `json:"candidates"`
+	Candidates    []geminiCandidate    `json:"candidates"`
+	UsageMetadata *geminiUsageMetadata `json:"usageMetadata,omitempty"`
+	ModelVersion  string               `json:"modelVersion,omitempty"`
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+}
+
+// usage converts the response's usage metadata. Thinking tokens are
+// billed as output, so they are counted with the candidates.
+func (r geminiResponse) usage(requested string) Usage {
+	u := Usage{Provider: "gemini", Model: r.ModelVersion}
+	if u.Model == "" {
+		u.Model = requested
+	}
+	if r.UsageMetadata != nil {
+		u.InputTokens = r.UsageMetadata.PromptTokenCount
+		u.OutputTokens = r.UsageMetadata.CandidatesTokenCount + r.UsageMetadata.ThoughtsTokenCount
+	}
+	return u
 }
 
 // --- Gemini native image generation types ---
 
 type geminiImageRequest struct {
-	Contents         []geminiContent  `json:"contents"`
+	Contents         []geminiContent   `json:"contents"`
 	GenerationConfig geminiImageConfig `json:"generationConfig"`
 }
 
 type geminiImageConfig struct {
-	ResponseModalities []string `json:"responseModalities"`
+	ResponseModalities []string            `json:"responseModalities"`
+	ImageConfig        *geminiImageOptions `json:"imageConfig,omitempty"`
+	Seed               int64               `json:"seed,omitempty"`
+}
+
+type geminiImageOptions struct {
+	AspectRatio string `json:"aspectRatio,omitempty"`
 }
 
 type geminiInlineData struct {
@@ -7,26 +7,125 @@ package ai
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 )
 
+// MaxImages is the most images one generation request may ask for.
+const MaxImages = 4
+
+// ImageAspects are the supported aspect ratios, the default first.
+var ImageAspects = []string{"16:9", "1:1", "4:3", "3:4", "9:16"}
+
+// ImageOptions control image generation and editing. The zero value asks
+// for one 16:9 image.
+type ImageOptions struct {
+	Aspect         string // One of ImageAspects; anything else means the default
+	Count          int    // 1 to MaxImages; out of range values are clamped
+	Style          string // e.g. "watercolor", added to the prompt
+	NegativePrompt string // Things to leave out, added to the prompt
+	Seed           int64  // Repeatable results where the provider supports it; 0 means random
+}
+
+// Normalize returns the options with the defaults filled in and the
+// count clamped.
+func (o ImageOptions) Normalize() ImageOptions {
+	if !slices.Contains(ImageAspects, o.Aspect) {
+		o.Aspect = ImageAspects[0]
+	}
+	o.Count = min(max(o.Count, 1), MaxImages)
+	o.Style = strings.TrimSpace(o.Style)
+	o.NegativePrompt = strings.TrimSpace(o.NegativePrompt)
+	return o
+}
+
+// prompt adds the style and negative prompt to a prompt. Neither OpenAI
+// nor Gemini takes them as parameters, so they are written out.
+func (o ImageOptions) prompt(p string) string {
+	if o.Style != "" {
+		p += "\n\nStyle: " + o.Style
+	}
+	if o.NegativePrompt != "" {
+		p += "\n\nAvoid: " + o.NegativePrompt
+	}
+	return p
+}
+
 // ImageGenerator is an optional interface that AI providers can implement
 // to support image generation. Not all providers have this capability
 // (e.g., Claude and Mistral are text-only).
 type ImageGenerator interface {
-	// GenerateImage creates an image from a text prompt. Returns the raw
-	// image bytes and the MIME content type (e.g., "image/png").
-	GenerateImage(ctx context.Context, prompt string) ([]byte, string, error)
+	// GenerateImage creates opts.Count images from a text prompt. opts is
+	// normalized.
+	GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([]Image, error)
 }
 
-// GenerateImage creates an image using the specified provider. If provider
+// ImageEdit is a change to an existing image. Edits are by instruction
+// only: the model decides which parts of the image to change.
+type ImageEdit struct {
+	Source Image
+	Prompt string // The change to make; empty asks for a variation
+}
+
+// IsVariation reports whether the edit asks for a variation of the
+// source rather than a described change.
+func (e ImageEdit) IsVariation() bool {
+	return strings.TrimSpace(e.Prompt) == ""
+}
+
+// variationPrompt is the instruction for a variation, for providers that
+// make variations as edits.
+const variationPrompt = "Create a variation of this image with the same subject, composition and style."
+
+// imageExtension returns the file extension for an image MIME type.
+func imageExtension(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".png"
+	}
+}
+
+// ImageEditor is an optional interface for image generators that can
+// also change an existing image or make variations of it.
+type ImageEditor interface {
+	// EditImage creates opts.Count edited versions of edit.Source. opts
+	// is normalized.
+	EditImage(ctx context.Context, edit ImageEdit, opts ImageOptions) ([]Image, error)
+}
+
+// ImageResult is the outcome of an image generation or edit.
+type ImageResult struct {
+	Images   []Image
+	Provider string // The provider that made them
+}
+
+// GenerateImage creates images using the specified provider. If provider
 // is empty, uses the active text provider when it supports images, otherwise
 // falls back to any available image-capable provider.
-func (r *Registry) GenerateImage(ctx context.Context, provider, prompt string) ([]byte, string, error) {
-	ig, err := r.resolveImageGenerator(provider)
+func (r *Registry) GenerateImage(ctx context.Context, provider, prompt string, opts ImageOptions) (ImageResult, error) {
+	name, ig, err := resolveImageProvider[ImageGenerator](r, provider, "image generation")
+	if err != nil {
+		return ImageResult{}, err
+	}
+	images, err := ig.GenerateImage(ctx, prompt, opts.Normalize())
+	return ImageResult{Images: images, Provider: name}, err
+}
+
+// EditImage changes an image, or makes variations of it, using the
+// specified provider. Provider selection works as in GenerateImage,
+// among providers that can edit images.
+func (r *Registry) EditImage(ctx context.Context, provider string, edit ImageEdit, opts ImageOptions) (ImageResult, error) {
+	name, ie, err := resolveImageProvider[ImageEditor](r, provider, "image editing")
 	if err != nil {
-		return nil, "", err
+		return ImageResult{}, err
 	}
-	return ig.GenerateImage(ctx, prompt)
+	images, err := ie.EditImage(ctx, edit, opts.Normalize())
+	return ImageResult{Images: images, Provider: name}, err
 }
 
 // SupportsImageGeneration returns true if any registered provider can generate images.
@@ -34,25 +133,43 @@ func (r *Registry) SupportsImageGeneration() bool {
 	return len(r.ImageProviders()) > 0
 }
 
+// SupportsImageEditing returns true if any registered provider can edit images.
+func (r *Registry) SupportsImageEditing() bool {
+	return len(r.ImageEditProviders()) > 0
+}
+
 // ImageProviders returns the names of all registered providers that support
 // image generation. Used by the UI to populate provider selectors.
 func (r *Registry) ImageProviders() []string {
+	return providersWith[ImageGenerator](r)
+}
+
+// ImageEditProviders returns the names of all registered providers that
+// can edit images.
+func (r *Registry) ImageEditProviders() []string {
+	return providersWith[ImageEditor](r)
+}
+
+// providersWith returns the sorted names of the providers implementing T.
+func providersWith[T any](r *Registry) []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var names []string
 	for name, p := range r.providers {
-		if _, ok := p.(ImageGenerator); ok {
+		if _, ok := p.(T); ok {
 			names = append(names, name)
 		}
 	}
+	sort.Strings(names)
 	return names
 }
 
-// resolveImageGenerator finds the ImageGenerator for the requested provider.
-// If provider is empty, prefers the active text provider, then falls back
-// to any available image-capable provider.
-func (r *Registry) resolveImageGenerator(provider string) (ImageGenerator, error) {
+// resolveImageProvider finds the requested provider as a T, for the
+// capability described by what. If provider is empty, prefers the active
+// text provider, then falls back to the first capable provider by name.
+func resolveImageProvider[T any](r *Registry, provider, what string) (string, T, error) {
+	var none T
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -60,28 +177,31 @@ func (r *Registry) resolveImageGenerator(provider string) (ImageGenerator, error
 	if provider != "" {
 		p, ok := r.providers[provider]
 		if !ok {
-			return nil, fmt.Errorf("ai: provider %q is not available", provider)
+			return "", none, fmt.Errorf("ai: provider %q is not available", provider)
 		}
-		ig, ok := p.(ImageGenerator)
+		t, ok := p.(T)
 		if !ok {
-			return nil, fmt.Errorf("ai: provider %q does not support image generation", provider)
+			return "", none, fmt.Errorf("ai: provider %q does not support %s", provider, what)
 		}
-		return ig, nil
+		return provider, t, nil
 	}
 
 	// No provider specified — try the active text provider first.
-	if p, ok := r.providers[r.active]; ok {
-		if ig, ok := p.(ImageGenerator); ok {
-			return ig, nil
-		}
+	if t, ok := r.providers[r.active].(T); ok {
+		return r.active, t, nil
 	}
 
-	// Fallback: any provider that supports images.
-	for _, p := range r.providers {
-		if ig, ok := p.(ImageGenerator); ok {
-			return ig, nil
+	// Fallback: any provider with the capability.
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if t, ok := r.providers[name].(T); ok {
+			return name, t, nil
 		}
 	}
 
-	return nil, fmt.Errorf("ai: no provider supports image generation (requires OpenAI key for DALL-E or Gemini key with GEMINI_MODEL_IMAGE set)")
+	return "", none, fmt.Errorf("ai: no provider supports %s (requires OpenAI key for DALL-E or Gemini key with GEMINI_MODEL_IMAGE set)", what)
 }
@@ -0,0 +1,308 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// imageProvider is a mockProvider that also generates images.
+type imageProvider struct {
+	mockProvider
+	opts ImageOptions
+}
+
+func (p *imageProvider) GenerateImage(_ context.Context, prompt string, opts ImageOptions) ([]Image, error) {
+	p.opts = opts
+	return make([]Image, opts.Count), nil
+}
+
+// editingProvider generates and edits images.
+type editingProvider struct {
+	imageProvider
+	edit ImageEdit
+}
+
+func (p *editingProvider) EditImage(_ context.Context, edit ImageEdit, opts ImageOptions) ([]Image, error) {
+	p.edit, p.opts = edit, opts
+	return make([]Image, opts.Count), nil
+}
+
+func TestImageOptionsNormalize(t *testing.T) {
+	got := ImageOptions{Aspect: "2:1", Count: 9, Style: " ink "}.Normalize()
+	if got.Aspect != "16:9" || got.Count != MaxImages || got.Style != "ink" {
+		t.Errorf("got %+v", got)
+	}
+	if got := (ImageOptions{Aspect: "9:16"}).Normalize(); got.Aspect != "9:16" || got.Count != 1 {
+		t.Errorf("got %+v", got)
+	}
+
+	p := ImageOptions{Style: "watercolor", NegativePrompt: "text"}.prompt("A fox")
+	if p != "A fox\n\nStyle: watercolor\n\nAvoid: text" {
+		t.Errorf("prompt: %q", p)
+	}
+}
+
+func TestRegistryImageProviders(t *testing.T) {
+	text := &mockProvider{name: "claude"}
+	gen := &imageProvider{mockProvider: mockProvider{name: "gemini"}}
+	edit := &editingProvider{imageProvider: imageProvider{mockProvider: mockProvider{name: "openai"}}}
+	reg := newFailoverRegistry(text, gen, edit)
+
+	if got := strings.Join(reg.ImageProviders(), ","); got != "gemini,openai" {
+		t.Errorf("ImageProviders: %s", got)
+	}
+	if got := strings.Join(reg.ImageEditProviders(), ","); got != "openai" {
+		t.Errorf("ImageEditProviders: %s", got)
+	}
+
+	// The active provider has no images, so the first by name is used,
+	// with normalized options.
+	res, err := reg.GenerateImage(context.Background(), "", "a fox", ImageOptions{Count: 3})
+	if err != nil || res.Provider != "gemini" || len(res.Images) != 3 || gen.opts.Aspect != "16:9" {
+		t.Errorf("GenerateImage: %+v, %v, opts %+v", res, err, gen.opts)
+	}
+
+	res, err = reg.EditImage(context.Background(), "", ImageEdit{Prompt: "add a hat"}, ImageOptions{})
+	if err != nil || res.Provider != "openai" || len(res.Images) != 1 || edit.edit.Prompt != "add a hat" {
+		t.Errorf("EditImage: %+v, %v", res, err)
+	}
+
+	if _, err := reg.EditImage(context.Background(), "gemini", ImageEdit{}, ImageOptions{}); err == nil {
+		t.Error("EditImage with a provider that can't edit: no error")
+	}
+	if _, err := reg.GenerateImage(context.Background(), "claude", "a fox", ImageOptions{}); err == nil {
+		t.Error("GenerateImage with a text-only provider: no error")
+	}
+}
+
+// imageServer answers OpenAI Images API requests with n images (or one
+// for variations), recording each request.
+type imageServer struct {
+	mu       sync.Mutex
+	paths    []string
+	requests []map[string]string
+	files    map[string]string // Form file field -> content type
+	uploads  map[string][]byte // Form file field -> contents
+}
+
+func (s *imageServer) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		fields := map[string]string{}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("parse form: %v", err)
+			}
+			for k, v := range r.MultipartForm.Value {
+				fields[k] = v[0]
+			}
+			s.files, s.uploads = map[string]string{}, map[string][]byte{}
+			for k, v := range r.MultipartForm.File {
+				s.files[k] = v[0].Header.Get("Content-Type")
+				f, _ := v[0].Open()
+				s.uploads[k], _ = io.ReadAll(f)
+				f.Close()
+			}
+		} else {
+			var body map[string]any
+			b, _ := io.ReadAll(r.Body)
+			json.Unmarshal(b, &body)
+			for k, v := range body {
+				fields[k] = fmt.Sprint(v)
+			}
+		}
+		s.paths = append(s.paths, r.URL.Path)
+		s.requests = append(s.requests, fields)
+
+		n := 1
+		if fields["n"] == "2" {
+			n = 2
+		}
+		var resp openAIImageResponse
+		for range n {
+			resp.Data = append(resp.Data, openAIImageData{B64JSON: base64.StdEncoding.EncodeToString([]byte("png"))})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func TestOpenAIGenerateImage(t *testing.T) {
+	s := &imageServer{}
+	srv := httptest.NewServer(s.handle(t))
+	defer srv.Close()
+
+	// DALL-E 3 makes one image per request.
+	p := newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL})
+	images, err := p.GenerateImage(context.Background(), "a fox", ImageOptions{Aspect: "9:16", Count: 2, Style: "ink"})
+	if err != nil || len(images) != 2 || string(images[1].Data) != "png" || images[1].MIMEType != "image/png" {
+		t.Fatalf("GenerateImage: %v, %v", images, err)
+	}
+	if len(s.requests) != 2 {
+		t.Fatalf("requests: %d", len(s.requests))
+	}
+	req := s.requests[0]
+	if req["model"] != "dall-e-3" || req["n"] != "1" || req["size"] != "1024x1792" ||
+		req["response_format"] != "b64_json" || req["prompt"] != "a fox\n\nStyle: ink" {
+		t.Errorf("dall-e-3 request: %v", req)
+	}
+
+	// gpt-image models take n, other sizes and no response format.
+	s.requests = nil
+	p = newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL, ModelImage: "gpt-image-1"})
+	if images, err := p.GenerateImage(context.Background(), "a fox", ImageOptions{Aspect: "16:9", Count: 2}); err != nil || len(images) != 2 {
+		t.Fatalf("GenerateImage: %v, %v", images, err)
+	}
+	req = s.requests[0]
+	if len(s.requests) != 1 || req["n"] != "2" || req["size"] != "1536x1024" || req["response_format"] != "" || req["quality"] != "" {
+		t.Errorf("gpt-image requests: %v", s.requests)
+	}
+}
+
+func TestOpenAIEditImage(t *testing.T) {
+	s := &imageServer{}
+	srv := httptest.NewServer(s.handle(t))
+	defer srv.Close()
+	src := Image{Data: []byte("src"), MIMEType: "image/png"}
+	opts := ImageOptions{Aspect: "1:1", Count: 1}
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL, ModelImage: "gpt-image-1"})
+	if _, err := p.EditImage(context.Background(), ImageEdit{Source: src, Prompt: "add a hat"}, opts); err != nil {
+		t.Fatalf("EditImage: %v", err)
+	}
+	req := s.requests[0]
+	if s.paths[0] != "/images/edits" || req["model"] != "gpt-image-1" || req["prompt"] != "add a hat" || req["size"] != "1024x1024" {
+		t.Errorf("edit: %s %v", s.paths[0], req)
+	}
+	if s.files["image"] != "image/png" || len(s.files) != 1 {
+		t.Errorf("files: %v", s.files)
+	}
+
+	// gpt-image variations are edits with a variation instruction.
+	p.EditImage(context.Background(), ImageEdit{Source: src}, opts)
+	if s.paths[1] != "/images/edits" || s.requests[1]["prompt"] != variationPrompt {
+		t.Errorf("gpt-image variation: %s %v", s.paths[1], s.requests[1])
+	}
+
+	// DALL-E variations use the variations endpoint with dall-e-2.
+	p = newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL})
+	p.EditImage(context.Background(), ImageEdit{Source: testJPEG(t, 64, 64)}, ImageOptions{Aspect: "16:9", Count: 1})
+	req = s.requests[2]
+	if s.paths[2] != "/images/variations" || req["model"] != "dall-e-2" || req["size"] != "1024x1024" || req["prompt"] != "" {
+		t.Errorf("dall-e variation: %s %v", s.paths[2], req)
+	}
+}
+
+// testJPEG returns a w×h JPEG, red on the left half and blue on the right.
+func testJPEG(t *testing.T, w, h int) Image {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := range w {
+		for y := range h {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return Image{Data: buf.Bytes(), MIMEType: "image/jpeg"}
+}
+
+func TestOpenAIEditImageConvertsSourceForDallE2(t *testing.T) {
+	s := &imageServer{}
+	srv := httptest.NewServer(s.handle(t))
+	defer srv.Close()
+
+	// A wide JPEG is sent to dall-e-2 as its centre square, as a PNG.
+	p := newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL})
+	if _, err := p.EditImage(context.Background(), ImageEdit{Source: testJPEG(t, 1600, 900), Prompt: "add a hat"}, ImageOptions{Aspect: "1:1", Count: 1}); err != nil {
+		t.Fatalf("EditImage: %v", err)
+	}
+	if s.requests[0]["model"] != "dall-e-2" || s.files["image"] != "image/png" {
+		t.Fatalf("request %v, files %v", s.requests[0], s.files)
+	}
+	sent, err := png.Decode(bytes.NewReader(s.uploads["image"]))
+	if err != nil {
+		t.Fatalf("source is not a PNG: %v", err)
+	}
+	if b := sent.Bounds(); b.Dx() != 900 || b.Dy() != 900 || len(s.uploads["image"]) >= dallE2MaxBytes {
+		t.Errorf("source %v, %d bytes", b, len(s.uploads["image"]))
+	}
+	if _, ok := sent.(*image.RGBA); !ok {
+		t.Errorf("source is %T, want RGBA", sent)
+	}
+	// Both halves survive the crop.
+	if r, _, b, _ := sent.At(100, 450).RGBA(); r < b {
+		t.Error("left of the crop is not red")
+	}
+	if r, _, b, _ := sent.At(800, 450).RGBA(); b < r {
+		t.Error("right of the crop is not blue")
+	}
+
+	// gpt-image models take the source as it is.
+	src := testJPEG(t, 300, 200)
+	p = newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL, ModelImage: "gpt-image-1"})
+	p.EditImage(context.Background(), ImageEdit{Source: src, Prompt: "add a hat"}, ImageOptions{Aspect: "1:1", Count: 1})
+	if s.files["image"] != "image/jpeg" || !bytes.Equal(s.uploads["image"], src.Data) {
+		t.Errorf("gpt-image source changed: %v", s.files)
+	}
+
+	if _, err := newOpenAI(ProviderConfig{APIKey: "k", BaseURL: srv.URL}).EditImage(context.Background(), ImageEdit{Source: Image{Data: []byte("x")}}, ImageOptions{Count: 1}); err == nil {
+		t.Error("undecodable source: no error")
+	}
+}
+
+func TestGeminiImages(t *testing.T) {
+	var mu sync.Mutex
+	var reqs []geminiImageRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req geminiImageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		reqs = append(reqs, req)
+		mu.Unlock()
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"ok"},{"inlineData":{"mimeType":"image/jpeg","data":"anBn"}}]}}]}`))
+	}))
+	defer srv.Close()
+
+	p := newGemini(ProviderConfig{APIKey: "k", BaseURL: srv.URL, ModelImage: "gemini-image"})
+	images, err := p.GenerateImage(context.Background(), "a fox", ImageOptions{Aspect: "4:3", Count: 2, Seed: 7})
+	if err != nil || len(images) != 2 || images[0].MIMEType != "image/jpeg" || string(images[0].Data) != "jpg" {
+		t.Fatalf("GenerateImage: %v, %v", images, err)
+	}
+	cfg := reqs[1].GenerationConfig
+	if cfg.ImageConfig == nil || cfg.ImageConfig.AspectRatio != "4:3" || reqs[0].GenerationConfig.Seed != 7 || cfg.Seed != 8 {
+		t.Errorf("generation config: %+v, %+v", reqs[0].GenerationConfig, cfg)
+	}
+
+	src := Image{Data: []byte("src"), MIMEType: "image/webp"}
+	if _, err := p.EditImage(context.Background(), ImageEdit{Source: src}, ImageOptions{Aspect: "1:1", Count: 1}); err != nil {
+		t.Fatalf("EditImage: %v", err)
+	}
+	parts := reqs[2].Contents[0].Parts
+	if len(parts) != 2 || parts[0].InlineData == nil || parts[0].InlineData.MimeType != "image/webp" || parts[1].Text != variationPrompt {
+		t.Errorf("edit parts: %+v", parts)
+	}
+}
@@ -34,25 +34,64 @@ func (p *mistralProvider) Name() string { return "mistral" }
 
 // Generate sends a chat completion request to Mistral's API using the default model.
 func (p *mistralProvider) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	return p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	res, err := p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	return res.Text, err
 }
 
 // GenerateWithModel sends a chat completion request using a specific model.
 // If model is empty, the provider's default model is used.
-func (p *mistralProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+func (p *mistralProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
+	return p.Chat(ctx, model, Prompt(systemPrompt, userPrompt))
+}
+
+// Chat sends a conversation as a chat completion request.
+func (p *mistralProvider) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
 	if model == "" {
 		model = p.inner.config.Model
 	}
 
-	messages := []openAIMessage{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userPrompt},
+	body := openAIRequest{
+		Model:    model,
+		Messages: openAIMessages(messages),
+	}
+
+	return p.inner.doChat(ctx, "mistral", body)
+}
+
+// ChatJSON sends a conversation with Mistral's json_schema response
+// format, which mirrors OpenAI's.
+func (p *mistralProvider) ChatJSON(ctx context.Context, model string, messages []Message, schema *Schema) (Result, error) {
+	if model == "" {
+		model = p.inner.config.Model
+	}
+
+	body := openAIRequest{
+		Model:          model,
+		Messages:       openAIMessages(messages),
+		ResponseFormat: jsonSchemaFormat(schema),
+	}
+
+	return p.inner.doChat(ctx, "mistral", body)
+}
+
+// StreamGenerate streams a chat completion from Mistral. The streaming
+// format is the same as OpenAI's; Mistral always includes usage in the
+// final chunk.
+func (p *mistralProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	return p.StreamChat(ctx, model, Prompt(systemPrompt, userPrompt), onDelta)
+}
+
+// StreamChat streams the next turn of a conversation.
+func (p *mistralProvider) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
+	if model == "" {
+		model = p.inner.config.Model
 	}
 
 	body := openAIRequest{
 		Model:    model,
-		Messages: messages,
+		Messages: openAIMessages(messages),
+		Stream:   true,
 	}
 
-	return p.inner.doChat(ctx, body)
+	return p.inner.streamChat(ctx, "mistral", body, onDelta)
 }
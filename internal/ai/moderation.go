@@ -85,7 +85,7 @@ func (m *openAIModerator) CheckSafety(ctx context.Context, text string) (*Modera
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("moderation API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{Source: "moderation", StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result openAIModResponse
@@ -175,7 +175,7 @@ func (m *mistralModerator) CheckSafety(ctx context.Context, text string) (*Moder
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("mistral moderation API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{Source: "mistral moderation", StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result mistralModResponse
@@ -231,7 +231,7 @@ func (m *fallbackModerator) CheckSafety(ctx context.Context, text string) (*Mode
 			return result, nil
 		}
 		// On auth/permission errors, permanently switch to secondary.
-		if strings.Contains(err.Error(), "status 403") || strings.Contains(err.Error(), "status 401") {
+		if Classify(err) == ErrorAuth {
 			slog.Warn("primary moderator returned auth error, switching to fallback",
 				"error", err)
 			m.usePrimary.Store(false)
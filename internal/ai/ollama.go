@@ -0,0 +1,221 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ollamaProvider implements the Provider interface using Ollama's native
+// chat API (POST /api/chat). The API key is optional; it is sent as a
+// bearer token for instances behind an authenticating proxy.
+type ollamaProvider struct {
+	name   string
+	config ProviderConfig
+	client *http.Client
+}
+
+// newOllama creates an Ollama provider. BaseURL defaults to the local
+// Ollama daemon.
+func newOllama(name string, cfg ProviderConfig) *ollamaProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "http://localhost:11434"
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &ollamaProvider{
+		name:   name,
+		config: cfg,
+		client: &http.Client{Timeout: localTimeout},
+	}
+}
+
+func (p *ollamaProvider) Name() string { return p.name }
+
+// Generate sends a chat request using the default model.
+func (p *ollamaProvider) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	res, err := p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	return res.Text, err
+}
+
+// GenerateWithModel sends a chat request using a specific model.
+// If model is empty, the provider's default model is used.
+func (p *ollamaProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
+	return p.Chat(ctx, model, Prompt(systemPrompt, userPrompt))
+}
+
+// Chat sends a conversation as a chat request.
+func (p *ollamaProvider) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
+	return p.chat(ctx, p.request(model, messages, false))
+}
+
+// ChatJSON sends a conversation with schema as the request's format,
+// which constrains the reply to that JSON Schema.
+func (p *ollamaProvider) ChatJSON(ctx context.Context, model string, messages []Message, schema *Schema) (Result, error) {
+	body := p.request(model, messages, false)
+	body.Format = schema.Def
+	return p.chat(ctx, body)
+}
+
+// chat performs a non-streamed chat call.
+func (p *ollamaProvider) chat(ctx context.Context, body ollamaRequest) (Result, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s marshal: %w", p.name, err)
+	}
+
+	req, err := p.newRequest(ctx, payload)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s request: %w", p.name, err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s http: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s read body: %w", p.name, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, &APIError{Source: p.name, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	var result ollamaResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return Result{}, fmt.Errorf("%s unmarshal: %w", p.name, err)
+	}
+	if result.Message.Content == "" {
+		return Result{}, fmt.Errorf("%s: no text in response", p.name)
+	}
+
+	return Result{Text: result.Message.Content, Usage: result.usage(p.name, body.Model)}, nil
+}
+
+// StreamGenerate streams a chat completion. Ollama streams newline-
+// delimited JSON rather than SSE; the last object has done=true and
+// carries the token counts.
+func (p *ollamaProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	return p.StreamChat(ctx, model, Prompt(systemPrompt, userPrompt), onDelta)
+}
+
+// StreamChat streams the next turn of a conversation.
+func (p *ollamaProvider) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
+	body := p.request(model, messages, true)
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s marshal: %w", p.name, err)
+	}
+
+	var full strings.Builder
+	usage := Usage{Provider: p.name, Model: body.Model}
+	newReq := func(ctx context.Context) (*http.Request, error) {
+		return p.newRequest(ctx, payload)
+	}
+	err = streamBody(ctx, p.name, "application/x-ndjson", newReq, func(r io.Reader, touch func()) error {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for sc.Scan() {
+			touch()
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			var chunk ollamaResponse
+			if err := json.Unmarshal(line, &chunk); err != nil {
+				return fmt.Errorf("unmarshal chunk: %w", err)
+			}
+			if chunk.Error != "" {
+				return errors.New("API error: " + chunk.Error)
+			}
+			if chunk.Done {
+				usage = chunk.usage(p.name, body.Model)
+				return errStreamDone
+			}
+			if chunk.Message.Content == "" {
+				continue
+			}
+			full.WriteString(chunk.Message.Content)
+			if err := onDelta(chunk.Message.Content); err != nil {
+				return err
+			}
+		}
+		return sc.Err()
+	})
+	res := Result{Text: full.String(), Usage: usage}
+	if err != nil {
+		return res, err
+	}
+	if full.Len() == 0 {
+		return res, fmt.Errorf("%s: no text in stream", p.name)
+	}
+	return res, nil
+}
+
+// request builds a chat request, resolving an empty model to the default.
+func (p *ollamaProvider) request(model string, messages []Message, stream bool) ollamaRequest {
+	if model == "" {
+		model = p.config.Model
+	}
+	return ollamaRequest{
+		Model:    model,
+		Messages: openAIMessages(messages),
+		Stream:   stream,
+	}
+}
+
+// newRequest builds the POST /api/chat request for payload.
+func (p *ollamaProvider) newRequest(ctx context.Context, payload []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.BaseURL+"/api/chat", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+	return req, nil
+}
+
+// --- Ollama chat API types ---
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream"` // Ollama streams unless told otherwise
+	Format   map[string]any  `json:"format,omitempty"`
+}
+
+// ollamaResponse is the non-streamed response and also each streamed
+// line. Token counts are only set once done is true.
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	Message         openAIMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// usage converts the response's token counts; requested is the model
+// that was asked for, used when the response doesn't name one.
+func (r ollamaResponse) usage(provider, requested string) Usage {
+	u := Usage{Provider: provider, Model: r.Model, InputTokens: r.PromptEvalCount, OutputTokens: r.EvalCount}
+	if u.Model == "" {
+		u.Model = requested
+	}
+	return u
+}
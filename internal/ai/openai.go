@@ -10,9 +10,19 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"image"
+	_ "image/jpeg" // Edit sources may be JPEG
+	"image/png"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Edit sources may be WebP
 )
 
 // openAIProvider implements the Provider interface using the OpenAI
@@ -20,6 +30,7 @@ import (
 type openAIProvider struct {
 	config ProviderConfig
 	client *http.Client
+	prefix string // Error prefix for chat calls; "" means "openai"
 }
 
 // newOpenAI creates a new OpenAI provider.
@@ -37,102 +48,288 @@ func (p *openAIProvider) Name() string { return "openai" }
 
 // Generate sends a chat completion request to OpenAI using the default model.
 func (p *openAIProvider) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
-	return p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	res, err := p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	return res.Text, err
 }
 
 // GenerateWithModel sends a chat completion request using a specific model.
 // If model is empty, the provider's default model is used.
-func (p *openAIProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+func (p *openAIProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
+	return p.Chat(ctx, model, Prompt(systemPrompt, userPrompt))
+}
+
+// Chat sends a conversation as a chat completion request. If model is
+// empty, the provider's default model is used.
+func (p *openAIProvider) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
 	if model == "" {
 		model = p.config.Model
 	}
 
-	messages := []openAIMessage{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userPrompt},
+	body := openAIRequest{
+		Model:    model,
+		Messages: openAIMessages(messages),
+	}
+
+	return p.doChat(ctx, "openai", body)
+}
+
+// ChatJSON sends a conversation with a json_schema response format in
+// strict mode, so the reply always matches schema.
+func (p *openAIProvider) ChatJSON(ctx context.Context, model string, messages []Message, schema *Schema) (Result, error) {
+	if model == "" {
+		model = p.config.Model
 	}
 
 	body := openAIRequest{
-		Model:    model,
-		Messages: messages,
+		Model:          model,
+		Messages:       openAIMessages(messages),
+		ResponseFormat: jsonSchemaFormat(schema),
 	}
 
-	return p.doChat(ctx, body)
+	return p.doChat(ctx, "openai", body)
 }
 
 // doChat performs the HTTP call to the chat completions endpoint.
-// Shared between OpenAI and Mistral (same API format).
-func (p *openAIProvider) doChat(ctx context.Context, body openAIRequest) (string, error) {
+// Shared between OpenAI, Mistral and OpenAI-compatible servers (same API
+// format); name identifies the provider in the returned usage.
+func (p *openAIProvider) doChat(ctx context.Context, name string, body openAIRequest) (Result, error) {
+	prefix := p.errPrefix()
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return "", fmt.Errorf("openai marshal: %w", err)
+		return Result{}, fmt.Errorf("%s marshal: %w", prefix, err)
 	}
 
 	url := p.config.BaseURL + "/chat/completions"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
-		return "", fmt.Errorf("openai request: %w", err)
+		return Result{}, fmt.Errorf("%s request: %w", prefix, err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	p.authorize(req)
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("openai http: %w", err)
+		return Result{}, fmt.Errorf("%s http: %w", prefix, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("openai read body: %w", err)
+		return Result{}, fmt.Errorf("%s read body: %w", prefix, err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("openai API error (status %d): %s", resp.StatusCode, string(respBody))
+		return Result{}, &APIError{Source: prefix, StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result openAIResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("openai unmarshal: %w", err)
+		return Result{}, fmt.Errorf("%s unmarshal: %w", prefix, err)
 	}
 
 	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("openai: no choices returned")
+		return Result{}, fmt.Errorf("%s: no choices returned", prefix)
 	}
 
-	return result.Choices[0].Message.Content, nil
+	return Result{
+		Text:  result.Choices[0].Message.Content,
+		Usage: result.usage(name, body.Model),
+	}, nil
+}
+
+// errPrefix returns the prefix for chat error messages.
+func (p *openAIProvider) errPrefix() string {
+	if p.prefix != "" {
+		return p.prefix
+	}
+	return "openai"
+}
+
+// authorize sets the bearer token. Local OpenAI-compatible servers often
+// run without one, so it is omitted when no key is configured.
+func (p *openAIProvider) authorize(req *http.Request) {
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
 }
 
-// GenerateImage creates an image using the OpenAI DALL-E API.
-// Returns PNG image bytes and the content type. Uses ModelImage from
-// config (defaults to "dall-e-3").
-func (p *openAIProvider) GenerateImage(ctx context.Context, prompt string) ([]byte, string, error) {
-	model := p.config.ModelImage
+// StreamGenerate streams a chat completion, calling onDelta with each
+// content fragment. If model is empty, the provider's default model is used.
+func (p *openAIProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	return p.StreamChat(ctx, model, Prompt(systemPrompt, userPrompt), onDelta)
+}
+
+// StreamChat streams the next turn of a conversation.
+func (p *openAIProvider) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
 	if model == "" {
-		model = "dall-e-3"
+		model = p.config.Model
 	}
-	body := openAIImageRequest{
-		Model:          model,
-		Prompt:         prompt,
-		N:              1,
-		Size:           "1792x1024",
-		ResponseFormat: "b64_json",
-		Quality:        "standard",
+
+	body := openAIRequest{
+		Model:    model,
+		Messages: openAIMessages(messages),
+		Stream:   true,
+		// Ask for a final chunk carrying token usage (OpenAI-specific).
+		StreamOptions: &openAIStreamOptions{IncludeUsage: true},
 	}
 
+	return p.streamChat(ctx, "openai", body, onDelta)
+}
+
+// streamChat performs a streamed chat completions call. Shared between
+// OpenAI, Mistral and OpenAI-compatible servers; name prefixes error messages.
+func (p *openAIProvider) streamChat(ctx context.Context, name string, body openAIRequest, onDelta DeltaFunc) (Result, error) {
 	payload, err := json.Marshal(body)
 	if err != nil {
-		return nil, "", fmt.Errorf("openai image marshal: %w", err)
+		return Result{}, fmt.Errorf("%s marshal: %w", name, err)
 	}
 
-	url := p.config.BaseURL + "/images/generations"
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	newReq := func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.BaseURL+"/chat/completions", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		p.authorize(req)
+		return req, nil
+	}
+
+	var full strings.Builder
+	usage := Usage{Provider: name, Model: body.Model}
+	err = doStream(ctx, name, newReq, func(ev sseEvent) error {
+		if ev.Data == "[DONE]" {
+			return errStreamDone
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(ev.Data), &chunk); err != nil {
+			return fmt.Errorf("unmarshal chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.openAIResponse.usage(name, body.Model)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		full.WriteString(delta)
+		return onDelta(delta)
+	})
+	res := Result{Text: full.String(), Usage: usage}
 	if err != nil {
-		return nil, "", fmt.Errorf("openai image request: %w", err)
+		return res, err
+	}
+	if full.Len() == 0 {
+		return res, fmt.Errorf("%s: empty stream", name)
 	}
+	return res, nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// GenerateImage creates images using the OpenAI Images API. Uses
+// ModelImage from config (defaults to "dall-e-3"). DALL-E 3 makes one
+// image per request, so more are requested one at a time. OpenAI has no
+// seed parameter; opts.Seed is ignored.
+func (p *openAIProvider) GenerateImage(ctx context.Context, prompt string, opts ImageOptions) ([]Image, error) {
+	model := p.imageModel()
+	var images []Image
+	for len(images) < opts.Count {
+		n := opts.Count - len(images)
+		if model == "dall-e-3" {
+			n = 1
+		}
+		body := openAIImageRequest{
+			Model:  model,
+			Prompt: opts.prompt(prompt),
+			N:      n,
+			Size:   openAIImageSize(model, opts.Aspect),
+		}
+		// gpt-image models always return base64 and have their own
+		// quality levels.
+		if !isGPTImage(model) {
+			body.ResponseFormat = "b64_json"
+			body.Quality = "standard"
+		}
+
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("openai image marshal: %w", err)
+		}
+		batch, err := p.doImage(ctx, "/images/generations", "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, batch...)
+	}
+	return images, nil
+}
+
+// EditImage changes an image through the Images API edits endpoint, or
+// makes variations of it. gpt-image models do both as edits. Otherwise
+// edits use dall-e-2, and variations its variations endpoint; both only
+// accept square PNG images, so the source is converted first (see
+// squarePNG).
+func (p *openAIProvider) EditImage(ctx context.Context, edit ImageEdit, opts ImageOptions) ([]Image, error) {
+	model, path, prompt := p.imageModel(), "/images/edits", edit.Prompt
+	switch {
+	case isGPTImage(model) && edit.IsVariation():
+		prompt = variationPrompt
+	case edit.IsVariation():
+		model, path = "dall-e-2", "/images/variations"
+	case !isGPTImage(model):
+		model = "dall-e-2"
+	}
+
+	source := edit.Source
+	if model == "dall-e-2" {
+		var err error
+		if source, err = squarePNG(source); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("model", model)
+	if path == "/images/edits" {
+		mw.WriteField("prompt", opts.prompt(prompt))
+	}
+	mw.WriteField("n", strconv.Itoa(opts.Count))
+	mw.WriteField("size", openAIImageSize(model, opts.Aspect))
+	if !isGPTImage(model) {
+		mw.WriteField("response_format", "b64_json")
+	}
+	if err := writeImagePart(mw, "image", source); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("openai image edit form: %w", err)
+	}
+
+	return p.doImage(ctx, path, mw.FormDataContentType(), &buf)
+}
+
+// imageModel returns the configured image model, "dall-e-3" by default.
+func (p *openAIProvider) imageModel() string {
+	if p.config.ModelImage == "" {
+		return "dall-e-3"
+	}
+	return p.config.ModelImage
+}
+
+// doImage posts an Images API request to path and decodes the images in
+// the reply.
+func (p *openAIProvider) doImage(ctx context.Context, path, contentType string, body io.Reader) ([]Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.BaseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("openai image request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
 
 	// Image generation can take up to 60 seconds; use a dedicated client
@@ -140,57 +337,241 @@ func (p *openAIProvider) GenerateImage(ctx context.Context, prompt string) ([]by
 	imgClient := &http.Client{Timeout: 120 * time.Second}
 	resp, err := imgClient.Do(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("openai image http: %w", err)
+		return nil, fmt.Errorf("openai image http: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, "", fmt.Errorf("openai image read body: %w", err)
+		return nil, fmt.Errorf("openai image read body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("openai image API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{Source: "openai image", StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result openAIImageResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, "", fmt.Errorf("openai image unmarshal: %w", err)
+		return nil, fmt.Errorf("openai image unmarshal: %w", err)
 	}
 
 	if len(result.Data) == 0 {
-		return nil, "", fmt.Errorf("openai image: no images returned")
+		return nil, fmt.Errorf("openai image: no images returned")
+	}
+
+	images := make([]Image, len(result.Data))
+	for i, d := range result.Data {
+		data, err := base64.StdEncoding.DecodeString(d.B64JSON)
+		if err != nil {
+			return nil, fmt.Errorf("openai image decode base64: %w", err)
+		}
+		images[i] = Image{Data: data, MIMEType: "image/png"}
+	}
+	return images, nil
+}
+
+// isGPTImage reports whether model is one of the gpt-image models, which
+// differ from DALL-E in sizes and parameters.
+func isGPTImage(model string) bool {
+	return strings.HasPrefix(model, "gpt-image")
+}
+
+// openAIImageSize returns the size closest to aspect that model accepts.
+// dall-e-2 only makes square images.
+func openAIImageSize(model, aspect string) string {
+	landscape, portrait := "1792x1024", "1024x1792"
+	switch {
+	case isGPTImage(model):
+		landscape, portrait = "1536x1024", "1024x1536"
+	case model == "dall-e-2":
+		return "1024x1024"
+	}
+	switch aspect {
+	case "1:1":
+		return "1024x1024"
+	case "3:4", "9:16":
+		return portrait
+	default:
+		return landscape
+	}
+}
+
+// dallE2MaxBytes is the largest image dall-e-2 accepts for edits and
+// variations.
+const dallE2MaxBytes = 4 << 20
+
+// squarePNG converts img to what dall-e-2 accepts: a square RGBA PNG under
+// 4 MB. Other shapes are cropped to their centre square rather than
+// padded, since dall-e-2 repaints transparent areas. Images are scaled to
+// at most 1024 pixels, the largest size it returns, and halved again while
+// the PNG is too big.
+func squarePNG(img Image) (Image, error) {
+	src, _, err := image.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		return Image{}, fmt.Errorf("openai image edit: decode source: %w", err)
 	}
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+
+	for size := min(side, 1024); ; size /= 2 {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, dst); err != nil {
+			return Image{}, fmt.Errorf("openai image edit: encode source: %w", err)
+		}
+		if buf.Len() < dallE2MaxBytes || size <= 256 {
+			return Image{Data: buf.Bytes(), MIMEType: "image/png"}, nil
+		}
+	}
+}
 
-	imgBytes, err := base64.StdEncoding.DecodeString(result.Data[0].B64JSON)
+// writeImagePart adds img to a multipart form as a file field.
+func writeImagePart(mw *multipart.Writer, field string, img Image) error {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s%s"`, field, field, imageExtension(img.MIMEType)))
+	h.Set("Content-Type", img.MIMEType)
+	w, err := mw.CreatePart(h)
 	if err != nil {
-		return nil, "", fmt.Errorf("openai image decode base64: %w", err)
+		return fmt.Errorf("openai image %s part: %w", field, err)
+	}
+	if _, err := w.Write(img.Data); err != nil {
+		return fmt.Errorf("openai image %s part: %w", field, err)
+	}
+	return nil
+}
+
+// DescribeImage sends img with a text prompt as a single user turn. The
+// model must accept image input; the light tier models do.
+func (p *openAIProvider) DescribeImage(ctx context.Context, model, systemPrompt, prompt string, img Image) (Result, error) {
+	if model == "" {
+		model = p.config.Model
+	}
+
+	body := openAIRequest{
+		Model: model,
+		Messages: []openAIMessage{
+			{Role: string(RoleSystem), Content: systemPrompt},
+			{Role: string(RoleUser), Parts: []openAIContentPart{
+				{Type: "image_url", ImageURL: &openAIImageURL{URL: img.dataURL()}},
+				{Type: "text", Text: prompt},
+			}},
+		},
 	}
 
-	return imgBytes, "image/png", nil
+	return p.doChat(ctx, "openai", body)
 }
 
 // --- OpenAI-compatible request/response types ---
-// Used by both OpenAI and Mistral providers.
+// Used by the OpenAI, Mistral and OpenAI-compatible providers.
 
 type openAIMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+
+	// Parts replaces Content in requests that mix text and images.
+	Parts []openAIContentPart `json:"-"`
+}
+
+// MarshalJSON sends Parts as the content array when set.
+func (m openAIMessage) MarshalJSON() ([]byte, error) {
+	if len(m.Parts) == 0 {
+		type plain openAIMessage
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		Role    string              `json:"role"`
+		Content []openAIContentPart `json:"content"`
+	}{m.Role, m.Parts})
+}
+
+// openAIContentPart is one element of a multi-part message: text or an
+// image given as a data URL.
+type openAIContentPart struct {
+	Type     string          `json:"type"` // "text" or "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
 }
 
 type openAIRequest struct {
-	Model    string          `json:"model"`
-	Messages []openAIMessage `json:"messages"`
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Stream         bool                  `json:"stream,omitempty"`
+	StreamOptions  *openAIStreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// openAIResponseFormat selects structured output. Mistral accepts the
+// same json_schema format.
+type openAIResponseFormat struct {
+	Type       string            `json:"type"` // "json_schema"
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict"`
+}
+
+// jsonSchemaFormat returns the strict json_schema response format for schema.
+func jsonSchemaFormat(schema *Schema) *openAIResponseFormat {
+	return &openAIResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: &openAIJSONSchema{Name: schema.Name, Schema: schema.Def, Strict: true},
+	}
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openAIResponse struct {
+	Model   string         `json:"model"`
 	Choices []openAIChoice `json:"choices"`
+	Usage   *openAIUsage   `json:"usage,omitempty"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// usage converts the response's usage block; requested is the model that
+// was asked for, used when the response doesn't name one.
+func (r openAIResponse) usage(provider, requested string) Usage {
+	u := Usage{Provider: provider, Model: r.Model}
+	if u.Model == "" {
+		u.Model = requested
+	}
+	if r.Usage != nil {
+		u.InputTokens = r.Usage.PromptTokens
+		u.OutputTokens = r.Usage.CompletionTokens
+	}
+	return u
 }
 
 type openAIChoice struct {
 	Message openAIMessage `json:"message"`
 }
 
+// openAIStreamChunk is one "data:" payload of a streamed completion. The
+// last chunk may carry only usage, with no choices.
+type openAIStreamChunk struct {
+	openAIResponse
+	Choices []struct {
+		Delta openAIMessage `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
 // --- OpenAI image generation types ---
 
 type openAIImageRequest struct {
@@ -198,8 +579,8 @@ type openAIImageRequest struct {
 	Prompt         string `json:"prompt"`
 	N              int    `json:"n"`
 	Size           string `json:"size"`
-	ResponseFormat string `json:"response_format"`
-	Quality        string `json:"quality"`
+	ResponseFormat string `json:"response_format,omitempty"`
+	Quality        string `json:"quality,omitempty"`
 }
 
 type openAIImageResponse struct {
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// pricing.go turns token usage into an estimated cost using a per-model
+// price table. The built-in prices are list prices at the time of writing
+// and can be overridden with AI_PRICES.
+package ai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Price is a model's list price in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// PriceTable maps model names (or model name prefixes) to prices.
+type PriceTable map[string]Price
+
+// DefaultPrices returns the built-in price table for the default models
+// of each provider.
+func DefaultPrices() PriceTable {
+	return PriceTable{
+		"gpt-4o":           {Input: 2.50, Output: 10.00},
+		"gpt-4o-mini":      {Input: 0.15, Output: 0.60},
+		"gpt-4.1":          {Input: 2.00, Output: 8.00},
+		"gpt-4.1-mini":     {Input: 0.40, Output: 1.60},
+		"claude-sonnet-4":  {Input: 3.00, Output: 15.00},
+		"claude-haiku-4":   {Input: 1.00, Output: 5.00},
+		"claude-opus-4":    {Input: 15.00, Output: 75.00},
+		"gemini-2.5-pro":   {Input: 1.25, Output: 10.00},
+		"gemini-2.5-flash": {Input: 0.30, Output: 2.50},
+		"mistral-large":    {Input: 2.00, Output: 6.00},
+		"mistral-medium":   {Input: 0.40, Output: 2.00},
+		"mistral-small":    {Input: 0.10, Output: 0.30},
+
+		// Embedding models bill input tokens only.
+		"text-embedding-3-small": {Input: 0.02},
+		"text-embedding-3-large": {Input: 0.13},
+		"gemini-embedding-001":   {Input: 0.15},
+		"mistral-embed":          {Input: 0.10},
+	}
+}
+
+// ParsePrices parses a price list of the form
+//
+//	model=input/output,model=input/output
+//
+// with prices in USD per million tokens, e.g. "gpt-4o=2.5/10".
+func ParsePrices(s string) (PriceTable, error) {
+	t := PriceTable{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		model, prices, ok := strings.Cut(entry, "=")
+		in, out, ok2 := strings.Cut(prices, "/")
+		if !ok || !ok2 || strings.TrimSpace(model) == "" {
+			return nil, fmt.Errorf("invalid price %q: want model=input/output", entry)
+		}
+		inPrice, err := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		if err != nil || inPrice < 0 {
+			return nil, fmt.Errorf("invalid input price in %q", entry)
+		}
+		outPrice, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err != nil || outPrice < 0 {
+			return nil, fmt.Errorf("invalid output price in %q", entry)
+		}
+		t[strings.TrimSpace(model)] = Price{Input: inPrice, Output: outPrice}
+	}
+	return t, nil
+}
+
+// Merge returns a copy of t with the entries of overrides added or replaced.
+func (t PriceTable) Merge(overrides PriceTable) PriceTable {
+	out := make(PriceTable, len(t)+len(overrides))
+	for k, v := range t {
+		out[k] = v
+	}
+	for k, v := range overrides {
+		out[k] = v
+	}
+	return out
+}
+
+// Lookup finds the price for model. An exact match wins; otherwise the
+// longest table entry that is a prefix of model is used, so dated model
+// names like "gpt-4o-2024-08-06" resolve to "gpt-4o".
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	if p, ok := t[model]; ok {
+		return p, true
+	}
+	best := ""
+	for name := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Cost estimates the USD cost of a completion. ok is false when the
+// model has no price.
+func (t PriceTable) Cost(model string, inputTokens, outputTokens int) (cost float64, ok bool) {
+	p, ok := t.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1_000_000, true
+}
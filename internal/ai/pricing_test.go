@@ -0,0 +1,72 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePrices(t *testing.T) {
+	got, err := ParsePrices(" gpt-4o=2.5/10 , local-model=0/0,")
+	if err != nil {
+		t.Fatalf("ParsePrices: %v", err)
+	}
+	if got["gpt-4o"] != (Price{Input: 2.5, Output: 10}) {
+		t.Errorf("gpt-4o: got %+v", got["gpt-4o"])
+	}
+	if _, ok := got["local-model"]; !ok || len(got) != 2 {
+		t.Errorf("expected 2 entries, got %+v", got)
+	}
+
+	if got, err := ParsePrices(""); err != nil || len(got) != 0 {
+		t.Errorf("empty input: got %+v, %v", got, err)
+	}
+
+	for _, bad := range []string{"gpt-4o", "gpt-4o=2.5", "=1/2", "gpt-4o=a/1", "gpt-4o=1/-2"} {
+		if _, err := ParsePrices(bad); err == nil {
+			t.Errorf("ParsePrices(%q): expected error", bad)
+		}
+	}
+}
+
+func TestPriceTableLookup(t *testing.T) {
+	table := PriceTable{
+		"gpt-4o":      {Input: 2.5, Output: 10},
+		"gpt-4o-mini": {Input: 0.15, Output: 0.6},
+	}
+
+	tests := []struct {
+		model string
+		want  Price
+		ok    bool
+	}{
+		{"gpt-4o", Price{2.5, 10}, true},
+		{"gpt-4o-2024-08-06", Price{2.5, 10}, true},
+		{"gpt-4o-mini-2024-07-18", Price{0.15, 0.6}, true},
+		{"claude-sonnet-4", Price{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := table.Lookup(tt.model)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPriceTableCost(t *testing.T) {
+	table := DefaultPrices().Merge(PriceTable{"gpt-4o": {Input: 2, Output: 8}})
+
+	cost, ok := table.Cost("gpt-4o", 1_000_000, 500_000)
+	if !ok || math.Abs(cost-6) > 1e-9 {
+		t.Errorf("Cost: got %v, %v; want 6, true", cost, ok)
+	}
+	if _, ok := table.Cost("unknown-model", 10, 10); ok {
+		t.Error("unknown model should be unpriced")
+	}
+	if DefaultPrices()["gpt-4o"].Input != 2.50 {
+		t.Error("Merge must not modify the receiver")
+	}
+}
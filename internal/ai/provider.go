@@ -3,14 +3,20 @@
 // All rights reserved. See LICENSE for details.
 
 // Package ai provides a unified interface for interacting with multiple
-// LLM providers (OpenAI, Gemini, Claude, Mistral). Each provider implements
+// LLM providers (OpenAI, Gemini, Claude, Mistral, plus any number of
+// OpenAI-compatible and Ollama servers, and a fake provider for
+// development and tests). Each provider implements
 // the Provider interface, and the Registry selects the active one by name.
 package ai
 
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // Provider defines the interface that all AI providers must implement.
@@ -23,7 +29,23 @@ type Provider interface {
 
 	// GenerateWithModel is like Generate but uses a specific model instead
 	// of the provider's default. If model is empty, falls back to the default.
-	GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (string, error)
+	// The Result carries the token usage reported by the API.
+	GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error)
+
+	// StreamGenerate is like GenerateWithModel but streams the completion:
+	// onDelta is called with each text fragment as it arrives, and the
+	// full text and usage are returned at the end. Cancelling ctx aborts the
+	// upstream request. On error the text received so far is returned with it.
+	StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error)
+
+	// Chat sends a whole conversation (system, user and assistant
+	// messages, oldest first) and returns the next assistant turn. Each
+	// provider maps the roles to its own API. If model is empty, falls
+	// back to the default.
+	Chat(ctx context.Context, model string, messages []Message) (Result, error)
+
+	// StreamChat is the streaming counterpart of Chat.
+	StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error)
 
 	// Name returns the provider identifier (e.g., "openai", "gemini").
 	Name() string
@@ -39,21 +61,27 @@ const (
 	TaskTemplate
 	// TaskLight is for cheap tasks: titles, excerpts, SEO, tags.
 	TaskLight
+	// TaskEmbedding is for embedding content and search queries.
+	TaskEmbedding
 )
 
 // ProviderConfig holds the credentials and model tier settings for a single provider.
 type ProviderConfig struct {
-	APIKey       string
-	Model        string // Default (pro) model — used for content and templates.
-	ModelLight   string // Cheaper/faster model for lightweight tasks.
-	ModelContent string // Optional override for content generation + rewrite.
-	ModelTemplate string // Optional override for template generation.
-	ModelImage   string // Image generation model (e.g., "dall-e-3", "gemini-2.5-flash-image").
-	BaseURL      string
+	APIKey         string
+	Model          string // Default (pro) model — used for content and templates.
+	ModelLight     string // Cheaper/faster model for lightweight tasks.
+	ModelContent   string // Optional override for content generation + rewrite.
+	ModelTemplate  string // Optional override for template generation.
+	ModelImage     string // Image generation model (e.g., "dall-e-3", "gemini-2.5-flash-image").
+	ModelEmbedding string // Embedding model; empty uses the provider's default.
+	BaseURL        string
+	Kind           string // KindOpenAICompatible or KindOllama; empty for built-in providers
 }
 
 // ModelForTask resolves the best model for a given task type.
 // Resolution order: task-specific override → tier default → Model.
+// Embeddings never fall back to Model, which is a chat model; an empty
+// result means the provider's default embedding model.
 func (c ProviderConfig) ModelForTask(task TaskType) string {
 	switch task {
 	case TaskContent:
@@ -71,6 +99,8 @@ func (c ProviderConfig) ModelForTask(task TaskType) string {
 			return c.ModelLight
 		}
 		return c.Model
+	case TaskEmbedding:
+		return c.ModelEmbedding
 	default:
 		return c.Model
 	}
@@ -84,11 +114,21 @@ type Registry struct {
 	providers map[string]Provider
 	configs   map[string]ProviderConfig // stored for model tier resolution
 	active    string
-	moderator Moderator // may be nil if no moderation API is available
+	moderator Moderator     // may be nil if no moderation API is available
+	recorder  UsageRecorder // may be nil; receives usage for every call
+	embedder  string        // provider used for embeddings; see SetEmbeddingProvider
+
+	// Failover: providers tried after the active one, per task, and a
+	// circuit breaker per provider (created on first use).
+	fallbacks map[TaskType][]string
+	breakers  map[string]*breaker
+	retryBase time.Duration // backoff base; zero means retryBaseDelay
 }
 
 // NewRegistry creates a registry and initialises providers for every config
 // that has a non-empty API key. Providers without keys are silently skipped.
+// Configs with a Kind are self-hosted or third-party servers registered
+// under their own name; for them the API key is optional.
 // A Moderator is automatically configured: OpenAI's free moderation API is
 // preferred; Mistral's paid endpoint is used as fallback.
 func NewRegistry(active string, configs map[string]ProviderConfig) *Registry {
@@ -99,6 +139,14 @@ func NewRegistry(active string, configs map[string]ProviderConfig) *Registry {
 	}
 
 	for name, cfg := range configs {
+		switch cfg.Kind {
+		case KindOpenAICompatible:
+			r.providers[name] = newOpenAICompatible(name, cfg)
+			continue
+		case KindOllama:
+			r.providers[name] = newOllama(name, cfg)
+			continue
+		}
 		if cfg.APIKey == "" {
 			continue
 		}
@@ -136,29 +184,222 @@ func NewRegistry(active string, configs map[string]ProviderConfig) *Registry {
 	return r
 }
 
-// Generate calls the active provider's Generate method using the default model.
+// Generate calls the active provider using its default model. Usage is
+// reported as TaskContent, the tier that uses the default model. Unlike
+// GenerateForTask it does not fail over.
 func (r *Registry) Generate(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	p, err := r.Active()
 	if err != nil {
 		return "", err
 	}
-	return p.Generate(ctx, systemPrompt, userPrompt)
+	res, err := r.record(ctx, TaskContent, p.Name(), "", func() (Result, error) {
+		return p.GenerateWithModel(ctx, "", systemPrompt, userPrompt)
+	})
+	return res.Text, err
 }
 
 // GenerateForTask calls the active provider using the model tier appropriate
 // for the given task type (e.g., light model for titles, pro model for content).
+// If the provider fails, the task's fallback providers are tried in order.
 func (r *Registry) GenerateForTask(ctx context.Context, task TaskType, systemPrompt, userPrompt string) (string, error) {
+	res, err := r.CompleteForTask(ctx, task, systemPrompt, userPrompt)
+	return res.Text, err
+}
+
+// CompleteForTask is like GenerateForTask but returns the full Result,
+// including which provider answered.
+func (r *Registry) CompleteForTask(ctx context.Context, task TaskType, systemPrompt, userPrompt string) (Result, error) {
+	return r.failover(ctx, task, nil, func(p Provider, model string) (Result, error) {
+		return p.GenerateWithModel(ctx, model, systemPrompt, userPrompt)
+	})
+}
+
+// StreamForTask is the streaming counterpart of CompleteForTask. It only
+// fails over while nothing has been streamed yet: once onDelta has been
+// called, an error is returned as is.
+func (r *Registry) StreamForTask(ctx context.Context, task TaskType, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	var started bool
+	return r.failover(ctx, task, &started, func(p Provider, model string) (Result, error) {
+		return p.StreamGenerate(ctx, model, systemPrompt, userPrompt, func(delta string) error {
+			started = true
+			return onDelta(delta)
+		})
+	})
+}
+
+// ChatForTask continues a conversation using the model tier for the
+// task, with the same failover as CompleteForTask.
+func (r *Registry) ChatForTask(ctx context.Context, task TaskType, messages []Message) (Result, error) {
+	return r.failover(ctx, task, nil, func(p Provider, model string) (Result, error) {
+		return p.Chat(ctx, model, messages)
+	})
+}
+
+// StreamChatForTask is the streaming counterpart of ChatForTask; like
+// StreamForTask it only fails over before the first delta.
+func (r *Registry) StreamChatForTask(ctx context.Context, task TaskType, messages []Message, onDelta DeltaFunc) (Result, error) {
+	var started bool
+	return r.failover(ctx, task, &started, func(p Provider, model string) (Result, error) {
+		return p.StreamChat(ctx, model, messages, func(delta string) error {
+			started = true
+			return onDelta(delta)
+		})
+	})
+}
+
+// SetFallbacks sets the providers tried, in order, when the active
+// provider fails a task. Names without a configured provider are skipped
+// at call time, so the same order can be used whatever keys are set.
+func (r *Registry) SetFallbacks(task TaskType, names []string) {
+	var order []string
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			order = append(order, n)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fallbacks == nil {
+		r.fallbacks = make(map[TaskType][]string)
+	}
+	r.fallbacks[task] = order
+}
+
+// chain returns the providers to try for a task: the active provider,
+// then its fallbacks, skipping duplicates and unconfigured providers.
+func (r *Registry) chain(task TaskType) []string {
 	r.mu.RLock()
-	p, ok := r.providers[r.active]
-	cfg := r.configs[r.active]
-	r.mu.RUnlock()
+	defer r.mu.RUnlock()
 
+	var names []string
+	seen := map[string]bool{}
+	for _, n := range append([]string{r.active}, r.fallbacks[task]...) {
+		if _, ok := r.providers[n]; ok && !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// breaker returns the circuit breaker for a provider, creating it on
+// first use.
+func (r *Registry) breaker(name string) *breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.breakers == nil {
+		r.breakers = make(map[string]*breaker)
+	}
+	b, ok := r.breakers[name]
 	if !ok {
-		return "", fmt.Errorf("ai: no provider configured for %q", r.active)
+		b = &breaker{}
+		r.breakers[name] = b
+	}
+	return b
+}
+
+// failover runs call against each provider in the task's chain until one
+// succeeds. Retryable errors are retried with exponential backoff before
+// moving on; auth errors move on at once; fatal errors and cancellation
+// end the request. Providers whose circuit is open are skipped. If
+// started is non-nil and becomes true, the call has already produced
+// output and is not retried. Every attempt is recorded.
+func (r *Registry) failover(ctx context.Context, task TaskType, started *bool, call func(p Provider, model string) (Result, error)) (Result, error) {
+	names := r.chain(task)
+	if len(names) == 0 {
+		return Result{}, fmt.Errorf("ai: no provider configured for %q", r.ActiveName())
+	}
+
+	r.mu.RLock()
+	base := r.retryBase
+	r.mu.RUnlock()
+	if base == 0 {
+		base = retryBaseDelay
+	}
+
+	var lastErr error
+	for _, name := range names {
+		b := r.breaker(name)
+		if !b.allow(time.Now()) {
+			slog.Info("ai provider skipped, circuit open", "provider", name, "task", task)
+			continue
+		}
+
+		r.mu.RLock()
+		p, cfg := r.providers[name], r.configs[name]
+		r.mu.RUnlock()
+		model := cfg.ModelForTask(task)
+
+		for attempt := 1; attempt <= retryAttempts; attempt++ {
+			if attempt > 1 {
+				if err := sleepCtx(ctx, backoff(base, attempt-1)); err != nil {
+					return Result{}, err
+				}
+			}
+
+			res, err := r.record(ctx, task, name, model, func() (Result, error) {
+				return call(p, model)
+			})
+			if err == nil {
+				b.success()
+				if name != names[0] {
+					res.FallbackFrom = names[0]
+				}
+				return res, nil
+			}
+			lastErr = err
+
+			class := Classify(err)
+			if ctx.Err() != nil || class == ErrorFatal || (started != nil && *started) {
+				return res, err
+			}
+			b.failure(time.Now(), class)
+			slog.Warn("ai provider failed", "provider", name, "task", task, "attempt", attempt, "class", class, "error", err)
+			if class == ErrorAuth || !b.allow(time.Now()) {
+				break
+			}
+		}
+	}
+
+	if lastErr == nil {
+		return Result{}, fmt.Errorf("ai: all providers for %s tasks are temporarily unavailable", task)
+	}
+	return Result{}, lastErr
+}
+
+// SetUsageRecorder installs a recorder that receives the usage of every
+// completion made through the registry. Pass nil to disable recording.
+func (r *Registry) SetUsageRecorder(rec UsageRecorder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recorder = rec
+}
+
+// record runs a completion, fills in the usage fields the provider left
+// empty (provider, model, latency), and reports it to the recorder.
+func (r *Registry) record(ctx context.Context, task TaskType, provider, model string, call func() (Result, error)) (Result, error) {
+	start := time.Now()
+	res, err := call()
+
+	u := &res.Usage
+	if u.Provider == "" {
+		u.Provider = provider
+	}
+	if u.Model == "" {
+		u.Model = model
+	}
+	if u.Latency == 0 {
+		u.Latency = time.Since(start)
 	}
 
-	model := cfg.ModelForTask(task)
-	return p.GenerateWithModel(ctx, model, systemPrompt, userPrompt)
+	r.mu.RLock()
+	rec := r.recorder
+	r.mu.RUnlock()
+	if rec != nil {
+		rec.RecordUsage(ctx, UsageRecord{Task: task, Usage: res.Usage, OutputChars: utf8.RuneCountInString(res.Text), Err: err})
+	}
+	return res, err
 }
 
 // Active returns the currently active provider.
@@ -226,6 +467,53 @@ func (r *Registry) CheckPrompt(ctx context.Context, prompt string) (*ModerationR
 	return r.moderator.CheckSafety(ctx, prompt)
 }
 
+// SetModerator replaces the moderator chosen by NewRegistry; nil turns
+// moderation off. CheckPrompt and CheckOutput read it without locking, so
+// call it at startup, before the registry is in use.
+func (r *Registry) SetModerator(m Moderator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.moderator = m
+}
+
+// HasModerator reports whether CheckPrompt and CheckOutput use a
+// moderation API, rather than passing everything.
+func (r *Registry) HasModerator() bool {
+	return r.moderator != nil
+}
+
+// maxModerationInput is the most text sent in one moderation request,
+// well below the input limits of the OpenAI and Mistral endpoints.
+const maxModerationInput = 12000
+
+// CheckOutput runs generated text through the same moderator as
+// CheckPrompt, before it is shown to the user or saved. Long output is
+// checked in chunks of maxModerationInput bytes; the first flagged chunk
+// decides. Like CheckPrompt it reports safe when no moderator is
+// configured.
+func (r *Registry) CheckOutput(ctx context.Context, text string) (*ModerationResult, error) {
+	if r.moderator == nil {
+		return &ModerationResult{Safe: true}, nil
+	}
+	for len(text) > 0 {
+		chunk := text
+		if len(chunk) > maxModerationInput {
+			cut := maxModerationInput
+			for cut > 0 && !utf8.RuneStart(chunk[cut]) {
+				cut--
+			}
+			chunk = chunk[:cut]
+		}
+		text = text[len(chunk):]
+
+		result, err := r.moderator.CheckSafety(ctx, chunk)
+		if err != nil || !result.Safe {
+			return result, err
+		}
+	}
+	return &ModerationResult{Safe: true}, nil
+}
+
 // HasProvider checks whether a named provider is configured and available.
 func (r *Registry) HasProvider(name string) bool {
 	r.mu.RLock()
@@ -1087,3 +1087,70 @@ func TestRegistryRegister(t *testing.T) {
 		}
 	})
 }
+
+// =====================================================================
+// Usage reporting
+// =====================================================================
+
+func TestGenerateWithModel_ReportsUsage(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		newP      func(baseURL string) Provider
+		wantModel string
+		wantIn    int
+		wantOut   int
+	}{
+		{
+			name: "openai",
+			body: `{"model":"gpt-4o-2024-08-06","choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`,
+			newP: func(u string) Provider {
+				return newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: u})
+			},
+			wantModel: "gpt-4o-2024-08-06", wantIn: 12, wantOut: 3,
+		},
+		{
+			name: "claude",
+			body: `{"model":"claude-sonnet-4-6","content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":20,"output_tokens":4}}`,
+			newP: func(u string) Provider {
+				return newClaude(ProviderConfig{APIKey: "k", Model: "claude-sonnet-4-6", BaseURL: u})
+			},
+			wantModel: "claude-sonnet-4-6", wantIn: 20, wantOut: 4,
+		},
+		{
+			name: "gemini",
+			body: `{"candidates":[{"content":{"parts":[{"text":"hi"}]}}],"usageMetadata":{"promptTokenCount":7,"candidatesTokenCount":2,"thoughtsTokenCount":5},"modelVersion":"gemini-2.5-pro"}`,
+			newP: func(u string) Provider {
+				return newGemini(ProviderConfig{APIKey: "k", Model: "gemini-2.5-pro", BaseURL: u})
+			},
+			wantModel: "gemini-2.5-pro", wantIn: 7, wantOut: 7,
+		},
+		{
+			name: "mistral",
+			body: `{"model":"mistral-large-latest","choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":9,"completion_tokens":1}}`,
+			newP: func(u string) Provider {
+				return newMistral(ProviderConfig{APIKey: "k", Model: "mistral-large-latest", BaseURL: u})
+			},
+			wantModel: "mistral-large-latest", wantIn: 9, wantOut: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, http.StatusOK, []byte(tt.body))
+			defer srv.Close()
+
+			res, err := tt.newP(srv.URL).GenerateWithModel(context.Background(), "", "sys", "usr")
+			if err != nil {
+				t.Fatalf("GenerateWithModel: %v", err)
+			}
+			if res.Text != "hi" {
+				t.Errorf("text: got %q", res.Text)
+			}
+			u := res.Usage
+			if u.Provider != tt.name || u.Model != tt.wantModel || u.InputTokens != tt.wantIn || u.OutputTokens != tt.wantOut {
+				t.Errorf("usage: got %+v", u)
+			}
+		})
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // mockProvider is a test double implementing the Provider interface.
@@ -21,6 +23,7 @@ type mockProvider struct {
 	callCount  int
 	lastSystem string
 	lastUser   string
+	lastChat   []Message
 	mu         sync.Mutex
 }
 
@@ -35,9 +38,34 @@ func (m *mockProvider) Generate(ctx context.Context, systemPrompt, userPrompt st
 	return m.response, m.err
 }
 
-func (m *mockProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (string, error) {
+func (m *mockProvider) GenerateWithModel(ctx context.Context, model, systemPrompt, userPrompt string) (Result, error) {
 	// For testing, delegate to Generate (ignores model).
-	return m.Generate(ctx, systemPrompt, userPrompt)
+	text, err := m.Generate(ctx, systemPrompt, userPrompt)
+	return Result{Text: text, Usage: Usage{InputTokens: len(userPrompt), OutputTokens: len(text)}}, err
+}
+
+func (m *mockProvider) StreamGenerate(ctx context.Context, model, systemPrompt, userPrompt string, onDelta DeltaFunc) (Result, error) {
+	res, err := m.GenerateWithModel(ctx, model, systemPrompt, userPrompt)
+	if err != nil {
+		return Result{}, err
+	}
+	return res, onDelta(res.Text)
+}
+
+func (m *mockProvider) Chat(ctx context.Context, model string, messages []Message) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callCount++
+	m.lastChat = messages
+	return Result{Text: m.response, Usage: Usage{InputTokens: len(messages), OutputTokens: len(m.response)}}, m.err
+}
+
+func (m *mockProvider) StreamChat(ctx context.Context, model string, messages []Message, onDelta DeltaFunc) (Result, error) {
+	res, err := m.Chat(ctx, model, messages)
+	if err != nil {
+		return Result{}, err
+	}
+	return res, onDelta(res.Text)
 }
 
 // ---------- Registry.Generate ----------
@@ -118,6 +146,117 @@ func TestRegistryGenerateNoProvider(t *testing.T) {
 	})
 }
 
+// ---------- Registry.StreamForTask ----------
+
+func TestRegistryStreamForTask(t *testing.T) {
+	t.Run("streams from the active provider", func(t *testing.T) {
+		mock := &mockProvider{name: "test", response: "streamed"}
+		reg := &Registry{
+			providers: map[string]Provider{"test": mock},
+			configs:   map[string]ProviderConfig{"test": {Model: "pro", ModelLight: "light"}},
+			active:    "test",
+		}
+
+		var deltas []string
+		result, err := reg.StreamForTask(context.Background(), TaskLight, "system", "user", func(d string) error {
+			deltas = append(deltas, d)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamForTask: unexpected error: %v", err)
+		}
+		if result.Text != "streamed" || len(deltas) != 1 || deltas[0] != "streamed" {
+			t.Errorf("got %q with deltas %q", result, deltas)
+		}
+	})
+
+	t.Run("error when no provider is active", func(t *testing.T) {
+		reg := &Registry{providers: map[string]Provider{}, active: "nonexistent"}
+
+		_, err := reg.StreamForTask(context.Background(), TaskContent, "system", "user", func(string) error { return nil })
+		if err == nil {
+			t.Fatal("expected error when no provider is active, got nil")
+		}
+	})
+}
+
+// ---------- Registry usage recording ----------
+
+// recordingUsageRecorder collects usage records for assertions.
+type recordingUsageRecorder struct {
+	mu      sync.Mutex
+	records []UsageRecord
+}
+
+func (r *recordingUsageRecorder) RecordUsage(ctx context.Context, rec UsageRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec)
+}
+
+func TestRegistryUsageRecorder(t *testing.T) {
+	t.Run("records successful calls with provider, model and task", func(t *testing.T) {
+		mock := &mockProvider{name: "test", response: "four"}
+		rec := &recordingUsageRecorder{}
+		reg := &Registry{
+			providers: map[string]Provider{"test": mock},
+			configs:   map[string]ProviderConfig{"test": {Model: "pro", ModelLight: "light"}},
+			active:    "test",
+		}
+		reg.SetUsageRecorder(rec)
+
+		if _, err := reg.GenerateForTask(context.Background(), TaskLight, "system", "user"); err != nil {
+			t.Fatalf("GenerateForTask: %v", err)
+		}
+		if _, err := reg.StreamForTask(context.Background(), TaskContent, "system", "prompt", func(string) error { return nil }); err != nil {
+			t.Fatalf("StreamForTask: %v", err)
+		}
+
+		if len(rec.records) != 2 {
+			t.Fatalf("expected 2 records, got %d", len(rec.records))
+		}
+		got := rec.records[0]
+		if got.Task != TaskLight || got.Provider != "test" || got.Model != "light" {
+			t.Errorf("record: got task=%v provider=%q model=%q", got.Task, got.Provider, got.Model)
+		}
+		if got.InputTokens != 4 || got.OutputTokens != 4 {
+			t.Errorf("tokens: got %d/%d, want 4/4", got.InputTokens, got.OutputTokens)
+		}
+		if got.Latency <= 0 || got.Err != nil {
+			t.Errorf("latency=%v err=%v", got.Latency, got.Err)
+		}
+		if got.OutputChars != len("four") {
+			t.Errorf("output chars: got %d, want 4", got.OutputChars)
+		}
+		if rec.records[1].Task != TaskContent || rec.records[1].Model != "pro" {
+			t.Errorf("stream record: got task=%v model=%q", rec.records[1].Task, rec.records[1].Model)
+		}
+	})
+
+	t.Run("records failed calls", func(t *testing.T) {
+		mock := &mockProvider{name: "test", err: fmt.Errorf("api failure")}
+		rec := &recordingUsageRecorder{}
+		reg := &Registry{
+			providers: map[string]Provider{"test": mock},
+			configs:   map[string]ProviderConfig{"test": {Model: "pro"}},
+			active:    "test",
+			retryBase: time.Millisecond,
+		}
+		reg.SetUsageRecorder(rec)
+
+		if _, err := reg.GenerateForTask(context.Background(), TaskTemplate, "system", "user"); err == nil {
+			t.Fatal("expected error")
+		}
+		// The error is retryable, so every attempt is recorded.
+		if len(rec.records) != retryAttempts || rec.records[0].Err == nil || rec.records[1].Err == nil {
+			t.Fatalf("expected %d failed records, got %+v", retryAttempts, rec.records)
+		}
+		if rec.records[0].Task.String() != "template" {
+			t.Errorf("task: got %q", rec.records[0].Task)
+		}
+	})
+}
+
 // ---------- Registry.SetActive ----------
 
 func TestRegistrySetActive(t *testing.T) {
@@ -487,3 +626,42 @@ func TestRegistryActive(t *testing.T) {
 		}
 	})
 }
+
+// chunkModerator flags any text containing word and records the size of
+// every text it checks.
+type chunkModerator struct {
+	word  string
+	sizes []int
+}
+
+func (m *chunkModerator) CheckSafety(ctx context.Context, text string) (*ModerationResult, error) {
+	m.sizes = append(m.sizes, len(text))
+	if strings.Contains(text, m.word) {
+		return &ModerationResult{Safe: false, Categories: []string{"violence"}}, nil
+	}
+	return &ModerationResult{Safe: true}, nil
+}
+
+func TestRegistryCheckOutput(t *testing.T) {
+	reg := &Registry{}
+	if res, err := reg.CheckOutput(context.Background(), "anything"); err != nil || !res.Safe {
+		t.Errorf("no moderator: got %+v, %v", res, err)
+	}
+
+	mod := &chunkModerator{word: "FLAG"}
+	reg.moderator = mod
+	long := strings.Repeat("é", maxModerationInput) // Two bytes per rune
+	res, err := reg.CheckOutput(context.Background(), long)
+	if err != nil || !res.Safe {
+		t.Fatalf("safe output: got %+v, %v", res, err)
+	}
+	if len(mod.sizes) != 2 || mod.sizes[0] != maxModerationInput || mod.sizes[1] != maxModerationInput {
+		t.Errorf("chunks: got sizes %v", mod.sizes)
+	}
+
+	mod.sizes = nil
+	res, err = reg.CheckOutput(context.Background(), long+"FLAG")
+	if err != nil || res.Safe || len(mod.sizes) != 3 {
+		t.Errorf("flagged output: got %+v after %d chunks, %v", res, len(mod.sizes), err)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// stream.go provides the shared plumbing for streamed completions: an
+// HTTP client without an overall deadline, an idle timeout between
+// chunks, and a Server-Sent Events reader used by every provider.
+package ai
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DeltaFunc receives each text fragment of a streamed completion as it
+// arrives. Returning an error aborts the stream.
+type DeltaFunc func(delta string) error
+
+const (
+	// streamHeaderTimeout bounds the wait for the provider to start
+	// responding (it may queue the request before emitting anything).
+	streamHeaderTimeout = 60 * time.Second
+
+	// streamIdleTimeout aborts a stream that stops sending data.
+	streamIdleTimeout = 60 * time.Second
+)
+
+// streamClient is used for streamed requests. Unlike the providers'
+// regular clients it has no overall timeout — a long completion may take
+// several minutes — and relies on the header and idle timeouts instead.
+var streamClient = newStreamClient()
+
+func newStreamClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = streamHeaderTimeout
+	return &http.Client{Transport: t}
+}
+
+// sseEvent is one Server-Sent Event. Multiple data lines are joined with
+// newlines, as the SSE spec requires.
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// doStream sends the request built by newReq and feeds every SSE event in
+// the response to onEvent until the body ends or onEvent returns errStreamDone.
+// newReq receives the context the request must be bound to, so the idle
+// timeout can cancel it. provider prefixes error messages.
+func doStream(ctx context.Context, provider string, newReq func(ctx context.Context) (*http.Request, error), onEvent func(sseEvent) error) error {
+	return streamBody(ctx, provider, "text/event-stream", newReq, func(body io.Reader, touch func()) error {
+		return readSSE(body, func(ev sseEvent) error {
+			touch()
+			return onEvent(ev)
+		})
+	})
+}
+
+// streamBody is the transport under doStream for any streamed format:
+// it sends the request with the given Accept header, checks the status,
+// and hands the body to read. read must call touch whenever data arrives
+// to reset the idle timeout, and may return errStreamDone to stop early.
+func streamBody(ctx context.Context, provider, accept string, newReq func(ctx context.Context) (*http.Request, error), read func(body io.Reader, touch func()) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var idle atomic.Bool
+	timer := time.AfterFunc(streamIdleTimeout, func() {
+		idle.Store(true)
+		cancel()
+	})
+	defer timer.Stop()
+
+	req, err := newReq(ctx)
+	if err != nil {
+		return fmt.Errorf("%s request: %w", provider, err)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		if idle.Load() {
+			return &timeoutError{fmt.Sprintf("%s stream: no response for %s", provider, streamIdleTimeout)}
+		}
+		return fmt.Errorf("%s http: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &APIError{Source: provider, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	err = read(resp.Body, func() { timer.Reset(streamIdleTimeout) })
+	switch {
+	case errors.Is(err, errStreamDone):
+		return nil
+	case err != nil && idle.Load():
+		return &timeoutError{fmt.Sprintf("%s stream: no data for %s", provider, streamIdleTimeout)}
+	case err != nil:
+		return fmt.Errorf("%s stream: %w", provider, err)
+	}
+	return nil
+}
+
+// timeoutError reports a stream that went idle. It is not a net.Error,
+// but like one it reports Timeout, so Classify treats it as retryable.
+type timeoutError struct{ msg string }
+
+func (e *timeoutError) Error() string { return e.msg }
+func (e *timeoutError) Timeout() bool { return true }
+
+// errStreamDone is returned by event handlers to stop reading once the
+// provider has signalled the end of the completion.
+var errStreamDone = errors.New("stream done")
+
+// readSSE parses a text/event-stream body and calls fn for each event.
+// Comment lines (":" prefix) and the id/retry fields are ignored.
+func readSSE(r io.Reader, fn func(sseEvent) error) error {
+	sc := bufio.NewScanner(r)
+	// Chunks are small, but a single event can carry a large JSON payload.
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var ev sseEvent
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+
+		if line == "" {
+			if len(data) > 0 {
+				ev.Data = strings.Join(data, "\n")
+				if err := fn(ev); err != nil {
+					return err
+				}
+			}
+			ev, data = sseEvent{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	// Some servers close the connection without a trailing blank line.
+	if len(data) > 0 {
+		ev.Data = strings.Join(data, "\n")
+		return fn(ev)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ---------- Helpers ----------
+
+// newStreamServer creates an httptest.Server that writes each event as a
+// separate flushed chunk, like a real streaming API. It records the last
+// request body in *gotBody if non-nil.
+func newStreamServer(t *testing.T, events []string, gotBody *map[string]any) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if gotBody != nil {
+			b, _ := io.ReadAll(r.Body)
+			json.Unmarshal(b, gotBody)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for _, ev := range events {
+			fmt.Fprint(w, ev)
+			w.(http.Flusher).Flush()
+		}
+	}))
+}
+
+// collect returns a DeltaFunc that appends every delta to *deltas.
+func collect(deltas *[]string) DeltaFunc {
+	return func(d string) error {
+		*deltas = append(*deltas, d)
+		return nil
+	}
+}
+
+// ---------- readSSE ----------
+
+func TestReadSSE(t *testing.T) {
+	input := ": keep-alive comment\n" +
+		"event: first\n" +
+		"data: line one\n" +
+		"data: line two\n" +
+		"\n" +
+		"id: 7\n" +
+		"data:no-space\n" +
+		"\n" +
+		"data: trailing without blank line"
+
+	var got []sseEvent
+	err := readSSE(strings.NewReader(input), func(ev sseEvent) error {
+		got = append(got, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE: %v", err)
+	}
+
+	want := []sseEvent{
+		{Event: "first", Data: "line one\nline two"},
+		{Data: "no-space"},
+		{Data: "trailing without blank line"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadSSE_StopsOnHandlerError(t *testing.T) {
+	input := "data: a\n\ndata: b\n\n"
+	calls := 0
+	err := readSSE(strings.NewReader(input), func(sseEvent) error {
+		calls++
+		return errStreamDone
+	})
+	if !errors.Is(err, errStreamDone) {
+		t.Errorf("expected errStreamDone, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+// ---------- Provider streaming ----------
+
+func TestOpenAIStreamGenerate(t *testing.T) {
+	var body map[string]any
+	srv := newStreamServer(t, []string{
+		`data: {"choices":[{"delta":{"role":"assistant"}}]}` + "\n\n",
+		`data: {"choices":[{"delta":{"content":"Hello"}}]}` + "\n\n",
+		`data: {"choices":[{"delta":{"content":", world"}}]}` + "\n\n",
+		`data: {"model":"gpt-4o-2024-08-06","choices":[],"usage":{"prompt_tokens":12,"completion_tokens":3}}` + "\n\n",
+		"data: [DONE]\n\n",
+	}, &body)
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+
+	var deltas []string
+	res, err := p.StreamGenerate(context.Background(), "", "sys", "user", collect(&deltas))
+	if err != nil {
+		t.Fatalf("StreamGenerate: %v", err)
+	}
+	if res.Text != "Hello, world" {
+		t.Errorf("result: got %q", res.Text)
+	}
+	if strings.Join(deltas, "|") != "Hello|, world" {
+		t.Errorf("deltas: got %q", deltas)
+	}
+	if body["stream"] != true || body["model"] != "gpt-4o" {
+		t.Errorf("request body: got %v", body)
+	}
+	if opts, _ := body["stream_options"].(map[string]any); opts["include_usage"] != true {
+		t.Errorf("stream_options.include_usage should be set, got %v", body["stream_options"])
+	}
+	if res.Usage.InputTokens != 12 || res.Usage.OutputTokens != 3 || res.Usage.Model != "gpt-4o-2024-08-06" {
+		t.Errorf("usage: got %+v", res.Usage)
+	}
+}
+
+func TestMistralStreamGenerate(t *testing.T) {
+	var body map[string]any
+	srv := newStreamServer(t, []string{
+		`data: {"choices":[{"delta":{"content":"Bonjour"}}]}` + "\n\n",
+		"data: [DONE]\n\n",
+	}, &body)
+	defer srv.Close()
+
+	p := newMistral(ProviderConfig{APIKey: "k", Model: "mistral-large", BaseURL: srv.URL})
+
+	var deltas []string
+	res, err := p.StreamGenerate(context.Background(), "mistral-small", "sys", "user", collect(&deltas))
+	if err != nil {
+		t.Fatalf("StreamGenerate: %v", err)
+	}
+	if res.Text != "Bonjour" {
+		t.Errorf("result: got %q", res.Text)
+	}
+	if body["model"] != "mistral-small" {
+		t.Errorf("model override not used: got %v", body["model"])
+	}
+}
+
+func TestClaudeStreamGenerate(t *testing.T) {
+	var body map[string]any
+	srv := newStreamServer(t, []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"claude-test-1\",\"usage\":{\"input_tokens\":20,\"output_tokens\":1}}}\n\n",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0}\n\n",
+		"event: ping\ndata: {\"type\":\"ping\"}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi \"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"there\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\"}\n\n",
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":7}}\n\n",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+	}, &body)
+	defer srv.Close()
+
+	p := newClaude(ProviderConfig{APIKey: "k", Model: "claude-test", BaseURL: srv.URL})
+
+	var deltas []string
+	res, err := p.StreamGenerate(context.Background(), "", "sys", "user", collect(&deltas))
+	if err != nil {
+		t.Fatalf("StreamGenerate: %v", err)
+	}
+	if res.Text != "Hi there" || len(deltas) != 2 {
+		t.Errorf("got %q with deltas %q", res.Text, deltas)
+	}
+	if body["stream"] != true || body["system"] != "sys" {
+		t.Errorf("request body: got %v", body)
+	}
+	if res.Usage.InputTokens != 20 || res.Usage.OutputTokens != 7 || res.Usage.Model != "claude-test-1" {
+		t.Errorf("usage: got %+v", res.Usage)
+	}
+}
+
+func TestClaudeStreamGenerate_ErrorEvent(t *testing.T) {
+	srv := newStreamServer(t, []string{
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"partial\"}}\n\n",
+		"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+	}, nil)
+	defer srv.Close()
+
+	p := newClaude(ProviderConfig{APIKey: "k", Model: "claude-test", BaseURL: srv.URL})
+
+	var deltas []string
+	res, err := p.StreamGenerate(context.Background(), "", "sys", "user", collect(&deltas))
+	if err == nil || !strings.Contains(err.Error(), "overloaded_error") {
+		t.Fatalf("expected overloaded_error, got %v", err)
+	}
+	if res.Text != "partial" {
+		t.Errorf("partial text should be returned with the error, got %q", res.Text)
+	}
+}
+
+func TestGeminiStreamGenerate(t *testing.T) {
+	var path, query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"candidates":[{"content":{"parts":[{"text":"Guten "}]}}]}`+"\r\n\r\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, `data: {"candidates":[{"content":{"parts":[{"text":"Tag"}]}}],"usageMetadata":{"promptTokenCount":9,"candidatesTokenCount":2,"thoughtsTokenCount":4}}`+"\r\n\r\n")
+	}))
+	defer srv.Close()
+
+	p := newGemini(ProviderConfig{APIKey: "k", Model: "gemini-test", BaseURL: srv.URL})
+
+	var deltas []string
+	res, err := p.StreamGenerate(context.Background(), "", "sys", "user", collect(&deltas))
+	if err != nil {
+		t.Fatalf("StreamGenerate: %v", err)
+	}
+	if res.Text != "Guten Tag" || len(deltas) != 2 {
+		t.Errorf("got %q with deltas %q", res.Text, deltas)
+	}
+	if res.Usage.InputTokens != 9 || res.Usage.OutputTokens != 6 {
+		t.Errorf("usage (thinking counts as output): got %+v", res.Usage)
+	}
+	if path != "/v1beta/models/gemini-test:streamGenerateContent" || query != "alt=sse" {
+		t.Errorf("URL: got %s?%s", path, query)
+	}
+}
+
+func TestStreamGenerate_APIError(t *testing.T) {
+	srv := newTestServer(t, http.StatusUnauthorized, []byte(`{"error":{"message":"bad key"}}`))
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+	_, err := p.StreamGenerate(context.Background(), "", "sys", "user", func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("expected status 401 error, got %v", err)
+	}
+}
+
+func TestStreamGenerate_DeltaErrorAborts(t *testing.T) {
+	srv := newStreamServer(t, []string{
+		`data: {"choices":[{"delta":{"content":"one"}}]}` + "\n\n",
+		`data: {"choices":[{"delta":{"content":"two"}}]}` + "\n\n",
+		"data: [DONE]\n\n",
+	}, nil)
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+
+	clientGone := errors.New("client gone")
+	calls := 0
+	_, err := p.StreamGenerate(context.Background(), "", "sys", "user", func(string) error {
+		calls++
+		return clientGone
+	})
+	if !errors.Is(err, clientGone) {
+		t.Errorf("expected the delta error to be returned, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("onDelta called %d times after failing, want 1", calls)
+	}
+}
+
+func TestStreamGenerate_CancelAbortsUpstream(t *testing.T) {
+	upstreamClosed := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"first"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		// Hang until the client goes away.
+		<-r.Context().Done()
+		close(upstreamClosed)
+	}))
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.StreamGenerate(ctx, "", "sys", "user", func(string) error {
+			cancel() // The browser disconnects after the first token.
+			return nil
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamGenerate did not return after cancel")
+	}
+
+	select {
+	case <-upstreamClosed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream request was not cancelled")
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// structured.go implements structured JSON output: a JSON Schema derived
+// from a Go struct, the optional JSONGenerator interface for vendors with
+// a native JSON mode, and Registry.GenerateJSON, which validates the reply
+// and asks once for a corrected one.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONGenerator is an optional interface for providers with a native JSON
+// or structured-output mode. ChatJSON is like Provider.Chat but constrains
+// the reply to schema and returns the JSON text in Result.Text. Providers
+// without it are asked for JSON in the prompt only.
+type JSONGenerator interface {
+	ChatJSON(ctx context.Context, model string, messages []Message, schema *Schema) (Result, error)
+}
+
+// Schema is the JSON Schema of the object a structured call must return.
+type Schema struct {
+	// Name identifies the schema to APIs that require one (OpenAI's
+	// json_schema name, Claude's tool name). Letters, digits, "_" and "-".
+	Name string
+
+	// Def is the JSON Schema: an object whose properties are all
+	// required and that allows no other properties.
+	Def map[string]any
+}
+
+// NewSchema derives a schema from the struct type of v. Exported fields
+// are named by their json tag and all of them are required; a desc tag
+// becomes the property's description. Supported field types are strings,
+// booleans, integers, floats, slices and nested structs.
+func NewSchema(name string, v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ai schema %s: %v is not a struct", name, t)
+	}
+	def, err := schemaOf(t)
+	if err != nil {
+		return nil, fmt.Errorf("ai schema %s: %w", name, err)
+	}
+	return &Schema{Name: name, Def: def}, nil
+}
+
+// MustSchema is like NewSchema but panics on error. It is meant for
+// package-level schema variables.
+func MustSchema(name string, v any) *Schema {
+	s, err := NewSchema(name, v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// schemaOf returns the JSON Schema for a Go type.
+func schemaOf(t reflect.Type) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Slice, reflect.Array:
+		items, err := schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		props := map[string]any{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			prop, err := schemaOf(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if desc := f.Tag.Get("desc"); desc != "" {
+				prop["description"] = desc
+			}
+			props[name] = prop
+			required = append(required, name)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"required":             required,
+			"additionalProperties": false,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// Validate checks that data is a JSON document matching the schema: the
+// right types, every required property present and no unknown ones.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("not valid JSON: %w", err)
+	}
+	if dec.More() {
+		return errors.New("not valid JSON: more than one value")
+	}
+	return validateValue(s.Def, v, "$")
+}
+
+// validateValue checks v against def; path locates v in error messages.
+func validateValue(def map[string]any, v any, path string) error {
+	switch def["type"] {
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: want a string", path)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: want a boolean", path)
+		}
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: want an integer", path)
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s: want an integer, got %s", path, n)
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return fmt.Errorf("%s: want a number", path)
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: want an array", path)
+		}
+		itemDef, _ := def["items"].(map[string]any)
+		for i, item := range items {
+			if err := validateValue(itemDef, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: want an object", path)
+		}
+		props, _ := def["properties"].(map[string]any)
+		required, _ := def["required"].([]string)
+		for _, name := range required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing property %q", path, name)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			propDef, ok := props[k].(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s: unknown property %q", path, k)
+			}
+			if err := validateValue(propDef, obj[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// extractJSON returns the JSON object in a reply, dropping markdown code
+// fences and any text around it. Models without a native JSON mode often
+// add one or the other.
+func extractJSON(text string) string {
+	text = strings.TrimSpace(text)
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return text
+	}
+	return text[start : end+1]
+}
+
+// jsonInstructions is appended to the system prompt of every structured
+// call, so providers without a native JSON mode know the expected shape.
+func jsonInstructions(schema *Schema) string {
+	def, _ := json.Marshal(schema.Def)
+	return "Reply with a single JSON object that matches this JSON Schema, and nothing else:\n" + string(def)
+}
+
+// GenerateJSON asks for a reply matching schema and decodes it into out,
+// which must point to the struct the schema was derived from. Providers
+// implementing JSONGenerator use their native JSON mode; others are
+// instructed in the prompt. A reply that fails validation is sent back
+// once with the error and a request to correct it. Calls fail over like
+// CompleteForTask. The returned Result is that of the last call.
+func (r *Registry) GenerateJSON(ctx context.Context, task TaskType, schema *Schema, systemPrompt, userPrompt string, out any) (Result, error) {
+	messages := Prompt(systemPrompt+"\n\n"+jsonInstructions(schema), userPrompt)
+
+	res, err := r.chatJSON(ctx, task, schema, messages)
+	if err != nil {
+		return res, err
+	}
+	reply := extractJSON(res.Text)
+	verr := decodeJSON(schema, reply, out)
+	if verr == nil {
+		return res, nil
+	}
+
+	slog.Warn("ai json reply invalid, asking for a repair", "task", task, "schema", schema.Name, "provider", res.Usage.Provider, "error", verr)
+	messages = append(messages,
+		Message{Role: RoleAssistant, Content: res.Text},
+		Message{Role: RoleUser, Content: fmt.Sprintf(
+			"Your reply does not match the required JSON Schema: %v. Reply again with only the corrected JSON object.", verr)},
+	)
+	res, err = r.chatJSON(ctx, task, schema, messages)
+	if err != nil {
+		return res, err
+	}
+	if err := decodeJSON(schema, extractJSON(res.Text), out); err != nil {
+		return res, fmt.Errorf("ai: invalid %s reply: %w", schema.Name, err)
+	}
+	return res, nil
+}
+
+// chatJSON runs one structured call with failover.
+func (r *Registry) chatJSON(ctx context.Context, task TaskType, schema *Schema, messages []Message) (Result, error) {
+	return r.failover(ctx, task, nil, func(p Provider, model string) (Result, error) {
+		if jg, ok := p.(JSONGenerator); ok {
+			return jg.ChatJSON(ctx, model, messages, schema)
+		}
+		return p.Chat(ctx, model, messages)
+	})
+}
+
+// decodeJSON validates reply against schema and decodes it into out.
+func decodeJSON(schema *Schema, reply string, out any) error {
+	if err := schema.Validate([]byte(reply)); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(reply), out); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
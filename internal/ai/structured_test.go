@@ -0,0 +1,237 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testSEO struct {
+	Description string   `json:"description" desc:"Meta description"`
+	Keywords    []string `json:"keywords"`
+	Score       int      `json:"score"`
+	Author      struct {
+		Name string `json:"name"`
+	} `json:"author"`
+	internal string
+}
+
+var testSEOSchema = MustSchema("test_seo", testSEO{})
+
+func TestNewSchema(t *testing.T) {
+	def := testSEOSchema.Def
+	if def["type"] != "object" || def["additionalProperties"] != false {
+		t.Errorf("top level: got %+v", def)
+	}
+	required := def["required"].([]string)
+	if strings.Join(required, ",") != "description,keywords,score,author" {
+		t.Errorf("required: got %v", required)
+	}
+	props := def["properties"].(map[string]any)
+	desc := props["description"].(map[string]any)
+	if desc["type"] != "string" || desc["description"] != "Meta description" {
+		t.Errorf("description: got %+v", desc)
+	}
+	if kw := props["keywords"].(map[string]any); kw["type"] != "array" {
+		t.Errorf("keywords: got %+v", kw)
+	}
+	if _, ok := props["internal"]; ok {
+		t.Error("unexported fields should be skipped")
+	}
+
+	if _, err := NewSchema("bad", struct{ M map[string]string }{}); err == nil {
+		t.Error("maps should be rejected")
+	}
+	if _, err := NewSchema("bad", "text"); err == nil {
+		t.Error("non-structs should be rejected")
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"valid", `{"description":"d","keywords":["a"],"score":3,"author":{"name":"n"}}`, ""},
+		{"missing property", `{"description":"d","keywords":[],"score":3}`, `missing property "author"`},
+		{"unknown property", `{"description":"d","keywords":[],"score":3,"author":{"name":"n"},"extra":1}`, `unknown property "extra"`},
+		{"wrong type", `{"description":"d","keywords":"a, b","score":3,"author":{"name":"n"}}`, "$.keywords: want an array"},
+		{"bad item", `{"description":"d","keywords":["a",2],"score":3,"author":{"name":"n"}}`, "$.keywords[1]: want a string"},
+		{"float for integer", `{"description":"d","keywords":[],"score":3.5,"author":{"name":"n"}}`, "want an integer"},
+		{"not JSON", `DESCRIPTION: d`, "not valid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testSEOSchema.Validate([]byte(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	got := extractJSON("Sure! Here it is:\n```json\n{\"a\": {\"b\": 1}}\n```")
+	if got != `{"a": {"b": 1}}` {
+		t.Errorf("got %q", got)
+	}
+	if got := extractJSON("no json here"); got != "no json here" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// scriptedProvider answers successive Chat calls with successive replies.
+type scriptedProvider struct {
+	mockProvider
+	replies []string
+	calls   [][]Message
+	mu      sync.Mutex
+}
+
+func (s *scriptedProvider) Chat(_ context.Context, _ string, messages []Message) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, messages)
+	reply := s.replies[min(len(s.calls), len(s.replies))-1]
+	return Result{Text: reply, Usage: Usage{Provider: s.name}}, nil
+}
+
+func TestRegistryGenerateJSON(t *testing.T) {
+	valid := `{"description":"d","keywords":["go"],"score":4,"author":{"name":"n"}}`
+
+	t.Run("valid reply", func(t *testing.T) {
+		p := &scriptedProvider{mockProvider: mockProvider{name: "test"}, replies: []string{"```json\n" + valid + "\n```"}}
+		reg := newFailoverRegistry(p)
+
+		var out testSEO
+		if _, err := reg.GenerateJSON(context.Background(), TaskLight, testSEOSchema, "Write SEO.", "Go post", &out); err != nil {
+			t.Fatalf("GenerateJSON: %v", err)
+		}
+		if out.Score != 4 || out.Keywords[0] != "go" || out.Author.Name != "n" {
+			t.Errorf("decoded: got %+v", out)
+		}
+		if len(p.calls) != 1 {
+			t.Errorf("calls: got %d, want 1", len(p.calls))
+		}
+		if sys := p.calls[0][0].Content; !strings.HasPrefix(sys, "Write SEO.") || !strings.Contains(sys, `"additionalProperties":false`) {
+			t.Errorf("system prompt should carry the schema, got %q", sys)
+		}
+	})
+
+	t.Run("repairs once", func(t *testing.T) {
+		p := &scriptedProvider{mockProvider: mockProvider{name: "test"}, replies: []string{`{"description":"d"}`, valid}}
+		reg := newFailoverRegistry(p)
+
+		var out testSEO
+		if _, err := reg.GenerateJSON(context.Background(), TaskLight, testSEOSchema, "sys", "user", &out); err != nil {
+			t.Fatalf("GenerateJSON: %v", err)
+		}
+		if len(p.calls) != 2 {
+			t.Fatalf("calls: got %d, want 2", len(p.calls))
+		}
+		repair := p.calls[1]
+		if len(repair) != 4 || repair[2].Role != RoleAssistant || !strings.Contains(repair[3].Content, `missing property "keywords"`) {
+			t.Errorf("repair conversation: got %+v", repair)
+		}
+		if out.Description != "d" {
+			t.Errorf("decoded: got %+v", out)
+		}
+	})
+
+	t.Run("gives up after the repair", func(t *testing.T) {
+		p := &scriptedProvider{mockProvider: mockProvider{name: "test"}, replies: []string{"nope"}}
+		reg := newFailoverRegistry(p)
+
+		var out testSEO
+		_, err := reg.GenerateJSON(context.Background(), TaskLight, testSEOSchema, "sys", "user", &out)
+		if err == nil || !strings.Contains(err.Error(), "invalid test_seo reply") {
+			t.Errorf("got %v", err)
+		}
+		if len(p.calls) != 2 {
+			t.Errorf("calls: got %d, want 2", len(p.calls))
+		}
+	})
+}
+
+func TestOpenAIChatJSON_SendsResponseFormat(t *testing.T) {
+	var req map[string]any
+	srv := newCaptureServer(t, openAISuccessBody(`{"x":1}`), &req)
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+	res, err := p.ChatJSON(context.Background(), "", conversation, testSEOSchema)
+	if err != nil {
+		t.Fatalf("ChatJSON: %v", err)
+	}
+	if res.Text != `{"x":1}` {
+		t.Errorf("text: got %q", res.Text)
+	}
+
+	format, _ := req["response_format"].(map[string]any)
+	schema, _ := format["json_schema"].(map[string]any)
+	if format["type"] != "json_schema" || schema["name"] != "test_seo" || schema["strict"] != true {
+		t.Errorf("response_format: got %+v", req["response_format"])
+	}
+}
+
+func TestClaudeChatJSON_ForcesTool(t *testing.T) {
+	var req claudeRequest
+	body := []byte(`{"model":"claude-sonnet","content":[{"type":"tool_use","id":"t1","name":"test_seo","input":{"description":"d"}}],"usage":{"input_tokens":5,"output_tokens":3}}`)
+	srv := newCaptureServer(t, body, &req)
+	defer srv.Close()
+
+	p := newClaude(ProviderConfig{APIKey: "k", Model: "claude-sonnet", BaseURL: srv.URL})
+	res, err := p.ChatJSON(context.Background(), "", conversation, testSEOSchema)
+	if err != nil {
+		t.Fatalf("ChatJSON: %v", err)
+	}
+	if res.Text != `{"description":"d"}` || res.Usage.OutputTokens != 3 {
+		t.Errorf("result: got %+v", res)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Name != "test_seo" || req.ToolChoice == nil || req.ToolChoice.Name != "test_seo" {
+		t.Errorf("tools: got %+v, choice %+v", req.Tools, req.ToolChoice)
+	}
+}
+
+func TestGeminiChatJSON_SetsMimeType(t *testing.T) {
+	var req geminiRequest
+	srv := newCaptureServer(t, geminiSuccessBody(`{"x":1}`), &req)
+	defer srv.Close()
+
+	p := newGemini(ProviderConfig{APIKey: "k", Model: "gemini-2.5-pro", BaseURL: srv.URL})
+	if _, err := p.ChatJSON(context.Background(), "", conversation, testSEOSchema); err != nil {
+		t.Fatalf("ChatJSON: %v", err)
+	}
+	cfg := req.GenerationConfig
+	if cfg == nil || cfg.ResponseMimeType != "application/json" || cfg.ResponseJSONSchema["type"] != "object" {
+		t.Errorf("generationConfig: got %+v", cfg)
+	}
+}
+
+func TestOllamaChatJSON_SendsFormat(t *testing.T) {
+	var req ollamaRequest
+	srv := newCaptureServer(t, []byte(`{"message":{"role":"assistant","content":"{}"},"done":true}`), &req)
+	defer srv.Close()
+
+	p := newOllama("ollama", ProviderConfig{Model: "llama3.1", BaseURL: srv.URL})
+	if _, err := p.ChatJSON(context.Background(), "", conversation, testSEOSchema); err != nil {
+		t.Fatalf("ChatJSON: %v", err)
+	}
+	format, _ := json.Marshal(req.Format)
+	if !strings.Contains(string(format), `"required":["description","keywords","score","author"]`) {
+		t.Errorf("format: got %s", format)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// usage.go defines the usage metadata returned with every completion and
+// the hook the Registry uses to report it for cost accounting.
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// Usage describes what a single completion consumed.
+type Usage struct {
+	Provider     string
+	Model        string // Model that served the request (as reported by the API when available)
+	InputTokens  int
+	OutputTokens int
+	Latency      time.Duration
+}
+
+// Result is the output of a completion together with its usage.
+type Result struct {
+	Text  string
+	Usage Usage
+
+	// FallbackFrom is the provider that was tried first when another one
+	// in the fallback chain (Usage.Provider) ended up answering.
+	FallbackFrom string
+}
+
+// UsageRecord is reported to the UsageRecorder after every Registry call,
+// successful or not. Err is the call's error, if any.
+type UsageRecord struct {
+	Task TaskType
+	Usage
+	OutputChars int // Length of the generated text in characters
+	Err         error
+}
+
+// UsageRecorder receives a record for every completion made through the
+// Registry. Implementations must not block for long: they run inline
+// after each call.
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, rec UsageRecord)
+}
+
+// String returns the task name stored with usage records.
+func (t TaskType) String() string {
+	switch t {
+	case TaskContent:
+		return "content"
+	case TaskTemplate:
+		return "template"
+	case TaskLight:
+		return "light"
+	case TaskEmbedding:
+		return "embedding"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// vision.go implements image input: the optional ImageDescriber interface
+// for providers whose models accept images, and Registry.DescribeImage.
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+)
+
+// Image is an image sent to a vision model.
+type Image struct {
+	Data     []byte
+	MIMEType string // e.g. "image/webp"
+}
+
+// base64 returns the image data base64-encoded.
+func (img Image) base64() string {
+	return base64.StdEncoding.EncodeToString(img.Data)
+}
+
+// dataURL returns the image as a data: URL.
+func (img Image) dataURL() string {
+	return "data:" + img.MIMEType + ";base64," + img.base64()
+}
+
+// ImageDescriber is an optional interface for providers whose models
+// accept image input (OpenAI, Gemini and Claude). Mistral, compatible
+// servers and Ollama don't implement it, since vision support varies by
+// model there.
+type ImageDescriber interface {
+	// DescribeImage sends img together with a text prompt as a single
+	// user turn and returns the reply. If model is empty, falls back to
+	// the default.
+	DescribeImage(ctx context.Context, model, systemPrompt, prompt string, img Image) (Result, error)
+}
+
+// SupportsVision returns true if any registered provider accepts images.
+func (r *Registry) SupportsVision() bool {
+	_, _, err := r.resolveImageDescriber()
+	return err == nil
+}
+
+// DescribeImage asks a vision-capable provider about img and decodes its
+// JSON reply into out, which must point to the struct schema was derived
+// from. The active provider is used when it accepts images, otherwise the
+// first one that does. Calls use the provider's light model and are
+// recorded as TaskLight; they don't fail over.
+func (r *Registry) DescribeImage(ctx context.Context, schema *Schema, systemPrompt, prompt string, img Image, out any) (Result, error) {
+	name, d, err := r.resolveImageDescriber()
+	if err != nil {
+		return Result{}, err
+	}
+
+	r.mu.RLock()
+	model := r.configs[name].ModelForTask(TaskLight)
+	r.mu.RUnlock()
+
+	res, err := r.record(ctx, TaskLight, name, model, func() (Result, error) {
+		return d.DescribeImage(ctx, model, systemPrompt+"\n\n"+jsonInstructions(schema), prompt, img)
+	})
+	if err != nil {
+		return res, err
+	}
+	if err := decodeJSON(schema, extractJSON(res.Text), out); err != nil {
+		return res, fmt.Errorf("ai: invalid %s reply: %w", schema.Name, err)
+	}
+	return res, nil
+}
+
+// resolveImageDescriber returns the active provider if it accepts images,
+// otherwise the first such provider by name.
+func (r *Registry) resolveImageDescriber() (string, ImageDescriber, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if d, ok := r.providers[r.active].(ImageDescriber); ok {
+		return r.active, d, nil
+	}
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if d, ok := r.providers[name].(ImageDescriber); ok {
+			return name, d, nil
+		}
+	}
+	return "", nil, fmt.Errorf("ai: no provider accepts images (requires an OpenAI, Gemini or Claude key)")
+}
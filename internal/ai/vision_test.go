@@ -0,0 +1,148 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+var testImage = Image{Data: []byte("webp"), MIMEType: "image/webp"}
+
+// visionProvider is a mockProvider that also accepts images.
+type visionProvider struct {
+	mockProvider
+	model string
+	img   Image
+}
+
+func (v *visionProvider) DescribeImage(_ context.Context, model, systemPrompt, prompt string, img Image) (Result, error) {
+	v.model, v.img = model, img
+	v.lastSystem, v.lastUser = systemPrompt, prompt
+	return Result{Text: v.response}, v.err
+}
+
+func TestRegistryDescribeImage(t *testing.T) {
+	text := &mockProvider{name: "mistral"}
+	vision := &visionProvider{mockProvider: mockProvider{name: "gemini", response: "```json\n{\"description\":\"d\",\"keywords\":[],\"score\":1,\"author\":{\"name\":\"\"}}\n```"}}
+	reg := newFailoverRegistry(text, vision)
+	reg.configs["gemini"] = ProviderConfig{Model: "pro", ModelLight: "flash"}
+
+	if !reg.SupportsVision() {
+		t.Fatal("SupportsVision: got false")
+	}
+
+	// The active provider has no vision, so the other one is used.
+	var out testSEO
+	res, err := reg.DescribeImage(context.Background(), testSEOSchema, "Describe.", "alt text please", testImage, &out)
+	if err != nil {
+		t.Fatalf("DescribeImage: %v", err)
+	}
+	if out.Description != "d" || res.Usage.Provider != "gemini" {
+		t.Errorf("got %+v, usage %+v", out, res.Usage)
+	}
+	if vision.model != "flash" || string(vision.img.Data) != "webp" {
+		t.Errorf("call: model %q, image %q", vision.model, vision.img.Data)
+	}
+	if !strings.HasPrefix(vision.lastSystem, "Describe.") || !strings.Contains(vision.lastSystem, "JSON Schema") {
+		t.Errorf("system prompt: got %q", vision.lastSystem)
+	}
+
+	vision.response = "no json"
+	if _, err := reg.DescribeImage(context.Background(), testSEOSchema, "Describe.", "p", testImage, &out); err == nil {
+		t.Error("invalid reply accepted")
+	}
+
+	if newFailoverRegistry(text).SupportsVision() {
+		t.Error("SupportsVision: got true without a vision provider")
+	}
+}
+
+func TestOpenAIDescribeImage_SendsDataURL(t *testing.T) {
+	var req map[string]any
+	srv := newCaptureServer(t, openAISuccessBody("a cat"), &req)
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+	res, err := p.DescribeImage(context.Background(), "gpt-4o-mini", "sys", "describe", testImage)
+	if err != nil || res.Text != "a cat" {
+		t.Fatalf("DescribeImage: %q, %v", res.Text, err)
+	}
+
+	messages, _ := req["messages"].([]any)
+	if len(messages) != 2 {
+		t.Fatalf("messages: got %v", req["messages"])
+	}
+	user, _ := messages[1].(map[string]any)
+	parts, _ := user["content"].([]any)
+	if len(parts) != 2 {
+		t.Fatalf("content parts: got %v", user["content"])
+	}
+	image, _ := parts[0].(map[string]any)["image_url"].(map[string]any)
+	if image["url"] != "data:image/webp;base64,d2VicA==" {
+		t.Errorf("image_url: got %v", image)
+	}
+}
+
+func TestClaudeDescribeImage_SendsImageBlock(t *testing.T) {
+	var req map[string]any
+	srv := newCaptureServer(t, claudeSuccessBody("a cat"), &req)
+	defer srv.Close()
+
+	p := newClaude(ProviderConfig{APIKey: "k", Model: "claude-sonnet", BaseURL: srv.URL})
+	if _, err := p.DescribeImage(context.Background(), "", "sys", "describe", testImage); err != nil {
+		t.Fatalf("DescribeImage: %v", err)
+	}
+	if req["system"] != "sys" {
+		t.Errorf("system: got %v", req["system"])
+	}
+	messages, _ := req["messages"].([]any)
+	blocks, _ := messages[0].(map[string]any)["content"].([]any)
+	if len(blocks) != 2 {
+		t.Fatalf("content blocks: got %v", messages)
+	}
+	source, _ := blocks[0].(map[string]any)["source"].(map[string]any)
+	if source["type"] != "base64" || source["media_type"] != "image/webp" || source["data"] != "d2VicA==" {
+		t.Errorf("image source: got %v", source)
+	}
+}
+
+func TestGeminiDescribeImage_SendsInlineData(t *testing.T) {
+	var req geminiRequest
+	srv := newCaptureServer(t, geminiSuccessBody("a cat"), &req)
+	defer srv.Close()
+
+	p := newGemini(ProviderConfig{APIKey: "k", Model: "gemini-2.5-pro", BaseURL: srv.URL})
+	if _, err := p.DescribeImage(context.Background(), "", "sys", "describe", testImage); err != nil {
+		t.Fatalf("DescribeImage: %v", err)
+	}
+	if len(req.Contents) != 1 || len(req.Contents[0].Parts) != 2 {
+		t.Fatalf("contents: got %+v", req.Contents)
+	}
+	inline := req.Contents[0].Parts[0].InlineData
+	if inline == nil || inline.MimeType != "image/webp" || inline.Data != "d2VicA==" {
+		t.Errorf("inline data: got %+v", inline)
+	}
+	if req.Contents[0].Parts[1].Text != "describe" {
+		t.Errorf("prompt part: got %+v", req.Contents[0].Parts[1])
+	}
+}
+
+// Plain messages keep the string content format.
+func TestMessageMarshal_PlainContent(t *testing.T) {
+	var req map[string]any
+	srv := newCaptureServer(t, openAISuccessBody("ok"), &req)
+	defer srv.Close()
+
+	p := newOpenAI(ProviderConfig{APIKey: "k", Model: "gpt-4o", BaseURL: srv.URL})
+	if _, err := p.Generate(context.Background(), "sys", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	messages, _ := req["messages"].([]any)
+	if c := messages[1].(map[string]any)["content"]; c != "hi" {
+		t.Errorf("content: got %v", c)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// Package audit prepares AI prompts for the audit log. Redaction rules
+// strip email addresses, API keys and other secrets first; then either
+// the redacted text or only its hash is kept, depending on the policy.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PromptMode selects what the audit log keeps of a prompt.
+type PromptMode string
+
+const (
+	PromptHash PromptMode = "hash" // Only the SHA-256 of the redacted prompt
+	PromptFull PromptMode = "full" // The redacted prompt and its hash
+)
+
+// Rule replaces every match of Pattern with Replacement, which may refer
+// to submatches as in regexp.ReplaceAllString.
+type Rule struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// builtinRules are the rule sets selectable by name.
+var builtinRules = map[string][]Rule{
+	"emails": {
+		{Name: "emails", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`), Replacement: "[email]"},
+	},
+	"keys": {
+		// Prefixed provider keys: OpenAI, Anthropic, Stripe, Google, AWS,
+		// GitHub and Slack.
+		{Name: "keys", Pattern: regexp.MustCompile(`\b(?:sk|pk|rk)-[A-Za-z0-9_-]{16,}`), Replacement: "[key]"},
+		{Name: "keys", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`), Replacement: "[key]"},
+		{Name: "keys", Pattern: regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), Replacement: "[key]"},
+		{Name: "keys", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`), Replacement: "[key]"},
+		{Name: "keys", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), Replacement: "[key]"},
+		{Name: "keys", Pattern: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{16,}=*`), Replacement: "Bearer [key]"},
+		// Anything assigned to a key, secret, token or password.
+		{Name: "keys", Pattern: regexp.MustCompile(`(?i)\b(api[_-]?key|secret|token|password|passwd)(\s*[:=]\s*)["']?[^\s"',;]{6,}["']?`), Replacement: "${1}${2}[key]"},
+	},
+}
+
+// RuleNames returns the names of the built-in rule sets.
+func RuleNames() []string {
+	return []string{"emails", "keys"}
+}
+
+// Policy decides what is stored of each prompt.
+type Policy struct {
+	Mode  PromptMode
+	Rules []Rule
+}
+
+// NewPolicy builds a policy from the AI_AUDIT_* settings. mode is "hash"
+// or "full" (empty means hash); rules is a comma-separated list of
+// built-in rule sets ("emails,keys", or "none"); pattern is an optional
+// regular expression of your own whose matches become "[redacted]".
+func NewPolicy(mode, rules, pattern string) (*Policy, error) {
+	p := &Policy{Mode: PromptMode(strings.ToLower(strings.TrimSpace(mode)))}
+	switch p.Mode {
+	case "":
+		p.Mode = PromptHash
+	case PromptHash, PromptFull:
+	default:
+		return nil, fmt.Errorf("audit: unknown prompt mode %q (want hash or full)", mode)
+	}
+
+	for _, name := range strings.Split(rules, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == "none" {
+			continue
+		}
+		set, ok := builtinRules[name]
+		if !ok {
+			return nil, fmt.Errorf("audit: unknown redaction rule %q (want %s)", name, strings.Join(RuleNames(), ", "))
+		}
+		p.Rules = append(p.Rules, set...)
+	}
+
+	if pattern = strings.TrimSpace(pattern); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("audit: invalid redaction pattern: %w", err)
+		}
+		p.Rules = append(p.Rules, Rule{Name: "custom", Pattern: re, Replacement: "[redacted]"})
+	}
+	return p, nil
+}
+
+// Redact applies the rules to text in order.
+func (p *Policy) Redact(text string) string {
+	for _, r := range p.Rules {
+		text = r.Pattern.ReplaceAllString(text, r.Replacement)
+	}
+	return text
+}
+
+// Prompt returns what to store of a prompt: the hex SHA-256 of the
+// redacted prompt, and in full mode the redacted prompt itself. Hashing
+// the redacted text means nothing derived from a stripped secret is
+// stored. An empty prompt gives two empty strings.
+func (p *Policy) Prompt(prompt string) (hash, text string) {
+	if strings.TrimSpace(prompt) == "" {
+		return "", ""
+	}
+	redacted := p.Redact(prompt)
+	sum := sha256.Sum256([]byte(redacted))
+	hash = hex.EncodeToString(sum[:])
+	if p.Mode == PromptFull {
+		text = redacted
+	}
+	return hash, text
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// bus.go provides a pub/sub channel for broadcasting L1 template cache
+// invalidations between replicas. The L2 page cache already lives in a
+// shared Valkey, but every replica keeps its own in-memory compiled
+// templates, so an update on one pod must be announced to the others.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultInvalidationChannel is the Valkey pub/sub channel used for
+// template cache invalidation events.
+const DefaultInvalidationChannel = "templates:invalidate"
+
+// InvalidationEvent announces that compiled templates must be dropped.
+// Either TemplateID is set (one template) or All is true (every template).
+type InvalidationEvent struct {
+	Origin     string `json:"origin"`                // Replica that published the event
+	TemplateID string `json:"template_id,omitempty"` // Template UUID as string
+	All        bool   `json:"all,omitempty"`         // Clear the whole L1 cache
+}
+
+// InvalidationBus transports invalidation events between replicas.
+type InvalidationBus interface {
+	// Publish broadcasts an event to every subscriber, including the
+	// publisher itself — receivers filter on Origin.
+	Publish(ctx context.Context, ev InvalidationEvent) error
+
+	// Subscribe registers handlers and returns once the subscription is
+	// active. onEvent is called for every event; onResync is called after
+	// any gap in delivery (e.g. a reconnect) during which events may have
+	// been missed. Delivery stops when ctx is cancelled.
+	Subscribe(ctx context.Context, onEvent func(InvalidationEvent), onResync func()) error
+}
+
+// --- Valkey bus ---
+
+// ValkeyBus implements InvalidationBus using Valkey PUBLISH/SUBSCRIBE.
+type ValkeyBus struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewValkeyBus creates a bus on the given channel. An empty channel uses
+// DefaultInvalidationChannel.
+func NewValkeyBus(client *redis.Client, channel string) *ValkeyBus {
+	if channel == "" {
+		channel = DefaultInvalidationChannel
+	}
+	return &ValkeyBus{client: client, channel: channel}
+}
+
+// Publish JSON-encodes the event and publishes it on the bus channel.
+func (b *ValkeyBus) Publish(ctx context.Context, ev InvalidationEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("invalidation marshal: %w", err)
+	}
+	if err := b.client.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf("invalidation publish: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the bus channel and waits for the server's
+// confirmation before returning, so events published afterwards are
+// guaranteed to be delivered. Messages are processed in a background
+// goroutine until ctx is cancelled.
+func (b *ValkeyBus) Subscribe(ctx context.Context, onEvent func(InvalidationEvent), onResync func()) error {
+	ps := b.client.Subscribe(ctx, b.channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return fmt.Errorf("invalidation subscribe: %w", err)
+	}
+
+	// Receive blocks on the socket and ignores cancellation; closing the
+	// PubSub is what unblocks it on shutdown.
+	go func() {
+		<-ctx.Done()
+		ps.Close()
+	}()
+
+	go b.listen(ctx, ps, onEvent, onResync)
+
+	slog.Info("template invalidation bus subscribed", "channel", b.channel)
+	return nil
+}
+
+// listen reads messages until ctx is cancelled. go-redis reconnects and
+// resubscribes on its own after a dropped connection; every subscribe
+// confirmation after the initial one therefore marks a gap, and so does
+// the first message following a receive error.
+func (b *ValkeyBus) listen(ctx context.Context, ps *redis.PubSub, onEvent func(InvalidationEvent), onResync func()) {
+	gap := false
+	for {
+		msg, err := ps.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !gap {
+				slog.Warn("template invalidation bus receive failed", "error", err)
+			}
+			gap = true
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				slog.Info("template invalidation bus resubscribed, flushing L1 cache")
+				gap = false
+				onResync()
+			}
+		case *redis.Message:
+			if gap {
+				gap = false
+				onResync()
+			}
+			var ev InvalidationEvent
+			if err := json.Unmarshal([]byte(m.Payload), &ev); err != nil {
+				slog.Warn("template invalidation bus: bad payload", "error", err)
+				continue
+			}
+			onEvent(ev)
+		}
+	}
+}
+
+// --- In-memory bus ---
+
+// MemoryBus is an in-process InvalidationBus for tests and single-binary
+// setups. Several engines sharing one MemoryBus behave like replicas
+// sharing a Valkey channel. Events are delivered synchronously.
+type MemoryBus struct {
+	mu   sync.Mutex
+	next int
+	subs map[int]memorySub
+}
+
+type memorySub struct {
+	onEvent  func(InvalidationEvent)
+	onResync func()
+}
+
+// NewMemoryBus creates an empty in-memory bus.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subs: make(map[int]memorySub)}
+}
+
+// Publish delivers the event to every current subscriber.
+func (b *MemoryBus) Publish(_ context.Context, ev InvalidationEvent) error {
+	for _, s := range b.snapshot() {
+		s.onEvent(ev)
+	}
+	return nil
+}
+
+// Subscribe registers the handlers until ctx is cancelled.
+func (b *MemoryBus) Subscribe(ctx context.Context, onEvent func(InvalidationEvent), onResync func()) error {
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.subs[id] = memorySub{onEvent: onEvent, onResync: onResync}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+// SimulateGap calls every subscriber's resync handler, as a Valkey bus
+// would after a reconnect.
+func (b *MemoryBus) SimulateGap() {
+	for _, s := range b.snapshot() {
+		s.onResync()
+	}
+}
+
+// snapshot copies the subscriber list so handlers run without the lock.
+func (b *MemoryBus) snapshot() []memorySub {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := make([]memorySub, 0, len(b.subs))
+	for _, s := range b.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestValkeyBusPublishSubscribe(t *testing.T) {
+	client := testValkeyClient(t)
+	bus := NewValkeyBus(client, "test:templates:invalidate")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan InvalidationEvent, 4)
+	err := bus.Subscribe(ctx, func(ev InvalidationEvent) { events <- ev }, func() {})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	want := InvalidationEvent{Origin: "replica-a", TemplateID: "tmpl-1"}
+	if err := bus.Publish(ctx, want); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case got := <-events:
+		if got != want {
+			t.Errorf("event: got %+v, want %+v", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for invalidation event")
+	}
+}
+
+func TestNewValkeyBusDefaultChannel(t *testing.T) {
+	bus := NewValkeyBus(nil, "")
+	if bus.channel != DefaultInvalidationChannel {
+		t.Errorf("channel: got %q, want %q", bus.channel, DefaultInvalidationChannel)
+	}
+}
+
+func TestMemoryBus(t *testing.T) {
+	t.Run("delivers to all subscribers", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var a, b []InvalidationEvent
+		bus.Subscribe(ctx, func(ev InvalidationEvent) { a = append(a, ev) }, func() {})
+		bus.Subscribe(ctx, func(ev InvalidationEvent) { b = append(b, ev) }, func() {})
+
+		bus.Publish(ctx, InvalidationEvent{All: true})
+
+		if len(a) != 1 || len(b) != 1 {
+			t.Fatalf("expected one event per subscriber, got %d and %d", len(a), len(b))
+		}
+		if !a[0].All {
+			t.Error("expected All=true")
+		}
+	})
+
+	t.Run("gap triggers resync", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		resyncs := 0
+		bus.Subscribe(ctx, func(InvalidationEvent) {}, func() { resyncs++ })
+		bus.SimulateGap()
+
+		if resyncs != 1 {
+			t.Errorf("resyncs: got %d, want 1", resyncs)
+		}
+	})
+
+	t.Run("cancelled subscriber stops receiving", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		received := make(chan struct{}, 1)
+		bus.Subscribe(ctx, func(InvalidationEvent) { received <- struct{}{} }, func() {})
+		cancel()
+
+		// Unsubscription happens asynchronously after cancel.
+		deadline := time.Now().Add(time.Second)
+		for len(bus.snapshot()) > 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		bus.Publish(context.Background(), InvalidationEvent{All: true})
+		select {
+		case <-received:
+			t.Error("cancelled subscriber should not receive events")
+		default:
+		}
+	})
+}
@@ -177,6 +177,19 @@ func TestSlugKey(t *testing.T) {
 	}
 }
 
+func TestLocaleSlugKey(t *testing.T) {
+	key := LocaleSlugKey("de", "ueber-uns")
+	if key != "de:ueber-uns" {
+		t.Errorf("LocaleSlugKey: got %q", key)
+	}
+	if l, s := ParseSlugKey(key); l != "de" || s != "ueber-uns" {
+		t.Errorf("ParseSlugKey(%q): got %q, %q", key, l, s)
+	}
+	if l, s := ParseSlugKey(SlugKey("about-us")); l != "" || s != "about-us" {
+		t.Errorf("ParseSlugKey(slug): got %q, %q", l, s)
+	}
+}
+
 func TestNewPageCacheDefaultTTL(t *testing.T) {
 	client := testValkeyClient(t)
 
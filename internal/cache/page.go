@@ -12,6 +12,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -50,6 +51,7 @@ func (pc *PageCache) Get(ctx context.Context, key string) ([]byte, bool) {
 		return nil, false
 	}
 	slog.Debug("page cache hit", "key", key)
+	pc.record(ctx, statsHitsKey, key)
 	return val, true
 }
 
@@ -61,34 +63,40 @@ func (pc *PageCache) Set(ctx context.Context, key string, html []byte) {
 }
 
 // InvalidatePage removes a single page from the cache by its slug.
-func (pc *PageCache) InvalidatePage(ctx context.Context, slug string) {
-	if err := pc.client.Del(ctx, pageKeyPrefix+slug).Err(); err != nil {
+// Returns the number of keys removed (0 or 1).
+func (pc *PageCache) InvalidatePage(ctx context.Context, slug string) int {
+	n, err := pc.client.Del(ctx, pageKeyPrefix+slug).Result()
+	if err != nil {
 		slog.Warn("page cache invalidate error", "slug", slug, "error", err)
 	}
 	slog.Debug("page cache invalidated", "slug", slug)
+	return int(n)
 }
 
-// InvalidateHomepage removes the cached homepage.
-func (pc *PageCache) InvalidateHomepage(ctx context.Context) {
-	pc.InvalidatePage(ctx, "_homepage")
+// InvalidateHomepage removes the cached homepage. Returns the number of
+// keys removed.
+func (pc *PageCache) InvalidateHomepage(ctx context.Context) int {
+	return pc.InvalidatePage(ctx, "_homepage")
 }
 
 // InvalidateAll removes all cached pages by scanning for the prefix.
 // Used when templates change, since any page could be affected.
-func (pc *PageCache) InvalidateAll(ctx context.Context) {
+// Returns the number of keys removed.
+func (pc *PageCache) InvalidateAll(ctx context.Context) int {
 	var cursor uint64
 	var deleted int
 	for {
 		keys, nextCursor, err := pc.client.Scan(ctx, cursor, pageKeyPrefix+"*", 100).Result()
 		if err != nil {
 			slog.Warn("page cache scan error", "error", err)
-			return
+			return deleted
 		}
 		if len(keys) > 0 {
-			if err := pc.client.Del(ctx, keys...).Err(); err != nil {
+			n, err := pc.client.Del(ctx, keys...).Result()
+			if err != nil {
 				slog.Warn("page cache bulk delete error", "error", err)
 			}
-			deleted += len(keys)
+			deleted += int(n)
 		}
 		cursor = nextCursor
 		if cursor == 0 {
@@ -98,6 +106,7 @@ func (pc *PageCache) InvalidateAll(ctx context.Context) {
 	if deleted > 0 {
 		slog.Info("page cache fully cleared", "deleted", deleted)
 	}
+	return deleted
 }
 
 // HomepageKey returns the cache key for the homepage.
@@ -109,3 +118,18 @@ func HomepageKey() string {
 func SlugKey(slug string) string {
 	return fmt.Sprintf("%s", slug)
 }
+
+// LocaleSlugKey returns the cache key for a translation served at
+// /{locale}/{slug}.
+func LocaleSlugKey(locale, slug string) string {
+	return locale + ":" + slug
+}
+
+// ParseSlugKey splits a key made by SlugKey or LocaleSlugKey into its
+// locale (empty for SlugKey) and slug.
+func ParseSlugKey(key string) (locale, slug string) {
+	if l, s, ok := strings.Cut(key, ":"); ok {
+		return l, s
+	}
+	return "", key
+}
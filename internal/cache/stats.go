@@ -0,0 +1,203 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// stats.go keeps per-key hit/miss counters for the L2 page cache in
+// Valkey and reports key count and memory use for the admin cache page.
+// Counters live in two hashes (field = cache key) so they are shared by
+// all replicas and survive restarts.
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// statsHitsKey and statsMissesKey hold per-key counters. They must not
+	// use pageKeyPrefix, or InvalidateAll would wipe them.
+	statsHitsKey   = "cache:stats:hits"
+	statsMissesKey = "cache:stats:misses"
+)
+
+// KeyStats holds the hit/miss counters and cache state of one page key.
+type KeyStats struct {
+	Key    string
+	Hits   int64
+	Misses int64
+	Cached bool  // Currently present in the cache
+	Bytes  int64 // Memory used by the cached entry, 0 if not cached
+}
+
+// HitRatio returns hits / (hits + misses) as 0–100, or -1 with no traffic.
+func (k KeyStats) HitRatio() int {
+	return ratio(k.Hits, k.Misses)
+}
+
+// HumanSize returns the cached entry's memory use as a human-readable string.
+func (k KeyStats) HumanSize() string {
+	return humanBytes(k.Bytes)
+}
+
+// Stats is a snapshot of the page cache for the admin dashboard.
+type Stats struct {
+	Keys        map[string]*KeyStats // By cache key (slug or HomepageKey())
+	TotalHits   int64
+	TotalMisses int64
+	KeyCount    int    // Cached pages
+	KeyBytes    int64  // Memory used by cached pages
+	UsedMemory  string // Server-wide used_memory_human from INFO
+}
+
+// HitRatio returns the overall hit ratio as 0–100, or -1 with no traffic.
+func (s *Stats) HitRatio() int {
+	return ratio(s.TotalHits, s.TotalMisses)
+}
+
+// HumanKeyBytes returns the memory used by cached pages as a
+// human-readable string.
+func (s *Stats) HumanKeyBytes() string {
+	return humanBytes(s.KeyBytes)
+}
+
+// Key returns the stats for a key, or zero stats if it was never seen.
+func (s *Stats) Key(key string) *KeyStats {
+	if k, ok := s.Keys[key]; ok {
+		return k
+	}
+	return &KeyStats{Key: key}
+}
+
+// humanBytes formats a byte count as B, KB, or MB.
+func humanBytes(n int64) string {
+	const (
+		kb = 1024
+		mb = 1024 * kb
+	)
+	switch {
+	case n >= mb:
+		return fmt.Sprintf("%.1f MB", float64(n)/float64(mb))
+	case n >= kb:
+		return fmt.Sprintf("%.0f KB", float64(n)/float64(kb))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func ratio(hits, misses int64) int {
+	if hits+misses == 0 {
+		return -1
+	}
+	return int(hits * 100 / (hits + misses))
+}
+
+// RecordMiss counts a cache miss for a key. Called by handlers once they
+// have rendered a page that exists, rather than from Get, so requests for
+// unknown slugs cannot grow the counter hash without bound.
+func (pc *PageCache) RecordMiss(ctx context.Context, key string) {
+	pc.record(ctx, statsMissesKey, key)
+}
+
+// record increments a counter field. Best-effort: errors are logged.
+func (pc *PageCache) record(ctx context.Context, hash, key string) {
+	if err := pc.client.HIncrBy(ctx, hash, key, 1).Err(); err != nil {
+		slog.Debug("page cache stats update failed", "key", key, "error", err)
+	}
+}
+
+// ResetStats clears all hit/miss counters.
+func (pc *PageCache) ResetStats(ctx context.Context) error {
+	if err := pc.client.Del(ctx, statsHitsKey, statsMissesKey).Err(); err != nil {
+		return fmt.Errorf("reset cache stats: %w", err)
+	}
+	return nil
+}
+
+// Stats collects hit/miss counters, the set of cached keys with their
+// memory use, and the server's overall memory use.
+func (pc *PageCache) Stats(ctx context.Context) (*Stats, error) {
+	st := &Stats{Keys: make(map[string]*KeyStats)}
+	key := func(k string) *KeyStats {
+		if _, ok := st.Keys[k]; !ok {
+			st.Keys[k] = &KeyStats{Key: k}
+		}
+		return st.Keys[k]
+	}
+
+	hits, err := pc.client.HGetAll(ctx, statsHitsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("read cache hits: %w", err)
+	}
+	misses, err := pc.client.HGetAll(ctx, statsMissesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("read cache misses: %w", err)
+	}
+	for k, v := range hits {
+		n, _ := strconv.ParseInt(v, 10, 64)
+		key(k).Hits = n
+		st.TotalHits += n
+	}
+	for k, v := range misses {
+		n, _ := strconv.ParseInt(v, 10, 64)
+		key(k).Misses = n
+		st.TotalMisses += n
+	}
+
+	// Walk the cached pages and fetch their memory use in one pipeline
+	// per SCAN batch.
+	var cursor uint64
+	for {
+		keys, next, err := pc.client.Scan(ctx, cursor, pageKeyPrefix+"*", 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("scan cached pages: %w", err)
+		}
+		if len(keys) > 0 {
+			pipe := pc.client.Pipeline()
+			cmds := make([]*redis.IntCmd, len(keys))
+			for i, k := range keys {
+				cmds[i] = pipe.MemoryUsage(ctx, k)
+			}
+			// Keys may expire between SCAN and MEMORY USAGE; those
+			// commands fail individually and are skipped below.
+			pipe.Exec(ctx)
+			for i, k := range keys {
+				bytes, err := cmds[i].Result()
+				if err != nil {
+					continue
+				}
+				ks := key(strings.TrimPrefix(k, pageKeyPrefix))
+				ks.Cached = true
+				ks.Bytes = bytes
+				st.KeyCount++
+				st.KeyBytes += bytes
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if info, err := pc.client.Info(ctx, "memory").Result(); err == nil {
+		st.UsedMemory = infoField(info, "used_memory_human")
+	}
+
+	return st, nil
+}
+
+// infoField extracts a "name:value" line from an INFO reply.
+func infoField(info, name string) string {
+	sc := bufio.NewScanner(strings.NewReader(info))
+	for sc.Scan() {
+		if v, ok := strings.CutPrefix(sc.Text(), name+":"); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
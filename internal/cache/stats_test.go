@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPageCacheStats(t *testing.T) {
+	client := testValkeyClient(t)
+	pc := NewPageCache(client, 1*time.Minute)
+	ctx := context.Background()
+
+	if err := pc.ResetStats(ctx); err != nil {
+		t.Fatalf("ResetStats: %v", err)
+	}
+	t.Cleanup(func() { pc.ResetStats(ctx) })
+
+	// One miss (render + store), then two hits.
+	pc.Get(ctx, "stats-page")
+	pc.RecordMiss(ctx, "stats-page")
+	pc.Set(ctx, "stats-page", []byte("<p>stats</p>"))
+	pc.Get(ctx, "stats-page")
+	pc.Get(ctx, "stats-page")
+
+	st, err := pc.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+
+	ks := st.Key("stats-page")
+	if ks.Hits != 2 || ks.Misses != 1 {
+		t.Errorf("stats-page: got %d hits / %d misses, want 2 / 1", ks.Hits, ks.Misses)
+	}
+	if ks.HitRatio() != 66 {
+		t.Errorf("HitRatio: got %d, want 66", ks.HitRatio())
+	}
+	if !ks.Cached || ks.Bytes <= 0 {
+		t.Errorf("expected stats-page to be cached with a size, got %+v", ks)
+	}
+	if st.KeyCount < 1 {
+		t.Errorf("KeyCount: got %d, want >= 1", st.KeyCount)
+	}
+
+	// InvalidateAll must not touch the counters.
+	if n := pc.InvalidateAll(ctx); n < 1 {
+		t.Errorf("InvalidateAll: got %d keys removed, want >= 1", n)
+	}
+	st, err = pc.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats after InvalidateAll: %v", err)
+	}
+	if st.Key("stats-page").Hits != 2 {
+		t.Error("counters should survive InvalidateAll")
+	}
+	if st.Key("stats-page").Cached {
+		t.Error("stats-page should no longer be cached")
+	}
+}
+
+func TestStatsHelpers(t *testing.T) {
+	if got := (KeyStats{}).HitRatio(); got != -1 {
+		t.Errorf("HitRatio with no traffic: got %d, want -1", got)
+	}
+	if got := (KeyStats{Hits: 3, Misses: 1}).HitRatio(); got != 75 {
+		t.Errorf("HitRatio: got %d, want 75", got)
+	}
+
+	sizes := map[int64]string{
+		512:             "512 B",
+		2048:            "2 KB",
+		3 * 1024 * 1024: "3.0 MB",
+	}
+	for n, want := range sizes {
+		if got := humanBytes(n); got != want {
+			t.Errorf("humanBytes(%d): got %q, want %q", n, got, want)
+		}
+	}
+
+	info := "# Memory\r\nused_memory:1048576\r\nused_memory_human:1.00M\r\n"
+	if got := infoField(info, "used_memory_human"); got != "1.00M" {
+		t.Errorf("infoField: got %q, want %q", got, "1.00M")
+	}
+	if got := infoField(info, "missing"); got != "" {
+		t.Errorf("infoField for missing field: got %q, want empty", got)
+	}
+}
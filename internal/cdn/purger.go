@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// Package cdn purges the CDN in front of the public site whenever the
+// L2 page cache is invalidated, so the edge never serves pages that are
+// stale relative to Valkey. Purges are described by URL and surrogate
+// key (the tags sent in the Surrogate-Key response header).
+package cdn
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Surrogate keys attached to public responses. Every page carries
+// SurrogateKeySite plus either SurrogateKeyHomepage or PageKey(slug).
+const (
+	SurrogateKeySite     = "site"
+	SurrogateKeyHomepage = "homepage"
+)
+
+// PageKey returns the surrogate key for a content slug.
+func PageKey(slug string) string {
+	return "page-" + slug
+}
+
+// Request describes what to purge. All purges the whole site and makes
+// URLs and SurrogateKeys redundant.
+type Request struct {
+	URLs          []string `json:"urls,omitempty"`
+	SurrogateKeys []string `json:"surrogate_keys,omitempty"`
+	All           bool     `json:"purge_all,omitempty"`
+}
+
+// Empty reports whether the request purges nothing.
+func (r Request) Empty() bool {
+	return !r.All && len(r.URLs) == 0 && len(r.SurrogateKeys) == 0
+}
+
+// Merge combines other into r, dropping duplicates. Merging a purge-all
+// request collapses the result to a purge-all.
+func (r Request) Merge(other Request) Request {
+	if r.All || other.All {
+		return Request{All: true}
+	}
+	return Request{
+		URLs:          mergeUnique(r.URLs, other.URLs),
+		SurrogateKeys: mergeUnique(r.SurrogateKeys, other.SurrogateKeys),
+	}
+}
+
+func mergeUnique(a, b []string) []string {
+	out := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Purger removes content from a CDN.
+type Purger interface {
+	Purge(ctx context.Context, req Request) error
+}
+
+// RecordingPurger is an in-memory Purger for tests and local development.
+// It records every request and optionally returns a fixed error.
+type RecordingPurger struct {
+	mu    sync.Mutex
+	calls []Request
+	Err   error // Returned from every Purge call when set
+}
+
+// Purge records the request.
+func (p *RecordingPurger) Purge(_ context.Context, req Request) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, req)
+	return p.Err
+}
+
+// Calls returns a copy of every request received so far.
+func (p *RecordingPurger) Calls() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.calls)
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequestMerge(t *testing.T) {
+	a := Request{URLs: []string{"/a", "/"}, SurrogateKeys: []string{PageKey("a")}}
+	b := Request{URLs: []string{"/b", "/"}, SurrogateKeys: []string{PageKey("b"), PageKey("a")}}
+
+	got := a.Merge(b)
+	if want := []string{"/a", "/", "/b"}; !slices.Equal(got.URLs, want) {
+		t.Errorf("URLs: got %v, want %v", got.URLs, want)
+	}
+	if want := []string{"page-a", "page-b"}; !slices.Equal(got.SurrogateKeys, want) {
+		t.Errorf("SurrogateKeys: got %v, want %v", got.SurrogateKeys, want)
+	}
+
+	all := got.Merge(Request{All: true})
+	if !all.All || len(all.URLs) != 0 || len(all.SurrogateKeys) != 0 {
+		t.Errorf("merging purge-all should collapse, got %+v", all)
+	}
+
+	if !(Request{}).Empty() {
+		t.Error("zero request should be empty")
+	}
+	if (Request{All: true}).Empty() {
+		t.Error("purge-all request should not be empty")
+	}
+}
+
+func TestWebhookPurger(t *testing.T) {
+	var gotAuth string
+	var gotBody Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := NewWebhookPurger(srv.URL, "secret", "https://example.com/")
+	err := p.Purge(context.Background(), Request{
+		URLs:          []string{"/hello", "https://cdn.example.com/x"},
+		SurrogateKeys: []string{PageKey("hello")},
+	})
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization: got %q", gotAuth)
+	}
+	if want := []string{"https://example.com/hello", "https://cdn.example.com/x"}; !slices.Equal(gotBody.URLs, want) {
+		t.Errorf("URLs: got %v, want %v", gotBody.URLs, want)
+	}
+	if !slices.Equal(gotBody.SurrogateKeys, []string{"page-hello"}) {
+		t.Errorf("SurrogateKeys: got %v", gotBody.SurrogateKeys)
+	}
+}
+
+func TestWebhookPurgerStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	err := NewWebhookPurger(srv.URL, "", "").Purge(context.Background(), Request{All: true})
+	var se *StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected StatusError, got %v", err)
+	}
+	if se.Code != http.StatusUnauthorized || se.Body != "bad token" {
+		t.Errorf("got %+v", se)
+	}
+	if se.Temporary() {
+		t.Error("401 should not be temporary")
+	}
+	if !(&StatusError{Code: 503}).Temporary() || !(&StatusError{Code: 429}).Temporary() {
+		t.Error("503 and 429 should be temporary")
+	}
+}
+
+// flakyPurger fails the first n calls with err, then succeeds.
+type flakyPurger struct {
+	mu    sync.Mutex
+	n     int
+	err   error
+	calls int
+	last  Request
+}
+
+func (p *flakyPurger) Purge(_ context.Context, req Request) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	p.last = req
+	if p.calls <= p.n {
+		return p.err
+	}
+	return nil
+}
+
+func (p *flakyPurger) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// waitFor polls cond until it is true or the deadline passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueueBatches(t *testing.T) {
+	rec := &RecordingPurger{}
+	q := NewQueue(rec, 50*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	q.Purge(ctx, Request{URLs: []string{"/a", "/"}})
+	q.Purge(ctx, Request{URLs: []string{"/b", "/"}})
+	waitFor(t, func() bool { return len(rec.Calls()) == 1 })
+
+	cancel()
+	q.Wait()
+
+	calls := rec.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected one batched purge, got %d", len(calls))
+	}
+	if want := []string{"/a", "/", "/b"}; !slices.Equal(calls[0].URLs, want) {
+		t.Errorf("URLs: got %v, want %v", calls[0].URLs, want)
+	}
+}
+
+func TestQueueRetriesTemporaryErrors(t *testing.T) {
+	p := &flakyPurger{n: 2, err: &StatusError{Code: 502}}
+	q := NewQueue(p, 10*time.Millisecond)
+	q.backoff = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	q.Purge(ctx, Request{All: true})
+	waitFor(t, func() bool { return p.Calls() == 3 })
+
+	cancel()
+	q.Wait()
+	if p.Calls() != 3 {
+		t.Errorf("expected 2 failures then success (3 calls), got %d", p.Calls())
+	}
+}
+
+func TestQueueDoesNotRetryPermanentErrors(t *testing.T) {
+	rec := &RecordingPurger{Err: &StatusError{Code: 403}}
+	q := NewQueue(rec, 10*time.Millisecond)
+	q.backoff = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	q.Purge(ctx, Request{All: true})
+	waitFor(t, func() bool { return len(rec.Calls()) >= 1 })
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	q.Wait()
+	if n := len(rec.Calls()); n != 1 {
+		t.Errorf("expected a single attempt for 403, got %d", n)
+	}
+}
+
+func TestQueueFlushesOnShutdown(t *testing.T) {
+	rec := &RecordingPurger{}
+	q := NewQueue(rec, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	q.Purge(ctx, Request{SurrogateKeys: []string{PageKey("x")}})
+	cancel()
+	q.Wait()
+
+	calls := rec.Calls()
+	if len(calls) != 1 || !slices.Equal(calls[0].SurrogateKeys, []string{"page-x"}) {
+		t.Errorf("pending purge should be flushed on shutdown, got %+v", calls)
+	}
+}
+
+func TestQueueFlushesBatchWaitingToRetryOnShutdown(t *testing.T) {
+	p := &flakyPurger{n: 1, err: &StatusError{Code: 503}}
+	q := NewQueue(p, 10*time.Millisecond)
+	q.backoff = time.Hour // Shutdown comes while the retry is waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	q.Purge(ctx, Request{URLs: []string{"/a"}})
+	waitFor(t, func() bool { return p.Calls() == 1 })
+	q.Purge(ctx, Request{URLs: []string{"/b"}})
+	cancel()
+	q.Wait()
+
+	// The final flush sends the unsent batch with whatever arrived since.
+	if p.Calls() != 2 || !slices.Equal(p.last.URLs, []string{"/a", "/b"}) {
+		t.Errorf("expected the waiting batch in the final flush, got %d calls, last %+v", p.Calls(), p.last)
+	}
+}
+
+func TestQueueOverflowEscalatesToPurgeAll(t *testing.T) {
+	rec := &RecordingPurger{}
+	q := NewQueue(rec, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Fill the buffer before the loop runs so the next request overflows.
+	for i := 0; i < cap(q.in)+1; i++ {
+		q.Purge(ctx, Request{URLs: []string{"/p"}})
+	}
+	q.Start(ctx)
+	cancel()
+	q.Wait()
+
+	calls := rec.Calls()
+	if len(calls) != 1 || !calls[0].All {
+		t.Errorf("overflow should escalate to purge-all, got %+v", calls)
+	}
+}
+
+func TestQueueIgnoresEmptyRequests(t *testing.T) {
+	rec := &RecordingPurger{}
+	q := NewQueue(rec, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	q.Purge(ctx, Request{})
+	cancel()
+	q.Wait()
+
+	if n := len(rec.Calls()); n != 0 {
+		t.Errorf("empty request should not be sent, got %d calls", n)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// queue.go batches purge requests in the background so admin actions
+// never wait on the CDN, and retries failed purges with backoff.
+package cdn
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultBatchWindow is how long the queue collects requests before
+	// sending them as one purge.
+	DefaultBatchWindow = 2 * time.Second
+
+	// maxAttempts is the number of tries per batch before it is dropped.
+	maxAttempts = 5
+
+	// flushTimeout bounds the final flush on shutdown.
+	flushTimeout = 10 * time.Second
+)
+
+// Queue is a Purger that batches requests and sends them to another
+// Purger from a background goroutine. Purge never blocks and never fails.
+type Queue struct {
+	purger  Purger
+	window  time.Duration
+	backoff time.Duration // Initial retry delay, doubled per attempt
+
+	in       chan Request
+	overflow atomic.Bool // Set when a request was rejected by a full buffer
+	done     chan struct{}
+}
+
+// NewQueue creates a queue in front of purger. Requests arriving within
+// window of each other are merged into one purge.
+func NewQueue(purger Purger, window time.Duration) *Queue {
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+	return &Queue{
+		purger:  purger,
+		window:  window,
+		backoff: time.Second,
+		in:      make(chan Request, 256),
+		done:    make(chan struct{}),
+	}
+}
+
+// Purge enqueues the request. If the queue is full the request is
+// upgraded to a purge-all on the next batch rather than dropped.
+func (q *Queue) Purge(_ context.Context, req Request) error {
+	if req.Empty() {
+		return nil
+	}
+	select {
+	case q.in <- req:
+	default:
+		if !q.overflow.Swap(true) {
+			slog.Warn("cdn purge queue full, escalating to purge-all")
+		}
+	}
+	return nil
+}
+
+// Start runs the batching loop until ctx is cancelled, then flushes any
+// pending purge. Use Wait to block until the flush is done.
+func (q *Queue) Start(ctx context.Context) {
+	go q.run(ctx)
+}
+
+// Wait blocks until the loop started by Start has exited.
+func (q *Queue) Wait() {
+	<-q.done
+}
+
+func (q *Queue) run(ctx context.Context) {
+	defer close(q.done)
+
+	var pending Request
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case req := <-q.in:
+			pending = pending.Merge(req)
+			if timer == nil {
+				timer = time.After(q.window)
+			}
+
+		case <-timer:
+			// A batch cut short by shutdown stays pending for the final
+			// flush.
+			pending, timer = q.send(ctx, q.takeOverflow(pending)), nil
+
+		case <-ctx.Done():
+			// Collect whatever is still buffered and make one last attempt
+			// with a fresh deadline, since ctx is already cancelled.
+		drain:
+			for {
+				select {
+				case req := <-q.in:
+					pending = pending.Merge(req)
+				default:
+					break drain
+				}
+			}
+			pending = q.takeOverflow(pending)
+			if !pending.Empty() {
+				flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+				if unsent := q.send(flushCtx, pending); !unsent.Empty() {
+					slog.Error("cdn purge abandoned on shutdown", "urls", unsent.URLs, "keys", unsent.SurrogateKeys, "all", unsent.All)
+				}
+				cancel()
+			}
+			return
+		}
+	}
+}
+
+// takeOverflow escalates the batch to a purge-all if any request was
+// rejected since the last batch, so nothing stays stale at the edge.
+func (q *Queue) takeOverflow(req Request) Request {
+	if q.overflow.Swap(false) {
+		return Request{All: true}
+	}
+	return req
+}
+
+// send delivers a batch, retrying temporary failures with exponential
+// backoff. A batch that still fails is logged and dropped. If ctx ends
+// while a retry is due, the batch is returned unsent so it can go out
+// with the final flush; otherwise the result is empty.
+func (q *Queue) send(ctx context.Context, req Request) Request {
+	delay := q.backoff
+	for attempt := 1; ; attempt++ {
+		err := q.purger.Purge(ctx, req)
+		if err == nil {
+			slog.Debug("cdn purge sent", "urls", len(req.URLs), "keys", len(req.SurrogateKeys), "all", req.All)
+			return Request{}
+		}
+
+		var se *StatusError
+		permanent := errors.As(err, &se) && !se.Temporary()
+		if permanent || attempt >= maxAttempts {
+			slog.Error("cdn purge failed", "attempts", attempt, "urls", req.URLs, "keys", req.SurrogateKeys, "all", req.All, "error", err)
+			return Request{}
+		}
+
+		slog.Warn("cdn purge failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return req
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// webhook.go implements a generic HTTP purger: it POSTs the purge request
+// as JSON to a configured endpoint, which can be a CDN's purge API or a
+// small adapter in front of one.
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookPurger sends purge requests to an HTTP endpoint as
+//
+//	POST <url>
+//	Authorization: Bearer <token>
+//	{"urls": [...], "surrogate_keys": [...], "purge_all": true}
+//
+// Relative URLs are made absolute with the configured site URL.
+type WebhookPurger struct {
+	url     string
+	token   string
+	siteURL string
+	client  *http.Client
+}
+
+// NewWebhookPurger creates a webhook purger. token may be empty; siteURL
+// (e.g. "https://example.com") is prefixed to relative URLs when set.
+func NewWebhookPurger(url, token, siteURL string) *WebhookPurger {
+	return &WebhookPurger{
+		url:     url,
+		token:   token,
+		siteURL: strings.TrimRight(siteURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// StatusError is returned when the webhook responds with a non-2xx status.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("cdn purge webhook error (status %d): %s", e.Code, e.Body)
+}
+
+// Temporary reports whether retrying may succeed: server errors and
+// rate limiting are temporary, other client errors are not.
+func (e *StatusError) Temporary() bool {
+	return e.Code >= 500 || e.Code == http.StatusTooManyRequests
+}
+
+// Purge sends the request to the webhook.
+func (p *WebhookPurger) Purge(ctx context.Context, req Request) error {
+	if p.siteURL != "" {
+		abs := make([]string, len(req.URLs))
+		for i, u := range req.URLs {
+			if strings.HasPrefix(u, "/") {
+				u = p.siteURL + u
+			}
+			abs[i] = u
+		}
+		req.URLs = abs
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal purge request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create purge request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("cdn purge webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &StatusError{Code: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// ai_providers.go loads the additional AI providers (OpenAI-compatible
+// servers and Ollama) listed in AI_CUSTOM_PROVIDERS.
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// CustomAIProvider is a self-hosted or third-party model server
+// configured alongside the built-in providers. Each one is read from
+// variables prefixed with its upper-cased name, e.g. LOCAL_BASE_URL.
+type CustomAIProvider struct {
+	Name          string // Registry name, e.g. "local"
+	Kind          string // "openai_compatible" or "ollama"
+	Label         string // Shown in the provider selector; defaults to Name
+	BaseURL       string
+	APIKey        string // Optional
+	Model         string
+	ModelLight    string
+	ModelContent  string
+	ModelTemplate string
+}
+
+// customProviderName restricts names to what works as an env var prefix.
+var customProviderName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// builtinAIProviders can't be reused as custom provider names.
+var builtinAIProviders = map[string]bool{"openai": true, "gemini": true, "claude": true, "mistral": true}
+
+// loadCustomAIProviders reads the providers named in AI_CUSTOM_PROVIDERS
+// (comma-separated). For each name it reads <NAME>_KIND, <NAME>_BASE_URL,
+// <NAME>_API_KEY, <NAME>_LABEL and the <NAME>_MODEL* tiers.
+func loadCustomAIProviders() ([]CustomAIProvider, error) {
+	var providers []CustomAIProvider
+	seen := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("AI_CUSTOM_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !customProviderName.MatchString(name) {
+			return nil, fmt.Errorf("AI_CUSTOM_PROVIDERS: invalid name %q (use lowercase letters, digits and _)", name)
+		}
+		if builtinAIProviders[name] {
+			return nil, fmt.Errorf("AI_CUSTOM_PROVIDERS: %q is a built-in provider", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("AI_CUSTOM_PROVIDERS: %q is listed twice", name)
+		}
+		seen[name] = true
+
+		prefix := strings.ToUpper(name) + "_"
+		p := CustomAIProvider{
+			Name:          name,
+			Kind:          os.Getenv(prefix + "KIND"),
+			Label:         envOrDefault(prefix+"LABEL", name),
+			BaseURL:       os.Getenv(prefix + "BASE_URL"),
+			APIKey:        os.Getenv(prefix + "API_KEY"),
+			Model:         os.Getenv(prefix + "MODEL"),
+			ModelLight:    os.Getenv(prefix + "MODEL_LIGHT"),
+			ModelContent:  os.Getenv(prefix + "MODEL_CONTENT"),
+			ModelTemplate: os.Getenv(prefix + "MODEL_TEMPLATE"),
+		}
+		switch p.Kind {
+		case "openai_compatible":
+			if p.BaseURL == "" {
+				return nil, fmt.Errorf("%sBASE_URL must be set for an openai_compatible provider", prefix)
+			}
+		case "ollama":
+			if p.BaseURL == "" {
+				p.BaseURL = "http://localhost:11434"
+			}
+		default:
+			return nil, fmt.Errorf("%sKIND must be openai_compatible or ollama, got %q", prefix, p.Kind)
+		}
+		if p.Model == "" {
+			return nil, fmt.Errorf("%sMODEL must be set", prefix)
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
@@ -9,6 +9,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds all application configuration values loaded from the environment.
@@ -30,9 +32,72 @@ type Config struct {
 	ValkeyPort     string
 	ValkeyPassword string
 
+	// Cache warmer — re-renders public pages into the L2 cache after bulk
+	// invalidations and, if CacheWarmInterval > 0, on a fixed schedule.
+	CacheWarmConcurrency int
+	CacheWarmInterval    time.Duration
+
+	// How long cache_invalidation_log rows are kept before being pruned.
+	CacheLogRetention time.Duration
+
+	// CDN purging — when CDNPurgeURL is set, every page cache invalidation
+	// is also sent to this webhook. SiteURL makes purged paths absolute.
+	CDNPurgeURL   string
+	CDNPurgeToken string
+	SiteURL       string
+
+	// AIJobWorkers is how many background AI jobs (revision metadata,
+	// image generation) each replica runs at once.
+	AIJobWorkers int
+
+	// AITemplateRepairs is how many times the template builder sends an
+	// invalid template back to the model with its error before showing it
+	// to the user. 0 turns the repair loop off.
+	AITemplateRepairs int
+
 	// AI providers — keys for all supported providers; AIProvider selects
 	// the default on startup. Switchable at runtime from admin Settings.
-	AIProvider string // Default active: "openai", "gemini", "claude", "mistral"
+	AIProvider string // Default active: "openai", "gemini", "claude", "mistral" or "fake"
+
+	// The fake provider (AI_PROVIDER=fake) answers without an API key,
+	// for development, e2e tests and demos. AIFakeLatency delays every
+	// reply, AIFakeFailEvery makes every Nth call fail (0 never) and
+	// AIFakeFlagWord is the word its moderator flags (empty uses the
+	// built-in one).
+	AIFakeLatency   time.Duration
+	AIFakeFailEvery int
+	AIFakeFlagWord  string
+
+	// AIPrices overrides the built-in per-model price table used for cost
+	// estimates, as "model=input/output,..." in USD per million tokens.
+	AIPrices string
+
+	// AI audit log. AIAuditPrompts is "hash" to keep only a SHA-256 of
+	// each prompt or "full" to keep its text. AIAuditRedact lists the
+	// built-in redaction rules applied first ("emails,keys", or "none");
+	// AIAuditRedactPattern adds a regular expression of your own. Rows
+	// older than AIAuditRetention are pruned.
+	AIAuditPrompts       string
+	AIAuditRedact        string
+	AIAuditRedactPattern string
+	AIAuditRetention     time.Duration
+
+	// AIFallback* list the providers tried, in order, when the active
+	// provider fails a task, e.g. "openai,gemini". AI_FALLBACK sets the
+	// order for every task; AI_FALLBACK_<TASK> overrides it per task.
+	AIFallbackContent  string
+	AIFallbackTemplate string
+	AIFallbackLight    string
+
+	// AIEmbeddingProvider picks the provider that embeds content for
+	// search and related posts: "openai", "gemini", "mistral" or "local".
+	// Empty uses the first of OpenAI, Gemini and Mistral with a key,
+	// otherwise the local embedder.
+	AIEmbeddingProvider string
+
+	// AICustomProviders are additional OpenAI-compatible or Ollama
+	// servers listed in AI_CUSTOM_PROVIDERS (see ai_providers.go).
+	AICustomProviders []CustomAIProvider
 
 	// Per-provider credentials and model tiers.
 	// MODEL is the default (pro) model. MODEL_LIGHT is for cheap tasks
@@ -44,6 +109,7 @@ type Config struct {
 	OpenAIModelContent string
 	OpenAIModelTemplate string
 	OpenAIModelImage   string
+	OpenAIModelEmbedding string
 	OpenAIBaseURL      string
 
 	GeminiKey          string
@@ -52,6 +118,7 @@ type Config struct {
 	GeminiModelContent string
 	GeminiModelTemplate string
 	GeminiModelImage   string
+	GeminiModelEmbedding string
 	GeminiBaseURL      string
 
 	ClaudeKey          string
@@ -66,6 +133,7 @@ type Config struct {
 	MistralModelLight   string
 	MistralModelContent string
 	MistralModelTemplate string
+	MistralModelEmbedding string
 	MistralBaseURL      string
 
 	// S3-compatible object storage (Hetzner CEPH)
@@ -97,7 +165,35 @@ func Load() (*Config, error) {
 		ValkeyPort:     envOrDefault("VALKEY_PORT", "6379"),
 		ValkeyPassword: os.Getenv("VALKEY_PASSWORD"),
 
+		CacheWarmConcurrency: envIntOrDefault("CACHE_WARM_CONCURRENCY", 4),
+		CacheWarmInterval:    envDurationOrDefault("CACHE_WARM_INTERVAL", 0),
+		CacheLogRetention:    envDurationOrDefault("CACHE_LOG_RETENTION", 30*24*time.Hour),
+
+		CDNPurgeURL:   os.Getenv("CDN_PURGE_URL"),
+		CDNPurgeToken: os.Getenv("CDN_PURGE_TOKEN"),
+		SiteURL:       os.Getenv("SITE_URL"),
+
+		AIJobWorkers: envIntOrDefault("AI_JOB_WORKERS", 2),
+
+		AITemplateRepairs: envCountOrDefault("AI_TEMPLATE_REPAIRS", 2),
+
 		AIProvider: envOrDefault("AI_PROVIDER", "gemini"),
+		AIPrices:   os.Getenv("AI_PRICES"),
+
+		AIFakeLatency:   envDurationOrDefault("AI_FAKE_LATENCY", 0),
+		AIFakeFailEvery: envIntOrDefault("AI_FAKE_FAIL_EVERY", 0),
+		AIFakeFlagWord:  os.Getenv("AI_FAKE_FLAG_WORD"),
+
+		AIAuditPrompts:       envOrDefault("AI_AUDIT_PROMPTS", "hash"),
+		AIAuditRedact:        envOrDefault("AI_AUDIT_REDACT", "emails,keys"),
+		AIAuditRedactPattern: os.Getenv("AI_AUDIT_REDACT_PATTERN"),
+		AIAuditRetention:     envDurationOrDefault("AI_AUDIT_RETENTION", 90*24*time.Hour),
+
+		AIFallbackContent:  envOrDefault("AI_FALLBACK_CONTENT", os.Getenv("AI_FALLBACK")),
+		AIFallbackTemplate: envOrDefault("AI_FALLBACK_TEMPLATE", os.Getenv("AI_FALLBACK")),
+		AIFallbackLight:    envOrDefault("AI_FALLBACK_LIGHT", os.Getenv("AI_FALLBACK")),
+
+		AIEmbeddingProvider: os.Getenv("AI_EMBEDDING_PROVIDER"),
 
 		OpenAIKey:          os.Getenv("OPENAI_API_KEY"),
 		OpenAIModel:        envOrDefault("OPENAI_MODEL", "gpt-4o"),
@@ -105,6 +201,7 @@ func Load() (*Config, error) {
 		OpenAIModelContent: os.Getenv("OPENAI_MODEL_CONTENT"),
 		OpenAIModelTemplate: os.Getenv("OPENAI_MODEL_TEMPLATE"),
 		OpenAIModelImage:   envOrDefault("OPENAI_MODEL_IMAGE", "dall-e-3"),
+		OpenAIModelEmbedding: os.Getenv("OPENAI_MODEL_EMBEDDING"),
 		OpenAIBaseURL:      envOrDefault("OPENAI_BASE_URL", "https://api.openai.com/v1"),
 
 		GeminiKey:          os.Getenv("GEMINI_API_KEY"),
@@ -113,6 +210,7 @@ func Load() (*Config, error) {
 		GeminiModelContent: os.Getenv("GEMINI_MODEL_CONTENT"),
 		GeminiModelTemplate: os.Getenv("GEMINI_MODEL_TEMPLATE"),
 		GeminiModelImage:   os.Getenv("GEMINI_MODEL_IMAGE"),
+		GeminiModelEmbedding: os.Getenv("GEMINI_MODEL_EMBEDDING"),
 		GeminiBaseURL:      envOrDefault("GEMINI_BASE_URL", "https://generativelanguage.googleapis.com"),
 
 		ClaudeKey:          os.Getenv("CLAUDE_API_KEY"),
@@ -127,6 +225,7 @@ func Load() (*Config, error) {
 		MistralModelLight:   os.Getenv("MISTRAL_MODEL_LIGHT"),
 		MistralModelContent: os.Getenv("MISTRAL_MODEL_CONTENT"),
 		MistralModelTemplate: os.Getenv("MISTRAL_MODEL_TEMPLATE"),
+		MistralModelEmbedding: os.Getenv("MISTRAL_MODEL_EMBEDDING"),
 		MistralBaseURL:      envOrDefault("MISTRAL_BASE_URL", "https://api.mistral.ai/v1"),
 
 		S3Endpoint:      os.Getenv("S3_ENDPOINT"),
@@ -138,10 +237,19 @@ func Load() (*Config, error) {
 		S3PublicURL:     os.Getenv("S3_PUBLIC_URL"),
 	}
 
+	custom, err := loadCustomAIProviders()
+	if err != nil {
+		return nil, err
+	}
+	cfg.AICustomProviders = custom
+
 	if cfg.Env == "production" {
 		if cfg.DBPassword == "changeme" {
 			return nil, fmt.Errorf("POSTGRES_PASSWORD must be set in production")
 		}
+		if cfg.AIProvider == "fake" {
+			return nil, fmt.Errorf("AI_PROVIDER=fake is for development and tests, not production")
+		}
 	}
 
 	return cfg, nil
@@ -172,3 +280,32 @@ func envOrDefault(key, fallback string) string {
 	}
 	return fallback
 }
+
+// envIntOrDefault reads a positive integer environment variable, returning
+// the fallback if unset, empty, or not a positive integer.
+func envIntOrDefault(key string, fallback int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// envCountOrDefault is like envIntOrDefault but also accepts 0.
+func envCountOrDefault(key string, fallback int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+// envDurationOrDefault reads a Go duration ("30m", "1h") from the
+// environment, returning the fallback if unset or unparsable.
+func envDurationOrDefault(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return d
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestLoad_Defaults verifies that Load returns sensible development defaults
@@ -406,3 +407,193 @@ func TestEnvOrDefault(t *testing.T) {
 		}
 	})
 }
+
+// TestCacheWarmSettings verifies parsing of the numeric and duration
+// cache warmer variables, including fallback on invalid input.
+func TestCacheWarmSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("CACHE_WARM_CONCURRENCY", "")
+		t.Setenv("CACHE_WARM_INTERVAL", "")
+		t.Setenv("CACHE_LOG_RETENTION", "")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if cfg.CacheWarmConcurrency != 4 {
+			t.Errorf("CacheWarmConcurrency = %d, want 4", cfg.CacheWarmConcurrency)
+		}
+		if cfg.CacheWarmInterval != 0 {
+			t.Errorf("CacheWarmInterval = %v, want 0", cfg.CacheWarmInterval)
+		}
+		if cfg.CacheLogRetention != 30*24*time.Hour {
+			t.Errorf("CacheLogRetention = %v, want 720h", cfg.CacheLogRetention)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv("CACHE_WARM_CONCURRENCY", "8")
+		t.Setenv("CACHE_WARM_INTERVAL", "30m")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if cfg.CacheWarmConcurrency != 8 {
+			t.Errorf("CacheWarmConcurrency = %d, want 8", cfg.CacheWarmConcurrency)
+		}
+		if cfg.CacheWarmInterval != 30*time.Minute {
+			t.Errorf("CacheWarmInterval = %v, want 30m", cfg.CacheWarmInterval)
+		}
+	})
+
+	t.Run("invalid values fall back", func(t *testing.T) {
+		t.Setenv("CACHE_WARM_CONCURRENCY", "-2")
+		t.Setenv("CACHE_WARM_INTERVAL", "soon")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if cfg.CacheWarmConcurrency != 4 {
+			t.Errorf("CacheWarmConcurrency = %d, want 4", cfg.CacheWarmConcurrency)
+		}
+		if cfg.CacheWarmInterval != 0 {
+			t.Errorf("CacheWarmInterval = %v, want 0", cfg.CacheWarmInterval)
+		}
+	})
+}
+
+// TestAIFallbackSettings verifies that AI_FALLBACK applies to every task
+// unless a per-task variable overrides it.
+func TestAIFallbackSettings(t *testing.T) {
+	t.Setenv("AI_FALLBACK", "openai,gemini")
+	t.Setenv("AI_FALLBACK_CONTENT", "")
+	t.Setenv("AI_FALLBACK_TEMPLATE", "")
+	t.Setenv("AI_FALLBACK_LIGHT", "mistral")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if cfg.AIFallbackContent != "openai,gemini" || cfg.AIFallbackTemplate != "openai,gemini" {
+		t.Errorf("content/template fallbacks = %q/%q, want AI_FALLBACK", cfg.AIFallbackContent, cfg.AIFallbackTemplate)
+	}
+	if cfg.AIFallbackLight != "mistral" {
+		t.Errorf("AIFallbackLight = %q, want %q", cfg.AIFallbackLight, "mistral")
+	}
+}
+
+// TestCustomAIProviders verifies loading of AI_CUSTOM_PROVIDERS and the
+// per-provider variables, including validation errors.
+func TestCustomAIProviders(t *testing.T) {
+	t.Run("loads each provider", func(t *testing.T) {
+		t.Setenv("AI_CUSTOM_PROVIDERS", "local, vllm")
+		t.Setenv("LOCAL_KIND", "ollama")
+		t.Setenv("LOCAL_MODEL", "llama3.1")
+		t.Setenv("LOCAL_MODEL_LIGHT", "llama3.2:3b")
+		t.Setenv("VLLM_KIND", "openai_compatible")
+		t.Setenv("VLLM_BASE_URL", "http://vllm:8000/v1")
+		t.Setenv("VLLM_API_KEY", "secret")
+		t.Setenv("VLLM_MODEL", "qwen2.5")
+		t.Setenv("VLLM_LABEL", "Staging vLLM")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if len(cfg.AICustomProviders) != 2 {
+			t.Fatalf("got %d providers, want 2", len(cfg.AICustomProviders))
+		}
+		local, vllm := cfg.AICustomProviders[0], cfg.AICustomProviders[1]
+		if local.Name != "local" || local.BaseURL != "http://localhost:11434" || local.Label != "local" || local.ModelLight != "llama3.2:3b" {
+			t.Errorf("local: got %+v", local)
+		}
+		if vllm.Kind != "openai_compatible" || vllm.APIKey != "secret" || vllm.Label != "Staging vLLM" {
+			t.Errorf("vllm: got %+v", vllm)
+		}
+	})
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"unknown kind", map[string]string{"AI_CUSTOM_PROVIDERS": "local", "LOCAL_KIND": "llamafile", "LOCAL_MODEL": "m"}, "LOCAL_KIND"},
+		{"missing base URL", map[string]string{"AI_CUSTOM_PROVIDERS": "vllm", "VLLM_KIND": "openai_compatible", "VLLM_MODEL": "m"}, "VLLM_BASE_URL"},
+		{"missing model", map[string]string{"AI_CUSTOM_PROVIDERS": "local", "LOCAL_KIND": "ollama"}, "LOCAL_MODEL"},
+		{"built-in name", map[string]string{"AI_CUSTOM_PROVIDERS": "openai"}, "built-in"},
+		{"invalid name", map[string]string{"AI_CUSTOM_PROVIDERS": "my-llm"}, "invalid name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestAIAuditSettings verifies the audit log defaults and overrides.
+func TestAIAuditSettings(t *testing.T) {
+	t.Setenv("AI_AUDIT_PROMPTS", "")
+	t.Setenv("AI_AUDIT_REDACT", "")
+	t.Setenv("AI_AUDIT_RETENTION", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if cfg.AIAuditPrompts != "hash" || cfg.AIAuditRedact != "emails,keys" || cfg.AIAuditRetention != 90*24*time.Hour {
+		t.Errorf("defaults: prompts %q, redact %q, retention %v", cfg.AIAuditPrompts, cfg.AIAuditRedact, cfg.AIAuditRetention)
+	}
+
+	t.Setenv("AI_AUDIT_PROMPTS", "full")
+	t.Setenv("AI_AUDIT_REDACT", "none")
+	t.Setenv("AI_AUDIT_RETENTION", "8760h")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if cfg.AIAuditPrompts != "full" || cfg.AIAuditRedact != "none" || cfg.AIAuditRetention != 365*24*time.Hour {
+		t.Errorf("overrides: prompts %q, redact %q, retention %v", cfg.AIAuditPrompts, cfg.AIAuditRedact, cfg.AIAuditRetention)
+	}
+}
+
+// TestAIFakeSettings verifies the fake provider settings and that it is
+// refused in production.
+func TestAIFakeSettings(t *testing.T) {
+	t.Setenv("AI_PROVIDER", "fake")
+	t.Setenv("AI_FAKE_LATENCY", "250ms")
+	t.Setenv("AI_FAKE_FAIL_EVERY", "3")
+	t.Setenv("AI_FAKE_FLAG_WORD", "banana")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if cfg.AIFakeLatency != 250*time.Millisecond || cfg.AIFakeFailEvery != 3 || cfg.AIFakeFlagWord != "banana" {
+		t.Errorf("fake settings: latency %v, fail every %d, flag word %q", cfg.AIFakeLatency, cfg.AIFakeFailEvery, cfg.AIFakeFlagWord)
+	}
+
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("POSTGRES_PASSWORD", "a-real-password")
+	if _, err := Load(); err == nil {
+		t.Error("Load() should refuse AI_PROVIDER=fake in production")
+	}
+}
+
+// TestAITemplateRepairs verifies the repair attempts setting, which can
+// be turned off with 0.
+func TestAITemplateRepairs(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  int
+	}{{"", 2}, {"0", 0}, {"4", 4}, {"-1", 2}, {"many", 2}} {
+		t.Setenv("AI_TEMPLATE_REPAIRS", tt.value)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() returned unexpected error: %v", err)
+		}
+		if cfg.AITemplateRepairs != tt.want {
+			t.Errorf("AI_TEMPLATE_REPAIRS=%q: got %d, want %d", tt.value, cfg.AITemplateRepairs, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+-- Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+-- Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+-- All rights reserved. See LICENSE for details.
+
+-- +goose Up
+-- Record who triggered each invalidation and how many cache keys it removed,
+-- and allow manual purges from the admin cache page (including the homepage,
+-- which has no backing entity).
+ALTER TABLE cache_invalidation_log
+    ADD COLUMN user_id       UUID    REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN keys_affected INTEGER NOT NULL DEFAULT 0,
+    DROP CONSTRAINT cache_log_entity_type_check,
+    ADD  CONSTRAINT cache_log_entity_type_check CHECK (entity_type IN ('content', 'template', 'homepage')),
+    DROP CONSTRAINT cache_log_action_check,
+    ADD  CONSTRAINT cache_log_action_check CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge'));
+
+-- +goose Down
+DELETE FROM cache_invalidation_log WHERE entity_type = 'homepage' OR action = 'purge';
+ALTER TABLE cache_invalidation_log
+    DROP CONSTRAINT cache_log_action_check,
+    ADD  CONSTRAINT cache_log_action_check CHECK (action IN ('create', 'update', 'delete', 'restore')),
+    DROP CONSTRAINT cache_log_entity_type_check,
+    ADD  CONSTRAINT cache_log_entity_type_check CHECK (entity_type IN ('content', 'template')),
+    DROP COLUMN keys_affected,
+    DROP COLUMN user_id;
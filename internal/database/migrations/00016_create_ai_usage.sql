@@ -0,0 +1,22 @@
+-- +goose Up
+-- One row per AI completion for usage and cost reporting. cost_usd is the
+-- estimate at the time of the call; NULL when the model has no price.
+CREATE TABLE ai_usage (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID REFERENCES users(id) ON DELETE SET NULL,
+    task          TEXT NOT NULL,
+    provider      TEXT NOT NULL,
+    model         TEXT NOT NULL,
+    input_tokens  INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms    INTEGER NOT NULL DEFAULT 0,
+    cost_usd      NUMERIC(12, 6),
+    success       BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_usage_created_at ON ai_usage(created_at);
+CREATE INDEX idx_ai_usage_user_id ON ai_usage(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_usage;
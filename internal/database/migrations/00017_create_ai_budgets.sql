@@ -0,0 +1,18 @@
+-- +goose Up
+-- AI token and cost budgets. A row applies either to every user with a
+-- role or to a single user (overriding their role's row). NULL limits are
+-- unlimited. Days and months are counted in UTC.
+CREATE TABLE ai_budgets (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    role             TEXT UNIQUE CHECK (role IN ('admin', 'editor', 'author')),
+    user_id          UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    daily_tokens     BIGINT CHECK (daily_tokens >= 0),
+    daily_cost_usd   NUMERIC(12, 4) CHECK (daily_cost_usd >= 0),
+    monthly_tokens   BIGINT CHECK (monthly_tokens >= 0),
+    monthly_cost_usd NUMERIC(12, 4) CHECK (monthly_cost_usd >= 0),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((role IS NULL) <> (user_id IS NULL))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_budgets;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- AI conversations: template-builder and content-assistant threads, one
+-- owner each. target is the template type (template threads) or content
+-- type (content threads). current_html is the latest template the thread
+-- produced, restored when it is resumed.
+CREATE TABLE ai_conversations (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind         TEXT NOT NULL CHECK (kind IN ('template', 'content')),
+    title        TEXT NOT NULL DEFAULT '',
+    target       TEXT NOT NULL DEFAULT '',
+    current_html TEXT NOT NULL DEFAULT '',
+    forked_from  UUID REFERENCES ai_conversations(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_conversations_user ON ai_conversations(user_id, kind, updated_at DESC);
+
+-- Messages are numbered from 1 within their conversation. System prompts
+-- are rebuilt on every call and never stored.
+CREATE TABLE ai_conversation_messages (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID NOT NULL REFERENCES ai_conversations(id) ON DELETE CASCADE,
+    position        INT NOT NULL,
+    role            TEXT NOT NULL CHECK (role IN ('user', 'assistant')),
+    content         TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (conversation_id, position)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_conversation_messages;
+DROP TABLE IF EXISTS ai_conversations;
@@ -0,0 +1,31 @@
+-- +goose Up
+-- Background AI jobs. Workers claim pending rows with FOR UPDATE SKIP
+-- LOCKED, so any number of replicas can share the queue. locked_at is
+-- refreshed while a job runs; a running job whose lock goes stale (the
+-- worker died) is put back to pending. payload and result hold JSON.
+CREATE TABLE ai_jobs (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind           TEXT NOT NULL,
+    payload        TEXT NOT NULL DEFAULT '{}',
+    status         TEXT NOT NULL DEFAULT 'pending'
+                   CHECK (status IN ('pending', 'running', 'succeeded', 'failed', 'cancelled')),
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    max_attempts   INTEGER NOT NULL DEFAULT 3,
+    run_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_at      TIMESTAMPTZ,
+    progress_done  INTEGER NOT NULL DEFAULT 0,
+    progress_total INTEGER NOT NULL DEFAULT 0,
+    result         TEXT NOT NULL DEFAULT '',
+    error          TEXT NOT NULL DEFAULT '',
+    created_by     UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_ai_jobs_pending ON ai_jobs(run_at) WHERE status = 'pending';
+CREATE INDEX idx_ai_jobs_running ON ai_jobs(locked_at) WHERE status = 'running';
+CREATE INDEX idx_ai_jobs_created_at ON ai_jobs(created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_jobs;
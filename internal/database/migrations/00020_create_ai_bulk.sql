@@ -0,0 +1,36 @@
+-- +goose Up
+-- Bulk AI runs over a filtered set of content. Each item is processed by
+-- one background job, which stores a proposed change (JSON) for review;
+-- nothing is written to content until an editor accepts the item. An
+-- accepted item points at the revision snapshot taken before the change.
+CREATE TABLE ai_bulk_runs (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    action      TEXT NOT NULL,
+    options     TEXT NOT NULL DEFAULT '{}',
+    filter      TEXT NOT NULL DEFAULT '',
+    created_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_bulk_runs_created_at ON ai_bulk_runs(created_at DESC);
+
+CREATE TABLE ai_bulk_items (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id       UUID NOT NULL REFERENCES ai_bulk_runs(id) ON DELETE CASCADE,
+    content_id   UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    job_id       UUID REFERENCES ai_jobs(id) ON DELETE SET NULL,
+    status       TEXT NOT NULL DEFAULT 'queued'
+                 CHECK (status IN ('queued', 'proposed', 'accepted', 'rejected', 'failed')),
+    proposal     TEXT NOT NULL DEFAULT '',
+    error        TEXT NOT NULL DEFAULT '',
+    revision_id  UUID REFERENCES content_revisions(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (run_id, content_id)
+);
+
+CREATE INDEX idx_ai_bulk_items_run_id ON ai_bulk_items(run_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_bulk_items;
+DROP TABLE IF EXISTS ai_bulk_runs;
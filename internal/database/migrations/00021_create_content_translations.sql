@@ -0,0 +1,17 @@
+-- +goose Up
+-- Links a content item to its translations. A translation is an ordinary
+-- content row (own slug, status and revisions) served at /{locale}/{slug};
+-- content without a link row is in the site's default language. Each
+-- source has at most one translation per locale.
+CREATE TABLE content_translations (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_id       UUID NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    translation_id  UUID NOT NULL UNIQUE REFERENCES content(id) ON DELETE CASCADE,
+    locale          VARCHAR(10) NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (source_id, locale),
+    CHECK (source_id <> translation_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS content_translations;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Captions are displayed with an image; alt_text remains the text for
+-- screen readers and image-less clients.
+ALTER TABLE media ADD COLUMN caption TEXT;
+
+-- AI-suggested alt text and captions. One row per media item, written by
+-- a background job from the image itself; nothing is copied to the media
+-- row until an editor accepts (and possibly edits) the suggestion.
+CREATE TABLE media_descriptions (
+    media_id     UUID PRIMARY KEY REFERENCES media(id) ON DELETE CASCADE,
+    job_id       UUID REFERENCES ai_jobs(id) ON DELETE SET NULL,
+    status       TEXT NOT NULL DEFAULT 'queued'
+                 CHECK (status IN ('queued', 'proposed', 'accepted', 'rejected', 'failed')),
+    alt_text     TEXT NOT NULL DEFAULT '',
+    caption      TEXT NOT NULL DEFAULT '',
+    error        TEXT NOT NULL DEFAULT '',
+    reviewed_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_descriptions_status ON media_descriptions(status);
+
+-- +goose Down
+DROP TABLE IF EXISTS media_descriptions;
+ALTER TABLE media DROP COLUMN IF EXISTS caption;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Enable pgvector when the server ships it, so similarity is computed in
+-- the database. Without it (or without the privilege to create it) the
+-- application scans the vectors itself; the table works either way.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+        CREATE EXTENSION IF NOT EXISTS vector;
+    END IF;
+EXCEPTION WHEN insufficient_privilege THEN
+    RAISE NOTICE 'pgvector is available but could not be enabled: %', SQLERRM;
+END
+$$;
+-- +goose StatementEnd
+
+-- One embedding per content item. model is "provider/model": vectors of
+-- different models can't be compared, so queries only match the current
+-- one. source_hash is the SHA-256 of the embedded text, so saves that
+-- don't change it skip the provider call. REAL[] casts to pgvector's
+-- vector type, so the column doesn't depend on the extension.
+CREATE TABLE content_embeddings (
+    content_id   UUID PRIMARY KEY REFERENCES content(id) ON DELETE CASCADE,
+    model        TEXT NOT NULL,
+    dims         INT NOT NULL,
+    embedding    REAL[] NOT NULL,
+    source_hash  TEXT NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_content_embeddings_model ON content_embeddings(model);
+
+-- +goose Down
+DROP TABLE IF EXISTS content_embeddings;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Plain text of a content body for full-text search: HTML tags, Markdown
+-- link targets and Markdown syntax become spaces, and the characters
+-- that could form markup are dropped, so ts_headline snippets of it are
+-- safe to show as HTML once the match markers are added.
+-- +goose StatementBegin
+CREATE FUNCTION content_plain_text(body TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT translate(
+        regexp_replace(
+            regexp_replace(
+                regexp_replace(body, '<[^>]*>', ' ', 'g'),
+                '\]\([^)]*\)', ' ', 'g'),
+            '[#*_`~|\[\]!>]+', ' ', 'g'),
+        '<&', '  ')
+$$;
+-- +goose StatementEnd
+
+-- Title outranks excerpt, which outranks body. The English configuration
+-- stems words, so "caching" also finds "cache".
+ALTER TABLE content ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(excerpt, '')), 'B') ||
+    setweight(to_tsvector('english', content_plain_text(coalesce(body, ''))), 'C')
+) STORED;
+
+CREATE INDEX idx_content_search ON content USING GIN (search_vector);
+
+-- A search template renders the public /search page.
+ALTER TABLE templates
+    DROP CONSTRAINT templates_type_check,
+    ADD  CONSTRAINT templates_type_check CHECK (type IN ('header', 'footer', 'page', 'article_loop', 'search'));
+
+-- +goose Down
+DELETE FROM templates WHERE type = 'search';
+ALTER TABLE templates
+    DROP CONSTRAINT templates_type_check,
+    ADD  CONSTRAINT templates_type_check CHECK (type IN ('header', 'footer', 'page', 'article_loop'));
+
+DROP INDEX IF EXISTS idx_content_search;
+ALTER TABLE content DROP COLUMN search_vector;
+DROP FUNCTION IF EXISTS content_plain_text(TEXT);
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Admin-editable system prompts, one row per saved version of a task's
+-- prompt. The highest version of a task is the one in use; rolling back
+-- saves an older body again as a new version, so history is never lost.
+-- The built-in defaults are inserted as version 1 at startup.
+CREATE TABLE prompts (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task        TEXT NOT NULL,
+    version     INT NOT NULL CHECK (version > 0),
+    body        TEXT NOT NULL,
+    note        TEXT NOT NULL DEFAULT '',
+    created_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (task, version)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS prompts;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- How an AI-generated image was made: its prompt, provider and options,
+-- as JSON, so the media library can show the prompt and run it again.
+-- NULL for uploaded files.
+ALTER TABLE media ADD COLUMN generation TEXT;
+
+-- +goose Down
+ALTER TABLE media DROP COLUMN generation;
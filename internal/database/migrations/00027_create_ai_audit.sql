@@ -0,0 +1,29 @@
+-- +goose Up
+-- One row per admin request or background job that used AI: who asked
+-- what, which provider answered, the moderation verdict and what became
+-- of the output. prompt is NULL unless AI_AUDIT_PROMPTS=full; both it and
+-- prompt_hash are taken after redaction. target names what was saved to
+-- the site, e.g. "template/<id>". Rows older than AI_AUDIT_RETENTION are
+-- pruned.
+CREATE TABLE ai_audit (
+    id                    UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id               UUID REFERENCES users(id) ON DELETE SET NULL,
+    endpoint              TEXT NOT NULL,
+    provider              TEXT NOT NULL DEFAULT '',
+    model                 TEXT NOT NULL DEFAULT '',
+    calls                 INTEGER NOT NULL DEFAULT 0,
+    prompt_hash           TEXT NOT NULL DEFAULT '',
+    prompt                TEXT,
+    response_chars        INTEGER NOT NULL DEFAULT 0,
+    moderation            TEXT NOT NULL DEFAULT '',
+    moderation_categories TEXT NOT NULL DEFAULT '',
+    outcome               TEXT NOT NULL,
+    target                TEXT NOT NULL DEFAULT '',
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_audit_created_at ON ai_audit(created_at);
+CREATE INDEX idx_ai_audit_user_id ON ai_audit(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS ai_audit;
@@ -61,7 +61,7 @@ func seedAdminUser(db *sql.DB) error {
 }
 
 // seedTemplates creates a minimal set of active templates (header, footer,
-// page, article_loop) so the public site works immediately after setup.
+// page, article_loop, search) so the public site works immediately after setup.
 func seedTemplates(db *sql.DB) error {
 	var count int
 	if err := db.QueryRow("SELECT COUNT(*) FROM templates").Scan(&count); err != nil {
@@ -145,6 +145,45 @@ func seedTemplates(db *sql.DB) error {
   </main>
   {{ .Footer }}
 </body>
+</html>`,
+		},
+		{
+			name:     "Default Search",
+			tmplType: "search",
+			html: `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>{{ .Title }} — {{ .SiteName }}</title>
+  <script src="https://cdn.tailwindcss.com"></script>
+</head>
+<body class="bg-white text-gray-900 min-h-screen flex flex-col">
+  {{ .Header }}
+  <main class="flex-1 max-w-3xl mx-auto px-4 py-8 w-full">
+    <h1 class="text-3xl font-bold mb-6">{{ .Title }}</h1>
+    <form action="/search" method="get" class="mb-8">
+      <input type="search" name="q" value="{{ .Query }}" placeholder="Search…"
+             class="w-full rounded-md border border-gray-300 px-4 py-2 focus:border-indigo-500 focus:outline-none">
+    </form>
+    {{ if .Query }}<p class="mb-6 text-sm text-gray-500">{{ .Total }} result{{ if ne .Total 1 }}s{{ end }} for “{{ .Query }}”</p>{{ end }}
+    {{ range .Results }}
+    <article class="mb-6">
+      <h2 class="text-lg font-semibold">
+        <a href="/{{ .Slug }}" class="text-indigo-600 hover:text-indigo-800">{{ .Title }}</a>
+      </h2>
+      {{ if .Snippet }}<p class="mt-1 text-gray-600 [&_mark]:bg-yellow-100">{{ .Snippet }}</p>{{ end }}
+    </article>
+    {{ end }}
+    {{ if or .PrevURL .NextURL }}
+    <nav class="mt-8 flex justify-between text-sm">
+      {{ if .PrevURL }}<a href="{{ .PrevURL }}" class="text-indigo-600 hover:text-indigo-800">← Previous</a>{{ else }}<span></span>{{ end }}
+      {{ if .NextURL }}<a href="{{ .NextURL }}" class="text-indigo-600 hover:text-indigo-800">Next →</a>{{ end }}
+    </nav>
+    {{ end }}
+  </main>
+  {{ .Footer }}
+</body>
 </html>`,
 		},
 	}
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestInjectAlternates verifies hreflang links are added before </head>
+// only when a page has more than one language version.
+func TestInjectAlternates(t *testing.T) {
+	page := []byte(`<html><head><title>x</title></HEAD><body></body></html>`)
+	versions := []Translation{
+		{Locale: "en", URL: "https://example.com/about", Default: true},
+		{Locale: "de", URL: "https://example.com/de/ueber-uns", Current: true},
+	}
+
+	got := string(injectAlternates(page, versions))
+	want := `<link rel="alternate" hreflang="en" href="https://example.com/about">` +
+		`<link rel="alternate" hreflang="x-default" href="https://example.com/about">` +
+		`<link rel="alternate" hreflang="de" href="https://example.com/de/ueber-uns"></HEAD>`
+	if !strings.Contains(got, want) {
+		t.Errorf("alternates not injected before </head>:\n%s", got)
+	}
+
+	if got := injectAlternates(page, versions[:1]); string(got) != string(page) {
+		t.Errorf("single version changed the page: %s", got)
+	}
+	noHead := []byte(`<p>fragment</p>`)
+	if got := injectAlternates(noHead, versions); string(got) != string(noHead) {
+		t.Errorf("page without </head> changed: %s", got)
+	}
+}
@@ -9,6 +9,7 @@ package engine
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"fmt"
 	"html/template"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"yaaicms/internal/cache"
 	"yaaicms/internal/markdown"
 	"yaaicms/internal/models"
 	"yaaicms/internal/storage"
@@ -43,51 +45,106 @@ func init() {
 // Templates can use {{.FeaturedImageURL}} for the main URL (backward-compat)
 // and {{.FeaturedImageSrcset}} for responsive <img srcset="...">.
 type FeaturedImage struct {
-	URL    string // Public URL of the original image (or largest variant)
-	Srcset string // Pre-built srcset string: "url_sm.webp 640w, url_md.webp 1024w, ..."
-	Alt    string // Alt text for accessibility
+	URL     string // Public URL of the original image (or largest variant)
+	Srcset  string // Pre-built srcset string: "url_sm.webp 640w, url_md.webp 1024w, ..."
+	Alt     string // Alt text for accessibility
+	Caption string // Caption to show with the image; may be empty
 }
 
 // PageData holds all variables available to a page template when rendering
 // a public page. Template authors (or AI) can use these as {{.Title}}, etc.
 type PageData struct {
-	SiteName            string
-	Title               string
-	Body                template.HTML // Content body — raw HTML from editor
-	Excerpt             string
-	MetaDescription     string
-	MetaKeywords        string
-	FeaturedImageURL    string        // Public URL of the featured image (empty if none)
-	FeaturedImageSrcset string        // Responsive srcset for the featured image
-	FeaturedImageAlt    string        // Alt text for the featured image
-	Slug                string
-	PublishedAt         string
-	Header              template.HTML // Pre-rendered header fragment
-	Footer              template.HTML // Pre-rendered footer fragment
-	Year                int
+	SiteName             string
+	Title                string
+	Body                 template.HTML // Content body — raw HTML from editor
+	Excerpt              string
+	MetaDescription      string
+	MetaKeywords         string
+	FeaturedImageURL     string // Public URL of the featured image (empty if none)
+	FeaturedImageSrcset  string // Responsive srcset for the featured image
+	FeaturedImageAlt     string // Alt text for the featured image
+	FeaturedImageCaption string // Caption for the featured image (may be empty)
+	Slug                 string
+	PublishedAt          string
+	Header               template.HTML // Pre-rendered header fragment
+	Footer               template.HTML // Pre-rendered footer fragment
+	Year                 int
+	Lang                 string        // Page language for <html lang>, e.g. "en"
+	Translations         []Translation // All language versions, this one included; empty if untranslated
+	RelatedPosts         []PostItem    // Posts similar to this one, best first; empty for pages
+}
+
+// Translation is one language version of a page. Templates use
+// {{range .Translations}} to build a language switcher; the same list is
+// emitted as hreflang alternates in the page head.
+type Translation struct {
+	Locale  string // Language code, e.g. "de"
+	Name    string // Language name in that language, e.g. "Deutsch"
+	Title   string // Title of this version
+	URL     string // Absolute when the site URL is configured, otherwise a path
+	Current bool   // The version being rendered
+	Default bool   // The site's default language version (hreflang x-default)
+}
+
+// Localization is the language of a rendered page and its versions.
+type Localization struct {
+	Lang         string
+	Translations []Translation
+}
+
+// PageExtras holds the optional parts of a rendered page.
+type PageExtras struct {
+	Localization *Localization // Language and translations; nil if untranslated
+	RelatedPosts []PostItem    // Similar posts, see PageData.RelatedPosts
 }
 
 // PostItem represents a single post in a listing (used by article_loop template).
 type PostItem struct {
-	Title               string
-	Slug                string
-	Excerpt             string
-	FeaturedImageURL    string // Public URL of the featured image (empty if none)
-	FeaturedImageSrcset string // Responsive srcset for the featured image
-	FeaturedImageAlt    string // Alt text for the featured image
-	PublishedAt         string
+	Title                string
+	Slug                 string
+	Excerpt              string
+	FeaturedImageURL     string // Public URL of the featured image (empty if none)
+	FeaturedImageSrcset  string // Responsive srcset for the featured image
+	FeaturedImageAlt     string // Alt text for the featured image
+	FeaturedImageCaption string // Caption for the featured image (may be empty)
+	PublishedAt          string
 }
 
 // ListData holds variables available to the article_loop template.
 type ListData struct {
 	SiteName string
 	Title    string
+	Query    string // Search query when rendering search results, otherwise empty
 	Posts    []PostItem
 	Header   template.HTML
 	Footer   template.HTML
 	Year     int
 }
 
+// SearchResult is one hit on the search page: the listing fields of the
+// post or page plus a snippet of the matching text.
+type SearchResult struct {
+	PostItem
+	Type    string        // "post" or "page"
+	Snippet template.HTML // Matching text with matches in <mark>; may be empty
+}
+
+// SearchData holds variables available to the search template.
+type SearchData struct {
+	SiteName   string
+	Title      string
+	Query      string
+	Results    []SearchResult // This page of results, best first
+	Total      int            // Results on all pages
+	Page       int            // Current page, starting at 1
+	TotalPages int
+	PrevURL    string // Empty on the first page
+	NextURL    string // Empty on the last page
+	Header     template.HTML
+	Footer     template.HTML
+	Year       int
+}
+
 // Engine compiles and renders templates from the database. It maintains
 // an in-memory cache (L1) of compiled Go templates keyed by ID+version,
 // so repeated renders skip the expensive template.Parse step.
@@ -104,6 +161,11 @@ type Engine struct {
 	mediaStore    *store.MediaStore
 	variantStore  *store.VariantStore
 	storageClient *storage.Client
+
+	// Optional cross-replica invalidation. instanceID tags events this
+	// engine publishes so it can ignore its own echoes.
+	bus        cache.InvalidationBus
+	instanceID string
 }
 
 // New creates a new template rendering engine with an empty L1 cache.
@@ -111,6 +173,7 @@ func New(templateStore *store.TemplateStore) *Engine {
 	return &Engine{
 		templateStore: templateStore,
 		cache:         newTemplateCache(),
+		instanceID:    uuid.NewString(),
 	}
 }
 
@@ -122,22 +185,83 @@ func (e *Engine) SetMediaDeps(mediaStore *store.MediaStore, variantStore *store.
 	e.storageClient = storageClient
 }
 
+// SetInvalidationBus configures the bus used to announce L1 invalidations
+// to other replicas. Call ListenForInvalidations to also receive them.
+func (e *Engine) SetInvalidationBus(bus cache.InvalidationBus) {
+	e.bus = bus
+}
+
+// ListenForInvalidations subscribes to the invalidation bus and applies
+// events published by other replicas until ctx is cancelled. After any
+// gap in delivery the whole L1 cache is flushed, since events may have
+// been missed. No-op when no bus is configured.
+func (e *Engine) ListenForInvalidations(ctx context.Context) error {
+	if e.bus == nil {
+		return nil
+	}
+	return e.bus.Subscribe(ctx, e.applyInvalidation, e.cache.invalidateAll)
+}
+
 // InvalidateTemplate removes a specific template from the L1 cache.
 // Called by admin handlers after template update or delete.
 func (e *Engine) InvalidateTemplate(id string) {
 	e.cache.invalidate(id)
+	e.publishInvalidation(cache.InvalidationEvent{TemplateID: id})
 }
 
 // InvalidateAllTemplates clears the entire L1 cache. Called after
 // template activation since it changes which template serves each type.
 func (e *Engine) InvalidateAllTemplates() {
 	e.cache.invalidateAll()
+	e.publishInvalidation(cache.InvalidationEvent{All: true})
+}
+
+// publishInvalidation announces a local invalidation to other replicas.
+// Best-effort: a failed publish is logged but never blocks the admin
+// action that triggered it.
+func (e *Engine) publishInvalidation(ev cache.InvalidationEvent) {
+	if e.bus == nil {
+		return
+	}
+	ev.Origin = e.instanceID
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := e.bus.Publish(ctx, ev); err != nil {
+		slog.Warn("template invalidation publish failed", "error", err)
+	}
+}
+
+// applyInvalidation handles an event received from the bus, skipping
+// events this engine published itself.
+func (e *Engine) applyInvalidation(ev cache.InvalidationEvent) {
+	if ev.Origin == e.instanceID {
+		return
+	}
+	switch {
+	case ev.All:
+		e.cache.invalidateAll()
+	case ev.TemplateID != "":
+		e.cache.invalidate(ev.TemplateID)
+	}
 }
 
 // RenderPage renders a content item using the active page template,
 // header, and footer. img holds the featured image data including responsive
 // variants (pass nil if none). Returns the complete HTML as a byte slice.
 func (e *Engine) RenderPage(content *models.Content, img *FeaturedImage) ([]byte, error) {
+	return e.RenderLocalizedPage(content, img, nil)
+}
+
+// RenderLocalizedPage is RenderPage for a page with a language and
+// translations: loc fills .Lang and .Translations, and hreflang
+// alternates are added to the page head. loc may be nil.
+func (e *Engine) RenderLocalizedPage(content *models.Content, img *FeaturedImage, loc *Localization) ([]byte, error) {
+	return e.RenderPageWith(content, img, PageExtras{Localization: loc})
+}
+
+// RenderPageWith is RenderPage with the optional page parts in extras.
+func (e *Engine) RenderPageWith(content *models.Content, img *FeaturedImage, extras PageExtras) ([]byte, error) {
 	// Load active templates for each component.
 	header, err := e.renderFragment(models.TemplateTypeHeader, nil)
 	if err != nil {
@@ -195,6 +319,7 @@ func (e *Engine) RenderPage(content *models.Content, img *FeaturedImage) ([]byte
 		data.FeaturedImageURL = img.URL
 		data.FeaturedImageSrcset = img.Srcset
 		data.FeaturedImageAlt = img.Alt
+		data.FeaturedImageCaption = img.Caption
 	}
 
 	if content.Excerpt != nil {
@@ -206,6 +331,11 @@ func (e *Engine) RenderPage(content *models.Content, img *FeaturedImage) ([]byte
 	if content.MetaKeywords != nil {
 		data.MetaKeywords = *content.MetaKeywords
 	}
+	if loc := extras.Localization; loc != nil {
+		data.Lang = loc.Lang
+		data.Translations = loc.Translations
+	}
+	data.RelatedPosts = extras.RelatedPosts
 
 	// Compile and execute the page template (L1 cached by ID+version).
 	rendered, err := e.compileAndRender(pageTmpl.ID.String(), pageTmpl.Version, pageTmpl.HTMLContent, data)
@@ -213,31 +343,97 @@ func (e *Engine) RenderPage(content *models.Content, img *FeaturedImage) ([]byte
 		return nil, err
 	}
 
-	return injectContentCSS(rendered), nil
+	return injectContentCSS(injectAlternates(rendered, data.Translations)), nil
 }
 
 // RenderPostList renders the article_loop template with a list of posts.
 // featuredImages maps content ID strings to their featured image data
 // including responsive variants.
 func (e *Engine) RenderPostList(posts []models.Content, featuredImages map[string]*FeaturedImage) ([]byte, error) {
+	return e.renderList("Blog", "", posts, featuredImages)
+}
+
+// RenderSearch renders the search page with the active search
+// template. Sites without one get the results through the article_loop
+// template, with {{.Query}} set and no snippets or pagination.
+func (e *Engine) RenderSearch(data SearchData) ([]byte, error) {
+	searchTmpl, err := e.templateStore.FindActiveByType(models.TemplateTypeSearch)
+	if err != nil || searchTmpl == nil {
+		posts := make([]PostItem, len(data.Results))
+		for i, r := range data.Results {
+			posts[i] = r.PostItem
+		}
+		return e.renderListItems("Search", data.Query, posts)
+	}
+
+	header, footer := e.layoutFragments()
+	data.SiteName = "YaaiCMS"
+	if data.Title == "" {
+		data.Title = "Search"
+	}
+	data.Header = template.HTML(header)
+	data.Footer = template.HTML(footer)
+	data.Year = time.Now().Year()
+
+	rendered, err := e.compileAndRender(searchTmpl.ID.String(), searchTmpl.Version, searchTmpl.HTMLContent, data)
+	if err != nil {
+		return nil, err
+	}
+	return injectContentCSS(rendered), nil
+}
+
+// renderList renders the article_loop template with a titled list of posts.
+func (e *Engine) renderList(title, query string, posts []models.Content, featuredImages map[string]*FeaturedImage) ([]byte, error) {
+	return e.renderListItems(title, query, PostItems(posts, featuredImages))
+}
+
+// renderListItems renders the article_loop template with listing items.
+func (e *Engine) renderListItems(title, query string, posts []PostItem) ([]byte, error) {
+	loopTmpl, err := e.templateStore.FindActiveByType(models.TemplateTypeArticleLoop)
+	if err != nil || loopTmpl == nil {
+		return nil, fmt.Errorf("no active article_loop template found")
+	}
+
+	header, footer := e.layoutFragments()
+	data := ListData{
+		SiteName: "YaaiCMS",
+		Title:    title,
+		Query:    query,
+		Posts:    posts,
+		Header:   template.HTML(header),
+		Footer:   template.HTML(footer),
+		Year:     time.Now().Year(),
+	}
+
+	rendered, err := e.compileAndRender(loopTmpl.ID.String(), loopTmpl.Version, loopTmpl.HTMLContent, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return injectContentCSS(rendered), nil
+}
+
+// layoutFragments renders the active header and footer. A missing or
+// failing fragment is logged and left empty.
+func (e *Engine) layoutFragments() (header, footer string) {
 	header, err := e.renderFragment(models.TemplateTypeHeader, nil)
 	if err != nil {
 		slog.Warn("header template not found or failed", "error", err)
 		header = ""
 	}
 
-	footer, err := e.renderFragment(models.TemplateTypeFooter, nil)
+	footer, err = e.renderFragment(models.TemplateTypeFooter, nil)
 	if err != nil {
 		slog.Warn("footer template not found or failed", "error", err)
 		footer = ""
 	}
+	return header, footer
+}
 
-	loopTmpl, err := e.templateStore.FindActiveByType(models.TemplateTypeArticleLoop)
-	if err != nil || loopTmpl == nil {
-		return nil, fmt.Errorf("no active article_loop template found")
-	}
-
-	var postItems []PostItem
+// PostItems converts posts to listing items. featuredImages maps content
+// ID strings to their featured image data and may be nil.
+func PostItems(posts []models.Content, featuredImages map[string]*FeaturedImage) []PostItem {
+	var items []PostItem
 	for _, p := range posts {
 		item := PostItem{
 			Title: p.Title,
@@ -253,25 +449,11 @@ func (e *Engine) RenderPostList(posts []models.Content, featuredImages map[strin
 			item.FeaturedImageURL = img.URL
 			item.FeaturedImageSrcset = img.Srcset
 			item.FeaturedImageAlt = img.Alt
+			item.FeaturedImageCaption = img.Caption
 		}
-		postItems = append(postItems, item)
-	}
-
-	data := ListData{
-		SiteName: "YaaiCMS",
-		Title:    "Blog",
-		Posts:    postItems,
-		Header:   template.HTML(header),
-		Footer:   template.HTML(footer),
-		Year:     time.Now().Year(),
+		items = append(items, item)
 	}
-
-	rendered, err := e.compileAndRender(loopTmpl.ID.String(), loopTmpl.Version, loopTmpl.HTMLContent, data)
-	if err != nil {
-		return nil, err
-	}
-
-	return injectContentCSS(rendered), nil
+	return items
 }
 
 // ValidateTemplate attempts to compile a template string and returns an
@@ -349,6 +531,30 @@ func injectContentCSS(rendered []byte) []byte {
 	return []byte(contentStyleTag + html)
 }
 
+// injectAlternates inserts an hreflang <link> per language version, plus
+// x-default for the default language version, before </head>. Pages
+// without translations or without a </head> are returned unchanged.
+func injectAlternates(rendered []byte, translations []Translation) []byte {
+	if len(translations) < 2 {
+		return rendered
+	}
+	page := string(rendered)
+	idx := strings.Index(strings.ToLower(page), "</head>")
+	if idx == -1 {
+		return rendered
+	}
+
+	var sb strings.Builder
+	for _, t := range translations {
+		href := template.HTMLEscapeString(t.URL)
+		fmt.Fprintf(&sb, `<link rel="alternate" hreflang="%s" href="%s">`, template.HTMLEscapeString(t.Locale), href)
+		if t.Default {
+			fmt.Fprintf(&sb, `<link rel="alternate" hreflang="x-default" href="%s">`, href)
+		}
+	}
+	return []byte(page[:idx] + sb.String() + page[idx:])
+}
+
 // RewriteBodyImages is the exported wrapper for rewriteBodyImages, allowing
 // other packages (e.g., admin handlers for preview) to apply the same
 // responsive srcset rewriting to content bodies.
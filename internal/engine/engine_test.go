@@ -5,6 +5,7 @@
 package engine
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"html/template"
@@ -17,6 +18,7 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
 
+	"yaaicms/internal/cache"
 	"yaaicms/internal/database"
 	"yaaicms/internal/models"
 	"yaaicms/internal/store"
@@ -597,6 +599,100 @@ func TestTemplateCacheConcurrency(t *testing.T) {
 	// If we reach here without a race condition panic, the test passes.
 }
 
+// --------------------------------------------------------------------------
+// TestInvalidationBus — L1 invalidations propagate between replicas
+// --------------------------------------------------------------------------
+
+func TestInvalidationBus(t *testing.T) {
+	tmpl := template.Must(template.New("bus").Parse("<p>bus</p>"))
+
+	// Two engines sharing one in-memory bus stand in for two replicas.
+	newReplica := func(t *testing.T, bus *cache.MemoryBus, id string) *Engine {
+		t.Helper()
+		e := &Engine{cache: newTemplateCache(), instanceID: id}
+		e.SetInvalidationBus(bus)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		if err := e.ListenForInvalidations(ctx); err != nil {
+			t.Fatalf("ListenForInvalidations: %v", err)
+		}
+		return e
+	}
+
+	t.Run("single template invalidation reaches other replica", func(t *testing.T) {
+		bus := cache.NewMemoryBus()
+		a := newReplica(t, bus, "replica-a")
+		b := newReplica(t, bus, "replica-b")
+
+		b.cache.put("id-1", 1, tmpl)
+		b.cache.put("id-2", 1, tmpl)
+
+		a.InvalidateTemplate("id-1")
+
+		if b.cache.get("id-1", 1) != nil {
+			t.Error("id-1 should be invalidated on replica b")
+		}
+		if b.cache.get("id-2", 1) == nil {
+			t.Error("id-2 should remain cached on replica b")
+		}
+	})
+
+	t.Run("invalidate all reaches other replica", func(t *testing.T) {
+		bus := cache.NewMemoryBus()
+		a := newReplica(t, bus, "replica-a")
+		b := newReplica(t, bus, "replica-b")
+
+		b.cache.put("id-1", 1, tmpl)
+		b.cache.put("id-2", 1, tmpl)
+
+		a.InvalidateAllTemplates()
+
+		if b.cache.get("id-1", 1) != nil || b.cache.get("id-2", 1) != nil {
+			t.Error("replica b cache should be empty after InvalidateAllTemplates")
+		}
+	})
+
+	t.Run("own events are ignored", func(t *testing.T) {
+		bus := cache.NewMemoryBus()
+		a := newReplica(t, bus, "replica-a")
+
+		// Re-populated after the local invalidation, as a concurrent render
+		// would; the echoed event must not wipe it again.
+		a.cache.put("id-1", 2, tmpl)
+		a.applyInvalidation(cache.InvalidationEvent{Origin: "replica-a", TemplateID: "id-1"})
+
+		if a.cache.get("id-1", 2) == nil {
+			t.Error("own event should not invalidate the local cache")
+		}
+	})
+
+	t.Run("gap flushes the whole cache", func(t *testing.T) {
+		bus := cache.NewMemoryBus()
+		b := newReplica(t, bus, "replica-b")
+
+		b.cache.put("id-1", 1, tmpl)
+		b.cache.put("id-2", 3, tmpl)
+
+		bus.SimulateGap()
+
+		if b.cache.get("id-1", 1) != nil || b.cache.get("id-2", 3) != nil {
+			t.Error("cache should be flushed after a delivery gap")
+		}
+	})
+
+	t.Run("no bus is a no-op", func(t *testing.T) {
+		e := &Engine{cache: newTemplateCache()}
+		if err := e.ListenForInvalidations(context.Background()); err != nil {
+			t.Fatalf("ListenForInvalidations without bus: %v", err)
+		}
+		e.cache.put("id-1", 1, tmpl)
+		e.InvalidateTemplate("id-1")
+		if e.cache.get("id-1", 1) != nil {
+			t.Error("local invalidation should still work without a bus")
+		}
+	})
+}
+
 // ==========================================================================
 // Integration tests — require a running PostgreSQL instance.
 // These tests exercise RenderPage and RenderPostList against real database
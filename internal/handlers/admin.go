@@ -15,17 +15,23 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/google/uuid"
 
 	"yaaicms/internal/ai"
+	"yaaicms/internal/audit"
 	"yaaicms/internal/cache"
+	"yaaicms/internal/cdn"
 	"yaaicms/internal/engine"
+	"yaaicms/internal/jobs"
 	"yaaicms/internal/middleware"
 	"yaaicms/internal/models"
+	"yaaicms/internal/prompts"
 	"yaaicms/internal/render"
+	"yaaicms/internal/search"
 	"yaaicms/internal/session"
 	"yaaicms/internal/slug"
 	"yaaicms/internal/storage"
@@ -35,12 +41,13 @@ import (
 // AIProviderInfo holds display information about a configured AI provider.
 // Used by the Settings page to show which providers are available.
 type AIProviderInfo struct {
-	Name      string // "openai", "gemini", "claude", "mistral"
+	Name      string // "openai", "gemini", "claude", "mistral" or a custom provider name
 	Label     string // Human-friendly label
 	HasKey    bool   // Whether an API key is configured
 	Active    bool   // Whether this is the currently active provider
 	Model     string // Configured model name
 	KeyEnvVar string // Environment variable name for the key
+	BaseURL   string // Endpoint of a custom (OpenAI-compatible or Ollama) provider; empty for built-ins
 }
 
 // AIConfig holds the AI provider configuration visible to admin handlers.
@@ -70,6 +77,26 @@ type Admin struct {
 	cacheLog              *store.CacheLogStore
 	aiRegistry            *ai.Registry
 	aiConfig              *AIConfig
+	warmer                *CacheWarmer                 // Optional; nil disables cache warming
+	purger                cdn.Purger                   // Optional; nil disables CDN purging
+	jobs                  *jobs.Queue                  // Optional; nil disables background AI jobs
+	search                *search.Index                // Optional; nil disables search indexing
+	prompts               *prompts.Library             // Optional; nil uses the built-in prompts
+	aiUsageStore          *store.AIUsageStore          // Optional; nil disables the usage report and budgets
+	aiBudgetStore         *store.AIBudgetStore         // Optional; nil disables AI budgets
+	aiConversations       *store.AIConversationStore   // Optional; nil doesn't save assistant conversations
+	aiBulk                *store.AIBulkStore           // Optional; nil disables bulk AI runs
+	translations          *store.TranslationStore      // Optional; nil disables AI translation
+	mediaDescriptions     *store.MediaDescriptionStore // Optional; nil disables alt text suggestions
+
+	// AI audit log (see SetAIAudit); nil aiAudit disables it.
+	aiAudit        *store.AIAuditStore
+	auditPolicy    *audit.Policy
+	auditRetention time.Duration
+
+	// templateRepairs is how many repair attempts the template builder
+	// makes for an invalid template (see SetTemplateRepairs).
+	templateRepairs int
 }
 
 // NewAdmin creates a new Admin handler group with the given dependencies.
@@ -115,6 +142,8 @@ func (a *Admin) Dashboard(w http.ResponseWriter, r *http.Request) {
 			"PageCount":  pageCount,
 			"UserCount":  len(users),
 			"MediaCount": mediaCount,
+			"CacheWarm":  a.warmer != nil,
+			"AIWarnings": a.dashboardAIWarnings(r),
 		},
 	})
 }
@@ -123,16 +152,7 @@ func (a *Admin) Dashboard(w http.ResponseWriter, r *http.Request) {
 
 // PostsList renders the posts management page.
 func (a *Admin) PostsList(w http.ResponseWriter, r *http.Request) {
-	posts, err := a.contentStore.ListByType(models.ContentTypePost)
-	if err != nil {
-		slog.Error("list posts failed", "error", err)
-	}
-
-	a.renderer.Page(w, r, "posts_list", &render.PageData{
-		Title:   "Posts",
-		Section: "posts",
-		Data:    map[string]any{"Items": posts},
-	})
+	a.contentList(w, r, models.ContentTypePost, "posts_list", "Posts", "posts")
 }
 
 // PostNew renders the new post form.
@@ -174,16 +194,7 @@ func (a *Admin) PostDelete(w http.ResponseWriter, r *http.Request) {
 
 // PagesList renders the pages management page.
 func (a *Admin) PagesList(w http.ResponseWriter, r *http.Request) {
-	pages, err := a.contentStore.ListByType(models.ContentTypePage)
-	if err != nil {
-		slog.Error("list pages failed", "error", err)
-	}
-
-	a.renderer.Page(w, r, "pages_list", &render.PageData{
-		Title:   "Pages",
-		Section: "pages",
-		Data:    map[string]any{"Items": pages},
-	})
+	a.contentList(w, r, models.ContentTypePage, "pages_list", "Pages", "pages")
 }
 
 // PageNew renders the new page form.
@@ -327,6 +338,7 @@ func (a *Admin) createContent(w http.ResponseWriter, r *http.Request, contentTyp
 
 	// Invalidate cache for the new content (homepage may show it in listings).
 	a.invalidateContentCache(r.Context(), created.ID, created.Slug, "create")
+	a.enqueueEmbed(r.Context(), created.ID)
 
 	if contentType == models.ContentTypePage {
 		http.Redirect(w, r, "/admin/pages", http.StatusSeeOther)
@@ -383,6 +395,9 @@ func (a *Admin) editContent(w http.ResponseWriter, r *http.Request, section stri
 	}
 	data["Revisions"] = revisions
 
+	// Load the item's languages for the translations panel.
+	data["Translations"] = a.buildTranslationPanel(item)
+
 	// Load categories for the category selector (posts only).
 	if contentType == "post" {
 		categories, _ := a.categoryStore.FlatTree()
@@ -547,19 +562,20 @@ func (a *Admin) updateContent(w http.ResponseWriter, r *http.Request, section st
 		return
 	}
 
-	// Generate AI revision title + changelog in the background.
+	// Generate AI revision title + changelog as a background job.
 	if created != nil {
-		go a.generateRevisionMeta(created.ID, rev, item, revisionMessage)
+		a.generateRevisionMeta(r.Context(), created.ID, rev, item, revisionMessage)
 	}
 
 	a.invalidateContentCache(r.Context(), item.ID, item.Slug, "update")
+	a.enqueueEmbed(r.Context(), item.ID)
 	http.Redirect(w, r, "/admin/"+section, http.StatusSeeOther)
 }
 
-// generateRevisionMeta uses AI to create a short title and changelog for a
-// revision, comparing the old state (rev) with the new state (updated item).
-// Runs in a background goroutine — errors are logged but don't affect the user.
-func (a *Admin) generateRevisionMeta(revID uuid.UUID, old *models.ContentRevision, updated *models.Content, userMessage string) {
+// generateRevisionMeta queues a job that uses AI to create a short title and
+// changelog for a revision, comparing the old state (rev) with the new state
+// (updated item). Errors are logged but don't affect the user.
+func (a *Admin) generateRevisionMeta(ctx context.Context, revID uuid.UUID, old *models.ContentRevision, updated *models.Content, userMessage string) {
 	// Build a concise diff summary for the AI.
 	var changes []string
 	if old.Title != updated.Title {
@@ -595,48 +611,21 @@ func (a *Admin) generateRevisionMeta(revID uuid.UUID, old *models.ContentRevisio
 
 	diffSummary := strings.Join(changes, "\n")
 
-	// Generate revision title if the user didn't provide one.
-	ctx := context.Background()
-	revTitle := userMessage
-	if revTitle == "" {
-		prompt := fmt.Sprintf("Changes made:\n%s\n\nOld title: %q\nNew title: %q",
-			diffSummary, truncateStr(old.Title, 100), truncateStr(updated.Title, 100))
-
-		systemPrompt := `You are a version control assistant. Generate a very short revision title
-(max 60 characters) that summarizes the changes made, like a git commit message.
-Output ONLY the title text, nothing else. Use imperative mood (e.g. "Update title and body content").`
-
-		result, err := a.aiRegistry.GenerateForTask(ctx, ai.TaskLight, systemPrompt, prompt)
-		if err != nil {
-			slog.Warn("ai revision title failed", "error", err)
-			revTitle = "Content updated"
-		} else {
-			revTitle = strings.TrimSpace(result)
-			revTitle = strings.Trim(revTitle, `"'`)
-			if len(revTitle) > 80 {
-				revTitle = revTitle[:77] + "..."
-			}
-		}
-	}
+	// Generate the revision title (unless the user provided one) and a
+	// changelog describing what changed.
+	prompt := fmt.Sprintf("Changes made:\n%s\n\nOld title: %q\nNew title: %q",
+		diffSummary, truncateStr(old.Title, 100), truncateStr(updated.Title, 100))
 
-	// Generate a changelog describing what changed.
-	changelogPrompt := fmt.Sprintf("Changes:\n%s", diffSummary)
+	systemPrompt := a.systemPrompt(prompts.RevisionContent, nil)
 
-	changelogSystem := `You are a version control assistant. Generate a brief changelog (2-4 bullet points)
-describing what changed in this content revision. Each bullet should start with "- ".
-Be concise and factual. Output ONLY the bullet points, nothing else.`
-
-	changelog, err := a.aiRegistry.GenerateForTask(ctx, ai.TaskLight, changelogSystem, changelogPrompt)
-	if err != nil {
-		slog.Warn("ai revision changelog failed", "error", err)
-		changelog = diffSummary
-	} else {
-		changelog = strings.TrimSpace(changelog)
-	}
-
-	if err := a.revisionStore.UpdateMeta(revID, revTitle, changelog); err != nil {
-		slog.Error("failed to update revision meta", "id", revID, "error", err)
-	}
+	a.enqueueRevisionMeta(ctx, revisionMetaJob{
+		RevisionID:   revID,
+		SystemPrompt: systemPrompt,
+		Prompt:       prompt,
+		UserTitle:    userMessage,
+		DefaultTitle: "Content updated",
+		DiffSummary:  diffSummary,
+	})
 }
 
 // RevisionRestore restores a content item to the state captured in a revision.
@@ -703,6 +692,7 @@ func (a *Admin) RevisionRestore(w http.ResponseWriter, r *http.Request) {
 	}
 
 	a.invalidateContentCache(r.Context(), item.ID, item.Slug, "restore")
+	a.enqueueEmbed(r.Context(), item.ID)
 
 	// Determine section for redirect.
 	section := "posts"
@@ -784,13 +774,18 @@ func (a *Admin) deleteContent(w http.ResponseWriter, r *http.Request, section st
 		return
 	}
 
-	// Look up the slug before deleting so we can invalidate its cache entry.
+	// Look up the pages before deleting so we can invalidate their cache
+	// entries; deleting removes the translation links.
 	item, _ := a.contentStore.FindByID(id)
+	var pages []publicPage
+	if item != nil {
+		pages = a.contentPages(id, item.Slug)
+	}
 
 	if err := a.contentStore.Delete(id); err != nil {
 		slog.Error("delete content failed", "error", err)
 	} else if item != nil {
-		a.invalidateContentCache(r.Context(), id, item.Slug, "delete")
+		a.invalidatePages(r.Context(), id, pages, "delete")
 	}
 
 	http.Redirect(w, r, "/admin/"+section, http.StatusSeeOther)
@@ -877,7 +872,7 @@ func (a *Admin) TemplateCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// New templates aren't active yet, but log the event for auditing.
-	a.cacheLog.Log("template", created.ID, "create")
+	a.cacheLog.Log("template", created.ID, "create", actorID(r.Context()), 0)
 	http.Redirect(w, r, "/admin/templates", http.StatusSeeOther)
 }
 
@@ -967,8 +962,8 @@ func (a *Admin) TemplateUpdate(w http.ResponseWriter, r *http.Request) {
 		if revErr != nil {
 			slog.Error("failed to create template revision", "error", revErr)
 		} else {
-			// Generate AI revision metadata in background.
-			go a.generateTemplateRevisionMeta(created.ID, oldName, oldHTML, newName, htmlContent, revisionMessage)
+			// Generate AI revision metadata as a background job.
+			a.generateTemplateRevisionMeta(r.Context(), created.ID, oldName, oldHTML, newName, htmlContent, revisionMessage)
 		}
 	}
 
@@ -1051,6 +1046,12 @@ func (a *Admin) TemplatePreview(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// The AI template builder previews generated HTML without its safety
+	// issues, as AITemplateGenerate does.
+	if r.FormValue("safe") == "1" {
+		htmlContent, _ = engine.SanitizeTemplate(htmlContent)
+	}
+
 	result, err := a.engine.ValidateAndRender(htmlContent, data)
 	if err != nil {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -1152,9 +1153,9 @@ func (a *Admin) TemplateRevisionUpdateTitle(w http.ResponseWriter, r *http.Reque
 	fmt.Fprintf(w, `<span class="text-xs font-medium text-gray-900">%s</span>`, html.EscapeString(newTitle))
 }
 
-// generateTemplateRevisionMeta generates AI-powered revision title and changelog
-// for a template revision, running in the background.
-func (a *Admin) generateTemplateRevisionMeta(revID uuid.UUID, oldName, oldHTML, newName, newHTML, userMessage string) {
+// generateTemplateRevisionMeta queues a job that generates an AI-powered
+// revision title and changelog for a template revision.
+func (a *Admin) generateTemplateRevisionMeta(ctx context.Context, revID uuid.UUID, oldName, oldHTML, newName, newHTML, userMessage string) {
 	// Build a concise diff summary.
 	var changes []string
 	if oldName != newName {
@@ -1172,46 +1173,62 @@ func (a *Admin) generateTemplateRevisionMeta(revID uuid.UUID, oldName, oldHTML,
 
 	diffSummary := strings.Join(changes, "\n")
 
-	ctx := context.Background()
-	revTitle := userMessage
-	if revTitle == "" {
-		prompt := fmt.Sprintf("Changes made to a template:\n%s\n\nOld name: %q\nNew name: %q",
-			diffSummary, truncateStr(oldName, 100), truncateStr(newName, 100))
+	prompt := fmt.Sprintf("Changes made to a template:\n%s\n\nOld name: %q\nNew name: %q",
+		diffSummary, truncateStr(oldName, 100), truncateStr(newName, 100))
 
-		systemPrompt := `You are a version control assistant. Generate a very short revision title
-(max 60 characters) that summarizes the template changes, like a git commit message.
-Output ONLY the title text, nothing else. Use imperative mood (e.g. "Restyle header with dark nav bar").`
+	systemPrompt := a.systemPrompt(prompts.RevisionTemplate, nil)
 
-		result, err := a.aiRegistry.GenerateForTask(ctx, ai.TaskLight, systemPrompt, prompt)
-		if err != nil {
-			slog.Warn("ai template revision title failed", "error", err)
-			revTitle = "Template updated"
-		} else {
-			revTitle = strings.TrimSpace(result)
-			revTitle = strings.Trim(revTitle, `"'`)
-			if len(revTitle) > 80 {
-				revTitle = revTitle[:77] + "..."
-			}
-		}
+	a.enqueueRevisionMeta(ctx, revisionMetaJob{
+		RevisionID:   revID,
+		Template:     true,
+		SystemPrompt: systemPrompt,
+		Prompt:       prompt,
+		UserTitle:    userMessage,
+		DefaultTitle: "Template updated",
+		DiffSummary:  diffSummary,
+	})
+}
+
+// revisionMetadata is the structured reply for revision titles and changelogs.
+type revisionMetadata struct {
+	Title     string   `json:"title" desc:"Short revision title, at most 60 characters"`
+	Changelog []string `json:"changelog" desc:"2-4 changelog points, without bullet markers"`
+}
+
+var revisionMetadataSchema = ai.MustSchema("revision_metadata", revisionMetadata{})
+
+// revisionMeta asks the AI for a revision title and changelog in one call.
+// A non-empty UserTitle is kept as the title. If the call fails, the
+// title falls back to UserTitle or DefaultTitle and the changelog to
+// DiffSummary, and the error is returned alongside them.
+func (a *Admin) revisionMeta(ctx context.Context, p revisionMetaJob) (title, changelog string, err error) {
+	title, changelog = p.UserTitle, p.DiffSummary
+	if title == "" {
+		title = p.DefaultTitle
 	}
 
-	// Generate a changelog.
-	changelogPrompt := fmt.Sprintf("Changes to a CMS template:\n%s", diffSummary)
-	changelogSystem := `You are a version control assistant. Generate a brief changelog (2-4 bullet points)
-describing what changed in this template revision. Each bullet should start with "- ".
-Be concise and factual. Output ONLY the bullet points, nothing else.`
+	var out revisionMetadata
+	if _, err := a.aiRegistry.GenerateJSON(ctx, ai.TaskLight, revisionMetadataSchema, p.SystemPrompt, p.Prompt, &out); err != nil {
+		return title, changelog, err
+	}
 
-	changelog, err := a.aiRegistry.GenerateForTask(ctx, ai.TaskLight, changelogSystem, changelogPrompt)
-	if err != nil {
-		slog.Warn("ai template revision changelog failed", "error", err)
-		changelog = diffSummary
-	} else {
-		changelog = strings.TrimSpace(changelog)
+	if p.UserTitle == "" {
+		if t := strings.Trim(strings.TrimSpace(out.Title), `"'`); t != "" {
+			title = t
+			if len(title) > 80 {
+				title = title[:77] + "..."
+			}
+		}
 	}
 
-	if err := a.templateRevisionStore.UpdateMeta(revID, revTitle, changelog); err != nil {
-		slog.Error("failed to update template revision meta", "id", revID, "error", err)
+	var bullets []string
+	for _, item := range cleanList(out.Changelog) {
+		bullets = append(bullets, "- "+strings.TrimSpace(strings.TrimLeft(item, "-*• ")))
+	}
+	if len(bullets) > 0 {
+		changelog = strings.Join(bullets, "\n")
 	}
+	return title, changelog, nil
 }
 
 // UsersList renders the user management page with real data.
@@ -1346,42 +1363,116 @@ func (a *Admin) UserCreate(w http.ResponseWriter, r *http.Request) {
 // logs the event. Always invalidates the homepage too since post listings
 // or the "home" page might have changed.
 func (a *Admin) invalidateContentCache(ctx context.Context, contentID uuid.UUID, contentSlug, action string) {
-	a.pageCache.InvalidatePage(ctx, cache.SlugKey(contentSlug))
-	a.pageCache.InvalidateHomepage(ctx)
-	a.cacheLog.Log("content", contentID, action)
+	a.invalidatePages(ctx, contentID, a.contentPages(contentID, contentSlug), action)
+}
+
+// publicPage is a content page served at contentPath(Locale, Slug).
+type publicPage struct {
+	Locale string
+	Slug   string
+}
+
+// contentPages returns the public pages affected by a change to a content
+// item: its own page plus the other language versions, whose language
+// switcher and hreflang links list it. Look them up before deleting.
+func (a *Admin) contentPages(contentID uuid.UUID, contentSlug string) []publicPage {
+	if a.translations == nil {
+		return []publicPage{{Slug: contentSlug}}
+	}
+
+	sourceID := contentID
+	own := publicPage{Slug: contentSlug}
+	if link, err := a.translations.FindByTranslation(contentID); err == nil && link != nil {
+		sourceID = link.SourceID
+		own.Locale = link.Locale
+	}
+	pages := []publicPage{own}
+
+	list, err := a.translations.ListBySource(sourceID)
+	if err != nil {
+		slog.Warn("list translations failed", "content", sourceID, "error", err)
+	}
+	if len(list) == 0 {
+		return pages
+	}
+	if sourceID != contentID {
+		if src, err := a.contentStore.FindByID(sourceID); err == nil && src != nil {
+			pages = append(pages, publicPage{Slug: src.Slug})
+		}
+	}
+	for _, t := range list {
+		if t.TranslationID != contentID {
+			pages = append(pages, publicPage{Locale: t.Locale, Slug: t.Slug})
+		}
+	}
+	return pages
+}
+
+// invalidatePages purges pages and the homepage from the L2 cache and the
+// CDN, logging the event against contentID.
+func (a *Admin) invalidatePages(ctx context.Context, contentID uuid.UUID, pages []publicPage, action string) {
+	n := a.pageCache.InvalidateHomepage(ctx)
+	req := cdn.Request{
+		URLs:          []string{"/"},
+		SurrogateKeys: []string{cdn.SurrogateKeyHomepage},
+	}
+	for _, p := range pages {
+		n += a.pageCache.InvalidatePage(ctx, contentCacheKey(p.Locale, p.Slug))
+		req.URLs = append(req.URLs, contentPath(p.Locale, p.Slug))
+		req.SurrogateKeys = append(req.SurrogateKeys, cdn.PageKey(p.Slug))
+	}
+	a.cacheLog.Log("content", contentID, action, actorID(ctx), n)
+	a.purgeCDN(ctx, req)
 }
 
 // invalidateTemplateCache purges both L1 (compiled template) and L2 (all
 // rendered pages) caches, since any template change can affect any page.
 func (a *Admin) invalidateTemplateCache(ctx context.Context, templateID uuid.UUID, action string) {
 	a.engine.InvalidateTemplate(templateID.String())
-	a.pageCache.InvalidateAll(ctx)
-	a.cacheLog.Log("template", templateID, action)
+	n := a.pageCache.InvalidateAll(ctx)
+	a.cacheLog.Log("template", templateID, action, actorID(ctx), n)
+	a.purgeCDN(ctx, cdn.Request{All: true})
+	a.triggerWarm(WarmReasonInvalidation)
 }
 
 // invalidateAllTemplateCache clears the entire L1 cache and all L2 pages.
 // Used for template activation which changes the active template for a type.
 func (a *Admin) invalidateAllTemplateCache(ctx context.Context, templateID uuid.UUID, action string) {
 	a.engine.InvalidateAllTemplates()
-	a.pageCache.InvalidateAll(ctx)
-	a.cacheLog.Log("template", templateID, action)
+	n := a.pageCache.InvalidateAll(ctx)
+	a.cacheLog.Log("template", templateID, action, actorID(ctx), n)
+	a.purgeCDN(ctx, cdn.Request{All: true})
+	a.triggerWarm(WarmReasonInvalidation)
 }
 
 // SettingsPage renders the settings page with site configuration and AI provider info.
 func (a *Admin) SettingsPage(w http.ResponseWriter, r *http.Request) {
+	a.renderSettingsPage(w, r, "", "")
+}
+
+// renderSettingsPage renders the settings page with an optional notice or
+// error from a preceding save. Admins also get the AI budget section.
+func (a *Admin) renderSettingsPage(w http.ResponseWriter, r *http.Request, notice, errMsg string) {
 	settings, err := a.siteSettingStore.All()
 	if err != nil {
 		slog.Error("failed to load site settings", "error", err)
 		settings = make(models.SiteSettings)
 	}
 
+	data := map[string]any{
+		"Providers": a.aiConfig.Providers,
+		"Settings":  settings,
+		"Notice":    notice,
+		"Error":     errMsg,
+	}
+	if sess := middleware.SessionFromCtx(r.Context()); sess != nil && sess.Role == string(models.RoleAdmin) && a.aiBudgetStore != nil {
+		data["Budgets"] = a.aiBudgetSettings()
+	}
+
 	a.renderer.Page(w, r, "settings", &render.PageData{
 		Title:   "Settings",
 		Section: "settings",
-		Data: map[string]any{
-			"Providers": a.aiConfig.Providers,
-			"Settings":  settings,
-		},
+		Data:    data,
 	})
 }
 
@@ -6,23 +6,27 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"html"
 	"html/template"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"yaaicms/internal/ai"
 	"yaaicms/internal/engine"
+	"yaaicms/internal/jobs"
 	"yaaicms/internal/markdown"
-	"yaaicms/internal/middleware"
 	"yaaicms/internal/models"
+	"yaaicms/internal/prompts"
 	"yaaicms/internal/render"
 	"yaaicms/internal/slug"
 	"yaaicms/internal/storage"
@@ -55,23 +59,63 @@ func (a *Admin) AIGenerateContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	systemPrompt := fmt.Sprintf(`You are an expert content writer for a CMS. Write a complete %s based on the user's description.
+	if !a.checkAIBudget(w, r) {
+		return
+	}
 
-Rules:
-- Output ONLY the article body as clean Markdown.
-- Use ## and ### for subheadings (not # — the CMS adds the title separately).
-- Use standard Markdown syntax: **bold**, *italic*, > blockquotes, - lists, 1. numbered lists, [links](url), etc.
-- Do NOT wrap the output in code fences.
-- Write 3-6 well-structured paragraphs with subheadings where appropriate.
-- Make the content informative, engaging, and ready to publish.`, contentType)
+	systemPrompt := a.systemPrompt(prompts.ContentGenerate, prompts.Vars{"content_type": contentType})
 
-	result, err := a.aiRegistry.GenerateForTask(r.Context(), ai.TaskContent, systemPrompt, prompt)
+	// Follow-up requests ("make it shorter") continue the thread named by
+	// conversation_id; the first request starts one.
+	conv, history, err := a.loadConversation(r, models.ConversationContent)
+	if err != nil {
+		slog.Warn("ai content conversation unavailable", "error", err)
+		writeAIError(w, "This conversation is no longer available. Start a new one.")
+		return
+	}
+	messages, _ := chatMessages(systemPrompt, history, prompt)
+
+	// Flagged output replaces the streamed text and isn't saved to the
+	// conversation.
+	finish := func(result string) string {
+		if msg := a.moderateOutput(r.Context(), result); msg != "" {
+			return aiErrorFragment(msg)
+		}
+		id := a.saveTurn(r, conv, models.ConversationContent, contentType, prompt, result, "")
+		return contentResultFragment(result) + conversationMarker(id)
+	}
+
+	if wantsEventStream(r) {
+		a.streamAIChat(w, r, ai.TaskContent, messages, func(result string) any {
+			return finish(result)
+		})
+		return
+	}
+
+	res, err := a.aiRegistry.ChatForTask(r.Context(), ai.TaskContent, messages)
 	if err != nil {
 		slog.Error("ai generate content failed", "error", err)
 		writeAIError(w, "AI request failed. Check your provider configuration.")
 		return
 	}
+	reportAIProvider(w, res)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(finish(res.Text)))
+}
+
+// conversationMarker is a hidden input carrying the conversation ID, read
+// by the editor so the next request continues the thread.
+func conversationMarker(id string) string {
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<input type="hidden" data-ai-conversation value="%s">`, html.EscapeString(id))
+}
 
+// contentResultFragment builds the preview fragment for a generated
+// article, with an "Apply" button that fills the body editor.
+func contentResultFragment(result string) string {
 	result = extractHTMLFromResponse(result)
 
 	// Render Markdown to HTML for a human-readable preview while keeping
@@ -81,7 +125,7 @@ Rules:
 		previewHTML = html.EscapeString(result)
 	}
 
-	fragment := fmt.Sprintf(
+	return fmt.Sprintf(
 		`<div class="space-y-3">
 			<div class="ai-preview text-gray-700 bg-gray-50 rounded p-3 max-h-64 overflow-y-auto prose prose-sm">%s</div>
 			<button type="button"
@@ -94,78 +138,258 @@ Rules:
 		quoteJSString(result),
 		quoteJSString(result),
 	)
-
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(fragment))
 }
 
-// AIGenerateImage generates an image using the selected AI provider's image
-// generation capability. Accepts an optional "image_provider" form value to
-// choose a specific provider (e.g., "openai" for DALL-E, "gemini" for Imagen);
-// if empty, uses the active provider or falls back to any image-capable one.
-// The generated image is uploaded to S3 and stored as a media record.
+// AIGenerateImage queues a job that generates an image using the selected AI
+// provider's image generation capability. Accepts an optional
+// "image_provider" form value to choose a specific provider (e.g., "openai"
+// for DALL-E, "gemini" for Imagen); if empty, uses the active provider or
+// falls back to any image-capable one. The job uploads the images to S3 and
+// stores them as media records (see createAIImages).
+//
+// With "image_mode" set to "edit" or "variation", the job changes the
+// media item named by "source_id" instead; see imageJobFromForm for the
+// other options.
+//
+// Returns {"job_id", "status_url"} when the client asks for JSON (the media
+// picker and media library poll the status URL), otherwise an HTML fragment
+// that polls until the image is ready (the featured image HTMX flow).
 func (a *Admin) AIGenerateImage(w http.ResponseWriter, r *http.Request) {
-	prompt := strings.TrimSpace(r.FormValue("ai_image_prompt"))
-	if prompt == "" {
-		writeAIError(w, "Please describe the image you'd like to generate.")
+	p, msg := imageJobFromForm(r)
+	if msg != "" {
+		writeAIError(w, msg)
 		return
 	}
 
-	imageProvider := strings.TrimSpace(r.FormValue("image_provider"))
-
 	if a.storageClient == nil || a.mediaStore == nil {
 		writeAIError(w, "Object storage is not configured. Cannot save generated images.")
 		return
 	}
 
-	if !a.aiRegistry.SupportsImageGeneration() {
+	if p.Mode == models.GenerationGenerate && !a.aiRegistry.SupportsImageGeneration() {
 		writeAIError(w, "Image generation requires OpenAI (DALL-E) or Gemini with GEMINI_MODEL_IMAGE set.")
 		return
 	}
+	if p.Mode != models.GenerationGenerate {
+		if !a.aiRegistry.SupportsImageEditing() {
+			writeAIError(w, "Image editing requires OpenAI or Gemini with GEMINI_MODEL_IMAGE set.")
+			return
+		}
+		source, err := a.mediaStore.FindByID(*p.SourceID)
+		if err != nil {
+			slog.Error("find image to edit failed", "error", err)
+			writeAIError(w, "Failed to load the image to edit.")
+			return
+		}
+		if source == nil || !variantTypes[source.ContentType] {
+			writeAIError(w, "Only JPEG, PNG and WebP images in the media library can be edited.")
+			return
+		}
+	}
 
-	if !a.checkPromptSafety(w, r, prompt) {
+	if a.jobs == nil {
+		writeAIError(w, "Background jobs are not configured. Cannot generate images.")
 		return
 	}
 
-	sess := middleware.SessionFromCtx(r.Context())
+	if !a.checkPromptSafety(w, r, strings.TrimSpace(p.Prompt+" "+p.Style+" "+p.NegativePrompt)) {
+		return
+	}
+
+	if !a.checkAIBudget(w, r) {
+		return
+	}
 
-	// Generate the image using the selected (or default) provider.
-	imgBytes, contentType, err := a.aiRegistry.GenerateImage(r.Context(), imageProvider, prompt)
+	// Generating twice costs twice, so a failed image is retried only once.
+	job, err := a.jobs.Enqueue(jobImageGenerate, p, jobs.Options{
+		MaxAttempts: 2,
+		CreatedBy:   actorID(r.Context()),
+	})
 	if err != nil {
-		slog.Error("ai generate image failed", "error", err)
-		writeAIError(w, "Image generation failed. Check your provider configuration and API limits.")
+		slog.Error("enqueue ai image job failed", "error", err)
+		writeAIError(w, "Failed to queue image generation.")
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		writeJSON(w, http.StatusAccepted, map[string]string{
+			"job_id":     job.ID.String(),
+			"status_url": "/admin/jobs/" + job.ID.String(),
+		})
 		return
 	}
+	writeJobStatus(w, job, jobViewImage)
+}
+
+// imageJobFromForm reads an image request: the prompt ("ai_image_prompt"),
+// provider, mode and source, and the options "image_aspect",
+// "image_count", "image_style", "image_negative_prompt" and "image_seed".
+// Returns a message for the user when the request is incomplete.
+func imageJobFromForm(r *http.Request) (imageJob, string) {
+	p := imageJob{
+		Prompt:         strings.TrimSpace(r.FormValue("ai_image_prompt")),
+		Provider:       strings.TrimSpace(r.FormValue("image_provider")),
+		Mode:           models.GenerationMode(r.FormValue("image_mode")),
+		Aspect:         r.FormValue("image_aspect"),
+		Style:          strings.TrimSpace(r.FormValue("image_style")),
+		NegativePrompt: strings.TrimSpace(r.FormValue("image_negative_prompt")),
+	}
+	p.Count, _ = strconv.Atoi(r.FormValue("image_count"))
+	p.Seed, _ = strconv.ParseInt(strings.TrimSpace(r.FormValue("image_seed")), 10, 64)
+
+	switch p.Mode {
+	case models.GenerationEdit, models.GenerationVariation:
+		id, err := uuid.Parse(r.FormValue("source_id"))
+		if err != nil {
+			return p, "Choose the image to edit."
+		}
+		p.SourceID = &id
+	default:
+		p.Mode = models.GenerationGenerate
+	}
+
+	switch {
+	case p.Mode == models.GenerationVariation:
+		p.Prompt = ""
+	case p.Prompt == "" && p.Mode == models.GenerationEdit:
+		return p, "Please describe the change you'd like to make."
+	case p.Prompt == "":
+		return p, "Please describe the image you'd like to generate."
+	}
+	return p, ""
+}
+
+// aiImage is a generated image saved to the media library.
+type aiImage struct {
+	ID       uuid.UUID `json:"id"`
+	URL      string    `json:"url"`
+	ThumbURL string    `json:"thumb_url"`
+	Filename string    `json:"filename"`
+	AltText  string    `json:"alt_text"`
+}
+
+// imageJobResult is the result of an image generation job: every saved
+// image, with the first one's fields at the top level for clients that
+// use a single image.
+type imageJobResult struct {
+	aiImage
+	Images []aiImage `json:"images"`
+}
+
+// createAIImages generates the images an image job asks for, or edits its
+// source image, and saves each to the media library with its responsive
+// variants, owned by uploaderID. The prompt, provider and options are
+// recorded with every image so it can be run again. It returns the images
+// that were saved, and an error only if none were.
+func (a *Admin) createAIImages(ctx context.Context, p imageJob, uploaderID uuid.UUID) ([]aiImage, error) {
+	opts := p.options().Normalize()
+	gen := models.ImageGeneration{
+		Mode:           p.Mode,
+		Prompt:         p.Prompt,
+		SourceID:       p.SourceID,
+		Aspect:         opts.Aspect,
+		Style:          opts.Style,
+		NegativePrompt: opts.NegativePrompt,
+		Seed:           opts.Seed,
+	}
+	description := p.Prompt
+
+	audited := auditFrom(ctx)
+	audited.prompt(p.Prompt)
+	var res ai.ImageResult
+	var err error
+	if p.Mode == models.GenerationGenerate {
+		res, err = a.aiRegistry.GenerateImage(ctx, p.Provider, p.Prompt, opts)
+	} else {
+		var source *models.Media
+		var edit ai.ImageEdit
+		source, edit.Source, err = a.loadImage(ctx, *p.SourceID)
+		if err != nil {
+			return nil, err
+		}
+		// The source's description fits an edit better than the change.
+		if source.AltText != nil && *source.AltText != "" {
+			description = *source.AltText
+		}
+		if p.Mode == models.GenerationEdit {
+			edit.Prompt = p.Prompt
+		}
+		res, err = a.aiRegistry.EditImage(ctx, p.Provider, edit, opts)
+	}
+	audited.imageCall(res.Provider, err)
+	if err != nil {
+		return nil, fmt.Errorf("generate image: %w", err)
+	}
+	gen.Provider = res.Provider
+
+	// An image that can't be saved is skipped rather than failing the job:
+	// a retry would generate, and pay for, every image again and save
+	// duplicates of the ones already saved. Only a job that saved nothing
+	// fails.
+	images := make([]aiImage, 0, len(res.Images))
+	var saveErr error
+	for i, img := range res.Images {
+		saved, err := a.saveAIImage(ctx, img, gen, description, uploaderID)
+		if err != nil {
+			slog.Error("failed to save generated image", "image", i+1, "of", len(res.Images), "error", err)
+			saveErr = err
+			continue
+		}
+		audited.saved("media/"+saved.ID.String(), 0)
+		images = append(images, *saved)
+	}
+	if len(images) == 0 && saveErr != nil {
+		return nil, saveErr
+	}
+	return images, nil
+}
 
+// loadImage downloads a media library image for editing. A missing or
+// unsupported image fails permanently.
+func (a *Admin) loadImage(ctx context.Context, id uuid.UUID) (*models.Media, ai.Image, error) {
+	m, err := a.mediaStore.FindByID(id)
+	if err != nil {
+		return nil, ai.Image{}, fmt.Errorf("find image %s: %w", id, err)
+	}
+	if m == nil || !variantTypes[m.ContentType] {
+		return nil, ai.Image{}, jobs.Permanent(fmt.Errorf("image %s is missing or can't be edited", id))
+	}
+	data, err := a.storageClient.Download(ctx, m.Bucket, m.S3Key)
+	if err != nil {
+		return nil, ai.Image{}, fmt.Errorf("download image %s: %w", m.S3Key, err)
+	}
+	return m, ai.Image{Data: data, MIMEType: m.ContentType}, nil
+}
+
+// saveAIImage uploads a generated image with its responsive variants and
+// creates its media record. description becomes the alt text and, as a
+// slug, the file name.
+func (a *Admin) saveAIImage(ctx context.Context, img ai.Image, gen models.ImageGeneration, description string, uploaderID uuid.UUID) (*aiImage, error) {
 	// Upload to S3 as a media item (same pipeline as manual uploads).
 	now := time.Now()
 	fileID := uuid.New().String()
-	ext := ".png"
-	if contentType == "image/jpeg" {
-		ext = ".jpg"
-	} else if contentType == "image/webp" {
-		ext = ".webp"
+	contentType := img.MIMEType
+	ext := extensionFromType(contentType)
+	if ext == "" {
+		ext = ".png"
 	}
 	s3Key := fmt.Sprintf("media/%d/%02d/%s%s", now.Year(), now.Month(), fileID, ext)
 	bucket := a.storageClient.PublicBucket()
 
-	ctx := r.Context()
-	if err := a.storageClient.Upload(ctx, bucket, s3Key, contentType, bytes.NewReader(imgBytes), int64(len(imgBytes))); err != nil {
-		slog.Error("ai image s3 upload failed", "error", err, "key", s3Key)
-		writeAIError(w, "Failed to upload generated image.")
-		return
+	if err := a.storageClient.Upload(ctx, bucket, s3Key, contentType, bytes.NewReader(img.Data), int64(len(img.Data))); err != nil {
+		return nil, fmt.Errorf("upload image %s: %w", s3Key, err)
 	}
 
 	// Generate responsive WebP variants (thumb, sm, md, lg).
 	var thumbKey *string
 	var pendingVariants []models.MediaVariant
 	if variantTypes[contentType] {
-		pendingVariants, thumbKey = a.generateAndUploadVariants(ctx, imgBytes, bucket, fileID, now)
+		pendingVariants, thumbKey = a.generateAndUploadVariants(ctx, img.Data, bucket, fileID, now)
 	}
 
 	// Create media record. Derive a descriptive filename from the prompt.
-	altText := truncate(prompt, 500)
-	safeName := slug.Generate(prompt)
+	altText := truncate(description, 500)
+	safeName := slug.Generate(description)
 	if len(safeName) > 80 {
 		safeName = safeName[:80]
 		// Trim at the last hyphen to avoid cutting a word in half.
@@ -180,19 +404,18 @@ func (a *Admin) AIGenerateImage(w http.ResponseWriter, r *http.Request) {
 		Filename:     fileID + ext,
 		OriginalName: safeName + ext,
 		ContentType:  contentType,
-		SizeBytes:    int64(len(imgBytes)),
+		SizeBytes:    int64(len(img.Data)),
 		Bucket:       bucket,
 		S3Key:        s3Key,
 		ThumbS3Key:   thumbKey,
 		AltText:      &altText,
-		UploaderID:   sess.UserID,
+		Generation:   &gen,
+		UploaderID:   uploaderID,
 	}
 
 	created, err := a.mediaStore.Create(media)
 	if err != nil {
-		slog.Error("ai image media insert failed", "error", err)
-		writeAIError(w, "Failed to save image metadata.")
-		return
+		return nil, fmt.Errorf("save image media: %w", err)
 	}
 
 	// Store variant records now that we have the media ID.
@@ -200,28 +423,24 @@ func (a *Admin) AIGenerateImage(w http.ResponseWriter, r *http.Request) {
 
 	// Build image URLs for the response.
 	imgURL := a.storageClient.FileURL(created.S3Key)
-	var thumbURL string
+	thumbURL := imgURL
 	if created.ThumbS3Key != nil {
 		thumbURL = a.storageClient.FileURL(*created.ThumbS3Key)
-	} else {
-		thumbURL = imgURL
 	}
 
-	// Return JSON when the client requests it (used by the media picker modal),
-	// otherwise return an HTML fragment (used by the featured image HTMX flow).
-	if strings.Contains(r.Header.Get("Accept"), "application/json") {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"id":        created.ID.String(),
-			"url":       imgURL,
-			"thumb_url": thumbURL,
-			"filename":  created.OriginalName,
-			"alt_text":  altText,
-		})
-		return
-	}
+	return &aiImage{
+		ID:       created.ID,
+		URL:      imgURL,
+		ThumbURL: thumbURL,
+		Filename: created.OriginalName,
+		AltText:  altText,
+	}, nil
+}
 
-	fragment := fmt.Sprintf(
+// imageResultFragment renders a generated image with a button that sets it
+// as the content's featured image.
+func imageResultFragment(img *aiImage) string {
+	return fmt.Sprintf(
 		`<div class="space-y-3">
 			<img src="%s" alt="%s" class="w-full rounded-lg shadow-sm border border-gray-200">
 			<button type="button"
@@ -233,14 +452,11 @@ func (a *Admin) AIGenerateImage(w http.ResponseWriter, r *http.Request) {
 				Use as Featured Image
 			</button>
 		</div>`,
-		html.EscapeString(thumbURL),
-		html.EscapeString(altText),
-		created.ID.String(),
-		html.EscapeString(imgURL),
+		html.EscapeString(img.ThumbURL),
+		html.EscapeString(img.AltText),
+		img.ID.String(),
+		html.EscapeString(img.URL),
 	)
-
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(fragment))
 }
 
 // AISuggestTitle generates title suggestions based on the content body.
@@ -259,23 +475,27 @@ func (a *Admin) AISuggestTitle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !a.checkAIBudget(w, r) {
+		return
+	}
+
 	prompt := fmt.Sprintf("Title: %s\n\nContent:\n%s", title, truncate(body, 2000))
 
-	systemPrompt := `You are a headline writing expert for a CMS. Generate exactly 5 compelling,
-SEO-friendly title suggestions for the given content. Each title should be on its own line,
-numbered 1-5. Keep titles under 70 characters. Do not include any other text or explanation.`
+	systemPrompt := a.systemPrompt(prompts.ContentTitles, nil)
 
-	result, err := a.aiRegistry.GenerateForTask(r.Context(), ai.TaskLight, systemPrompt, prompt)
+	var out titleSuggestions
+	res, err := a.aiRegistry.GenerateJSON(r.Context(), ai.TaskLight, titleSuggestionsSchema, systemPrompt, prompt, &out)
 	if err != nil {
 		slog.Error("ai suggest title failed", "error", err)
 		writeAIError(w, "AI request failed. Check your provider configuration.")
 		return
 	}
+	reportAIProvider(w, res)
 
-	// Parse the numbered titles and render as clickable items.
-	titles := parseNumberedList(result)
+	// Render the titles as clickable items.
+	titles := cleanList(out.Titles)
 	if len(titles) == 0 {
-		writeAIResult(w, result)
+		writeAIError(w, "AI returned no title suggestions. Please try again.")
 		return
 	}
 
@@ -311,20 +531,22 @@ func (a *Admin) AIGenerateExcerpt(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prompt := fmt.Sprintf("Title: %s\n\nContent:\n%s", title, truncate(body, 2000))
-
-	systemPrompt := `You are a content summarization expert. Generate a compelling excerpt/summary
-of the given content in 1-2 sentences (max 160 characters). The excerpt should capture the essence
-of the content and entice readers to click. Output ONLY the excerpt text, nothing else.`
+	if !a.checkAIBudget(w, r) {
+		return
+	}
 
-	result, err := a.aiRegistry.GenerateForTask(r.Context(), ai.TaskLight, systemPrompt, prompt)
+	result, res, err := a.generateExcerpt(r.Context(), title, body)
 	if err != nil {
 		slog.Error("ai generate excerpt failed", "error", err)
 		writeAIError(w, "AI request failed. Check your provider configuration.")
 		return
 	}
+	reportAIProvider(w, res)
 
-	result = strings.TrimSpace(result)
+	if result == "" {
+		writeAIError(w, "AI returned an empty excerpt. Please try again.")
+		return
+	}
 	escaped := html.EscapeString(result)
 
 	fragment := fmt.Sprintf(
@@ -359,26 +581,17 @@ func (a *Admin) AISEOMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prompt := fmt.Sprintf("Title: %s\n\nContent:\n%s", title, truncate(body, 2000))
-
-	systemPrompt := `You are an SEO expert. For the given content, generate:
-1. A meta description (max 160 characters, compelling for search results)
-2. A comma-separated list of 5-8 relevant keywords
-
-Output your response in EXACTLY this format (two lines only):
-DESCRIPTION: <your meta description>
-KEYWORDS: <keyword1, keyword2, keyword3, ...>
-
-Do not include any other text.`
+	if !a.checkAIBudget(w, r) {
+		return
+	}
 
-	result, err := a.aiRegistry.GenerateForTask(r.Context(), ai.TaskLight, systemPrompt, prompt)
+	desc, keywords, res, err := a.generateSEO(r.Context(), title, body)
 	if err != nil {
 		slog.Error("ai seo metadata failed", "error", err)
 		writeAIError(w, "AI request failed. Check your provider configuration.")
 		return
 	}
-
-	desc, keywords := parseSEOResult(result)
+	reportAIProvider(w, res)
 
 	var sb strings.Builder
 	sb.WriteString(`<div class="space-y-3">`)
@@ -415,9 +628,8 @@ Do not include any other text.`
 		))
 	}
 
-	// Fallback if parsing failed: show raw result.
 	if desc == "" && keywords == "" {
-		writeAIResult(w, result)
+		writeAIError(w, "AI returned no SEO metadata. Please try again.")
 		return
 	}
 
@@ -444,46 +656,26 @@ func (a *Admin) AIRewrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if suggestion != "" && !a.checkPromptSafety(w, r, suggestion) {
+	if !a.checkAIBudget(w, r) {
 		return
 	}
 
-	if tone == "" {
-		tone = "professional"
-	}
-
-	toneDescriptions := map[string]string{
-		"professional": "professional, clear, and authoritative",
-		"casual":       "casual, friendly, and conversational",
-		"formal":       "formal, academic, and precise",
-		"persuasive":   "persuasive, compelling, and action-oriented",
-		"concise":      "concise, direct, and to-the-point",
-	}
-
-	toneDesc, ok := toneDescriptions[tone]
-	if !ok {
-		toneDesc = "professional, clear, and authoritative"
-	}
-
-	prompt := fmt.Sprintf("Title: %s\n\nContent to rewrite:\n%s", title, truncate(body, 3000))
-	if suggestion != "" {
-		prompt += fmt.Sprintf("\n\nEditor's guidance: %s", suggestion)
+	if suggestion != "" && !a.checkPromptSafety(w, r, suggestion) {
+		return
 	}
 
-	systemPrompt := fmt.Sprintf(`You are a professional content editor. Rewrite the given content
-in a %s tone. Preserve the key information and structure but adjust the language and style.
-The content uses Markdown formatting — preserve all Markdown syntax.
-If the editor provided guidance, follow those instructions carefully while applying the requested tone.
-Output ONLY the rewritten content, nothing else.`, toneDesc)
-
-	result, err := a.aiRegistry.GenerateForTask(r.Context(), ai.TaskContent, systemPrompt, prompt)
+	result, res, err := a.rewriteBody(r.Context(), title, truncate(body, 3000), models.BodyFormatMarkdown, tone, suggestion)
 	if err != nil {
 		slog.Error("ai rewrite failed", "error", err)
 		writeAIError(w, "AI request failed. Check your provider configuration.")
 		return
 	}
+	reportAIProvider(w, res)
 
-	result = strings.TrimSpace(result)
+	if msg := a.moderateOutput(r.Context(), result); msg != "" {
+		writeAIError(w, msg)
+		return
+	}
 
 	rewriteHTML, err := markdown.ToHTML(result)
 	if err != nil {
@@ -523,22 +715,20 @@ func (a *Admin) AIExtractTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prompt := fmt.Sprintf("Title: %s\n\nContent:\n%s", title, truncate(body, 2000))
-
-	systemPrompt := `You are a content categorization expert. Extract 5-10 relevant tags from
-the given content. Tags should be short (1-3 words), lowercase, and relevant for blog categorization.
-Output ONLY the tags as a comma-separated list on a single line. No other text.`
+	if !a.checkAIBudget(w, r) {
+		return
+	}
 
-	result, err := a.aiRegistry.GenerateForTask(r.Context(), ai.TaskLight, systemPrompt, prompt)
+	tags, res, err := a.extractTags(r.Context(), title, body)
 	if err != nil {
 		slog.Error("ai extract tags failed", "error", err)
 		writeAIError(w, "AI request failed. Check your provider configuration.")
 		return
 	}
+	reportAIProvider(w, res)
 
-	tags := parseTags(result)
 	if len(tags) == 0 {
-		writeAIResult(w, result)
+		writeAIError(w, "AI returned no tags. Please try again.")
 		return
 	}
 
@@ -563,6 +753,89 @@ Output ONLY the tags as a comma-separated list on a single line. No other text.`
 	w.Write([]byte(sb.String()))
 }
 
+// rewriteTones maps the rewrite tones offered in the editor to the
+// description given to the model.
+var rewriteTones = map[string]string{
+	"professional": "professional, clear, and authoritative",
+	"casual":       "casual, friendly, and conversational",
+	"formal":       "formal, academic, and precise",
+	"persuasive":   "persuasive, compelling, and action-oriented",
+	"concise":      "concise, direct, and to-the-point",
+}
+
+// generateExcerpt asks the AI for a 1-2 sentence excerpt of a content item.
+// Only the start of the body is sent.
+func (a *Admin) generateExcerpt(ctx context.Context, title, body string) (string, ai.Result, error) {
+	prompt := fmt.Sprintf("Title: %s\n\nContent:\n%s", title, truncate(body, 2000))
+
+	systemPrompt := a.systemPrompt(prompts.ContentExcerpt, nil)
+
+	var out contentExcerpt
+	res, err := a.aiRegistry.GenerateJSON(ctx, ai.TaskLight, contentExcerptSchema, systemPrompt, prompt, &out)
+	if err != nil {
+		return "", res, err
+	}
+	return strings.TrimSpace(out.Excerpt), res, nil
+}
+
+// generateSEO asks the AI for a meta description and comma-separated
+// keywords for a content item. Only the start of the body is sent.
+func (a *Admin) generateSEO(ctx context.Context, title, body string) (desc, keywords string, res ai.Result, err error) {
+	prompt := fmt.Sprintf("Title: %s\n\nContent:\n%s", title, truncate(body, 2000))
+
+	systemPrompt := a.systemPrompt(prompts.ContentSEO, nil)
+
+	var out seoMetadata
+	res, err = a.aiRegistry.GenerateJSON(ctx, ai.TaskLight, seoMetadataSchema, systemPrompt, prompt, &out)
+	if err != nil {
+		return "", "", res, err
+	}
+	return strings.TrimSpace(out.Description), strings.Join(cleanList(out.Keywords), ", "), res, nil
+}
+
+// extractTags asks the AI for short lowercase tags for a content item.
+// Only the start of the body is sent.
+func (a *Admin) extractTags(ctx context.Context, title, body string) ([]string, ai.Result, error) {
+	prompt := fmt.Sprintf("Title: %s\n\nContent:\n%s", title, truncate(body, 2000))
+
+	systemPrompt := a.systemPrompt(prompts.ContentTags, nil)
+
+	var out contentTags
+	res, err := a.aiRegistry.GenerateJSON(ctx, ai.TaskLight, contentTagsSchema, systemPrompt, prompt, &out)
+	if err != nil {
+		return nil, res, err
+	}
+	return cleanList(out.Tags), res, nil
+}
+
+// rewriteBody asks the AI to rewrite a body in one of rewriteTones
+// (professional if unknown), following the editor's optional guidance.
+// The whole body is sent; callers limit its length.
+func (a *Admin) rewriteBody(ctx context.Context, title, body string, format models.BodyFormat, tone, guidance string) (string, ai.Result, error) {
+	toneDesc, ok := rewriteTones[tone]
+	if !ok {
+		toneDesc = rewriteTones["professional"]
+	}
+
+	prompt := fmt.Sprintf("Title: %s\n\nContent to rewrite:\n%s", title, body)
+	if guidance != "" {
+		prompt += fmt.Sprintf("\n\nEditor's guidance: %s", guidance)
+	}
+
+	formatNote := "The content uses Markdown formatting — preserve all Markdown syntax."
+	if format == models.BodyFormatHTML {
+		formatNote = "The content is HTML — preserve all tags and attributes."
+	}
+
+	systemPrompt := a.systemPrompt(prompts.ContentRewrite, prompts.Vars{"tone": toneDesc, "format_note": formatNote})
+
+	res, err := a.aiRegistry.CompleteForTask(ctx, ai.TaskContent, systemPrompt, prompt)
+	if err != nil {
+		return "", res, err
+	}
+	return strings.TrimSpace(res.Text), res, nil
+}
+
 // --- Provider Switching ---
 
 // AISetProvider switches the active AI provider at runtime.
@@ -686,6 +959,7 @@ func (a *Admin) writeProviderSelector(w http.ResponseWriter, active string) {
 // If the prompt is flagged, writes an error response and returns false.
 func (a *Admin) checkPromptSafety(w http.ResponseWriter, r *http.Request, prompt string) bool {
 	result, err := a.aiRegistry.CheckPrompt(r.Context(), prompt)
+	auditFrom(r.Context()).checked(prompt, result, err, a.aiRegistry.HasModerator())
 	if err != nil {
 		slog.Warn("moderation check failed, allowing prompt", "error", err)
 		return true // fail open — providers have their own safety filters
@@ -706,17 +980,60 @@ func (a *Admin) checkPromptSafety(w http.ResponseWriter, r *http.Request, prompt
 	return false
 }
 
+// moderateOutput runs generated text through the moderation API before
+// it is shown. It returns "" if the text is safe or can't be checked,
+// otherwise a message for the user; the text must then be discarded.
+func (a *Admin) moderateOutput(ctx context.Context, text string) string {
+	result, err := a.aiRegistry.CheckOutput(ctx, text)
+	if err != nil {
+		slog.Warn("output moderation check failed, allowing output", "error", err)
+		return "" // fail open, like prompts
+	}
+	if result.Safe {
+		return ""
+	}
+
+	categories := strings.Join(result.Categories, ", ")
+	slog.Warn("ai output flagged by moderation", "categories", categories)
+	auditFrom(ctx).setOutcome(models.AuditDiscarded)
+	return fmt.Sprintf("The generated text was flagged for: %s and has been discarded. Please adjust your request and try again.", categories)
+}
+
+// aiProviderEvent tells the editor which provider answered an AI request.
+// FallbackFrom is set when the active provider failed and a fallback
+// provider answered instead.
+type aiProviderEvent struct {
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	FallbackFrom string `json:"fallbackFrom,omitempty"`
+}
+
+func newAIProviderEvent(res ai.Result) aiProviderEvent {
+	return aiProviderEvent{Provider: res.Usage.Provider, Model: res.Usage.Model, FallbackFrom: res.FallbackFrom}
+}
+
+// reportAIProvider sets the X-AI-Provider header and an "ai-provider"
+// HX-Trigger event, which the admin layout shows as a notice when a
+// fallback provider was used. Call it before writing the body.
+func reportAIProvider(w http.ResponseWriter, res ai.Result) {
+	w.Header().Set("X-AI-Provider", res.Usage.Provider)
+	trigger, err := json.Marshal(map[string]aiProviderEvent{"ai-provider": newAIProviderEvent(res)})
+	if err != nil {
+		return
+	}
+	w.Header().Set("HX-Trigger", string(trigger))
+}
+
 // writeAIError writes an error message HTML fragment.
 func writeAIError(w http.ResponseWriter, msg string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprintf(w, `<p class="text-xs text-red-600 bg-red-50 rounded p-2">%s</p>`, html.EscapeString(msg))
+	w.Write([]byte(aiErrorFragment(msg)))
 }
 
-// writeAIResult writes a plain text result as an HTML fragment.
-func writeAIResult(w http.ResponseWriter, result string) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprintf(w, `<p class="text-xs text-gray-700 bg-gray-50 rounded p-2 whitespace-pre-wrap">%s</p>`,
-		html.EscapeString(strings.TrimSpace(result)))
+// aiErrorFragment is the HTML written by writeAIError, for responses
+// that are already under way.
+func aiErrorFragment(msg string) string {
+	return fmt.Sprintf(`<p class="text-xs text-red-600 bg-red-50 rounded p-2">%s</p>`, html.EscapeString(msg))
 }
 
 // truncate cuts a string to maxLen characters, appending "..." if truncated.
@@ -727,65 +1044,47 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
-// parseNumberedList extracts items from a numbered list (e.g., "1. Title Here").
-func parseNumberedList(text string) []string {
-	var items []string
-	for _, line := range strings.Split(text, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		// Strip common numbered list prefixes: "1. ", "1) ", "- "
-		for _, prefix := range []string{"1. ", "2. ", "3. ", "4. ", "5. ", "6. ", "7. ", "8. ", "9. ", "10. ",
-			"1) ", "2) ", "3) ", "4) ", "5) ", "6) ", "7) ", "8) ", "9) ", "10) ",
-			"- ", "* "} {
-			if strings.HasPrefix(line, prefix) {
-				line = strings.TrimPrefix(line, prefix)
-				break
-			}
-		}
-		line = strings.TrimSpace(line)
-		// Remove surrounding quotes if present.
-		line = strings.Trim(line, `"'`)
-		if line != "" {
-			items = append(items, line)
-		}
-	}
-	return items
-}
-
-// parseSEOResult extracts meta description and keywords from the structured
-// AI response (expects "DESCRIPTION: ..." and "KEYWORDS: ..." lines).
-func parseSEOResult(text string) (description, keywords string) {
-	for _, line := range strings.Split(text, "\n") {
-		line = strings.TrimSpace(line)
-		upper := strings.ToUpper(line)
-		if strings.HasPrefix(upper, "DESCRIPTION:") {
-			description = strings.TrimSpace(line[len("DESCRIPTION:"):])
-		} else if strings.HasPrefix(upper, "KEYWORDS:") {
-			keywords = strings.TrimSpace(line[len("KEYWORDS:"):])
-		} else if strings.HasPrefix(upper, "META DESCRIPTION:") {
-			description = strings.TrimSpace(line[len("META DESCRIPTION:"):])
-		} else if strings.HasPrefix(upper, "META KEYWORDS:") {
-			keywords = strings.TrimSpace(line[len("META KEYWORDS:"):])
-		}
-	}
-	return description, keywords
+// Structured replies for the content assistant. Each is requested with
+// ai.Registry.GenerateJSON, which validates the reply against the schema
+// derived from the struct.
+type titleSuggestions struct {
+	Titles []string `json:"titles" desc:"Title suggestions, each under 70 characters"`
+}
+
+type contentExcerpt struct {
+	Excerpt string `json:"excerpt" desc:"One or two sentences, at most 160 characters"`
+}
+
+type seoMetadata struct {
+	Description string   `json:"description" desc:"Meta description, at most 160 characters"`
+	Keywords    []string `json:"keywords" desc:"Relevant search keywords"`
+}
+
+type contentTags struct {
+	Tags []string `json:"tags" desc:"Short lowercase tags of 1-3 words"`
 }
 
-// parseTags splits a comma-separated tag string into individual trimmed tags.
-func parseTags(text string) []string {
-	var tags []string
-	for _, tag := range strings.Split(text, ",") {
-		tag = strings.TrimSpace(tag)
-		// Remove surrounding quotes, dashes, bullets.
-		tag = strings.Trim(tag, `"'-*`)
-		tag = strings.TrimSpace(tag)
-		if tag != "" {
-			tags = append(tags, tag)
+var (
+	titleSuggestionsSchema = ai.MustSchema("title_suggestions", titleSuggestions{})
+	contentExcerptSchema   = ai.MustSchema("content_excerpt", contentExcerpt{})
+	seoMetadataSchema      = ai.MustSchema("seo_metadata", seoMetadata{})
+	contentTagsSchema      = ai.MustSchema("content_tags", contentTags{})
+)
+
+// cleanList trims the items of a list from a structured reply and drops
+// empty ones and duplicates.
+func cleanList(items []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
 		}
+		seen[item] = true
+		out = append(out, item)
 	}
-	return tags
+	return out
 }
 
 // --- AI Template Builder Endpoints ---
@@ -810,6 +1109,20 @@ type templateGenResponse struct {
 	ValidationError string `json:"validation_error,omitempty"`
 	Preview         string `json:"preview,omitempty"`
 	Error           string `json:"error,omitempty"`
+	ConversationID  string `json:"conversation_id,omitempty"`
+
+	// SafetyIssues lists the risky constructs found in the template, or
+	// removed from it when Sanitized is set.
+	SafetyIssues []engine.SafetyIssue `json:"safety_issues,omitempty"`
+	Sanitized    bool                 `json:"sanitized,omitempty"`
+
+	// Repairs is how many times the template was sent back to the model
+	// to fix an error (see repairTemplate).
+	Repairs int `json:"repairs,omitempty"`
+
+	// errorSource is the template ValidationError refers to, for finding
+	// the line it points at.
+	errorSource string
 }
 
 // templateSaveResponse is the JSON response from the template save endpoint.
@@ -819,9 +1132,14 @@ type templateSaveResponse struct {
 }
 
 // AITemplateGenerate generates an HTML+TailwindCSS template from a user prompt.
-// It accepts the template type, prompt, optional conversation history, and
-// optional current HTML for iterative refinement. Returns JSON with the
-// generated HTML, validation status, and a rendered preview.
+// It accepts the template type, prompt, an optional conversation_id to
+// continue a saved conversation, and optional current HTML for iterative
+// refinement. Returns JSON with the generated HTML, validation status, a
+// rendered preview and the conversation ID.
+//
+// Restyle All sets restyle=1 and passes the templates generated so far as
+// chat_history: each template is generated in one shot from that shared
+// context and no conversation is saved.
 func (a *Admin) AITemplateGenerate(w http.ResponseWriter, r *http.Request) {
 	prompt := r.FormValue("prompt")
 	tmplType := r.FormValue("template_type")
@@ -835,6 +1153,7 @@ func (a *Admin) AITemplateGenerate(w http.ResponseWriter, r *http.Request) {
 
 	// Check prompt safety before generating.
 	modResult, err := a.aiRegistry.CheckPrompt(r.Context(), prompt)
+	auditFrom(r.Context()).checked(prompt, modResult, err, a.aiRegistry.HasModerator())
 	if err != nil {
 		slog.Warn("moderation check failed for template prompt", "error", err)
 	} else if !modResult.Safe {
@@ -846,12 +1165,87 @@ func (a *Admin) AITemplateGenerate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if msg := a.aiBudgetExceeded(r); msg != "" {
+		writeJSON(w, http.StatusOK, templateGenResponse{Error: msg})
+		return
+	}
+
 	// Build the system prompt with type-specific variable documentation and
 	// the active design brief (if any) for visual consistency.
-	designBrief := a.getActiveDesignBrief()
-	systemPrompt := buildTemplateSystemPrompt(tmplType, designBrief)
+	systemPrompt := a.systemPrompt(prompts.TemplateGenerate, templatePromptVars(tmplType))
+
+	if r.FormValue("restyle") != "" {
+		userPrompt := templateUserPrompt(tmplType, prompt, currentHTML, chatHistory)
+		if wantsEventStream(r) {
+			a.streamAI(w, r, ai.TaskTemplate, systemPrompt, userPrompt, func(result string) any {
+				_, resp := a.repairTemplate(r, tmplType, ai.Prompt(systemPrompt, userPrompt), result, a.templateGenResult(r, tmplType, result))
+				return resp
+			})
+			return
+		}
+
+		res, err := a.aiRegistry.CompleteForTask(r.Context(), ai.TaskTemplate, systemPrompt, userPrompt)
+		if err != nil {
+			slog.Error("ai template generate failed", "error", err)
+			writeJSON(w, http.StatusOK, templateGenResponse{
+				Error: "AI request failed. Check your provider configuration.",
+			})
+			return
+		}
+		reportAIProvider(w, res)
+		_, resp := a.repairTemplate(r, tmplType, ai.Prompt(systemPrompt, userPrompt), res.Text, a.templateGenResult(r, tmplType, res.Text))
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	conv, history, err := a.loadConversation(r, models.ConversationTemplate)
+	if err != nil {
+		slog.Warn("ai template conversation unavailable", "error", err)
+		writeJSON(w, http.StatusOK, templateGenResponse{Error: "This conversation is no longer available. Start a new one."})
+		return
+	}
 
-	// Build the user prompt with context.
+	// Earlier turns are sent as messages. The editor's HTML is only added
+	// when the model can't see it: it was edited by hand since the last
+	// reply, or that reply no longer fits in the history.
+	messages, kept := chatMessages(systemPrompt, history, "")
+	editorHTML := ""
+	if conv == nil || currentHTML != conv.CurrentHTML || kept == 0 {
+		editorHTML = currentHTML
+	}
+	messages[len(messages)-1].Content = templateUserPrompt(tmplType, prompt, editorHTML, "")
+
+	finish := func(result string) templateGenResponse {
+		result, resp := a.repairTemplate(r, tmplType, messages, result, a.templateGenResult(r, tmplType, result))
+		resp.ConversationID = a.saveTurn(r, conv, models.ConversationTemplate, tmplType, prompt, result, resp.HTML)
+		return resp
+	}
+
+	if wantsEventStream(r) {
+		a.streamAIChat(w, r, ai.TaskTemplate, messages, func(result string) any {
+			return finish(result)
+		})
+		return
+	}
+
+	res, err := a.aiRegistry.ChatForTask(r.Context(), ai.TaskTemplate, messages)
+	if err != nil {
+		slog.Error("ai template generate failed", "error", err)
+		writeJSON(w, http.StatusOK, templateGenResponse{
+			Error: "AI request failed. Check your provider configuration.",
+		})
+		return
+	}
+	reportAIProvider(w, res)
+
+	writeJSON(w, http.StatusOK, finish(res.Text))
+}
+
+// templateUserPrompt builds the user message for a template request: the
+// template type, the current HTML to modify (if any), the shared context
+// from chat_history (if any, and only without current HTML), then the
+// request itself.
+func templateUserPrompt(tmplType, prompt, currentHTML, chatHistory string) string {
 	var userPrompt strings.Builder
 	userPrompt.WriteString(fmt.Sprintf("Template type: %s\n\n", tmplType))
 
@@ -869,30 +1263,37 @@ func (a *Admin) AITemplateGenerate(w http.ResponseWriter, r *http.Request) {
 
 	userPrompt.WriteString("Request: ")
 	userPrompt.WriteString(prompt)
+	return userPrompt.String()
+}
 
-	result, err := a.aiRegistry.GenerateForTask(r.Context(), ai.TaskTemplate, systemPrompt, userPrompt.String())
-	if err != nil {
-		slog.Error("ai template generate failed", "error", err)
-		writeJSON(w, http.StatusOK, templateGenResponse{
-			Error: "AI request failed. Check your provider configuration.",
-		})
-		return
-	}
-
+// templateGenResult validates the AI output as a Go template, scans it
+// for unsafe HTML and renders a preview, using real content if the request
+// names a content_id. Restyle All output is sanitized right away, since
+// nobody reviews it before it is saved.
+func (a *Admin) templateGenResult(r *http.Request, tmplType, result string) templateGenResponse {
 	// Extract HTML from the response (the AI may wrap it in markdown code blocks).
 	htmlContent := extractHTMLFromResponse(result)
+	return a.checkTemplate(r, tmplType, htmlContent, r.FormValue("restyle") != "")
+}
+
+// checkTemplate validates a template and renders its preview. Safety
+// issues are reported, and the preview always leaves them out; with
+// sanitize the returned HTML leaves them out too.
+func (a *Admin) checkTemplate(r *http.Request, tmplType, htmlContent string, sanitize bool) templateGenResponse {
+	cleanHTML, issues := engine.SanitizeTemplate(htmlContent)
+	if sanitize {
+		htmlContent = cleanHTML
+	}
 
 	// Validate as a Go template.
 	validationErr := a.engine.ValidateTemplate(htmlContent)
-	valid := validationErr == nil
-	validationErrStr := ""
-	if validationErr != nil {
-		validationErrStr = validationErr.Error()
-	}
+	errorSource := htmlContent
 
 	// Generate a preview — use real content if a content_id was provided.
+	// A template that parses but fails to render with this data is invalid
+	// too.
 	var previewHTML string
-	if valid {
+	if validationErr == nil {
 		contentID := r.FormValue("content_id")
 		var previewData any
 		if contentID != "" {
@@ -901,29 +1302,63 @@ func (a *Admin) AITemplateGenerate(w http.ResponseWriter, r *http.Request) {
 		if previewData == nil {
 			previewData = buildPreviewData(tmplType)
 		}
-		rendered, err := a.engine.ValidateAndRender(htmlContent, previewData)
-		if err == nil {
+		rendered, err := a.engine.ValidateAndRender(cleanHTML, previewData)
+		if err != nil {
+			validationErr = err
+			errorSource = cleanHTML
+		} else {
 			previewHTML = string(rendered)
 		}
 	}
+	valid := validationErr == nil
+	validationErrStr := ""
+	if validationErr != nil {
+		validationErrStr = validationErr.Error()
+	}
 
 	// Build a summary message for the chat.
 	message := "Template generated successfully."
-	if !valid {
-		message = "Template generated but has a syntax error. I'll try to fix it — describe the issue or try again."
+	switch {
+	case !valid:
+		message = "Template generated but has an error. Describe the issue or try again."
+	case len(issues) > 0 && sanitize:
+		message = fmt.Sprintf("Template generated. Removed %d unsafe construct(s).", len(issues))
+	case len(issues) > 0:
+		message = fmt.Sprintf("Template generated with %d safety issue(s). The preview leaves them out; sanitize the template before saving it.", len(issues))
 	}
 
-	writeJSON(w, http.StatusOK, templateGenResponse{
+	return templateGenResponse{
 		HTML:            htmlContent,
 		Message:         message,
 		Valid:           valid,
 		ValidationError: validationErrStr,
 		Preview:         previewHTML,
-	})
+		SafetyIssues:    issues,
+		Sanitized:       sanitize && len(issues) > 0,
+		errorSource:     errorSource,
+	}
+}
+
+// AITemplateSanitize removes the safety issues found in html_content and
+// returns the result like AITemplateGenerate does, with the removed
+// issues listed.
+func (a *Admin) AITemplateSanitize(w http.ResponseWriter, r *http.Request) {
+	htmlContent := r.FormValue("html_content")
+	if htmlContent == "" {
+		writeJSON(w, http.StatusBadRequest, templateGenResponse{Error: "There is no template to sanitize."})
+		return
+	}
+
+	resp := a.checkTemplate(r, r.FormValue("template_type"), htmlContent, true)
+	if resp.Valid {
+		resp.Message = fmt.Sprintf("Removed %d unsafe construct(s).", len(resp.SafetyIssues))
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // AITemplateSave saves a generated template to the database.
-// Validates the template before saving and triggers cache invalidation.
+// Validates and scans the template before saving and triggers cache
+// invalidation. Templates with safety issues need allow_unsafe=1.
 func (a *Admin) AITemplateSave(w http.ResponseWriter, r *http.Request) {
 	name := r.FormValue("name")
 	tmplType := models.TemplateType(r.FormValue("type"))
@@ -940,6 +1375,14 @@ func (a *Admin) AITemplateSave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Unsafe HTML is only saved when the user confirms it.
+	if issues := engine.ScanTemplate(htmlContent); len(issues) > 0 && r.FormValue("allow_unsafe") != "1" {
+		writeJSON(w, http.StatusOK, templateSaveResponse{
+			Error: fmt.Sprintf("Template has %d safety issue(s), first %s. Sanitize it, or confirm saving it anyway.", len(issues), issues[0]),
+		})
+		return
+	}
+
 	t := &models.Template{
 		Name:        name,
 		Type:        tmplType,
@@ -953,7 +1396,8 @@ func (a *Admin) AITemplateSave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	a.cacheLog.Log("template", created.ID, "create")
+	a.cacheLog.Log("template", created.ID, "create", actorID(r.Context()), 0)
+	auditFrom(r.Context()).saved("template/"+created.ID.String(), utf8.RuneCountInString(htmlContent))
 	writeJSON(w, http.StatusOK, templateSaveResponse{ID: created.ID.String()})
 }
 
@@ -1111,6 +1555,9 @@ func (a *Admin) AIActiveTheme(w http.ResponseWriter, r *http.Request) {
 // getActiveDesignBrief fetches the active theme's style prompt, returning
 // an empty string if no theme is active or the lookup fails.
 func (a *Admin) getActiveDesignBrief() string {
+	if a.themeStore == nil {
+		return ""
+	}
 	theme, err := a.themeStore.FindActive()
 	if err != nil || theme == nil {
 		return ""
@@ -1376,6 +1823,7 @@ func (a *Admin) buildRealPagePreview(contentID string) any {
 			if media.AltText != nil {
 				data.FeaturedImageAlt = *media.AltText
 			}
+			data.FeaturedImageCaption = ptrStr(media.Caption)
 			// Build srcset from variants.
 			if a.variantStore != nil {
 				variants, err := a.variantStore.FindByMediaIDs([]uuid.UUID{media.ID})
@@ -1434,6 +1882,7 @@ func (a *Admin) buildRealArticleLoopPreview() any {
 				if media.AltText != nil {
 					item.FeaturedImageAlt = *media.AltText
 				}
+				item.FeaturedImageCaption = ptrStr(media.Caption)
 				if variantMap != nil {
 					item.FeaturedImageSrcset = buildSrcsetForPreview(a.storageClient, variantMap[media.ID])
 				}
@@ -1466,25 +1915,12 @@ func buildSrcsetForPreview(storageClient *storage.Client, variants []models.Medi
 	return strings.Join(parts, ", ")
 }
 
-// buildTemplateSystemPrompt creates a system prompt that instructs the LLM
-// to generate an HTML+TailwindCSS template with the correct Go template
-// variables for the given template type. Each variable includes a detailed
-// description of its content, purpose, and recommended usage patterns so
-// the AI can make informed design decisions.
-func buildTemplateSystemPrompt(tmplType string, designBrief ...string) string {
-	base := `You are an expert web designer who creates beautiful, modern HTML templates using TailwindCSS.
-You generate complete, production-ready HTML+TailwindCSS templates for a CMS called YaaiCMS.
-
-CRITICAL RULES:
-1. Output ONLY the HTML template code. No explanations, no markdown code fences, no comments outside the HTML.
-2. Use TailwindCSS utility classes for all styling. Do not use custom CSS.
-3. Use Go template syntax for dynamic content: {{.VariableName}}
-4. For raw HTML content (like Body, Header, Footer), the CMS handles escaping — just use {{.Body}} etc.
-5. Templates should be responsive and look professional on all screen sizes.
-6. Use semantic HTML elements (header, nav, main, article, footer, section, etc.).
-7. Include the TailwindCSS CDN script tag only in full page templates (page, article_loop).
-8. Guard optional fields with {{if .Field}} to avoid rendering empty markup.`
-
+// templateVariables describes a template type and documents the Go
+// template variables it can use, filling the template prompt's
+// {{variables}} placeholder. Each variable includes a detailed description
+// of its content, purpose, and recommended usage patterns so the AI can
+// make informed design decisions.
+func templateVariables(tmplType string) string {
 	var vars string
 	switch tmplType {
 	case "header":
@@ -1587,13 +2023,19 @@ Featured image (all three are empty strings when no image is set):
 - {{.FeaturedImageAlt}} (string)
   Descriptive alt text for accessibility and SEO.
 
+- {{.FeaturedImageCaption}} (string, may be empty)
+  Caption to display with the featured image, e.g. in a <figcaption>.
+
   RECOMMENDED featured image pattern:
   {{if .FeaturedImageURL}}
+  <figure>
   <img src="{{.FeaturedImageURL}}"
        {{if .FeaturedImageSrcset}}srcset="{{.FeaturedImageSrcset}}"
        sizes="(max-width: 640px) 100vw, (max-width: 1024px) 1024px, 1920px"{{end}}
        alt="{{.FeaturedImageAlt}}"
        class="w-full h-auto rounded-lg object-cover" loading="lazy">
+  {{if .FeaturedImageCaption}}<figcaption class="mt-2 text-sm text-gray-500">{{.FeaturedImageCaption}}</figcaption>{{end}}
+  </figure>
   {{end}}
 
 SEO metadata (for <head>):
@@ -1612,6 +2054,24 @@ Site-level:
 - {{.Year}} (int, always set)
   Current year. Available but rarely needed in page templates (footer handles copyright).
 
+Language:
+- {{.Lang}} (string, may be empty)
+  The page language code, e.g. "en" or "de". Use as: <html lang="{{if .Lang}}{{.Lang}}{{else}}en{{end}}">
+
+- {{.Translations}} (list, empty when the page has no translations)
+  Every language version of the page, this one included. Each item has
+  .Locale ("de"), .Name (language name in that language, e.g. "Deutsch"),
+  .Title, .URL and .Current (true for the page being shown).
+  hreflang links are added to <head> automatically; use the list for a language switcher:
+  {{if .Translations}}<nav aria-label="Language">{{range .Translations}}{{if .Current}}<span>{{.Name}}</span>{{else}}<a href="{{.URL}}" hreflang="{{.Locale}}">{{.Name}}</a>{{end}} {{end}}</nav>{{end}}
+
+Related posts:
+- {{.RelatedPosts}} (list, empty for pages and when nothing similar exists)
+  Up to 3 posts similar to this one, most similar first. Each item has the
+  same fields as an article loop post: .Title, .Slug, .Excerpt, .PublishedAt
+  and the .FeaturedImage* fields. Show them after the body:
+  {{if .RelatedPosts}}<aside><h2>Related posts</h2><ul>{{range .RelatedPosts}}<li><a href="/{{.Slug}}">{{.Title}}</a></li>{{end}}</ul></aside>{{end}}
+
 DESIGN GUIDELINES:
 - Structure: <html> → <head> (with TailwindCSS CDN, meta tags) → <body> → {{.Header}} → <main> → {{.Footer}}
 - Use a hero section with the title, date, and optional featured image.
@@ -1635,6 +2095,12 @@ Layout:
 - {{.SiteName}} (string) — Site name, for <title> tag.
 - {{.Year}} (int) — Current year.
 - {{.Title}} (string) — Page title, typically "Blog" or "Posts". Display as <h1>.
+- {{.Query}} (string) — The search query when this template renders search
+  results at /search?q=... (only while no search template is active), otherwise
+  empty. Posts are then the results, best first. Include a search form and a
+  message for no results:
+  <form action="/search"><input type="search" name="q" value="{{.Query}}"></form>
+  {{if and .Query (not .Posts)}}<p>No results for "{{.Query}}".</p>{{end}}
 
 Post loop — iterate with {{range .Posts}} ... {{end}}:
 Each post item has these fields:
@@ -1661,6 +2127,9 @@ Each post item has these fields:
 - {{.FeaturedImageAlt}} (string, empty if no image)
   Alt text for the featured image.
 
+- {{.FeaturedImageCaption}} (string, may be empty)
+  Caption for the featured image; cards usually leave it out.
+
   RECOMMENDED post card image pattern:
   {{if .FeaturedImageURL}}
   <img src="{{.FeaturedImageURL}}"
@@ -1677,26 +2146,78 @@ DESIGN GUIDELINES:
 - Include the page title as an <h1> above the post grid.
 - Consider adding visual interest when no featured image exists (colored placeholder, icon, etc.).`
 
+	case "search":
+		vars = `
+
+TEMPLATE TYPE: Search (search results page)
+The search template renders /search?q=... It is a FULL HTML document with
+<html>, <head>, <body> tags. Results are iterated with {{range .Results}}.
+
+Include the TailwindCSS CDN in <head>: <script src="https://cdn.tailwindcss.com"></script>
+
+AVAILABLE VARIABLES:
+
+Layout:
+- {{.Header}} (template.HTML) — Pre-rendered site header. Place at top of <body>.
+- {{.Footer}} (template.HTML) — Pre-rendered site footer. Place at bottom of <body>.
+- {{.SiteName}} (string) — Site name, for <title> tag.
+- {{.Year}} (int) — Current year.
+- {{.Title}} (string) — Page title, "Search". Display as <h1>.
+
+Search:
+- {{.Query}} (string, may be empty)
+  The search query. Always include a search form prefilled with it:
+  <form action="/search" method="get"><input type="search" name="q" value="{{.Query}}"></form>
+
+- {{.Total}} (int) — Number of results on all pages.
+
+- {{.Page}} (int) and {{.TotalPages}} (int) — Current page (from 1) and page count.
+
+- {{.PrevURL}} and {{.NextURL}} (string, empty on the first / last page)
+  Links to the previous and next page of results:
+  {{if .PrevURL}}<a href="{{.PrevURL}}">Previous</a>{{end}} {{if .NextURL}}<a href="{{.NextURL}}">Next</a>{{end}}
+
+Result loop — iterate with {{range .Results}} ... {{end}}:
+Each result has the article loop post fields (.Title, .Slug, .Excerpt,
+.PublishedAt, .FeaturedImageURL, .FeaturedImageSrcset, .FeaturedImageAlt)
+plus:
+
+- {{.Type}} (string) — "post" or "page".
+
+- {{.Snippet}} (template.HTML, may be empty)
+  The matching text with the matched words wrapped in <mark>. Output it as-is
+  and style the marks, e.g. class="[&_mark]:bg-yellow-200".
+
+  Link each result as: <a href="/{{.Slug}}">{{.Title}}</a>
+
+  When there are no results, show a message:
+  {{if and .Query (not .Results)}}<p>No results for "{{.Query}}".</p>{{end}}
+
+DESIGN GUIDELINES:
+- Put the search form at the top, then the result count, results and pagination.
+- Show results as a single-column list: title (linked), snippet, date.
+- Make the layout responsive: full-width on mobile, max-w-3xl centered on desktop.`
+
 	default:
 		vars = "\nGenerate a generic HTML template using TailwindCSS."
 	}
 
-	prompt := base + "\n" + vars
+	return strings.TrimSpace(vars)
+}
 
-	// Inject the design brief if one is active. This ensures all templates
-	// share the same visual language (colors, typography, spacing, mood).
-	if len(designBrief) > 0 && designBrief[0] != "" {
-		prompt += `
+// designBriefSection is appended to the template prompt when a design
+// brief is active and the prompt doesn't place {{design_brief}} itself.
+// It keeps all templates in the same visual language (colors,
+// typography, spacing, mood).
+func designBriefSection(designBrief string) string {
+	return `
 
 DESIGN BRIEF — Follow this style guide for visual consistency across all templates:
-` + designBrief[0] + `
+` + designBrief + `
 
 You MUST follow the design brief above. Match the colors, typography, spacing, and
 visual mood described. All templates (header, footer, page, article_loop) should
 feel like they belong to the same website.`
-	}
-
-	return prompt
 }
 
 // buildPreviewData creates dummy data appropriate for the template type,
@@ -1705,19 +2226,24 @@ func buildPreviewData(tmplType string) any {
 	switch tmplType {
 	case "page":
 		return engine.PageData{
-			SiteName:         "YaaiCMS",
-			Title:            "Preview Page Title",
-			Body:             "<p>This is preview content. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.</p><p>Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris.</p>",
-			Excerpt:          "A brief preview excerpt for the page.",
-			MetaDescription:  "Preview meta description for search engines",
-			FeaturedImageURL:    "https://placehold.co/1200x630/0f172a/e2e8f0?text=Featured+Image",
-			FeaturedImageSrcset: "https://placehold.co/640x336/0f172a/e2e8f0?text=640w 640w, https://placehold.co/1024x538/0f172a/e2e8f0?text=1024w 1024w, https://placehold.co/1920x1008/0f172a/e2e8f0?text=1920w 1920w",
-			FeaturedImageAlt:    "A preview featured image",
-			Slug:                "preview-page",
-			PublishedAt:      "February 25, 2026",
-			Header:           "<header class='bg-gray-800 text-white p-4'><nav class='max-w-6xl mx-auto flex justify-between items-center'><span class='text-xl font-bold'>YaaiCMS</span><div class='space-x-4'><a href='/' class='hover:text-gray-300'>Home</a><a href='/blog' class='hover:text-gray-300'>Blog</a></div></nav></header>",
-			Footer:           "<footer class='bg-gray-800 text-gray-400 p-6 text-center text-sm'>&copy; 2026 YaaiCMS. All rights reserved.</footer>",
-			Year:             2026,
+			SiteName:             "YaaiCMS",
+			Title:                "Preview Page Title",
+			Body:                 "<p>This is preview content. Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.</p><p>Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris.</p>",
+			Excerpt:              "A brief preview excerpt for the page.",
+			MetaDescription:      "Preview meta description for search engines",
+			FeaturedImageURL:     "https://placehold.co/1200x630/0f172a/e2e8f0?text=Featured+Image",
+			FeaturedImageSrcset:  "https://placehold.co/640x336/0f172a/e2e8f0?text=640w 640w, https://placehold.co/1024x538/0f172a/e2e8f0?text=1024w 1024w, https://placehold.co/1920x1008/0f172a/e2e8f0?text=1920w 1920w",
+			FeaturedImageAlt:     "A preview featured image",
+			FeaturedImageCaption: "A caption for the preview image",
+			Slug:                 "preview-page",
+			PublishedAt:          "February 25, 2026",
+			Header:               "<header class='bg-gray-800 text-white p-4'><nav class='max-w-6xl mx-auto flex justify-between items-center'><span class='text-xl font-bold'>YaaiCMS</span><div class='space-x-4'><a href='/' class='hover:text-gray-300'>Home</a><a href='/blog' class='hover:text-gray-300'>Blog</a></div></nav></header>",
+			Footer:               "<footer class='bg-gray-800 text-gray-400 p-6 text-center text-sm'>&copy; 2026 YaaiCMS. All rights reserved.</footer>",
+			Year:                 2026,
+			RelatedPosts: []engine.PostItem{
+				{Title: "Building Modern Websites", Slug: "modern-websites", Excerpt: "Discover the latest techniques for building fast, responsive websites.", PublishedAt: "February 24, 2026"},
+				{Title: "AI-Powered Content Creation", Slug: "ai-content", Excerpt: "How artificial intelligence is transforming the way we create web content.", PublishedAt: "February 23, 2026"},
+			},
 		}
 	case "article_loop":
 		return engine.ListData{
@@ -1732,6 +2258,23 @@ func buildPreviewData(tmplType string) any {
 			Footer: "<footer class='bg-gray-800 text-gray-400 p-6 text-center text-sm'>&copy; 2026 YaaiCMS. All rights reserved.</footer>",
 			Year:   2026,
 		}
+	case "search":
+		return engine.SearchData{
+			SiteName: "YaaiCMS",
+			Title:    "Search",
+			Query:    "templates",
+			Results: []engine.SearchResult{
+				{PostItem: engine.PostItem{Title: "Getting Started with YaaiCMS", Slug: "getting-started", Excerpt: "Learn how to set up your YaaiCMS CMS and create your first blog post.", PublishedAt: "February 25, 2026"}, Type: "post", Snippet: "Create AI-powered <mark>templates</mark> for your header, footer and pages … every <mark>template</mark> can be edited later."},
+				{PostItem: engine.PostItem{Title: "Design", Slug: "design", Excerpt: "How this site is designed."}, Type: "page", Snippet: "The site's <mark>templates</mark> share one design brief."},
+			},
+			Total:      12,
+			Page:       1,
+			TotalPages: 2,
+			NextURL:    "/search?page=2&q=templates",
+			Header:     "<header class='bg-gray-800 text-white p-4'><nav class='max-w-6xl mx-auto flex justify-between items-center'><span class='text-xl font-bold'>YaaiCMS</span><div class='space-x-4'><a href='/' class='hover:text-gray-300'>Home</a><a href='/blog' class='hover:text-gray-300'>Blog</a></div></nav></header>",
+			Footer:     "<footer class='bg-gray-800 text-gray-400 p-6 text-center text-sm'>&copy; 2026 YaaiCMS. All rights reserved.</footer>",
+			Year:       2026,
+		}
 	default:
 		// Header and footer use nil data (they only access .SiteName and .Year).
 		return struct {
@@ -0,0 +1,428 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_audit.go keeps the AI audit log: who asked the AI what, what
+// moderation said, which provider answered and what was saved to the
+// site. Every admin request and AI job carries an audit entry in its
+// context; prompt checks, AI calls and saves add to it, and entries that
+// saw any of them are stored when the request or job ends.
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/audit"
+	"yaaicms/internal/jobs"
+	"yaaicms/internal/models"
+	"yaaicms/internal/render"
+	"yaaicms/internal/store"
+)
+
+// aiAuditPageSize is the number of rows per page of the audit log.
+const aiAuditPageSize = 50
+
+// SetAIAudit enables the AI audit log, storing prompts as policy says.
+func (a *Admin) SetAIAudit(s *store.AIAuditStore, policy *audit.Policy, retention time.Duration) {
+	a.aiAudit = s
+	a.auditPolicy = policy
+	a.auditRetention = retention
+}
+
+// auditEntry collects what one request or job did with AI. Its methods
+// are safe on a nil entry, for code running outside an audited context.
+type auditEntry struct {
+	mu         sync.Mutex
+	used       bool
+	prompts    []string
+	moderation string
+	categories []string
+	provider   string
+	model      string
+	calls      int
+	failures   int
+	chars      int
+	outcome    models.AuditOutcome // Set explicitly; otherwise derived from the calls
+	targets    []string
+	failed     bool // The job returned an error
+}
+
+type auditKey struct{}
+
+// withAuditEntry returns ctx carrying a new, empty audit entry.
+func withAuditEntry(ctx context.Context) (context.Context, *auditEntry) {
+	e := &auditEntry{}
+	return context.WithValue(ctx, auditKey{}, e), e
+}
+
+// auditFrom returns the audit entry of ctx, or nil.
+func auditFrom(ctx context.Context) *auditEntry {
+	e, _ := ctx.Value(auditKey{}).(*auditEntry)
+	return e
+}
+
+// prompt adds a prompt sent to the AI.
+func (e *auditEntry) prompt(text string) {
+	if e == nil || strings.TrimSpace(text) == "" {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.used = true
+	e.prompts = append(e.prompts, text)
+}
+
+// checked adds a prompt and the moderation verdict on it. moderated is
+// false when no moderation API is configured. A flagged prompt blocks
+// the request.
+func (e *auditEntry) checked(text string, res *ai.ModerationResult, err error, moderated bool) {
+	if e == nil {
+		return
+	}
+	e.prompt(text)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.used = true
+	switch {
+	case err != nil || !moderated || res == nil:
+		if e.moderation == "" {
+			e.moderation = models.ModerationUnchecked
+		}
+	case res.Safe:
+		if e.moderation != models.ModerationFlagged {
+			e.moderation = models.ModerationPassed
+		}
+	default:
+		e.moderation = models.ModerationFlagged
+		e.categories = append(e.categories, res.Categories...)
+		e.outcome = models.AuditBlocked
+	}
+}
+
+// call adds a completion. Embeddings are left out: they index content
+// rather than answer a prompt, and the usage report already counts them.
+func (e *auditEntry) call(rec ai.UsageRecord) {
+	if e == nil || rec.Task == ai.TaskEmbedding {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.used = true
+	e.calls++
+	if rec.Err != nil {
+		e.failures++
+		if e.provider != "" {
+			return
+		}
+	}
+	e.provider, e.model = rec.Provider, rec.Model
+	e.chars += rec.OutputChars
+}
+
+// imageCall adds an image generation or edit by provider.
+func (e *auditEntry) imageCall(provider string, err error) {
+	e.call(ai.UsageRecord{Usage: ai.Usage{Provider: provider}, Err: err})
+}
+
+// setOutcome records what became of the AI output.
+func (e *auditEntry) setOutcome(o models.AuditOutcome) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.used = true
+	e.outcome = o
+}
+
+// saved records that AI output of chars characters was saved to the site
+// as target, e.g. "template/<id>".
+func (e *auditEntry) saved(target string, chars int) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.used = true
+	e.outcome = models.AuditSaved
+	e.targets = append(e.targets, target)
+	e.chars += chars
+}
+
+// row returns the audit row for the entry, or nil if nothing AI-related
+// happened.
+func (e *auditEntry) row(policy *audit.Policy, endpoint string, userID *uuid.UUID) *models.AIAudit {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.used {
+		return nil
+	}
+
+	outcome := e.outcome
+	switch {
+	case outcome != "":
+	case e.failed || (e.calls > 0 && e.failures == e.calls):
+		outcome = models.AuditError
+	case e.calls > 0:
+		outcome = models.AuditOK
+	default:
+		outcome = models.AuditNoCall
+	}
+
+	row := &models.AIAudit{
+		UserID:               userID,
+		Endpoint:             endpoint,
+		Provider:             e.provider,
+		Model:                e.model,
+		Calls:                e.calls,
+		ResponseChars:        e.chars,
+		Moderation:           e.moderation,
+		ModerationCategories: strings.Join(e.categories, ", "),
+		Outcome:              outcome,
+		Target:               strings.Join(e.targets, " "),
+	}
+	if policy != nil {
+		hash, text := policy.Prompt(strings.Join(e.prompts, "\n\n"))
+		row.PromptHash = hash
+		if text != "" {
+			row.Prompt = &text
+		}
+	}
+	return row
+}
+
+// storeAudit stores e if it saw anything AI-related. Best-effort:
+// failures are logged.
+func (a *Admin) storeAudit(ctx context.Context, e *auditEntry, endpoint string) {
+	row := e.row(a.auditPolicy, endpoint, actorID(ctx))
+	if row == nil {
+		return
+	}
+	if err := a.aiAudit.Record(row); err != nil {
+		slog.Warn("record ai audit failed", "endpoint", endpoint, "error", err)
+	}
+}
+
+// AuditAI is middleware that gives each admin request an audit entry
+// and stores it when the request is done. The endpoint is the matched
+// route pattern. Requests that don't touch AI leave no row.
+func (a *Admin) AuditAI(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.aiAudit == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, e := withAuditEntry(r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		endpoint := r.URL.Path
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			endpoint = rctx.RoutePattern()
+		}
+		a.storeAudit(ctx, e, endpoint)
+	})
+}
+
+// auditJob wraps h so each run of a job that uses AI is stored with
+// "job:<kind>" as its endpoint. Wrap it in asActor so the row is
+// attributed to the user who queued the job.
+func (a *Admin) auditJob(kind string, h jobs.Handler) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job) (any, error) {
+		if a.aiAudit == nil {
+			return h(ctx, job)
+		}
+		ctx, e := withAuditEntry(ctx)
+		res, err := h(ctx, job)
+		if err != nil {
+			e.mu.Lock()
+			e.failed = true
+			e.mu.Unlock()
+		}
+		a.storeAudit(ctx, e, "job:"+kind)
+		return res, err
+	}
+}
+
+// aiAuditFilter reads the audit log filter from query parameters: q,
+// user, endpoint, outcome, moderation, and from and to as YYYY-MM-DD
+// (both inclusive). It also returns the recognised parameters, for
+// building page and export links.
+func aiAuditFilter(params url.Values) (store.AIAuditFilter, url.Values) {
+	var f store.AIAuditFilter
+	kept := url.Values{}
+	keep := func(key, val string) {
+		if val != "" {
+			kept.Set(key, val)
+		}
+	}
+
+	f.Query = strings.TrimSpace(params.Get("q"))
+	if runes := []rune(f.Query); len(runes) > maxSearchQuery {
+		f.Query = string(runes[:maxSearchQuery])
+	}
+	keep("q", f.Query)
+	if id, err := uuid.Parse(params.Get("user")); err == nil {
+		f.UserID = &id
+		keep("user", id.String())
+	}
+	f.Endpoint = strings.TrimSpace(params.Get("endpoint"))
+	keep("endpoint", f.Endpoint)
+	for _, o := range models.AuditOutcomes {
+		if params.Get("outcome") == string(o) {
+			f.Outcome = o
+			keep("outcome", string(o))
+		}
+	}
+	switch m := params.Get("moderation"); m {
+	case models.ModerationPassed, models.ModerationFlagged, models.ModerationUnchecked:
+		f.Moderation = m
+		keep("moderation", m)
+	}
+	if d, err := time.ParseInLocation("2006-01-02", params.Get("from"), time.Local); err == nil {
+		f.Since = d
+		keep("from", params.Get("from"))
+	}
+	if d, err := time.ParseInLocation("2006-01-02", params.Get("to"), time.Local); err == nil {
+		f.Until = d.AddDate(0, 0, 1)
+		keep("to", params.Get("to"))
+	}
+	return f, kept
+}
+
+// AIAuditPage renders the AI audit log, filtered and paginated.
+func (a *Admin) AIAuditPage(w http.ResponseWriter, r *http.Request) {
+	f, kept := aiAuditFilter(r.URL.Query())
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	page = max(page, 1)
+
+	var errMsg string
+	var items []models.AIAudit
+	var total int
+	var endpoints []string
+	if a.aiAudit == nil {
+		errMsg = "The AI audit log is not configured."
+	} else {
+		var err error
+		if items, total, err = a.aiAudit.List(f, store.Page{Number: page, Size: aiAuditPageSize}); err != nil {
+			slog.Error("list ai audit failed", "error", err)
+			errMsg = "Could not load the AI audit log."
+		}
+		if endpoints, err = a.aiAudit.Endpoints(); err != nil {
+			slog.Error("list ai audit endpoints failed", "error", err)
+		}
+	}
+	users, err := a.userStore.List()
+	if err != nil {
+		slog.Error("list users failed", "error", err)
+	}
+
+	link := func(path string, n int) string {
+		v := url.Values{}
+		for key := range kept {
+			v.Set(key, kept.Get(key))
+		}
+		if n > 1 {
+			v.Set("page", strconv.Itoa(n))
+		}
+		if len(v) == 0 {
+			return path
+		}
+		return path + "?" + v.Encode()
+	}
+	data := map[string]any{
+		"Items":      items,
+		"Total":      total,
+		"Users":      users,
+		"Endpoints":  endpoints,
+		"Outcomes":   models.AuditOutcomes,
+		"Filter":     kept,
+		"Filtered":   len(kept) > 0,
+		"ExportURL":  link("/admin/ai-audit/export.csv", 1),
+		"FullPrompt": a.auditPolicy != nil && a.auditPolicy.Mode == audit.PromptFull,
+		"Retention":  int(a.auditRetention.Hours() / 24),
+		"Error":      errMsg,
+	}
+	if page > 1 {
+		data["PrevURL"] = link("/admin/ai-audit", page-1)
+	}
+	if page*aiAuditPageSize < total {
+		data["NextURL"] = link("/admin/ai-audit", page+1)
+	}
+
+	a.renderer.Page(w, r, "ai_audit", &render.PageData{
+		Title:   "AI Audit Log",
+		Section: "ai_audit",
+		Data:    data,
+	})
+}
+
+// aiAuditCSVHeader is the header row of the CSV export.
+var aiAuditCSVHeader = []string{
+	"time", "user_id", "user", "endpoint", "provider", "model", "calls", "moderation",
+	"moderation_categories", "outcome", "target", "response_chars", "prompt_hash", "prompt",
+}
+
+// AIAuditExport streams the rows matching the page's filter as CSV.
+func (a *Admin) AIAuditExport(w http.ResponseWriter, r *http.Request) {
+	if a.aiAudit == nil {
+		http.Error(w, "The AI audit log is not configured.", http.StatusNotFound)
+		return
+	}
+	f, _ := aiAuditFilter(r.URL.Query())
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="ai-audit-`+time.Now().Format("20060102-150405")+`.csv"`)
+	cw := csv.NewWriter(w)
+	cw.Write(aiAuditCSVHeader)
+	err := a.aiAudit.Each(r.Context(), f, func(e *models.AIAudit) error {
+		return cw.Write(aiAuditCSVRecord(e))
+	})
+	cw.Flush()
+	if err != nil {
+		// The header is already sent; the export ends early.
+		slog.Error("export ai audit failed", "error", err)
+	}
+}
+
+// aiAuditCSVRecord returns the CSV fields of e, in aiAuditCSVHeader order.
+func aiAuditCSVRecord(e *models.AIAudit) []string {
+	var userID string
+	if e.UserID != nil {
+		userID = e.UserID.String()
+	}
+	var prompt string
+	if e.Prompt != nil {
+		prompt = *e.Prompt
+	}
+	fields := []string{
+		e.CreatedAt.UTC().Format(time.RFC3339), userID, e.UserName, e.Endpoint, e.Provider, e.Model,
+		strconv.Itoa(e.Calls), e.Moderation, e.ModerationCategories, string(e.Outcome), e.Target,
+		strconv.Itoa(e.ResponseChars), e.PromptHash, prompt,
+	}
+	for i, s := range fields {
+		fields[i] = csvSafe(s)
+	}
+	return fields
+}
+
+// csvSafe keeps spreadsheets from reading a cell as a formula: prompts
+// are user input, so a leading =, +, -, @ or control character gets a
+// quote in front.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
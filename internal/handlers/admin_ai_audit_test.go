@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/audit"
+	"yaaicms/internal/models"
+)
+
+func TestAuditEntryRow(t *testing.T) {
+	policy, _ := audit.NewPolicy("full", "emails", "")
+	user := uuid.New()
+
+	// A nil entry ignores everything; an untouched one gives no row.
+	var none *auditEntry
+	none.prompt("x")
+	none.call(ai.UsageRecord{})
+	if row := (&auditEntry{}).row(policy, "/x", &user); row != nil {
+		t.Errorf("untouched entry: %+v", row)
+	}
+
+	e := &auditEntry{}
+	e.checked("Ask bob@example.com", &ai.ModerationResult{Safe: true}, nil, true)
+	e.call(ai.UsageRecord{Task: ai.TaskEmbedding, OutputChars: 99})
+	e.call(ai.UsageRecord{Usage: ai.Usage{Provider: "openai", Model: "m1"}, Err: errors.New("rate limited")})
+	e.call(ai.UsageRecord{Usage: ai.Usage{Provider: "gemini", Model: "m2"}, OutputChars: 40})
+	row := e.row(policy, "/admin/ai/rewrite", &user)
+	if row.Outcome != models.AuditOK || row.Calls != 2 || row.Provider != "gemini" || row.Model != "m2" || row.ResponseChars != 40 {
+		t.Errorf("answered after a failover: %+v", row)
+	}
+	if row.Moderation != models.ModerationPassed || row.Prompt == nil || *row.Prompt != "Ask [email]" || len(row.PromptHash) != 64 {
+		t.Errorf("prompt: %+v", row)
+	}
+
+	e = &auditEntry{}
+	e.call(ai.UsageRecord{Usage: ai.Usage{Provider: "openai"}, Err: errors.New("down")})
+	if row := e.row(policy, "/x", nil); row.Outcome != models.AuditError || row.Provider != "openai" {
+		t.Errorf("failed call: %+v", row)
+	}
+
+	e = &auditEntry{}
+	e.checked("p", nil, errors.New("timeout"), true)
+	if row := e.row(policy, "/x", nil); row.Outcome != models.AuditNoCall || row.Moderation != models.ModerationUnchecked {
+		t.Errorf("unchecked prompt without a call: %+v", row)
+	}
+
+	e = &auditEntry{}
+	e.call(ai.UsageRecord{Usage: ai.Usage{Provider: "p"}, OutputChars: 10})
+	e.saved("content/1", 5)
+	e.saved("content/2", 5)
+	if row := e.row(policy, "/x", nil); row.Outcome != models.AuditSaved || row.Target != "content/1 content/2" || row.ResponseChars != 20 {
+		t.Errorf("saved: %+v", row)
+	}
+
+	e = &auditEntry{}
+	e.imageCall("gemini", nil)
+	e.failed = true
+	if row := e.row(policy, "job:image_generate", nil); row.Outcome != models.AuditError || row.Calls != 1 {
+		t.Errorf("failed job: %+v", row)
+	}
+}
+
+func TestAuditModerationVerdicts(t *testing.T) {
+	policy, _ := audit.NewPolicy("hash", "", "")
+
+	run := func(body, reply string) *models.AIAudit {
+		t.Helper()
+		a := newModeratedAdmin(t, reply)
+		ctx, e := withAuditEntry(context.Background())
+		a.AIRewrite(httptest.NewRecorder(), postForm(url.Values{"body": {body}}).WithContext(ctx))
+		return e.row(policy, "/admin/ai/rewrite", nil)
+	}
+
+	row := run("Something FORBIDDEN.", "A rewrite.")
+	if row.Outcome != models.AuditBlocked || row.Moderation != models.ModerationFlagged || row.ModerationCategories != "violence" {
+		t.Errorf("flagged prompt: %+v", row)
+	}
+	if row.Prompt != nil || row.PromptHash == "" {
+		t.Errorf("hash mode kept the prompt: %+v", row)
+	}
+
+	row = run("A harmless draft.", "A FORBIDDEN rewrite.")
+	if row.Outcome != models.AuditDiscarded || row.Moderation != models.ModerationPassed {
+		t.Errorf("flagged output: %+v", row)
+	}
+}
+
+func TestAIAuditFilter(t *testing.T) {
+	user := uuid.New()
+	f, kept := aiAuditFilter(url.Values{
+		"q": {"  compost "}, "user": {user.String()}, "endpoint": {"/admin/ai/rewrite"},
+		"outcome": {"saved"}, "moderation": {"flagged"}, "from": {"2026-10-01"}, "to": {"2026-10-17"},
+	})
+	if f.Query != "compost" || f.UserID == nil || *f.UserID != user || f.Endpoint != "/admin/ai/rewrite" ||
+		f.Outcome != models.AuditSaved || f.Moderation != models.ModerationFlagged {
+		t.Errorf("filter: %+v", f)
+	}
+	if f.Since.Format(time.DateOnly) != "2026-10-01" || f.Until.Format(time.DateOnly) != "2026-10-18" {
+		t.Errorf("dates: %v to %v", f.Since, f.Until)
+	}
+	if len(kept) != 7 {
+		t.Errorf("kept: %v", kept)
+	}
+
+	f, kept = aiAuditFilter(url.Values{"user": {"nobody"}, "outcome": {"maybe"}, "moderation": {"x"}, "from": {"yesterday"}})
+	if f.UserID != nil || f.Outcome != "" || f.Moderation != "" || !f.Since.IsZero() || len(kept) != 0 {
+		t.Errorf("invalid values kept: %+v %v", f, kept)
+	}
+}
+
+func TestAIAuditCSVRecord(t *testing.T) {
+	prompt := "=HYPERLINK(\"http://evil\")"
+	e := &models.AIAudit{
+		Endpoint: "/admin/ai/rewrite", Provider: "openai", Calls: 1, Outcome: models.AuditOK,
+		Prompt: &prompt, PromptHash: "abc", ResponseChars: 12,
+		CreatedAt: time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+	}
+	got := aiAuditCSVRecord(e)
+	if len(got) != len(aiAuditCSVHeader) {
+		t.Fatalf("%d fields for %d columns", len(got), len(aiAuditCSVHeader))
+	}
+	if got[0] != "2026-10-18T09:30:00Z" || got[1] != "" || got[11] != "12" || got[13] != "'"+prompt {
+		t.Errorf("record: %q", got)
+	}
+	if !strings.HasPrefix(csvSafe("-1"), "'") || csvSafe("plain") != "plain" || csvSafe("") != "" {
+		t.Error("csvSafe")
+	}
+}
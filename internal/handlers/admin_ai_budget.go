@@ -0,0 +1,326 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_budget.go enforces per-role and per-user AI budgets and serves
+// the budget section of the Settings page.
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+	"yaaicms/internal/store"
+)
+
+// aiBudgetWarnRatio is the fraction of a budget at which admins are warned.
+const aiBudgetWarnRatio = 0.8
+
+// budgetRoles are the roles with an editable budget, in display order.
+var budgetRoles = []models.Role{models.RoleAdmin, models.RoleEditor, models.RoleAuthor}
+
+// aiBudgetWarning is a user who has used most of their AI budget.
+type aiBudgetWarning struct {
+	UserName string
+	Role     models.Role
+	Limit    string // e.g. "daily token"
+	Percent  int
+}
+
+// roleBudgetRow is one role's row in the Settings budget table. Budget is
+// nil when the role has no limits.
+type roleBudgetRow struct {
+	Role   models.Role
+	Budget *models.AIBudget
+}
+
+// SetAIBudgets enables per-role AI budgets. They are only enforced when
+// usage is recorded too (see SetAIUsage).
+func (a *Admin) SetAIBudgets(s *store.AIBudgetStore) {
+	a.aiBudgetStore = s
+}
+
+// aiBudgetExceeded returns a message for the user if the request's user
+// has used up their AI budget, or "" if they may proceed. Budgets fail
+// open: if usage can't be loaded, the request is allowed.
+func (a *Admin) aiBudgetExceeded(r *http.Request) string {
+	sess := middleware.SessionFromCtx(r.Context())
+	if sess == nil {
+		return ""
+	}
+	msg := a.userBudgetExceeded(sess.UserID, models.Role(sess.Role))
+	if msg != "" {
+		auditFrom(r.Context()).setOutcome(models.AuditOverBudget)
+	}
+	return msg
+}
+
+// userBudgetExceeded is aiBudgetExceeded for a given user, for work that
+// runs outside a request (background jobs).
+func (a *Admin) userBudgetExceeded(userID uuid.UUID, role models.Role) string {
+	if a.aiBudgetStore == nil || a.aiUsageStore == nil {
+		return ""
+	}
+
+	budget, err := a.aiBudgetStore.ForUser(userID, role)
+	if err != nil {
+		slog.Warn("ai budget lookup failed, allowing request", "error", err)
+		return ""
+	}
+	if budget == nil {
+		return ""
+	}
+	spend, err := a.aiUsageStore.Spend(userID)
+	if err != nil {
+		slog.Warn("ai spend lookup failed, allowing request", "error", err)
+		return ""
+	}
+
+	ratio, limit := budget.Usage(spend)
+	if ratio < 1 {
+		return ""
+	}
+	slog.Info("ai request blocked by budget", "user_id", userID, "limit", limit)
+
+	resets := "at midnight UTC"
+	if strings.HasPrefix(limit, "monthly") {
+		resets = "on the 1st of next month"
+	}
+	return fmt.Sprintf("You have reached your %s budget for AI features. It resets %s — ask an administrator if you need more.", limit, resets)
+}
+
+// checkAIBudget writes an AI error fragment and returns false if the
+// user is over budget. Call it before any AI generation.
+func (a *Admin) checkAIBudget(w http.ResponseWriter, r *http.Request) bool {
+	if msg := a.aiBudgetExceeded(r); msg != "" {
+		writeAIError(w, msg)
+		return false
+	}
+	return true
+}
+
+// aiBudgetWarnings returns the users at or above aiBudgetWarnRatio of
+// their budget, most used first.
+func (a *Admin) aiBudgetWarnings() []aiBudgetWarning {
+	if a.aiBudgetStore == nil || a.aiUsageStore == nil {
+		return nil
+	}
+	budgets, err := a.aiBudgetStore.List()
+	if err != nil || len(budgets) == 0 {
+		if err != nil {
+			slog.Error("list ai budgets failed", "error", err)
+		}
+		return nil
+	}
+	spend, err := a.aiUsageStore.SpendByUser()
+	if err != nil {
+		slog.Error("load ai spend failed", "error", err)
+		return nil
+	}
+	users, err := a.userStore.List()
+	if err != nil {
+		slog.Error("list users failed", "error", err)
+		return nil
+	}
+
+	byRole := map[models.Role]*models.AIBudget{}
+	byUser := map[uuid.UUID]*models.AIBudget{}
+	for i := range budgets {
+		b := &budgets[i]
+		if b.UserID != nil {
+			byUser[*b.UserID] = b
+		} else if b.Role != nil {
+			byRole[*b.Role] = b
+		}
+	}
+
+	var warnings []aiBudgetWarning
+	for _, u := range users {
+		b := byUser[u.ID]
+		if b == nil {
+			b = byRole[u.Role]
+		}
+		if b == nil {
+			continue
+		}
+		ratio, limit := b.Usage(spend[u.ID])
+		if ratio >= aiBudgetWarnRatio {
+			warnings = append(warnings, aiBudgetWarning{
+				UserName: u.DisplayName,
+				Role:     u.Role,
+				Limit:    limit,
+				Percent:  int(ratio * 100),
+			})
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Percent > warnings[j].Percent })
+	return warnings
+}
+
+// dashboardAIWarnings returns the budget warnings for admins and nil for
+// everyone else.
+func (a *Admin) dashboardAIWarnings(r *http.Request) []aiBudgetWarning {
+	sess := middleware.SessionFromCtx(r.Context())
+	if sess == nil || sess.Role != string(models.RoleAdmin) {
+		return nil
+	}
+	return a.aiBudgetWarnings()
+}
+
+// aiBudgetSettings returns the template data for the Settings budget
+// section: one row per role, the user overrides, and the users that can
+// be given an override.
+func (a *Admin) aiBudgetSettings() map[string]any {
+	budgets, err := a.aiBudgetStore.List()
+	if err != nil {
+		slog.Error("list ai budgets failed", "error", err)
+	}
+	users, err := a.userStore.List()
+	if err != nil {
+		slog.Error("list users failed", "error", err)
+	}
+
+	rows := make([]roleBudgetRow, len(budgetRoles))
+	var overrides []models.AIBudget
+	for i, role := range budgetRoles {
+		rows[i].Role = role
+	}
+	for i := range budgets {
+		b := budgets[i]
+		if b.UserID != nil {
+			overrides = append(overrides, b)
+			continue
+		}
+		for j := range rows {
+			if b.Role != nil && *b.Role == rows[j].Role {
+				rows[j].Budget = &b
+			}
+		}
+	}
+
+	return map[string]any{
+		"Roles":     rows,
+		"Overrides": overrides,
+		"Users":     users,
+		"Warnings":  a.aiBudgetWarnings(),
+	}
+}
+
+// AIBudgetSave handles POST /admin/settings/ai-budgets — creates or
+// replaces the budget for a role (form field "role") or a user override
+// ("user_id"). Empty limits are unlimited.
+func (a *Admin) AIBudgetSave(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	budget, errMsg := parseAIBudgetForm(r)
+	if errMsg != "" {
+		a.renderSettingsPage(w, r, "", errMsg)
+		return
+	}
+
+	if userID := r.FormValue("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			a.renderSettingsPage(w, r, "", "Please choose a user for the budget override.")
+			return
+		}
+		if err := a.aiBudgetStore.SaveUser(id, budget); err != nil {
+			slog.Error("save user ai budget failed", "error", err)
+			a.renderSettingsPage(w, r, "", "Could not save the AI budget.")
+			return
+		}
+		a.renderSettingsPage(w, r, "AI budget override saved.", "")
+		return
+	}
+
+	role := models.Role(r.FormValue("role"))
+	valid := false
+	for _, br := range budgetRoles {
+		valid = valid || br == role
+	}
+	if !valid {
+		a.renderSettingsPage(w, r, "", "Unknown role.")
+		return
+	}
+	if err := a.aiBudgetStore.SaveRole(role, budget); err != nil {
+		slog.Error("save role ai budget failed", "error", err)
+		a.renderSettingsPage(w, r, "", "Could not save the AI budget.")
+		return
+	}
+	a.renderSettingsPage(w, r, fmt.Sprintf("AI budget for %ss saved.", role), "")
+}
+
+// AIBudgetDeleteUser handles DELETE /admin/settings/ai-budgets/users/{id}
+// — removes a user's override so their role's budget applies again.
+func (a *Admin) AIBudgetDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if err := a.aiBudgetStore.DeleteUser(id); err != nil {
+		slog.Error("delete user ai budget failed", "error", err)
+		a.renderSettingsPage(w, r, "", "Could not remove the AI budget override.")
+		return
+	}
+	a.renderSettingsPage(w, r, "AI budget override removed.", "")
+}
+
+// parseAIBudgetForm reads the four budget limits from the form. Blank
+// fields are unlimited. Returns a validation message for negative or
+// malformed values.
+func parseAIBudgetForm(r *http.Request) (*models.AIBudget, string) {
+	var b models.AIBudget
+	tokens := []struct {
+		field string
+		label string
+		dst   **int64
+	}{
+		{"daily_tokens", "Daily tokens", &b.DailyTokens},
+		{"monthly_tokens", "Monthly tokens", &b.MonthlyTokens},
+	}
+	for _, f := range tokens {
+		v := strings.TrimSpace(r.FormValue(f.field))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return nil, f.label + " must be a whole number of 0 or more."
+		}
+		*f.dst = &n
+	}
+
+	costs := []struct {
+		field string
+		label string
+		dst   **float64
+	}{
+		{"daily_cost_usd", "Daily cost", &b.DailyCostUSD},
+		{"monthly_cost_usd", "Monthly cost", &b.MonthlyCostUSD},
+	}
+	for _, f := range costs {
+		v := strings.TrimSpace(strings.TrimPrefix(r.FormValue(f.field), "$"))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 {
+			return nil, f.label + " must be an amount in USD of 0 or more."
+		}
+		*f.dst = &n
+	}
+	return &b, ""
+}
@@ -0,0 +1,871 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_bulk.go contains bulk AI operations: selecting content by type,
+// status and category, queueing one background job per item, and the
+// review screen where each proposed change is accepted (written with a
+// revision) or rejected.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"yaaicms/internal/jobs"
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+	"yaaicms/internal/render"
+	"yaaicms/internal/session"
+	"yaaicms/internal/store"
+)
+
+const (
+	// jobBulkItem is the job kind that proposes a change for one item.
+	jobBulkItem = "bulk_item"
+
+	// bulkMaxItems caps the items in one run, to keep runs reviewable and
+	// their cost predictable.
+	bulkMaxItems = 200
+
+	// bulkRewriteMaxChars is the longest body a bulk rewrite will send.
+	// Longer bodies fail instead of being cut short.
+	bulkRewriteMaxChars = 12_000
+
+	// bulkRunsLimit is how many recent runs the bulk page lists.
+	bulkRunsLimit = 20
+)
+
+// bulkAction describes an action on the bulk page.
+type bulkAction struct {
+	Action models.BulkAction
+	Label  string
+	Help   string
+}
+
+// bulkActions lists the bulk actions in display order.
+var bulkActions = []bulkAction{
+	{models.BulkExcerpt, "Generate missing excerpts", "Writes a 1-2 sentence excerpt for items without one."},
+	{models.BulkSEO, "Generate SEO metadata", "Writes a meta description and keywords for items without a meta description."},
+	{models.BulkTags, "Extract tags", "Adds extracted tags to each item's meta keywords."},
+	{models.BulkRewrite, "Rewrite in house tone", "Rewrites each body in the chosen tone, following your style notes."},
+}
+
+// bulkActionInfo returns the description of action, or false if unknown.
+func bulkActionInfo(action models.BulkAction) (bulkAction, bool) {
+	for _, b := range bulkActions {
+		if b.Action == action {
+			return b, true
+		}
+	}
+	return bulkAction{}, false
+}
+
+// bulkOptions are a run's settings, stored as JSON on the run.
+type bulkOptions struct {
+	Overwrite bool   `json:"overwrite,omitempty"` // excerpt, seo: also items that already have one
+	Tone      string `json:"tone,omitempty"`      // rewrite
+	Guidance  string `json:"guidance,omitempty"`  // rewrite: house style notes
+}
+
+// bulkProposal is the change proposed for one item. Nil fields are left
+// unchanged when the change is accepted.
+type bulkProposal struct {
+	Excerpt         *string `json:"excerpt,omitempty"`
+	MetaDescription *string `json:"meta_description,omitempty"`
+	MetaKeywords    *string `json:"meta_keywords,omitempty"`
+	Body            *string `json:"body,omitempty"`
+}
+
+// bulkItemJob is the payload of a bulk_item job.
+type bulkItemJob struct {
+	ItemID uuid.UUID `json:"item_id"`
+}
+
+// bulkFieldChange is one field of a proposed change, for review.
+type bulkFieldChange struct {
+	Field string
+	Old   string
+	New   string
+	Long  bool // Body text, shown in a scrolling box
+}
+
+// bulkItemView is an item on the review screen.
+type bulkItemView struct {
+	models.AIBulkItem
+	Changes []bulkFieldChange
+	Pending bool // Queued and its job hasn't finished
+}
+
+// errBulkNotProposed is returned when accepting an item that is no longer
+// waiting for review.
+var errBulkNotProposed = errors.New("item is not waiting for review")
+
+// needsBulk reports whether c should be part of a run of action.
+func needsBulk(action models.BulkAction, opts bulkOptions, c *models.Content) bool {
+	switch action {
+	case models.BulkExcerpt:
+		return opts.Overwrite || ptrStr(c.Excerpt) == ""
+	case models.BulkSEO:
+		return opts.Overwrite || ptrStr(c.MetaDescription) == ""
+	case models.BulkRewrite:
+		return strings.TrimSpace(c.Body) != ""
+	}
+	return true
+}
+
+// mergeKeywords appends tags missing (case-insensitively) from the
+// comma-separated keywords, keeping the result within maxLen characters.
+func mergeKeywords(keywords string, tags []string, maxLen int) string {
+	var out []string
+	seen := map[string]bool{}
+	for _, k := range append(strings.Split(keywords, ","), tags...) {
+		k = strings.TrimSpace(k)
+		if k == "" || seen[strings.ToLower(k)] {
+			continue
+		}
+		next := strings.Join(append(out, k), ", ")
+		if utf8.RuneCountInString(next) > maxLen {
+			break
+		}
+		seen[strings.ToLower(k)] = true
+		out = append(out, k)
+	}
+	return strings.Join(out, ", ")
+}
+
+// parseBulkForm reads the selection and action from the bulk form.
+func parseBulkForm(r *http.Request) (store.ContentFilter, models.BulkAction, bulkOptions) {
+	f := store.ContentFilter{
+		Type:   models.ContentType(r.FormValue("type")),
+		Status: models.ContentStatus(r.FormValue("status")),
+	}
+	if f.Type != models.ContentTypePage {
+		f.Type = models.ContentTypePost
+	}
+	if f.Status != models.ContentStatusDraft && f.Status != models.ContentStatusPublished {
+		f.Status = ""
+	}
+	if id, err := uuid.Parse(r.FormValue("category_id")); err == nil {
+		f.CategoryID = &id
+	}
+
+	opts := bulkOptions{
+		Overwrite: r.FormValue("overwrite") == "on",
+		Tone:      r.FormValue("tone"),
+		Guidance:  strings.TrimSpace(r.FormValue("guidance")),
+	}
+	if _, ok := rewriteTones[opts.Tone]; !ok {
+		opts.Tone = "professional"
+	}
+	return f, models.BulkAction(r.FormValue("action")), opts
+}
+
+// describeFilter returns a short description of a selection, e.g.
+// "Published posts in News".
+func (a *Admin) describeFilter(f store.ContentFilter) string {
+	kind := "posts"
+	if f.Type == models.ContentTypePage {
+		kind = "pages"
+	}
+	desc := "All " + kind
+	switch f.Status {
+	case models.ContentStatusDraft:
+		desc = "Draft " + kind
+	case models.ContentStatusPublished:
+		desc = "Published " + kind
+	}
+	if f.CategoryID != nil {
+		if cat, err := a.categoryStore.FindByID(*f.CategoryID); err == nil && cat != nil {
+			desc += " in " + cat.Name
+		}
+	}
+	return desc
+}
+
+// selectBulk returns the content matching the form's selection that the
+// action applies to.
+func (a *Admin) selectBulk(f store.ContentFilter, action models.BulkAction, opts bulkOptions) ([]models.Content, error) {
+	all, err := a.contentStore.ListFiltered(f)
+	if err != nil {
+		return nil, err
+	}
+	var items []models.Content
+	for i := range all {
+		if needsBulk(action, opts, &all[i]) {
+			items = append(items, all[i])
+		}
+	}
+	return items, nil
+}
+
+// canSeeBulkRun reports whether the session's user may review run.
+func canSeeBulkRun(sess *session.Data, run *models.AIBulkRun) bool {
+	if sess == nil {
+		return false
+	}
+	if sess.Role == string(models.RoleAdmin) {
+		return true
+	}
+	return run.CreatedBy != nil && *run.CreatedBy == sess.UserID
+}
+
+// SetAIBulk enables bulk AI runs. Their items are processed by background
+// jobs, so SetJobQueue is needed too.
+func (a *Admin) SetAIBulk(s *store.AIBulkStore) {
+	a.aiBulk = s
+}
+
+// AIBulkPage renders the bulk action form and the user's recent runs.
+func (a *Admin) AIBulkPage(w http.ResponseWriter, r *http.Request) {
+	a.renderBulkPage(w, r, "")
+}
+
+// renderBulkPage renders the bulk page with an optional error.
+func (a *Admin) renderBulkPage(w http.ResponseWriter, r *http.Request, errMsg string) {
+	sess := middleware.SessionFromCtx(r.Context())
+
+	var createdBy *uuid.UUID
+	if sess.Role != string(models.RoleAdmin) {
+		createdBy = &sess.UserID
+	}
+	var runs []models.AIBulkRun
+	if a.aiBulk == nil {
+		if errMsg == "" {
+			errMsg = "Bulk AI is not configured."
+		}
+	} else {
+		var err error
+		if runs, err = a.aiBulk.ListRuns(createdBy, bulkRunsLimit); err != nil {
+			slog.Error("list bulk runs failed", "error", err)
+		}
+	}
+	categories, _ := a.categoryStore.FlatTree()
+
+	a.renderer.Page(w, r, "ai_bulk", &render.PageData{
+		Title:   "Bulk AI",
+		Section: "ai_bulk",
+		Data: map[string]any{
+			"Actions":    bulkActions,
+			"Categories": categories,
+			"Runs":       runs,
+			"MaxItems":   bulkMaxItems,
+			"Error":      errMsg,
+		},
+	})
+}
+
+// AIBulkPreview is the dry run of a selection: it returns an HTML fragment
+// listing the items a run would process, without calling the AI.
+func (a *Admin) AIBulkPreview(w http.ResponseWriter, r *http.Request) {
+	f, action, opts := parseBulkForm(r)
+	if _, ok := bulkActionInfo(action); !ok {
+		writeAIError(w, "Choose an action.")
+		return
+	}
+	items, err := a.selectBulk(f, action, opts)
+	if err != nil {
+		slog.Error("bulk preview failed", "error", err)
+		writeAIError(w, "Failed to load content.")
+		return
+	}
+
+	var sb strings.Builder
+	switch {
+	case len(items) == 0:
+		sb.WriteString(`<p class="text-sm text-gray-500">No content matches this selection.</p>`)
+	case len(items) > bulkMaxItems:
+		sb.WriteString(fmt.Sprintf(
+			`<p class="text-sm text-red-600">%d items match; a run can process at most %d. Narrow the selection.</p>`,
+			len(items), bulkMaxItems))
+	default:
+		sb.WriteString(fmt.Sprintf(
+			`<p class="text-sm text-gray-700">%d items will be processed. Nothing is changed until you accept each proposal.</p>`,
+			len(items)))
+	}
+	if len(items) > 0 {
+		sb.WriteString(`<ul class="mt-2 max-h-48 overflow-y-auto text-xs text-gray-600 list-disc list-inside">`)
+		for _, c := range items {
+			sb.WriteString("<li>" + html.EscapeString(c.Title) + "</li>")
+		}
+		sb.WriteString(`</ul>`)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(sb.String()))
+}
+
+// AIBulkStart creates a run for the selection and queues one job per item,
+// then sends the user to the run's review screen.
+func (a *Admin) AIBulkStart(w http.ResponseWriter, r *http.Request) {
+	sess := middleware.SessionFromCtx(r.Context())
+	f, action, opts := parseBulkForm(r)
+
+	if _, ok := bulkActionInfo(action); !ok {
+		a.renderBulkPage(w, r, "Choose an action.")
+		return
+	}
+	if a.jobs == nil {
+		a.renderBulkPage(w, r, "Background jobs are not configured.")
+		return
+	}
+	if a.aiBulk == nil {
+		a.renderBulkPage(w, r, "Bulk AI is not configured.")
+		return
+	}
+	if msg := a.aiBudgetExceeded(r); msg != "" {
+		a.renderBulkPage(w, r, msg)
+		return
+	}
+	if opts.Guidance != "" {
+		res, err := a.aiRegistry.CheckPrompt(r.Context(), opts.Guidance)
+		auditFrom(r.Context()).checked(opts.Guidance, res, err, a.aiRegistry.HasModerator())
+		if err == nil && !res.Safe {
+			a.renderBulkPage(w, r, "Your style notes were flagged by moderation. Please reformulate them.")
+			return
+		}
+	}
+
+	items, err := a.selectBulk(f, action, opts)
+	if err != nil {
+		slog.Error("bulk select failed", "error", err)
+		a.renderBulkPage(w, r, "Failed to load content.")
+		return
+	}
+	if len(items) == 0 {
+		a.renderBulkPage(w, r, "No content matches this selection.")
+		return
+	}
+	if len(items) > bulkMaxItems {
+		a.renderBulkPage(w, r, fmt.Sprintf("%d items match; a run can process at most %d. Narrow the selection.", len(items), bulkMaxItems))
+		return
+	}
+
+	optsJSON, _ := json.Marshal(opts)
+	ids := make([]uuid.UUID, len(items))
+	for i, c := range items {
+		ids[i] = c.ID
+	}
+	run, itemIDs, err := a.aiBulk.CreateRun(&models.AIBulkRun{
+		Action:    action,
+		Options:   string(optsJSON),
+		Filter:    a.describeFilter(f),
+		CreatedBy: &sess.UserID,
+	}, ids)
+	if err != nil {
+		slog.Error("create bulk run failed", "error", err)
+		a.renderBulkPage(w, r, "Failed to create the run.")
+		return
+	}
+
+	for _, id := range itemIDs {
+		a.enqueueBulkItem(r.Context(), id)
+	}
+	slog.Info("bulk ai run started", "run", run.ID, "action", action, "items", len(itemIDs), "user", sess.Email)
+
+	url := "/admin/ai-bulk/" + run.ID.String()
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", url)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, url, http.StatusSeeOther)
+}
+
+// enqueueBulkItem queues the job for one item. If it can't be queued the
+// item is marked failed, so it can be retried from the review screen.
+func (a *Admin) enqueueBulkItem(ctx context.Context, itemID uuid.UUID) {
+	job, err := a.jobs.Enqueue(jobBulkItem, bulkItemJob{ItemID: itemID}, jobs.Options{CreatedBy: actorID(ctx)})
+	if err == nil {
+		err = a.aiBulk.SetItemJob(itemID, job.ID)
+	}
+	if err != nil {
+		slog.Error("enqueue bulk item failed", "item", itemID, "error", err)
+		a.aiBulk.Fail(ctx, itemID, "Could not queue the job.")
+	}
+}
+
+// runBulkItemJob proposes the run's change for one item. Failures that
+// won't be retried are recorded on the item.
+func (a *Admin) runBulkItemJob(ctx context.Context, job *jobs.Job) (any, error) {
+	if a.aiBulk == nil {
+		return nil, jobs.Permanent(errors.New("bulk AI is not configured"))
+	}
+	var p bulkItemJob
+	if err := job.Decode(&p); err != nil {
+		return nil, err
+	}
+	item, err := a.aiBulk.FindItem(p.ItemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.Status != models.BulkItemQueued {
+		// Deleted with its content, or already handled.
+		return nil, nil
+	}
+
+	proposal, err := a.proposeBulkItem(ctx, job, item)
+	if err != nil {
+		if jobs.IsPermanent(err) || job.LastAttempt() {
+			if ferr := a.aiBulk.Fail(ctx, item.ID, err.Error()); ferr != nil {
+				slog.Error("fail bulk item failed", "item", item.ID, "error", ferr)
+			}
+		}
+		return nil, err
+	}
+
+	data, _ := json.Marshal(proposal)
+	if err := a.aiBulk.Propose(ctx, item.ID, string(data)); err != nil {
+		return nil, err
+	}
+	return map[string]string{"item_id": item.ID.String()}, nil
+}
+
+// proposeBulkItem generates the change for one item.
+func (a *Admin) proposeBulkItem(ctx context.Context, job *jobs.Job, item *models.AIBulkItem) (*bulkProposal, error) {
+	run, err := a.aiBulk.FindRun(item.RunID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, jobs.Permanent(errors.New("the run was deleted"))
+	}
+	var opts bulkOptions
+	if err := json.Unmarshal([]byte(run.Options), &opts); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("decode bulk run options: %w", err))
+	}
+
+	if job.CreatedBy != nil {
+		if u, err := a.userStore.FindByID(*job.CreatedBy); err == nil && u != nil {
+			if msg := a.userBudgetExceeded(u.ID, u.Role); msg != "" {
+				return nil, jobs.Permanent(errors.New(msg))
+			}
+		}
+	}
+
+	c, err := a.contentStore.FindByID(item.ContentID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, jobs.Permanent(errors.New("the content was deleted"))
+	}
+
+	var p bulkProposal
+	switch run.Action {
+	case models.BulkExcerpt:
+		excerpt, _, err := a.generateExcerpt(ctx, c.Title, c.Body)
+		if err != nil {
+			return nil, err
+		}
+		if excerpt != "" && excerpt != ptrStr(c.Excerpt) {
+			p.Excerpt = &excerpt
+		}
+
+	case models.BulkSEO:
+		desc, keywords, _, err := a.generateSEO(ctx, c.Title, c.Body)
+		if err != nil {
+			return nil, err
+		}
+		if desc != "" && desc != ptrStr(c.MetaDescription) {
+			p.MetaDescription = &desc
+		}
+		if keywords != "" && (opts.Overwrite || ptrStr(c.MetaKeywords) == "") && keywords != ptrStr(c.MetaKeywords) {
+			p.MetaKeywords = &keywords
+		}
+
+	case models.BulkTags:
+		tags, _, err := a.extractTags(ctx, c.Title, c.Body)
+		if err != nil {
+			return nil, err
+		}
+		if merged := mergeKeywords(ptrStr(c.MetaKeywords), tags, maxMetaKeywordLen); merged != ptrStr(c.MetaKeywords) {
+			p.MetaKeywords = &merged
+		}
+
+	case models.BulkRewrite:
+		if utf8.RuneCountInString(c.Body) > bulkRewriteMaxChars {
+			return nil, jobs.Permanent(fmt.Errorf("the body is longer than %d characters; rewrite it from the editor", bulkRewriteMaxChars))
+		}
+		body, _, err := a.rewriteBody(ctx, c.Title, c.Body, c.BodyFormat, opts.Tone, opts.Guidance)
+		if err != nil {
+			return nil, err
+		}
+		if body != "" && body != c.Body {
+			p.Body = &body
+		}
+
+	default:
+		return nil, jobs.Permanent(fmt.Errorf("unknown bulk action %q", run.Action))
+	}
+
+	if p == (bulkProposal{}) {
+		return nil, jobs.Permanent(errors.New("the AI proposed no change"))
+	}
+	if msg := validateMetadata(ptrStr(p.Excerpt), ptrStr(p.MetaDescription), ptrStr(p.MetaKeywords)); msg != "" {
+		return nil, jobs.Permanent(errors.New(msg))
+	}
+	return &p, nil
+}
+
+// findBulkRun loads the run named by the {id} URL parameter and checks
+// that the user may review it. Writes the error response and returns nil
+// if not.
+func (a *Admin) findBulkRun(w http.ResponseWriter, r *http.Request) *models.AIBulkRun {
+	if a.aiBulk == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return nil
+	}
+	run, err := a.aiBulk.FindRun(id)
+	if err != nil {
+		slog.Error("find bulk run failed", "id", id, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil
+	}
+	if run == nil || !canSeeBulkRun(middleware.SessionFromCtx(r.Context()), run) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil
+	}
+	return run
+}
+
+// AIBulkRunPage renders a run's review screen. It refreshes itself while
+// items are still being processed.
+func (a *Admin) AIBulkRunPage(w http.ResponseWriter, r *http.Request) {
+	run := a.findBulkRun(w, r)
+	if run == nil {
+		return
+	}
+	a.renderBulkRun(w, r, run, "", "")
+}
+
+// renderBulkRun renders the review screen with an optional notice or error.
+func (a *Admin) renderBulkRun(w http.ResponseWriter, r *http.Request, run *models.AIBulkRun, notice, errMsg string) {
+	// Reload the counts, which the action before rendering may have changed.
+	if fresh, err := a.aiBulk.FindRun(run.ID); err == nil && fresh != nil {
+		run = fresh
+	}
+	items, err := a.aiBulk.Items(run.ID)
+	if err != nil {
+		slog.Error("list bulk items failed", "run", run.ID, "error", err)
+		errMsg = "Failed to load items."
+	}
+
+	views := make([]bulkItemView, len(items))
+	pending := false
+	for i, item := range items {
+		views[i] = bulkItemView{AIBulkItem: item}
+		if item.Status == models.BulkItemQueued && !item.JobStatus.Finished() && item.JobStatus != "" {
+			views[i].Pending = true
+			pending = true
+		}
+		if item.Status == models.BulkItemProposed {
+			views[i].Changes = a.bulkChanges(&item)
+		}
+	}
+
+	info, _ := bulkActionInfo(run.Action)
+	a.renderer.Page(w, r, "ai_bulk_run", &render.PageData{
+		Title:   "Bulk AI Review",
+		Section: "ai_bulk",
+		Data: map[string]any{
+			"Run":     run,
+			"Action":  info,
+			"Items":   views,
+			"Pending": pending,
+			"Notice":  notice,
+			"Error":   errMsg,
+		},
+	})
+}
+
+// bulkChanges compares a proposed item with its content's current values.
+func (a *Admin) bulkChanges(item *models.AIBulkItem) []bulkFieldChange {
+	var p bulkProposal
+	if err := json.Unmarshal([]byte(item.Proposal), &p); err != nil {
+		return nil
+	}
+	c, err := a.contentStore.FindByID(item.ContentID)
+	if err != nil || c == nil {
+		return nil
+	}
+
+	var changes []bulkFieldChange
+	if p.Excerpt != nil {
+		changes = append(changes, bulkFieldChange{Field: "Excerpt", Old: ptrStr(c.Excerpt), New: *p.Excerpt})
+	}
+	if p.MetaDescription != nil {
+		changes = append(changes, bulkFieldChange{Field: "Meta description", Old: ptrStr(c.MetaDescription), New: *p.MetaDescription})
+	}
+	if p.MetaKeywords != nil {
+		changes = append(changes, bulkFieldChange{Field: "Meta keywords", Old: ptrStr(c.MetaKeywords), New: *p.MetaKeywords})
+	}
+	if p.Body != nil {
+		changes = append(changes, bulkFieldChange{Field: "Body", Old: c.Body, New: *p.Body, Long: true})
+	}
+	return changes
+}
+
+// acceptBulkItem writes a proposed change to its content. A revision of
+// the current state is saved first so the change can be rolled back from
+// the content's revision history; without it nothing is written.
+func (a *Admin) acceptBulkItem(ctx context.Context, run *models.AIBulkRun, item *models.AIBulkItem, userID uuid.UUID) error {
+	ok, err := a.aiBulk.Decide(item.ID, models.BulkItemAccepted)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errBulkNotProposed
+	}
+
+	fail := func(msg string, err error) error {
+		slog.Error("accept bulk item failed", "item", item.ID, "step", msg, "error", err)
+		if ferr := a.aiBulk.Fail(ctx, item.ID, msg); ferr != nil {
+			slog.Error("fail bulk item failed", "item", item.ID, "error", ferr)
+		}
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	var p bulkProposal
+	if err := json.Unmarshal([]byte(item.Proposal), &p); err != nil {
+		return fail("Invalid proposal", err)
+	}
+	c, err := a.contentStore.FindByID(item.ContentID)
+	if err != nil || c == nil {
+		return fail("Content not found", err)
+	}
+
+	info, _ := bulkActionInfo(run.Action)
+	var log []string
+	if p.Excerpt != nil {
+		log = append(log, "- Excerpt: generated")
+	}
+	if p.MetaDescription != nil {
+		log = append(log, "- Meta description: generated")
+	}
+	if p.MetaKeywords != nil {
+		log = append(log, "- Meta keywords: updated")
+	}
+	if p.Body != nil {
+		log = append(log, "- Body: rewritten")
+	}
+
+	rev, err := a.revisionStore.Create(&models.ContentRevision{
+		ContentID:       c.ID,
+		Title:           c.Title,
+		Slug:            c.Slug,
+		Body:            c.Body,
+		BodyFormat:      c.BodyFormat,
+		Excerpt:         c.Excerpt,
+		Status:          string(c.Status),
+		MetaDescription: c.MetaDescription,
+		MetaKeywords:    c.MetaKeywords,
+		FeaturedImageID: c.FeaturedImageID,
+		CategoryID:      c.CategoryID,
+		RevisionTitle:   "Bulk AI: " + info.Label,
+		RevisionLog:     strings.Join(log, "\n"),
+		CreatedBy:       userID,
+	})
+	if err != nil {
+		return fail("Failed to save a revision", err)
+	}
+
+	if p.Excerpt != nil {
+		c.Excerpt = p.Excerpt
+	}
+	if p.MetaDescription != nil {
+		c.MetaDescription = p.MetaDescription
+	}
+	if p.MetaKeywords != nil {
+		c.MetaKeywords = p.MetaKeywords
+	}
+	if p.Body != nil {
+		c.Body = *p.Body
+	}
+	if err := a.contentStore.Update(c); err != nil {
+		return fail("Failed to update the content", err)
+	}
+	if err := a.aiBulk.SetRevision(item.ID, rev.ID); err != nil {
+		slog.Error("set bulk item revision failed", "item", item.ID, "error", err)
+	}
+
+	a.invalidateContentCache(ctx, c.ID, c.Slug, "update")
+	a.enqueueEmbed(ctx, c.ID)
+	auditFrom(ctx).saved("content/"+c.ID.String(), utf8.RuneCountInString(item.Proposal))
+	return nil
+}
+
+// findBulkItem loads the item named by the {id} URL parameter and its
+// run, checking that the user may review it.
+func (a *Admin) findBulkItem(w http.ResponseWriter, r *http.Request) (*models.AIBulkRun, *models.AIBulkItem) {
+	if a.aiBulk == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil, nil
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return nil, nil
+	}
+	item, err := a.aiBulk.FindItem(id)
+	if err != nil {
+		slog.Error("find bulk item failed", "id", id, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil
+	}
+	if item == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil, nil
+	}
+	run, err := a.aiBulk.FindRun(item.RunID)
+	if err != nil || run == nil || !canSeeBulkRun(middleware.SessionFromCtx(r.Context()), run) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil, nil
+	}
+	return run, item
+}
+
+// AIBulkItemAccept accepts one proposed change and re-renders the review
+// screen (the row swaps itself in with hx-select).
+func (a *Admin) AIBulkItemAccept(w http.ResponseWriter, r *http.Request) {
+	run, item := a.findBulkItem(w, r)
+	if item == nil {
+		return
+	}
+	sess := middleware.SessionFromCtx(r.Context())
+
+	var errMsg string
+	if err := a.acceptBulkItem(r.Context(), run, item, sess.UserID); err != nil {
+		errMsg = "The change could not be applied."
+		if errors.Is(err, errBulkNotProposed) {
+			errMsg = "This item is no longer waiting for review."
+		}
+	}
+	a.renderBulkRun(w, r, run, "", errMsg)
+}
+
+// AIBulkItemReject discards one proposed change.
+func (a *Admin) AIBulkItemReject(w http.ResponseWriter, r *http.Request) {
+	run, item := a.findBulkItem(w, r)
+	if item == nil {
+		return
+	}
+	if _, err := a.aiBulk.Decide(item.ID, models.BulkItemRejected); err != nil {
+		slog.Error("reject bulk item failed", "item", item.ID, "error", err)
+	}
+	a.renderBulkRun(w, r, run, "", "")
+}
+
+// AIBulkAcceptAll accepts every proposed change in a run.
+func (a *Admin) AIBulkAcceptAll(w http.ResponseWriter, r *http.Request) {
+	run := a.findBulkRun(w, r)
+	if run == nil {
+		return
+	}
+	sess := middleware.SessionFromCtx(r.Context())
+
+	items, err := a.aiBulk.Items(run.ID)
+	if err != nil {
+		slog.Error("list bulk items failed", "run", run.ID, "error", err)
+		a.renderBulkRun(w, r, run, "", "Failed to load items.")
+		return
+	}
+	accepted, failed := 0, 0
+	for i := range items {
+		if items[i].Status != models.BulkItemProposed {
+			continue
+		}
+		if err := a.acceptBulkItem(r.Context(), run, &items[i], sess.UserID); err != nil {
+			if !errors.Is(err, errBulkNotProposed) {
+				failed++
+			}
+			continue
+		}
+		accepted++
+	}
+
+	var errMsg string
+	if failed > 0 {
+		errMsg = fmt.Sprintf("%d changes could not be applied.", failed)
+	}
+	a.renderBulkRun(w, r, run, fmt.Sprintf("Accepted %d changes.", accepted), errMsg)
+}
+
+// AIBulkRejectAll discards every proposed change in a run.
+func (a *Admin) AIBulkRejectAll(w http.ResponseWriter, r *http.Request) {
+	run := a.findBulkRun(w, r)
+	if run == nil {
+		return
+	}
+	items, err := a.aiBulk.Items(run.ID)
+	if err != nil {
+		slog.Error("list bulk items failed", "run", run.ID, "error", err)
+		a.renderBulkRun(w, r, run, "", "Failed to load items.")
+		return
+	}
+	rejected := 0
+	for _, item := range items {
+		if item.Status != models.BulkItemProposed {
+			continue
+		}
+		if ok, err := a.aiBulk.Decide(item.ID, models.BulkItemRejected); err != nil {
+			slog.Error("reject bulk item failed", "item", item.ID, "error", err)
+		} else if ok {
+			rejected++
+		}
+	}
+	a.renderBulkRun(w, r, run, fmt.Sprintf("Rejected %d changes.", rejected), "")
+}
+
+// AIBulkRetryFailed queues the failed items of a run again.
+func (a *Admin) AIBulkRetryFailed(w http.ResponseWriter, r *http.Request) {
+	run := a.findBulkRun(w, r)
+	if run == nil {
+		return
+	}
+	if a.jobs == nil {
+		a.renderBulkRun(w, r, run, "", "Background jobs are not configured.")
+		return
+	}
+	if msg := a.aiBudgetExceeded(r); msg != "" {
+		a.renderBulkRun(w, r, run, "", msg)
+		return
+	}
+	items, err := a.aiBulk.Items(run.ID)
+	if err != nil {
+		slog.Error("list bulk items failed", "run", run.ID, "error", err)
+		a.renderBulkRun(w, r, run, "", "Failed to load items.")
+		return
+	}
+	queued := 0
+	for _, item := range items {
+		if item.Status != models.BulkItemFailed {
+			continue
+		}
+		ok, err := a.aiBulk.Requeue(item.ID)
+		if err != nil {
+			slog.Error("requeue bulk item failed", "item", item.ID, "error", err)
+			continue
+		}
+		if ok {
+			a.enqueueBulkItem(r.Context(), item.ID)
+			queued++
+		}
+	}
+	a.renderBulkRun(w, r, run, fmt.Sprintf("Queued %d items again.", queued), "")
+}
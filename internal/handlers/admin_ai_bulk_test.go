@@ -0,0 +1,105 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+	"yaaicms/internal/session"
+)
+
+func TestNeedsBulk(t *testing.T) {
+	has := "set"
+	empty := &models.Content{Body: "  "}
+	full := &models.Content{Body: "text", Excerpt: &has, MetaDescription: &has}
+
+	tests := []struct {
+		name   string
+		action models.BulkAction
+		opts   bulkOptions
+		c      *models.Content
+		want   bool
+	}{
+		{"excerpt missing", models.BulkExcerpt, bulkOptions{}, empty, true},
+		{"excerpt present", models.BulkExcerpt, bulkOptions{}, full, false},
+		{"excerpt overwrite", models.BulkExcerpt, bulkOptions{Overwrite: true}, full, true},
+		{"seo missing", models.BulkSEO, bulkOptions{}, empty, true},
+		{"seo present", models.BulkSEO, bulkOptions{}, full, false},
+		{"tags always", models.BulkTags, bulkOptions{}, full, true},
+		{"rewrite empty body", models.BulkRewrite, bulkOptions{}, empty, false},
+		{"rewrite", models.BulkRewrite, bulkOptions{}, full, true},
+	}
+	for _, tt := range tests {
+		if got := needsBulk(tt.action, tt.opts, tt.c); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMergeKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		keywords string
+		tags     []string
+		maxLen   int
+		want     string
+	}{
+		{"empty", "", []string{"go", "web"}, 100, "go, web"},
+		{"dedupes case-insensitively", "Go, cms", []string{"go", "CMS", "htmx"}, 100, "Go, cms, htmx"},
+		{"cleans existing", " a ,, b ", nil, 100, "a, b"},
+		{"stops at the limit", "alpha", []string{"beta", "gamma"}, 11, "alpha, beta"},
+	}
+	for _, tt := range tests {
+		if got := mergeKeywords(tt.keywords, tt.tags, tt.maxLen); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseBulkForm(t *testing.T) {
+	cat := uuid.New()
+	form := url.Values{
+		"type": {"page"}, "status": {"archived"}, "category_id": {cat.String()},
+		"action": {"rewrite"}, "tone": {"shouty"}, "guidance": {"  British spelling "},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/admin/ai-bulk", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	f, action, opts := parseBulkForm(r)
+	if f.Type != models.ContentTypePage || f.Status != "" || f.CategoryID == nil || *f.CategoryID != cat {
+		t.Errorf("filter: got %+v", f)
+	}
+	if action != models.BulkRewrite {
+		t.Errorf("action: got %q", action)
+	}
+	if opts.Tone != "professional" || opts.Guidance != "British spelling" || opts.Overwrite {
+		t.Errorf("options: got %+v", opts)
+	}
+}
+
+func TestCanSeeBulkRun(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	run := &models.AIBulkRun{CreatedBy: &owner}
+
+	if !canSeeBulkRun(&session.Data{UserID: owner, Role: string(models.RoleEditor)}, run) {
+		t.Error("owner can't see their run")
+	}
+	if canSeeBulkRun(&session.Data{UserID: other, Role: string(models.RoleEditor)}, run) {
+		t.Error("another editor can see the run")
+	}
+	if !canSeeBulkRun(&session.Data{UserID: other, Role: string(models.RoleAdmin)}, run) {
+		t.Error("admin can't see the run")
+	}
+	if canSeeBulkRun(nil, run) {
+		t.Error("no session can see the run")
+	}
+}
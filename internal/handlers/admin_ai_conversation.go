@@ -0,0 +1,300 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_conversation.go keeps the template builder's and content
+// assistant's conversations, and serves the endpoints the AI Design page
+// uses to list, resume, fork and delete them.
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/engine"
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+	"yaaicms/internal/store"
+)
+
+const (
+	// conversationHistoryChars caps the history sent with each turn. The
+	// oldest turns are dropped first.
+	conversationHistoryChars = 24000
+
+	// conversationListLimit is the number of conversations listed.
+	conversationListLimit = 50
+
+	// conversationTitleLen is the length of a title taken from the first prompt.
+	conversationTitleLen = 80
+)
+
+// errConversationNotFound is returned for a conversation that doesn't
+// exist or belongs to another user or assistant; the two aren't told apart.
+var errConversationNotFound = errors.New("conversation not found")
+
+// conversationResponse is a conversation with its messages, as returned
+// when one is opened.
+type conversationResponse struct {
+	*models.AIConversation
+	Messages []models.AIConversationMessage `json:"messages"`
+
+	// SafetyIssues are those of CurrentHTML, for template conversations.
+	SafetyIssues []engine.SafetyIssue `json:"safety_issues,omitempty"`
+}
+
+// SetAIConversations enables saved assistant conversations. Without it
+// every assistant message starts a new conversation.
+func (a *Admin) SetAIConversations(s *store.AIConversationStore) {
+	a.aiConversations = s
+}
+
+// findConversation returns the caller's conversation with the given ID
+// and kind, or errConversationNotFound.
+func (a *Admin) findConversation(r *http.Request, id uuid.UUID, kind models.ConversationKind) (*models.AIConversation, error) {
+	sess := middleware.SessionFromCtx(r.Context())
+	if sess == nil {
+		return nil, errConversationNotFound
+	}
+	conv, err := a.aiConversations.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil || conv.UserID != sess.UserID || (kind != "" && conv.Kind != kind) {
+		return nil, errConversationNotFound
+	}
+	return conv, nil
+}
+
+// loadConversation returns the conversation named by the request's
+// conversation_id form value and its messages. Both are nil when no ID is
+// given or conversations aren't stored, i.e. the turn starts a new one.
+func (a *Admin) loadConversation(r *http.Request, kind models.ConversationKind) (*models.AIConversation, []models.AIConversationMessage, error) {
+	idStr := r.FormValue("conversation_id")
+	if a.aiConversations == nil || idStr == "" {
+		return nil, nil, nil
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, nil, errConversationNotFound
+	}
+	conv, err := a.findConversation(r, id, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	msgs, err := a.aiConversations.Messages(conv.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conv, msgs, nil
+}
+
+// chatMessages builds the messages for the next turn: the system prompt,
+// the most recent history that fits in conversationHistoryChars, and the
+// new user message. It also returns how many history messages were kept.
+func chatMessages(systemPrompt string, history []models.AIConversationMessage, userMsg string) ([]ai.Message, int) {
+	start, size := len(history), 0
+	for start > 0 && size+len(history[start-1].Content) <= conversationHistoryChars {
+		size += len(history[start-1].Content)
+		start--
+	}
+	// Claude and Gemini reject a conversation that opens with the assistant.
+	for start < len(history) && history[start].Role != string(ai.RoleUser) {
+		start++
+	}
+
+	msgs := []ai.Message{{Role: ai.RoleSystem, Content: systemPrompt}}
+	for _, m := range history[start:] {
+		msgs = append(msgs, ai.Message{Role: ai.Role(m.Role), Content: m.Content})
+	}
+	msgs = append(msgs, ai.Message{Role: ai.RoleUser, Content: userMsg})
+	return msgs, len(history) - start
+}
+
+// saveTurn records a completed turn, creating the conversation on its
+// first turn, and returns the conversation ID. Failures are logged and
+// return "": the user still gets the result, just not the history.
+func (a *Admin) saveTurn(r *http.Request, conv *models.AIConversation, kind models.ConversationKind, target, prompt, reply, currentHTML string) string {
+	if a.aiConversations == nil {
+		return ""
+	}
+	if conv == nil {
+		sess := middleware.SessionFromCtx(r.Context())
+		if sess == nil {
+			return ""
+		}
+		var err error
+		conv, err = a.aiConversations.Create(&models.AIConversation{
+			UserID: sess.UserID,
+			Kind:   kind,
+			Title:  conversationTitle(prompt),
+			Target: target,
+		})
+		if err != nil {
+			slog.Error("create ai conversation failed", "error", err)
+			return ""
+		}
+	}
+	if err := a.aiConversations.AppendTurn(conv.ID, prompt, reply, currentHTML); err != nil {
+		slog.Error("save ai conversation turn failed", "conversation_id", conv.ID, "error", err)
+		return ""
+	}
+	return conv.ID.String()
+}
+
+// conversationTitle derives a title from the first prompt of a
+// conversation: its first line, shortened on a rune boundary.
+func conversationTitle(prompt string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	title = strings.TrimSpace(title)
+	if runes := []rune(title); len(runes) > conversationTitleLen {
+		title = string(runes[:conversationTitleLen]) + "..."
+	}
+	return title
+}
+
+// AIConversationList returns the caller's conversations as JSON, most
+// recent first. The kind query parameter selects "template" (default) or
+// "content" conversations.
+func (a *Admin) AIConversationList(w http.ResponseWriter, r *http.Request) {
+	sess := middleware.SessionFromCtx(r.Context())
+	if a.aiConversations == nil || sess == nil {
+		writeJSON(w, http.StatusOK, []models.AIConversation{})
+		return
+	}
+
+	kind := models.ConversationKind(r.URL.Query().Get("kind"))
+	if kind != models.ConversationContent {
+		kind = models.ConversationTemplate
+	}
+
+	items, err := a.aiConversations.ListByUser(sess.UserID, kind, conversationListLimit)
+	if err != nil {
+		slog.Error("list ai conversations failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to list conversations."})
+		return
+	}
+	if items == nil {
+		items = []models.AIConversation{}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// AIConversationGet returns one of the caller's conversations with its
+// messages, for resuming it.
+func (a *Admin) AIConversationGet(w http.ResponseWriter, r *http.Request) {
+	conv, ok := a.conversationFromURL(w, r)
+	if !ok {
+		return
+	}
+	msgs, err := a.aiConversations.Messages(conv.ID)
+	if err != nil {
+		slog.Error("load ai conversation messages failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to load conversation."})
+		return
+	}
+	if msgs == nil {
+		msgs = []models.AIConversationMessage{}
+	}
+	writeJSON(w, http.StatusOK, conversationResponse{
+		AIConversation: conv,
+		Messages:       msgs,
+		SafetyIssues:   engine.ScanTemplate(conv.CurrentHTML),
+	})
+}
+
+// AIConversationFork copies a conversation into a new one, up to and
+// including the message at the "position" form value (the whole
+// conversation if it is empty), and returns the copy.
+func (a *Admin) AIConversationFork(w http.ResponseWriter, r *http.Request) {
+	conv, ok := a.conversationFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	position := conv.MessageCount
+	if v := r.FormValue("position"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid message position."})
+			return
+		}
+		position = n
+	}
+
+	// A template fork starts from the template of the last reply it keeps.
+	msgs, err := a.aiConversations.Messages(conv.ID)
+	if err != nil {
+		slog.Error("load ai conversation messages failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to fork conversation."})
+		return
+	}
+	fork, err := a.aiConversations.Fork(conv.ID, position, forkHTML(conv, msgs, position))
+	if err != nil {
+		slog.Error("fork ai conversation failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to fork conversation."})
+		return
+	}
+	writeJSON(w, http.StatusOK, fork)
+}
+
+// forkHTML returns the template a fork up to position starts from: the
+// HTML in the last assistant message it keeps. Content conversations have
+// no template.
+func forkHTML(conv *models.AIConversation, msgs []models.AIConversationMessage, position int) string {
+	if conv.Kind != models.ConversationTemplate {
+		return ""
+	}
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Position <= position && msgs[i].Role == string(ai.RoleAssistant) {
+			return extractHTMLFromResponse(msgs[i].Content)
+		}
+	}
+	return ""
+}
+
+// AIConversationDelete deletes one of the caller's conversations.
+func (a *Admin) AIConversationDelete(w http.ResponseWriter, r *http.Request) {
+	conv, ok := a.conversationFromURL(w, r)
+	if !ok {
+		return
+	}
+	if err := a.aiConversations.Delete(conv.ID); err != nil {
+		slog.Error("delete ai conversation failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to delete conversation."})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// conversationFromURL loads the caller's conversation named by the {id}
+// URL parameter, writing a JSON error and returning false if it can't.
+func (a *Admin) conversationFromURL(w http.ResponseWriter, r *http.Request) (*models.AIConversation, bool) {
+	if a.aiConversations == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Conversation not found."})
+		return nil, false
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid conversation ID."})
+		return nil, false
+	}
+	conv, err := a.findConversation(r, id, "")
+	if errors.Is(err, errConversationNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Conversation not found."})
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("load ai conversation failed", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to load conversation."})
+		return nil, false
+	}
+	return conv, true
+}
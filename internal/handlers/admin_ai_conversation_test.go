@@ -0,0 +1,120 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/models"
+)
+
+func TestChatMessages(t *testing.T) {
+	history := []models.AIConversationMessage{
+		{Position: 1, Role: "user", Content: "A dark header"},
+		{Position: 2, Role: "assistant", Content: "<header>v1</header>"},
+	}
+
+	msgs, kept := chatMessages("sys", history, "Add a logo")
+	if kept != 2 || len(msgs) != 4 {
+		t.Fatalf("got %d messages, kept %d", len(msgs), kept)
+	}
+	if msgs[0].Role != ai.RoleSystem || msgs[2].Role != ai.RoleAssistant || msgs[3].Content != "Add a logo" {
+		t.Errorf("messages: got %+v", msgs)
+	}
+
+	t.Run("drops the oldest turns", func(t *testing.T) {
+		long := []models.AIConversationMessage{
+			{Position: 1, Role: "user", Content: "first"},
+			{Position: 2, Role: "assistant", Content: strings.Repeat("x", conversationHistoryChars)},
+			{Position: 3, Role: "user", Content: "second"},
+			{Position: 4, Role: "assistant", Content: "short"},
+		}
+		msgs, kept := chatMessages("sys", long, "third")
+		if kept != 2 {
+			t.Fatalf("kept: got %d, want 2", kept)
+		}
+		if msgs[1].Content != "second" {
+			t.Errorf("history should start at the last user turn, got %+v", msgs[1])
+		}
+	})
+
+	t.Run("never opens with the assistant", func(t *testing.T) {
+		long := []models.AIConversationMessage{
+			{Position: 1, Role: "user", Content: strings.Repeat("x", conversationHistoryChars)},
+			{Position: 2, Role: "assistant", Content: "reply"},
+		}
+		msgs, kept := chatMessages("sys", long, "next")
+		if kept != 0 || len(msgs) != 2 {
+			t.Errorf("got %d messages, kept %d", len(msgs), kept)
+		}
+	})
+}
+
+func TestConversationTitle(t *testing.T) {
+	if got := conversationTitle("  Dark header\nwith a logo"); got != "Dark header" {
+		t.Errorf("got %q", got)
+	}
+	long := strings.Repeat("é", conversationTitleLen+5)
+	got := conversationTitle(long)
+	if got != strings.Repeat("é", conversationTitleLen)+"..." {
+		t.Errorf("should cut on a rune boundary, got %q", got)
+	}
+}
+
+func TestForkHTML(t *testing.T) {
+	conv := &models.AIConversation{Kind: models.ConversationTemplate}
+	msgs := []models.AIConversationMessage{
+		{Position: 1, Role: "user", Content: "A header"},
+		{Position: 2, Role: "assistant", Content: "```html\n<header>v1</header>\n```"},
+		{Position: 3, Role: "user", Content: "Darker"},
+		{Position: 4, Role: "assistant", Content: "<header>v2</header>"},
+	}
+
+	if got := forkHTML(conv, msgs, 3); got != "<header>v1</header>" {
+		t.Errorf("fork at 3: got %q", got)
+	}
+	if got := forkHTML(conv, msgs, 4); got != "<header>v2</header>" {
+		t.Errorf("fork at 4: got %q", got)
+	}
+	if got := forkHTML(&models.AIConversation{Kind: models.ConversationContent}, msgs, 4); got != "" {
+		t.Errorf("content fork: got %q", got)
+	}
+}
+
+func TestConversationMarker(t *testing.T) {
+	if conversationMarker("") != "" {
+		t.Error("no ID should give no marker")
+	}
+	got := conversationMarker("0b6e2f5c-1111-2222-3333-444455556666")
+	if !strings.Contains(got, `data-ai-conversation value="0b6e2f5c-1111-2222-3333-444455556666"`) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestStreamAIChat_SendsMessages(t *testing.T) {
+	p := &mockAIProvider{name: "test", response: "Hello world"}
+	a := newStreamAdmin(p)
+
+	msgs := []ai.Message{
+		{Role: ai.RoleSystem, Content: "sys"},
+		{Role: ai.RoleUser, Content: "hi"},
+		{Role: ai.RoleAssistant, Content: "hello"},
+		{Role: ai.RoleUser, Content: "again"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-template", nil)
+	rec := httptest.NewRecorder()
+	a.streamAIChat(rec, req, ai.TaskTemplate, msgs, func(result string) any { return result })
+
+	if len(p.lastChat) != 4 || p.lastChat[2].Role != ai.RoleAssistant {
+		t.Errorf("provider got %+v", p.lastChat)
+	}
+	if !strings.HasSuffix(rec.Body.String(), "event: done\ndata: \"Hello world\"\n\n") {
+		t.Errorf("body: got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/engine"
+)
+
+// newFakeAdmin returns an Admin whose AI is the fake provider.
+func newFakeAdmin() *Admin {
+	reg := ai.NewRegistry(ai.FakeName, nil)
+	fake := ai.NewFake(ai.FakeOptions{})
+	reg.Register(ai.FakeName, fake)
+	reg.SetModerator(fake.Moderator())
+	return &Admin{aiRegistry: reg, engine: engine.New(nil)}
+}
+
+func TestFakeProviderTemplates(t *testing.T) {
+	a := newFakeAdmin()
+	for _, typ := range []string{"header", "footer", "page", "article_loop", "search"} {
+		rec := httptest.NewRecorder()
+		a.AITemplateGenerate(rec, postForm(url.Values{"prompt": {"A calm look"}, "template_type": {typ}, "restyle": {"1"}}))
+
+		var resp templateGenResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if !resp.Valid || resp.Error != "" || len(resp.SafetyIssues) > 0 || resp.Preview == "" {
+			t.Errorf("%s: valid %v, error %q, issues %v, preview %d bytes, validation %q",
+				typ, resp.Valid, resp.Error, resp.SafetyIssues, len(resp.Preview), resp.ValidationError)
+		}
+	}
+}
+
+func TestFakeProviderContentAssistant(t *testing.T) {
+	a := newFakeAdmin()
+
+	rec := httptest.NewRecorder()
+	a.AISuggestTitle(rec, postForm(url.Values{"title": {"Balcony tomatoes"}, "body": {"Grow them in big pots."}}))
+	if got := strings.Count(rec.Body.String(), "<button"); got != 5 || !strings.Contains(rec.Body.String(), "Balcony tomatoes: A Beginner&#39;s Guide") {
+		t.Errorf("titles: %d buttons in %s", got, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.AISuggestTitle(rec, postForm(url.Values{"title": {"Something " + ai.FakeFlagWord}}))
+	if !strings.Contains(rec.Body.String(), "flagged") {
+		t.Errorf("flag word not blocked: %s", rec.Body.String())
+	}
+}
@@ -13,6 +13,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
 	"yaaicms/internal/models"
 )
 
@@ -45,7 +48,7 @@ func TestAISuggestTitle_EmptyInput_ReturnsError(t *testing.T) {
 
 func TestAISuggestTitle_Success(t *testing.T) {
 	env := newTestEnv(t)
-	setMockAIResponse(env, "1. Great Title One\n2. Another Title\n3. Third Option", nil)
+	setMockAIResponse(env, `{"titles":["Great Title One","Another Title","Third Option"]}`, nil)
 
 	form := url.Values{}
 	form.Set("body", "This is content about testing.")
@@ -58,13 +61,29 @@ func TestAISuggestTitle_Success(t *testing.T) {
 
 	body := rec.Body.String()
 	if !strings.Contains(body, "Great Title One") {
-		t.Error("expected parsed title in response")
+		t.Error("expected title in response")
 	}
 	if !strings.Contains(body, "button") {
 		t.Error("expected clickable buttons in response")
 	}
 }
 
+func TestReportAIProvider(t *testing.T) {
+	rec := httptest.NewRecorder()
+	reportAIProvider(rec, ai.Result{
+		Usage:        ai.Usage{Provider: "openai", Model: "gpt-4o-mini"},
+		FallbackFrom: "claude",
+	})
+
+	if got := rec.Header().Get("X-AI-Provider"); got != "openai" {
+		t.Errorf("X-AI-Provider: got %q, want %q", got, "openai")
+	}
+	want := `{"ai-provider":{"provider":"openai","model":"gpt-4o-mini","fallbackFrom":"claude"}}`
+	if got := rec.Header().Get("HX-Trigger"); got != want {
+		t.Errorf("HX-Trigger: got %q, want %q", got, want)
+	}
+}
+
 func TestAISuggestTitle_AIError(t *testing.T) {
 	env := newTestEnv(t)
 	setMockAIResponse(env, "", fmt.Errorf("provider unreachable"))
@@ -83,10 +102,10 @@ func TestAISuggestTitle_AIError(t *testing.T) {
 	}
 }
 
-func TestAISuggestTitle_UnparsedResponse(t *testing.T) {
+func TestAISuggestTitle_InvalidReply(t *testing.T) {
 	env := newTestEnv(t)
-	// A response with no numbered list — should fall through to writeAIResult.
-	setMockAIResponse(env, "", nil)
+	// A reply that isn't JSON fails validation, also after the repair.
+	setMockAIResponse(env, "1. Great Title One", nil)
 
 	form := url.Values{}
 	form.Set("body", "content")
@@ -99,6 +118,9 @@ func TestAISuggestTitle_UnparsedResponse(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Errorf("status: got %d, want 200", rec.Code)
 	}
+	if !strings.Contains(rec.Body.String(), "AI request failed") {
+		t.Errorf("expected error message, got: %s", rec.Body.String())
+	}
 }
 
 // --- AIGenerateExcerpt ---
@@ -121,7 +143,7 @@ func TestAIGenerateExcerpt_EmptyBody_ReturnsError(t *testing.T) {
 
 func TestAIGenerateExcerpt_Success(t *testing.T) {
 	env := newTestEnv(t)
-	setMockAIResponse(env, "This is a compelling excerpt about the article.", nil)
+	setMockAIResponse(env, `{"excerpt":"This is a compelling excerpt about the article."}`, nil)
 
 	form := url.Values{}
 	form.Set("body", "Long article body content here.")
@@ -177,7 +199,7 @@ func TestAISEOMetadata_EmptyInput_ReturnsError(t *testing.T) {
 
 func TestAISEOMetadata_Success(t *testing.T) {
 	env := newTestEnv(t)
-	setMockAIResponse(env, "DESCRIPTION: A great Go tutorial for beginners.\nKEYWORDS: go, programming, tutorial, beginner", nil)
+	setMockAIResponse(env, `{"description":"A great Go tutorial for beginners.","keywords":["go","programming","tutorial","beginner"]}`, nil)
 
 	form := url.Values{}
 	form.Set("body", "Article about learning Go.")
@@ -195,11 +217,14 @@ func TestAISEOMetadata_Success(t *testing.T) {
 	if !strings.Contains(body, "Apply Keywords") {
 		t.Error("expected Apply Keywords button")
 	}
+	if !strings.Contains(body, "go, programming, tutorial, beginner") {
+		t.Error("expected keywords joined with commas")
+	}
 }
 
-func TestAISEOMetadata_UnparsedFallback(t *testing.T) {
+func TestAISEOMetadata_InvalidReply(t *testing.T) {
 	env := newTestEnv(t)
-	setMockAIResponse(env, "Some random response without structure", nil)
+	setMockAIResponse(env, "DESCRIPTION: not JSON", nil)
 
 	form := url.Values{}
 	form.Set("body", "content")
@@ -209,10 +234,12 @@ func TestAISEOMetadata_UnparsedFallback(t *testing.T) {
 	rec := httptest.NewRecorder()
 	env.Admin.AISEOMetadata(rec, req)
 
-	// When parsing fails, writeAIResult is called as fallback.
 	if rec.Code != http.StatusOK {
 		t.Errorf("status: got %d, want 200", rec.Code)
 	}
+	if !strings.Contains(rec.Body.String(), "AI request failed") {
+		t.Error("expected AI error message")
+	}
 }
 
 func TestAISEOMetadata_AIError(t *testing.T) {
@@ -328,7 +355,7 @@ func TestAIExtractTags_EmptyInput_ReturnsError(t *testing.T) {
 
 func TestAIExtractTags_Success(t *testing.T) {
 	env := newTestEnv(t)
-	setMockAIResponse(env, "go, testing, web development, api design", nil)
+	setMockAIResponse(env, `{"tags":["go","testing","web development","api design"]}`, nil)
 
 	form := url.Values{}
 	form.Set("body", "Article about Go web APIs.")
@@ -340,8 +367,8 @@ func TestAIExtractTags_Success(t *testing.T) {
 	env.Admin.AIExtractTags(rec, req)
 
 	body := rec.Body.String()
-	if !strings.Contains(body, "go") {
-		t.Error("expected parsed tags in response")
+	if !strings.Contains(body, "web development") {
+		t.Error("expected tags in response")
 	}
 	if !strings.Contains(body, "Click tags") {
 		t.Error("expected instruction text")
@@ -428,6 +455,42 @@ func TestAITemplateGenerate_ValidPrompt_ReturnsJSON(t *testing.T) {
 	}
 }
 
+func TestAITemplateGenerate_Stream(t *testing.T) {
+	env := newTestEnv(t)
+	setMockAIResponse(env, `<header class="bg-white p-4"><nav>{{.SiteName}}</nav></header>`, nil)
+
+	form := url.Values{}
+	form.Set("prompt", "Create a simple header with nav")
+	form.Set("template_type", "header")
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-template", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "text/event-stream")
+
+	rec := httptest.NewRecorder()
+	env.Admin.AITemplateGenerate(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type: got %q, want text/event-stream", ct)
+	}
+
+	body := rec.Body.String()
+	if strings.Count(body, "event: delta\n") != 2 {
+		t.Errorf("expected 2 delta events, got body: %s", body)
+	}
+
+	_, done, ok := strings.Cut(body, "event: done\ndata: ")
+	if !ok {
+		t.Fatalf("expected a done event, got body: %s", body)
+	}
+	var resp templateGenResponse
+	if err := json.Unmarshal([]byte(strings.TrimSpace(done)), &resp); err != nil {
+		t.Fatalf("decode done event: %v", err)
+	}
+	if !resp.Valid || resp.HTML == "" {
+		t.Errorf("expected a valid template in the done event, got %+v", resp)
+	}
+}
+
 func TestAITemplateGenerate_InvalidSyntax(t *testing.T) {
 	env := newTestEnv(t)
 	setMockAIResponse(env, `<header>{{.Broken</header>`, nil)
@@ -482,6 +545,7 @@ func TestAITemplateGenerate_WithChatHistory(t *testing.T) {
 	form.Set("prompt", "Now add a logo")
 	form.Set("template_type", "header")
 	form.Set("chat_history", "User: Create a header\nAI: Done")
+	form.Set("restyle", "1")
 	req := httptest.NewRequest(http.MethodPost, "/admin/ai/template-generate", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
@@ -491,6 +555,81 @@ func TestAITemplateGenerate_WithChatHistory(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Fatalf("status: got %d, want 200", rec.Code)
 	}
+	var resp templateGenResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.ConversationID != "" {
+		t.Error("restyle requests should not be saved as a conversation")
+	}
+}
+
+func TestAITemplateGenerate_ContinuesConversation(t *testing.T) {
+	env := newTestEnv(t)
+	mock := &mockAIProvider{name: "test", response: `<header>{{.SiteName}}</header>`}
+	env.AIRegistry.Register("test", mock)
+
+	email := "test-aiconv@handler-test.local"
+	user, err := env.UserStore.Create(email, "testpass123", "Conversation User", models.RoleAuthor)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { env.DB.Exec("DELETE FROM users WHERE email = $1", email) })
+	sess := testSession(user.ID, email, "author", true)
+
+	generate := func(prompt, conversationID, currentHTML string) templateGenResponse {
+		form := url.Values{}
+		form.Set("prompt", prompt)
+		form.Set("template_type", "header")
+		form.Set("conversation_id", conversationID)
+		form.Set("current_html", currentHTML)
+		req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-template", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(ctxWithSession(req.Context(), sess))
+		rec := httptest.NewRecorder()
+		env.Admin.AITemplateGenerate(rec, req)
+
+		var resp templateGenResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return resp
+	}
+
+	first := generate("Create a header", "", "")
+	if first.ConversationID == "" {
+		t.Fatalf("expected a conversation ID, got %+v", first)
+	}
+
+	second := generate("Add a logo", first.ConversationID, first.HTML)
+	if second.ConversationID != first.ConversationID {
+		t.Errorf("conversation ID: got %q, want %q", second.ConversationID, first.ConversationID)
+	}
+	// system, first request, first reply, new request
+	if len(mock.lastChat) != 4 || mock.lastChat[2].Role != ai.RoleAssistant {
+		t.Fatalf("messages sent: got %+v", mock.lastChat)
+	}
+	if strings.Contains(mock.lastChat[3].Content, "Current template HTML") {
+		t.Error("unchanged HTML is already in the history and should not be resent")
+	}
+
+	conv, err := env.AIChats.FindByID(uuid.MustParse(first.ConversationID))
+	if err != nil || conv == nil {
+		t.Fatalf("FindByID: %v, %v", conv, err)
+	}
+	if conv.MessageCount != 4 || conv.Title != "Create a header" || conv.Target != "header" {
+		t.Errorf("conversation: got %+v", conv)
+	}
+
+	// Another user can't continue it.
+	other := testSession(uuid.New(), "someone@handler-test.local", "author", true)
+	form := url.Values{"prompt": {"Hijack"}, "template_type": {"header"}, "conversation_id": {first.ConversationID}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-template", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(ctxWithSession(req.Context(), other))
+	rec := httptest.NewRecorder()
+	env.Admin.AITemplateGenerate(rec, req)
+	var resp templateGenResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Error == "" {
+		t.Error("expected an error for someone else's conversation")
+	}
 }
 
 func TestAITemplateGenerate_AIError(t *testing.T) {
@@ -578,3 +717,69 @@ func TestAITemplateSave_ValidTemplate(t *testing.T) {
 		t.Error("expected template ID in response")
 	}
 }
+
+// --- AI budgets ---
+
+func TestAISuggestTitle_OverBudget(t *testing.T) {
+	env := newTestEnv(t)
+	setMockAIResponse(env, "1. Should Not Appear", nil)
+
+	email := "test-overbudget@handler-test.local"
+	user, err := env.UserStore.Create(email, "testpass123", "Budget User", models.RoleAuthor)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { env.DB.Exec("DELETE FROM users WHERE email = $1", email) })
+
+	zero := int64(0)
+	if err := env.AIBudgets.SaveUser(user.ID, &models.AIBudget{DailyTokens: &zero}); err != nil {
+		t.Fatalf("SaveUser: %v", err)
+	}
+
+	form := url.Values{}
+	form.Set("body", "content")
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/suggest-title", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(ctxWithSession(req.Context(), testSession(user.ID, email, "author", true)))
+
+	rec := httptest.NewRecorder()
+	env.Admin.AISuggestTitle(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "daily token budget") {
+		t.Errorf("expected budget message, got: %s", body)
+	}
+	if strings.Contains(body, "Should Not Appear") {
+		t.Error("AI should not be called when over budget")
+	}
+}
+
+func TestParseAIBudgetForm(t *testing.T) {
+	form := url.Values{}
+	form.Set("daily_tokens", "5000")
+	form.Set("monthly_cost_usd", "$12.50")
+	req := httptest.NewRequest(http.MethodPost, "/admin/settings/ai-budgets", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	b, msg := parseAIBudgetForm(req)
+	if msg != "" {
+		t.Fatalf("unexpected validation error: %s", msg)
+	}
+	if b.DailyTokens == nil || *b.DailyTokens != 5000 {
+		t.Errorf("daily tokens: got %v", b.DailyTokens)
+	}
+	if b.MonthlyCostUSD == nil || *b.MonthlyCostUSD != 12.5 {
+		t.Errorf("monthly cost: got %v", b.MonthlyCostUSD)
+	}
+	if b.DailyCostUSD != nil || b.MonthlyTokens != nil {
+		t.Error("blank fields should be unlimited")
+	}
+
+	for _, bad := range []url.Values{{"daily_tokens": {"-1"}}, {"monthly_tokens": {"1.5"}}, {"daily_cost_usd": {"abc"}}} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/settings/ai-budgets", strings.NewReader(bad.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if _, msg := parseAIBudgetForm(req); msg == "" {
+			t.Errorf("expected validation error for %v", bad)
+		}
+	}
+}
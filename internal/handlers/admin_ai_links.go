@@ -0,0 +1,308 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_links.go suggests internal links for the post or page in the
+// editor and inserts the ones the editor accepts.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/links"
+	"yaaicms/internal/models"
+	"yaaicms/internal/prompts"
+	"yaaicms/internal/store"
+)
+
+// maxLinkCandidates caps the pages the AI is offered as link targets.
+const maxLinkCandidates = 12
+
+// linkContextRadius is the number of bytes of text shown on each side
+// of a suggested anchor.
+const linkContextRadius = 60
+
+// linkPicks is the structured reply for internal link suggestions.
+type linkPicks struct {
+	Links []linkPick `json:"links"`
+}
+
+type linkPick struct {
+	Page   int    `json:"page" desc:"Number of the page to link to, from the list"`
+	Anchor string `json:"anchor" desc:"2-6 words copied exactly from the content"`
+}
+
+var linkPicksSchema = ai.MustSchema("internal_links", linkPicks{})
+
+// AIInternalLinks suggests links from the body in the editor to other
+// published content. Titles and keywords of other content found in the
+// body are suggested as they are; the AI adds anchors for the most
+// similar content. Returns an HTML fragment with one Insert button per
+// suggestion.
+func (a *Admin) AIInternalLinks(w http.ResponseWriter, r *http.Request) {
+	c := contentFromForm(r)
+	c.ID, _ = uuid.Parse(r.FormValue("id"))
+	if strings.TrimSpace(c.Body) == "" {
+		writeAIError(w, "Please write some content first so links can be suggested.")
+		return
+	}
+	if !a.checkPromptSafety(w, r, truncate(c.Title+" "+c.Body, 3000)) {
+		return
+	}
+	if !a.checkAIBudget(w, r) {
+		return
+	}
+
+	targets, err := a.linkTargets(c.ID)
+	if err != nil {
+		slog.Error("list link targets failed", "error", err)
+		writeAIError(w, "Failed to load content to link to.")
+		return
+	}
+	if len(targets) == 0 {
+		writeAIError(w, "There is no other published content to link to yet.")
+		return
+	}
+
+	body := links.Parse(c.Body, c.BodyFormat)
+	var notice string
+	picks, res, err := a.pickLinks(r.Context(), c, body, targets)
+	switch {
+	case err != nil:
+		slog.Warn("ai link suggestions failed, using title matches only", "error", err)
+		notice = "AI suggestions are unavailable right now; showing title and keyword matches only."
+	case res.Usage.Provider != "":
+		reportAIProvider(w, res)
+	}
+
+	suggestions := links.Suggest(body, targets, picks)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(linkSuggestionsFragment(body, c.ID, suggestions, notice)))
+}
+
+// linkTargets returns every published post and page in the default
+// language except the content being edited, with its title and keywords
+// as phrases to look for.
+func (a *Admin) linkTargets(self uuid.UUID) ([]links.Target, error) {
+	items, err := a.contentStore.ListFiltered(store.ContentFilter{Status: models.ContentStatusPublished, DefaultLanguage: true})
+	if err != nil {
+		return nil, err
+	}
+	var targets []links.Target
+	for _, item := range items {
+		if item.ID == self {
+			continue
+		}
+		phrases := []string{item.Title}
+		for _, kw := range strings.Split(ptrStr(item.MetaKeywords), ",") {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				phrases = append(phrases, kw)
+			}
+		}
+		targets = append(targets, links.Target{ID: item.ID, Title: item.Title, URL: "/" + item.Slug, Phrases: phrases})
+	}
+	return targets, nil
+}
+
+// linkCandidates returns the targets most related to c: by embedding
+// similarity when the search index is enabled, or else (or when that
+// finds nothing) by a keyword search for its title and keywords. Targets
+// the body already links to are left out.
+func (a *Admin) linkCandidates(ctx context.Context, c *models.Content, body *links.Body, targets []links.Target) []links.Target {
+	var related []models.Content
+	if a.search != nil {
+		var err error
+		if related, err = a.search.Similar(ctx, c, maxLinkCandidates); err != nil {
+			slog.Warn("similar content lookup failed, using keyword search", "error", err)
+			related = nil
+		}
+	}
+	if related == nil {
+		terms := []string{c.Title}
+		for _, kw := range strings.Split(ptrStr(c.MetaKeywords), ",") {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				terms = append(terms, kw)
+			}
+		}
+		res, err := a.contentStore.Search(strings.Join(terms, " or "),
+			store.ContentFilter{Status: models.ContentStatusPublished, DefaultLanguage: true},
+			store.Page{Number: 1, Size: maxLinkCandidates + 1})
+		if err != nil {
+			slog.Warn("keyword search for link candidates failed", "error", err)
+		}
+		for _, hit := range res.Hits {
+			related = append(related, hit.Content)
+		}
+	}
+
+	byID := make(map[uuid.UUID]links.Target, len(targets))
+	for _, t := range targets {
+		byID[t.ID] = t
+	}
+	var out []links.Target
+	for _, item := range related {
+		if t, ok := byID[item.ID]; ok && !body.LinksTo(t.URL) && len(out) < maxLinkCandidates {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// pickLinks asks the AI for anchors in the body linking to the most
+// related targets. With no related targets it returns no picks without
+// calling the AI.
+func (a *Admin) pickLinks(ctx context.Context, c *models.Content, body *links.Body, targets []links.Target) ([]links.Pick, ai.Result, error) {
+	candidates := a.linkCandidates(ctx, c, body, targets)
+	if len(candidates) == 0 {
+		return nil, ai.Result{}, nil
+	}
+
+	found, err := a.contentStore.ListByIDs(targetIDs(candidates))
+	if err != nil {
+		return nil, ai.Result{}, err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Title: %s\n\nContent:\n%s\n\nPages on this site:\n", c.Title, truncate(c.Body, 6000))
+	for i, t := range candidates {
+		summary := ""
+		if item := found[t.ID]; item != nil {
+			summary = ptrStr(item.Excerpt)
+			if summary == "" {
+				summary = ptrStr(item.MetaDescription)
+			}
+		}
+		fmt.Fprintf(&sb, "%d. %s (%s)", i+1, t.Title, t.URL)
+		if summary != "" {
+			fmt.Fprintf(&sb, ": %s", truncate(summary, 200))
+		}
+		sb.WriteString("\n")
+	}
+
+	var out linkPicks
+	res, err := a.aiRegistry.GenerateJSON(ctx, ai.TaskLight, linkPicksSchema,
+		a.systemPrompt(prompts.ContentLinks, nil), sb.String(), &out)
+	if err != nil {
+		return nil, res, err
+	}
+	var picks []links.Pick
+	for _, p := range out.Links {
+		if p.Page >= 1 && p.Page <= len(candidates) {
+			picks = append(picks, links.Pick{TargetID: candidates[p.Page-1].ID, Anchor: strings.TrimSpace(p.Anchor)})
+		}
+	}
+	return picks, res, nil
+}
+
+func targetIDs(targets []links.Target) []uuid.UUID {
+	ids := make([]uuid.UUID, len(targets))
+	for i, t := range targets {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// linkSuggestionsFragment renders the suggestions with their context and
+// an Insert button each. Inserting posts the current body, so earlier
+// insertions and edits are kept.
+func linkSuggestionsFragment(body *links.Body, self uuid.UUID, suggestions []links.Suggestion, notice string) string {
+	var sb strings.Builder
+	if notice != "" {
+		fmt.Fprintf(&sb, `<p class="mb-2 text-xs text-amber-700 bg-amber-50 rounded p-2">%s</p>`, html.EscapeString(notice))
+	}
+	if len(suggestions) == 0 {
+		sb.WriteString(`<p class="text-xs text-gray-500">No link suggestions. The text doesn't mention other published content, or already links to it.</p>`)
+		return sb.String()
+	}
+
+	sb.WriteString(`<ul class="space-y-2">`)
+	for i, s := range suggestions {
+		before, anchor, after := body.Context(s, linkContextRadius)
+		vals, _ := json.Marshal(map[string]string{"anchor": s.Anchor, "target": s.Target.ID.String(), "id": idString(self)})
+		source := "Title match"
+		if s.FromAI {
+			source = "AI"
+		}
+		fmt.Fprintf(&sb, `<li id="link-suggestion-%d" class="rounded border border-gray-200 p-2 space-y-1.5">
+				<p class="text-xs text-gray-600">%s <mark class="bg-yellow-100 rounded px-0.5">%s</mark> %s</p>
+				<p class="text-xs text-gray-500">&rarr; <span class="font-medium text-gray-800">%s</span> <span class="text-gray-400">%s · %s</span></p>
+				<button type="button"
+					hx-post="/admin/internal-links/apply"
+					hx-include="#body, [name='body_format']"
+					hx-vals="%s"
+					hx-target="#link-suggestion-%d"
+					hx-swap="outerHTML"
+					class="w-full rounded-md bg-indigo-50 border border-indigo-200 px-2 py-1 text-xs font-medium text-indigo-700 hover:bg-indigo-100 transition-colors">
+					Insert Link
+				</button>
+			</li>`,
+			i, html.EscapeString(before), html.EscapeString(anchor), html.EscapeString(after),
+			html.EscapeString(s.Target.Title), html.EscapeString(s.Target.URL), source,
+			html.EscapeString(string(vals)), i)
+	}
+	sb.WriteString(`</ul>`)
+	return sb.String()
+}
+
+// idString formats a content ID for a form value, empty for new content.
+func idString(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// InternalLinkApply inserts an accepted link suggestion into the posted
+// body. The target is checked again, so a link is never made to the
+// content itself or to content that is no longer published. The linked
+// body comes back in a hidden textarea, which the editor loads.
+func (a *Admin) InternalLinkApply(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	anchor := r.FormValue("anchor")
+	self, _ := uuid.Parse(r.FormValue("id"))
+	targetID, err := uuid.Parse(r.FormValue("target"))
+	if err != nil || anchor == "" {
+		w.Write([]byte(aiErrorFragment("Invalid link suggestion.")))
+		return
+	}
+
+	target, err := a.contentStore.FindByID(targetID)
+	if err != nil {
+		slog.Error("find link target failed", "error", err)
+		w.Write([]byte(aiErrorFragment("Failed to load the linked content.")))
+		return
+	}
+	if target == nil || !target.IsPublished() || target.ID == self {
+		w.Write([]byte(aiErrorFragment("That content can't be linked: it is no longer published.")))
+		return
+	}
+
+	format := models.BodyFormat(r.FormValue("body_format"))
+	if format != models.BodyFormatHTML {
+		format = models.BodyFormatMarkdown
+	}
+	url := "/" + target.Slug
+	body := links.Parse(r.FormValue("body"), format)
+	if body.LinksTo(url) {
+		fmt.Fprintf(w, `<p class="text-xs text-gray-500 bg-gray-50 rounded p-2">The text already links to %s.</p>`, html.EscapeString(target.Title))
+		return
+	}
+	linked, ok := body.Apply(anchor, url)
+	if !ok {
+		fmt.Fprintf(w, `<p class="text-xs text-gray-500 bg-gray-50 rounded p-2">&ldquo;%s&rdquo; is no longer in the text, or is now inside a link or heading.</p>`, html.EscapeString(anchor))
+		return
+	}
+	fmt.Fprintf(w, `<div class="text-xs text-green-700 bg-green-50 rounded p-2">
+			<textarea hidden data-linked-body>%s</textarea>
+			Linked &ldquo;%s&rdquo; to %s.
+		</div>`,
+		html.EscapeString(linked), html.EscapeString(anchor), html.EscapeString(target.Title))
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"html"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/links"
+	"yaaicms/internal/models"
+)
+
+func TestLinkSuggestionsFragment(t *testing.T) {
+	body := links.Parse("Read about <b>soil & compost mixes</b> first.", models.BodyFormatMarkdown)
+	target := links.Target{ID: uuid.New(), Title: "Soil <Mixes>", URL: "/soil-mixes", Phrases: []string{"compost mixes"}}
+	got := linkSuggestionsFragment(body, uuid.Nil, links.Suggest(body, []links.Target{target}, nil), "")
+
+	if !strings.Contains(got, `<mark class="bg-yellow-100 rounded px-0.5">compost mixes</mark>`) ||
+		!strings.Contains(got, "Read about soil &amp;") || !strings.Contains(got, "Soil &lt;Mixes&gt;") {
+		t.Errorf("fragment:\n%s", got)
+	}
+	vals := `{"anchor":"compost mixes","id":"","target":"` + target.ID.String() + `"}`
+	if !strings.Contains(got, `hx-vals="`+html.EscapeString(vals)+`"`) {
+		t.Errorf("hx-vals missing %s:\n%s", vals, got)
+	}
+
+	got = linkSuggestionsFragment(body, uuid.Nil, nil, "AI is unavailable.")
+	if !strings.Contains(got, "AI is unavailable.") || !strings.Contains(got, "No link suggestions") {
+		t.Errorf("empty fragment:\n%s", got)
+	}
+}
+
+func TestInternalLinks_SuggestAndApply(t *testing.T) {
+	env := newTestEnv(t)
+
+	word := "zq" + uuid.New().String()[:8]
+	targetSlug := "test-link-target-" + uuid.New().String()[:8]
+	draftSlug := "test-link-draft-" + uuid.New().String()[:8]
+	selfSlug := "test-link-self-" + uuid.New().String()[:8]
+	t.Cleanup(func() { cleanContent(t, env.DB, targetSlug, draftSlug, selfSlug) })
+
+	authorID := testAuthorID(t, env.DB)
+	create := func(title, slug string, status models.ContentStatus) *models.Content {
+		c, err := env.ContentStore.Create(&models.Content{
+			Type: models.ContentTypePost, Title: title, Slug: slug, Body: "Text.",
+			BodyFormat: models.BodyFormatMarkdown, Status: status, AuthorID: authorID,
+		})
+		if err != nil {
+			t.Fatalf("create content: %v", err)
+		}
+		return c
+	}
+	target := create(word+" Composting Basics", targetSlug, models.ContentStatusPublished)
+	draft := create(word+" Seed Saving", draftSlug, models.ContentStatusDraft)
+	self := create(word+" Balcony Garden", selfSlug, models.ContentStatusPublished)
+
+	body := "Start with " + word + " composting basics. Then " + word + " seed saving, and this " + word + " balcony garden."
+	rec := httptest.NewRecorder()
+	env.Admin.AIInternalLinks(rec, postForm(url.Values{
+		"id": {self.ID.String()}, "title": {self.Title}, "body": {body}, "meta_keywords": {word + " composting"},
+	}))
+
+	// The mock provider's reply isn't JSON, so only title matches remain.
+	got := rec.Body.String()
+	if !strings.Contains(got, "title and keyword matches only") || !strings.Contains(got, "/"+targetSlug) {
+		t.Errorf("suggestions:\n%s", got)
+	}
+	if strings.Contains(got, draftSlug) || strings.Contains(got, selfSlug) {
+		t.Errorf("suggested a draft or the content itself:\n%s", got)
+	}
+
+	apply := func(targetID uuid.UUID, anchor string) string {
+		rec := httptest.NewRecorder()
+		env.Admin.InternalLinkApply(rec, postForm(url.Values{
+			"id": {self.ID.String()}, "target": {targetID.String()}, "anchor": {anchor}, "body": {body},
+		}))
+		return rec.Body.String()
+	}
+	want := html.EscapeString("[" + word + " composting basics](/" + targetSlug + ")")
+	if got := apply(target.ID, word+" composting basics"); !strings.Contains(got, "data-linked-body") || !strings.Contains(got, want) {
+		t.Errorf("apply:\n%s", got)
+	}
+	if got := apply(draft.ID, word+" seed saving"); strings.Contains(got, "data-linked-body") {
+		t.Errorf("linked a draft:\n%s", got)
+	}
+	if got := apply(self.ID, word+" balcony garden"); strings.Contains(got, "data-linked-body") {
+		t.Errorf("linked the content to itself:\n%s", got)
+	}
+}
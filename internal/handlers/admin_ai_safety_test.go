@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/engine"
+)
+
+// newModeratedAdmin returns an Admin whose OpenAI provider answers every
+// chat with reply, and whose moderator flags any text containing "FORBIDDEN".
+func newModeratedAdmin(t *testing.T, reply string) *Admin {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input string `json:"input"`
+		}
+		switch r.URL.Path {
+		case "/moderations":
+			json.NewDecoder(r.Body).Decode(&req)
+			flagged := strings.Contains(req.Input, "FORBIDDEN")
+			json.NewEncoder(w).Encode(map[string]any{"results": []any{map[string]any{
+				"flagged": flagged, "categories": map[string]bool{"violence": flagged},
+			}}})
+		case "/chat/completions":
+			json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{
+				"message": map[string]string{"role": "assistant", "content": reply},
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	reg := ai.NewRegistry("openai", map[string]ai.ProviderConfig{
+		"openai": {APIKey: "test", Model: "test-model", BaseURL: srv.URL},
+	})
+	return &Admin{aiRegistry: reg, engine: engine.New(nil)}
+}
+
+func postForm(values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestAIRewrite_FlaggedOutputDiscarded(t *testing.T) {
+	a := newModeratedAdmin(t, "A FORBIDDEN rewrite.")
+	rec := httptest.NewRecorder()
+	a.AIRewrite(rec, postForm(url.Values{"body": {"A harmless draft."}}))
+
+	body := rec.Body.String()
+	if strings.Contains(body, "rewrite.") || !strings.Contains(body, "flagged for: violence") {
+		t.Errorf("flagged rewrite shown: %s", body)
+	}
+
+	a = newModeratedAdmin(t, "A calm rewrite.")
+	rec = httptest.NewRecorder()
+	a.AIRewrite(rec, postForm(url.Values{"body": {"A harmless draft."}}))
+	if !strings.Contains(rec.Body.String(), "A calm rewrite.") {
+		t.Errorf("safe rewrite missing: %s", rec.Body.String())
+	}
+}
+
+func TestAIGenerateContent_FlaggedOutputDiscarded(t *testing.T) {
+	a := newModeratedAdmin(t, "## FORBIDDEN\n\nText.")
+	rec := httptest.NewRecorder()
+	a.AIGenerateContent(rec, postForm(url.Values{"ai_content_prompt": {"Write about gardens"}}))
+
+	body := rec.Body.String()
+	if strings.Contains(body, "Apply to Content") || !strings.Contains(body, "flagged for: violence") {
+		t.Errorf("flagged article shown: %s", body)
+	}
+}
+
+func TestTemplateGenResult_SafetyIssues(t *testing.T) {
+	a := newModeratedAdmin(t, "")
+	output := "```html\n" + `<header onclick="x()"><script src="https://evil.example/x.js"></script>{{.SiteName}}</header>` + "\n```"
+
+	resp := a.templateGenResult(postForm(url.Values{}), "header", output)
+	if len(resp.SafetyIssues) != 2 || resp.Sanitized || !strings.Contains(resp.HTML, "evil.example") {
+		t.Errorf("generate: got %+v", resp)
+	}
+	if strings.Contains(resp.Preview, "evil.example") || strings.Contains(resp.Preview, "onclick") {
+		t.Errorf("preview keeps unsafe HTML: %s", resp.Preview)
+	}
+
+	// Restyle All output is sanitized right away.
+	resp = a.templateGenResult(postForm(url.Values{"restyle": {"1"}}), "header", output)
+	if len(resp.SafetyIssues) != 2 || !resp.Sanitized || resp.HTML != "<header>{{.SiteName}}</header>" {
+		t.Errorf("restyle: got %+v", resp)
+	}
+
+	rec := httptest.NewRecorder()
+	a.AITemplateSanitize(rec, postForm(url.Values{
+		"html_content":  {`<header><a href="javascript:alert(1)">{{.SiteName}}</a></header>`},
+		"template_type": {"header"},
+	}))
+	resp = templateGenResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode JSON: %v", err)
+	}
+	if resp.HTML != `<header><a>{{.SiteName}}</a></header>` || !resp.Valid || !strings.Contains(resp.Preview, "<a>") {
+		t.Errorf("sanitize: got %+v", resp)
+	}
+}
+
+func TestAITemplateSave_UnsafeNeedsConfirmation(t *testing.T) {
+	a := newModeratedAdmin(t, "")
+	rec := httptest.NewRecorder()
+	a.AITemplateSave(rec, postForm(url.Values{
+		"name":         {"Unsafe"},
+		"type":         {"header"},
+		"html_content": {`<header><iframe src="https://ads.example"></iframe></header>`},
+	}))
+
+	var resp templateSaveResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if !strings.Contains(resp.Error, "iframe embedding ads.example") {
+		t.Errorf("save: got %+v", resp)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_template_repair.go sends invalid AI templates back to the model
+// with their error until they parse and render, or the attempts run out.
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+)
+
+// SetTemplateRepairs sets how many repair attempts the template builder
+// makes for an invalid template. 0 shows invalid templates as they are.
+func (a *Admin) SetTemplateRepairs(n int) {
+	a.templateRepairs = n
+}
+
+// repairTemplate asks the model to fix an invalid template. messages is the
+// conversation that produced result and resp is its check. Each attempt
+// adds the failed reply and a repair request to the conversation; it stops
+// once the template is valid, the attempts are used up, a call fails or
+// the user is over budget. It returns the last reply and its check, with
+// Repairs set to the attempts made.
+func (a *Admin) repairTemplate(r *http.Request, tmplType string, messages []ai.Message, result string, resp templateGenResponse) (string, templateGenResponse) {
+	for attempt := 1; !resp.Valid && attempt <= a.templateRepairs; attempt++ {
+		if sess := middleware.SessionFromCtx(r.Context()); sess != nil && a.userBudgetExceeded(sess.UserID, models.Role(sess.Role)) != "" {
+			break
+		}
+
+		messages = append(messages,
+			ai.Message{Role: ai.RoleAssistant, Content: result},
+			ai.Message{Role: ai.RoleUser, Content: templateRepairPrompt(tmplType, resp)},
+		)
+		res, err := a.aiRegistry.ChatForTask(r.Context(), ai.TaskTemplate, messages)
+		if err != nil {
+			slog.Warn("ai template repair failed", "attempt", attempt, "error", err)
+			break
+		}
+		slog.Info("ai template repair attempt", "type", tmplType, "attempt", attempt, "error", resp.ValidationError)
+
+		result = res.Text
+		resp = a.templateGenResult(r, tmplType, result)
+		resp.Repairs = attempt
+	}
+
+	switch {
+	case resp.Repairs == 0:
+	case resp.Valid:
+		resp.Message = fmt.Sprintf("Fixed an error in the template after %d repair attempt(s). %s", resp.Repairs, resp.Message)
+	default:
+		resp.Message = fmt.Sprintf("The template still has an error after %d repair attempt(s). Describe the issue or try again.", resp.Repairs)
+	}
+	return result, resp
+}
+
+// templateRepairPrompt is the user message for one repair attempt: the
+// error, the line it points at and the variables the template can use.
+func templateRepairPrompt(tmplType string, resp templateGenResponse) string {
+	var b strings.Builder
+	b.WriteString("The template you returned does not work. Parsing or rendering it with preview data failed with this error:\n")
+	b.WriteString(resp.ValidationError)
+	b.WriteString("\n\n")
+
+	if n, line := errorLine(resp.ValidationError, resp.errorSource); line != "" {
+		fmt.Fprintf(&b, "Line %d of the template is:\n%s\n\n", n, line)
+	}
+
+	fmt.Fprintf(&b, "The only variables a %s template can use are:\n", tmplType)
+	b.WriteString(templateFields(tmplType))
+	b.WriteString("\nFix the error without changing the design. Reply with only the complete corrected template.")
+	return b.String()
+}
+
+// templateErrorLine finds the line number in text/template and html/template
+// errors, as in "template: page:5:12: executing ..." or
+// "html/template:page:5:12: ...".
+var templateErrorLine = regexp.MustCompile(`template: ?[^:\s]+:(\d+)`)
+
+// errorLine returns the number and text of the source line a template error
+// points at, or an empty line if the error has no line number in source.
+func errorLine(errMsg, source string) (int, string) {
+	m := templateErrorLine.FindStringSubmatch(errMsg)
+	if m == nil {
+		return 0, ""
+	}
+	n, _ := strconv.Atoi(m[1])
+	lines := strings.Split(source, "\n")
+	if n < 1 || n > len(lines) {
+		return 0, ""
+	}
+	return n, truncate(strings.TrimSpace(lines[n-1]), 300)
+}
+
+// templateFields lists the fields of the data a template type is rendered
+// with, one per line. Slices of structs list the fields available inside
+// their range.
+func templateFields(tmplType string) string {
+	var b strings.Builder
+	for _, f := range reflect.VisibleFields(reflect.TypeOf(buildPreviewData(tmplType))) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		fmt.Fprintf(&b, "- .%s", f.Name)
+		if f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct {
+			fmt.Fprintf(&b, " (inside {{range .%s}}: %s)", f.Name, strings.Join(itemFields(f.Type.Elem()), ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// itemFields returns the exported fields of a struct, promoted ones included.
+func itemFields(t reflect.Type) []string {
+	var names []string
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.Anonymous && f.IsExported() {
+			names = append(names, "."+f.Name)
+		}
+	}
+	return names
+}
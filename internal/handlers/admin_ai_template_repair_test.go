@@ -0,0 +1,125 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/engine"
+)
+
+// scriptedProvider answers each call with the next reply, repeating the
+// last one, and records the conversations it was sent.
+type scriptedProvider struct {
+	mockAIProvider
+	replies []string
+	chats   [][]ai.Message
+}
+
+func (s *scriptedProvider) next() string {
+	reply := s.replies[0]
+	if len(s.replies) > 1 {
+		s.replies = s.replies[1:]
+	}
+	return reply
+}
+
+func (s *scriptedProvider) GenerateWithModel(_ context.Context, _, _, _ string) (ai.Result, error) {
+	return ai.Result{Text: s.next()}, nil
+}
+
+func (s *scriptedProvider) Chat(_ context.Context, _ string, messages []ai.Message) (ai.Result, error) {
+	s.chats = append(s.chats, messages)
+	return ai.Result{Text: s.next()}, nil
+}
+
+// generateRestyle runs a restyle generation of tmplType with the given
+// number of repair attempts.
+func generateRestyle(t *testing.T, repairs int, tmplType string, replies ...string) (templateGenResponse, *scriptedProvider) {
+	t.Helper()
+	p := &scriptedProvider{mockAIProvider: mockAIProvider{name: "test"}, replies: replies}
+	reg := ai.NewRegistry("test", map[string]ai.ProviderConfig{})
+	reg.Register("test", p)
+	a := &Admin{aiRegistry: reg, engine: engine.New(nil), templateRepairs: repairs}
+
+	rec := httptest.NewRecorder()
+	a.AITemplateGenerate(rec, postForm(url.Values{"prompt": {"A calm look"}, "template_type": {tmplType}, "restyle": {"1"}}))
+	var resp templateGenResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp, p
+}
+
+func TestTemplateRepairFixesParseError(t *testing.T) {
+	resp, p := generateRestyle(t, 2, "header", "<header>\n{{.SiteName</header>", "<header>{{.SiteName}}</header>")
+
+	if !resp.Valid || resp.Repairs != 1 || resp.HTML != "<header>{{.SiteName}}</header>" || resp.Preview == "" {
+		t.Fatalf("got %+v", resp)
+	}
+	if !strings.HasPrefix(resp.Message, "Fixed an error in the template after 1 repair attempt(s).") {
+		t.Errorf("message %q", resp.Message)
+	}
+
+	if len(p.chats) != 1 {
+		t.Fatalf("%d repair calls, want 1", len(p.chats))
+	}
+	msgs := p.chats[0]
+	if len(msgs) != 4 || msgs[2].Role != ai.RoleAssistant || msgs[2].Content != "<header>\n{{.SiteName</header>" {
+		t.Fatalf("conversation %+v", msgs)
+	}
+	repair := msgs[3].Content
+	for _, want := range []string{"bad character", "Line 2 of the template is:\n{{.SiteName</header>", "- .SiteName\n- .Year\n"} {
+		if !strings.Contains(repair, want) {
+			t.Errorf("repair prompt lacks %q:\n%s", want, repair)
+		}
+	}
+}
+
+func TestTemplateRepairExecutionError(t *testing.T) {
+	// The template parses, but headers are rendered without a .Title.
+	broken := "<header>\n<h1>{{.Title}}</h1>\n</header>"
+
+	resp, _ := generateRestyle(t, 0, "header", broken)
+	if resp.Valid || resp.Repairs != 0 || !strings.Contains(resp.ValidationError, "can't evaluate field Title") || resp.Preview != "" {
+		t.Errorf("without repairs: %+v", resp)
+	}
+
+	resp, p := generateRestyle(t, 2, "header", broken)
+	if resp.Valid || resp.Repairs != 2 || len(p.chats) != 2 {
+		t.Fatalf("got %+v after %d calls", resp, len(p.chats))
+	}
+	if !strings.HasPrefix(resp.Message, "The template still has an error after 2 repair attempt(s).") {
+		t.Errorf("message %q", resp.Message)
+	}
+	if last := p.chats[1]; len(last) != 6 || !strings.Contains(last[5].Content, "Line 2 of the template is:\n<h1>{{.Title}}</h1>") {
+		t.Errorf("second attempt: %+v", last)
+	}
+}
+
+func TestTemplateFields(t *testing.T) {
+	loop := templateFields("article_loop")
+	if !strings.Contains(loop, "- .Posts (inside {{range .Posts}}: .Title, .Slug, .Excerpt,") || !strings.Contains(loop, "- .Footer\n") {
+		t.Errorf("article_loop:\n%s", loop)
+	}
+	// Promoted fields of embedded structs are listed; the embedded struct is not.
+	search := templateFields("search")
+	if !strings.Contains(search, "{{range .Results}}: .Title,") || !strings.Contains(search, ".Snippet") || strings.Contains(search, ".PostItem") {
+		t.Errorf("search:\n%s", search)
+	}
+
+	if n, line := errorLine("template: page:3:5: executing", "a\nb\n  c {{.X}}  \n"); n != 3 || line != "c {{.X}}" {
+		t.Errorf("errorLine = %d, %q", n, line)
+	}
+	if _, line := errorLine("template: page:9: bad", "a"); line != "" {
+		t.Errorf("line past the end: %q", line)
+	}
+}
@@ -5,163 +5,69 @@
 package handlers
 
 import (
+	"context"
+	"net/url"
 	"strings"
 	"testing"
 
+	"github.com/google/uuid"
+
 	"yaaicms/internal/engine"
+	"yaaicms/internal/models"
+	"yaaicms/internal/prompts"
 )
 
-func TestParseNumberedList(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected []string
-	}{
-		{
-			name:     "dot numbered",
-			input:    "1. First Title\n2. Second Title\n3. Third Title",
-			expected: []string{"First Title", "Second Title", "Third Title"},
-		},
-		{
-			name:     "paren numbered",
-			input:    "1) First Title\n2) Second Title",
-			expected: []string{"First Title", "Second Title"},
-		},
-		{
-			name:     "dash bullets",
-			input:    "- First Title\n- Second Title",
-			expected: []string{"First Title", "Second Title"},
-		},
-		{
-			name:     "with quotes",
-			input:    `1. "First Title"` + "\n" + `2. 'Second Title'`,
-			expected: []string{"First Title", "Second Title"},
-		},
-		{
-			name:     "with empty lines",
-			input:    "\n1. First\n\n2. Second\n\n",
-			expected: []string{"First", "Second"},
-		},
-		{
-			name:     "no prefix",
-			input:    "Just a single line",
-			expected: []string{"Just a single line"},
-		},
+func TestCleanList(t *testing.T) {
+	got := cleanList([]string{"  go ", "", "web development", "go", "  "})
+	want := []string{"go", "web development"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := parseNumberedList(tt.input)
-			if len(result) != len(tt.expected) {
-				t.Fatalf("got %d items, want %d: %v", len(result), len(tt.expected), result)
-			}
-			for i, item := range result {
-				if item != tt.expected[i] {
-					t.Errorf("item %d: got %q, want %q", i, item, tt.expected[i])
-				}
-			}
-		})
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d: got %q, want %q", i, got[i], want[i])
+		}
 	}
 }
 
-func TestParseSEOResult(t *testing.T) {
-	tests := []struct {
-		name         string
-		input        string
-		wantDesc     string
-		wantKeywords string
-	}{
-		{
-			name:         "standard format",
-			input:        "DESCRIPTION: A great article about Go.\nKEYWORDS: go, programming, google",
-			wantDesc:     "A great article about Go.",
-			wantKeywords: "go, programming, google",
-		},
-		{
-			name:         "lowercase prefixes",
-			input:        "description: Some description.\nkeywords: key1, key2",
-			wantDesc:     "Some description.",
-			wantKeywords: "key1, key2",
-		},
-		{
-			name:         "meta prefixes",
-			input:        "Meta Description: SEO optimized.\nMeta Keywords: seo, web",
-			wantDesc:     "SEO optimized.",
-			wantKeywords: "seo, web",
-		},
-		{
-			name:         "extra whitespace",
-			input:        "\n\nDESCRIPTION:   Spaced out.  \nKEYWORDS:   a, b, c  \n",
-			wantDesc:     "Spaced out.",
-			wantKeywords: "a, b, c",
-		},
-		{
-			name:         "no match",
-			input:        "Some random text without structure",
-			wantDesc:     "",
-			wantKeywords: "",
-		},
-	}
+func TestRevisionMeta(t *testing.T) {
+	reply := `{"title":"\"Update the title\"","changelog":["Renamed the post","- Fixed a typo"]}`
+	job := revisionMetaJob{SystemPrompt: "sys", Prompt: "prompt", DefaultTitle: "Content updated", DiffSummary: "Title: a -> b"}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			desc, kw := parseSEOResult(tt.input)
-			if desc != tt.wantDesc {
-				t.Errorf("description: got %q, want %q", desc, tt.wantDesc)
-			}
-			if kw != tt.wantKeywords {
-				t.Errorf("keywords: got %q, want %q", kw, tt.wantKeywords)
-			}
-		})
-	}
-}
+	t.Run("title and changelog", func(t *testing.T) {
+		a := newStreamAdmin(&mockAIProvider{name: "test", response: reply})
+		title, changelog, err := a.revisionMeta(context.Background(), job)
+		if err != nil {
+			t.Fatalf("revisionMeta: %v", err)
+		}
+		if title != "Update the title" {
+			t.Errorf("title: got %q", title)
+		}
+		if changelog != "- Renamed the post\n- Fixed a typo" {
+			t.Errorf("changelog: got %q", changelog)
+		}
+	})
 
-func TestParseTags(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected []string
-	}{
-		{
-			name:     "comma separated",
-			input:    "go, programming, web development, api",
-			expected: []string{"go", "programming", "web development", "api"},
-		},
-		{
-			name:     "with quotes",
-			input:    `"go", "programming", "web"`,
-			expected: []string{"go", "programming", "web"},
-		},
-		{
-			name:     "with dashes and bullets",
-			input:    "- go, - programming, * web",
-			expected: []string{"go", "programming", "web"},
-		},
-		{
-			name:     "extra whitespace",
-			input:    "  go ,  programming  , web  ",
-			expected: []string{"go", "programming", "web"},
-		},
-		{
-			name:     "empty items filtered",
-			input:    "go,,, programming",
-			expected: []string{"go", "programming"},
-		},
-	}
+	t.Run("keeps the user's title", func(t *testing.T) {
+		a := newStreamAdmin(&mockAIProvider{name: "test", response: reply})
+		p := job
+		p.UserTitle = "My message"
+		title, _, _ := a.revisionMeta(context.Background(), p)
+		if title != "My message" {
+			t.Errorf("title: got %q", title)
+		}
+	})
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := parseTags(tt.input)
-			if len(result) != len(tt.expected) {
-				t.Fatalf("got %d tags, want %d: %v", len(result), len(tt.expected), result)
-			}
-			for i, tag := range result {
-				if tag != tt.expected[i] {
-					t.Errorf("tag %d: got %q, want %q", i, tag, tt.expected[i])
-				}
-			}
-		})
-	}
+	t.Run("falls back when the reply is invalid", func(t *testing.T) {
+		a := newStreamAdmin(&mockAIProvider{name: "test", response: "Update the title"})
+		title, changelog, err := a.revisionMeta(context.Background(), job)
+		if err == nil {
+			t.Error("expected an error for an invalid reply")
+		}
+		if title != "Content updated" || changelog != "Title: a -> b" {
+			t.Errorf("got %q, %q", title, changelog)
+		}
+	})
 }
 
 func TestTruncate(t *testing.T) {
@@ -216,8 +122,9 @@ func TestExtractHTMLFromResponse(t *testing.T) {
 	}
 }
 
-func TestBuildTemplateSystemPrompt(t *testing.T) {
+func TestTemplateSystemPrompt(t *testing.T) {
 	// Verify each template type produces a prompt containing the right variables.
+	a := &Admin{}
 	tests := []struct {
 		tmplType string
 		contains []string
@@ -230,7 +137,7 @@ func TestBuildTemplateSystemPrompt(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.tmplType, func(t *testing.T) {
-			prompt := buildTemplateSystemPrompt(tt.tmplType)
+			prompt := a.systemPrompt(prompts.TemplateGenerate, templatePromptVars(tt.tmplType))
 			for _, s := range tt.contains {
 				if !containsStr(prompt, s) {
 					t.Errorf("prompt for %q should contain %q", tt.tmplType, s)
@@ -299,3 +206,67 @@ func TestQuoteJSString(t *testing.T) {
 		})
 	}
 }
+
+func TestImageJobFromForm(t *testing.T) {
+	source := uuid.New()
+	tests := []struct {
+		name    string
+		form    url.Values
+		want    imageJob
+		wantMsg string
+	}{
+		{
+			name: "generate with options",
+			form: url.Values{"ai_image_prompt": {" a fox "}, "image_aspect": {"1:1"}, "image_count": {"2"},
+				"image_style": {"ink"}, "image_negative_prompt": {"text"}, "image_seed": {"7"}, "source_id": {source.String()}},
+			want: imageJob{Prompt: "a fox", Mode: models.GenerationGenerate, Aspect: "1:1", Count: 2, Style: "ink", NegativePrompt: "text", Seed: 7},
+		},
+		{
+			name:    "generate needs a prompt",
+			form:    url.Values{},
+			wantMsg: "describe the image",
+		},
+		{
+			name: "edit",
+			form: url.Values{"ai_image_prompt": {"add a hat"}, "image_mode": {"edit"}, "source_id": {source.String()}},
+			want: imageJob{Prompt: "add a hat", Mode: models.GenerationEdit, SourceID: &source},
+		},
+		{
+			name:    "edit needs a change",
+			form:    url.Values{"image_mode": {"edit"}, "source_id": {source.String()}},
+			wantMsg: "describe the change",
+		},
+		{
+			name:    "edit needs a source",
+			form:    url.Values{"ai_image_prompt": {"add a hat"}, "image_mode": {"edit"}},
+			wantMsg: "Choose the image",
+		},
+		{
+			name: "variation drops the prompt",
+			form: url.Values{"ai_image_prompt": {"ignored"}, "image_mode": {"variation"}, "source_id": {source.String()}},
+			want: imageJob{Mode: models.GenerationVariation, SourceID: &source},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, msg := imageJobFromForm(postForm(tt.form))
+			if tt.wantMsg != "" {
+				if !strings.Contains(msg, tt.wantMsg) {
+					t.Errorf("message: got %q, want %q", msg, tt.wantMsg)
+				}
+				return
+			}
+			if msg != "" {
+				t.Fatalf("unexpected message %q", msg)
+			}
+			if (got.SourceID == nil) != (tt.want.SourceID == nil) || (got.SourceID != nil && *got.SourceID != *tt.want.SourceID) {
+				t.Errorf("source: got %v, want %v", got.SourceID, tt.want.SourceID)
+			}
+			got.SourceID, tt.want.SourceID = nil, nil
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_translate.go contains AI translation of content into the
+// site's other languages and the editor's translations panel.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+	"yaaicms/internal/prompts"
+	"yaaicms/internal/slug"
+	"yaaicms/internal/store"
+)
+
+// translateMaxChars is the longest body sent for translation. The whole
+// body goes in one request so its structure survives; longer bodies
+// should be split into several items.
+const translateMaxChars = 30_000
+
+// translatedContent is the structured reply of a translation.
+type translatedContent struct {
+	Title           string   `json:"title" desc:"Translated title"`
+	Body            string   `json:"body" desc:"Translated body with the original markup unchanged"`
+	Excerpt         string   `json:"excerpt" desc:"Translated excerpt, empty if the original has none"`
+	MetaDescription string   `json:"meta_description" desc:"Translated meta description, empty if the original has none"`
+	MetaKeywords    []string `json:"meta_keywords" desc:"Translated keywords, empty if the original has none"`
+}
+
+var translatedContentSchema = ai.MustSchema("translated_content", translatedContent{})
+
+// translationPanel is the editor's view of a content item's languages.
+type translationPanel struct {
+	Locale       string                      // The item's locale, "" for the default language
+	LocaleName   string                      // English name of the item's language
+	Source       *models.Content             // The source, when the item is a translation
+	Translations []models.ContentTranslation // Translations of the source
+	Languages    []models.Language           // Languages it can be translated to
+}
+
+// SetTranslations enables AI translation of content.
+func (a *Admin) SetTranslations(s *store.TranslationStore) {
+	a.translations = s
+}
+
+// siteLanguage returns the site's default language code.
+func (a *Admin) siteLanguage() string {
+	if a.siteSettingStore != nil {
+		if lang, err := a.siteSettingStore.Get("language", "en"); err == nil && lang != "" {
+			return lang
+		}
+	}
+	return "en"
+}
+
+// languageName returns the English name of a language code.
+func languageName(code string) string {
+	if l, ok := models.LanguageByCode(code); ok {
+		return l.Name
+	}
+	return code
+}
+
+// translationSource returns the source of item and item's locale: item
+// itself and "" when it isn't a translation.
+func (a *Admin) translationSource(item *models.Content) (*models.Content, string, error) {
+	link, err := a.translations.FindByTranslation(item.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if link == nil {
+		return item, "", nil
+	}
+	source, err := a.contentStore.FindByID(link.SourceID)
+	if err != nil {
+		return nil, "", err
+	}
+	if source == nil {
+		return nil, "", fmt.Errorf("translation source %s not found", link.SourceID)
+	}
+	return source, link.Locale, nil
+}
+
+// buildTranslationPanel collects the languages of item for the editor.
+// Returns nil if translations are not available.
+func (a *Admin) buildTranslationPanel(item *models.Content) *translationPanel {
+	if a.translations == nil {
+		return nil
+	}
+	source, locale, err := a.translationSource(item)
+	if err != nil {
+		slog.Error("load translation source failed", "content", item.ID, "error", err)
+		return nil
+	}
+	list, err := a.translations.ListBySource(source.ID)
+	if err != nil {
+		slog.Error("list translations failed", "content", source.ID, "error", err)
+	}
+
+	site := a.siteLanguage()
+	panel := &translationPanel{
+		Locale:       locale,
+		LocaleName:   languageName(site),
+		Translations: list,
+	}
+	if locale != "" {
+		panel.LocaleName = languageName(locale)
+		panel.Source = source
+	}
+	for _, l := range models.Languages {
+		if l.Code != site {
+			panel.Languages = append(panel.Languages, l)
+		}
+	}
+	return panel
+}
+
+// translationLocales returns the locale of every translation keyed by
+// content ID, for badging the content lists.
+func (a *Admin) translationLocales() map[uuid.UUID]string {
+	if a.translations == nil {
+		return nil
+	}
+	locales, err := a.translations.Locales()
+	if err != nil {
+		slog.Error("list translation locales failed", "error", err)
+	}
+	return locales
+}
+
+// translateContent asks the AI to translate a content item's title, body,
+// excerpt and SEO metadata into lang, keeping the body's markup intact.
+func (a *Admin) translateContent(ctx context.Context, c *models.Content, from, to models.Language) (*translatedContent, ai.Result, error) {
+	formatNote := "The body is Markdown. Keep every Markdown construct (headings, lists, links, emphasis, tables, code) exactly as it is and translate only the text."
+	if c.BodyFormat == models.BodyFormatHTML {
+		formatNote = "The body is HTML. Keep every tag and attribute exactly as it is and translate only the text between tags and the alt and title attributes."
+	}
+
+	systemPrompt := a.systemPrompt(prompts.ContentTranslate, prompts.Vars{
+		"source_language": from.Name,
+		"target_language": to.Name,
+		"format_note":     formatNote,
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Title: %s\n\nBody:\n%s\n", c.Title, c.Body)
+	if e := ptrStr(c.Excerpt); e != "" {
+		fmt.Fprintf(&sb, "\nExcerpt: %s\n", e)
+	}
+	if d := ptrStr(c.MetaDescription); d != "" {
+		fmt.Fprintf(&sb, "\nMeta description: %s\n", d)
+	}
+	if k := ptrStr(c.MetaKeywords); k != "" {
+		fmt.Fprintf(&sb, "\nMeta keywords: %s\n", k)
+	}
+
+	var out translatedContent
+	res, err := a.aiRegistry.GenerateJSON(ctx, ai.TaskContent, translatedContentSchema, systemPrompt, sb.String(), &out)
+	if err != nil {
+		return nil, res, err
+	}
+	out.Title = strings.TrimSpace(out.Title)
+	out.Body = strings.TrimSpace(out.Body)
+	out.Excerpt = strings.TrimSpace(out.Excerpt)
+	out.MetaDescription = strings.TrimSpace(out.MetaDescription)
+	out.MetaKeywords = cleanList(out.MetaKeywords)
+	return &out, res, nil
+}
+
+// applyTranslation copies a translation onto c. Optional fields the
+// source doesn't have stay empty.
+func applyTranslation(c, source *models.Content, t *translatedContent) {
+	c.Title = t.Title
+	c.Body = t.Body
+	c.Excerpt = nil
+	c.MetaDescription = nil
+	c.MetaKeywords = nil
+	if t.Excerpt != "" && ptrStr(source.Excerpt) != "" {
+		c.Excerpt = &t.Excerpt
+	}
+	if t.MetaDescription != "" && ptrStr(source.MetaDescription) != "" {
+		c.MetaDescription = &t.MetaDescription
+	}
+	if kw := strings.Join(t.MetaKeywords, ", "); kw != "" && ptrStr(source.MetaKeywords) != "" {
+		c.MetaKeywords = &kw
+	}
+}
+
+// translationSlug returns an unused slug for a translation: the slug of
+// its title, or the source's slug when the title has no Latin letters,
+// suffixed with the locale if taken.
+func (a *Admin) translationSlug(title, sourceSlug, locale string) (string, error) {
+	base := slug.Generate(title)
+	if base == "" {
+		base = sourceSlug
+	}
+	candidates := []string{base, base + "-" + locale}
+	for i := 2; i <= 9; i++ {
+		candidates = append(candidates, fmt.Sprintf("%s-%s-%d", base, locale, i))
+	}
+	for _, s := range candidates {
+		exists, err := a.contentStore.SlugExists(s)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("no free slug for %q", base)
+}
+
+// AITranslate translates a content item into another language. A new
+// translation is created as a draft; an existing one is updated in place
+// after saving a revision. Responds with a redirect to the translation's
+// editor.
+func (a *Admin) AITranslate(w http.ResponseWriter, r *http.Request) {
+	sess := middleware.SessionFromCtx(r.Context())
+	ctx := r.Context()
+
+	if a.translations == nil {
+		writeAIError(w, "Translations are not available.")
+		return
+	}
+	id, err := uuid.Parse(r.FormValue("content_id"))
+	if err != nil {
+		writeAIError(w, "Save the content before translating it.")
+		return
+	}
+	target, ok := models.LanguageByCode(r.FormValue("locale"))
+	site := a.siteLanguage()
+	if !ok || target.Code == site {
+		writeAIError(w, "Choose a language to translate to.")
+		return
+	}
+	from, ok := models.LanguageByCode(site)
+	if !ok {
+		from = models.Language{Code: site, Name: site}
+	}
+
+	item, err := a.contentStore.FindByID(id)
+	if err != nil || item == nil {
+		writeAIError(w, "Content not found.")
+		return
+	}
+	// Always translate from the source, never from another translation.
+	source, _, err := a.translationSource(item)
+	if err != nil {
+		slog.Error("load translation source failed", "content", id, "error", err)
+		writeAIError(w, "Failed to load the original content.")
+		return
+	}
+	if utf8.RuneCountInString(source.Body) > translateMaxChars {
+		writeAIError(w, fmt.Sprintf("The content is too long to translate in one go (max %d characters).", translateMaxChars))
+		return
+	}
+	if !a.checkAIBudget(w, r) {
+		return
+	}
+
+	translated, res, err := a.translateContent(ctx, source, from, target)
+	if err != nil {
+		slog.Error("ai translate failed", "content", source.ID, "locale", target.Code, "error", err)
+		writeAIError(w, "AI translation failed. Check your provider configuration.")
+		return
+	}
+	reportAIProvider(w, res)
+
+	if translated.Title == "" || translated.Body == "" {
+		writeAIError(w, "The AI returned an empty translation. Try again.")
+		return
+	}
+	if errMsg := validateContent(translated.Title, "", translated.Body); errMsg != "" {
+		writeAIError(w, "The translation is invalid: "+errMsg)
+		return
+	}
+
+	existing, err := a.translations.FindBySource(source.ID, target.Code)
+	if err != nil {
+		slog.Error("find translation failed", "content", source.ID, "error", err)
+		writeAIError(w, "Failed to load translations.")
+		return
+	}
+
+	var c *models.Content
+	if existing != nil {
+		c, err = a.updateTranslation(ctx, existing.TranslationID, source, translated, target, sess.UserID)
+	} else {
+		c, err = a.createTranslation(source, translated, target, sess.UserID)
+	}
+	if err != nil {
+		slog.Error("save translation failed", "content", source.ID, "locale", target.Code, "error", err)
+		writeAIError(w, "Failed to save the translation.")
+		return
+	}
+	if errMsg := validateMetadata(ptrStr(c.Excerpt), ptrStr(c.MetaDescription), ptrStr(c.MetaKeywords)); errMsg != "" {
+		slog.Warn("translated metadata too long", "content", c.ID, "error", errMsg)
+	}
+
+	slog.Info("content translated", "source", source.ID, "translation", c.ID, "locale", target.Code, "user", sess.Email)
+	auditFrom(ctx).saved("content/"+c.ID.String(), utf8.RuneCountInString(translated.Title+translated.Body))
+
+	w.Header().Set("HX-Redirect", "/admin/"+string(c.Type)+"s/"+c.ID.String())
+	w.WriteHeader(http.StatusOK)
+}
+
+// createTranslation saves a new draft translation of source and links it.
+func (a *Admin) createTranslation(source *models.Content, t *translatedContent, lang models.Language, authorID uuid.UUID) (*models.Content, error) {
+	s, err := a.translationSlug(t.Title, source.Slug, lang.Code)
+	if err != nil {
+		return nil, err
+	}
+	c := &models.Content{
+		Type:            source.Type,
+		Slug:            s,
+		BodyFormat:      source.BodyFormat,
+		Status:          models.ContentStatusDraft,
+		FeaturedImageID: source.FeaturedImageID,
+		CategoryID:      source.CategoryID,
+		AuthorID:        authorID,
+	}
+	applyTranslation(c, source, t)
+	truncateMetadata(c)
+
+	created, err := a.contentStore.Create(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.translations.Link(source.ID, created.ID, lang.Code); err != nil {
+		// Don't leave an unlinked copy behind.
+		if derr := a.contentStore.Delete(created.ID); derr != nil {
+			slog.Error("delete unlinked translation failed", "content", created.ID, "error", derr)
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+// updateTranslation re-translates an existing translation, saving a
+// revision of it first so the previous wording can be restored. Its slug
+// and status are kept.
+func (a *Admin) updateTranslation(ctx context.Context, id uuid.UUID, source *models.Content, t *translatedContent, lang models.Language, userID uuid.UUID) (*models.Content, error) {
+	c, err := a.contentStore.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("translation %s not found", id)
+	}
+
+	_, err = a.revisionStore.Create(&models.ContentRevision{
+		ContentID:       c.ID,
+		Title:           c.Title,
+		Slug:            c.Slug,
+		Body:            c.Body,
+		BodyFormat:      c.BodyFormat,
+		Excerpt:         c.Excerpt,
+		Status:          string(c.Status),
+		MetaDescription: c.MetaDescription,
+		MetaKeywords:    c.MetaKeywords,
+		FeaturedImageID: c.FeaturedImageID,
+		CategoryID:      c.CategoryID,
+		RevisionTitle:   "AI translation: " + lang.Name,
+		RevisionLog:     "- Re-translated from \"" + source.Title + "\"",
+		CreatedBy:       userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save revision: %w", err)
+	}
+
+	c.BodyFormat = source.BodyFormat
+	applyTranslation(c, source, t)
+	truncateMetadata(c)
+	if err := a.contentStore.Update(c); err != nil {
+		return nil, err
+	}
+	a.invalidateContentCache(ctx, c.ID, c.Slug, "update")
+	return c, nil
+}
+
+// truncateMetadata cuts AI-written metadata to the lengths the editor
+// accepts, so the translation can be saved from the form unchanged.
+func truncateMetadata(c *models.Content) {
+	cut := func(p *string, n int) *string {
+		if p == nil || utf8.RuneCountInString(*p) <= n {
+			return p
+		}
+		s := string([]rune(*p)[:n])
+		return &s
+	}
+	c.Excerpt = cut(c.Excerpt, maxExcerptLen)
+	c.MetaDescription = cut(c.MetaDescription, maxMetaDescLen)
+	c.MetaKeywords = cut(c.MetaKeywords, maxMetaKeywordLen)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"yaaicms/internal/models"
+)
+
+func TestContentPath(t *testing.T) {
+	tests := []struct {
+		locale, slug string
+		path, key    string
+	}{
+		{"", "about", "/about", "about"},
+		{"de", "ueber-uns", "/de/ueber-uns", "de:ueber-uns"},
+	}
+	for _, tt := range tests {
+		if got := contentPath(tt.locale, tt.slug); got != tt.path {
+			t.Errorf("contentPath(%q, %q) = %q, want %q", tt.locale, tt.slug, got, tt.path)
+		}
+		if got := contentCacheKey(tt.locale, tt.slug); got != tt.key {
+			t.Errorf("contentCacheKey(%q, %q) = %q, want %q", tt.locale, tt.slug, got, tt.key)
+		}
+	}
+}
+
+func TestApplyTranslation(t *testing.T) {
+	has := "set"
+	tr := &translatedContent{
+		Title: "Hallo", Body: "# Hallo", Excerpt: "Kurz",
+		MetaDescription: "Beschreibung", MetaKeywords: []string{"eins", "zwei"},
+	}
+
+	// Only fields the source has are copied.
+	c := &models.Content{Excerpt: &has}
+	applyTranslation(c, &models.Content{MetaKeywords: &has}, tr)
+	if c.Title != "Hallo" || c.Body != "# Hallo" {
+		t.Errorf("title/body: got %q, %q", c.Title, c.Body)
+	}
+	if c.Excerpt != nil || c.MetaDescription != nil {
+		t.Errorf("copied fields the source lacks: %v, %v", c.Excerpt, c.MetaDescription)
+	}
+	if ptrStr(c.MetaKeywords) != "eins, zwei" {
+		t.Errorf("keywords: got %q", ptrStr(c.MetaKeywords))
+	}
+}
+
+func TestTruncateMetadata(t *testing.T) {
+	long := strings.Repeat("ü", maxMetaDescLen+10)
+	c := &models.Content{MetaDescription: &long}
+	truncateMetadata(c)
+	if n := len([]rune(*c.MetaDescription)); n != maxMetaDescLen {
+		t.Errorf("meta description: got %d runes, want %d", n, maxMetaDescLen)
+	}
+	if c.Excerpt != nil {
+		t.Error("nil excerpt became non-nil")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_ai_usage.go records AI token usage per user and serves the admin
+// usage and cost report.
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/models"
+	"yaaicms/internal/render"
+	"yaaicms/internal/store"
+)
+
+// UsageRecorder implements ai.UsageRecorder by storing one ai_usage row per
+// completion, attributed to the user of the request that triggered it.
+type UsageRecorder struct {
+	store  *store.AIUsageStore
+	prices ai.PriceTable
+}
+
+// NewUsageRecorder creates a UsageRecorder that prices calls with prices.
+func NewUsageRecorder(s *store.AIUsageStore, prices ai.PriceTable) *UsageRecorder {
+	return &UsageRecorder{store: s, prices: prices}
+}
+
+// RecordUsage stores rec and adds it to the audit entry of ctx, if any.
+// Best-effort: failures are logged and never fail the AI call itself.
+func (u *UsageRecorder) RecordUsage(ctx context.Context, rec ai.UsageRecord) {
+	auditFrom(ctx).call(rec)
+	row := &models.AIUsage{
+		UserID:       actorID(ctx),
+		Task:         rec.Task.String(),
+		Provider:     rec.Provider,
+		Model:        rec.Model,
+		InputTokens:  rec.InputTokens,
+		OutputTokens: rec.OutputTokens,
+		LatencyMs:    int(rec.Latency.Milliseconds()),
+		Success:      rec.Err == nil,
+	}
+	if cost, ok := u.prices.Cost(rec.Model, rec.InputTokens, rec.OutputTokens); ok {
+		row.CostUSD = &cost
+	}
+	if err := u.store.Record(row); err != nil {
+		slog.Warn("record ai usage failed", "provider", rec.Provider, "model", rec.Model, "error", err)
+	}
+}
+
+// SetAIUsage enables the AI usage report. Budgets also need it (see
+// SetAIBudgets).
+func (a *Admin) SetAIUsage(s *store.AIUsageStore) {
+	a.aiUsageStore = s
+}
+
+// aiUsageRanges are the report periods selectable on the usage page, in days.
+var aiUsageRanges = []int{7, 30, 90}
+
+// AIUsagePage renders the AI usage and cost report: totals for the
+// selected period and breakdowns by day, user, task, provider and model.
+func (a *Admin) AIUsagePage(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil {
+		for _, allowed := range aiUsageRanges {
+			if d == allowed {
+				days = d
+			}
+		}
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	var errMsg string
+	var totals models.AIUsageSummary
+	breakdowns := map[string][]models.AIUsageSummary{}
+	if a.aiUsageStore == nil {
+		errMsg = "AI usage tracking is not configured."
+	} else {
+		var err error
+		if totals, err = a.aiUsageStore.Totals(since); err != nil {
+			slog.Error("load ai usage totals failed", "error", err)
+			errMsg = "Could not load AI usage."
+		}
+		for _, dim := range []store.UsageDimension{store.UsageByDay, store.UsageByUser, store.UsageByTask, store.UsageByProvider, store.UsageByModel} {
+			items, err := a.aiUsageStore.Breakdown(dim, since)
+			if err != nil {
+				slog.Error("load ai usage breakdown failed", "dimension", dim, "error", err)
+				errMsg = "Could not load AI usage."
+				continue
+			}
+			breakdowns[string(dim)] = items
+		}
+	}
+
+	a.renderer.Page(w, r, "ai_usage", &render.PageData{
+		Title:   "AI Usage",
+		Section: "ai_usage",
+		Data: map[string]any{
+			"Days":       days,
+			"Ranges":     aiUsageRanges,
+			"Totals":     totals,
+			"ByDay":      breakdowns[string(store.UsageByDay)],
+			"ByUser":     breakdowns[string(store.UsageByUser)],
+			"ByTask":     breakdowns[string(store.UsageByTask)],
+			"ByProvider": breakdowns[string(store.UsageByProvider)],
+			"ByModel":    breakdowns[string(store.UsageByModel)],
+			"Warnings":   a.aiBudgetWarnings(),
+			"Error":      errMsg,
+		},
+	})
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_cache.go contains admin handlers for page cache management:
+// the cache observability page, per-page purge and warm, and cache
+// warmer progress.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"yaaicms/internal/cache"
+	"yaaicms/internal/cdn"
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+	"yaaicms/internal/render"
+)
+
+// cacheRow is one public URL on the cache page with its cache stats.
+type cacheRow struct {
+	Key   string // Cache key (slug or cache.HomepageKey())
+	Label string // Content title, or "Homepage"
+	Type  string // "homepage", "page", "post"
+	URL   string // Public path
+	Stats *cache.KeyStats
+}
+
+// SetCacheWarmer enables cache warming after bulk invalidations and the
+// warm progress widget on the dashboard.
+func (a *Admin) SetCacheWarmer(cw *CacheWarmer) {
+	a.warmer = cw
+}
+
+// SetPurger enables CDN purging after every page cache invalidation.
+func (a *Admin) SetPurger(p cdn.Purger) {
+	a.purger = p
+}
+
+// purgeCDN forwards an invalidation to the CDN if a purger is configured.
+// Best-effort: failures are logged and never block the admin action.
+func (a *Admin) purgeCDN(ctx context.Context, req cdn.Request) {
+	if a.purger == nil {
+		return
+	}
+	if err := a.purger.Purge(ctx, req); err != nil {
+		slog.Warn("cdn purge failed", "error", err)
+	}
+}
+
+// actorID returns the logged-in user's ID from the request context, the
+// user a background job runs for (see asActor), or nil for background
+// work with no user.
+func actorID(ctx context.Context) *uuid.UUID {
+	sess := middleware.SessionFromCtx(ctx)
+	if sess == nil {
+		if id, ok := ctx.Value(actorKey{}).(uuid.UUID); ok {
+			return &id
+		}
+		return nil
+	}
+	id := sess.UserID
+	return &id
+}
+
+// CachePage renders the cache observability page: overall and per-URL
+// hit ratios, cached key count and memory use, and recent invalidations
+// grouped by entity.
+func (a *Admin) CachePage(w http.ResponseWriter, r *http.Request) {
+	a.renderCachePage(w, r, "", "")
+}
+
+// renderCachePage renders the cache page with an optional notice or error
+// from a preceding purge/warm action.
+func (a *Admin) renderCachePage(w http.ResponseWriter, r *http.Request, notice, errMsg string) {
+	stats, err := a.pageCache.Stats(r.Context())
+	if err != nil {
+		slog.Error("load cache stats failed", "error", err)
+		stats = &cache.Stats{Keys: map[string]*cache.KeyStats{}}
+		if errMsg == "" {
+			errMsg = "Could not read cache statistics from Valkey."
+		}
+	}
+
+	rows := []cacheRow{{
+		Key:   cache.HomepageKey(),
+		Label: "Homepage",
+		Type:  "homepage",
+		URL:   "/",
+		Stats: stats.Key(cache.HomepageKey()),
+	}}
+	for _, ct := range []models.ContentType{models.ContentTypePage, models.ContentTypePost} {
+		items, err := a.contentStore.ListPublishedByType(ct)
+		if err != nil {
+			slog.Error("list published content failed", "error", err)
+			continue
+		}
+		for _, c := range items {
+			key := cache.SlugKey(c.Slug)
+			rows = append(rows, cacheRow{
+				Key:   key,
+				Label: c.Title,
+				Type:  string(ct),
+				URL:   "/" + c.Slug,
+				Stats: stats.Key(key),
+			})
+		}
+	}
+	if a.translations != nil {
+		items, err := a.translations.ListPublished()
+		if err != nil {
+			slog.Error("list published translations failed", "error", err)
+		}
+		locales, _ := a.translations.Locales()
+		for _, c := range items {
+			locale := locales[c.ID]
+			key := contentCacheKey(locale, c.Slug)
+			rows = append(rows, cacheRow{
+				Key:   key,
+				Label: c.Title,
+				Type:  string(c.Type) + " (" + locale + ")",
+				URL:   contentPath(locale, c.Slug),
+				Stats: stats.Key(key),
+			})
+		}
+	}
+
+	groups, err := a.cacheLog.RecentByEntity(25)
+	if err != nil {
+		slog.Error("load cache log failed", "error", err)
+	}
+
+	a.renderer.Page(w, r, "cache", &render.PageData{
+		Title:   "Cache",
+		Section: "cache",
+		Data: map[string]any{
+			"Stats":       stats,
+			"Rows":        rows,
+			"Groups":      groups,
+			"WarmEnabled": a.warmer != nil,
+			"Notice":      notice,
+			"Error":       errMsg,
+		},
+	})
+}
+
+// CachePurgeKey handles POST /admin/cache/keys/{key}/purge — removes one
+// page from the L2 cache and logs the purge against its entity.
+func (a *Admin) CachePurgeKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := chi.URLParam(r, "key")
+
+	n := a.pageCache.InvalidatePage(ctx, key)
+
+	if key == cache.HomepageKey() {
+		a.cacheLog.Log("homepage", uuid.Nil, "purge", actorID(ctx), n)
+		a.purgeCDN(ctx, cdn.Request{URLs: []string{"/"}, SurrogateKeys: []string{cdn.SurrogateKeyHomepage}})
+	} else {
+		locale, slug := cache.ParseSlugKey(key)
+		if c, err := a.contentStore.FindBySlug(slug); err == nil && c != nil {
+			a.cacheLog.Log("content", c.ID, "purge", actorID(ctx), n)
+		}
+		a.purgeCDN(ctx, cdn.Request{URLs: []string{contentPath(locale, slug)}, SurrogateKeys: []string{cdn.PageKey(slug)}})
+	}
+
+	notice := fmt.Sprintf("Purged %q from the cache.", key)
+	if n == 0 {
+		notice = fmt.Sprintf("%q was not cached.", key)
+	}
+	a.renderCachePage(w, r, notice, "")
+}
+
+// CacheWarmKey handles POST /admin/cache/keys/{key}/warm — renders one
+// page into the L2 cache immediately.
+func (a *Admin) CacheWarmKey(w http.ResponseWriter, r *http.Request) {
+	if a.warmer == nil {
+		http.Error(w, "Cache warming is not enabled", http.StatusNotFound)
+		return
+	}
+	key := chi.URLParam(r, "key")
+
+	if err := a.warmer.WarmKey(r.Context(), key); err != nil {
+		slog.Warn("cache warm key failed", "key", key, "error", err)
+		a.renderCachePage(w, r, "", fmt.Sprintf("Could not warm %q: %v", key, err))
+		return
+	}
+	a.renderCachePage(w, r, fmt.Sprintf("Warmed %q.", key), "")
+}
+
+// CacheResetStats handles POST /admin/cache/reset-stats — clears all
+// hit/miss counters.
+func (a *Admin) CacheResetStats(w http.ResponseWriter, r *http.Request) {
+	if err := a.pageCache.ResetStats(r.Context()); err != nil {
+		slog.Error("reset cache stats failed", "error", err)
+		a.renderCachePage(w, r, "", "Could not reset cache statistics.")
+		return
+	}
+	a.renderCachePage(w, r, "Hit/miss counters reset.", "")
+}
+
+// triggerWarm queues a warm run if a warmer is configured.
+func (a *Admin) triggerWarm(reason string) {
+	if a.warmer != nil {
+		a.warmer.Trigger(reason)
+	}
+}
+
+// CacheWarmStatus handles GET /admin/cache/warm — returns the warm
+// progress widget. The widget polls itself while a run is in progress.
+func (a *Admin) CacheWarmStatus(w http.ResponseWriter, r *http.Request) {
+	if a.warmer == nil {
+		http.Error(w, "Cache warming is not enabled", http.StatusNotFound)
+		return
+	}
+	writeWarmStatus(w, a.warmer.Status())
+}
+
+// CacheWarmStart handles POST /admin/cache/warm — queues a manual warm
+// run and returns the progress widget.
+func (a *Admin) CacheWarmStart(w http.ResponseWriter, r *http.Request) {
+	if a.warmer == nil {
+		http.Error(w, "Cache warming is not enabled", http.StatusNotFound)
+		return
+	}
+	a.warmer.Trigger(WarmReasonManual)
+
+	// Report the queued run as running so the widget starts polling
+	// even if the warmer loop has not picked it up yet.
+	st := a.warmer.Status()
+	if !st.Running {
+		st = WarmStatus{Running: true, Reason: WarmReasonManual}
+	}
+	writeWarmStatus(w, st)
+}
+
+// writeWarmStatus renders the warm progress widget as an HTML fragment.
+func writeWarmStatus(w http.ResponseWriter, st WarmStatus) {
+	var sb strings.Builder
+
+	sb.WriteString(`<div id="cache-warm-status" class="space-y-2"`)
+	if st.Running {
+		sb.WriteString(` hx-get="/admin/cache/warm" hx-trigger="every 2s" hx-swap="outerHTML"`)
+	}
+	sb.WriteString(`>`)
+
+	switch {
+	case st.Running:
+		sb.WriteString(fmt.Sprintf(
+			`<p class="text-sm text-gray-700">Warming %d of %d pages (%s)…</p>`,
+			st.Done+st.Failed, st.Total, html.EscapeString(st.Reason)))
+	case st.StartedAt.IsZero():
+		sb.WriteString(`<p class="text-sm text-gray-500">No warm run since startup.</p>`)
+	default:
+		sb.WriteString(fmt.Sprintf(
+			`<p class="text-sm text-gray-700">Last run (%s) warmed %d of %d pages in %s, finished %s.</p>`,
+			html.EscapeString(st.Reason), st.Done, st.Total,
+			st.FinishedAt.Sub(st.StartedAt).Round(time.Millisecond),
+			st.FinishedAt.Format("15:04:05")))
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		`<div class="w-full bg-gray-100 rounded-full h-2"><div class="bg-indigo-600 h-2 rounded-full" style="width: %d%%"></div></div>`,
+		st.Percent()))
+
+	if st.Failed > 0 {
+		sb.WriteString(fmt.Sprintf(
+			`<p class="text-xs text-red-600">%d pages failed to render and will be rendered on first visit.</p>`,
+			st.Failed))
+	}
+
+	if !st.Running {
+		sb.WriteString(`<button type="button" hx-post="/admin/cache/warm" hx-target="#cache-warm-status" hx-swap="outerHTML" `)
+		sb.WriteString(`class="inline-flex items-center rounded-md bg-white px-3 py-1.5 text-xs font-medium text-gray-700 shadow-sm ring-1 ring-gray-300 hover:bg-gray-50 transition-colors">`)
+		sb.WriteString(`Warm cache now</button>`)
+	}
+	sb.WriteString(`</div>`)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(sb.String()))
+}
@@ -5,14 +5,18 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
+	"yaaicms/internal/ai"
+	"yaaicms/internal/cdn"
 	"yaaicms/internal/models"
 )
 
@@ -50,6 +54,40 @@ func TestPostsList_Returns200(t *testing.T) {
 	}
 }
 
+func TestPostsList_SearchHighlightsMatches(t *testing.T) {
+	env := newTestEnv(t)
+
+	word := "zq" + uuid.New().String()[:8]
+	matchSlug := "test-list-search-" + uuid.New().String()[:8]
+	otherSlug := "test-list-other-" + uuid.New().String()[:8]
+	t.Cleanup(func() { cleanContent(t, env.DB, matchSlug, otherSlug) })
+
+	authorID := testAuthorID(t, env.DB)
+	for _, c := range []*models.Content{
+		{Title: "Found", Slug: matchSlug, Body: "All about " + word + "."},
+		{Title: "Other", Slug: otherSlug, Body: "Unrelated."},
+	} {
+		c.Type = models.ContentTypePost
+		c.Status = models.ContentStatusDraft
+		c.AuthorID = authorID
+		if _, err := env.ContentStore.Create(c); err != nil {
+			t.Fatalf("create content: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/posts?status=draft&q="+word, nil)
+	rec := httptest.NewRecorder()
+	env.Admin.PostsList(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, matchSlug) || strings.Contains(body, otherSlug) {
+		t.Errorf("PostsList: filtered rows wrong")
+	}
+	if !strings.Contains(body, "<mark>"+word+"</mark>") {
+		t.Errorf("PostsList: snippet not highlighted")
+	}
+}
+
 func TestPostNew_Returns200(t *testing.T) {
 	env := newTestEnv(t)
 
@@ -992,3 +1030,61 @@ func createTestTemplate(t *testing.T, env *testEnv, name, tmplType, htmlContent
 	return created
 }
 
+// --- Cache ---
+
+func TestCachePurgeKey_PurgesCDN(t *testing.T) {
+	env := newTestEnv(t)
+	purger := &cdn.RecordingPurger{}
+	env.Admin.SetPurger(purger)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache/keys/about/purge", nil)
+	req = withChiURLParam(req, "key", "about")
+
+	rec := httptest.NewRecorder()
+	env.Admin.CachePurgeKey(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("CachePurgeKey: got status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	calls := purger.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 CDN purge, got %d", len(calls))
+	}
+	if len(calls[0].URLs) != 1 || calls[0].URLs[0] != "/about" {
+		t.Errorf("URLs: got %v, want [/about]", calls[0].URLs)
+	}
+	if len(calls[0].SurrogateKeys) != 1 || calls[0].SurrogateKeys[0] != cdn.PageKey("about") {
+		t.Errorf("SurrogateKeys: got %v, want [%s]", calls[0].SurrogateKeys, cdn.PageKey("about"))
+	}
+}
+
+// --- AI usage ---
+
+func TestAIUsagePage_ShowsRecordedUsage(t *testing.T) {
+	env := newTestEnv(t)
+	t.Cleanup(func() {
+		env.DB.Exec("DELETE FROM ai_usage WHERE provider = 'usage-test'")
+	})
+
+	recorder := NewUsageRecorder(env.AIUsage, ai.PriceTable{"usage-model": {Input: 1, Output: 2}})
+	recorder.RecordUsage(context.Background(), ai.UsageRecord{
+		Task:  ai.TaskContent,
+		Usage: ai.Usage{Provider: "usage-test", Model: "usage-model", InputTokens: 1000, OutputTokens: 500, Latency: 250 * time.Millisecond},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/ai-usage?days=7", nil)
+	rec := httptest.NewRecorder()
+	env.Admin.AIUsagePage(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("AIUsagePage: got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "usage-test/usage-model") {
+		t.Error("expected the model breakdown to list usage-test/usage-model")
+	}
+	if !strings.Contains(body, "last 7 days") {
+		t.Error("expected the selected range to be shown")
+	}
+}
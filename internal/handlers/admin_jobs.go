@@ -0,0 +1,375 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_jobs.go contains the background AI job handlers (revision metadata,
+// image generation) and the job status page, polling fragment and actions.
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/jobs"
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+	"yaaicms/internal/render"
+	"yaaicms/internal/session"
+)
+
+// Background job kinds.
+const (
+	jobRevisionMeta  = "revision_meta"
+	jobImageGenerate = "image_generate"
+)
+
+// Views of the job status fragment, picked with ?view= on the status URL.
+const (
+	jobViewDefault = ""
+	jobViewImage   = "image" // Shows the generated image with "Use as Featured Image"
+)
+
+// jobsPageLimit is how many recent jobs the jobs page lists.
+const jobsPageLimit = 100
+
+// SetJobQueue enables background AI jobs and registers their handlers.
+// Call before the queue is started.
+func (a *Admin) SetJobQueue(q *jobs.Queue) {
+	a.jobs = q
+	q.Register(jobRevisionMeta, asActor(a.auditJob(jobRevisionMeta, a.runRevisionMetaJob)))
+	q.Register(jobImageGenerate, asActor(a.auditJob(jobImageGenerate, a.runImageJob)))
+	q.Register(jobBulkItem, asActor(a.auditJob(jobBulkItem, a.runBulkItemJob)))
+	q.Register(jobMediaDescribe, asActor(a.auditJob(jobMediaDescribe, a.runDescribeJob)))
+	q.Register(jobContentEmbed, asActor(a.runEmbedJob))
+	q.Register(jobEmbedBackfill, a.runEmbedBackfillJob)
+}
+
+// actorKey is the context key for the user a background job runs for.
+type actorKey struct{}
+
+// asActor wraps h so the job runs as the user who queued it: actorID
+// returns that user, so AI usage and cache logs are attributed to them.
+func asActor(h jobs.Handler) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job) (any, error) {
+		if job.CreatedBy != nil {
+			ctx = context.WithValue(ctx, actorKey{}, *job.CreatedBy)
+		}
+		return h(ctx, job)
+	}
+}
+
+// revisionMetaJob is the payload of a revision_meta job: the prompts for
+// one revision's title and changelog and the values to fall back to.
+type revisionMetaJob struct {
+	RevisionID   uuid.UUID `json:"revision_id"`
+	Template     bool      `json:"template,omitempty"`
+	SystemPrompt string    `json:"system_prompt"`
+	Prompt       string    `json:"prompt"`
+	UserTitle    string    `json:"user_title,omitempty"`
+	DefaultTitle string    `json:"default_title"`
+	DiffSummary  string    `json:"diff_summary"`
+}
+
+// enqueueRevisionMeta queues a revision_meta job. Failures are logged; the
+// revision keeps the title the user gave it.
+func (a *Admin) enqueueRevisionMeta(ctx context.Context, p revisionMetaJob) {
+	if a.jobs == nil {
+		return
+	}
+	if _, err := a.jobs.Enqueue(jobRevisionMeta, p, jobs.Options{CreatedBy: actorID(ctx)}); err != nil {
+		slog.Error("enqueue revision meta job failed", "revision", p.RevisionID, "error", err)
+	}
+}
+
+// runRevisionMetaJob generates and stores a revision's title and changelog.
+// When the AI call fails the fallback title and changelog are stored, so
+// the revision is never left untitled, and the job is retried.
+func (a *Admin) runRevisionMetaJob(ctx context.Context, job *jobs.Job) (any, error) {
+	var p revisionMetaJob
+	if err := job.Decode(&p); err != nil {
+		return nil, err
+	}
+
+	title, changelog, aiErr := a.revisionMeta(ctx, p)
+
+	var err error
+	if p.Template {
+		err = a.templateRevisionStore.UpdateMeta(p.RevisionID, title, changelog)
+	} else {
+		err = a.revisionStore.UpdateMeta(p.RevisionID, title, changelog)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update revision meta: %w", err)
+	}
+	if aiErr != nil {
+		return nil, aiErr
+	}
+	return map[string]string{"title": title}, nil
+}
+
+// imageJob is the payload of an image_generate job. Jobs queued before
+// the options existed have no mode and generate from the prompt.
+type imageJob struct {
+	Prompt         string                `json:"prompt"`
+	Provider       string                `json:"provider,omitempty"`
+	Mode           models.GenerationMode `json:"mode,omitempty"`
+	SourceID       *uuid.UUID            `json:"source_id,omitempty"` // The image to edit
+	Aspect         string                `json:"aspect,omitempty"`
+	Count          int                   `json:"count,omitempty"`
+	Style          string                `json:"style,omitempty"`
+	NegativePrompt string                `json:"negative_prompt,omitempty"`
+	Seed           int64                 `json:"seed,omitempty"`
+}
+
+// options returns the job's image options.
+func (p imageJob) options() ai.ImageOptions {
+	return ai.ImageOptions{Aspect: p.Aspect, Count: p.Count, Style: p.Style, NegativePrompt: p.NegativePrompt, Seed: p.Seed}
+}
+
+// runImageJob generates images into the media library. The result is an
+// imageJobResult. Errors retrying can't fix (bad key, rejected prompt,
+// missing source image) fail the job at once.
+func (a *Admin) runImageJob(ctx context.Context, job *jobs.Job) (any, error) {
+	var p imageJob
+	if err := job.Decode(&p); err != nil {
+		return nil, err
+	}
+	if job.CreatedBy == nil {
+		return nil, jobs.Permanent(fmt.Errorf("image job has no uploader"))
+	}
+	if p.Mode == "" {
+		p.Mode = models.GenerationGenerate
+	}
+	if p.Mode != models.GenerationGenerate && p.SourceID == nil {
+		return nil, jobs.Permanent(fmt.Errorf("image %s job has no source image", p.Mode))
+	}
+
+	images, err := a.createAIImages(ctx, p, *job.CreatedBy)
+	if err != nil {
+		if c := ai.Classify(err); c == ai.ErrorFatal || c == ai.ErrorAuth {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no images were generated")
+	}
+	return imageJobResult{aiImage: images[0], Images: images}, nil
+}
+
+// jobResponse is the JSON form of a job, with its result decoded.
+type jobResponse struct {
+	*models.AIJob
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// findJob loads the job named by the {id} URL parameter and checks that
+// the user may see it: admins see every job, others only their own.
+// Writes the error response and returns nil if not.
+func (a *Admin) findJob(w http.ResponseWriter, r *http.Request) *models.AIJob {
+	if a.jobs == nil {
+		http.Error(w, "Background jobs are not configured", http.StatusNotFound)
+		return nil
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return nil
+	}
+	job, err := a.jobs.Find(id)
+	if err != nil {
+		slog.Error("find ai job failed", "id", id, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil
+	}
+	sess := middleware.SessionFromCtx(r.Context())
+	if job == nil || !canSeeJob(sess, job) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil
+	}
+	return job
+}
+
+// canSeeJob reports whether the session's user may see and act on job.
+func canSeeJob(sess *session.Data, job *models.AIJob) bool {
+	if sess == nil {
+		return false
+	}
+	if sess.Role == string(models.RoleAdmin) {
+		return true
+	}
+	return job.CreatedBy != nil && *job.CreatedBy == sess.UserID
+}
+
+// JobsPage lists recent background jobs: every job for admins, the user's
+// own jobs for everyone else.
+func (a *Admin) JobsPage(w http.ResponseWriter, r *http.Request) {
+	a.renderJobsPage(w, r, "", "")
+}
+
+// renderJobsPage renders the jobs page with an optional notice or error
+// from a preceding cancel/retry action.
+func (a *Admin) renderJobsPage(w http.ResponseWriter, r *http.Request, notice, errMsg string) {
+	sess := middleware.SessionFromCtx(r.Context())
+
+	var list []models.AIJob
+	if a.jobs == nil {
+		errMsg = "Background jobs are not configured."
+	} else {
+		var createdBy *uuid.UUID
+		if sess.Role != string(models.RoleAdmin) {
+			createdBy = &sess.UserID
+		}
+		var err error
+		if list, err = a.jobs.List(createdBy, jobsPageLimit); err != nil {
+			slog.Error("list ai jobs failed", "error", err)
+			errMsg = "Failed to load jobs."
+		}
+	}
+
+	running := false
+	for _, j := range list {
+		if !j.Status.Finished() {
+			running = true
+			break
+		}
+	}
+
+	a.renderer.Page(w, r, "ai_jobs", &render.PageData{
+		Title:   "Background Jobs",
+		Section: "jobs",
+		Data: map[string]any{
+			"Jobs":    list,
+			"Running": running,
+			"Notice":  notice,
+			"Error":   errMsg,
+		},
+	})
+}
+
+// JobStatus returns a job as JSON when the client asks for it, otherwise
+// as an HTML fragment that polls itself until the job has finished.
+func (a *Admin) JobStatus(w http.ResponseWriter, r *http.Request) {
+	job := a.findJob(w, r)
+	if job == nil {
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		resp := jobResponse{AIJob: job}
+		if job.Result != "" {
+			resp.Result = json.RawMessage(job.Result)
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	writeJobStatus(w, job, r.URL.Query().Get("view"))
+}
+
+// JobCancel cancels a pending job.
+func (a *Admin) JobCancel(w http.ResponseWriter, r *http.Request) {
+	job := a.findJob(w, r)
+	if job == nil {
+		return
+	}
+	ok, err := a.jobs.Cancel(job.ID)
+	switch {
+	case err != nil:
+		slog.Error("cancel ai job failed", "id", job.ID, "error", err)
+		a.renderJobsPage(w, r, "", "Failed to cancel the job.")
+	case !ok:
+		a.renderJobsPage(w, r, "", "The job has already started and can no longer be cancelled.")
+	default:
+		a.renderJobsPage(w, r, "Job cancelled.", "")
+	}
+}
+
+// JobRetry runs a failed or cancelled job again.
+func (a *Admin) JobRetry(w http.ResponseWriter, r *http.Request) {
+	job := a.findJob(w, r)
+	if job == nil {
+		return
+	}
+	ok, err := a.jobs.Resubmit(job.ID)
+	switch {
+	case err != nil:
+		slog.Error("retry ai job failed", "id", job.ID, "error", err)
+		a.renderJobsPage(w, r, "", "Failed to retry the job.")
+	case !ok:
+		a.renderJobsPage(w, r, "", "Only failed or cancelled jobs can be retried.")
+	default:
+		a.renderJobsPage(w, r, "Job queued again.", "")
+	}
+}
+
+// writeJobStatus renders a job's status fragment. While the job is pending
+// or running the fragment polls /admin/jobs/{id} every 2s and replaces
+// itself; view picks how a finished job is shown.
+func writeJobStatus(w http.ResponseWriter, job *models.AIJob, view string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	switch job.Status {
+	case models.JobSucceeded:
+		if view == jobViewImage {
+			var res imageJobResult
+			if err := json.Unmarshal([]byte(job.Result), &res); err == nil {
+				// Results from before multiple images have no list.
+				if len(res.Images) == 0 {
+					res.Images = []aiImage{res.aiImage}
+				}
+				w.Write([]byte(`<div class="space-y-4">`))
+				for i := range res.Images {
+					w.Write([]byte(imageResultFragment(&res.Images[i])))
+				}
+				w.Write([]byte(`</div>`))
+				return
+			}
+		}
+		fmt.Fprint(w, `<p class="text-xs text-green-700 bg-green-50 rounded p-2">Done.</p>`)
+		return
+	case models.JobFailed:
+		msg := job.Error
+		if view == jobViewImage {
+			msg = "Image generation failed. Check your provider configuration and API limits."
+		}
+		writeAIError(w, msg)
+		return
+	case models.JobCancelled:
+		writeAIError(w, "The job was cancelled.")
+		return
+	}
+
+	statusURL := "/admin/jobs/" + job.ID.String()
+	if view != jobViewDefault {
+		statusURL += "?view=" + view
+	}
+
+	label := "Queued…"
+	switch {
+	case job.Status == models.JobRunning && job.ProgressTotal > 0:
+		label = fmt.Sprintf("Running… %d of %d done", job.ProgressDone, job.ProgressTotal)
+	case job.Status == models.JobRunning:
+		label = "Running (this may take a moment)…"
+	case job.Attempts > 0:
+		label = fmt.Sprintf("Retrying after attempt %d of %d failed…", job.Attempts, job.MaxAttempts)
+	}
+
+	fmt.Fprintf(w,
+		`<div hx-get="%s" hx-trigger="every 2s" hx-swap="outerHTML" class="flex items-center gap-2">
+			<svg class="animate-spin h-3 w-3 text-purple-500" fill="none" viewBox="0 0 24 24">
+				<circle class="opacity-25" cx="12" cy="12" r="10" stroke="currentColor" stroke-width="4"></circle>
+				<path class="opacity-75" fill="currentColor" d="M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4z"></path>
+			</svg>
+			<span class="text-xs text-purple-600">%s</span>
+		</div>`,
+		html.EscapeString(statusURL), html.EscapeString(label))
+}
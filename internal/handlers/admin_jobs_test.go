@@ -0,0 +1,142 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/jobs"
+	"yaaicms/internal/models"
+	"yaaicms/internal/session"
+)
+
+func TestWriteJobStatus(t *testing.T) {
+	id := uuid.New()
+	img := aiImage{ID: uuid.New(), URL: "https://cdn.test/a.png", ThumbURL: "https://cdn.test/a-thumb.webp", AltText: `a "cat"`}
+	result, _ := json.Marshal(img)
+	second := aiImage{ID: uuid.New(), URL: "https://cdn.test/b.png", ThumbURL: "https://cdn.test/b-thumb.webp"}
+	several, _ := json.Marshal(imageJobResult{aiImage: img, Images: []aiImage{img, second}})
+
+	tests := []struct {
+		name    string
+		job     models.AIJob
+		view    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "pending polls",
+			job:  models.AIJob{ID: id, Status: models.JobPending},
+			view: jobViewImage,
+			want: []string{`hx-get="/admin/jobs/` + id.String() + `?view=image"`, `hx-trigger="every 2s"`, "Queued"},
+		},
+		{
+			name: "running shows progress",
+			job:  models.AIJob{ID: id, Status: models.JobRunning, ProgressDone: 2, ProgressTotal: 5},
+			want: []string{`hx-get="/admin/jobs/` + id.String() + `"`, "2 of 5 done"},
+		},
+		{
+			name: "retrying",
+			job:  models.AIJob{ID: id, Status: models.JobPending, Attempts: 1, MaxAttempts: 3},
+			want: []string{"attempt 1 of 3"},
+		},
+		{
+			name:    "succeeded image",
+			job:     models.AIJob{ID: id, Status: models.JobSucceeded, Result: string(result)},
+			view:    jobViewImage,
+			want:    []string{img.ThumbURL, img.ID.String(), "Use as Featured Image", "a &#34;cat&#34;"},
+			notWant: []string{"hx-trigger"},
+		},
+		{
+			name:    "succeeded with several images",
+			job:     models.AIJob{ID: id, Status: models.JobSucceeded, Result: string(several)},
+			view:    jobViewImage,
+			want:    []string{img.ID.String(), second.ID.String(), second.ThumbURL},
+			notWant: []string{"hx-trigger"},
+		},
+		{
+			name:    "failed image hides the provider error",
+			job:     models.AIJob{ID: id, Status: models.JobFailed, Error: "openai: 401 invalid key sk-..."},
+			view:    jobViewImage,
+			want:    []string{"Image generation failed"},
+			notWant: []string{"sk-", "hx-trigger"},
+		},
+		{
+			name: "failed",
+			job:  models.AIJob{ID: id, Status: models.JobFailed, Error: "<boom>"},
+			want: []string{"&lt;boom&gt;"},
+		},
+		{
+			name:    "cancelled",
+			job:     models.AIJob{ID: id, Status: models.JobCancelled},
+			want:    []string{"cancelled"},
+			notWant: []string{"hx-trigger"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeJobStatus(w, &tt.job, tt.view)
+			body := w.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("missing %q in %s", s, body)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(body, s) {
+					t.Errorf("unexpected %q in %s", s, body)
+				}
+			}
+		})
+	}
+}
+
+func TestCanSeeJob(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	job := &models.AIJob{CreatedBy: &owner}
+
+	if !canSeeJob(&session.Data{UserID: owner, Role: string(models.RoleAuthor)}, job) {
+		t.Error("owner can't see their job")
+	}
+	if canSeeJob(&session.Data{UserID: other, Role: string(models.RoleEditor)}, job) {
+		t.Error("another editor can see the job")
+	}
+	if !canSeeJob(&session.Data{UserID: other, Role: string(models.RoleAdmin)}, job) {
+		t.Error("admin can't see the job")
+	}
+	if canSeeJob(&session.Data{UserID: other, Role: string(models.RoleAuthor)}, &models.AIJob{}) {
+		t.Error("non-admin can see a system job")
+	}
+	if canSeeJob(nil, job) {
+		t.Error("no session can see the job")
+	}
+}
+
+func TestAsActor(t *testing.T) {
+	user := uuid.New()
+	var got *uuid.UUID
+	h := asActor(func(ctx context.Context, job *jobs.Job) (any, error) {
+		got = actorID(ctx)
+		return nil, nil
+	})
+
+	h(context.Background(), &jobs.Job{AIJob: &models.AIJob{CreatedBy: &user}})
+	if got == nil || *got != user {
+		t.Errorf("actorID: got %v, want %s", got, user)
+	}
+
+	h(context.Background(), &jobs.Job{AIJob: &models.AIJob{}})
+	if got != nil {
+		t.Errorf("actorID without creator: got %v", got)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"yaaicms/internal/ai"
 	"yaaicms/internal/imaging"
 	"yaaicms/internal/middleware"
 	"yaaicms/internal/models"
@@ -68,18 +69,26 @@ func (a *Admin) MediaLibrary(w http.ResponseWriter, r *http.Request) {
 	// Build URLs for each media item.
 	type mediaView struct {
 		models.Media
-		URL      string
-		ThumbURL string
+		URL            string
+		ThumbURL       string
+		Editable       bool   // AI can edit it or make variations
+		GenerationJSON string // Media.Generation as JSON, for the generate dialog
 	}
+	canGenerate := a.aiRegistry != nil && a.aiRegistry.SupportsImageGeneration()
+	canEdit := a.aiRegistry != nil && a.aiRegistry.SupportsImageEditing()
 	var views []mediaView
 	for _, m := range items {
-		mv := mediaView{Media: m}
+		mv := mediaView{Media: m, Editable: canEdit && variantTypes[m.ContentType]}
 		if m.Bucket == a.storageClient.PublicBucket() {
 			mv.URL = a.storageClient.FileURL(m.S3Key)
 			if m.ThumbS3Key != nil {
 				mv.ThumbURL = a.storageClient.FileURL(*m.ThumbS3Key)
 			}
 		}
+		if m.Generation != nil {
+			b, _ := json.Marshal(m.Generation)
+			mv.GenerationJSON = string(b)
+		}
 		views = append(views, mv)
 	}
 
@@ -87,8 +96,12 @@ func (a *Admin) MediaLibrary(w http.ResponseWriter, r *http.Request) {
 		Title:   "Media Library",
 		Section: "media",
 		Data: map[string]any{
-			"Items":     views,
-			"NoStorage": false,
+			"Items":       views,
+			"NoStorage":   false,
+			"CanGenerate": canGenerate,
+			"CanEdit":     canEdit,
+			"Aspects":     ai.ImageAspects,
+			"MaxImages":   ai.MaxImages,
 		},
 	})
 }
@@ -257,6 +270,9 @@ func (a *Admin) MediaUpload(w http.ResponseWriter, r *http.Request) {
 	// Store variant records now that we have the media ID.
 	a.saveVariants(created.ID, pendingVariants)
 
+	// Suggest alt text and a caption for images uploaded without alt text.
+	a.describeAfterUpload(ctx, created)
+
 	// Build response URL.
 	url := a.storageClient.FileURL(created.S3Key)
 	var thumbURL string
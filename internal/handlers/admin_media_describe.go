@@ -0,0 +1,489 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_media_describe.go contains AI-suggested alt text and captions for
+// images: the media_describe job, queued after upload or in bulk, and the
+// review queue where editors accept, edit or reject the suggestions.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/jobs"
+	"yaaicms/internal/middleware"
+	"yaaicms/internal/models"
+	"yaaicms/internal/prompts"
+	"yaaicms/internal/render"
+	"yaaicms/internal/store"
+)
+
+// jobMediaDescribe is the kind of the job that describes one image.
+const jobMediaDescribe = "media_describe"
+
+const (
+	// describeMaxBytes is the largest image sent to a vision model. Larger
+	// originals are only described through one of their variants.
+	describeMaxBytes = 5 << 20
+
+	// describeMissingLimit is how many images one "describe all" click
+	// queues. Clicking again queues the next batch.
+	describeMissingLimit = 200
+
+	// describeReviewLimit is how many descriptions the review page lists
+	// per status.
+	describeReviewLimit = 100
+
+	// maxAltTextLen and maxCaptionLen cap the suggested text. Screen
+	// readers read alt text in one go, so it is kept short.
+	maxAltTextLen = 250
+	maxCaptionLen = 500
+)
+
+// describableTypes are the image types vision models accept. SVG and PDF
+// are left out. Keep in sync with the list in store/media_description.go.
+var describableTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/gif":  true,
+}
+
+// describeVariantOrder is the order variants are tried in: md is detailed
+// enough to describe and small enough to send cheaply.
+var describeVariantOrder = []string{"md", "sm", "lg", "thumb"}
+
+// imageDescription is the structured reply of a describe request.
+type imageDescription struct {
+	AltText string `json:"alt_text" desc:"Concise alt text for screen readers: what the image shows, one sentence, no 'image of'"`
+	Caption string `json:"caption" desc:"One or two sentence caption to display under the image"`
+}
+
+var imageDescriptionSchema = ai.MustSchema("image_description", imageDescription{})
+
+// mediaDescribeJob is the payload of a media_describe job.
+type mediaDescribeJob struct {
+	MediaID uuid.UUID `json:"media_id"`
+}
+
+// SetMediaDescriptions enables AI alt text and caption suggestions. They
+// are generated by background jobs, so SetJobQueue is needed too.
+func (a *Admin) SetMediaDescriptions(s *store.MediaDescriptionStore) {
+	a.mediaDescriptions = s
+}
+
+// canDescribe reports whether images can be described: jobs are running
+// and a provider accepts images.
+func (a *Admin) canDescribe() bool {
+	return a.jobs != nil && a.mediaDescriptions != nil &&
+		a.aiRegistry != nil && a.aiRegistry.SupportsVision()
+}
+
+// enqueueDescribe queues a description of a media item. Returns false
+// without error if one is already queued.
+func (a *Admin) enqueueDescribe(ctx context.Context, mediaID uuid.UUID) (bool, error) {
+	queued, err := a.mediaDescriptions.Queue(mediaID)
+	if err != nil || !queued {
+		return false, err
+	}
+	job, err := a.jobs.Enqueue(jobMediaDescribe, mediaDescribeJob{MediaID: mediaID}, jobs.Options{CreatedBy: actorID(ctx)})
+	if err != nil {
+		if ferr := a.mediaDescriptions.Fail(ctx, mediaID, "could not queue the job"); ferr != nil {
+			slog.Error("fail media description failed", "media", mediaID, "error", ferr)
+		}
+		return false, err
+	}
+	if err := a.mediaDescriptions.SetJob(mediaID, job.ID); err != nil {
+		slog.Warn("set media description job failed", "media", mediaID, "error", err)
+	}
+	return true, nil
+}
+
+// describeAfterUpload queues a description of a freshly uploaded image
+// that was given no alt text. Failures are logged; the upload succeeded.
+func (a *Admin) describeAfterUpload(ctx context.Context, m *models.Media) {
+	if !describableTypes[m.ContentType] || ptrStr(m.AltText) != "" || !a.canDescribe() {
+		return
+	}
+	if _, err := a.enqueueDescribe(ctx, m.ID); err != nil {
+		slog.Error("enqueue media describe job failed", "media", m.ID, "error", err)
+	}
+}
+
+// runDescribeJob describes one image and stores the suggestion for review.
+// When the job gives up the description is marked failed, so it shows up
+// in the review queue with a retry button.
+func (a *Admin) runDescribeJob(ctx context.Context, job *jobs.Job) (any, error) {
+	var p mediaDescribeJob
+	if err := job.Decode(&p); err != nil {
+		return nil, err
+	}
+	d, err := a.mediaDescriptions.Find(p.MediaID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.Status != models.DescriptionQueued {
+		// Deleted with its media, or queued again by a later job.
+		return nil, nil
+	}
+
+	desc, err := a.describeMedia(ctx, job, p.MediaID)
+	if err != nil {
+		if jobs.IsPermanent(err) || job.LastAttempt() {
+			if ferr := a.mediaDescriptions.Fail(ctx, p.MediaID, err.Error()); ferr != nil {
+				slog.Error("fail media description failed", "media", p.MediaID, "error", ferr)
+			}
+		}
+		return nil, err
+	}
+
+	if err := a.mediaDescriptions.Propose(ctx, p.MediaID, desc.AltText, desc.Caption); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// describeMedia downloads an image and asks a vision model to describe it.
+func (a *Admin) describeMedia(ctx context.Context, job *jobs.Job, mediaID uuid.UUID) (*imageDescription, error) {
+	if job.CreatedBy != nil {
+		if u, err := a.userStore.FindByID(*job.CreatedBy); err == nil && u != nil {
+			if msg := a.userBudgetExceeded(u.ID, u.Role); msg != "" {
+				return nil, jobs.Permanent(errors.New(msg))
+			}
+		}
+	}
+	if a.storageClient == nil {
+		return nil, jobs.Permanent(errors.New("object storage is not configured"))
+	}
+
+	m, err := a.mediaStore.FindByID(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, jobs.Permanent(errors.New("the image was deleted"))
+	}
+	variants, err := a.variantStore.FindByMediaID(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	key, mimeType, ok := describeSource(m, variants)
+	if !ok {
+		return nil, jobs.Permanent(fmt.Errorf("no variant of %s is small enough to describe", m.OriginalName))
+	}
+	data, err := a.storageClient.Download(ctx, m.Bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("download image: %w", err)
+	}
+
+	var out imageDescription
+	_, err = a.aiRegistry.DescribeImage(ctx, imageDescriptionSchema,
+		a.systemPrompt(prompts.ImageDescribe, nil),
+		describePrompt(m.OriginalName),
+		ai.Image{Data: data, MIMEType: mimeType}, &out)
+	if err != nil {
+		if c := ai.Classify(err); c == ai.ErrorFatal || c == ai.ErrorAuth {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	cleanDescription(&out)
+	if out.AltText == "" {
+		return nil, errors.New("the model returned no alt text")
+	}
+	return &out, nil
+}
+
+// describeSource picks the stored file to send to the vision model: the
+// first variant in describeVariantOrder, else the original when it is
+// small enough. Returns the storage key and its MIME type.
+func describeSource(m *models.Media, variants []models.MediaVariant) (key, mimeType string, ok bool) {
+	for _, name := range describeVariantOrder {
+		for _, v := range variants {
+			if v.Name == name {
+				return v.S3Key, v.ContentType, true
+			}
+		}
+	}
+	if describableTypes[m.ContentType] && m.SizeBytes <= describeMaxBytes {
+		return m.S3Key, m.ContentType, true
+	}
+	return "", "", false
+}
+
+// describePrompt asks for a description, giving the filename as a hint
+// when it looks meaningful.
+func describePrompt(filename string) string {
+	prompt := "Describe this image."
+	name := strings.TrimSuffix(filename, filepath.Ext(filename))
+	if name != "" && !looksGenerated(name) {
+		prompt += fmt.Sprintf(" Its file is named %q, which may hint at the subject.", filename)
+	}
+	return prompt
+}
+
+// looksGenerated reports whether a file name carries no meaning, like
+// "IMG_2041", "DSC01234" or a UUID.
+func looksGenerated(name string) bool {
+	letters := 0
+	for _, r := range name {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			letters++
+		}
+	}
+	if letters <= 4 {
+		return true
+	}
+	_, err := uuid.Parse(name)
+	return err == nil
+}
+
+// cleanDescription trims and caps a suggestion.
+func cleanDescription(d *imageDescription) {
+	d.AltText = capRunes(strings.Trim(strings.TrimSpace(d.AltText), `"`), maxAltTextLen)
+	d.Caption = capRunes(strings.Trim(strings.TrimSpace(d.Caption), `"`), maxCaptionLen)
+}
+
+// capRunes cuts s to at most n runes.
+func capRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:n]))
+}
+
+// descriptionView is a description with its thumbnail URL.
+type descriptionView struct {
+	models.MediaDescription
+	ThumbURL string
+}
+
+// MediaDescriptionsPage renders the review queue of suggested alt text
+// and captions.
+func (a *Admin) MediaDescriptionsPage(w http.ResponseWriter, r *http.Request) {
+	a.renderMediaDescriptions(w, r, "", "")
+}
+
+// renderMediaDescriptions renders the review queue with an optional notice
+// or error from a preceding action.
+func (a *Admin) renderMediaDescriptions(w http.ResponseWriter, r *http.Request, notice, errMsg string) {
+	data := map[string]any{
+		"Enabled": a.canDescribe(),
+		"Notice":  notice,
+		"Error":   errMsg,
+	}
+	if a.mediaDescriptions == nil {
+		data["Error"] = "Image descriptions are not configured."
+		a.renderer.Page(w, r, "media_descriptions", &render.PageData{
+			Title: "Alt Text Review", Section: "media", Data: data,
+		})
+		return
+	}
+
+	counts, err := a.mediaDescriptions.Counts()
+	if err != nil {
+		slog.Error("count media descriptions failed", "error", err)
+	}
+	proposed, err := a.mediaDescriptions.ListByStatus(models.DescriptionProposed, describeReviewLimit)
+	if err != nil {
+		slog.Error("list media descriptions failed", "error", err)
+		data["Error"] = "Failed to load descriptions."
+	}
+	failed, err := a.mediaDescriptions.ListByStatus(models.DescriptionFailed, describeReviewLimit)
+	if err != nil {
+		slog.Error("list media descriptions failed", "error", err)
+	}
+
+	data["Queued"] = counts[models.DescriptionQueued]
+	data["ProposedCount"] = counts[models.DescriptionProposed]
+	data["Accepted"] = counts[models.DescriptionAccepted]
+	data["Proposed"] = a.descriptionViews(proposed)
+	data["Failed"] = a.descriptionViews(failed)
+	a.renderer.Page(w, r, "media_descriptions", &render.PageData{
+		Title:   "Alt Text Review",
+		Section: "media",
+		Data:    data,
+	})
+}
+
+// descriptionViews adds thumbnail URLs to descriptions of public images.
+func (a *Admin) descriptionViews(list []models.MediaDescription) []descriptionView {
+	views := make([]descriptionView, len(list))
+	for i, d := range list {
+		views[i] = descriptionView{MediaDescription: d}
+		if a.storageClient != nil && d.Bucket == a.storageClient.PublicBucket() {
+			key := d.S3Key
+			if d.ThumbS3Key != nil {
+				key = *d.ThumbS3Key
+			}
+			views[i].ThumbURL = a.storageClient.FileURL(key)
+		}
+	}
+	return views
+}
+
+// MediaDescribeMissing queues descriptions of images without alt text.
+func (a *Admin) MediaDescribeMissing(w http.ResponseWriter, r *http.Request) {
+	if !a.canDescribe() {
+		a.renderMediaDescriptions(w, r, "", "No configured AI provider can describe images.")
+		return
+	}
+	if msg := a.aiBudgetExceeded(r); msg != "" {
+		a.renderMediaDescriptions(w, r, "", msg)
+		return
+	}
+
+	ids, err := a.mediaDescriptions.ListMissingAlt(describeMissingLimit)
+	if err != nil {
+		slog.Error("list media missing alt text failed", "error", err)
+		a.renderMediaDescriptions(w, r, "", "Failed to load images.")
+		return
+	}
+	queued := 0
+	for _, id := range ids {
+		ok, err := a.enqueueDescribe(r.Context(), id)
+		if err != nil {
+			slog.Error("enqueue media describe job failed", "media", id, "error", err)
+			continue
+		}
+		if ok {
+			queued++
+		}
+	}
+
+	notice := "Every image already has alt text or a pending suggestion."
+	if queued > 0 {
+		notice = fmt.Sprintf("Queued %d images. Suggestions appear here as they are ready.", queued)
+	}
+	a.renderMediaDescriptions(w, r, notice, "")
+}
+
+// MediaDescribe queues a description of one image, e.g. to retry one
+// that failed or was rejected.
+func (a *Admin) MediaDescribe(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	if !a.canDescribe() {
+		a.renderMediaDescriptions(w, r, "", "No configured AI provider can describe images.")
+		return
+	}
+	if msg := a.aiBudgetExceeded(r); msg != "" {
+		a.renderMediaDescriptions(w, r, "", msg)
+		return
+	}
+	m, err := a.mediaStore.FindByID(id)
+	if err != nil || m == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if !describableTypes[m.ContentType] {
+		a.renderMediaDescriptions(w, r, "", fmt.Sprintf("%s is not an image that can be described.", m.OriginalName))
+		return
+	}
+	if _, err := a.enqueueDescribe(r.Context(), id); err != nil {
+		slog.Error("enqueue media describe job failed", "media", id, "error", err)
+		a.renderMediaDescriptions(w, r, "", "Failed to queue the image.")
+		return
+	}
+	a.renderMediaDescriptions(w, r, fmt.Sprintf("Queued %s.", m.OriginalName), "")
+}
+
+// findDescription loads the description named by the {id} URL parameter.
+// Writes the error response and returns nil if it doesn't exist.
+func (a *Admin) findDescription(w http.ResponseWriter, r *http.Request) *models.MediaDescription {
+	if a.mediaDescriptions == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return nil
+	}
+	d, err := a.mediaDescriptions.Find(id)
+	if err != nil {
+		slog.Error("find media description failed", "media", id, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil
+	}
+	if d == nil {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return nil
+	}
+	return d
+}
+
+// MediaDescriptionAccept applies a suggestion, as edited by the reviewer,
+// to the image and purges the pages that show it.
+func (a *Admin) MediaDescriptionAccept(w http.ResponseWriter, r *http.Request) {
+	d := a.findDescription(w, r)
+	if d == nil {
+		return
+	}
+	altText := capRunes(strings.TrimSpace(r.FormValue("alt_text")), maxAltTextLen)
+	caption := capRunes(strings.TrimSpace(r.FormValue("caption")), maxCaptionLen)
+	if altText == "" {
+		a.renderMediaDescriptions(w, r, "", "Alt text can't be empty. Reject the suggestion instead.")
+		return
+	}
+
+	sess := middleware.SessionFromCtx(r.Context())
+	ok, err := a.mediaDescriptions.Decide(d.MediaID, models.DescriptionAccepted, altText, caption, sess.UserID)
+	if err != nil {
+		slog.Error("accept media description failed", "media", d.MediaID, "error", err)
+		a.renderMediaDescriptions(w, r, "", "The description could not be saved.")
+		return
+	}
+	if !ok {
+		a.renderMediaDescriptions(w, r, "", "This description is no longer waiting for review.")
+		return
+	}
+	if err := a.mediaStore.UpdateDescription(d.MediaID, altText, caption); err != nil {
+		slog.Error("update media description failed", "media", d.MediaID, "error", err)
+		a.renderMediaDescriptions(w, r, "", "The description could not be saved.")
+		return
+	}
+	a.invalidateMediaPages(r.Context(), d.MediaID, d.S3Key)
+	auditFrom(r.Context()).saved("media/"+d.MediaID.String(), utf8.RuneCountInString(altText+caption))
+	a.renderMediaDescriptions(w, r, fmt.Sprintf("Saved the description of %s.", d.OriginalName), "")
+}
+
+// MediaDescriptionReject discards a suggestion.
+func (a *Admin) MediaDescriptionReject(w http.ResponseWriter, r *http.Request) {
+	d := a.findDescription(w, r)
+	if d == nil {
+		return
+	}
+	sess := middleware.SessionFromCtx(r.Context())
+	if _, err := a.mediaDescriptions.Decide(d.MediaID, models.DescriptionRejected, d.AltText, d.Caption, sess.UserID); err != nil {
+		slog.Error("reject media description failed", "media", d.MediaID, "error", err)
+	}
+	a.renderMediaDescriptions(w, r, "", "")
+}
+
+// invalidateMediaPages purges the cached pages that show a media item, so
+// they pick up its new alt text and caption.
+func (a *Admin) invalidateMediaPages(ctx context.Context, mediaID uuid.UUID, s3Key string) {
+	items, err := a.contentStore.ListUsingMedia(mediaID, s3Key)
+	if err != nil {
+		slog.Warn("list content using media failed", "media", mediaID, "error", err)
+		return
+	}
+	for _, c := range items {
+		a.invalidateContentCache(ctx, c.ID, c.Slug, "update")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"yaaicms/internal/models"
+)
+
+func TestDescribeSource(t *testing.T) {
+	variants := []models.MediaVariant{
+		{Name: "thumb", S3Key: "thumb.webp", ContentType: "image/webp"},
+		{Name: "lg", S3Key: "lg.webp", ContentType: "image/webp"},
+		{Name: "md", S3Key: "md.webp", ContentType: "image/webp"},
+	}
+	small := &models.Media{S3Key: "orig.png", ContentType: "image/png", SizeBytes: 1 << 20}
+	large := &models.Media{S3Key: "orig.png", ContentType: "image/png", SizeBytes: describeMaxBytes + 1}
+	gif := &models.Media{S3Key: "anim.gif", ContentType: "image/gif", SizeBytes: 1 << 20}
+	svg := &models.Media{S3Key: "logo.svg", ContentType: "image/svg+xml", SizeBytes: 1024}
+
+	tests := []struct {
+		name     string
+		media    *models.Media
+		variants []models.MediaVariant
+		key      string
+		ok       bool
+	}{
+		{"md variant first", small, variants, "md.webp", true},
+		{"next variant in order", small, variants[:2], "lg.webp", true},
+		{"small original", small, nil, "orig.png", true},
+		{"gif original", gif, nil, "anim.gif", true},
+		{"large original", large, nil, "", false},
+		{"svg", svg, nil, "", false},
+	}
+	for _, tt := range tests {
+		key, _, ok := describeSource(tt.media, tt.variants)
+		if key != tt.key || ok != tt.ok {
+			t.Errorf("%s: got %q, %v; want %q, %v", tt.name, key, ok, tt.key, tt.ok)
+		}
+	}
+}
+
+func TestDescribePrompt(t *testing.T) {
+	if p := describePrompt("golden-gate-bridge.jpg"); !strings.Contains(p, "golden-gate-bridge.jpg") {
+		t.Errorf("meaningful filename left out: %q", p)
+	}
+	for _, name := range []string{"IMG_2041.jpg", "DSC01234.png", "3f2b8c1e-9d4a-4f6b-8e2a-1c5d7f9b0a3e.webp"} {
+		if p := describePrompt(name); strings.Contains(p, name) {
+			t.Errorf("generated filename %q used as a hint: %q", name, p)
+		}
+	}
+}
+
+func TestCleanDescription(t *testing.T) {
+	d := &imageDescription{
+		AltText: `  "A dog catching a frisbee"  `,
+		Caption: strings.Repeat("é", maxCaptionLen+10),
+	}
+	cleanDescription(d)
+	if d.AltText != "A dog catching a frisbee" {
+		t.Errorf("AltText = %q", d.AltText)
+	}
+	if n := len([]rune(d.Caption)); n != maxCaptionLen {
+		t.Errorf("caption has %d runes, want %d", n, maxCaptionLen)
+	}
+}
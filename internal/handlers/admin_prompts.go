@@ -0,0 +1,329 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_prompts.go fills in the system prompts of AI tasks from the
+// prompt library and serves the admin's prompt editor.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/prompts"
+	"yaaicms/internal/render"
+)
+
+// maxPromptLen caps the length of a saved prompt, in characters.
+const maxPromptLen = 20000
+
+// SetPrompts sets the library the AI tasks read their system prompts
+// from. Without one they use the built-in defaults.
+func (a *Admin) SetPrompts(lib *prompts.Library) {
+	a.prompts = lib
+}
+
+// systemPrompt returns the system prompt in use for task with its
+// placeholders filled in from vars and the site's settings.
+func (a *Admin) systemPrompt(task string, vars prompts.Vars) string {
+	return a.renderPrompt(task, a.prompts.Body(task), vars)
+}
+
+// renderPrompt fills in the placeholders of a prompt body for task: the
+// common ones (site name, language, design brief) and then vars. A
+// template prompt that doesn't place the design brief gets it appended.
+func (a *Admin) renderPrompt(task, body string, vars prompts.Vars) string {
+	all := prompts.Vars{
+		"site_name": a.siteName(),
+		"language":  languageName(a.siteLanguage()),
+	}
+	placeBrief := prompts.Uses(body, "design_brief")
+	if placeBrief || task == prompts.TemplateGenerate {
+		all["design_brief"] = a.getActiveDesignBrief()
+	}
+	for k, v := range vars {
+		all[k] = v
+	}
+
+	out := prompts.Render(body, all)
+	if task == prompts.TemplateGenerate && !placeBrief && all["design_brief"] != "" {
+		out += designBriefSection(all["design_brief"])
+	}
+	return out
+}
+
+// templatePromptVars returns the template prompt's placeholders for a
+// template type.
+func templatePromptVars(tmplType string) prompts.Vars {
+	return prompts.Vars{"template_type": tmplType, "variables": templateVariables(tmplType)}
+}
+
+// siteName returns the site title from Settings.
+func (a *Admin) siteName() string {
+	if a.siteSettingStore != nil {
+		if name, err := a.siteSettingStore.Get("site_title", "YaaiCMS"); err == nil && name != "" {
+			return name
+		}
+	}
+	return "YaaiCMS"
+}
+
+// promptTest says how the editor's Test button runs a task: the model
+// tier, the reply schema (nil for plain text) and sample values for the
+// task's own placeholders.
+type promptTest struct {
+	tier   ai.TaskType
+	schema *ai.Schema
+	vars   prompts.Vars
+}
+
+// promptTests lists the tasks that can be tested in the editor. Image
+// descriptions need an image, so they can't.
+var promptTests = map[string]promptTest{
+	prompts.ContentGenerate: {tier: ai.TaskContent, vars: prompts.Vars{"content_type": "article"}},
+	prompts.ContentTitles:   {tier: ai.TaskLight, schema: titleSuggestionsSchema},
+	prompts.ContentExcerpt:  {tier: ai.TaskLight, schema: contentExcerptSchema},
+	prompts.ContentSEO:      {tier: ai.TaskLight, schema: seoMetadataSchema},
+	prompts.ContentTags:     {tier: ai.TaskLight, schema: contentTagsSchema},
+	prompts.ContentRewrite: {tier: ai.TaskContent, vars: prompts.Vars{
+		"tone":        rewriteTones["casual"],
+		"format_note": "The content uses Markdown formatting — preserve all Markdown syntax.",
+	}},
+	prompts.ContentTranslate: {tier: ai.TaskContent, schema: translatedContentSchema, vars: prompts.Vars{
+		"source_language": "English",
+		"target_language": "French",
+		"format_note":     "The body is Markdown. Keep every Markdown construct (headings, lists, links, emphasis, tables, code) exactly as it is and translate only the text.",
+	}},
+	prompts.ContentQuality:   {tier: ai.TaskLight, schema: qualitySuggestionsSchema},
+	prompts.ContentLinks:     {tier: ai.TaskLight, schema: linkPicksSchema},
+	prompts.TemplateGenerate: {tier: ai.TaskTemplate, vars: templatePromptVars("header")},
+	prompts.RevisionContent:  {tier: ai.TaskLight, schema: revisionMetadataSchema},
+	prompts.RevisionTemplate: {tier: ai.TaskLight, schema: revisionMetadataSchema},
+}
+
+// promptRow is a task on the prompt library page.
+type promptRow struct {
+	prompts.Task
+	Version int // The version in use, 0 if none is saved yet
+}
+
+// PromptsPage lists the AI tasks with an editable system prompt.
+func (a *Admin) PromptsPage(w http.ResponseWriter, r *http.Request) {
+	data := map[string]any{}
+	versions := map[string]int{}
+	if a.prompts == nil {
+		data["Error"] = "The prompt library is not configured; the built-in prompts are in use."
+	} else if v, err := a.prompts.LatestVersions(); err != nil {
+		slog.Error("list prompt versions failed", "error", err)
+		data["Error"] = "Failed to load the prompt library."
+	} else {
+		versions = v
+	}
+
+	rows := make([]promptRow, len(prompts.Tasks))
+	for i, t := range prompts.Tasks {
+		rows[i] = promptRow{Task: t, Version: versions[t.Key]}
+	}
+	data["Tasks"] = rows
+
+	a.renderer.Page(w, r, "prompts", &render.PageData{
+		Title:   "Prompts",
+		Section: "prompts",
+		Data:    data,
+	})
+}
+
+// PromptEdit renders the editor of a task's prompt.
+func (a *Admin) PromptEdit(w http.ResponseWriter, r *http.Request) {
+	task, ok := a.promptTask(w, r)
+	if !ok {
+		return
+	}
+	a.renderPromptEditor(w, r, task, "", "", "")
+}
+
+// renderPromptEditor renders the editor of a task's prompt with an
+// optional notice or error from a preceding action. A non-empty draft
+// is shown instead of the prompt in use, so a rejected edit isn't lost.
+func (a *Admin) renderPromptEditor(w http.ResponseWriter, r *http.Request, task prompts.Task, draft, notice, errMsg string) {
+	versions, err := a.prompts.Versions(task.Key)
+	if err != nil {
+		slog.Error("list prompt versions failed", "task", task.Key, "error", err)
+		errMsg = "Failed to load the prompt's versions."
+	}
+
+	body := draft
+	if body == "" {
+		body = a.prompts.Body(task.Key)
+	}
+	current := 0
+	if len(versions) > 0 {
+		current = versions[0].Version
+	}
+
+	var warning string
+	if unknown := prompts.Unknown(task, body); len(unknown) > 0 {
+		warning = fmt.Sprintf("Not placeholders of this task, so they are sent as written: {{%s}}.", strings.Join(unknown, "}}, {{"))
+	}
+
+	_, testable := promptTests[task.Key]
+	a.renderer.Page(w, r, "prompt_edit", &render.PageData{
+		Title:   task.Name + " Prompt",
+		Section: "prompts",
+		Data: map[string]any{
+			"Task":         task,
+			"Body":         body,
+			"Current":      current,
+			"Versions":     versions,
+			"Placeholders": append(append([]prompts.Placeholder{}, prompts.Common...), task.Placeholders...),
+			"Testable":     testable && task.Sample != "",
+			"Warning":      warning,
+			"Notice":       notice,
+			"Error":        errMsg,
+		},
+	})
+}
+
+// PromptSave saves the edited prompt as the task's next version.
+func (a *Admin) PromptSave(w http.ResponseWriter, r *http.Request) {
+	task, ok := a.promptTask(w, r)
+	if !ok {
+		return
+	}
+	body := strings.TrimSpace(r.FormValue("body"))
+	note := capRunes(strings.TrimSpace(r.FormValue("note")), 200)
+
+	switch {
+	case body == "":
+		a.renderPromptEditor(w, r, task, "", "", "The prompt can't be empty.")
+		return
+	case utf8.RuneCountInString(body) > maxPromptLen:
+		a.renderPromptEditor(w, r, task, body, "", fmt.Sprintf("The prompt is longer than %d characters.", maxPromptLen))
+		return
+	case body == a.prompts.Body(task.Key):
+		a.renderPromptEditor(w, r, task, "", "No changes to save.", "")
+		return
+	}
+
+	p, err := a.prompts.Save(task.Key, body, note, actorID(r.Context()))
+	if err != nil {
+		slog.Error("save prompt failed", "task", task.Key, "error", err)
+		a.renderPromptEditor(w, r, task, body, "", "The prompt could not be saved.")
+		return
+	}
+	slog.Info("prompt saved", "task", task.Key, "version", p.Version)
+	a.renderPromptEditor(w, r, task, "", fmt.Sprintf("Saved version %d. It is used from now on.", p.Version), "")
+}
+
+// PromptRollback makes an earlier version of a task's prompt the one in
+// use again, saving it as a new version.
+func (a *Admin) PromptRollback(w http.ResponseWriter, r *http.Request) {
+	task, ok := a.promptTask(w, r)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	p, err := a.prompts.Rollback(task.Key, version, actorID(r.Context()))
+	if err != nil {
+		slog.Error("roll back prompt failed", "task", task.Key, "version", version, "error", err)
+		a.renderPromptEditor(w, r, task, "", "", "The prompt could not be rolled back.")
+		return
+	}
+	if p == nil {
+		a.renderPromptEditor(w, r, task, "", "", fmt.Sprintf("Version %d doesn't exist.", version))
+		return
+	}
+	slog.Info("prompt rolled back", "task", task.Key, "from", version, "version", p.Version)
+	a.renderPromptEditor(w, r, task, "", fmt.Sprintf("Restored version %d as version %d.", version, p.Version), "")
+}
+
+// PromptTest runs the prompt in the editor, saved or not, against sample
+// input and returns the reply as an HTML fragment.
+func (a *Admin) PromptTest(w http.ResponseWriter, r *http.Request) {
+	task, ok := prompts.Lookup(chi.URLParam(r, "task"))
+	spec, testable := promptTests[task.Key]
+	if !ok || !testable {
+		writeAIError(w, "This prompt can't be tested here.")
+		return
+	}
+	body := strings.TrimSpace(r.FormValue("body"))
+	input := strings.TrimSpace(r.FormValue("sample"))
+	if body == "" || input == "" {
+		writeAIError(w, "Both the prompt and the sample input are needed for a test.")
+		return
+	}
+	if !a.checkPromptSafety(w, r, truncate(input, 3000)) {
+		return
+	}
+	if !a.checkAIBudget(w, r) {
+		return
+	}
+
+	systemPrompt := a.renderPrompt(task.Key, body, spec.vars)
+	var (
+		reply string
+		res   ai.Result
+		err   error
+	)
+	if spec.schema != nil {
+		var out map[string]any
+		res, err = a.aiRegistry.GenerateJSON(r.Context(), spec.tier, spec.schema, systemPrompt, input, &out)
+		if err == nil {
+			var sb strings.Builder
+			enc := json.NewEncoder(&sb)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			enc.Encode(out)
+			reply = strings.TrimSpace(sb.String())
+		}
+	} else {
+		res, err = a.aiRegistry.CompleteForTask(r.Context(), spec.tier, systemPrompt, input)
+		reply = strings.TrimSpace(res.Text)
+	}
+	if err != nil {
+		slog.Error("ai prompt test failed", "task", task.Key, "error", err)
+		writeAIError(w, "AI request failed. Check your provider configuration.")
+		return
+	}
+	reportAIProvider(w, res)
+
+	if msg := a.moderateOutput(r.Context(), reply); msg != "" {
+		writeAIError(w, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<div class="space-y-1">
+		<p class="text-xs text-gray-500">Reply from %s</p>
+		<pre class="text-xs text-gray-800 bg-gray-50 rounded p-3 max-h-96 overflow-auto whitespace-pre-wrap">%s</pre>
+	</div>`, html.EscapeString(res.Usage.Provider), html.EscapeString(reply))
+}
+
+// promptTask returns the task named in the URL, or writes a 404 and
+// false. It also writes an error when the prompt library is missing.
+func (a *Admin) promptTask(w http.ResponseWriter, r *http.Request) (prompts.Task, bool) {
+	task, ok := prompts.Lookup(chi.URLParam(r, "task"))
+	if !ok {
+		http.Error(w, "Prompt not found", http.StatusNotFound)
+		return task, false
+	}
+	if a.prompts == nil {
+		http.Error(w, "The prompt library is not configured", http.StatusServiceUnavailable)
+		return task, false
+	}
+	return task, true
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/prompts"
+)
+
+func TestSystemPromptPlaceholders(t *testing.T) {
+	a := &Admin{}
+	got := a.systemPrompt(prompts.ContentRewrite, prompts.Vars{"tone": "casual", "format_note": "Keep the Markdown."})
+	if !strings.Contains(got, "in a casual tone") || !strings.Contains(got, "\nKeep the Markdown.\n") || strings.Contains(got, "{{") {
+		t.Errorf("rewrite prompt:\n%s", got)
+	}
+
+	got = a.renderPrompt(prompts.ContentTags, "Tags for {{site_name}} in {{language}}.{{design_brief}}", nil)
+	if got != "Tags for YaaiCMS in English." {
+		t.Errorf("common placeholders: got %q", got)
+	}
+}
+
+func TestPromptTest(t *testing.T) {
+	var system string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/moderations":
+			json.NewEncoder(w).Encode(map[string]any{"results": []any{map[string]any{"flagged": false}}})
+		case "/chat/completions":
+			system = req.Messages[0].Content
+			json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{
+				"message": map[string]string{"role": "assistant", "content": `{"tags": ["<tomatoes>", "balcony"]}`},
+			}}})
+		}
+	}))
+	defer srv.Close()
+	a := &Admin{aiRegistry: ai.NewRegistry("openai", map[string]ai.ProviderConfig{
+		"openai": {APIKey: "test", Model: "test-model", BaseURL: srv.URL},
+	})}
+
+	rec := httptest.NewRecorder()
+	req := withChiURLParam(postForm(url.Values{
+		"body":   {"Unsaved draft for {{site_name}}."},
+		"sample": {"Title: Tomatoes"},
+	}), "task", prompts.ContentTags)
+	a.PromptTest(rec, req)
+
+	if !strings.HasPrefix(system, "Unsaved draft for YaaiCMS.") {
+		t.Errorf("system prompt: %q", system)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "&lt;tomatoes&gt;") || !strings.Contains(body, "balcony") {
+		t.Errorf("reply: %s", body)
+	}
+
+	// Image descriptions need an image and can't be tested.
+	rec = httptest.NewRecorder()
+	a.PromptTest(rec, withChiURLParam(postForm(url.Values{"body": {"x"}, "sample": {"y"}}), "task", prompts.ImageDescribe))
+	if !strings.Contains(rec.Body.String(), "be tested here") {
+		t.Errorf("image prompt test: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_quality.go serves the content health checks: the editor's panel,
+// AI improvement suggestions and the site-wide content health report.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/models"
+	"yaaicms/internal/prompts"
+	"yaaicms/internal/quality"
+	"yaaicms/internal/render"
+	"yaaicms/internal/store"
+)
+
+// qualitySuggestions is the structured reply for improvement suggestions.
+type qualitySuggestions struct {
+	Suggestions []string `json:"suggestions" desc:"3-5 specific improvements, most important first"`
+}
+
+var qualitySuggestionsSchema = ai.MustSchema("quality_suggestions", qualitySuggestions{})
+
+// contentFromForm builds the content item being edited from the editor's
+// fields, so unsaved changes are checked.
+func contentFromForm(r *http.Request) *models.Content {
+	format := models.BodyFormat(r.FormValue("body_format"))
+	if format != models.BodyFormatHTML {
+		format = models.BodyFormatMarkdown
+	}
+	desc := r.FormValue("meta_description")
+	keywords := r.FormValue("meta_keywords")
+	return &models.Content{
+		Title:           strings.TrimSpace(r.FormValue("title")),
+		Slug:            strings.TrimSpace(r.FormValue("slug")),
+		Body:            r.FormValue("body"),
+		BodyFormat:      format,
+		MetaDescription: &desc,
+		MetaKeywords:    &keywords,
+	}
+}
+
+// ContentHealthAnalyze checks the content in the editor and returns the
+// editor panel's HTML fragment.
+func (a *Admin) ContentHealthAnalyze(w http.ResponseWriter, r *http.Request) {
+	report := quality.Analyze(contentFromForm(r), r.Host)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<div class="space-y-3">
+		<div class="flex items-baseline justify-between">
+			<span class="text-2xl font-semibold %s">%d<span class="text-sm font-normal text-gray-400">/100</span></span>
+			<span class="text-xs text-gray-500">%d words · %s</span>
+		</div>
+		<ul class="space-y-2">`,
+		scoreColor(report.Score), report.Score, report.Words, html.EscapeString(report.ReadabilityLabel()))
+	for _, c := range report.Checks {
+		fmt.Fprintf(&sb, `<li class="flex gap-2 text-xs">
+				<span class="mt-1 h-2 w-2 flex-shrink-0 rounded-full %s" title="%s"></span>
+				<div><p class="font-medium text-gray-800">%s</p><p class="text-gray-500">%s</p></div>
+			</li>`,
+			statusDot(c.Status), c.Status, html.EscapeString(c.Label), html.EscapeString(c.Detail))
+	}
+	sb.WriteString(`</ul></div>`)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(sb.String()))
+}
+
+// AIQualitySuggestions asks the AI how to improve the content in the
+// editor, given the health check findings. Returns an HTML fragment.
+func (a *Admin) AIQualitySuggestions(w http.ResponseWriter, r *http.Request) {
+	c := contentFromForm(r)
+	if strings.TrimSpace(c.Body) == "" {
+		writeAIError(w, "Please write some content first so AI can review it.")
+		return
+	}
+	if !a.checkPromptSafety(w, r, truncate(c.Title+" "+c.Body, 3000)) {
+		return
+	}
+	if !a.checkAIBudget(w, r) {
+		return
+	}
+
+	suggestions, res, err := a.suggestImprovements(r.Context(), c, quality.Analyze(c, r.Host))
+	if err != nil {
+		slog.Error("ai quality suggestions failed", "error", err)
+		writeAIError(w, "AI request failed. Check your provider configuration.")
+		return
+	}
+	reportAIProvider(w, res)
+
+	if len(suggestions) == 0 {
+		writeAIError(w, "AI returned no suggestions. Please try again.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<ol class="list-decimal list-inside space-y-1.5 text-xs text-gray-700 bg-gray-50 rounded p-2">`)
+	for _, s := range suggestions {
+		fmt.Fprintf(&sb, `<li>%s</li>`, html.EscapeString(s))
+	}
+	sb.WriteString(`</ol>`)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(sb.String()))
+}
+
+// suggestImprovements asks the AI for improvements to a content item,
+// sending the findings that aren't good and the start of the body.
+func (a *Admin) suggestImprovements(ctx context.Context, c *models.Content, report *quality.Report) ([]string, ai.Result, error) {
+	var sb strings.Builder
+	meta := ptrStr(c.MetaDescription)
+	if meta == "" {
+		meta = "(none)"
+	}
+	keyword := report.Keyword
+	if keyword == "" {
+		keyword = "(none)"
+	}
+	fmt.Fprintf(&sb, "Title: %s\nSlug: %s\nMeta description: %s\nFocus keyword: %s\n\nFindings:\n", c.Title, c.Slug, meta, keyword)
+	for _, ch := range report.Checks {
+		if ch.Status != quality.Good {
+			fmt.Fprintf(&sb, "- %s (%s): %s\n", ch.Label, ch.Status, ch.Detail)
+		}
+	}
+	if report.Issues() == 0 {
+		sb.WriteString("- None; every check passed.\n")
+	}
+	fmt.Fprintf(&sb, "\nContent:\n%s", truncate(c.Body, 4000))
+
+	var out qualitySuggestions
+	res, err := a.aiRegistry.GenerateJSON(ctx, ai.TaskLight, qualitySuggestionsSchema,
+		a.systemPrompt(prompts.ContentQuality, nil), sb.String(), &out)
+	if err != nil {
+		return nil, res, err
+	}
+	return cleanList(out.Suggestions), res, nil
+}
+
+// scoreColor is the text color of a health score.
+func scoreColor(score int) string {
+	switch {
+	case score >= 80:
+		return "text-green-600"
+	case score >= 50:
+		return "text-amber-600"
+	default:
+		return "text-red-600"
+	}
+}
+
+// statusDot is the background color of a check's status dot.
+func statusDot(s quality.Status) string {
+	switch s {
+	case quality.Good:
+		return "bg-green-500"
+	case quality.Warning:
+		return "bg-amber-400"
+	default:
+		return "bg-red-500"
+	}
+}
+
+// healthRow is a content item in the content health report.
+type healthRow struct {
+	Item   models.Content
+	Report *quality.Report
+}
+
+// healthSorts are the report's sort keys, each comparing two rows in
+// ascending order.
+var healthSorts = map[string]func(a, b healthRow) bool{
+	"title":       func(a, b healthRow) bool { return strings.ToLower(a.Item.Title) < strings.ToLower(b.Item.Title) },
+	"score":       func(a, b healthRow) bool { return a.Report.Score < b.Report.Score },
+	"readability": func(a, b healthRow) bool { return a.Report.Readability < b.Report.Readability },
+	"words":       func(a, b healthRow) bool { return a.Report.Words < b.Report.Words },
+	"issues":      func(a, b healthRow) bool { return a.Report.Issues() < b.Report.Issues() },
+	"updated":     func(a, b healthRow) bool { return a.Item.UpdatedAt.Before(b.Item.UpdatedAt) },
+}
+
+// ContentHealthPage renders the content health report: every post and
+// page with its score, sortable by any column. It opens on the lowest
+// scores, the items most in need of work.
+func (a *Admin) ContentHealthPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := store.ContentFilter{}
+	switch t := models.ContentType(q.Get("type")); t {
+	case models.ContentTypePost, models.ContentTypePage:
+		f.Type = t
+	}
+	switch s := models.ContentStatus(q.Get("status")); s {
+	case models.ContentStatusDraft, models.ContentStatusPublished:
+		f.Status = s
+	}
+	sortKey := q.Get("sort")
+	if healthSorts[sortKey] == nil {
+		sortKey = "score"
+	}
+	desc := q.Get("dir") == "desc"
+
+	data := map[string]any{
+		"Type":   string(f.Type),
+		"Status": string(f.Status),
+		"Sort":   sortKey,
+		"Desc":   desc,
+	}
+	items, err := a.contentStore.ListFiltered(f)
+	if err != nil {
+		slog.Error("list content for health report failed", "error", err)
+		data["Error"] = "Failed to load content."
+	}
+
+	rows := make([]healthRow, len(items))
+	total := 0
+	for i, item := range items {
+		rows[i] = healthRow{Item: item, Report: quality.Analyze(&items[i], r.Host)}
+		total += rows[i].Report.Score
+	}
+	less := healthSorts[sortKey]
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+	data["Rows"] = rows
+	if len(rows) > 0 {
+		data["Average"] = total / len(rows)
+	}
+
+	// Each column header sorts by its key, flipping the direction when
+	// it is already the sort column.
+	sortURLs := make(map[string]string, len(healthSorts))
+	for key := range healthSorts {
+		v := url.Values{"sort": {key}}
+		if f.Type != "" {
+			v.Set("type", string(f.Type))
+		}
+		if f.Status != "" {
+			v.Set("status", string(f.Status))
+		}
+		if key == sortKey && !desc {
+			v.Set("dir", "desc")
+		}
+		sortURLs[key] = "/admin/content-health?" + v.Encode()
+	}
+	data["SortURLs"] = sortURLs
+
+	a.renderer.Page(w, r, "content_health", &render.PageData{
+		Title:   "Content Health",
+		Section: "content_health",
+		Data:    data,
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/ai"
+)
+
+func TestContentHealthAnalyze(t *testing.T) {
+	a := &Admin{}
+	rec := httptest.NewRecorder()
+	a.ContentHealthAnalyze(rec, postForm(url.Values{
+		"title": {"Balcony <tomatoes>"},
+		"slug":  {"balcony-tomatoes"},
+		"body":  {"A short draft with an image ![](/media/a.jpg)."},
+	}))
+
+	body := rec.Body.String()
+	for _, want := range []string{"/100", "Meta description", "Image alt text", "bg-red-500"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("fragment missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestAIQualitySuggestions(t *testing.T) {
+	var user string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/moderations":
+			json.NewEncoder(w).Encode(map[string]any{"results": []any{map[string]any{"flagged": false}}})
+		case "/chat/completions":
+			user = req.Messages[len(req.Messages)-1].Content
+			json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{
+				"message": map[string]string{"role": "assistant", "content": `{"suggestions": ["Add a <meta> description.", " ", "Write more."]}`},
+			}}})
+		}
+	}))
+	defer srv.Close()
+	a := &Admin{aiRegistry: ai.NewRegistry("openai", map[string]ai.ProviderConfig{
+		"openai": {APIKey: "test", Model: "test-model", BaseURL: srv.URL},
+	})}
+
+	rec := httptest.NewRecorder()
+	a.AIQualitySuggestions(rec, postForm(url.Values{
+		"title": {"Tomatoes"},
+		"body":  {"Tomatoes like sun."},
+	}))
+
+	if !strings.Contains(user, "Meta description (problem)") || !strings.Contains(user, "Content:\nTomatoes like sun.") {
+		t.Errorf("user prompt:\n%s", user)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Add a &lt;meta&gt; description.") || strings.Count(body, "<li>") != 2 {
+		t.Errorf("reply: %s", body)
+	}
+
+	// Nothing to review without a body.
+	rec = httptest.NewRecorder()
+	a.AIQualitySuggestions(rec, postForm(url.Values{"title": {"Tomatoes"}}))
+	if !strings.Contains(rec.Body.String(), "write some content first") {
+		t.Errorf("empty body: %s", rec.Body.String())
+	}
+}
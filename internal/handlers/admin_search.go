@@ -0,0 +1,209 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// admin_search.go filters the posts and pages lists and keeps the search
+// index current: every content save queues a content_embed job, and a
+// backfill job embeds content the current embedding model hasn't seen yet.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/jobs"
+	"yaaicms/internal/models"
+	"yaaicms/internal/render"
+	"yaaicms/internal/search"
+	"yaaicms/internal/store"
+)
+
+// Background job kinds for the search index.
+const (
+	jobContentEmbed  = "content_embed"
+	jobEmbedBackfill = "content_embed_backfill"
+)
+
+// embedBackfillBatch is how many items one backfill job embeds before it
+// queues the next.
+const embedBackfillBatch = 100
+
+// contentListPageSize is the number of rows per page of the posts and
+// pages lists.
+const contentListPageSize = 50
+
+// contentList renders the posts or pages list filtered by the q, status
+// and category query parameters. With a query the rows are ranked by
+// relevance and show a highlighted snippet of the match.
+func (a *Admin) contentList(w http.ResponseWriter, r *http.Request, contentType models.ContentType, name, title, section string) {
+	params := r.URL.Query()
+	q := strings.TrimSpace(params.Get("q"))
+	if runes := []rune(q); len(runes) > maxSearchQuery {
+		q = string(runes[:maxSearchQuery])
+	}
+	f := store.ContentFilter{Type: contentType, Status: models.ContentStatus(params.Get("status"))}
+	if f.Status != models.ContentStatusDraft && f.Status != models.ContentStatusPublished {
+		f.Status = ""
+	}
+	var categoryID string
+	if contentType == models.ContentTypePost {
+		if id, err := uuid.Parse(params.Get("category")); err == nil {
+			f.CategoryID = &id
+			categoryID = id.String()
+		}
+	}
+	page, _ := strconv.Atoi(params.Get("page"))
+	page = max(page, 1)
+
+	res, err := a.contentStore.Search(q, f, store.Page{Number: page, Size: contentListPageSize})
+	if err != nil {
+		slog.Error("list "+section+" failed", "error", err)
+	}
+
+	items := make([]models.Content, len(res.Hits))
+	snippets := make(map[uuid.UUID]template.HTML)
+	for i, hit := range res.Hits {
+		items[i] = hit.Content
+		if hit.Snippet != "" {
+			snippets[hit.Content.ID] = markSnippet(hit.Snippet)
+		}
+	}
+
+	pageURL := func(n int) string {
+		v := url.Values{}
+		for key, val := range map[string]string{"q": q, "status": string(f.Status), "category": categoryID} {
+			if val != "" {
+				v.Set(key, val)
+			}
+		}
+		if n > 1 {
+			v.Set("page", strconv.Itoa(n))
+		}
+		if len(v) == 0 {
+			return "/admin/" + section
+		}
+		return "/admin/" + section + "?" + v.Encode()
+	}
+	data := map[string]any{
+		"Items":      items,
+		"Snippets":   snippets,
+		"Locales":    a.translationLocales(),
+		"Query":      q,
+		"Status":     string(f.Status),
+		"CategoryID": categoryID,
+		"Total":      res.Total,
+		"Filtered":   q != "" || f.Status != "" || f.CategoryID != nil,
+	}
+	if contentType == models.ContentTypePost {
+		data["Categories"], _ = a.categoryStore.FlatTree()
+	}
+	if page > 1 {
+		data["PrevURL"] = pageURL(page - 1)
+	}
+	if page*contentListPageSize < res.Total {
+		data["NextURL"] = pageURL(page + 1)
+	}
+
+	a.renderer.Page(w, r, name, &render.PageData{
+		Title:   title,
+		Section: section,
+		Data:    data,
+	})
+}
+
+// SetSearchIndex enables embedding content for search and related posts.
+// Call before SetJobQueue's jobs can run.
+func (a *Admin) SetSearchIndex(idx *search.Index) {
+	a.search = idx
+}
+
+// contentEmbedJob is the payload of a content_embed job.
+type contentEmbedJob struct {
+	ContentID uuid.UUID `json:"content_id"`
+}
+
+// enqueueEmbed queues re-embedding a content item after it was saved.
+// Failures are logged; the backfill picks the item up on the next start.
+func (a *Admin) enqueueEmbed(ctx context.Context, id uuid.UUID) {
+	if a.jobs == nil || a.search == nil {
+		return
+	}
+	if _, err := a.jobs.Enqueue(jobContentEmbed, contentEmbedJob{ContentID: id}, jobs.Options{CreatedBy: actorID(ctx)}); err != nil {
+		slog.Error("enqueue content embed job failed", "content", id, "error", err)
+	}
+}
+
+// runEmbedJob updates the embedding of one content item. Content deleted
+// in the meantime has nothing to embed.
+func (a *Admin) runEmbedJob(ctx context.Context, job *jobs.Job) (any, error) {
+	var p contentEmbedJob
+	if err := job.Decode(&p); err != nil {
+		return nil, err
+	}
+	if a.search == nil {
+		return nil, nil
+	}
+
+	c, err := a.contentStore.FindByID(p.ContentID)
+	if err != nil {
+		return nil, fmt.Errorf("find content: %w", err)
+	}
+	if c == nil {
+		return nil, nil
+	}
+	if err := a.search.Update(ctx, c); err != nil {
+		if cl := ai.Classify(err); cl == ai.ErrorFatal || cl == ai.ErrorAuth {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	return map[string]string{"model": a.search.Model()}, nil
+}
+
+// BackfillEmbeddings queues a backfill job if published content lacks an
+// embedding for the current model, e.g. on first start or after the
+// embedding provider changed.
+func (a *Admin) BackfillEmbeddings() {
+	if a.jobs == nil || a.search == nil {
+		return
+	}
+	missing, err := a.search.HasMissing()
+	if err != nil {
+		slog.Error("check for content missing embeddings failed", "error", err)
+		return
+	}
+	if !missing {
+		return
+	}
+	if _, err := a.jobs.Enqueue(jobEmbedBackfill, struct{}{}, jobs.Options{}); err != nil {
+		slog.Error("enqueue embedding backfill failed", "error", err)
+	}
+}
+
+// runEmbedBackfillJob embeds a batch of content missing an embedding and
+// queues another job while a full batch was needed.
+func (a *Admin) runEmbedBackfillJob(ctx context.Context, job *jobs.Job) (any, error) {
+	if a.search == nil {
+		return nil, nil
+	}
+	n, err := a.search.UpdateMissing(ctx, embedBackfillBatch)
+	if err != nil {
+		if cl := ai.Classify(err); cl == ai.ErrorFatal || cl == ai.ErrorAuth {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	if n == embedBackfillBatch {
+		a.BackfillEmbeddings()
+	}
+	return map[string]int{"embedded": n}, nil
+}
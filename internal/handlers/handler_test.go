@@ -24,7 +24,9 @@ import (
 	"yaaicms/internal/cache"
 	"yaaicms/internal/database"
 	"yaaicms/internal/engine"
+	"yaaicms/internal/jobs"
 	"yaaicms/internal/middleware"
+	"yaaicms/internal/prompts"
 	"yaaicms/internal/render"
 	"yaaicms/internal/session"
 	"yaaicms/internal/store"
@@ -35,14 +37,36 @@ type mockAIProvider struct {
 	name     string
 	response string
 	err      error
+	lastChat []ai.Message // Messages of the last Chat/StreamChat call
 }
 
 func (m *mockAIProvider) Name() string { return m.name }
 func (m *mockAIProvider) Generate(_ context.Context, _, _ string) (string, error) {
 	return m.response, m.err
 }
-func (m *mockAIProvider) GenerateWithModel(_ context.Context, _, _, _ string) (string, error) {
-	return m.response, m.err
+func (m *mockAIProvider) GenerateWithModel(_ context.Context, _, _, _ string) (ai.Result, error) {
+	return ai.Result{Text: m.response}, m.err
+}
+func (m *mockAIProvider) StreamGenerate(_ context.Context, _, _, _ string, onDelta ai.DeltaFunc) (ai.Result, error) {
+	if m.err != nil {
+		return ai.Result{}, m.err
+	}
+	// Split the response in two to exercise multiple deltas.
+	half := len(m.response) / 2
+	for _, part := range []string{m.response[:half], m.response[half:]} {
+		if err := onDelta(part); err != nil {
+			return ai.Result{}, err
+		}
+	}
+	return ai.Result{Text: m.response}, nil
+}
+func (m *mockAIProvider) Chat(_ context.Context, _ string, messages []ai.Message) (ai.Result, error) {
+	m.lastChat = messages
+	return ai.Result{Text: m.response}, m.err
+}
+func (m *mockAIProvider) StreamChat(ctx context.Context, model string, messages []ai.Message, onDelta ai.DeltaFunc) (ai.Result, error) {
+	m.lastChat = messages
+	return m.StreamGenerate(ctx, model, "", "", onDelta)
 }
 
 func envOr(key, fallback string) string {
@@ -127,6 +151,12 @@ type testEnv struct {
 	TemplateStore *store.TemplateStore
 	MediaStore    *store.MediaStore
 	CacheLog      *store.CacheLogStore
+	AIUsage       *store.AIUsageStore
+	AIBudgets     *store.AIBudgetStore
+	AIChats       *store.AIConversationStore
+	AIBulk        *store.AIBulkStore
+	Translations  *store.TranslationStore
+	Jobs          *jobs.Queue
 	Engine        *engine.Engine
 	PageCache     *cache.PageCache
 	AIRegistry    *ai.Registry
@@ -173,10 +203,27 @@ func newTestEnv(t *testing.T) *testEnv {
 
 	siteSettingStore := store.NewSiteSettingStore(db)
 	categoryStore := store.NewCategoryStore(db)
+	aiUsageStore := store.NewAIUsageStore(db)
+	aiBudgetStore := store.NewAIBudgetStore(db)
+	aiConversationStore := store.NewAIConversationStore(db)
+	aiBulkStore := store.NewAIBulkStore(db)
+	translationStore := store.NewTranslationStore(db)
+	mediaDescriptionStore := store.NewMediaDescriptionStore(db)
 	admin := NewAdmin(renderer, sessions, contentStore, userStore, templateStore,
 		mediaStore, nil, nil, nil, nil, siteSettingStore, categoryStore, nil, eng, pageCache, cacheLogStore, aiRegistry, aiCfg)
+	admin.SetAIUsage(aiUsageStore)
+	admin.SetAIBudgets(aiBudgetStore)
+	admin.SetAIConversations(aiConversationStore)
+	admin.SetAIBulk(aiBulkStore)
+	admin.SetTranslations(translationStore)
+	admin.SetMediaDescriptions(mediaDescriptionStore)
+	// The queue isn't started; tests run or inspect jobs directly.
+	jobQueue := jobs.NewQueue(store.NewAIJobStore(db), 1)
+	admin.SetJobQueue(jobQueue)
+	admin.SetPrompts(prompts.NewLibrary(store.NewPromptStore(db)))
 	auth := NewAuth(renderer, sessions, userStore)
 	public := NewPublic(eng, contentStore, nil, nil, nil, pageCache)
+	public.SetTranslations(translationStore, siteSettingStore, "")
 
 	return &testEnv{
 		DB:            db,
@@ -188,6 +235,12 @@ func newTestEnv(t *testing.T) *testEnv {
 		TemplateStore: templateStore,
 		MediaStore:    mediaStore,
 		CacheLog:      cacheLogStore,
+		AIUsage:       aiUsageStore,
+		AIBudgets:     aiBudgetStore,
+		AIChats:       aiConversationStore,
+		AIBulk:        aiBulkStore,
+		Translations:  translationStore,
+		Jobs:          jobQueue,
 		Engine:        eng,
 		PageCache:     pageCache,
 		AIRegistry:    aiRegistry,
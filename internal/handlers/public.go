@@ -5,6 +5,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"html"
 	"log/slog"
@@ -15,8 +16,10 @@ import (
 	"github.com/google/uuid"
 
 	"yaaicms/internal/cache"
+	"yaaicms/internal/cdn"
 	"yaaicms/internal/engine"
 	"yaaicms/internal/models"
+	"yaaicms/internal/search"
 	"yaaicms/internal/storage"
 	"yaaicms/internal/store"
 )
@@ -31,6 +34,16 @@ type Public struct {
 	variantStore  *store.VariantStore
 	storageClient *storage.Client
 	pageCache     *cache.PageCache
+
+	// Optional translations. Nil until SetTranslations is called, in which
+	// case every page is rendered without a language or translations.
+	translations *store.TranslationStore
+	settings     *store.SiteSettingStore
+	siteURL      string
+
+	// Optional search. Nil until SetSearch is called, in which case
+	// /search is not found and pages have no related posts.
+	search *search.Index
 }
 
 // NewPublic creates a new Public handler group. mediaStore, variantStore,
@@ -46,12 +59,23 @@ func NewPublic(eng *engine.Engine, contentStore *store.ContentStore, mediaStore
 	}
 }
 
+// SetTranslations enables translated pages: locale-prefixed routes, the
+// .Lang and .Translations template variables and hreflang alternates.
+// siteURL makes the alternate URLs absolute; it may be empty.
+func (p *Public) SetTranslations(translations *store.TranslationStore, settings *store.SiteSettingStore, siteURL string) {
+	p.translations = translations
+	p.settings = settings
+	p.siteURL = strings.TrimRight(siteURL, "/")
+}
+
 // Homepage renders the site homepage. If an article_loop template is active,
 // it renders a blog-style post listing. Otherwise, it looks for a page with
 // slug "home" or falls back to a simple default.
 func (p *Public) Homepage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	setSurrogateKeys(w, cdn.SurrogateKeyHomepage)
+
 	// Check L2 cache first.
 	if cached, ok := p.pageCache.Get(ctx, cache.HomepageKey()); ok {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -59,7 +83,37 @@ func (p *Public) Homepage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Try to render a blog-style homepage with the article_loop template.
+	if rendered := p.renderHomepage(); rendered != nil {
+		p.pageCache.RecordMiss(ctx, cache.HomepageKey())
+		p.pageCache.Set(ctx, cache.HomepageKey(), rendered)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(rendered)
+		return
+	}
+
+	// Default fallback when no templates or content exist yet (not cached).
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(`<!DOCTYPE html>
+<html><head><title>YaaiCMS</title>
+<script src="https://cdn.tailwindcss.com"></script></head>
+<body class="bg-gray-100 flex items-center justify-center min-h-screen">
+<div class="text-center">
+<h1 class="text-4xl font-bold text-gray-900"><span class="text-indigo-600">Smart</span>Press</h1>
+<p class="mt-2 text-gray-500">Your site is running. Set up templates in the admin panel.</p>
+<a href="/admin/login" class="mt-4 inline-block text-indigo-600 hover:text-indigo-800 text-sm">Go to Admin Panel</a>
+</div></body></html>`))
+}
+
+// setSurrogateKeys tags the response for the CDN so it can be purged by
+// key. Every page also carries the site-wide key for full purges.
+func setSurrogateKeys(w http.ResponseWriter, key string) {
+	w.Header().Set("Surrogate-Key", cdn.SurrogateKeySite+" "+key)
+}
+
+// renderHomepage renders the blog-style post listing through the
+// article_loop template, falling back to the page with slug "home".
+// Returns nil when neither can be rendered. Shared with the cache warmer.
+func (p *Public) renderHomepage() []byte {
 	posts, err := p.contentStore.ListPublishedByType(models.ContentTypePost)
 	if err != nil {
 		slog.Error("list published posts failed", "error", err)
@@ -68,10 +122,7 @@ func (p *Public) Homepage(w http.ResponseWriter, r *http.Request) {
 	if len(posts) > 0 {
 		rendered, err := p.engine.RenderPostList(posts, p.resolveFeaturedImages(posts))
 		if err == nil {
-			p.pageCache.Set(ctx, cache.HomepageKey(), rendered)
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.Write(rendered)
-			return
+			return rendered
 		}
 		slog.Warn("article_loop render failed, trying homepage", "error", err)
 	}
@@ -79,36 +130,38 @@ func (p *Public) Homepage(w http.ResponseWriter, r *http.Request) {
 	// Fall back to a "home" page if it exists.
 	home, err := p.contentStore.FindBySlug("home")
 	if err == nil && home != nil {
-		rendered, err := p.engine.RenderPage(home, p.resolveFeaturedImage(home))
+		rendered, err := p.renderContent(home)
 		if err == nil {
-			p.pageCache.Set(ctx, cache.HomepageKey(), rendered)
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.Write(rendered)
-			return
+			return rendered
 		}
 		slog.Warn("homepage render failed", "error", err)
 	}
 
-	// Default fallback when no templates or content exist yet (not cached).
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(`<!DOCTYPE html>
-<html><head><title>YaaiCMS</title>
-<script src="https://cdn.tailwindcss.com"></script></head>
-<body class="bg-gray-100 flex items-center justify-center min-h-screen">
-<div class="text-center">
-<h1 class="text-4xl font-bold text-gray-900"><span class="text-indigo-600">Smart</span>Press</h1>
-<p class="mt-2 text-gray-500">Your site is running. Set up templates in the admin panel.</p>
-<a href="/admin/login" class="mt-4 inline-block text-indigo-600 hover:text-indigo-800 text-sm">Go to Admin Panel</a>
-</div></body></html>`))
+	return nil
 }
 
-// Page renders a public page or post by its slug using the template engine.
+// Page renders a public page or post in the site's default language by
+// its slug using the template engine.
 func (p *Public) Page(w http.ResponseWriter, r *http.Request) {
+	p.serveContent(w, r, "", chi.URLParam(r, "slug"))
+}
+
+// LocalizedPage renders a translation at /{locale}/{slug}.
+func (p *Public) LocalizedPage(w http.ResponseWriter, r *http.Request) {
+	p.serveContent(w, r, chi.URLParam(r, "locale"), chi.URLParam(r, "slug"))
+}
+
+// serveContent renders the content with the given slug, served under
+// locale ("" for the default language). Content requested under another
+// locale than its own is redirected to its canonical path.
+func (p *Public) serveContent(w http.ResponseWriter, r *http.Request, locale, slugParam string) {
 	ctx := r.Context()
-	slugParam := chi.URLParam(r, "slug")
+	key := contentCacheKey(locale, slugParam)
+	setSurrogateKeys(w, cdn.PageKey(slugParam))
 
-	// Check L2 cache first.
-	if cached, ok := p.pageCache.Get(ctx, cache.SlugKey(slugParam)); ok {
+	// Check L2 cache first. Only pages served at their canonical path are
+	// stored, so a hit needs no locale check.
+	if cached, ok := p.pageCache.Get(ctx, key); ok {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.Write(cached)
 		return
@@ -126,7 +179,16 @@ func (p *Public) Page(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rendered, err := p.engine.RenderPage(content, p.resolveFeaturedImage(content))
+	loc, contentLocale := p.localize(content)
+	if contentLocale != locale {
+		http.Redirect(w, r, contentPath(contentLocale, content.Slug), http.StatusMovedPermanently)
+		return
+	}
+
+	rendered, err := p.engine.RenderPageWith(content, p.resolveFeaturedImage(content), engine.PageExtras{
+		Localization: loc,
+		RelatedPosts: p.relatedPosts(ctx, content, contentLocale),
+	})
 	if err != nil {
 		slog.Error("render page failed", "error", err, "slug", slugParam)
 		// Fall back to a safe error page when the template engine fails.
@@ -145,12 +207,132 @@ func (p *Public) Page(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Store in L2 cache.
-	p.pageCache.Set(ctx, cache.SlugKey(slugParam), rendered)
+	p.pageCache.RecordMiss(ctx, key)
+	p.pageCache.Set(ctx, key, rendered)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Write(rendered)
 }
 
+// renderContent renders a content item with its featured image, language,
+// translations and related posts. Shared with the cache warmer.
+func (p *Public) renderContent(c *models.Content) ([]byte, error) {
+	loc, locale := p.localize(c)
+	return p.engine.RenderPageWith(c, p.resolveFeaturedImage(c), engine.PageExtras{
+		Localization: loc,
+		RelatedPosts: p.relatedPosts(context.Background(), c, locale),
+	})
+}
+
+// contentLocale returns the locale of a translation, or "" for content
+// in the default language.
+func (p *Public) contentLocale(id uuid.UUID) string {
+	if p.translations == nil {
+		return ""
+	}
+	link, err := p.translations.FindByTranslation(id)
+	if err != nil {
+		slog.Warn("find translation failed", "content", id, "error", err)
+	}
+	if link == nil {
+		return ""
+	}
+	return link.Locale
+}
+
+// localize returns the language and published language versions of c
+// for rendering, and c's locale ("" for the default language). It
+// returns nil when translations are not enabled.
+func (p *Public) localize(c *models.Content) (*engine.Localization, string) {
+	if p.translations == nil {
+		return nil, ""
+	}
+
+	defaultLang := "en"
+	if p.settings != nil {
+		if lang, err := p.settings.Get("language", "en"); err == nil && lang != "" {
+			defaultLang = lang
+		}
+	}
+
+	source := c
+	locale := ""
+	link, err := p.translations.FindByTranslation(c.ID)
+	if err != nil {
+		slog.Warn("find translation failed", "content", c.ID, "error", err)
+	}
+	if link != nil {
+		locale = link.Locale
+		if source, err = p.contentStore.FindByID(link.SourceID); err != nil {
+			slog.Warn("find translation source failed", "content", c.ID, "error", err)
+		}
+	}
+
+	loc := &engine.Localization{Lang: defaultLang}
+	if locale != "" {
+		loc.Lang = locale
+	}
+	if source == nil {
+		return loc, locale
+	}
+	list, err := p.translations.ListBySource(source.ID)
+	if err != nil {
+		slog.Warn("list translations failed", "content", source.ID, "error", err)
+	}
+	if len(list) == 0 {
+		return loc, locale
+	}
+
+	var versions []engine.Translation
+	if source.IsPublished() {
+		versions = append(versions, p.version(defaultLang, "", source.Title, source.Slug, source.ID == c.ID))
+		versions[0].Default = true
+	}
+	for _, t := range list {
+		if t.Status == models.ContentStatusPublished {
+			versions = append(versions, p.version(t.Locale, t.Locale, t.Title, t.Slug, t.TranslationID == c.ID))
+		}
+	}
+	if len(versions) > 1 {
+		loc.Translations = versions
+	}
+	return loc, locale
+}
+
+// version builds one entry of .Translations. pathLocale is the URL
+// prefix, empty for the default language.
+func (p *Public) version(lang, pathLocale, title, slug string, current bool) engine.Translation {
+	name := lang
+	if l, ok := models.LanguageByCode(lang); ok {
+		name = l.Native
+	}
+	return engine.Translation{
+		Locale:  lang,
+		Name:    name,
+		Title:   title,
+		URL:     p.siteURL + contentPath(pathLocale, slug),
+		Current: current,
+	}
+}
+
+// contentPath returns the public path of a content item: /{slug} in the
+// default language, /{locale}/{slug} for a translation.
+func contentPath(locale, slug string) string {
+	if locale == "" {
+		return "/" + slug
+	}
+	return "/" + locale + "/" + slug
+}
+
+// contentCacheKey returns the page cache key for the content served at
+// contentPath(locale, slug).
+func contentCacheKey(locale, slug string) string {
+	if locale == "" {
+		return cache.SlugKey(slug)
+	}
+	return cache.LocaleSlugKey(locale, slug)
+}
+
 // resolveFeaturedImage returns the featured image data (URL, srcset, alt)
 // for a content item, or nil if none is set or storage is not configured.
 func (p *Public) resolveFeaturedImage(content *models.Content) *engine.FeaturedImage {
@@ -168,9 +350,8 @@ func (p *Public) resolveFeaturedImage(content *models.Content) *engine.FeaturedI
 	img := &engine.FeaturedImage{
 		URL: p.storageClient.FileURL(media.S3Key),
 	}
-	if media.AltText != nil {
-		img.Alt = *media.AltText
-	}
+	img.Alt = ptrStr(media.AltText)
+	img.Caption = ptrStr(media.Caption)
 
 	// Build srcset from responsive variants.
 	if p.variantStore != nil {
@@ -224,9 +405,8 @@ func (p *Public) resolveFeaturedImages(posts []models.Content) map[string]*engin
 		img := &engine.FeaturedImage{
 			URL: p.storageClient.FileURL(media.S3Key),
 		}
-		if media.AltText != nil {
-			img.Alt = *media.AltText
-		}
+		img.Alt = ptrStr(media.AltText)
+		img.Caption = ptrStr(media.Caption)
 		if variants, ok := variantMap[ref.mediaID]; ok {
 			img.Srcset = p.buildSrcsetFromVariants(variants)
 		}
@@ -58,6 +58,9 @@ func TestHomepageDefault(t *testing.T) {
 	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
 		t.Errorf("Content-Type: got %q, want %q", ct, "text/html; charset=utf-8")
 	}
+	if sk := rec.Header().Get("Surrogate-Key"); sk != "site homepage" {
+		t.Errorf("Surrogate-Key: got %q, want %q", sk, "site homepage")
+	}
 }
 
 // TestHomepageFallback verifies that when a published page with slug "home"
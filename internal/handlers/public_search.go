@@ -0,0 +1,168 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// public_search.go serves site search and fills in related posts.
+package handlers
+
+import (
+	"context"
+	"html"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"yaaicms/internal/engine"
+	"yaaicms/internal/models"
+	"yaaicms/internal/search"
+)
+
+const (
+	// maxSearchQuery caps the query length in runes.
+	maxSearchQuery = 200
+
+	// searchResultLimit caps the results of one query, over all pages.
+	searchResultLimit = 100
+
+	// searchPageSize is the number of results per search page.
+	searchPageSize = 10
+
+	// relatedPostLimit caps .RelatedPosts.
+	relatedPostLimit = 3
+)
+
+// SetSearch enables the /search page and the .RelatedPosts template
+// variable.
+func (p *Public) SetSearch(idx *search.Index) {
+	p.search = idx
+}
+
+// Search renders the results for ?q= (page ?page=) through the search
+// template. Results are never cached, since queries are unbounded.
+func (p *Public) Search(w http.ResponseWriter, r *http.Request) {
+	if p.search == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if runes := []rune(q); len(runes) > maxSearchQuery {
+		q = string(runes[:maxSearchQuery])
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	var results []search.Result
+	if q != "" {
+		var err error
+		results, err = p.search.Search(r.Context(), q, searchResultLimit)
+		if err != nil {
+			slog.Error("search failed", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	data := p.searchData(q, results, page)
+	rendered, err := p.engine.RenderSearch(data)
+	if err != nil {
+		slog.Error("render search results failed", "error", err)
+		w.Write(searchFallback(q, data.Results))
+		return
+	}
+	w.Write(rendered)
+}
+
+// searchData builds the search template data for one page of results.
+// Results without a snippet (found by meaning, not by words) show their
+// excerpt instead.
+func (p *Public) searchData(q string, results []search.Result, page int) engine.SearchData {
+	data := engine.SearchData{Query: q, Total: len(results)}
+	data.TotalPages = (len(results) + searchPageSize - 1) / searchPageSize
+	data.Page = min(max(page, 1), max(data.TotalPages, 1))
+
+	start := (data.Page - 1) * searchPageSize
+	pageResults := results[start:min(start+searchPageSize, len(results))]
+
+	posts := make([]models.Content, len(pageResults))
+	for i, res := range pageResults {
+		posts[i] = res.Content
+	}
+	items := engine.PostItems(posts, p.resolveFeaturedImages(posts))
+	for i, res := range pageResults {
+		sr := engine.SearchResult{PostItem: items[i], Type: string(res.Content.Type)}
+		if res.Snippet != "" {
+			sr.Snippet = markSnippet(res.Snippet)
+		} else {
+			sr.Snippet = template.HTML(html.EscapeString(items[i].Excerpt))
+		}
+		data.Results = append(data.Results, sr)
+	}
+
+	pageURL := func(n int) string {
+		v := url.Values{"q": {q}}
+		if n > 1 {
+			v.Set("page", strconv.Itoa(n))
+		}
+		return "/search?" + v.Encode()
+	}
+	if data.Page > 1 {
+		data.PrevURL = pageURL(data.Page - 1)
+	}
+	if data.Page < data.TotalPages {
+		data.NextURL = pageURL(data.Page + 1)
+	}
+	return data
+}
+
+// snippetMarks restores the match markers of an escaped snippet.
+var snippetMarks = strings.NewReplacer("&lt;mark&gt;", "<mark>", "&lt;/mark&gt;", "</mark>")
+
+// markSnippet escapes a search snippet except for its <mark> tags. The
+// database already strips markup from snippets; escaping here keeps them
+// safe even if that ever changes.
+func markSnippet(s string) template.HTML {
+	return template.HTML(snippetMarks.Replace(html.EscapeString(s)))
+}
+
+// searchFallback renders plain search results when no template can
+// render them. Everything but the snippets is escaped.
+func searchFallback(q string, results []engine.SearchResult) []byte {
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html><html><head><title>Search</title>
+<script src="https://cdn.tailwindcss.com"></script></head>
+<body class="bg-gray-100 min-h-screen">
+<div class="max-w-2xl mx-auto py-12 px-4">
+<h1 class="text-3xl font-bold text-gray-900">Search</h1>
+<form action="/search" class="mt-4"><input type="search" name="q" value="` + html.EscapeString(q) + `" class="w-full rounded border px-3 py-2"></form>
+<ul class="mt-6 space-y-3">`)
+	for _, res := range results {
+		sb.WriteString(`<li><a href="/` + html.EscapeString(res.Slug) + `" class="text-indigo-600 hover:text-indigo-800">` + html.EscapeString(res.Title) + `</a>`)
+		sb.WriteString(`<p class="text-sm text-gray-600">` + string(res.Snippet) + `</p></li>`)
+	}
+	sb.WriteString(`</ul>`)
+	if q != "" && len(results) == 0 {
+		sb.WriteString(`<p class="mt-6 text-gray-500">No results.</p>`)
+	}
+	sb.WriteString(`</div></body></html>`)
+	return []byte(sb.String())
+}
+
+// relatedPosts returns the posts similar to c as listing items, or nil
+// when search is disabled or c is a translation.
+func (p *Public) relatedPosts(ctx context.Context, c *models.Content, locale string) []engine.PostItem {
+	if p.search == nil || locale != "" || c.Type != models.ContentTypePost {
+		return nil
+	}
+	posts, err := p.search.Related(ctx, c, relatedPostLimit)
+	if err != nil {
+		slog.Warn("find related posts failed", "content", c.ID, "error", err)
+		return nil
+	}
+	return engine.PostItems(posts, p.resolveFeaturedImages(posts))
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/engine"
+	"yaaicms/internal/models"
+	"yaaicms/internal/search"
+)
+
+func TestSearchDisabled(t *testing.T) {
+	p := &Public{}
+	rec := httptest.NewRecorder()
+	p.Search(rec, httptest.NewRequest(http.MethodGet, "/search?q=go", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestSearchData(t *testing.T) {
+	var results []search.Result
+	for i := range 25 {
+		results = append(results, search.Result{Content: models.Content{Title: fmt.Sprintf("Post %d", i), Slug: fmt.Sprintf("post-%d", i)}})
+	}
+	results[10].Snippet = "a <mark>go</mark> b"
+
+	p := &Public{}
+	data := p.searchData("go & more", results, 2)
+	if data.Total != 25 || data.Page != 2 || data.TotalPages != 3 || len(data.Results) != searchPageSize {
+		t.Fatalf("searchData: got total %d, page %d of %d, %d results", data.Total, data.Page, data.TotalPages, len(data.Results))
+	}
+	if data.Results[0].Slug != "post-10" || data.Results[0].Snippet != "a <mark>go</mark> b" {
+		t.Errorf("first result: got %q %q", data.Results[0].Slug, data.Results[0].Snippet)
+	}
+	if data.PrevURL != "/search?q=go+%26+more" || data.NextURL != "/search?page=3&q=go+%26+more" {
+		t.Errorf("page links: got %q, %q", data.PrevURL, data.NextURL)
+	}
+
+	// Out of range pages clamp to the last one.
+	if data := p.searchData("go", results, 9); data.Page != 3 || len(data.Results) != 5 || data.NextURL != "" {
+		t.Errorf("last page: got page %d with %d results, next %q", data.Page, len(data.Results), data.NextURL)
+	}
+	if data := p.searchData("none", nil, 1); data.Page != 1 || data.PrevURL != "" || data.NextURL != "" {
+		t.Errorf("no results: got %+v", data)
+	}
+}
+
+func TestMarkSnippet(t *testing.T) {
+	got := markSnippet(`<mark>x</mark> <img src=x onerror=alert(1)> & "q"`)
+	want := `<mark>x</mark> &lt;img src=x onerror=alert(1)&gt; &amp; &#34;q&#34;`
+	if string(got) != want {
+		t.Errorf("markSnippet: got %q, want %q", got, want)
+	}
+}
+
+func TestSearchFallbackEscapes(t *testing.T) {
+	q := `"><script>alert(1)</script>`
+	results := []engine.SearchResult{{
+		PostItem: engine.PostItem{Title: "<b>Title</b>", Slug: "a-post"},
+		Snippet:  markSnippet("a <mark>match</mark>"),
+	}}
+	body := string(searchFallback(q, results))
+	if strings.Contains(body, "<script>alert") || strings.Contains(body, "<b>Title</b>") {
+		t.Errorf("unescaped input in fallback: %s", body)
+	}
+	if !strings.Contains(body, `href="/a-post"`) || !strings.Contains(body, "<mark>match</mark>") {
+		t.Errorf("result missing: %s", body)
+	}
+	if body := string(searchFallback(q, nil)); !strings.Contains(body, "No results.") {
+		t.Error("no-results message missing")
+	}
+}
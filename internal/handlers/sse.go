@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// sse.go provides a minimal Server-Sent Events writer used to stream AI
+// output to the admin UI as it is generated.
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"yaaicms/internal/ai"
+)
+
+// sseStreamTimeout replaces the server-wide WriteTimeout for streamed
+// responses, which would otherwise cut off long completions.
+const sseStreamTimeout = 5 * time.Minute
+
+// wantsEventStream reports whether the client asked for a streamed
+// response. Endpoints that support streaming fall back to their regular
+// response for everyone else.
+func wantsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// sseWriter writes Server-Sent Events. Every event's data is JSON, so
+// multi-line text needs no special framing on either side.
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEWriter sends the event-stream headers and extends the write
+// deadline. It must be called before anything else is written to w.
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(sseStreamTimeout)); err != nil {
+		slog.Debug("sse: cannot extend write deadline", "error", err)
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	return &sseWriter{w: w, rc: rc}
+}
+
+// Send writes one event with v encoded as JSON and flushes it to the client.
+func (s *sseWriter) Send(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("sse marshal: %w", err)
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// streamAI streams a completion to the client: one "delta" event per text
+// fragment, then a "provider" event naming the provider that answered and
+// "done" carrying finish(result), or "error" with a user-facing message.
+// When the client disconnects the request context is cancelled, which
+// aborts the upstream provider request.
+func (a *Admin) streamAI(w http.ResponseWriter, r *http.Request, task ai.TaskType, systemPrompt, userPrompt string, finish func(result string) any) {
+	a.streamCompletion(w, r, func(onDelta ai.DeltaFunc) (ai.Result, error) {
+		return a.aiRegistry.StreamForTask(r.Context(), task, systemPrompt, userPrompt, onDelta)
+	}, finish)
+}
+
+// streamAIChat is like streamAI for a multi-turn conversation.
+func (a *Admin) streamAIChat(w http.ResponseWriter, r *http.Request, task ai.TaskType, messages []ai.Message, finish func(result string) any) {
+	a.streamCompletion(w, r, func(onDelta ai.DeltaFunc) (ai.Result, error) {
+		return a.aiRegistry.StreamChatForTask(r.Context(), task, messages, onDelta)
+	}, finish)
+}
+
+// streamCompletion runs a streamed call and writes its events for
+// streamAI and streamAIChat.
+func (a *Admin) streamCompletion(w http.ResponseWriter, r *http.Request, call func(onDelta ai.DeltaFunc) (ai.Result, error), finish func(result string) any) {
+	sse := newSSEWriter(w)
+
+	res, err := call(func(delta string) error {
+		return sse.Send("delta", delta)
+	})
+	if r.Context().Err() != nil {
+		slog.Info("ai stream cancelled by client", "received_bytes", len(res.Text))
+		return
+	}
+	if err != nil {
+		slog.Error("ai stream failed", "error", err)
+		sse.Send("error", "AI request failed. Check your provider configuration.")
+		return
+	}
+
+	sse.Send("provider", newAIProviderEvent(res))
+	sse.Send("done", finish(res.Text))
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"yaaicms/internal/ai"
+)
+
+// newStreamAdmin returns an Admin with only an AI registry, which is all
+// streamAI needs.
+func newStreamAdmin(p ai.Provider) *Admin {
+	reg := ai.NewRegistry("test", map[string]ai.ProviderConfig{})
+	reg.Register("test", p)
+	return &Admin{aiRegistry: reg}
+}
+
+func TestWantsEventStream(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if wantsEventStream(r) {
+		t.Error("no Accept header should not stream")
+	}
+	r.Header.Set("Accept", "text/event-stream")
+	if !wantsEventStream(r) {
+		t.Error("Accept: text/event-stream should stream")
+	}
+}
+
+func TestSSEWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sse := newSSEWriter(rec)
+
+	if err := sse.Send("delta", "line one\nline two"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type: got %q", ct)
+	}
+	if !rec.Flushed {
+		t.Error("events should be flushed")
+	}
+	want := "event: delta\ndata: \"line one\\nline two\"\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body: got %q, want %q", got, want)
+	}
+}
+
+func TestStreamAI_DeltasThenDone(t *testing.T) {
+	a := newStreamAdmin(&mockAIProvider{name: "test", response: "Hello world"})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-content", nil)
+	rec := httptest.NewRecorder()
+	a.streamAI(rec, req, ai.TaskContent, "sys", "user", func(result string) any {
+		return "<p>" + result + "</p>"
+	})
+
+	want := "event: delta\ndata: \"Hello\"\n\n" +
+		"event: delta\ndata: \" world\"\n\n" +
+		"event: provider\ndata: {\"provider\":\"test\",\"model\":\"\"}\n\n" +
+		"event: done\ndata: \"\\u003cp\\u003eHello world\\u003c/p\\u003e\"\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestStreamAI_ReportsFallbackProvider(t *testing.T) {
+	reg := ai.NewRegistry("claude", map[string]ai.ProviderConfig{})
+	reg.Register("claude", &mockAIProvider{name: "claude", err: &ai.APIError{Source: "claude", StatusCode: 401}})
+	reg.Register("test", &mockAIProvider{name: "test", response: "Hello"})
+	reg.SetFallbacks(ai.TaskContent, []string{"test"})
+	a := &Admin{aiRegistry: reg}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-content", nil)
+	rec := httptest.NewRecorder()
+	a.streamAI(rec, req, ai.TaskContent, "sys", "user", func(result string) any { return result })
+
+	want := "event: provider\ndata: {\"provider\":\"test\",\"model\":\"\",\"fallbackFrom\":\"claude\"}\n\n"
+	if body := rec.Body.String(); !strings.Contains(body, want) {
+		t.Errorf("expected provider event %q, got %q", want, body)
+	}
+}
+
+func TestStreamAI_ProviderError(t *testing.T) {
+	a := newStreamAdmin(&mockAIProvider{name: "test", err: errors.New("upstream down")})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-content", nil)
+	rec := httptest.NewRecorder()
+	a.streamAI(rec, req, ai.TaskContent, "sys", "user", func(result string) any {
+		t.Error("finish should not be called on error")
+		return nil
+	})
+
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "event: error\n") {
+		t.Errorf("expected an error event, got %q", body)
+	}
+	if strings.Contains(body, "upstream down") {
+		t.Error("internal error details should not reach the client")
+	}
+}
+
+func TestStreamAI_ClientDisconnect(t *testing.T) {
+	a := newStreamAdmin(&mockAIProvider{name: "test", response: "never sent"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/admin/ai/generate-content", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	a.streamAI(rec, req, ai.TaskContent, "sys", "user", func(result string) any {
+		t.Error("finish should not be called after disconnect")
+		return nil
+	})
+
+	if strings.Contains(rec.Body.String(), "event: done") || strings.Contains(rec.Body.String(), "event: error") {
+		t.Errorf("nothing but deltas should be written after disconnect, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// warmer.go implements the L2 cache warmer. After a bulk invalidation
+// (template update or activation) every public URL would otherwise be
+// rendered on the first visitor's request; the warmer pre-renders them
+// in the background with bounded concurrency instead.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"yaaicms/internal/cache"
+	"yaaicms/internal/models"
+)
+
+// Reasons reported in WarmStatus for what started a warm run.
+const (
+	WarmReasonInvalidation = "invalidation"
+	WarmReasonSchedule     = "schedule"
+	WarmReasonManual       = "manual"
+)
+
+// WarmStatus is a snapshot of the warmer's current or last run.
+type WarmStatus struct {
+	Running    bool
+	Reason     string
+	Total      int
+	Done       int // Rendered and stored
+	Failed     int // Render errors; the page is left for the next visitor
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Percent returns the progress of the run as 0–100.
+func (s WarmStatus) Percent() int {
+	if s.Total == 0 {
+		if s.Running {
+			return 0
+		}
+		return 100
+	}
+	return (s.Done + s.Failed) * 100 / s.Total
+}
+
+// warmTarget is one cache key and the function that renders it. A nil
+// result with no error means there is nothing to cache (e.g. no homepage
+// template yet) and is counted as done.
+type warmTarget struct {
+	key    string
+	render func() ([]byte, error)
+}
+
+// CacheWarmer re-renders public pages into the L2 page cache. Runs are
+// queued with Trigger and executed one at a time by the loop started
+// with Start; triggers arriving during a run coalesce into one follow-up
+// run so content changed mid-warm is picked up.
+type CacheWarmer struct {
+	public      *Public
+	concurrency int
+	interval    time.Duration
+
+	// Overridable in tests.
+	targets func() []warmTarget
+	store   func(ctx context.Context, key string, html []byte)
+
+	trigger chan string
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	status WarmStatus
+}
+
+// NewCacheWarmer creates a warmer rendering through the public handlers.
+// concurrency bounds parallel renders; interval > 0 also warms on a
+// fixed schedule.
+func NewCacheWarmer(public *Public, concurrency int, interval time.Duration) *CacheWarmer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	cw := &CacheWarmer{
+		public:      public,
+		concurrency: concurrency,
+		interval:    interval,
+		trigger:     make(chan string, 1),
+		done:        make(chan struct{}),
+	}
+	cw.targets = cw.publicTargets
+	cw.store = public.pageCache.Set
+	return cw
+}
+
+// Start runs the warmer loop in a background goroutine until ctx is
+// cancelled. Use Wait to block until it has stopped.
+func (cw *CacheWarmer) Start(ctx context.Context) {
+	go cw.run(ctx)
+}
+
+// Wait blocks until the loop started by Start has exited. An in-progress
+// run stops dispatching new renders on cancellation and waits only for
+// those already in flight.
+func (cw *CacheWarmer) Wait() {
+	<-cw.done
+}
+
+// Trigger queues a warm run. Never blocks: if a run is already queued the
+// call is a no-op.
+func (cw *CacheWarmer) Trigger(reason string) {
+	select {
+	case cw.trigger <- reason:
+	default:
+	}
+}
+
+// Status returns a snapshot of the current or most recent run.
+func (cw *CacheWarmer) Status() WarmStatus {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+	return cw.status
+}
+
+// run is the warmer loop: one warm per trigger or schedule tick.
+func (cw *CacheWarmer) run(ctx context.Context) {
+	defer close(cw.done)
+
+	var tick <-chan time.Time
+	if cw.interval > 0 {
+		ticker := time.NewTicker(cw.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case reason := <-cw.trigger:
+			cw.warm(ctx, reason)
+		case <-tick:
+			cw.warm(ctx, WarmReasonSchedule)
+		}
+	}
+}
+
+// warm renders every target into the page cache with at most
+// cw.concurrency renders in flight.
+func (cw *CacheWarmer) warm(ctx context.Context, reason string) {
+	targets := cw.targets()
+
+	cw.mu.Lock()
+	cw.status = WarmStatus{Running: true, Reason: reason, Total: len(targets), StartedAt: time.Now()}
+	cw.mu.Unlock()
+
+	slog.Info("cache warm started", "reason", reason, "targets", len(targets))
+
+	sem := make(chan struct{}, cw.concurrency)
+	var wg sync.WaitGroup
+
+dispatch:
+	for _, t := range targets {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			html, err := t.render()
+			if err != nil {
+				slog.Warn("cache warm render failed", "key", t.key, "error", err)
+			} else if html != nil {
+				cw.store(ctx, t.key, html)
+			}
+
+			cw.mu.Lock()
+			if err != nil {
+				cw.status.Failed++
+			} else {
+				cw.status.Done++
+			}
+			cw.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	cw.mu.Lock()
+	cw.status.Running = false
+	cw.status.FinishedAt = time.Now()
+	st := cw.status
+	cw.mu.Unlock()
+
+	slog.Info("cache warm finished",
+		"reason", reason,
+		"done", st.Done,
+		"failed", st.Failed,
+		"total", st.Total,
+		"duration", st.FinishedAt.Sub(st.StartedAt).Round(time.Millisecond),
+		"cancelled", ctx.Err() != nil,
+	)
+}
+
+// WarmKey renders a single cache key (a content key or
+// cache.HomepageKey()) synchronously and stores it. Used by the per-page
+// warm button.
+func (cw *CacheWarmer) WarmKey(ctx context.Context, key string) error {
+	var t warmTarget
+	if key == cache.HomepageKey() {
+		t = cw.homepageTarget()
+	} else {
+		_, slug := cache.ParseSlugKey(key)
+		c, err := cw.public.contentStore.FindBySlug(slug)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return fmt.Errorf("no published content with slug %q", slug)
+		}
+		t = cw.contentTarget(c)
+	}
+
+	html, err := t.render()
+	if err != nil {
+		return err
+	}
+	if html == nil {
+		return fmt.Errorf("nothing to render for %q", key)
+	}
+	cw.store(ctx, t.key, html)
+	return nil
+}
+
+// publicTargets lists every URL the public site serves from the L2
+// cache: the homepage plus each published page, post and translation.
+func (cw *CacheWarmer) publicTargets() []warmTarget {
+	targets := []warmTarget{cw.homepageTarget()}
+
+	for _, ct := range []models.ContentType{models.ContentTypePage, models.ContentTypePost} {
+		items, err := cw.public.contentStore.ListPublishedByType(ct)
+		if err != nil {
+			slog.Error("cache warm: list published content failed", "type", ct, "error", err)
+			continue
+		}
+		for i := range items {
+			targets = append(targets, cw.contentTarget(&items[i]))
+		}
+	}
+
+	if cw.public.translations != nil {
+		items, err := cw.public.translations.ListPublished()
+		if err != nil {
+			slog.Error("cache warm: list published translations failed", "error", err)
+		}
+		for i := range items {
+			targets = append(targets, cw.contentTarget(&items[i]))
+		}
+	}
+	return targets
+}
+
+func (cw *CacheWarmer) homepageTarget() warmTarget {
+	return warmTarget{
+		key:    cache.HomepageKey(),
+		render: func() ([]byte, error) { return cw.public.renderHomepage(), nil },
+	}
+}
+
+func (cw *CacheWarmer) contentTarget(c *models.Content) warmTarget {
+	p := cw.public
+	return warmTarget{
+		key: contentCacheKey(p.contentLocale(c.ID), c.Slug),
+		render: func() ([]byte, error) {
+			return p.renderContent(c)
+		},
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestWarmer creates a warmer with fake targets and an in-memory store,
+// so the warm loop can be tested without PostgreSQL or Valkey.
+func newTestWarmer(concurrency int, targets []warmTarget) (*CacheWarmer, map[string][]byte) {
+	cw := NewCacheWarmer(&Public{}, concurrency, 0)
+	stored := make(map[string][]byte)
+	var mu sync.Mutex
+	cw.targets = func() []warmTarget { return targets }
+	cw.store = func(_ context.Context, key string, html []byte) {
+		mu.Lock()
+		stored[key] = html
+		mu.Unlock()
+	}
+	return cw, stored
+}
+
+func TestCacheWarmerWarm(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	var targets []warmTarget
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("page-%d", i)
+		targets = append(targets, warmTarget{
+			key: key,
+			render: func() ([]byte, error) {
+				n := inFlight.Add(1)
+				for {
+					m := maxInFlight.Load()
+					if n <= m || maxInFlight.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				inFlight.Add(-1)
+				return []byte("<p>" + key + "</p>"), nil
+			},
+		})
+	}
+
+	cw, stored := newTestWarmer(3, targets)
+	cw.warm(context.Background(), WarmReasonManual)
+
+	if len(stored) != 20 {
+		t.Errorf("stored %d pages, want 20", len(stored))
+	}
+	if string(stored["page-7"]) != "<p>page-7</p>" {
+		t.Errorf("page-7: got %q", stored["page-7"])
+	}
+	if got := maxInFlight.Load(); got > 3 {
+		t.Errorf("max concurrent renders = %d, want <= 3", got)
+	}
+
+	st := cw.Status()
+	if st.Running {
+		t.Error("status should not be running after warm")
+	}
+	if st.Done != 20 || st.Failed != 0 || st.Total != 20 {
+		t.Errorf("status = %+v, want 20 done of 20", st)
+	}
+	if st.Reason != WarmReasonManual {
+		t.Errorf("reason = %q, want %q", st.Reason, WarmReasonManual)
+	}
+	if st.Percent() != 100 {
+		t.Errorf("Percent() = %d, want 100", st.Percent())
+	}
+}
+
+func TestCacheWarmerFailuresAndEmptyRenders(t *testing.T) {
+	targets := []warmTarget{
+		{key: "ok", render: func() ([]byte, error) { return []byte("ok"), nil }},
+		{key: "broken", render: func() ([]byte, error) { return nil, errors.New("template error") }},
+		{key: "_homepage", render: func() ([]byte, error) { return nil, nil }},
+	}
+	cw, stored := newTestWarmer(2, targets)
+	cw.warm(context.Background(), WarmReasonInvalidation)
+
+	if _, ok := stored["broken"]; ok {
+		t.Error("failed render should not be stored")
+	}
+	if _, ok := stored["_homepage"]; ok {
+		t.Error("nil render should not be stored")
+	}
+	if _, ok := stored["ok"]; !ok {
+		t.Error("successful render should be stored")
+	}
+
+	st := cw.Status()
+	if st.Done != 2 || st.Failed != 1 {
+		t.Errorf("status = %+v, want 2 done and 1 failed", st)
+	}
+}
+
+func TestCacheWarmerCancelledStopsDispatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var rendered atomic.Int32
+
+	var targets []warmTarget
+	for i := 0; i < 10; i++ {
+		targets = append(targets, warmTarget{
+			key: fmt.Sprintf("page-%d", i),
+			render: func() ([]byte, error) {
+				// The first render cancels the run; later ones must not start.
+				rendered.Add(1)
+				cancel()
+				return []byte("x"), nil
+			},
+		})
+	}
+
+	cw, _ := newTestWarmer(1, targets)
+	cw.warm(ctx, WarmReasonManual)
+
+	if got := rendered.Load(); got >= 10 {
+		t.Errorf("rendered %d targets after cancellation, want fewer than 10", got)
+	}
+	if cw.Status().Running {
+		t.Error("status should not be running after a cancelled warm")
+	}
+}
+
+func TestCacheWarmerLoop(t *testing.T) {
+	warmed := make(chan struct{}, 4)
+	targets := []warmTarget{{key: "a", render: func() ([]byte, error) {
+		warmed <- struct{}{}
+		return []byte("a"), nil
+	}}}
+	cw, _ := newTestWarmer(1, targets)
+
+	// Triggers before the loop starts coalesce into a single queued run.
+	cw.Trigger(WarmReasonInvalidation)
+	cw.Trigger(WarmReasonInvalidation)
+	cw.Trigger(WarmReasonInvalidation)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cw.Start(ctx)
+
+	select {
+	case <-warmed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for triggered warm")
+	}
+
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		cw.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Wait did not return after cancellation")
+	}
+
+	if n := len(warmed); n != 0 {
+		t.Errorf("expected coalesced triggers to run once, got %d extra runs", n)
+	}
+}
+
+func TestWarmStatusPercent(t *testing.T) {
+	tests := []struct {
+		status WarmStatus
+		want   int
+	}{
+		{WarmStatus{}, 100},
+		{WarmStatus{Running: true}, 0},
+		{WarmStatus{Running: true, Total: 4, Done: 1}, 25},
+		{WarmStatus{Running: true, Total: 4, Done: 1, Failed: 1}, 50},
+	}
+	for _, tt := range tests {
+		if got := tt.status.Percent(); got != tt.want {
+			t.Errorf("Percent(%+v) = %d, want %d", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestWriteWarmStatus(t *testing.T) {
+	t.Run("running polls", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		writeWarmStatus(w, WarmStatus{Running: true, Reason: WarmReasonManual, Total: 10, Done: 4})
+		body := w.Body.String()
+		if !strings.Contains(body, `hx-trigger="every 2s"`) {
+			t.Error("running widget should poll for progress")
+		}
+		if !strings.Contains(body, "4 of 10") {
+			t.Errorf("expected progress count in body: %s", body)
+		}
+		if strings.Contains(body, "Warm cache now") {
+			t.Error("running widget should not offer a new run")
+		}
+	})
+
+	t.Run("finished offers manual run", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		start := time.Now().Add(-time.Second)
+		writeWarmStatus(w, WarmStatus{Reason: WarmReasonSchedule, Total: 3, Done: 2, Failed: 1, StartedAt: start, FinishedAt: time.Now()})
+		body := w.Body.String()
+		if strings.Contains(body, "every 2s") {
+			t.Error("finished widget should not poll")
+		}
+		if !strings.Contains(body, "Warm cache now") {
+			t.Error("finished widget should offer a manual run")
+		}
+		if !strings.Contains(body, "1 pages failed") {
+			t.Errorf("expected failure note in body: %s", body)
+		}
+	})
+}
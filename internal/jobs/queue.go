@@ -0,0 +1,418 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// Package jobs runs background AI work from a queue stored in Postgres.
+// Jobs survive restarts, are retried with backoff, report progress, and
+// can be processed by workers in any number of replicas.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+	"yaaicms/internal/store"
+)
+
+const (
+	// DefaultWorkers is the number of jobs run concurrently per replica.
+	DefaultWorkers = 2
+
+	// pollInterval is how often idle workers look for due jobs. Jobs
+	// enqueued by this replica wake a worker at once.
+	pollInterval = 2 * time.Second
+
+	// jobTimeout bounds a single attempt.
+	jobTimeout = 10 * time.Minute
+
+	// heartbeatInterval is how often a running job's lock is refreshed;
+	// staleAfter is how old a lock must be before the job is requeued.
+	heartbeatInterval = time.Minute
+	staleAfter        = 5 * time.Minute
+
+	// maintenanceInterval is how often stale jobs are requeued and old
+	// finished jobs pruned; retention is how long finished jobs are kept.
+	maintenanceInterval = 10 * time.Minute
+	retention           = 7 * 24 * time.Hour
+
+	// retryBase is the delay before the first retry, doubled per attempt
+	// up to retryMax.
+	retryBase = 30 * time.Second
+	retryMax  = 30 * time.Minute
+)
+
+// Handler runs one attempt of a job. The returned result is stored as
+// JSON. Returning an error retries the job until its attempts are used
+// up, unless the error is wrapped with Permanent.
+type Handler func(ctx context.Context, job *Job) (any, error)
+
+// Job is a claimed job passed to its Handler.
+type Job struct {
+	*models.AIJob
+	store *store.AIJobStore
+}
+
+// Decode unmarshals the job's payload into v.
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal([]byte(j.Payload), v); err != nil {
+		return Permanent(fmt.Errorf("decode %s payload: %w", j.Kind, err))
+	}
+	return nil
+}
+
+// Progress records that done of total items are finished. Failures are
+// logged; progress is informational.
+func (j *Job) Progress(ctx context.Context, done, total int) {
+	if err := j.store.SetProgress(ctx, j.ID, j.Attempts, done, total); err != nil {
+		slog.Warn("ai job progress failed", "id", j.ID, "error", err)
+	}
+}
+
+// LastAttempt reports whether a failure of this attempt will be final.
+func (j *Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// permanentError marks an error that retrying can't fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails without further retries.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Options are the per-job settings for Enqueue.
+type Options struct {
+	MaxAttempts int        // Zero means 3
+	CreatedBy   *uuid.UUID // User the job runs for, if any
+}
+
+// Queue enqueues jobs and runs them with a fixed pool of workers.
+type Queue struct {
+	store    *store.AIJobStore
+	workers  int
+	handlers map[string]Handler
+
+	wake chan struct{}
+	wg   sync.WaitGroup
+
+	// jobCtx is the parent of every running attempt. It outlives the
+	// context passed to Start so in-flight jobs can finish during
+	// shutdown; Drain cancels it when its deadline is reached.
+	jobCtx    context.Context
+	cancelJob context.CancelFunc
+}
+
+// NewQueue creates a queue backed by s with the given number of workers
+// (DefaultWorkers if workers < 1).
+func NewQueue(s *store.AIJobStore, workers int) *Queue {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	jobCtx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		store:     s,
+		workers:   workers,
+		handlers:  make(map[string]Handler),
+		wake:      make(chan struct{}, 1),
+		jobCtx:    jobCtx,
+		cancelJob: cancel,
+	}
+}
+
+// Register sets the handler for a job kind. Call before Start.
+func (q *Queue) Register(kind string, h Handler) {
+	q.handlers[kind] = h
+}
+
+// Enqueue stores a pending job with payload marshalled to JSON and wakes
+// a worker.
+func (q *Queue) Enqueue(kind string, payload any, opts Options) (*models.AIJob, error) {
+	if _, ok := q.handlers[kind]; !ok {
+		return nil, fmt.Errorf("jobs: no handler for %q", kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: marshal %s payload: %w", kind, err)
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 3
+	}
+
+	job, err := q.store.Enqueue(kind, string(data), opts.MaxAttempts, opts.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Find returns a job, or nil if it doesn't exist.
+func (q *Queue) Find(id uuid.UUID) (*models.AIJob, error) {
+	return q.store.FindByID(id)
+}
+
+// List returns recent jobs, only createdBy's if it is non-nil.
+func (q *Queue) List(createdBy *uuid.UUID, limit int) ([]models.AIJob, error) {
+	return q.store.ListRecent(createdBy, limit)
+}
+
+// Cancel cancels a pending job. Returns false if it already started.
+func (q *Queue) Cancel(id uuid.UUID) (bool, error) {
+	return q.store.Cancel(id)
+}
+
+// Resubmit runs a failed or cancelled job again from scratch.
+func (q *Queue) Resubmit(id uuid.UUID) (bool, error) {
+	ok, err := q.store.Resubmit(id)
+	if ok {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+	return ok, err
+}
+
+// Start launches the workers and the maintenance loop. Workers stop
+// claiming jobs when ctx is cancelled; use Drain to wait for the jobs
+// already running.
+func (q *Queue) Start(ctx context.Context) {
+	// Jobs left running by a previous process are requeued once their
+	// lock goes stale; the maintenance loop checks at once on startup.
+	q.wg.Add(q.workers + 1)
+	go q.maintain(ctx)
+	for i := 0; i < q.workers; i++ {
+		go q.work(ctx)
+	}
+	slog.Info("ai job workers started", "workers", q.workers)
+}
+
+// Drain waits for the workers to finish their current jobs after the
+// context passed to Start is cancelled. If ctx expires first, running
+// jobs are cancelled and put back to pending for the next start.
+func (q *Queue) Drain(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("ai job drain timed out, requeueing running jobs")
+		q.cancelJob()
+		<-done
+	}
+	q.cancelJob()
+}
+
+// work is a worker loop: run due jobs until none is left, then wait for
+// a wake-up or the next poll.
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			job, err := q.store.Claim(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("ai job claim failed", "error", err)
+				}
+				break
+			}
+			if job == nil {
+				break
+			}
+			q.run(job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// run executes one attempt of job and records the outcome.
+func (q *Queue) run(m *models.AIJob) {
+	ctx, cancel := context.WithTimeout(q.jobCtx, jobTimeout)
+	defer cancel()
+
+	// Keep the lock fresh while the handler runs.
+	beat := make(chan struct{})
+	defer close(beat)
+	go func() {
+		t := time.NewTicker(heartbeatInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-beat:
+				return
+			case <-t.C:
+				if err := q.store.Heartbeat(ctx, m.ID, m.Attempts); err != nil {
+					slog.Warn("ai job heartbeat failed", "id", m.ID, "error", err)
+				}
+			}
+		}
+	}()
+
+	job := &Job{AIJob: m, store: q.store}
+	start := time.Now()
+	result, err := q.call(ctx, job)
+
+	// Record the outcome even if the job's context was cancelled.
+	rctx, rcancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer rcancel()
+
+	switch {
+	case err == nil:
+		data, merr := json.Marshal(result)
+		if merr != nil {
+			err = fmt.Errorf("marshal result: %w", merr)
+			q.fail(rctx, job, err)
+			return
+		}
+		if ok, err := q.store.Complete(rctx, m.ID, m.Attempts, string(data)); err != nil {
+			slog.Error("ai job complete failed", "id", m.ID, "error", err)
+		} else if !ok {
+			lostClaim(m)
+			return
+		}
+		slog.Info("ai job succeeded", "id", m.ID, "kind", m.Kind, "attempt", m.Attempts, "duration", time.Since(start).Round(time.Millisecond))
+
+	case q.jobCtx.Err() != nil:
+		// Shutdown cut the job short; it runs again after the restart.
+		if ok, err := q.store.Release(rctx, m.ID, m.Attempts); err != nil {
+			slog.Error("ai job release failed", "id", m.ID, "error", err)
+		} else if !ok {
+			lostClaim(m)
+			return
+		}
+		slog.Info("ai job interrupted by shutdown, requeued", "id", m.ID, "kind", m.Kind)
+
+	case IsPermanent(err) || job.LastAttempt():
+		q.fail(rctx, job, err)
+
+	default:
+		delay := backoff(m.Attempts)
+		if ok, err := q.store.Retry(rctx, m.ID, m.Attempts, err.Error(), time.Now().Add(delay)); err != nil {
+			slog.Error("ai job retry failed", "id", m.ID, "error", err)
+		} else if !ok {
+			lostClaim(m)
+			return
+		}
+		slog.Warn("ai job failed, will retry", "id", m.ID, "kind", m.Kind, "attempt", m.Attempts, "retry_in", delay, "error", err)
+	}
+}
+
+// call runs the job's handler, turning a panic into a permanent error.
+func (q *Queue) call(ctx context.Context, job *Job) (result any, err error) {
+	h, ok := q.handlers[job.Kind]
+	if !ok {
+		return nil, Permanent(fmt.Errorf("no handler for %q", job.Kind))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(fmt.Errorf("panic: %v", r))
+		}
+	}()
+	return h(ctx, job)
+}
+
+// fail marks the job as failed for good.
+func (q *Queue) fail(ctx context.Context, job *Job, err error) {
+	if ok, ferr := q.store.Fail(ctx, job.ID, job.Attempts, err.Error()); ferr != nil {
+		slog.Error("ai job fail failed", "id", job.ID, "error", ferr)
+	} else if !ok {
+		lostClaim(job.AIJob)
+		return
+	}
+	slog.Error("ai job failed", "id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+}
+
+// lostClaim logs an attempt whose outcome was dropped because the job is
+// no longer running under this claim: it was requeued as stale, and
+// possibly claimed again.
+func lostClaim(m *models.AIJob) {
+	slog.Warn("ai job outcome dropped, the job was requeued while running", "id", m.ID, "kind", m.Kind, "attempt", m.Attempts)
+}
+
+// maintain requeues jobs whose worker died, or fails them if they have
+// no attempts left, and prunes old finished jobs, at startup and then
+// every maintenanceInterval.
+func (q *Queue) maintain(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+	for {
+		if n, failed, err := q.store.RequeueStale(ctx, staleAfter); err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("ai job requeue failed", "error", err)
+			}
+		} else {
+			if failed > 0 {
+				slog.Warn("stale ai jobs failed, no attempts left", "jobs", failed)
+			}
+			if n > 0 {
+				slog.Info("ai jobs requeued", "jobs", n)
+				select {
+				case q.wake <- struct{}{}:
+				default:
+				}
+			}
+		}
+		if n, err := q.store.Prune(ctx, retention); err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("ai job prune failed", "error", err)
+			}
+		} else if n > 0 {
+			slog.Info("ai jobs pruned", "jobs", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backoff returns the delay before retrying after the given attempt.
+func backoff(attempt int) time.Duration {
+	d := retryBase
+	for i := 1; i < attempt && d < retryMax; i++ {
+		d *= 2
+	}
+	return min(d, retryMax)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"yaaicms/internal/models"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{7, 30 * time.Minute},
+		{50, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	base := errors.New("bad key")
+	err := fmt.Errorf("generate: %w", Permanent(base))
+
+	if !IsPermanent(err) {
+		t.Error("wrapped permanent error not detected")
+	}
+	if !errors.Is(err, base) {
+		t.Error("permanent error should unwrap to its cause")
+	}
+	if IsPermanent(base) {
+		t.Error("plain error reported as permanent")
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) should be nil")
+	}
+}
+
+func TestJobDecode(t *testing.T) {
+	job := &Job{AIJob: &models.AIJob{Kind: "test", Payload: `{"prompt":"a cat"}`}}
+	var p struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := job.Decode(&p); err != nil || p.Prompt != "a cat" {
+		t.Errorf("Decode: got %+v, %v", p, err)
+	}
+
+	job.Payload = `{not json`
+	if err := job.Decode(&p); !IsPermanent(err) {
+		t.Errorf("bad payload should be a permanent error, got %v", err)
+	}
+}
+
+func TestJobLastAttempt(t *testing.T) {
+	job := &Job{AIJob: &models.AIJob{Attempts: 1, MaxAttempts: 3}}
+	if job.LastAttempt() {
+		t.Error("attempt 1 of 3 reported as last")
+	}
+	job.Attempts = 3
+	if !job.LastAttempt() {
+		t.Error("attempt 3 of 3 not reported as last")
+	}
+}
+
+func TestEnqueueUnknownKind(t *testing.T) {
+	q := NewQueue(nil, 0)
+	if q.workers != DefaultWorkers {
+		t.Errorf("workers: got %d, want %d", q.workers, DefaultWorkers)
+	}
+	if _, err := q.Enqueue("missing", nil, Options{}); err == nil {
+		t.Error("expected an error for a kind without a handler")
+	}
+}
+
+func TestCallRecoversPanic(t *testing.T) {
+	q := NewQueue(nil, 1)
+	q.Register("boom", func(ctx context.Context, job *Job) (any, error) {
+		panic("nil map")
+	})
+	q.Register("ok", func(ctx context.Context, job *Job) (any, error) {
+		return job.Kind, nil
+	})
+
+	_, err := q.call(context.Background(), &Job{AIJob: &models.AIJob{Kind: "boom"}})
+	if !IsPermanent(err) {
+		t.Errorf("panic should become a permanent error, got %v", err)
+	}
+
+	got, err := q.call(context.Background(), &Job{AIJob: &models.AIJob{Kind: "ok"}})
+	if err != nil || got != "ok" {
+		t.Errorf("call: got %v, %v", got, err)
+	}
+
+	if _, err := q.call(context.Background(), &Job{AIJob: &models.AIJob{Kind: "gone"}}); !IsPermanent(err) {
+		t.Errorf("unknown kind should be a permanent error, got %v", err)
+	}
+}
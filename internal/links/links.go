@@ -0,0 +1,290 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// Package links finds phrases in a post or page body that could link to
+// other content, and writes those links into the body. It works on the
+// source text, Markdown or HTML, and never places a link inside an
+// existing link, a heading, code or markup.
+package links
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// MinPhraseWords is the number of words a title or keyword needs to be
+// suggested as anchor text by itself. Shorter phrases, like a page
+// called "About", match too much ordinary text.
+const MinPhraseWords = 2
+
+// Target is content a body can link to.
+type Target struct {
+	ID      uuid.UUID
+	Title   string
+	URL     string
+	Phrases []string // Anchor texts to look for, best first
+}
+
+// Suggestion is a proposed link: the first linkable occurrence of Anchor
+// in the body, at Start:End, pointing at Target.
+type Suggestion struct {
+	Target     Target
+	Anchor     string // As written in the body
+	Start, End int
+	FromAI     bool
+}
+
+// Body is a post or page body with the parts that can't take a link
+// marked.
+type Body struct {
+	text    string
+	format  models.BodyFormat
+	blocked []bool // per byte of text
+}
+
+// Parse prepares a body for finding and inserting links.
+func Parse(text string, format models.BodyFormat) *Body {
+	b := &Body{text: text, format: format, blocked: make([]bool, len(text))}
+	if format != models.BodyFormatHTML {
+		b.blockMarkdown()
+	}
+	b.blockHTML()
+	return b
+}
+
+// Text returns the body's source text.
+func (b *Body) Text() string {
+	return b.text
+}
+
+var (
+	fenceRe     = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	headingRe   = regexp.MustCompile(`^\s{0,3}(#{1,6}\s|#{1,6}$)`)
+	refDefRe    = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:`)
+	setextRe    = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	inlineMDRes = []*regexp.Regexp{
+		regexp.MustCompile("`[^`\n]+`"),                // code spans
+		regexp.MustCompile(`!?\[[^\]]*\]\([^)\n]*\)`),  // links and images
+		regexp.MustCompile(`!?\[[^\]]*\]\[[^\]\n]*\]`), // reference links
+		regexp.MustCompile(`https?://[^\s)<>]+`),       // bare URLs
+	}
+	tagRe        = regexp.MustCompile(`<[^>]*>`)
+	htmlElemsRes = blockedElements("a", "code", "pre", "h1", "h2", "h3", "h4", "h5", "h6", "script", "style", "figcaption")
+)
+
+// blockedElements returns patterns matching the given HTML elements with
+// their content.
+func blockedElements(names ...string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(names))
+	for i, n := range names {
+		res[i] = regexp.MustCompile(`(?is)<` + n + `\b[^>]*>.*?</` + n + `\s*>`)
+	}
+	return res
+}
+
+// blockMarkdown marks fenced code, headings, link reference definitions,
+// code spans, existing links and images, and bare URLs.
+func (b *Body) blockMarkdown() {
+	inFence := false
+	start := 0
+	lines := strings.SplitAfter(b.text, "\n")
+	for i, line := range lines {
+		end := start + len(line)
+		fence := fenceRe.MatchString(line)
+		// A setext heading is the text line above a line of = or -.
+		setext := i+1 < len(lines) && strings.TrimSpace(line) != "" && setextRe.MatchString(lines[i+1])
+		if inFence || fence || setext || headingRe.MatchString(line) || refDefRe.MatchString(line) {
+			b.block(start, end)
+		}
+		if fence {
+			inFence = !inFence
+		}
+		start = end
+	}
+	for _, re := range inlineMDRes {
+		for _, m := range re.FindAllStringIndex(b.text, -1) {
+			b.block(m[0], m[1])
+		}
+	}
+}
+
+// blockHTML marks tags and the content of links, code, headings and
+// other elements that must not contain a new link. Markdown may contain
+// HTML too, so this runs for both formats.
+func (b *Body) blockHTML() {
+	for _, re := range htmlElemsRes {
+		for _, m := range re.FindAllStringIndex(b.text, -1) {
+			b.block(m[0], m[1])
+		}
+	}
+	for _, m := range tagRe.FindAllStringIndex(b.text, -1) {
+		b.block(m[0], m[1])
+	}
+}
+
+func (b *Body) block(start, end int) {
+	for i := start; i < end; i++ {
+		b.blocked[i] = true
+	}
+}
+
+// phrasePattern matches a phrase case-insensitively, with any run of
+// whitespace between its words.
+func phrasePattern(phrase string) *regexp.Regexp {
+	words := strings.Fields(phrase)
+	if len(words) == 0 {
+		return nil
+	}
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(words, `\s+`))
+}
+
+// Locate returns the position of the first occurrence of phrase that is
+// free text, starts and ends on a word boundary and can take a link.
+func (b *Body) Locate(phrase string) (start, end int, ok bool) {
+	re := phrasePattern(phrase)
+	if re == nil {
+		return 0, 0, false
+	}
+	for _, m := range re.FindAllStringIndex(b.text, -1) {
+		if b.linkable(m[0], m[1]) {
+			return m[0], m[1], true
+		}
+	}
+	return 0, 0, false
+}
+
+// linkable reports whether text[start:end] is whole words outside any
+// blocked region.
+func (b *Body) linkable(start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(b.text[:start]); start > 0 && isWordRune(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(b.text[end:]); end < len(b.text) && isWordRune(r) {
+		return false
+	}
+	for i := start; i < end; i++ {
+		if b.blocked[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// LinksTo reports whether the body already links to url.
+func (b *Body) LinksTo(url string) bool {
+	q := regexp.QuoteMeta(url)
+	re := regexp.MustCompile(`\]\(\s*<?` + q + `/?[\s>)#?]|(?i:href)\s*=\s*["']?` + q + `/?["'#?\s>]`)
+	return re.MatchString(b.text)
+}
+
+// Apply links the first linkable occurrence of anchor to url, in the
+// body's format, and returns the new text. ok is false when the anchor
+// is no longer in the body as linkable text.
+func (b *Body) Apply(anchor, url string) (text string, ok bool) {
+	start, end, ok := b.Locate(anchor)
+	if !ok {
+		return b.text, false
+	}
+	words := b.text[start:end]
+	var link string
+	if b.format == models.BodyFormatHTML {
+		link = `<a href="` + html.EscapeString(url) + `">` + words + `</a>`
+	} else {
+		link = "[" + words + "](" + url + ")"
+	}
+	return b.text[:start] + link + b.text[end:], true
+}
+
+// Context returns the text around a suggestion, split at the anchor, for
+// showing where the link would go. Markup is left out.
+func (b *Body) Context(s Suggestion, radius int) (before, anchor, after string) {
+	clean := func(s string) string {
+		return strings.Join(strings.Fields(tagRe.ReplaceAllString(s, " ")), " ")
+	}
+	from := max(s.Start-radius, 0)
+	for from > 0 && !utf8.RuneStart(b.text[from]) {
+		from--
+	}
+	to := min(s.End+radius, len(b.text))
+	for to < len(b.text) && !utf8.RuneStart(b.text[to]) {
+		to++
+	}
+	before, after = clean(b.text[from:s.Start]), clean(b.text[s.End:to])
+	if from > 0 {
+		before = "…" + before
+	}
+	if to < len(b.text) {
+		after += "…"
+	}
+	return before, b.text[s.Start:s.End], after
+}
+
+// Pick is an anchor chosen for a target by other means, e.g. by AI.
+type Pick struct {
+	TargetID uuid.UUID
+	Anchor   string
+}
+
+// Suggest proposes at most one link per target, in reading order. It
+// first matches each target's phrases of MinPhraseWords or more words,
+// then adds picks that are still free. Targets the body already links
+// to, overlapping anchors and picks whose anchor isn't linkable text are
+// left out.
+func Suggest(b *Body, targets []Target, picks []Pick) []Suggestion {
+	byID := make(map[uuid.UUID]Target, len(targets))
+	for _, t := range targets {
+		byID[t.ID] = t
+	}
+
+	var out []Suggestion
+	done := make(map[uuid.UUID]bool)
+	add := func(t Target, phrase string, fromAI bool) bool {
+		if done[t.ID] || b.LinksTo(t.URL) {
+			return false
+		}
+		start, end, ok := b.Locate(phrase)
+		if !ok {
+			return false
+		}
+		for _, s := range out {
+			if start < s.End && s.Start < end {
+				return false
+			}
+		}
+		out = append(out, Suggestion{Target: t, Anchor: b.text[start:end], Start: start, End: end, FromAI: fromAI})
+		done[t.ID] = true
+		return true
+	}
+
+	for _, t := range targets {
+		for _, p := range t.Phrases {
+			if len(strings.Fields(p)) >= MinPhraseWords && add(t, p, false) {
+				break
+			}
+		}
+	}
+	for _, p := range picks {
+		if t, ok := byID[p.TargetID]; ok {
+			add(t, p.Anchor, true)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package links
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+const markdownBody = "## Balcony Tomatoes\n\n" +
+	"Growing balcony tomatoes needs sun. See [balcony tomatoes](/other) and `balcony tomatoes`.\n\n" +
+	"```\nbalcony tomatoes\n```\n\n" +
+	"Later, water the Balcony\ntomatoes daily. Composting basics help too.\n"
+
+func TestLocateMarkdown(t *testing.T) {
+	b := Parse(markdownBody, models.BodyFormatMarkdown)
+
+	start, end, ok := b.Locate("balcony tomatoes")
+	if !ok || b.Text()[start:end] != "balcony tomatoes" || start != len("## Balcony Tomatoes\n\nGrowing ") {
+		t.Fatalf("Locate: %d:%d %v", start, end, ok)
+	}
+
+	// Whole words only.
+	if _, _, ok := b.Locate("compost"); ok {
+		t.Error("matched part of a word")
+	}
+	if _, _, ok := b.Locate("heading"); ok {
+		t.Error("matched missing phrase")
+	}
+}
+
+func TestApplyMarkdown(t *testing.T) {
+	text := markdownBody
+	for i, want := range []string{"[balcony tomatoes](/tomatoes)", "[Balcony\ntomatoes](/tomatoes)"} {
+		b := Parse(text, models.BodyFormatMarkdown)
+		var ok bool
+		text, ok = b.Apply("balcony tomatoes", "/tomatoes")
+		if !ok || !strings.Contains(text, want) {
+			t.Fatalf("apply %d: %v\n%s", i, ok, text)
+		}
+	}
+
+	// Every other occurrence is in a heading, link, code span or fence.
+	if _, ok := Parse(text, models.BodyFormatMarkdown).Apply("balcony tomatoes", "/tomatoes"); ok {
+		t.Errorf("linked blocked text:\n%s", text)
+	}
+}
+
+func TestApplyHTML(t *testing.T) {
+	body := `<h2>Soil mix</h2><p><a href="/x">soil mix</a> first, then <img alt="soil mix"> a good Soil Mix.</p>`
+	got, ok := Parse(body, models.BodyFormatHTML).Apply("soil mix", "/soil?a&b")
+	want := `<h2>Soil mix</h2><p><a href="/x">soil mix</a> first, then <img alt="soil mix"> a good <a href="/soil?a&amp;b">Soil Mix</a>.</p>`
+	if !ok || got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	body := "Start with composting basics, then plant balcony tomatoes. About basil: see [herbs](/herbs)."
+	b := Parse(body, models.BodyFormatMarkdown)
+
+	compost := Target{ID: uuid.New(), Title: "Composting Basics", URL: "/composting", Phrases: []string{"Composting Basics"}}
+	tomatoes := Target{ID: uuid.New(), Title: "Tomatoes", URL: "/tomatoes", Phrases: []string{"Tomatoes"}}
+	about := Target{ID: uuid.New(), Title: "About", URL: "/about", Phrases: []string{"About"}}
+	herbs := Target{ID: uuid.New(), Title: "Herb Garden Ideas", URL: "/herbs", Phrases: []string{"herb garden ideas"}}
+
+	got := Suggest(b, []Target{compost, tomatoes, about, herbs}, []Pick{
+		{TargetID: tomatoes.ID, Anchor: "balcony tomatoes"},
+		{TargetID: compost.ID, Anchor: "basics"},  // target already suggested
+		{TargetID: about.ID, Anchor: "tomatoes"},  // overlaps the tomatoes link
+		{TargetID: herbs.ID, Anchor: "basil"},     // body already links to herbs
+		{TargetID: uuid.New(), Anchor: "plant"},   // unknown target
+		{TargetID: about.ID, Anchor: "not there"}, // not in the body
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d suggestions: %+v", len(got), got)
+	}
+	if got[0].Target.ID != compost.ID || got[0].Anchor != "composting basics" || got[0].FromAI {
+		t.Errorf("first: %+v", got[0])
+	}
+	if got[1].Target.ID != tomatoes.ID || got[1].Anchor != "balcony tomatoes" || !got[1].FromAI {
+		t.Errorf("second: %+v", got[1])
+	}
+
+	before, anchor, after := b.Context(got[1], 12)
+	if before != "…then plant" || anchor != "balcony tomatoes" || after != ". About basi…" {
+		t.Errorf("context: %q %q %q", before, anchor, after)
+	}
+}
+
+func TestLinksTo(t *testing.T) {
+	tests := map[string]bool{
+		"see [x](/tomatoes)":                true,
+		"see [x](/tomatoes#care)":           true,
+		`<a href="/tomatoes">x</a>`:         true,
+		`<a HREF='/tomatoes/'>x</a>`:        true,
+		"see [x](/tomatoes-on-a-balcony)":   false,
+		"plain /tomatoes in text":           false,
+		`<a href="/tomatoes-indoors">x</a>`: false,
+	}
+	for body, want := range tests {
+		if got := Parse(body, models.BodyFormatMarkdown).LinksTo("/tomatoes"); got != want {
+			t.Errorf("LinksTo in %q = %v, want %v", body, got, want)
+		}
+	}
+}
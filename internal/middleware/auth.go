@@ -72,6 +72,20 @@ func Require2FA(next http.Handler) http.Handler {
 	})
 }
 
+// RequireEditor returns 403 unless the authenticated user is an editor or
+// an admin. Must be applied after RequireAuth and Require2FA.
+func RequireEditor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := SessionFromCtx(r.Context())
+		if sess == nil || (sess.Role != "admin" && sess.Role != "editor") {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // RequireAdmin returns 403 if the authenticated user is not an admin.
 // Must be applied after RequireAuth and Require2FA.
 func RequireAdmin(next http.Handler) http.Handler {
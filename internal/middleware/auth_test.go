@@ -340,3 +340,39 @@ func TestRequireAdmin(t *testing.T) {
 		})
 	}
 }
+
+// ---------- RequireEditor ----------
+
+func TestRequireEditor(t *testing.T) {
+	tests := []struct {
+		name           string
+		session        *session.Data
+		wantNextCalled bool
+	}{
+		{"returns 403 when session is nil", nil, false},
+		{"returns 403 when role is author", newTestSession("author", true), false},
+		{"passes through when role is editor", newTestSession("editor", true), true},
+		{"passes through when role is admin", newTestSession("admin", true), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner, called := okHandler()
+			handler := RequireEditor(inner)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/ai-bulk", nil)
+			if tt.session != nil {
+				req = req.WithContext(ctxWithSession(req.Context(), tt.session))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if *called != tt.wantNextCalled {
+				t.Errorf("next handler called: got %v, want %v", *called, tt.wantNextCalled)
+			}
+			if !tt.wantNextCalled && rr.Code != http.StatusForbidden {
+				t.Errorf("status: got %d, want %d", rr.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -36,6 +36,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return rw.ResponseWriter.Write(b)
 }
 
+// Unwrap returns the underlying ResponseWriter so http.ResponseController
+// can reach its Flush and SetWriteDeadline methods (needed for streaming).
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Logger is a structured logging middleware that records method, path,
 // status code, and request duration for every HTTP request.
 func Logger(next http.Handler) http.Handler {
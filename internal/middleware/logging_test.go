@@ -119,6 +119,18 @@ func TestResponseWriter(t *testing.T) {
 		}
 	})
 
+	t.Run("ResponseController reaches the underlying Flusher", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		rw := &responseWriter{ResponseWriter: rr, statusCode: http.StatusOK}
+
+		if err := http.NewResponseController(rw).Flush(); err != nil {
+			t.Fatalf("Flush through wrapper: %v", err)
+		}
+		if !rr.Flushed {
+			t.Error("underlying recorder should be flushed")
+		}
+	})
+
 	t.Run("Write sets default 200 status", func(t *testing.T) {
 		rr := httptest.NewRecorder()
 		rw := &responseWriter{ResponseWriter: rr, statusCode: http.StatusOK}
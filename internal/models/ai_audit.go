@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditOutcome is what became of one audited AI request.
+type AuditOutcome string
+
+const (
+	AuditOK         AuditOutcome = "ok"          // The AI answered and the answer was shown
+	AuditError      AuditOutcome = "error"       // Every AI call failed
+	AuditBlocked    AuditOutcome = "blocked"     // Moderation flagged the prompt; no AI call
+	AuditDiscarded  AuditOutcome = "discarded"   // Moderation flagged the output, which was thrown away
+	AuditOverBudget AuditOutcome = "over_budget" // Refused: the user's AI budget is used up
+	AuditNoCall     AuditOutcome = "no_call"     // The prompt was checked but the AI wasn't called (e.g. queued as a job)
+	AuditSaved      AuditOutcome = "saved"       // AI output was saved to the site; Target says where
+)
+
+// AuditOutcomes lists every outcome, in the order the audit log filter
+// shows them.
+var AuditOutcomes = []AuditOutcome{AuditOK, AuditSaved, AuditError, AuditBlocked, AuditDiscarded, AuditOverBudget, AuditNoCall}
+
+// Moderation verdicts of an audited prompt. The verdict is empty when the
+// prompt wasn't checked (background jobs, saves).
+const (
+	ModerationPassed    = "passed"
+	ModerationFlagged   = "flagged"
+	ModerationUnchecked = "unchecked" // No moderation API, or the check failed
+)
+
+// AIAudit is one admin request or background job that used AI. Prompt is
+// nil unless full prompts are kept; PromptHash is set whenever a prompt
+// was checked. Both are taken after redaction.
+type AIAudit struct {
+	ID                   uuid.UUID    `json:"id"`
+	UserID               *uuid.UUID   `json:"user_id,omitempty"`
+	UserName             string       `json:"user_name,omitempty"` // Display name of UserID, filled by queries
+	Endpoint             string       `json:"endpoint"`            // Route pattern, or "job:<kind>"
+	Provider             string       `json:"provider"`
+	Model                string       `json:"model"`
+	Calls                int          `json:"calls"`
+	PromptHash           string       `json:"prompt_hash"`
+	Prompt               *string      `json:"prompt,omitempty"`
+	ResponseChars        int          `json:"response_chars"`
+	Moderation           string       `json:"moderation"`
+	ModerationCategories string       `json:"moderation_categories,omitempty"`
+	Outcome              AuditOutcome `json:"outcome"`
+	Target               string       `json:"target,omitempty"` // What was saved, e.g. "template/<id>"
+	CreatedAt            time.Time    `json:"created_at"`
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AIBudget limits AI usage for every user with Role, or for a single user
+// (UserID) in place of their role's budget. Exactly one of Role and UserID
+// is set. Nil limits are unlimited.
+type AIBudget struct {
+	ID             uuid.UUID  `json:"id"`
+	Role           *Role      `json:"role,omitempty"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	UserName       string     `json:"user_name,omitempty"` // Display name, for user overrides
+	DailyTokens    *int64     `json:"daily_tokens,omitempty"`
+	DailyCostUSD   *float64   `json:"daily_cost_usd,omitempty"`
+	MonthlyTokens  *int64     `json:"monthly_tokens,omitempty"`
+	MonthlyCostUSD *float64   `json:"monthly_cost_usd,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// AISpend is a user's AI usage in the current day and month (UTC).
+type AISpend struct {
+	DailyTokens    int64
+	DailyCostUSD   float64
+	MonthlyTokens  int64
+	MonthlyCostUSD float64
+}
+
+// Usage returns the largest fraction of any limit that spend has used
+// (1 or more means the budget is exhausted) and names that limit, e.g.
+// "daily token". Returns 0 and "" when the budget has no limits.
+func (b *AIBudget) Usage(spend AISpend) (ratio float64, limit string) {
+	check := func(used, max float64, name string) {
+		if max <= 0 {
+			// A zero limit disables AI for the scope entirely.
+			if ratio < 1 {
+				ratio, limit = 1, name
+			}
+			return
+		}
+		if r := used / max; r > ratio {
+			ratio, limit = r, name
+		}
+	}
+	if b.DailyTokens != nil {
+		check(float64(spend.DailyTokens), float64(*b.DailyTokens), "daily token")
+	}
+	if b.DailyCostUSD != nil {
+		check(spend.DailyCostUSD, *b.DailyCostUSD, "daily cost")
+	}
+	if b.MonthlyTokens != nil {
+		check(float64(spend.MonthlyTokens), float64(*b.MonthlyTokens), "monthly token")
+	}
+	if b.MonthlyCostUSD != nil {
+		check(spend.MonthlyCostUSD, *b.MonthlyCostUSD, "monthly cost")
+	}
+	return ratio, limit
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import "testing"
+
+// TestAIBudgetUsage verifies that Usage reports the most-used limit.
+func TestAIBudgetUsage(t *testing.T) {
+	i64 := func(v int64) *int64 { return &v }
+	f64 := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name      string
+		budget    AIBudget
+		spend     AISpend
+		wantRatio float64
+		wantLimit string
+	}{
+		{name: "no limits", budget: AIBudget{}, spend: AISpend{DailyTokens: 1e9}, wantRatio: 0, wantLimit: ""},
+		{name: "daily tokens half used", budget: AIBudget{DailyTokens: i64(1000)}, spend: AISpend{DailyTokens: 500}, wantRatio: 0.5, wantLimit: "daily token"},
+		{
+			name:      "monthly cost dominates",
+			budget:    AIBudget{DailyTokens: i64(1000), MonthlyCostUSD: f64(10)},
+			spend:     AISpend{DailyTokens: 100, MonthlyCostUSD: 12},
+			wantRatio: 1.2, wantLimit: "monthly cost",
+		},
+		{name: "zero limit blocks", budget: AIBudget{DailyCostUSD: f64(0)}, spend: AISpend{}, wantRatio: 1, wantLimit: "daily cost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratio, limit := tt.budget.Usage(tt.spend)
+			if ratio != tt.wantRatio || limit != tt.wantLimit {
+				t.Errorf("Usage() = %v, %q; want %v, %q", ratio, limit, tt.wantRatio, tt.wantLimit)
+			}
+		})
+	}
+}
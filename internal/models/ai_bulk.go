@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BulkAction is the AI operation a bulk run applies to each content item.
+type BulkAction string
+
+const (
+	BulkExcerpt BulkAction = "excerpt" // Generate an excerpt
+	BulkSEO     BulkAction = "seo"     // Generate meta description and keywords
+	BulkTags    BulkAction = "tags"    // Extract tags into the meta keywords
+	BulkRewrite BulkAction = "rewrite" // Rewrite the body in a given tone
+)
+
+// BulkItemStatus is the review state of one item in a bulk run.
+type BulkItemStatus string
+
+const (
+	BulkItemQueued   BulkItemStatus = "queued"   // Waiting for its job
+	BulkItemProposed BulkItemStatus = "proposed" // Change ready for review
+	BulkItemAccepted BulkItemStatus = "accepted" // Written to the content with a revision
+	BulkItemRejected BulkItemStatus = "rejected" // Discarded by the editor
+	BulkItemFailed   BulkItemStatus = "failed"   // The job or the write failed
+)
+
+// AIBulkRun is one bulk AI operation over a filtered set of content.
+// Options holds the action's settings as JSON; Filter describes the
+// selection for display. The counts are filled by list queries.
+type AIBulkRun struct {
+	ID        uuid.UUID
+	Action    BulkAction
+	Options   string
+	Filter    string
+	CreatedBy *uuid.UUID
+	CreatedAt time.Time
+
+	Total    int
+	Queued   int
+	Proposed int
+	Accepted int
+	Rejected int
+	Failed   int
+}
+
+// AIBulkItem is one content item of a bulk run. Proposal is the proposed
+// change as JSON. ContentTitle and JobStatus are joined for display;
+// JobStatus is empty once the job has been pruned.
+type AIBulkItem struct {
+	ID         uuid.UUID
+	RunID      uuid.UUID
+	ContentID  uuid.UUID
+	JobID      *uuid.UUID
+	Status     BulkItemStatus
+	Proposal   string
+	Error      string
+	RevisionID *uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	ContentTitle string
+	ContentType  ContentType
+	JobStatus    JobStatus
+}
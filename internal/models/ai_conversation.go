@@ -0,0 +1,43 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConversationKind says which AI assistant a conversation belongs to.
+type ConversationKind string
+
+const (
+	ConversationTemplate ConversationKind = "template" // AI Design template builder
+	ConversationContent  ConversationKind = "content"  // Content editor's generator
+)
+
+// AIConversation is a saved multi-turn thread with an AI assistant. Only
+// its owner can see, resume, fork or delete it.
+type AIConversation struct {
+	ID           uuid.UUID        `json:"id"`
+	UserID       uuid.UUID        `json:"user_id"`
+	Kind         ConversationKind `json:"kind"`
+	Title        string           `json:"title"`
+	Target       string           `json:"target"`                 // Template type or content type
+	CurrentHTML  string           `json:"current_html,omitempty"` // Latest generated template
+	ForkedFrom   *uuid.UUID       `json:"forked_from,omitempty"`
+	MessageCount int              `json:"message_count"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// AIConversationMessage is one user or assistant turn. Position numbers
+// the messages of a conversation from 1.
+type AIConversationMessage struct {
+	Position  int       `json:"position"`
+	Role      string    `json:"role"` // "user" or "assistant"
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus is the lifecycle state of a background job.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // Waiting to run (or to be retried)
+	JobRunning   JobStatus = "running"   // Claimed by a worker
+	JobSucceeded JobStatus = "succeeded" // Finished; Result is set
+	JobFailed    JobStatus = "failed"    // Gave up; Error is set
+	JobCancelled JobStatus = "cancelled" // Cancelled before it ran
+)
+
+// Finished reports whether the job has reached a final state.
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// AIJob is a unit of background AI work. Payload and Result are JSON
+// documents whose shape depends on Kind.
+type AIJob struct {
+	ID            uuid.UUID  `json:"id"`
+	Kind          string     `json:"kind"`
+	Payload       string     `json:"-"`
+	Status        JobStatus  `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	RunAt         time.Time  `json:"run_at"`
+	ProgressDone  int        `json:"progress_done"`
+	ProgressTotal int        `json:"progress_total"`
+	Result        string     `json:"-"`
+	Error         string     `json:"error,omitempty"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AIUsage records the tokens, latency and estimated cost of a single AI
+// completion. UserID is nil for calls made outside a user session, and
+// CostUSD is nil when the model has no configured price.
+type AIUsage struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       *uuid.UUID `json:"user_id,omitempty"`
+	Task         string     `json:"task"`
+	Provider     string     `json:"provider"`
+	Model        string     `json:"model"`
+	InputTokens  int        `json:"input_tokens"`
+	OutputTokens int        `json:"output_tokens"`
+	LatencyMs    int        `json:"latency_ms"`
+	CostUSD      *float64   `json:"cost_usd,omitempty"`
+	Success      bool       `json:"success"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// AIUsageSummary aggregates usage over a group of calls (a day, a user,
+// a task, ...). Label is the human-readable group name.
+type AIUsageSummary struct {
+	Key          string  `json:"key"`
+	Label        string  `json:"label"`
+	Calls        int64   `json:"calls"`
+	Failed       int64   `json:"failed"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	Unpriced     int64   `json:"unpriced"` // Calls whose model had no price
+	AvgLatencyMs int64   `json:"avg_latency_ms"`
+}
+
+// TotalTokens returns input plus output tokens.
+func (s AIUsageSummary) TotalTokens() int64 {
+	return s.InputTokens + s.OutputTokens
+}
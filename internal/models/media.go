@@ -15,17 +15,19 @@ import (
 // Media represents a file uploaded to S3-compatible object storage.
 // Metadata is stored in PostgreSQL; the file itself lives in the bucket.
 type Media struct {
-	ID           uuid.UUID `json:"id"`
-	Filename     string    `json:"filename"`
-	OriginalName string    `json:"original_name"`
-	ContentType  string    `json:"content_type"`
-	SizeBytes    int64     `json:"size_bytes"`
-	Bucket       string    `json:"bucket"`
-	S3Key        string    `json:"s3_key"`
-	ThumbS3Key   *string   `json:"thumb_s3_key,omitempty"`
-	AltText      *string   `json:"alt_text,omitempty"`
-	UploaderID   uuid.UUID `json:"uploader_id"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID        `json:"id"`
+	Filename     string           `json:"filename"`
+	OriginalName string           `json:"original_name"`
+	ContentType  string           `json:"content_type"`
+	SizeBytes    int64            `json:"size_bytes"`
+	Bucket       string           `json:"bucket"`
+	S3Key        string           `json:"s3_key"`
+	ThumbS3Key   *string          `json:"thumb_s3_key,omitempty"`
+	AltText      *string          `json:"alt_text,omitempty"`
+	Caption      *string          `json:"caption,omitempty"`
+	Generation   *ImageGeneration `json:"generation,omitempty"` // Set for AI-generated images
+	UploaderID   uuid.UUID        `json:"uploader_id"`
+	CreatedAt    time.Time        `json:"created_at"`
 }
 
 // IsImage returns true if the media item is an image type.
@@ -33,6 +35,29 @@ func (m *Media) IsImage() bool {
 	return strings.HasPrefix(m.ContentType, "image/")
 }
 
+// GenerationMode is how an AI-generated image was made.
+type GenerationMode string
+
+const (
+	GenerationGenerate  GenerationMode = "generate"  // From a text prompt
+	GenerationEdit      GenerationMode = "edit"      // An existing image changed as the prompt describes
+	GenerationVariation GenerationMode = "variation" // A variation of an existing image
+)
+
+// ImageGeneration records how an AI-generated image was made, so the
+// media library can show its prompt and run it again. It is stored as
+// JSON in the media generation column.
+type ImageGeneration struct {
+	Mode           GenerationMode `json:"mode"`
+	Prompt         string         `json:"prompt,omitempty"` // Empty for variations
+	Provider       string         `json:"provider"`
+	SourceID       *uuid.UUID     `json:"source_id,omitempty"` // The edited image
+	Aspect         string         `json:"aspect,omitempty"`
+	Style          string         `json:"style,omitempty"`
+	NegativePrompt string         `json:"negative_prompt,omitempty"`
+	Seed           int64          `json:"seed,omitempty"`
+}
+
 // MediaVariant represents a responsive image variant (WebP at a specific
 // breakpoint) linked to a parent Media item. Generated by the imaging
 // pipeline on upload or regeneration.
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DescriptionStatus is the review state of an AI image description.
+type DescriptionStatus string
+
+const (
+	DescriptionQueued   DescriptionStatus = "queued"   // Waiting for its job
+	DescriptionProposed DescriptionStatus = "proposed" // Ready for review
+	DescriptionAccepted DescriptionStatus = "accepted" // Copied to the media item
+	DescriptionRejected DescriptionStatus = "rejected" // Discarded by the editor
+	DescriptionFailed   DescriptionStatus = "failed"   // The job failed
+)
+
+// MediaDescription is the AI-suggested alt text and caption for an image.
+// The media fields are joined for the review queue.
+type MediaDescription struct {
+	MediaID    uuid.UUID
+	JobID      *uuid.UUID
+	Status     DescriptionStatus
+	AltText    string
+	Caption    string
+	Error      string
+	ReviewedBy *uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	OriginalName string
+	Bucket       string
+	S3Key        string
+	ThumbS3Key   *string
+	MediaAlt     *string // The media item's current alt text
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Prompt is one saved version of the system prompt for an AI task. The
+// highest version of a task is the one in use.
+type Prompt struct {
+	ID            uuid.UUID  `json:"id"`
+	Task          string     `json:"task"`
+	Version       int        `json:"version"`
+	Body          string     `json:"body"`
+	Note          string     `json:"note"` // What changed, e.g. "Restored version 2"
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedByName string     `json:"created_by_name,omitempty"` // Display name of CreatedBy
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -18,6 +18,7 @@ const (
 	TemplateTypeFooter      TemplateType = "footer"
 	TemplateTypePage        TemplateType = "page"
 	TemplateTypeArticleLoop TemplateType = "article_loop"
+	TemplateTypeSearch      TemplateType = "search"
 )
 
 // Template represents an AI-generated HTML+TailwindCSS template stored in
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContentTranslation links a content item (the source, in the site's
+// default language) to a translation of it in Locale. Title, Slug and
+// Status belong to the translation and are filled by list queries.
+type ContentTranslation struct {
+	ID            uuid.UUID
+	SourceID      uuid.UUID
+	TranslationID uuid.UUID
+	Locale        string
+	CreatedAt     time.Time
+
+	Title  string
+	Slug   string
+	Status ContentStatus
+}
+
+// Language is a site or translation language.
+type Language struct {
+	Code   string // ISO 639-1 code, used as the locale and URL prefix
+	Name   string // English name, shown in the admin
+	Native string // Name in the language itself, shown to visitors
+}
+
+// Languages lists the languages offered for the site and for
+// translations, in the order of the settings page.
+var Languages = []Language{
+	{"en", "English", "English"},
+	{"es", "Spanish", "Español"},
+	{"fr", "French", "Français"},
+	{"de", "German", "Deutsch"},
+	{"it", "Italian", "Italiano"},
+	{"pt", "Portuguese", "Português"},
+	{"nl", "Dutch", "Nederlands"},
+	{"ro", "Romanian", "Română"},
+	{"pl", "Polish", "Polski"},
+	{"sv", "Swedish", "Svenska"},
+	{"ja", "Japanese", "日本語"},
+	{"ko", "Korean", "한국어"},
+	{"zh", "Chinese", "中文"},
+	{"ar", "Arabic", "العربية"},
+	{"hi", "Hindi", "हिन्दी"},
+}
+
+// LanguageByCode returns the language with the given code, or false.
+func LanguageByCode(code string) (Language, bool) {
+	for _, l := range Languages {
+		if l.Code == code {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package models
+
+import "testing"
+
+// TestLanguageByCode verifies lookups of the supported site languages.
+func TestLanguageByCode(t *testing.T) {
+	if l, ok := LanguageByCode("de"); !ok || l.Name != "German" {
+		t.Errorf("LanguageByCode(de) = %+v, %v", l, ok)
+	}
+	for _, code := range []string{"", "xx", "DE", "deu"} {
+		if _, ok := LanguageByCode(code); ok {
+			t.Errorf("LanguageByCode(%q) found a language", code)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, l := range Languages {
+		if len(l.Code) != 2 || seen[l.Code] {
+			t.Errorf("invalid or duplicate language code %q", l.Code)
+		}
+		seen[l.Code] = true
+	}
+}
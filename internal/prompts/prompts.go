@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// Package prompts is the library of system prompts the AI features use.
+// Every task has a built-in default; admins save new versions of it,
+// which are kept in the database, and can roll back to any earlier one.
+// Prompts may contain {{placeholders}}, filled in each time they are used.
+package prompts
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+	"yaaicms/internal/store"
+)
+
+// Vars maps placeholder names to their values.
+type Vars map[string]string
+
+// placeholderRe matches a {{name}} placeholder. Go template actions like
+// {{.Title}} never match, so template prompts can show them verbatim.
+var placeholderRe = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// Render replaces the placeholders in body that have a value in vars.
+// Others are left as written.
+func Render(body string, vars Vars) string {
+	return placeholderRe.ReplaceAllStringFunc(body, func(m string) string {
+		if v, ok := vars[placeholderRe.FindStringSubmatch(m)[1]]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// Uses reports whether body contains the named placeholder.
+func Uses(body, name string) bool {
+	for _, m := range placeholderRe.FindAllStringSubmatch(body, -1) {
+		if m[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Unknown returns the placeholders in body that task doesn't fill in,
+// once each, in order of appearance.
+func Unknown(task Task, body string) []string {
+	known := make(map[string]bool)
+	for _, p := range Common {
+		known[p.Name] = true
+	}
+	for _, p := range task.Placeholders {
+		known[p.Name] = true
+	}
+	var out []string
+	for _, m := range placeholderRe.FindAllStringSubmatch(body, -1) {
+		if !known[m[1]] {
+			known[m[1]] = true
+			out = append(out, m[1])
+		}
+	}
+	return out
+}
+
+// Library reads and saves prompt versions. A nil Library, or one whose
+// lookups fail, serves the built-in defaults.
+type Library struct {
+	store *store.PromptStore
+}
+
+// NewLibrary creates a Library backed by s.
+func NewLibrary(s *store.PromptStore) *Library {
+	return &Library{store: s}
+}
+
+// Seed saves the built-in default of every task that has no prompt yet.
+func (l *Library) Seed() error {
+	for _, t := range Tasks {
+		added, err := l.store.SeedDefault(t.Key, t.Default)
+		if err != nil {
+			return err
+		}
+		if added {
+			slog.Info("seeded prompt", "task", t.Key)
+		}
+	}
+	return nil
+}
+
+// Body returns the prompt in use for a task, unrendered.
+func (l *Library) Body(task string) string {
+	if l != nil {
+		p, err := l.store.Latest(task)
+		if err != nil {
+			slog.Error("load prompt failed, using the default", "task", task, "error", err)
+		}
+		if p != nil {
+			return p.Body
+		}
+	}
+	t, _ := Lookup(task)
+	return t.Default
+}
+
+// Versions returns every saved version of a task's prompt, newest first.
+func (l *Library) Versions(task string) ([]models.Prompt, error) {
+	return l.store.Versions(task)
+}
+
+// LatestVersions returns the version in use of every task with a saved
+// prompt.
+func (l *Library) LatestVersions() (map[string]int, error) {
+	return l.store.LatestVersions()
+}
+
+// Save stores body as the next version of a task's prompt.
+func (l *Library) Save(task, body, note string, userID *uuid.UUID) (*models.Prompt, error) {
+	return l.store.Create(task, body, note, userID)
+}
+
+// Rollback saves an earlier version's body again as the next version.
+// Returns nil if that version doesn't exist.
+func (l *Library) Rollback(task string, version int, userID *uuid.UUID) (*models.Prompt, error) {
+	old, err := l.store.FindVersion(task, version)
+	if err != nil || old == nil {
+		return nil, err
+	}
+	return l.store.Create(task, old.Body, fmt.Sprintf("Restored version %d", version), userID)
+}
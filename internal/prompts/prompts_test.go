@@ -0,0 +1,67 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package prompts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	body := "Write for {{site_name}} in {{ language }}. Keep {{.Title}}, {{if .Body}}{{end}} and {{unknown}}."
+	got := Render(body, Vars{"site_name": "My Blog", "language": "French", "end": "x"})
+	want := "Write for My Blog in French. Keep {{.Title}}, {{if .Body}}x and {{unknown}}."
+	if got != want {
+		t.Errorf("Render:\ngot  %q\nwant %q", got, want)
+	}
+
+	// Values are not scanned for placeholders again.
+	if got := Render("{{a}}", Vars{"a": "{{b}}", "b": "no"}); got != "{{b}}" {
+		t.Errorf("Render re-expanded a value: %q", got)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	task, _ := Lookup(ContentRewrite)
+	got := Unknown(task, "{{tone}} {{site_name}} {{tone_desc}} {{.Title}} {{tone_desc}} {{foo}}")
+	if want := []string{"tone_desc", "foo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown: got %v, want %v", got, want)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, task := range Tasks {
+		if seen[task.Key] {
+			t.Errorf("duplicate task %s", task.Key)
+		}
+		seen[task.Key] = true
+		if task.Name == "" || task.Default == "" {
+			t.Errorf("task %s has no name or default", task.Key)
+		}
+		if u := Unknown(task, task.Default); len(u) != 0 {
+			t.Errorf("default of %s uses unknown placeholders %v", task.Key, u)
+		}
+	}
+	if !Uses(mustLookup(t, TemplateGenerate).Default, "variables") {
+		t.Error("template default doesn't place {{variables}}")
+	}
+}
+
+func TestNilLibraryServesDefaults(t *testing.T) {
+	var l *Library
+	if got := l.Body(ContentTags); got != mustLookup(t, ContentTags).Default {
+		t.Errorf("Body: got %q", got)
+	}
+}
+
+func mustLookup(t *testing.T, key string) Task {
+	t.Helper()
+	task, ok := Lookup(key)
+	if !ok {
+		t.Fatalf("no task %s", key)
+	}
+	return task
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// tasks.go lists the AI tasks with an editable system prompt, along with
+// their built-in default prompts.
+package prompts
+
+// Task keys, as stored in the prompts table.
+const (
+	ContentGenerate  = "content_generate"
+	ContentTitles    = "content_titles"
+	ContentExcerpt   = "content_excerpt"
+	ContentSEO       = "content_seo"
+	ContentTags      = "content_tags"
+	ContentRewrite   = "content_rewrite"
+	ContentTranslate = "content_translate"
+	ContentQuality   = "content_quality"
+	ContentLinks     = "content_links"
+	ImageDescribe    = "image_describe"
+	TemplateGenerate = "template_generate"
+	RevisionContent  = "revision_content"
+	RevisionTemplate = "revision_template"
+)
+
+// Placeholder is a {{name}} a prompt may contain, replaced with a value
+// when the prompt is used.
+type Placeholder struct {
+	Name        string
+	Description string
+}
+
+// Common placeholders are available to every task.
+var Common = []Placeholder{
+	{"site_name", "The site title from Settings"},
+	{"language", "The site language, e.g. English"},
+	{"design_brief", "The active design theme's style brief, empty when no theme is active"},
+}
+
+// Task is an AI task whose system prompt admins can edit.
+type Task struct {
+	Key          string
+	Name         string
+	Description  string
+	Placeholders []Placeholder // Task-specific, in addition to Common
+	Sample       string        // User input for the editor's test run; empty if it can't be tested
+	Default      string        // The built-in prompt, saved as version 1
+}
+
+// Tasks lists every task in the order the admin shows them.
+var Tasks = []Task{
+	{
+		Key:         ContentGenerate,
+		Name:        "Write content",
+		Description: "Writes a post or page body from the editor's description.",
+		Placeholders: []Placeholder{
+			{"content_type", `What to write, e.g. "article" or "blog post"`},
+		},
+		Sample: "A beginner's guide to growing tomatoes on a balcony.",
+		Default: `You are an expert content writer for a CMS. Write a complete {{content_type}} based on the user's description.
+
+Rules:
+- Output ONLY the article body as clean Markdown.
+- Use ## and ### for subheadings (not # — the CMS adds the title separately).
+- Use standard Markdown syntax: **bold**, *italic*, > blockquotes, - lists, 1. numbered lists, [links](url), etc.
+- Do NOT wrap the output in code fences.
+- Write 3-6 well-structured paragraphs with subheadings where appropriate.
+- Make the content informative, engaging, and ready to publish.`,
+	},
+	{
+		Key:         ContentTitles,
+		Name:        "Suggest titles",
+		Description: "Suggests five titles for a post or page.",
+		Sample:      sampleContent,
+		Default: `You are a headline writing expert for a CMS. Generate exactly 5 compelling,
+SEO-friendly title suggestions for the given content. Keep titles under 70 characters.`,
+	},
+	{
+		Key:         ContentExcerpt,
+		Name:        "Write excerpt",
+		Description: "Summarises a post or page in one or two sentences.",
+		Sample:      sampleContent,
+		Default: `You are a content summarization expert. Generate a compelling excerpt/summary
+of the given content in 1-2 sentences (max 160 characters). The excerpt should capture the essence
+of the content and entice readers to click.`,
+	},
+	{
+		Key:         ContentSEO,
+		Name:        "SEO metadata",
+		Description: "Writes a meta description and keywords.",
+		Sample:      sampleContent,
+		Default: `You are an SEO expert. For the given content, generate a meta description
+(max 160 characters, compelling for search results) and 5-8 relevant keywords.`,
+	},
+	{
+		Key:         ContentTags,
+		Name:        "Extract tags",
+		Description: "Picks tags for a post or page.",
+		Sample:      sampleContent,
+		Default: `You are a content categorization expert. Extract 5-10 relevant tags from
+the given content. Tags should be short (1-3 words), lowercase, and relevant for blog categorization.`,
+	},
+	{
+		Key:         ContentRewrite,
+		Name:        "Rewrite",
+		Description: "Rewrites a body in the chosen tone.",
+		Placeholders: []Placeholder{
+			{"tone", `The chosen tone, e.g. "casual, friendly, and conversational"`},
+			{"format_note", "How to treat the body's Markdown or HTML"},
+		},
+		Sample: "Title: Balcony tomatoes\n\nContent to rewrite:\n" + sampleBody,
+		Default: `You are a professional content editor. Rewrite the given content
+in a {{tone}} tone. Preserve the key information and structure but adjust the language and style.
+{{format_note}}
+If the editor provided guidance, follow those instructions carefully while applying the requested tone.
+Output ONLY the rewritten content, nothing else.`,
+	},
+	{
+		Key:         ContentTranslate,
+		Name:        "Translate",
+		Description: "Translates a post or page with its excerpt and SEO metadata.",
+		Placeholders: []Placeholder{
+			{"source_language", "The language translated from"},
+			{"target_language", "The language translated into"},
+			{"format_note", "How to treat the body's Markdown or HTML"},
+		},
+		Sample: "Title: Balcony tomatoes\n\nBody:\n" + sampleBody,
+		Default: `You are a professional translator for a website. Translate the given
+content from {{source_language}} into {{target_language}}.
+
+Rules:
+- {{format_note}}
+- Never translate URLs, file names, code, or text inside code blocks.
+- Keep the meaning, tone and paragraph structure; write natural, idiomatic {{target_language}}.
+- Translate the excerpt, meta description and keywords only when the original has them; otherwise leave them empty.
+- Keep the meta description under 160 characters.`,
+	},
+	{
+		Key:         ContentQuality,
+		Name:        "Improvement suggestions",
+		Description: "Suggests how to improve a post or page, given the findings of the content health checks.",
+		Sample: "Title: Balcony tomatoes\nSlug: balcony-tomatoes\nMeta description: (none)\nFocus keyword: balcony tomatoes\n\n" +
+			"Findings:\n- Length (warning): 52 words. Aim for at least 300 so search engines have something to rank.\n" +
+			"- Meta description (problem): No meta description. Search engines will pick a snippet themselves.\n\nContent:\n" + sampleBody,
+		Default: `You are an experienced web editor reviewing a draft before it is published. You get the
+draft and the findings of automatic readability, SEO and accessibility checks.
+
+Suggest 3-5 specific, actionable improvements, most important first:
+- Refer to the actual text: quote the sentence to shorten, name the section that needs a heading, propose the meta description.
+- Address the findings marked as problems or warnings; don't repeat what is already fine.
+- Keep each suggestion to one or two sentences.`,
+	},
+	{
+		Key:         ContentLinks,
+		Name:        "Internal links",
+		Description: "Picks phrases in a post or page to link to related content on the site.",
+		Sample: sampleContent + "\n\nPages on this site:\n" +
+			"1. Choosing a Pot Size (/pot-sizes): How big a container each vegetable needs.\n" +
+			"2. Feeding Container Plants (/feeding-container-plants): Which fertiliser to use and how often.\n" +
+			"3. Our Team (/team): The people behind the site.",
+		Default: `You are an editor adding internal links to a draft on a website. You get the draft and a
+numbered list of other pages on the same site.
+
+Pick up to 5 places where a link to one of those pages would help a reader:
+- The anchor must be copied exactly from the draft's running text: 2-6 words that describe the linked page. Never use a heading, an existing link or code.
+- Link each page at most once, and skip pages that aren't relevant to the draft.
+- Don't use vague anchors like "click here" or "this post".
+- Return an empty list when nothing fits.`,
+	},
+	{
+		Key:         ImageDescribe,
+		Name:        "Describe image",
+		Description: "Suggests alt text and a caption for an uploaded image. It needs an image, so it can't be tested here.",
+		Default: "You write alt text and captions for images on a website. " +
+			"Alt text describes what the image shows for someone who can't see it: " +
+			"the subject, the action and any legible text, in one sentence. " +
+			"Don't start with \"Image of\" or \"Picture of\" and don't guess names. " +
+			"The caption adds context a reader would want under the image. " +
+			"Write both in {{language}}.",
+	},
+	{
+		Key:         TemplateGenerate,
+		Name:        "Design template",
+		Description: "Generates templates in the AI template builder. The active design brief is appended unless the prompt places {{design_brief}} itself.",
+		Placeholders: []Placeholder{
+			{"template_type", "The type being designed: header, footer, page, article_loop or search"},
+			{"variables", "The template type's description and the variables it can use"},
+		},
+		Sample: "A clean header with the site name on the left and links to Home and Blog on the right.",
+		Default: `You are an expert web designer who creates beautiful, modern HTML templates using TailwindCSS.
+You generate complete, production-ready HTML+TailwindCSS templates for a CMS called YaaiCMS.
+
+CRITICAL RULES:
+1. Output ONLY the HTML template code. No explanations, no markdown code fences, no comments outside the HTML.
+2. Use TailwindCSS utility classes for all styling. Do not use custom CSS.
+3. Use Go template syntax for dynamic content: {{.VariableName}}
+4. For raw HTML content (like Body, Header, Footer), the CMS handles escaping — just use {{.Body}} etc.
+5. Templates should be responsive and look professional on all screen sizes.
+6. Use semantic HTML elements (header, nav, main, article, footer, section, etc.).
+7. Include the TailwindCSS CDN script tag only in full page templates (page, article_loop, search).
+8. Guard optional fields with {{if .Field}} to avoid rendering empty markup.
+9. No other scripts, inline event handlers (onclick etc.), javascript: URLs, iframes, forms posting to other sites or tracking pixels: they are flagged as safety issues and stripped.
+
+{{variables}}`,
+	},
+	{
+		Key:         RevisionContent,
+		Name:        "Content revision summary",
+		Description: "Titles and summarises the changes in a saved post or page revision.",
+		Sample:      "Changes made:\nTitle: changed\nBody: changed (1200 -> 1450 chars)\n\nOld title: \"Balcony tomatoes\"\nNew title: \"Growing tomatoes on a balcony\"",
+		Default: `You are a version control assistant. For this content revision, generate a very
+short title (max 60 characters) that summarizes the changes, like a git commit message, in imperative
+mood (e.g. "Update title and body content"), and a brief changelog of 2-4 concise, factual points.`,
+	},
+	{
+		Key:         RevisionTemplate,
+		Name:        "Template revision summary",
+		Description: "Titles and summarises the changes in a saved template revision.",
+		Sample:      "Changes made to a template:\nHTML content: changed (2300 -> 2650 chars)\n\nOld name: \"Default Header\"\nNew name: \"Default Header\"",
+		Default: `You are a version control assistant. For this CMS template revision, generate a very
+short title (max 60 characters) that summarizes the changes, like a git commit message, in imperative
+mood (e.g. "Restyle header with dark nav bar"), and a brief changelog of 2-4 concise, factual points.`,
+	},
+}
+
+// sampleBody is the Markdown body used to test content prompts.
+const sampleBody = `Growing tomatoes on a balcony is easier than most people think. Pick a compact
+variety, a pot of at least 20 litres and the sunniest corner you have.
+
+## Watering
+
+Water deeply in the morning and keep the soil evenly moist. Feed every two weeks
+once the first flowers appear.`
+
+// sampleContent is the input used to test the title, excerpt, SEO and
+// tag prompts.
+const sampleContent = "Title: Balcony tomatoes\n\nContent:\n" + sampleBody
+
+// Lookup returns the task with the given key.
+func Lookup(key string) (Task, bool) {
+	for _, t := range Tasks {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return Task{}, false
+}
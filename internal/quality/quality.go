@@ -0,0 +1,498 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// Package quality scores content for readability, SEO and accessibility.
+// Every check is local and deterministic: the body is rendered to HTML
+// and walked once, so the same content always gets the same report and
+// no AI call is needed.
+package quality
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	xhtml "golang.org/x/net/html"
+
+	"yaaicms/internal/markdown"
+	"yaaicms/internal/models"
+	"yaaicms/internal/slug"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	Good    Status = "good"    // Nothing to do
+	Warning Status = "warning" // Worth improving
+	Problem Status = "problem" // Should be fixed before publishing
+)
+
+// Thresholds of the checks.
+const (
+	MinWords             = 300 // Shorter content gets a warning
+	MaxParagraphWords    = 150 // Longer paragraphs get a warning
+	MinMetaDescription   = 70  // Characters
+	MaxMetaDescription   = 160 // Characters; search engines cut the rest
+	GoodReadability      = 60  // Flesch reading ease at or above this is good
+	DifficultReadability = 30  // Below this is a problem
+)
+
+// Check is the result of one check, with a sentence on what was found.
+type Check struct {
+	ID     string `json:"id"`
+	Label  string `json:"label"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the analysis of one content item.
+type Report struct {
+	Score            int     `json:"score"` // 0-100, from the checks
+	Words            int     `json:"words"`
+	Sentences        int     `json:"sentences"`
+	Paragraphs       int     `json:"paragraphs"`
+	Readability      float64 `json:"readability"` // Flesch reading ease
+	Keyword          string  `json:"keyword"`     // The first meta keyword
+	Headings         int     `json:"headings"`
+	InternalLinks    int     `json:"internal_links"`
+	ExternalLinks    int     `json:"external_links"`
+	Images           int     `json:"images"`
+	ImagesMissingAlt int     `json:"images_missing_alt"`
+	LongParagraphs   int     `json:"long_paragraphs"`
+	MetaLength       int     `json:"meta_length"` // Meta description length in characters
+	Checks           []Check `json:"checks"`
+}
+
+// Issues counts the checks that aren't good.
+func (r *Report) Issues() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status != Good {
+			n++
+		}
+	}
+	return n
+}
+
+// ReadabilityLabel describes the Flesch score in words.
+func (r *Report) ReadabilityLabel() string {
+	switch {
+	case r.Words == 0:
+		return "No text"
+	case r.Readability >= 80:
+		return "Easy"
+	case r.Readability >= GoodReadability:
+		return "Plain"
+	case r.Readability >= DifficultReadability:
+		return "Fairly difficult"
+	default:
+		return "Difficult"
+	}
+}
+
+// Analyze checks a content item. Links to siteHost, or without a host,
+// count as internal; siteHost may be empty.
+func Analyze(c *models.Content, siteHost string) *Report {
+	body := c.Body
+	if c.BodyFormat != models.BodyFormatHTML {
+		if h, err := markdown.ToHTML(c.Body); err == nil {
+			body = h
+		}
+	}
+	doc := parseBody(body, siteHost)
+
+	r := &Report{
+		Paragraphs:       len(doc.paragraphs),
+		Headings:         len(doc.headings),
+		InternalLinks:    doc.internalLinks,
+		ExternalLinks:    doc.externalLinks,
+		Images:           doc.images,
+		ImagesMissingAlt: doc.imagesMissingAlt,
+		MetaLength:       utf8.RuneCountInString(strings.TrimSpace(ptrStr(c.MetaDescription))),
+		Keyword:          focusKeyword(ptrStr(c.MetaKeywords)),
+	}
+
+	var syllables int
+	for _, p := range doc.paragraphs {
+		words := splitWords(p)
+		r.Words += len(words)
+		r.Sentences += countSentences(p)
+		for _, w := range words {
+			syllables += countSyllables(w)
+		}
+		if len(words) > MaxParagraphWords {
+			r.LongParagraphs++
+		}
+	}
+	if r.Words > 0 && r.Sentences > 0 {
+		r.Readability = flesch(r.Words, r.Sentences, syllables)
+	}
+
+	r.Checks = []Check{
+		r.checkLength(),
+		r.checkReadability(),
+		checkHeadings(doc.headings, r.Words),
+		r.checkKeyword(c),
+		r.checkMetaDescription(),
+		r.checkLinks(),
+		r.checkImages(),
+		r.checkParagraphs(),
+	}
+	r.Score = score(r.Checks)
+	return r
+}
+
+func (r *Report) checkLength() Check {
+	c := Check{ID: "length", Label: "Length", Status: Good, Detail: fmt.Sprintf("%d words.", r.Words)}
+	switch {
+	case r.Words == 0:
+		c.Status, c.Detail = Problem, "The body has no text."
+	case r.Words < MinWords:
+		c.Status, c.Detail = Warning, fmt.Sprintf("%d words. Aim for at least %d so search engines have something to rank.", r.Words, MinWords)
+	}
+	return c
+}
+
+func (r *Report) checkReadability() Check {
+	c := Check{ID: "readability", Label: "Readability"}
+	switch {
+	case r.Words == 0:
+		c.Status, c.Detail = Warning, "Nothing to measure yet."
+	case r.Readability >= GoodReadability:
+		c.Status, c.Detail = Good, fmt.Sprintf("Flesch reading ease %.0f: easy to read.", r.Readability)
+	case r.Readability >= DifficultReadability:
+		c.Status, c.Detail = Warning, fmt.Sprintf("Flesch reading ease %.0f: fairly difficult. Use shorter sentences and simpler words.", r.Readability)
+	default:
+		c.Status, c.Detail = Problem, fmt.Sprintf("Flesch reading ease %.0f: difficult. Split long sentences and replace long words.", r.Readability)
+	}
+	return c
+}
+
+// checkHeadings wants subheadings in longer content, no <h1> (the title
+// is the page's <h1>) and no skipped levels.
+func checkHeadings(levels []int, words int) Check {
+	c := Check{ID: "headings", Label: "Headings", Status: Good, Detail: fmt.Sprintf("%d subheadings, well nested.", len(levels))}
+	prev := 1
+	for _, l := range levels {
+		if l == 1 {
+			c.Status, c.Detail = Warning, "The body has a level 1 heading. The title is already the page's main heading; use ## instead."
+			return c
+		}
+		if l > prev+1 {
+			c.Status, c.Detail = Warning, fmt.Sprintf("A level %d heading follows a level %d one. Don't skip levels.", l, prev)
+			return c
+		}
+		prev = l
+	}
+	if len(levels) == 0 {
+		if words >= MinWords {
+			c.Status, c.Detail = Warning, "No subheadings. Break long content into sections with ## headings."
+		} else {
+			c.Detail = "No subheadings, which is fine for short content."
+		}
+	}
+	return c
+}
+
+// checkKeyword looks for the focus keyword in the title, slug and meta
+// description.
+func (r *Report) checkKeyword(item *models.Content) Check {
+	c := Check{ID: "keyword", Label: "Focus keyword"}
+	if r.Keyword == "" {
+		c.Status, c.Detail = Warning, "No focus keyword. The first meta keyword is used as the focus keyword."
+		return c
+	}
+	kw := strings.ToLower(r.Keyword)
+	var missing []string
+	if !strings.Contains(strings.ToLower(item.Title), kw) {
+		missing = append(missing, "title")
+	}
+	if !strings.Contains(item.Slug, slug.Generate(r.Keyword)) {
+		missing = append(missing, "slug")
+	}
+	if !strings.Contains(strings.ToLower(ptrStr(item.MetaDescription)), kw) {
+		missing = append(missing, "meta description")
+	}
+	switch len(missing) {
+	case 0:
+		c.Status, c.Detail = Good, fmt.Sprintf("%q is in the title, slug and meta description.", r.Keyword)
+	case 3:
+		c.Status, c.Detail = Problem, fmt.Sprintf("%q isn't in the title, slug or meta description.", r.Keyword)
+	default:
+		c.Status, c.Detail = Warning, fmt.Sprintf("%q is missing from the %s.", r.Keyword, strings.Join(missing, " and "))
+	}
+	return c
+}
+
+func (r *Report) checkMetaDescription() Check {
+	c := Check{ID: "meta_description", Label: "Meta description", Status: Good,
+		Detail: fmt.Sprintf("%d characters.", r.MetaLength)}
+	switch {
+	case r.MetaLength == 0:
+		c.Status, c.Detail = Problem, "No meta description. Search engines will pick a snippet themselves."
+	case r.MetaLength < MinMetaDescription:
+		c.Status, c.Detail = Warning, fmt.Sprintf("%d characters. Use %d-%d to fill the search result.", r.MetaLength, MinMetaDescription, MaxMetaDescription)
+	case r.MetaLength > MaxMetaDescription:
+		c.Status, c.Detail = Warning, fmt.Sprintf("%d characters. Search engines cut it after about %d.", r.MetaLength, MaxMetaDescription)
+	}
+	return c
+}
+
+func (r *Report) checkLinks() Check {
+	c := Check{ID: "links", Label: "Links", Status: Good,
+		Detail: fmt.Sprintf("%d internal, %d external.", r.InternalLinks, r.ExternalLinks)}
+	if r.InternalLinks == 0 {
+		c.Status = Warning
+		c.Detail += " Link to related posts or pages on this site."
+	}
+	return c
+}
+
+func (r *Report) checkImages() Check {
+	c := Check{ID: "images", Label: "Image alt text", Status: Good}
+	switch {
+	case r.Images == 0:
+		c.Detail = "No images."
+	case r.ImagesMissingAlt > 0:
+		c.Status = Problem
+		c.Detail = fmt.Sprintf("%d of %d images have no alt text, so screen reader users miss them.", r.ImagesMissingAlt, r.Images)
+	default:
+		c.Detail = fmt.Sprintf("All %d images have alt text.", r.Images)
+	}
+	return c
+}
+
+func (r *Report) checkParagraphs() Check {
+	c := Check{ID: "paragraphs", Label: "Paragraph length", Status: Good, Detail: "Paragraphs are a comfortable length."}
+	if r.LongParagraphs > 0 {
+		c.Status = Warning
+		c.Detail = fmt.Sprintf("%d paragraphs are longer than %d words. Split them up.", r.LongParagraphs, MaxParagraphWords)
+	}
+	return c
+}
+
+// score averages the checks, counting a warning as half.
+func score(checks []Check) int {
+	if len(checks) == 0 {
+		return 0
+	}
+	points := 0
+	for _, c := range checks {
+		switch c.Status {
+		case Good:
+			points += 2
+		case Warning:
+			points++
+		}
+	}
+	return int(math.Round(float64(points) * 100 / float64(2*len(checks))))
+}
+
+// flesch computes the Flesch reading ease. It is calibrated for English
+// and only indicative for other languages.
+func flesch(words, sentences, syllables int) float64 {
+	v := 206.835 - 1.015*float64(words)/float64(sentences) - 84.6*float64(syllables)/float64(words)
+	return math.Round(v*10) / 10
+}
+
+// countSentences counts runs of sentence-ending punctuation, and the
+// last sentence when it has none. A point between digits, as in "2.5",
+// doesn't end a sentence.
+func countSentences(text string) int {
+	n := 0
+	inEnd := false
+	tail := false
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+		case r == '.' || r == '!' || r == '?':
+			if !inEnd && tail {
+				n++
+			}
+			inEnd = true
+			tail = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			inEnd = false
+			tail = true
+		}
+	}
+	if tail {
+		n++
+	}
+	return n
+}
+
+// countSyllables estimates the syllables of an English word by counting
+// vowel groups, less a silent final "e". Every word has at least one.
+func countSyllables(word string) int {
+	word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }))
+	if word == "" {
+		return 0
+	}
+	n := 0
+	prevVowel := false
+	for _, r := range word {
+		v := strings.ContainsRune("aeiouy", r)
+		if v && !prevVowel {
+			n++
+		}
+		prevVowel = v
+	}
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && n > 1 {
+		n--
+	}
+	return max(n, 1)
+}
+
+// splitWords splits text into words, leaving out stray punctuation.
+func splitWords(text string) []string {
+	var out []string
+	for _, f := range strings.Fields(text) {
+		if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// focusKeyword returns the first of the comma-separated meta keywords.
+func focusKeyword(keywords string) string {
+	first, _, _ := strings.Cut(keywords, ",")
+	return strings.TrimSpace(first)
+}
+
+func ptrStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// document is what the checks need from the rendered body.
+type document struct {
+	paragraphs       []string // Text of paragraphs and list items
+	headings         []int    // Heading levels in order
+	internalLinks    int
+	externalLinks    int
+	images           int
+	imagesMissingAlt int
+}
+
+// textTags are the elements whose text counts as a paragraph.
+var textTags = map[string]bool{"p": true, "li": true, "blockquote": true, "td": true, "th": true, "dd": true}
+
+// inlineTags are the elements that can sit inside a word, as in
+// un<em>believ</em>able. Any other tag, br included, separates words.
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true, "data": true,
+	"del": true, "dfn": true, "em": true, "i": true, "ins": true, "kbd": true, "mark": true,
+	"q": true, "s": true, "samp": true, "small": true, "span": true, "strong": true,
+	"sub": true, "sup": true, "time": true, "u": true, "var": true,
+}
+
+// parseBody walks rendered HTML once. Text inside pre and code blocks
+// isn't prose, so it is left out of the paragraphs.
+func parseBody(body, siteHost string) *document {
+	doc := &document{}
+	z := xhtml.NewTokenizer(strings.NewReader(body))
+	var (
+		text   strings.Builder
+		depth  int // Nesting of textTags; text is collected at any depth
+		inCode int
+	)
+	flush := func() {
+		if t := strings.Join(strings.Fields(text.String()), " "); t != "" {
+			doc.paragraphs = append(doc.paragraphs, t)
+		}
+		text.Reset()
+	}
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			switch tag := tok.Data; {
+			case textTags[tag]:
+				if depth > 0 {
+					flush()
+				}
+				depth++
+			case tag == "pre" || tag == "code":
+				inCode++
+			case len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6':
+				doc.headings = append(doc.headings, int(tag[1]-'0'))
+			case tag == "a":
+				doc.countLink(attr(tok, "href"), siteHost)
+			case tag == "img":
+				doc.images++
+				if strings.TrimSpace(attr(tok, "alt")) == "" {
+					doc.imagesMissingAlt++
+				}
+			}
+			if !inlineTags[tok.Data] {
+				text.WriteByte(' ')
+			}
+		case xhtml.EndTagToken:
+			switch tag := tok.Data; {
+			case textTags[tag]:
+				flush()
+				if depth > 0 {
+					depth--
+				}
+			case tag == "pre" || tag == "code":
+				if inCode > 0 {
+					inCode--
+				}
+			}
+			if !inlineTags[tok.Data] {
+				text.WriteByte(' ')
+			}
+		case xhtml.TextToken:
+			if depth > 0 && inCode == 0 {
+				text.WriteString(tok.Data)
+			}
+		}
+	}
+	flush()
+	return doc
+}
+
+// countLink counts href as an internal or external link. Fragments,
+// mailto: and other non-web links aren't counted.
+func (d *document) countLink(href, siteHost string) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return
+	}
+	switch {
+	case u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https":
+		return
+	case u.Host == "" || (siteHost != "" && strings.EqualFold(u.Host, siteHost)):
+		d.internalLinks++
+	default:
+		d.externalLinks++
+	}
+}
+
+// attr returns the value of an attribute of tok.
+func attr(tok xhtml.Token, name string) string {
+	for _, a := range tok.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package quality
+
+import (
+	"strings"
+	"testing"
+
+	"yaaicms/internal/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+func statuses(r *Report) map[string]Status {
+	m := make(map[string]Status)
+	for _, c := range r.Checks {
+		m[c.ID] = c.Status
+	}
+	return m
+}
+
+func TestAnalyzeProblems(t *testing.T) {
+	c := &models.Content{
+		Title:        "Notes",
+		Slug:         "notes",
+		BodyFormat:   models.BodyFormatMarkdown,
+		MetaKeywords: strPtr("balcony tomatoes, gardening"),
+		Body: `# Notes
+
+#### Too deep
+
+The implementation of comprehensive horticultural methodologies necessitates considerable deliberation regarding environmental circumstances.
+
+![](/media/a.jpg) ![Tomatoes](/media/b.jpg)
+
+[Elsewhere](https://example.org/page) and [mail](mailto:me@example.org).
+
+` + "```\nignored code block with many words here\n```",
+	}
+	r := Analyze(c, "mysite.test")
+
+	want := map[string]Status{
+		"length":           Warning,
+		"readability":      Problem,
+		"headings":         Warning,
+		"keyword":          Problem,
+		"meta_description": Problem,
+		"links":            Warning,
+		"images":           Problem,
+		"paragraphs":       Good,
+	}
+	got := statuses(r)
+	for id, s := range want {
+		if got[id] != s {
+			t.Errorf("%s: got %s, want %s", id, got[id], s)
+		}
+	}
+	if r.Keyword != "balcony tomatoes" || r.ExternalLinks != 1 || r.InternalLinks != 0 || r.ImagesMissingAlt != 1 || r.Images != 2 {
+		t.Errorf("report: %+v", r)
+	}
+	if r.Words != 15 {
+		t.Errorf("words: got %d, want 15 (code blocks excluded)", r.Words)
+	}
+	if r.Score >= 50 || r.Issues() != 7 {
+		t.Errorf("score %d, issues %d", r.Score, r.Issues())
+	}
+}
+
+func TestAnalyzeGood(t *testing.T) {
+	para := strings.Repeat("We grow red tomatoes on the small balcony. The sun is warm and the pots are big. ", 6)
+	body := "<h2>Start</h2><p>" + para + `<a href="/soil">Soil</a> and <a href="https://mysite.test/pots">pots</a>.</p>` +
+		"<h3>Water</h3><p>" + para + "</p><h2>Pick</h2><p>" + para + `<img src="/t.jpg" alt="Ripe tomatoes"></p><p>` + para + "</p>"
+	c := &models.Content{
+		Title:           "Balcony Tomatoes for Beginners",
+		Slug:            "balcony-tomatoes-for-beginners",
+		BodyFormat:      models.BodyFormatHTML,
+		Body:            body,
+		MetaKeywords:    strPtr("Balcony tomatoes"),
+		MetaDescription: strPtr("How to grow balcony tomatoes: pick a variety, a big pot and a sunny spot, then water and feed them well."),
+	}
+	r := Analyze(c, "mysite.test")
+	for _, ch := range r.Checks {
+		if ch.Status != Good {
+			t.Errorf("%s: %s (%s)", ch.ID, ch.Status, ch.Detail)
+		}
+	}
+	if r.Score != 100 || r.InternalLinks != 2 || r.Headings != 3 {
+		t.Errorf("report: %+v", r)
+	}
+	if r.ReadabilityLabel() != "Easy" {
+		t.Errorf("readability %.1f labelled %s", r.Readability, r.ReadabilityLabel())
+	}
+}
+
+func TestParseBodyWords(t *testing.T) {
+	// Inline markup inside a word doesn't split it; block tags and br do.
+	doc := parseBody(`<p>It was un<em>believ</em>able, I read the <a href="/x">post</a>s.</p>`+
+		`<ul><li>one<br>two<div>three</div><code>skipped</code>four</li></ul>`, "")
+	want := []string{"It was unbelievable, I read the posts.", "one two three four"}
+	if strings.Join(doc.paragraphs, "|") != strings.Join(want, "|") {
+		t.Errorf("paragraphs: got %q, want %q", doc.paragraphs, want)
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := map[string]int{
+		"the": 1, "tomato": 3, "make": 1, "little": 2, "beautiful": 3, "rhythm": 1, "a": 1, "42": 0, "Water,": 2,
+	}
+	for word, want := range tests {
+		if got := countSyllables(word); got != want {
+			t.Errorf("countSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestCountSentences(t *testing.T) {
+	tests := map[string]int{
+		"One. Two! Three?":      3,
+		"Wait... what?!":        2,
+		"No ending punctuation": 1,
+		"Version 2.5 is out.":   1,
+		"":                      0,
+	}
+	for text, want := range tests {
+		if got := countSentences(text); got != want {
+			t.Errorf("countSentences(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
@@ -94,6 +94,22 @@ func New(devMode bool) (*Renderer, error) {
 			"uuidEq": func(ptr *uuid.UUID, val uuid.UUID) bool {
 				return ptr != nil && *ptr == val
 			},
+			// dict builds a map from alternating keys and values so a
+			// sub-template can receive more than one argument.
+			"dict": func(pairs ...any) (map[string]any, error) {
+				if len(pairs)%2 != 0 {
+					return nil, fmt.Errorf("dict: odd number of arguments")
+				}
+				m := make(map[string]any, len(pairs)/2)
+				for i := 0; i < len(pairs); i += 2 {
+					key, ok := pairs[i].(string)
+					if !ok {
+						return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+					}
+					m[key] = pairs[i+1]
+				}
+				return m, nil
+			},
 		},
 	}
 
@@ -436,3 +436,30 @@ func TestRendererTemplateCount(t *testing.T) {
 		t.Errorf("expected at least %d templates, got %d", expectedMin, len(rn.templates))
 	}
 }
+
+// --------------------------------------------------------------------------
+// TestDictFunc — verify the dict template helper
+// --------------------------------------------------------------------------
+
+func TestDictFunc(t *testing.T) {
+	rn, err := New(true)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	dict := rn.funcMap["dict"].(func(...any) (map[string]any, error))
+
+	m, err := dict("Form", "f1", "Budget", nil)
+	if err != nil {
+		t.Fatalf("dict: %v", err)
+	}
+	if m["Form"] != "f1" || m["Budget"] != nil || len(m) != 2 {
+		t.Errorf("dict: got %v", m)
+	}
+
+	if _, err := dict("odd"); err == nil {
+		t.Error("expected error for odd argument count")
+	}
+	if _, err := dict(1, "x"); err == nil {
+		t.Error("expected error for non-string key")
+	}
+}
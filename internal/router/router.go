@@ -27,9 +27,11 @@ func New(sessionStore *session.Store, admin *handlers.Admin, auth *handlers.Auth
 	r := chi.NewRouter()
 
 	// Rate limiters: auth endpoints are tightly limited (brute-force protection),
-	// AI endpoints get a generous limit (authenticated users, slow operations).
+	// AI endpoints get a generous limit (authenticated users, slow operations),
+	// and public search is limited since each query may call an embedding API.
 	authLimiter := middleware.NewRateLimiter(10, 1*time.Minute)
 	aiLimiter := middleware.NewRateLimiter(30, 1*time.Minute)
+	searchLimiter := middleware.NewRateLimiter(30, 1*time.Minute)
 
 	// Global middleware — applied to every request.
 	r.Use(middleware.Recoverer)
@@ -71,6 +73,8 @@ func New(sessionStore *session.Store, admin *handlers.Admin, auth *handlers.Auth
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.RequireAuth)
 			r.Use(middleware.Require2FA)
+			// Requests that use AI leave a row in the AI audit log.
+			r.Use(admin.AuditAI)
 
 			// Dashboard
 			r.Get("/", admin.Dashboard)
@@ -118,6 +122,16 @@ func New(sessionStore *session.Store, admin *handlers.Admin, auth *handlers.Auth
 				r.Get("/{id}/url", admin.MediaServe)
 				r.Post("/{id}/regenerate", admin.MediaRegenerateVariants)
 				r.Post("/regenerate-all", admin.MediaRegenerateBulk)
+
+				// AI alt text and caption suggestions — editors review them.
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireEditor)
+					r.Get("/descriptions", admin.MediaDescriptionsPage)
+					r.Post("/descriptions/describe-missing", admin.MediaDescribeMissing)
+					r.Post("/descriptions/{id}/accept", admin.MediaDescriptionAccept)
+					r.Post("/descriptions/{id}/reject", admin.MediaDescriptionReject)
+					r.Post("/{id}/describe", admin.MediaDescribe)
+				})
 			})
 
 			// Content Revisions
@@ -137,6 +151,16 @@ func New(sessionStore *session.Store, admin *handlers.Admin, auth *handlers.Auth
 				r.Post("/reorder", admin.CategoryReorder)
 			})
 
+			// Content health — readability, SEO and accessibility checks
+			r.Route("/content-health", func(r chi.Router) {
+				r.Get("/", admin.ContentHealthPage)
+				r.Post("/analyze", admin.ContentHealthAnalyze)
+			})
+
+			// Inserting an accepted internal link suggestion; no AI call,
+			// so it lives outside /ai and its rate limit.
+			r.Post("/internal-links/apply", admin.InternalLinkApply)
+
 			// User management — admin only
 			r.Route("/users", func(r chi.Router) {
 				r.Use(middleware.RequireAdmin)
@@ -159,8 +183,12 @@ func New(sessionStore *session.Store, admin *handlers.Admin, auth *handlers.Auth
 				r.Post("/seo-metadata", admin.AISEOMetadata)
 				r.Post("/rewrite", admin.AIRewrite)
 				r.Post("/extract-tags", admin.AIExtractTags)
+				r.Post("/translate", admin.AITranslate)
+				r.Post("/quality-suggestions", admin.AIQualitySuggestions)
+				r.Post("/internal-links", admin.AIInternalLinks)
 				r.Post("/generate-template", admin.AITemplateGenerate)
 				r.Post("/save-template", admin.AITemplateSave)
+				r.Post("/sanitize-template", admin.AITemplateSanitize)
 				r.Get("/preview-content", admin.AIPreviewContentList)
 
 				// Design themes (style briefs for visual consistency)
@@ -172,11 +200,86 @@ func New(sessionStore *session.Store, admin *handlers.Admin, auth *handlers.Auth
 				r.Post("/themes/{id}/deactivate", admin.AIThemeDeactivate)
 				r.Delete("/themes/{id}", admin.AIThemeDelete)
 				r.Post("/restyle-preview", admin.AIRestylePreview)
+
+				// Saved template-builder and content-assistant conversations
+				r.Get("/conversations", admin.AIConversationList)
+				r.Get("/conversations/{id}", admin.AIConversationGet)
+				r.Post("/conversations/{id}/fork", admin.AIConversationFork)
+				r.Delete("/conversations/{id}", admin.AIConversationDelete)
+			})
+
+			// Background AI jobs — status polling lives outside /ai so it
+			// doesn't count against the AI rate limit. Users see their own
+			// jobs; admins see all of them.
+			r.Route("/jobs", func(r chi.Router) {
+				r.Get("/", admin.JobsPage)
+				r.Get("/{id}", admin.JobStatus)
+				r.Post("/{id}/cancel", admin.JobCancel)
+				r.Post("/{id}/retry", admin.JobRetry)
+			})
+
+			// Bulk AI operations — editors and admins. Runs are queued as
+			// jobs, so this lives outside /ai and its rate limit.
+			r.Route("/ai-bulk", func(r chi.Router) {
+				r.Use(middleware.RequireEditor)
+				r.Get("/", admin.AIBulkPage)
+				r.Post("/", admin.AIBulkStart)
+				r.Post("/preview", admin.AIBulkPreview)
+				r.Get("/{id}", admin.AIBulkRunPage)
+				r.Post("/{id}/accept-all", admin.AIBulkAcceptAll)
+				r.Post("/{id}/reject-all", admin.AIBulkRejectAll)
+				r.Post("/{id}/retry-failed", admin.AIBulkRetryFailed)
+				r.Post("/items/{id}/accept", admin.AIBulkItemAccept)
+				r.Post("/items/{id}/reject", admin.AIBulkItemReject)
+			})
+
+			// Page cache — warm progress is shown on everyone's dashboard;
+			// stats, purge and per-page warm are admin only.
+			r.Route("/cache", func(r chi.Router) {
+				r.Get("/warm", admin.CacheWarmStatus)
+				r.Post("/warm", admin.CacheWarmStart)
+
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireAdmin)
+					r.Get("/", admin.CachePage)
+					r.Post("/reset-stats", admin.CacheResetStats)
+					r.Post("/keys/{key}/purge", admin.CachePurgeKey)
+					r.Post("/keys/{key}/warm", admin.CacheWarmKey)
+				})
+			})
+
+			// AI usage and cost report — admin only
+			r.Route("/ai-usage", func(r chi.Router) {
+				r.Use(middleware.RequireAdmin)
+				r.Get("/", admin.AIUsagePage)
+			})
+
+			// AI audit log and its CSV export — admin only
+			r.Route("/ai-audit", func(r chi.Router) {
+				r.Use(middleware.RequireAdmin)
+				r.Get("/", admin.AIAuditPage)
+				r.Get("/export.csv", admin.AIAuditExport)
+			})
+
+			// AI prompt library — admin only. Test runs count against
+			// the AI rate limit.
+			r.Route("/prompts", func(r chi.Router) {
+				r.Use(middleware.RequireAdmin)
+				r.Get("/", admin.PromptsPage)
+				r.Get("/{task}", admin.PromptEdit)
+				r.Post("/{task}", admin.PromptSave)
+				r.Post("/{task}/rollback/{version}", admin.PromptRollback)
+				r.With(aiLimiter.Middleware).Post("/{task}/test", admin.PromptTest)
 			})
 
 			// Settings
 			r.Get("/settings", admin.SettingsPage)
 			r.Post("/settings", admin.SettingsSave)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireAdmin)
+				r.Post("/settings/ai-budgets", admin.AIBudgetSave)
+				r.Delete("/settings/ai-budgets/users/{id}", admin.AIBudgetDeleteUser)
+			})
 
 			// Help
 			r.Get("/help", admin.HelpPage)
@@ -185,7 +288,9 @@ func New(sessionStore *session.Store, admin *handlers.Admin, auth *handlers.Auth
 
 	// Public routes — served by the dynamic template engine.
 	r.Get("/", public.Homepage)
+	r.With(searchLimiter.Middleware).Get("/search", public.Search)
 	r.Get("/{slug}", public.Page)
+	r.Get("/{locale:[a-z]{2}}/{slug}", public.LocalizedPage)
 
 	return r
 }
@@ -0,0 +1,381 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// Package search provides site search and related posts. Content is
+// embedded with the AI registry's embedding provider; a search blends
+// the similarity of those embeddings with the Postgres full-text rank,
+// so exact terms still win while a query can also find content that
+// uses other words for the same thing.
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/ai"
+	"yaaicms/internal/markdown"
+	"yaaicms/internal/models"
+	"yaaicms/internal/store"
+)
+
+const (
+	// maxDocumentRunes caps the text embedded per content item, keeping
+	// requests within every provider's input limit.
+	maxDocumentRunes = 8000
+
+	// keywordCandidates and semanticCandidates cap the results each
+	// ranking contributes before blending.
+	keywordCandidates  = 100
+	semanticCandidates = 50
+
+	// semanticWeight is the share of the semantic score in a blended
+	// score; the keyword score makes up the rest.
+	semanticWeight = 0.6
+
+	// minSemantic is the similarity a result without any keyword match
+	// needs to be shown.
+	minSemantic = 0.35
+
+	// minRelated is the similarity a related post needs.
+	minRelated = 0.2
+
+	// queryCacheSize caps the cached query embeddings.
+	queryCacheSize = 256
+
+	// embedBatch is the number of documents embedded per request when
+	// backfilling.
+	embedBatch = 16
+)
+
+// Index keeps content embeddings up to date and answers searches and
+// related-post lookups.
+type Index struct {
+	registry     *ai.Registry
+	embeddings   *store.EmbeddingStore
+	content      *store.ContentStore
+	translations *store.TranslationStore
+
+	mu      sync.Mutex
+	queries map[string][]float32 // query embeddings keyed by model and query
+}
+
+// New creates an Index. translations may be nil when the site is not
+// translated.
+func New(registry *ai.Registry, embeddings *store.EmbeddingStore, content *store.ContentStore, translations *store.TranslationStore) *Index {
+	return &Index{
+		registry:     registry,
+		embeddings:   embeddings,
+		content:      content,
+		translations: translations,
+		queries:      make(map[string][]float32),
+	}
+}
+
+// Result is a search hit. Snippet is set for keyword matches; see
+// store.SearchHit.
+type Result struct {
+	Content models.Content
+	Score   float64
+	Snippet string
+}
+
+// Model returns the embedding model new vectors are made with.
+func (x *Index) Model() string {
+	return x.registry.EmbeddingModel()
+}
+
+// Update embeds a content item after it is saved. Published content in
+// the default language is embedded when its text changed; anything else
+// loses its embedding, so it drops out of search and related posts.
+func (x *Index) Update(ctx context.Context, c *models.Content) error {
+	if !c.IsPublished() || x.isTranslation(c.ID) {
+		return x.embeddings.Delete(ctx, c.ID)
+	}
+
+	doc := Document(c)
+	hash := hashDocument(doc)
+	model := x.Model()
+	storedModel, storedHash, err := x.embeddings.SourceHash(c.ID)
+	if err != nil {
+		return err
+	}
+	if storedModel == model && storedHash == hash {
+		return nil
+	}
+
+	out, err := x.registry.Embed(ctx, []string{doc})
+	if err != nil {
+		return fmt.Errorf("embed content: %w", err)
+	}
+	return x.embeddings.Upsert(ctx, c.ID, model, out.Vectors[0], hash)
+}
+
+// HasMissing reports whether any published item lacks an embedding for
+// the current model.
+func (x *Index) HasMissing() (bool, error) {
+	ids, err := x.embeddings.ListMissing(x.Model(), 1)
+	return len(ids) > 0, err
+}
+
+// UpdateMissing embeds up to limit published items that have no
+// embedding for the current model, e.g. after the embedding provider
+// changed. It returns how many were embedded.
+func (x *Index) UpdateMissing(ctx context.Context, limit int) (int, error) {
+	model := x.Model()
+	ids, err := x.embeddings.ListMissing(model, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for start := 0; start < len(ids); start += embedBatch {
+		batch := ids[start:min(start+embedBatch, len(ids))]
+		found, err := x.content.ListByIDs(batch)
+		if err != nil {
+			return done, err
+		}
+
+		var items []*models.Content
+		var docs []string
+		for _, id := range batch {
+			if c := found[id]; c != nil {
+				items = append(items, c)
+				docs = append(docs, Document(c))
+			}
+		}
+		if len(docs) == 0 {
+			continue
+		}
+
+		out, err := x.registry.Embed(ctx, docs)
+		if err != nil {
+			return done, fmt.Errorf("embed content: %w", err)
+		}
+		for i, c := range items {
+			if err := x.embeddings.Upsert(ctx, c.ID, model, out.Vectors[i], hashDocument(docs[i])); err != nil {
+				return done, err
+			}
+			done++
+		}
+	}
+	return done, nil
+}
+
+// Search returns up to limit published items matching q, best first.
+// When the query can't be embedded, results are ranked by keywords only.
+func (x *Index) Search(ctx context.Context, q string, limit int) ([]Result, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, nil
+	}
+
+	keyword, err := x.content.Search(q, store.ContentFilter{Status: models.ContentStatusPublished, DefaultLanguage: true}, store.Page{Number: 1, Size: keywordCandidates})
+	if err != nil {
+		return nil, err
+	}
+
+	var neighbors []store.Neighbor
+	if vec, err := x.queryVector(ctx, q); err != nil {
+		slog.Warn("search query embedding failed, using keywords only", "error", err)
+	} else {
+		neighbors, err = x.embeddings.Nearest(ctx, x.Model(), vec, store.NeighborFilter{}, semanticCandidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Load the content only the semantic ranking found.
+	known := make(map[uuid.UUID]bool, len(keyword.Hits))
+	for _, h := range keyword.Hits {
+		known[h.Content.ID] = true
+	}
+	var missing []uuid.UUID
+	for _, n := range neighbors {
+		if !known[n.ContentID] && n.Score >= minSemantic {
+			missing = append(missing, n.ContentID)
+		}
+	}
+	extra, err := x.content.ListByIDs(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	results := rank(keyword.Hits, neighbors, extra)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Related returns up to limit published posts most similar to c, best
+// first. Translations have no related posts, since only content in the
+// default language is embedded.
+func (x *Index) Related(ctx context.Context, c *models.Content, limit int) ([]models.Content, error) {
+	vec, err := x.embeddings.Find(c.ID, x.Model())
+	if err != nil || vec == nil {
+		return nil, err
+	}
+
+	return x.nearest(ctx, vec, store.NeighborFilter{Type: models.ContentTypePost, Exclude: c.ID}, limit)
+}
+
+// Similar returns up to limit published posts and pages most similar to
+// c, best first, leaving out c itself. Unlike Related it embeds c's
+// current text, so it works for unsaved edits and new content.
+func (x *Index) Similar(ctx context.Context, c *models.Content, limit int) ([]models.Content, error) {
+	out, err := x.registry.Embed(ctx, []string{Document(c)})
+	if err != nil {
+		return nil, fmt.Errorf("embed content: %w", err)
+	}
+	return x.nearest(ctx, out.Vectors[0], store.NeighborFilter{Exclude: c.ID}, limit)
+}
+
+// nearest loads the content nearest to vec that has at least minRelated
+// similarity, best first.
+func (x *Index) nearest(ctx context.Context, vec []float32, f store.NeighborFilter, limit int) ([]models.Content, error) {
+	neighbors, err := x.embeddings.Nearest(ctx, x.Model(), vec, f, limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	for _, n := range neighbors {
+		if n.Score >= minRelated {
+			ids = append(ids, n.ContentID)
+		}
+	}
+	found, err := x.content.ListByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.Content
+	for _, id := range ids {
+		if c := found[id]; c != nil {
+			items = append(items, *c)
+		}
+	}
+	return items, nil
+}
+
+// queryVector embeds a search query, caching the vector so repeated and
+// paginated searches don't call the provider again.
+func (x *Index) queryVector(ctx context.Context, q string) ([]float32, error) {
+	key := x.Model() + "\x00" + strings.ToLower(strings.TrimSpace(q))
+
+	x.mu.Lock()
+	vec, ok := x.queries[key]
+	x.mu.Unlock()
+	if ok {
+		return vec, nil
+	}
+
+	out, err := x.registry.Embed(ctx, []string{q})
+	if err != nil {
+		return nil, err
+	}
+	vec = out.Vectors[0]
+
+	x.mu.Lock()
+	if len(x.queries) >= queryCacheSize {
+		clear(x.queries)
+	}
+	x.queries[key] = vec
+	x.mu.Unlock()
+	return vec, nil
+}
+
+// isTranslation reports whether id is a translation of other content.
+func (x *Index) isTranslation(id uuid.UUID) bool {
+	if x.translations == nil {
+		return false
+	}
+	link, err := x.translations.FindByTranslation(id)
+	if err != nil {
+		slog.Warn("find translation failed", "content", id, "error", err)
+	}
+	return link != nil
+}
+
+// rank blends the keyword hits and the semantic neighbors into one list,
+// best first. Keyword ranks are scaled so the best hit scores 1. extra
+// holds the content of neighbors without a keyword match; those need
+// minSemantic.
+func rank(keyword []store.SearchHit, neighbors []store.Neighbor, extra map[uuid.UUID]*models.Content) []Result {
+	semantic := make(map[uuid.UUID]float64, len(neighbors))
+	for _, n := range neighbors {
+		semantic[n.ContentID] = max(n.Score, 0)
+	}
+	var best float64
+	for _, h := range keyword {
+		best = max(best, h.Rank)
+	}
+
+	var results []Result
+	seen := make(map[uuid.UUID]bool, len(keyword))
+	for _, h := range keyword {
+		seen[h.Content.ID] = true
+		var kw float64
+		if best > 0 {
+			kw = h.Rank / best
+		}
+		score := semanticWeight*semantic[h.Content.ID] + (1-semanticWeight)*kw
+		results = append(results, Result{Content: h.Content, Score: score, Snippet: h.Snippet})
+	}
+	for _, n := range neighbors {
+		c := extra[n.ContentID]
+		if c == nil || seen[n.ContentID] || n.Score < minSemantic {
+			continue
+		}
+		results = append(results, Result{Content: *c, Score: semanticWeight * n.Score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
+
+// tagRe matches HTML tags.
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// Document returns the text embedded for a content item: its title,
+// excerpt, meta description and body as plain text, capped at
+// maxDocumentRunes.
+func Document(c *models.Content) string {
+	body := c.Body
+	if c.BodyFormat == models.BodyFormatMarkdown {
+		if rendered, err := markdown.ToHTML(body); err == nil {
+			body = rendered
+		}
+	}
+	body = html.UnescapeString(tagRe.ReplaceAllString(body, " "))
+
+	parts := []string{c.Title}
+	if c.Excerpt != nil && *c.Excerpt != "" {
+		parts = append(parts, *c.Excerpt)
+	}
+	if c.MetaDescription != nil && *c.MetaDescription != "" {
+		parts = append(parts, *c.MetaDescription)
+	}
+	parts = append(parts, strings.Join(strings.Fields(body), " "))
+
+	doc := strings.Join(parts, "\n\n")
+	if r := []rune(doc); len(r) > maxDocumentRunes {
+		doc = string(r[:maxDocumentRunes])
+	}
+	return doc
+}
+
+// hashDocument returns the hex SHA-256 of an embedded document.
+func hashDocument(doc string) string {
+	sum := sha256.Sum256([]byte(doc))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+	"yaaicms/internal/store"
+)
+
+func TestRank(t *testing.T) {
+	post := func(title string) models.Content {
+		return models.Content{ID: uuid.New(), Title: title}
+	}
+	titleMatch := post("Caching pages")
+	bodyMatch := post("Performance")
+	similar := post("Speeding up page loads")
+	distant := post("Holiday photos")
+
+	keyword := []store.SearchHit{
+		{Content: titleMatch, Rank: 0.6, Snippet: "<mark>Caching</mark> pages"},
+		{Content: bodyMatch, Rank: 0.1},
+	}
+	neighbors := []store.Neighbor{
+		{ContentID: similar.ID, Score: 0.8},
+		{ContentID: bodyMatch.ID, Score: 0.75},
+		{ContentID: distant.ID, Score: 0.1},
+	}
+	extra := map[uuid.UUID]*models.Content{similar.ID: &similar, distant.ID: &distant}
+
+	got := rank(keyword, neighbors, extra)
+	var titles []string
+	for _, r := range got {
+		titles = append(titles, r.Content.Title)
+	}
+	// bodyMatch: 0.6*0.75 + 0.4/6; similar: 0.6*0.8; titleMatch: 0.4*1.
+	want := []string{"Performance", "Speeding up page loads", "Caching pages"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("rank: got %q, want %q", titles, want)
+	}
+	if got[2].Snippet != "<mark>Caching</mark> pages" {
+		t.Errorf("snippet: got %q", got[2].Snippet)
+	}
+
+	// Without embeddings, keyword hits keep their order.
+	got = rank(keyword, nil, nil)
+	if len(got) != 2 || got[0].Content.ID != titleMatch.ID || got[0].Score != 0.4 {
+		t.Errorf("keyword only: got %+v", got)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	excerpt := "Short summary"
+	c := &models.Content{
+		Title:      "Hello",
+		Excerpt:    &excerpt,
+		Body:       "# Heading\n\nSome **bold** text &amp; more.",
+		BodyFormat: models.BodyFormatMarkdown,
+	}
+	doc := Document(c)
+	if doc != "Hello\n\nShort summary\n\nHeading Some bold text & more." {
+		t.Errorf("Document: got %q", doc)
+	}
+
+	c.Body = strings.Repeat("x ", maxDocumentRunes)
+	c.BodyFormat = models.BodyFormatHTML
+	if n := len([]rune(Document(c))); n != maxDocumentRunes {
+		t.Errorf("Document: got %d runes, want %d", n, maxDocumentRunes)
+	}
+	if hashDocument("a") == hashDocument("b") {
+		t.Error("hashDocument collides")
+	}
+}
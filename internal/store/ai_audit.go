@@ -0,0 +1,184 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// ai_audit.go stores the AI audit log: one row per admin request or
+// background job that used AI, searchable and exportable by admins.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// AIAuditStore handles AI audit log rows.
+type AIAuditStore struct {
+	db *sql.DB
+}
+
+// NewAIAuditStore creates a new AIAuditStore.
+func NewAIAuditStore(db *sql.DB) *AIAuditStore {
+	return &AIAuditStore{db: db}
+}
+
+// Record inserts an audit row.
+func (s *AIAuditStore) Record(e *models.AIAudit) error {
+	_, err := s.db.Exec(`
+		INSERT INTO ai_audit (user_id, endpoint, provider, model, calls, prompt_hash, prompt,
+		                      response_chars, moderation, moderation_categories, outcome, target)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, e.UserID, e.Endpoint, e.Provider, e.Model, e.Calls, e.PromptHash, e.Prompt,
+		e.ResponseChars, e.Moderation, e.ModerationCategories, string(e.Outcome), e.Target)
+	if err != nil {
+		return fmt.Errorf("record ai audit: %w", err)
+	}
+	return nil
+}
+
+// AIAuditFilter narrows the audit log. Zero fields match everything.
+type AIAuditFilter struct {
+	Query      string // Found, ignoring case, in the prompt, prompt hash, endpoint or target
+	UserID     *uuid.UUID
+	Endpoint   string
+	Outcome    models.AuditOutcome
+	Moderation string
+	Since      time.Time
+	Until      time.Time // Exclusive
+}
+
+// aiAuditWhere is the WHERE clause for an AIAuditFilter; its arguments
+// come from args.
+const aiAuditWhere = `
+	WHERE ($1 = '' OR strpos(lower(COALESCE(a.prompt, '') || ' ' || a.prompt_hash || ' ' || a.endpoint || ' ' || a.target), lower($1)) > 0)
+	  AND ($2::uuid IS NULL OR a.user_id = $2)
+	  AND ($3 = '' OR a.endpoint = $3)
+	  AND ($4 = '' OR a.outcome = $4)
+	  AND ($5 = '' OR a.moderation = $5)
+	  AND ($6::timestamptz IS NULL OR a.created_at >= $6)
+	  AND ($7::timestamptz IS NULL OR a.created_at < $7)`
+
+func (f AIAuditFilter) args() []any {
+	var since, until *time.Time
+	if !f.Since.IsZero() {
+		since = &f.Since
+	}
+	if !f.Until.IsZero() {
+		until = &f.Until
+	}
+	return []any{f.Query, f.UserID, f.Endpoint, string(f.Outcome), f.Moderation, since, until}
+}
+
+const aiAuditColumns = `
+	a.id, a.user_id, COALESCE(u.display_name, ''), a.endpoint, a.provider, a.model, a.calls,
+	a.prompt_hash, a.prompt, a.response_chars, a.moderation, a.moderation_categories,
+	a.outcome, a.target, a.created_at`
+
+// List returns one page of the rows matching f, newest first, and the
+// number of matching rows.
+func (s *AIAuditStore) List(f AIAuditFilter, page Page) ([]models.AIAudit, int, error) {
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM ai_audit a`+aiAuditWhere, f.args()...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count ai audit: %w", err)
+	}
+
+	args := append(f.args(), page.Size, page.offset())
+	rows, err := s.db.Query(`
+		SELECT `+aiAuditColumns+`
+		FROM ai_audit a
+		LEFT JOIN users u ON u.id = a.user_id`+aiAuditWhere+`
+		ORDER BY a.created_at DESC
+		LIMIT $8 OFFSET $9
+	`, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list ai audit: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.AIAudit
+	for rows.Next() {
+		e, err := scanAIAudit(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan ai audit: %w", err)
+		}
+		items = append(items, *e)
+	}
+	return items, total, rows.Err()
+}
+
+// Each calls fn for every row matching f, newest first, without loading
+// them all at once. It stops at the first error fn returns.
+func (s *AIAuditStore) Each(ctx context.Context, f AIAuditFilter, fn func(*models.AIAudit) error) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+aiAuditColumns+`
+		FROM ai_audit a
+		LEFT JOIN users u ON u.id = a.user_id`+aiAuditWhere+`
+		ORDER BY a.created_at DESC
+	`, f.args()...)
+	if err != nil {
+		return fmt.Errorf("query ai audit: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAIAudit(rows)
+		if err != nil {
+			return fmt.Errorf("scan ai audit: %w", err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Endpoints returns the distinct endpoints in the log, sorted.
+func (s *AIAuditStore) Endpoints() ([]string, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT endpoint FROM ai_audit ORDER BY endpoint`)
+	if err != nil {
+		return nil, fmt.Errorf("list ai audit endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []string
+	for rows.Next() {
+		var e string
+		if err := rows.Scan(&e); err != nil {
+			return nil, fmt.Errorf("scan ai audit endpoint: %w", err)
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, rows.Err()
+}
+
+// Prune deletes rows older than the retention period and returns the
+// number of rows removed.
+func (s *AIAuditStore) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM ai_audit
+		WHERE created_at < $1
+	`, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("prune ai audit: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+func scanAIAudit(scanner interface{ Scan(...any) error }) (*models.AIAudit, error) {
+	var e models.AIAudit
+	var outcome string
+	err := scanner.Scan(&e.ID, &e.UserID, &e.UserName, &e.Endpoint, &e.Provider, &e.Model, &e.Calls,
+		&e.PromptHash, &e.Prompt, &e.ResponseChars, &e.Moderation, &e.ModerationCategories,
+		&outcome, &e.Target, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	e.Outcome = models.AuditOutcome(outcome)
+	return &e, nil
+}
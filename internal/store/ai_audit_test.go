@@ -0,0 +1,89 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+func TestAIAuditStoreFilter(t *testing.T) {
+	db := testDB(t)
+	s := NewAIAuditStore(db)
+
+	// A unique endpoint isolates this test's rows from other data.
+	endpoint := "/test/" + uuid.New().String()[:8]
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM ai_audit WHERE endpoint = $1", endpoint)
+	})
+
+	prompt := "Write about Compost Heaps"
+	rows := []*models.AIAudit{
+		{Endpoint: endpoint, Provider: "p", Model: "m", Calls: 1, PromptHash: "abc123", Prompt: &prompt,
+			ResponseChars: 120, Moderation: models.ModerationPassed, Outcome: models.AuditOK},
+		{Endpoint: endpoint, PromptHash: "def456", Moderation: models.ModerationFlagged,
+			ModerationCategories: "violence", Outcome: models.AuditBlocked},
+		{Endpoint: endpoint, Outcome: models.AuditSaved, Target: "template/42"},
+	}
+	for _, r := range rows {
+		if err := s.Record(r); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	list := func(f AIAuditFilter) []models.AIAudit {
+		t.Helper()
+		f.Endpoint = endpoint
+		items, total, err := s.List(f, Page{Number: 1, Size: 10})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != len(items) {
+			t.Errorf("total %d, got %d rows", total, len(items))
+		}
+		return items
+	}
+
+	if got := list(AIAuditFilter{}); len(got) != 3 || got[0].Outcome != models.AuditSaved {
+		t.Errorf("all rows, newest first: %+v", got)
+	}
+	if got := list(AIAuditFilter{Query: "compost"}); len(got) != 1 || *got[0].Prompt != prompt || got[0].ResponseChars != 120 {
+		t.Errorf("prompt search: %+v", got)
+	}
+	if got := list(AIAuditFilter{Query: "DEF456"}); len(got) != 1 || got[0].ModerationCategories != "violence" {
+		t.Errorf("hash search: %+v", got)
+	}
+	if got := list(AIAuditFilter{Outcome: models.AuditSaved}); len(got) != 1 || got[0].Target != "template/42" {
+		t.Errorf("outcome filter: %+v", got)
+	}
+	if got := list(AIAuditFilter{Moderation: models.ModerationFlagged}); len(got) != 1 {
+		t.Errorf("moderation filter: %+v", got)
+	}
+	if got := list(AIAuditFilter{Since: time.Now().Add(time.Hour)}); len(got) != 0 {
+		t.Errorf("future since: %+v", got)
+	}
+
+	n := 0
+	if err := s.Each(context.Background(), AIAuditFilter{Endpoint: endpoint}, func(*models.AIAudit) error { n++; return nil }); err != nil || n != 3 {
+		t.Errorf("Each: %d rows, %v", n, err)
+	}
+
+	endpoints, err := s.Endpoints()
+	if err != nil {
+		t.Fatalf("Endpoints: %v", err)
+	}
+	found := false
+	for _, e := range endpoints {
+		found = found || e == endpoint
+	}
+	if !found {
+		t.Errorf("endpoint %q missing from %v", endpoint, endpoints)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// ai_budget.go manages per-role and per-user AI budgets.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// AIBudgetStore handles AI budget persistence.
+type AIBudgetStore struct {
+	db *sql.DB
+}
+
+// NewAIBudgetStore creates a new AIBudgetStore.
+func NewAIBudgetStore(db *sql.DB) *AIBudgetStore {
+	return &AIBudgetStore{db: db}
+}
+
+// aiBudgetColumns lists the columns selected in budget queries.
+const aiBudgetColumns = `b.id, b.role, b.user_id, COALESCE(u.display_name, ''),
+	b.daily_tokens, b.daily_cost_usd::float8, b.monthly_tokens, b.monthly_cost_usd::float8, b.updated_at`
+
+// List returns all budgets: role budgets first, then user overrides by
+// display name.
+func (s *AIBudgetStore) List() ([]models.AIBudget, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + aiBudgetColumns + `
+		FROM ai_budgets b
+		LEFT JOIN users u ON u.id = b.user_id
+		ORDER BY b.role IS NULL, b.role, u.display_name`)
+	if err != nil {
+		return nil, fmt.Errorf("list ai budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.AIBudget
+	for rows.Next() {
+		b, err := scanAIBudget(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan ai budget: %w", err)
+		}
+		items = append(items, *b)
+	}
+	return items, rows.Err()
+}
+
+// ForUser returns the budget that applies to a user: their own override
+// if one exists, otherwise their role's budget. Returns nil if neither
+// exists.
+func (s *AIBudgetStore) ForUser(userID uuid.UUID, role models.Role) (*models.AIBudget, error) {
+	b, err := scanAIBudget(s.db.QueryRow(`
+		SELECT `+aiBudgetColumns+`
+		FROM ai_budgets b
+		LEFT JOIN users u ON u.id = b.user_id
+		WHERE b.user_id = $1 OR b.role = $2
+		ORDER BY b.user_id IS NULL
+		LIMIT 1`, userID, role))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find ai budget: %w", err)
+	}
+	return b, nil
+}
+
+// SaveRole creates or replaces the budget for a role.
+func (s *AIBudgetStore) SaveRole(role models.Role, b *models.AIBudget) error {
+	_, err := s.db.Exec(`
+		INSERT INTO ai_budgets (role, daily_tokens, daily_cost_usd, monthly_tokens, monthly_cost_usd)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (role) DO UPDATE SET
+			daily_tokens = EXCLUDED.daily_tokens,
+			daily_cost_usd = EXCLUDED.daily_cost_usd,
+			monthly_tokens = EXCLUDED.monthly_tokens,
+			monthly_cost_usd = EXCLUDED.monthly_cost_usd,
+			updated_at = NOW()`,
+		role, b.DailyTokens, b.DailyCostUSD, b.MonthlyTokens, b.MonthlyCostUSD)
+	if err != nil {
+		return fmt.Errorf("save role ai budget: %w", err)
+	}
+	return nil
+}
+
+// SaveUser creates or replaces a user's budget override.
+func (s *AIBudgetStore) SaveUser(userID uuid.UUID, b *models.AIBudget) error {
+	_, err := s.db.Exec(`
+		INSERT INTO ai_budgets (user_id, daily_tokens, daily_cost_usd, monthly_tokens, monthly_cost_usd)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			daily_tokens = EXCLUDED.daily_tokens,
+			daily_cost_usd = EXCLUDED.daily_cost_usd,
+			monthly_tokens = EXCLUDED.monthly_tokens,
+			monthly_cost_usd = EXCLUDED.monthly_cost_usd,
+			updated_at = NOW()`,
+		userID, b.DailyTokens, b.DailyCostUSD, b.MonthlyTokens, b.MonthlyCostUSD)
+	if err != nil {
+		return fmt.Errorf("save user ai budget: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user's override so their role's budget applies again.
+func (s *AIBudgetStore) DeleteUser(userID uuid.UUID) error {
+	if _, err := s.db.Exec(`DELETE FROM ai_budgets WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete user ai budget: %w", err)
+	}
+	return nil
+}
+
+// scanAIBudget scans a row selected with aiBudgetColumns.
+func scanAIBudget(scanner interface{ Scan(...any) error }) (*models.AIBudget, error) {
+	var b models.AIBudget
+	err := scanner.Scan(
+		&b.ID, &b.Role, &b.UserID, &b.UserName, &b.DailyTokens, &b.DailyCostUSD,
+		&b.MonthlyTokens, &b.MonthlyCostUSD, &b.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
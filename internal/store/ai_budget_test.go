@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"testing"
+
+	"yaaicms/internal/models"
+)
+
+func TestAIBudgetStoreForUser(t *testing.T) {
+	db := testDB(t)
+	s := NewAIBudgetStore(db)
+	users := NewUserStore(db)
+
+	email := "test-aibudget@store-test.local"
+	t.Cleanup(func() { cleanUsers(t, db, email) })
+	u, err := users.Create(email, "password123", "Budget Test", models.RoleAuthor)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM ai_budgets WHERE role = 'author'")
+	})
+
+	// No budget at all.
+	if b, err := s.ForUser(u.ID, models.RoleAuthor); err != nil || b != nil {
+		t.Fatalf("expected no budget, got %+v, %v", b, err)
+	}
+
+	// The role budget applies...
+	daily := int64(5000)
+	if err := s.SaveRole(models.RoleAuthor, &models.AIBudget{DailyTokens: &daily}); err != nil {
+		t.Fatalf("SaveRole: %v", err)
+	}
+	b, err := s.ForUser(u.ID, models.RoleAuthor)
+	if err != nil || b == nil || b.Role == nil || *b.Role != models.RoleAuthor {
+		t.Fatalf("expected author budget, got %+v, %v", b, err)
+	}
+	if b.DailyTokens == nil || *b.DailyTokens != 5000 || b.MonthlyCostUSD != nil {
+		t.Errorf("limits: got %+v", b)
+	}
+
+	// ...until the user gets an override.
+	cost := 1.5
+	if err := s.SaveUser(u.ID, &models.AIBudget{MonthlyCostUSD: &cost}); err != nil {
+		t.Fatalf("SaveUser: %v", err)
+	}
+	b, err = s.ForUser(u.ID, models.RoleAuthor)
+	if err != nil || b == nil || b.UserID == nil || *b.UserID != u.ID {
+		t.Fatalf("expected user override, got %+v, %v", b, err)
+	}
+	if b.UserName != "Budget Test" || b.MonthlyCostUSD == nil || *b.MonthlyCostUSD != 1.5 || b.DailyTokens != nil {
+		t.Errorf("override: got %+v", b)
+	}
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) < 2 || list[0].Role == nil {
+		t.Errorf("expected role budgets first, got %+v", list)
+	}
+
+	if err := s.DeleteUser(u.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if b, _ := s.ForUser(u.ID, models.RoleAuthor); b == nil || b.Role == nil {
+		t.Errorf("expected role budget after removing override, got %+v", b)
+	}
+}
+
+func TestAIUsageStoreSpend(t *testing.T) {
+	db := testDB(t)
+	usage := NewAIUsageStore(db)
+	users := NewUserStore(db)
+
+	email := "test-aispend@store-test.local"
+	u, err := users.Create(email, "password123", "Spend Test", models.RoleEditor)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM ai_usage WHERE user_id = $1", u.ID)
+		cleanUsers(t, db, email)
+	})
+
+	cost := 0.2
+	for i := 0; i < 2; i++ {
+		if err := usage.Record(&models.AIUsage{UserID: &u.ID, Task: "light", Provider: "test", Model: "m",
+			InputTokens: 30, OutputTokens: 20, CostUSD: &cost, Success: true}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	sp, err := usage.Spend(u.ID)
+	if err != nil {
+		t.Fatalf("Spend: %v", err)
+	}
+	if sp.DailyTokens != 100 || sp.MonthlyTokens != 100 {
+		t.Errorf("tokens: got %d daily / %d monthly, want 100", sp.DailyTokens, sp.MonthlyTokens)
+	}
+	if sp.DailyCostUSD < 0.39 || sp.DailyCostUSD > 0.41 {
+		t.Errorf("daily cost: got %v, want 0.4", sp.DailyCostUSD)
+	}
+
+	all, err := usage.SpendByUser()
+	if err != nil {
+		t.Fatalf("SpendByUser: %v", err)
+	}
+	if all[u.ID] != sp {
+		t.Errorf("SpendByUser: got %+v, want %+v", all[u.ID], sp)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// ai_bulk.go stores bulk AI runs and their per-content items. Items move
+// from queued to proposed when their job finishes, and from proposed to
+// accepted or rejected when an editor reviews them.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// AIBulkStore handles bulk AI run persistence.
+type AIBulkStore struct {
+	db *sql.DB
+}
+
+// NewAIBulkStore creates a new AIBulkStore.
+func NewAIBulkStore(db *sql.DB) *AIBulkStore {
+	return &AIBulkStore{db: db}
+}
+
+// bulkRunSelect selects runs with their item counts per status. Callers
+// append WHERE/GROUP BY/ORDER BY.
+const bulkRunSelect = `
+	SELECT r.id, r.action, r.options, r.filter, r.created_by, r.created_at,
+	       COUNT(i.id),
+	       COUNT(i.id) FILTER (WHERE i.status = 'queued'),
+	       COUNT(i.id) FILTER (WHERE i.status = 'proposed'),
+	       COUNT(i.id) FILTER (WHERE i.status = 'accepted'),
+	       COUNT(i.id) FILTER (WHERE i.status = 'rejected'),
+	       COUNT(i.id) FILTER (WHERE i.status = 'failed')
+	FROM ai_bulk_runs r
+	LEFT JOIN ai_bulk_items i ON i.run_id = r.id`
+
+// scanBulkRun scans a row selected with bulkRunSelect.
+func scanBulkRun(scanner interface{ Scan(...any) error }) (*models.AIBulkRun, error) {
+	var r models.AIBulkRun
+	err := scanner.Scan(
+		&r.ID, &r.Action, &r.Options, &r.Filter, &r.CreatedBy, &r.CreatedAt,
+		&r.Total, &r.Queued, &r.Proposed, &r.Accepted, &r.Rejected, &r.Failed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// bulkItemSelect selects items with their content title and job status.
+const bulkItemSelect = `
+	SELECT i.id, i.run_id, i.content_id, i.job_id, i.status, i.proposal, i.error,
+	       i.revision_id, i.created_at, i.updated_at,
+	       c.title, c.type, COALESCE(j.status, '')
+	FROM ai_bulk_items i
+	JOIN content c ON c.id = i.content_id
+	LEFT JOIN ai_jobs j ON j.id = i.job_id`
+
+// scanBulkItem scans a row selected with bulkItemSelect.
+func scanBulkItem(scanner interface{ Scan(...any) error }) (*models.AIBulkItem, error) {
+	var i models.AIBulkItem
+	err := scanner.Scan(
+		&i.ID, &i.RunID, &i.ContentID, &i.JobID, &i.Status, &i.Proposal, &i.Error,
+		&i.RevisionID, &i.CreatedAt, &i.UpdatedAt,
+		&i.ContentTitle, &i.ContentType, &i.JobStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// CreateRun inserts a run with one queued item per content ID and returns
+// the run and the new item IDs, in the order of contentIDs.
+func (s *AIBulkStore) CreateRun(run *models.AIBulkRun, contentIDs []uuid.UUID) (*models.AIBulkRun, []uuid.UUID, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	created := *run
+	err = tx.QueryRow(`
+		INSERT INTO ai_bulk_runs (action, options, filter, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		run.Action, run.Options, run.Filter, run.CreatedBy,
+	).Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create bulk run: %w", err)
+	}
+
+	itemIDs := make([]uuid.UUID, 0, len(contentIDs))
+	for _, cid := range contentIDs {
+		var id uuid.UUID
+		err := tx.QueryRow(`
+			INSERT INTO ai_bulk_items (run_id, content_id) VALUES ($1, $2) RETURNING id`,
+			created.ID, cid,
+		).Scan(&id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create bulk item: %w", err)
+		}
+		itemIDs = append(itemIDs, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("commit bulk run: %w", err)
+	}
+	created.Total = len(itemIDs)
+	created.Queued = len(itemIDs)
+	return &created, itemIDs, nil
+}
+
+// SetItemJob links an item to the job that processes it.
+func (s *AIBulkStore) SetItemJob(id, jobID uuid.UUID) error {
+	_, err := s.db.Exec(`
+		UPDATE ai_bulk_items SET job_id = $2, updated_at = NOW() WHERE id = $1`, id, jobID)
+	if err != nil {
+		return fmt.Errorf("set bulk item job: %w", err)
+	}
+	return nil
+}
+
+// FindRun returns a run with its item counts, or nil if it doesn't exist.
+func (s *AIBulkStore) FindRun(id uuid.UUID) (*models.AIBulkRun, error) {
+	r, err := scanBulkRun(s.db.QueryRow(bulkRunSelect+`
+		WHERE r.id = $1
+		GROUP BY r.id`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find bulk run: %w", err)
+	}
+	return r, nil
+}
+
+// ListRuns returns the most recent runs, newest first. If createdBy is
+// non-nil only that user's runs are returned.
+func (s *AIBulkStore) ListRuns(createdBy *uuid.UUID, limit int) ([]models.AIBulkRun, error) {
+	rows, err := s.db.Query(bulkRunSelect+`
+		WHERE $1::uuid IS NULL OR r.created_by = $1
+		GROUP BY r.id
+		ORDER BY r.created_at DESC
+		LIMIT $2`, createdBy, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list bulk runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []models.AIBulkRun
+	for rows.Next() {
+		r, err := scanBulkRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan bulk run: %w", err)
+		}
+		runs = append(runs, *r)
+	}
+	return runs, rows.Err()
+}
+
+// Items returns a run's items ordered by content title.
+func (s *AIBulkStore) Items(runID uuid.UUID) ([]models.AIBulkItem, error) {
+	rows, err := s.db.Query(bulkItemSelect+`
+		WHERE i.run_id = $1
+		ORDER BY c.title, i.id`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("list bulk items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.AIBulkItem
+	for rows.Next() {
+		i, err := scanBulkItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan bulk item: %w", err)
+		}
+		items = append(items, *i)
+	}
+	return items, rows.Err()
+}
+
+// FindItem returns an item, or nil if it doesn't exist.
+func (s *AIBulkStore) FindItem(id uuid.UUID) (*models.AIBulkItem, error) {
+	i, err := scanBulkItem(s.db.QueryRow(bulkItemSelect+`
+		WHERE i.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find bulk item: %w", err)
+	}
+	return i, nil
+}
+
+// Propose stores a queued item's proposed change for review.
+func (s *AIBulkStore) Propose(ctx context.Context, id uuid.UUID, proposal string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE ai_bulk_items
+		SET status = 'proposed', proposal = $2, error = '', updated_at = NOW()
+		WHERE id = $1 AND status = 'queued'`, id, proposal)
+	if err != nil {
+		return fmt.Errorf("propose bulk item: %w", err)
+	}
+	return nil
+}
+
+// Fail marks an item as failed with the reason.
+func (s *AIBulkStore) Fail(ctx context.Context, id uuid.UUID, errMsg string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE ai_bulk_items SET status = 'failed', error = $2, updated_at = NOW()
+		WHERE id = $1`, id, errMsg)
+	if err != nil {
+		return fmt.Errorf("fail bulk item: %w", err)
+	}
+	return nil
+}
+
+// Requeue puts a failed item back to queued so its job can run again.
+// Returns false if the item had not failed.
+func (s *AIBulkStore) Requeue(id uuid.UUID) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE ai_bulk_items SET status = 'queued', error = '', updated_at = NOW()
+		WHERE id = $1 AND status = 'failed'`, id)
+	if err != nil {
+		return false, fmt.Errorf("requeue bulk item: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Decide moves a proposed item to accepted or rejected. Returns false if
+// the item was not proposed, e.g. because it was already decided.
+func (s *AIBulkStore) Decide(id uuid.UUID, status models.BulkItemStatus) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE ai_bulk_items SET status = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'proposed'`, id, status)
+	if err != nil {
+		return false, fmt.Errorf("decide bulk item: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SetRevision records the revision taken before an accepted change.
+func (s *AIBulkStore) SetRevision(id, revisionID uuid.UUID) error {
+	_, err := s.db.Exec(`
+		UPDATE ai_bulk_items SET revision_id = $2, updated_at = NOW() WHERE id = $1`, id, revisionID)
+	if err != nil {
+		return fmt.Errorf("set bulk item revision: %w", err)
+	}
+	return nil
+}
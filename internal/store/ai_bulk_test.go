@@ -0,0 +1,129 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+func TestAIBulkStore(t *testing.T) {
+	db := testDB(t)
+	s := NewAIBulkStore(db)
+	cs := NewContentStore(db)
+	ctx := context.Background()
+	authorID := testAuthorID(t, db)
+
+	prefix := "test-bulk-" + uuid.NewString()[:8]
+	slugA, slugB := prefix+"-a", prefix+"-b"
+	t.Cleanup(func() { cleanContent(t, db, slugA, slugB) })
+
+	var ids []uuid.UUID
+	for _, slug := range []string{slugA, slugB} {
+		c, err := cs.Create(&models.Content{
+			Type: models.ContentTypePost, Title: slug, Slug: slug, Body: "body",
+			Status: models.ContentStatusDraft, AuthorID: authorID,
+		})
+		if err != nil {
+			t.Fatalf("Create content: %v", err)
+		}
+		ids = append(ids, c.ID)
+	}
+
+	// ListFiltered sees both drafts and applies the status filter.
+	drafts, err := cs.ListFiltered(ContentFilter{Type: models.ContentTypePost, Status: models.ContentStatusDraft})
+	if err != nil {
+		t.Fatalf("ListFiltered: %v", err)
+	}
+	found := 0
+	for _, c := range drafts {
+		if c.Slug == slugA || c.Slug == slugB {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("ListFiltered drafts: found %d of 2", found)
+	}
+	published, _ := cs.ListFiltered(ContentFilter{Type: models.ContentTypePost, Status: models.ContentStatusPublished})
+	for _, c := range published {
+		if c.Slug == slugA || c.Slug == slugB {
+			t.Errorf("ListFiltered published returned draft %s", c.Slug)
+		}
+	}
+
+	run, itemIDs, err := s.CreateRun(&models.AIBulkRun{
+		Action: models.BulkExcerpt, Options: `{}`, Filter: "Draft posts", CreatedBy: &authorID,
+	}, ids)
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM ai_bulk_runs WHERE id = $1", run.ID) })
+	if len(itemIDs) != 2 || run.Total != 2 || run.Queued != 2 {
+		t.Fatalf("CreateRun: got run %+v, items %v", run, itemIDs)
+	}
+
+	// Propose, then decide once; the second decision is refused.
+	if err := s.Propose(ctx, itemIDs[0], `{"excerpt":"x"}`); err != nil {
+		t.Fatalf("Propose: %v", err)
+	}
+	item, err := s.FindItem(itemIDs[0])
+	if err != nil || item == nil {
+		t.Fatalf("FindItem: %v, %v", item, err)
+	}
+	if item.Status != models.BulkItemProposed || item.Proposal != `{"excerpt":"x"}` || item.ContentTitle != slugA {
+		t.Errorf("proposed item: got %+v", item)
+	}
+	if ok, err := s.Decide(itemIDs[0], models.BulkItemAccepted); err != nil || !ok {
+		t.Fatalf("Decide: %v, %v", ok, err)
+	}
+	if ok, _ := s.Decide(itemIDs[0], models.BulkItemRejected); ok {
+		t.Error("Decide changed an accepted item")
+	}
+
+	// A failed item can be requeued once.
+	if err := s.Fail(ctx, itemIDs[1], "boom"); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if ok, err := s.Requeue(itemIDs[1]); err != nil || !ok {
+		t.Fatalf("Requeue: %v, %v", ok, err)
+	}
+	if ok, _ := s.Requeue(itemIDs[1]); ok {
+		t.Error("Requeue changed a queued item")
+	}
+
+	got, err := s.FindRun(run.ID)
+	if err != nil || got == nil {
+		t.Fatalf("FindRun: %v, %v", got, err)
+	}
+	if got.Accepted != 1 || got.Queued != 1 || got.Failed != 0 {
+		t.Errorf("counts: got %+v", got)
+	}
+
+	runs, err := s.ListRuns(&authorID, 50)
+	if err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	listed := false
+	for _, r := range runs {
+		if r.ID == run.ID {
+			listed = true
+		}
+	}
+	if !listed {
+		t.Error("ListRuns did not return the run")
+	}
+
+	items, err := s.Items(run.ID)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Items: %v, %v", items, err)
+	}
+	if items[0].ContentTitle != slugA || items[1].ContentTitle != slugB {
+		t.Errorf("Items order: got %s, %s", items[0].ContentTitle, items[1].ContentTitle)
+	}
+}
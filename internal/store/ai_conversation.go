@@ -0,0 +1,210 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// ai_conversation.go stores template-builder and content-assistant
+// conversations and their messages.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// AIConversationStore handles AI conversation persistence.
+type AIConversationStore struct {
+	db *sql.DB
+}
+
+// NewAIConversationStore creates a new AIConversationStore.
+func NewAIConversationStore(db *sql.DB) *AIConversationStore {
+	return &AIConversationStore{db: db}
+}
+
+// aiConversationColumns lists the columns selected in conversation
+// queries, including the message count.
+const aiConversationColumns = `c.id, c.user_id, c.kind, c.title, c.target, c.current_html,
+	c.forked_from, (SELECT COUNT(*) FROM ai_conversation_messages m WHERE m.conversation_id = c.id),
+	c.created_at, c.updated_at`
+
+// scanAIConversation scans a single conversation row.
+func scanAIConversation(scanner interface{ Scan(...any) error }) (*models.AIConversation, error) {
+	var c models.AIConversation
+	err := scanner.Scan(
+		&c.ID, &c.UserID, &c.Kind, &c.Title, &c.Target, &c.CurrentHTML,
+		&c.ForkedFrom, &c.MessageCount, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Create inserts a conversation with no messages and returns it.
+func (s *AIConversationStore) Create(c *models.AIConversation) (*models.AIConversation, error) {
+	var id uuid.UUID
+	err := s.db.QueryRow(`
+		INSERT INTO ai_conversations (user_id, kind, title, target, current_html)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		c.UserID, c.Kind, c.Title, c.Target, c.CurrentHTML,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("create ai conversation: %w", err)
+	}
+	return s.FindByID(id)
+}
+
+// FindByID returns a conversation, or nil if it doesn't exist.
+func (s *AIConversationStore) FindByID(id uuid.UUID) (*models.AIConversation, error) {
+	c, err := scanAIConversation(s.db.QueryRow(`
+		SELECT `+aiConversationColumns+`
+		FROM ai_conversations c
+		WHERE c.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find ai conversation: %w", err)
+	}
+	return c, nil
+}
+
+// ListByUser returns a user's conversations of one kind, most recently
+// updated first, without their current HTML.
+func (s *AIConversationStore) ListByUser(userID uuid.UUID, kind models.ConversationKind, limit int) ([]models.AIConversation, error) {
+	rows, err := s.db.Query(`
+		SELECT `+aiConversationColumns+`
+		FROM ai_conversations c
+		WHERE c.user_id = $1 AND c.kind = $2
+		ORDER BY c.updated_at DESC
+		LIMIT $3`, userID, kind, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list ai conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.AIConversation
+	for rows.Next() {
+		c, err := scanAIConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan ai conversation: %w", err)
+		}
+		c.CurrentHTML = ""
+		items = append(items, *c)
+	}
+	return items, rows.Err()
+}
+
+// Messages returns a conversation's messages in order.
+func (s *AIConversationStore) Messages(id uuid.UUID) ([]models.AIConversationMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT position, role, content, created_at
+		FROM ai_conversation_messages
+		WHERE conversation_id = $1
+		ORDER BY position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list ai conversation messages: %w", err)
+	}
+	defer rows.Close()
+
+	var msgs []models.AIConversationMessage
+	for rows.Next() {
+		var m models.AIConversationMessage
+		if err := rows.Scan(&m.Position, &m.Role, &m.Content, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan ai conversation message: %w", err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, rows.Err()
+}
+
+// AppendTurn adds a user message and the assistant's reply to the end of
+// a conversation. A non-empty currentHTML replaces the conversation's
+// current template.
+func (s *AIConversationStore) AppendTurn(id uuid.UUID, userMsg, assistantMsg, currentHTML string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the conversation so concurrent turns get distinct positions.
+	_, err = tx.Exec(`
+		UPDATE ai_conversations
+		SET updated_at = NOW(), current_html = COALESCE(NULLIF($2, ''), current_html)
+		WHERE id = $1`, id, currentHTML)
+	if err != nil {
+		return fmt.Errorf("update ai conversation: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO ai_conversation_messages (conversation_id, position, role, content)
+		SELECT $1, next.n + v.offs, v.role, v.content
+		FROM (SELECT COALESCE(MAX(position), 0) AS n FROM ai_conversation_messages WHERE conversation_id = $1) next,
+		     (VALUES (1, 'user', $2::text), (2, 'assistant', $3::text)) AS v(offs, role, content)`,
+		id, userMsg, assistantMsg)
+	if err != nil {
+		return fmt.Errorf("append ai conversation messages: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Fork copies a conversation's messages up to and including position
+// into a new conversation owned by the same user, with currentHTML as the
+// fork's current template.
+func (s *AIConversationStore) Fork(id uuid.UUID, position int, currentHTML string) (*models.AIConversation, error) {
+	src, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if src == nil {
+		return nil, fmt.Errorf("fork ai conversation: %w", sql.ErrNoRows)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var forkID uuid.UUID
+	err = tx.QueryRow(`
+		INSERT INTO ai_conversations (user_id, kind, title, target, current_html, forked_from)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		src.UserID, src.Kind, "Fork of "+src.Title, src.Target, currentHTML, src.ID,
+	).Scan(&forkID)
+	if err != nil {
+		return nil, fmt.Errorf("create ai conversation fork: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO ai_conversation_messages (conversation_id, position, role, content, created_at)
+		SELECT $1, position, role, content, created_at
+		FROM ai_conversation_messages
+		WHERE conversation_id = $2 AND position <= $3`,
+		forkID, src.ID, position)
+	if err != nil {
+		return nil, fmt.Errorf("copy ai conversation messages: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit ai conversation fork: %w", err)
+	}
+	return s.FindByID(forkID)
+}
+
+// Delete removes a conversation and its messages.
+func (s *AIConversationStore) Delete(id uuid.UUID) error {
+	if _, err := s.db.Exec(`DELETE FROM ai_conversations WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete ai conversation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"testing"
+
+	"yaaicms/internal/models"
+)
+
+func TestAIConversationStore(t *testing.T) {
+	db := testDB(t)
+	s := NewAIConversationStore(db)
+	users := NewUserStore(db)
+
+	email := "test-aiconv@store-test.local"
+	t.Cleanup(func() { cleanUsers(t, db, email) })
+	u, err := users.Create(email, "password123", "Conversation Test", models.RoleAuthor)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	c, err := s.Create(&models.AIConversation{
+		UserID: u.ID,
+		Kind:   models.ConversationTemplate,
+		Title:  "Dark header",
+		Target: "header",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if c.MessageCount != 0 || c.Target != "header" {
+		t.Errorf("created: got %+v", c)
+	}
+
+	if err := s.AppendTurn(c.ID, "Make a dark header", "<header>v1</header>", "<header>v1</header>"); err != nil {
+		t.Fatalf("AppendTurn: %v", err)
+	}
+	if err := s.AppendTurn(c.ID, "Add a logo", "<header>v2</header>", "<header>v2</header>"); err != nil {
+		t.Fatalf("AppendTurn: %v", err)
+	}
+
+	msgs, err := s.Messages(c.ID)
+	if err != nil {
+		t.Fatalf("Messages: %v", err)
+	}
+	if len(msgs) != 4 {
+		t.Fatalf("messages: got %d, want 4", len(msgs))
+	}
+	for i, m := range msgs {
+		if m.Position != i+1 {
+			t.Errorf("message %d: position %d", i, m.Position)
+		}
+	}
+	if msgs[0].Role != "user" || msgs[3].Role != "assistant" || msgs[3].Content != "<header>v2</header>" {
+		t.Errorf("messages: got %+v", msgs)
+	}
+
+	got, err := s.FindByID(c.ID)
+	if err != nil || got == nil {
+		t.Fatalf("FindByID: %v, %v", got, err)
+	}
+	if got.CurrentHTML != "<header>v2</header>" || got.MessageCount != 4 {
+		t.Errorf("after turns: got %+v", got)
+	}
+
+	// Forking after the first reply drops the second turn.
+	fork, err := s.Fork(c.ID, 2, "<header>v1</header>")
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if fork.MessageCount != 2 || fork.ForkedFrom == nil || *fork.ForkedFrom != c.ID || fork.CurrentHTML != "<header>v1</header>" {
+		t.Errorf("fork: got %+v", fork)
+	}
+
+	list, err := s.ListByUser(u.ID, models.ConversationTemplate, 10)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != fork.ID {
+		t.Errorf("list: got %+v", list)
+	}
+	if content, _ := s.ListByUser(u.ID, models.ConversationContent, 10); len(content) != 0 {
+		t.Errorf("content threads: got %d", len(content))
+	}
+
+	// Deleting the source keeps the fork.
+	if err := s.Delete(c.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, _ := s.FindByID(c.ID); got != nil {
+		t.Error("conversation should be deleted")
+	}
+	fork, _ = s.FindByID(fork.ID)
+	if fork == nil || fork.ForkedFrom != nil {
+		t.Errorf("fork after delete: got %+v", fork)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// ai_job.go stores the background AI job queue. Workers claim jobs with
+// FOR UPDATE SKIP LOCKED so concurrent workers never take the same row.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// AIJobStore handles background job persistence.
+type AIJobStore struct {
+	db *sql.DB
+}
+
+// NewAIJobStore creates a new AIJobStore.
+func NewAIJobStore(db *sql.DB) *AIJobStore {
+	return &AIJobStore{db: db}
+}
+
+// aiJobColumns lists the columns selected in job queries.
+const aiJobColumns = `id, kind, payload, status, attempts, max_attempts, run_at,
+	progress_done, progress_total, result, error, created_by, created_at, updated_at, finished_at`
+
+// scanAIJob scans a single job row.
+func scanAIJob(scanner interface{ Scan(...any) error }) (*models.AIJob, error) {
+	var j models.AIJob
+	err := scanner.Scan(
+		&j.ID, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+		&j.ProgressDone, &j.ProgressTotal, &j.Result, &j.Error, &j.CreatedBy,
+		&j.CreatedAt, &j.UpdatedAt, &j.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// Enqueue inserts a pending job and returns it.
+func (s *AIJobStore) Enqueue(kind, payload string, maxAttempts int, createdBy *uuid.UUID) (*models.AIJob, error) {
+	j, err := scanAIJob(s.db.QueryRow(`
+		INSERT INTO ai_jobs (kind, payload, max_attempts, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+aiJobColumns,
+		kind, payload, maxAttempts, createdBy))
+	if err != nil {
+		return nil, fmt.Errorf("enqueue ai job: %w", err)
+	}
+	return j, nil
+}
+
+// Claim marks the oldest due pending job as running, counting the
+// attempt, and returns it. Returns nil, nil when no job is due.
+func (s *AIJobStore) Claim(ctx context.Context) (*models.AIJob, error) {
+	j, err := scanAIJob(s.db.QueryRowContext(ctx, `
+		UPDATE ai_jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM ai_jobs
+			WHERE status = 'pending' AND run_at <= NOW()
+			ORDER BY run_at, created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+aiJobColumns))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("claim ai job: %w", err)
+	}
+	return j, nil
+}
+
+// Heartbeat, SetProgress, Complete, Retry, Fail and Release act on a
+// claimed job and take the claim's attempt number (the job's Attempts as
+// returned by Claim) as its token. They change nothing unless the job is
+// still running under that attempt: a worker whose job was requeued as
+// stale, and possibly claimed again, must not touch the newer claim. The
+// ones that end the attempt report whether they did.
+
+// Heartbeat refreshes a running job's lock so it isn't taken for stale.
+func (s *AIJobStore) Heartbeat(ctx context.Context, id uuid.UUID, attempt int) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE ai_jobs SET locked_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`, id, attempt)
+	if err != nil {
+		return fmt.Errorf("heartbeat ai job: %w", err)
+	}
+	return nil
+}
+
+// SetProgress records how many of a running job's items are done.
+func (s *AIJobStore) SetProgress(ctx context.Context, id uuid.UUID, attempt, done, total int) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE ai_jobs
+		SET progress_done = $3, progress_total = $4, locked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`, id, attempt, done, total)
+	if err != nil {
+		return fmt.Errorf("set ai job progress: %w", err)
+	}
+	return nil
+}
+
+// Complete marks a running job as succeeded with its JSON result.
+func (s *AIJobStore) Complete(ctx context.Context, id uuid.UUID, attempt int, result string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_jobs
+		SET status = 'succeeded', result = $3, error = '', locked_at = NULL,
+		    updated_at = NOW(), finished_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`, id, attempt, result)
+	if err != nil {
+		return false, fmt.Errorf("complete ai job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Retry puts a failed running job back to pending, to run again at runAt.
+func (s *AIJobStore) Retry(ctx context.Context, id uuid.UUID, attempt int, errMsg string, runAt time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_jobs
+		SET status = 'pending', error = $3, run_at = $4, locked_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`, id, attempt, errMsg, runAt)
+	if err != nil {
+		return false, fmt.Errorf("retry ai job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Fail marks a running job as failed for good.
+func (s *AIJobStore) Fail(ctx context.Context, id uuid.UUID, attempt int, errMsg string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_jobs
+		SET status = 'failed', error = $3, locked_at = NULL, updated_at = NOW(), finished_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`, id, attempt, errMsg)
+	if err != nil {
+		return false, fmt.Errorf("fail ai job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Release puts a running job back to pending without counting the
+// attempt. Used when a worker is stopped mid-job during shutdown.
+func (s *AIJobStore) Release(ctx context.Context, id uuid.UUID, attempt int) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE ai_jobs
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0), locked_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2`, id, attempt)
+	if err != nil {
+		return false, fmt.Errorf("release ai job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// staleJobError is the error recorded on a stale job that has no attempts
+// left.
+const staleJobError = "worker stopped during the last attempt"
+
+// RequeueStale handles running jobs whose lock is older than staleAfter:
+// their worker stopped without finishing them (crash, kill). Jobs with
+// attempts left go back to pending; the others fail, so a job that
+// crashes its worker isn't retried forever. Returns how many were
+// requeued and how many failed.
+func (s *AIJobStore) RequeueStale(ctx context.Context, staleAfter time.Duration) (requeued, failed int64, err error) {
+	err = s.db.QueryRowContext(ctx, `
+		WITH stale AS (
+			UPDATE ai_jobs
+			SET status = CASE WHEN attempts >= max_attempts THEN 'failed' ELSE 'pending' END,
+			    error = CASE WHEN attempts >= max_attempts THEN $2 ELSE error END,
+			    finished_at = CASE WHEN attempts >= max_attempts THEN NOW() END,
+			    locked_at = NULL, updated_at = NOW()
+			WHERE status = 'running' AND locked_at < $1
+			RETURNING status
+		)
+		SELECT COUNT(*) FILTER (WHERE status = 'pending'), COUNT(*) FILTER (WHERE status = 'failed')
+		FROM stale`, time.Now().Add(-staleAfter), staleJobError).Scan(&requeued, &failed)
+	if err != nil {
+		return 0, 0, fmt.Errorf("requeue stale ai jobs: %w", err)
+	}
+	return requeued, failed, nil
+}
+
+// Cancel marks a pending job as cancelled. Returns false if the job was
+// not pending (already running or finished).
+func (s *AIJobStore) Cancel(id uuid.UUID) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE ai_jobs
+		SET status = 'cancelled', updated_at = NOW(), finished_at = NOW()
+		WHERE id = $1 AND status = 'pending'`, id)
+	if err != nil {
+		return false, fmt.Errorf("cancel ai job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Resubmit puts a failed or cancelled job back to pending with a fresh
+// set of attempts. Returns false if the job is in another state.
+func (s *AIJobStore) Resubmit(id uuid.UUID) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE ai_jobs
+		SET status = 'pending', attempts = 0, error = '', run_at = NOW(),
+		    progress_done = 0, updated_at = NOW(), finished_at = NULL
+		WHERE id = $1 AND status IN ('failed', 'cancelled')`, id)
+	if err != nil {
+		return false, fmt.Errorf("resubmit ai job: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// FindByID returns a job, or nil if it doesn't exist.
+func (s *AIJobStore) FindByID(id uuid.UUID) (*models.AIJob, error) {
+	j, err := scanAIJob(s.db.QueryRow(`
+		SELECT `+aiJobColumns+` FROM ai_jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find ai job: %w", err)
+	}
+	return j, nil
+}
+
+// ListRecent returns the most recently created jobs, newest first. If
+// createdBy is non-nil only that user's jobs are returned.
+func (s *AIJobStore) ListRecent(createdBy *uuid.UUID, limit int) ([]models.AIJob, error) {
+	rows, err := s.db.Query(`
+		SELECT `+aiJobColumns+`
+		FROM ai_jobs
+		WHERE $1::uuid IS NULL OR created_by = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, createdBy, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list ai jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.AIJob
+	for rows.Next() {
+		j, err := scanAIJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan ai job: %w", err)
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, rows.Err()
+}
+
+// Prune deletes finished jobs older than retention.
+func (s *AIJobStore) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM ai_jobs
+		WHERE status IN ('succeeded', 'failed', 'cancelled') AND finished_at < $1`,
+		time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("prune ai jobs: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// enqueueFirst enqueues a job and backdates it so Claim takes it before
+// any job left pending by other tests.
+func enqueueFirst(t *testing.T, db *sql.DB, s *AIJobStore, kind string) *models.AIJob {
+	t.Helper()
+	j, err := s.Enqueue(kind, `{"n":1}`, 2, nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE ai_jobs SET run_at = '2000-01-01' WHERE id = $1`, j.ID); err != nil {
+		t.Fatalf("backdate job: %v", err)
+	}
+	return j
+}
+
+func TestAIJobStore(t *testing.T) {
+	db := testDB(t)
+	s := NewAIJobStore(db)
+	ctx := context.Background()
+
+	const kind = "store_test_job"
+	t.Cleanup(func() { db.Exec("DELETE FROM ai_jobs WHERE kind = $1", kind) })
+
+	j := enqueueFirst(t, db, s, kind)
+	if j.Status != models.JobPending || j.Attempts != 0 || j.MaxAttempts != 2 || j.Payload != `{"n":1}` {
+		t.Fatalf("enqueued: got %+v", j)
+	}
+
+	// A row locked by another transaction is skipped, not waited on.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err := tx.Exec(`SELECT id FROM ai_jobs WHERE id = $1 FOR UPDATE`, j.ID); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	other, err := s.Claim(ctx)
+	if err != nil {
+		t.Fatalf("Claim while locked: %v", err)
+	}
+	if other != nil {
+		if other.ID == j.ID {
+			t.Error("Claim took a locked job")
+		}
+		// Put back the unrelated job we took.
+		s.Release(ctx, other.ID, other.Attempts)
+	}
+	tx.Rollback()
+
+	claimed, err := s.Claim(ctx)
+	if err != nil || claimed == nil || claimed.ID != j.ID {
+		t.Fatalf("Claim: got %+v, %v", claimed, err)
+	}
+	if claimed.Status != models.JobRunning || claimed.Attempts != 1 {
+		t.Errorf("claimed: got %+v", claimed)
+	}
+
+	if err := s.SetProgress(ctx, j.ID, claimed.Attempts, 3, 10); err != nil {
+		t.Fatalf("SetProgress: %v", err)
+	}
+
+	// A running job can't be cancelled or resubmitted.
+	if ok, err := s.Cancel(j.ID); err != nil || ok {
+		t.Errorf("Cancel running: got %v, %v", ok, err)
+	}
+	if ok, err := s.Resubmit(j.ID); err != nil || ok {
+		t.Errorf("Resubmit running: got %v, %v", ok, err)
+	}
+
+	// Retry puts it back with the error; it isn't due until runAt.
+	if ok, err := s.Retry(ctx, j.ID, claimed.Attempts, "rate limited", time.Now().Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("Retry: got %v, %v", ok, err)
+	}
+	got, _ := s.FindByID(j.ID)
+	if got.Status != models.JobPending || got.Error != "rate limited" || got.ProgressDone != 3 || got.ProgressTotal != 10 {
+		t.Errorf("after Retry: got %+v", got)
+	}
+	if next, _ := s.Claim(ctx); next != nil && next.ID == j.ID {
+		t.Error("Claim took a job before its run_at")
+	}
+
+	// Release doesn't count the interrupted attempt.
+	db.Exec(`UPDATE ai_jobs SET run_at = '2000-01-01' WHERE id = $1`, j.ID)
+	if c, _ := s.Claim(ctx); c == nil || c.ID != j.ID || c.Attempts != 2 {
+		t.Fatalf("second Claim: got %+v", c)
+	}
+	if ok, err := s.Release(ctx, j.ID, 2); err != nil || !ok {
+		t.Fatalf("Release: got %v, %v", ok, err)
+	}
+	got, _ = s.FindByID(j.ID)
+	if got.Status != models.JobPending || got.Attempts != 1 {
+		t.Errorf("after Release: got %+v", got)
+	}
+
+	// Complete stores the result and clears the error.
+	if c, _ := s.Claim(ctx); c == nil || c.ID != j.ID || c.Attempts != 2 {
+		t.Fatalf("third Claim: got %+v", c)
+	}
+	if ok, err := s.Complete(ctx, j.ID, 2, `{"ok":true}`); err != nil || !ok {
+		t.Fatalf("Complete: got %v, %v", ok, err)
+	}
+	got, _ = s.FindByID(j.ID)
+	if got.Status != models.JobSucceeded || got.Result != `{"ok":true}` || got.Error != "" || got.FinishedAt == nil {
+		t.Errorf("after Complete: got %+v", got)
+	}
+
+	// Fail, then resubmit from scratch.
+	f := enqueueFirst(t, db, s, kind)
+	if c, _ := s.Claim(ctx); c == nil || c.ID != f.ID {
+		t.Fatalf("Claim failing job: got %+v", c)
+	}
+	if ok, err := s.Fail(ctx, f.ID, 1, "bad key"); err != nil || !ok {
+		t.Fatalf("Fail: got %v, %v", ok, err)
+	}
+	if ok, err := s.Resubmit(f.ID); err != nil || !ok {
+		t.Fatalf("Resubmit: got %v, %v", ok, err)
+	}
+	got, _ = s.FindByID(f.ID)
+	if got.Status != models.JobPending || got.Attempts != 0 || got.Error != "" || got.FinishedAt != nil {
+		t.Errorf("after Resubmit: got %+v", got)
+	}
+
+	// Cancel only works on pending jobs.
+	if ok, err := s.Cancel(f.ID); err != nil || !ok {
+		t.Fatalf("Cancel: got %v, %v", ok, err)
+	}
+	if ok, _ := s.Cancel(f.ID); ok {
+		t.Error("Cancel of a cancelled job succeeded")
+	}
+
+	// A running job with a stale lock goes back to pending.
+	r := enqueueFirst(t, db, s, kind)
+	if c, _ := s.Claim(ctx); c == nil || c.ID != r.ID {
+		t.Fatalf("Claim stale job: got %+v", c)
+	}
+	db.Exec(`UPDATE ai_jobs SET locked_at = NOW() - INTERVAL '1 hour' WHERE id = $1`, r.ID)
+	if n, _, err := s.RequeueStale(ctx, 5*time.Minute); err != nil || n < 1 {
+		t.Errorf("RequeueStale: got %d, %v", n, err)
+	}
+	got, _ = s.FindByID(r.ID)
+	if got.Status != models.JobPending {
+		t.Errorf("after RequeueStale: got %s", got.Status)
+	}
+
+	// The worker that lost the job can no longer record an outcome.
+	for name, record := range map[string]func() (bool, error){
+		"Complete": func() (bool, error) { return s.Complete(ctx, r.ID, 1, `{}`) },
+		"Retry":    func() (bool, error) { return s.Retry(ctx, r.ID, 1, "late", time.Now()) },
+		"Fail":     func() (bool, error) { return s.Fail(ctx, r.ID, 1, "late") },
+		"Release":  func() (bool, error) { return s.Release(ctx, r.ID, 1) },
+	} {
+		if ok, err := record(); err != nil || ok {
+			t.Errorf("%s after requeue: got %v, %v", name, ok, err)
+		}
+	}
+	if got, _ = s.FindByID(r.ID); got.Status != models.JobPending || got.Error != "" {
+		t.Errorf("stale worker changed the job: got %+v", got)
+	}
+
+	// A stale job on its last attempt fails instead, so a job that kills
+	// its worker isn't retried forever.
+	db.Exec(`UPDATE ai_jobs SET run_at = '2000-01-01' WHERE id = $1`, r.ID)
+	if c, _ := s.Claim(ctx); c == nil || c.ID != r.ID || c.Attempts != 2 {
+		t.Fatalf("Claim stale job again: got %+v", c)
+	}
+	db.Exec(`UPDATE ai_jobs SET locked_at = NOW() - INTERVAL '1 hour' WHERE id = $1`, r.ID)
+	if _, failed, err := s.RequeueStale(ctx, 5*time.Minute); err != nil || failed < 1 {
+		t.Errorf("RequeueStale last attempt: got %d failed, %v", failed, err)
+	}
+	got, _ = s.FindByID(r.ID)
+	if got.Status != models.JobFailed || got.Error != staleJobError || got.FinishedAt == nil {
+		t.Errorf("after RequeueStale on the last attempt: got %+v", got)
+	}
+
+	// Prune removes old finished jobs only.
+	p := enqueueFirst(t, db, s, kind)
+	db.Exec(`UPDATE ai_jobs SET finished_at = NOW() - INTERVAL '30 days' WHERE id IN ($1, $2)`, j.ID, p.ID)
+	if _, err := s.Prune(ctx, 7*24*time.Hour); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if got, _ := s.FindByID(j.ID); got != nil {
+		t.Error("Prune kept an old succeeded job")
+	}
+	if got, _ := s.FindByID(p.ID); got == nil {
+		t.Error("Prune removed a pending job")
+	}
+
+	if got, err := s.FindByID(uuid.New()); err != nil || got != nil {
+		t.Errorf("FindByID unknown: got %+v, %v", got, err)
+	}
+}
+
+func TestAIJobStoreStaleClaim(t *testing.T) {
+	db := testDB(t)
+	s := NewAIJobStore(db)
+	ctx := context.Background()
+
+	const kind = "store_test_stale_claim"
+	t.Cleanup(func() { db.Exec("DELETE FROM ai_jobs WHERE kind = $1", kind) })
+
+	// The first worker's claim goes stale and a second worker claims the
+	// job again.
+	j := enqueueFirst(t, db, s, kind)
+	first, _ := s.Claim(ctx)
+	if first == nil || first.ID != j.ID {
+		t.Fatalf("first Claim: got %+v", first)
+	}
+	db.Exec(`UPDATE ai_jobs SET locked_at = NOW() - INTERVAL '1 hour' WHERE id = $1`, j.ID)
+	if n, _, err := s.RequeueStale(ctx, 5*time.Minute); err != nil || n < 1 {
+		t.Fatalf("RequeueStale: got %d, %v", n, err)
+	}
+	db.Exec(`UPDATE ai_jobs SET run_at = '2000-01-01' WHERE id = $1`, j.ID)
+	second, _ := s.Claim(ctx)
+	if second == nil || second.ID != j.ID || second.Attempts != first.Attempts+1 {
+		t.Fatalf("second Claim: got %+v", second)
+	}
+	before, _ := s.FindByID(j.ID)
+
+	// The first worker finishing late changes nothing.
+	if err := s.Heartbeat(ctx, j.ID, first.Attempts); err != nil {
+		t.Errorf("Heartbeat: %v", err)
+	}
+	if err := s.SetProgress(ctx, j.ID, first.Attempts, 5, 5); err != nil {
+		t.Errorf("SetProgress: %v", err)
+	}
+	for name, record := range map[string]func() (bool, error){
+		"Complete": func() (bool, error) { return s.Complete(ctx, j.ID, first.Attempts, `{"late":true}`) },
+		"Retry":    func() (bool, error) { return s.Retry(ctx, j.ID, first.Attempts, "late", time.Now()) },
+		"Fail":     func() (bool, error) { return s.Fail(ctx, j.ID, first.Attempts, "late") },
+		"Release":  func() (bool, error) { return s.Release(ctx, j.ID, first.Attempts) },
+	} {
+		if ok, err := record(); err != nil || ok {
+			t.Errorf("%s with the stale claim: got %v, %v", name, ok, err)
+		}
+	}
+	got, _ := s.FindByID(j.ID)
+	if got.Status != models.JobRunning || got.Attempts != second.Attempts || got.Result != "" || got.Error != "" ||
+		got.ProgressDone != 0 || !got.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("second claim changed: got %+v, want %+v", got, before)
+	}
+
+	// The second worker still owns the job.
+	if ok, err := s.Complete(ctx, j.ID, second.Attempts, `{"ok":true}`); err != nil || !ok {
+		t.Errorf("Complete with the current claim: got %v, %v", ok, err)
+	}
+}
+
+func TestAIJobStoreListRecent(t *testing.T) {
+	db := testDB(t)
+	s := NewAIJobStore(db)
+	users := NewUserStore(db)
+
+	const kind = "store_test_list"
+	email := "test-aijobs@store-test.local"
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM ai_jobs WHERE kind = $1", kind)
+		cleanUsers(t, db, email)
+	})
+	u, err := users.Create(email, "password123", "Jobs Test", models.RoleAuthor)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	mine, err := s.Enqueue(kind, "{}", 3, &u.ID)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := s.Enqueue(kind, "{}", 3, nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	list, err := s.ListRecent(&u.ID, 10)
+	if err != nil {
+		t.Fatalf("ListRecent: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != mine.ID {
+		t.Errorf("user's jobs: got %+v", list)
+	}
+
+	all, err := s.ListRecent(nil, 1000)
+	if err != nil {
+		t.Fatalf("ListRecent all: %v", err)
+	}
+	n := 0
+	for _, j := range all {
+		if j.Kind == kind {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("all jobs: got %d of kind %s, want 2", n, kind)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// ai_usage.go records one row per AI completion and aggregates them for
+// the usage and cost report.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// AIUsageStore handles AI usage accounting.
+type AIUsageStore struct {
+	db *sql.DB
+}
+
+// NewAIUsageStore creates a new AIUsageStore.
+func NewAIUsageStore(db *sql.DB) *AIUsageStore {
+	return &AIUsageStore{db: db}
+}
+
+// Record inserts a usage row.
+func (s *AIUsageStore) Record(u *models.AIUsage) error {
+	_, err := s.db.Exec(`
+		INSERT INTO ai_usage (user_id, task, provider, model, input_tokens, output_tokens,
+		                      latency_ms, cost_usd, success)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, u.UserID, u.Task, u.Provider, u.Model, u.InputTokens, u.OutputTokens,
+		u.LatencyMs, u.CostUSD, u.Success)
+	if err != nil {
+		return fmt.Errorf("record ai usage: %w", err)
+	}
+	return nil
+}
+
+// UsageDimension selects how Breakdown groups usage rows.
+type UsageDimension string
+
+const (
+	UsageByDay      UsageDimension = "day"
+	UsageByUser     UsageDimension = "user"
+	UsageByTask     UsageDimension = "task"
+	UsageByProvider UsageDimension = "provider"
+	UsageByModel    UsageDimension = "model"
+)
+
+// usageGroupings maps each dimension to its key and label expressions and
+// the ordering of the resulting groups.
+var usageGroupings = map[UsageDimension]struct{ key, label, order string }{
+	UsageByDay:      {"to_char(a.created_at, 'YYYY-MM-DD')", "to_char(a.created_at, 'Mon DD')", "1 DESC"},
+	UsageByUser:     {"COALESCE(a.user_id::text, '')", "COALESCE(MAX(u.display_name), '')", "cost DESC, calls DESC"},
+	UsageByTask:     {"a.task", "a.task", "cost DESC, calls DESC"},
+	UsageByProvider: {"a.provider", "a.provider", "cost DESC, calls DESC"},
+	UsageByModel:    {"a.provider || '/' || a.model", "a.provider || '/' || a.model", "cost DESC, calls DESC"},
+}
+
+// usageAggregates is the aggregate column list shared by Totals and
+// Breakdown; both scan the columns in this order.
+const usageAggregates = `
+	COUNT(*) AS calls,
+	COUNT(*) FILTER (WHERE NOT a.success),
+	COALESCE(SUM(a.input_tokens), 0),
+	COALESCE(SUM(a.output_tokens), 0),
+	COALESCE(SUM(a.cost_usd), 0)::float8 AS cost,
+	COUNT(*) FILTER (WHERE a.cost_usd IS NULL),
+	COALESCE(AVG(a.latency_ms), 0)::bigint`
+
+// Totals returns usage aggregated over every call since the given time.
+func (s *AIUsageStore) Totals(since time.Time) (models.AIUsageSummary, error) {
+	var t models.AIUsageSummary
+	err := s.db.QueryRow(`
+		SELECT `+usageAggregates+`
+		FROM ai_usage a
+		WHERE a.created_at >= $1
+	`, since).Scan(&t.Calls, &t.Failed, &t.InputTokens, &t.OutputTokens,
+		&t.CostUSD, &t.Unpriced, &t.AvgLatencyMs)
+	if err != nil {
+		return t, fmt.Errorf("ai usage totals: %w", err)
+	}
+	return t, nil
+}
+
+// Breakdown returns usage since the given time grouped by dim. Days are
+// newest first; every other dimension is ordered by cost, then calls.
+func (s *AIUsageStore) Breakdown(dim UsageDimension, since time.Time) ([]models.AIUsageSummary, error) {
+	g, ok := usageGroupings[dim]
+	if !ok {
+		return nil, fmt.Errorf("unknown usage dimension %q", dim)
+	}
+
+	// The label of non-user groupings is a function of the key; wrap it in
+	// MAX so the query stays valid with a single GROUP BY column.
+	label := g.label
+	if dim != UsageByUser {
+		label = "MAX(" + g.label + ")"
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+g.key+`, `+label+`,`+usageAggregates+`
+		FROM ai_usage a
+		LEFT JOIN users u ON u.id = a.user_id
+		WHERE a.created_at >= $1
+		GROUP BY 1
+		ORDER BY `+g.order, since)
+	if err != nil {
+		return nil, fmt.Errorf("ai usage by %s: %w", dim, err)
+	}
+	defer rows.Close()
+
+	var items []models.AIUsageSummary
+	for rows.Next() {
+		var r models.AIUsageSummary
+		if err := rows.Scan(&r.Key, &r.Label, &r.Calls, &r.Failed, &r.InputTokens,
+			&r.OutputTokens, &r.CostUSD, &r.Unpriced, &r.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("scan ai usage: %w", err)
+		}
+		items = append(items, r)
+	}
+	return items, rows.Err()
+}
+
+// spendColumns sums tokens and cost for the current UTC day and month.
+// Rows must already be limited to the current month.
+const spendColumns = `
+	COALESCE(SUM(input_tokens + output_tokens) FILTER (WHERE created_at >= date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'), 0),
+	COALESCE(SUM(cost_usd) FILTER (WHERE created_at >= date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'), 0)::float8,
+	COALESCE(SUM(input_tokens + output_tokens), 0),
+	COALESCE(SUM(cost_usd), 0)::float8`
+
+// monthStart is the start of the current UTC month in SQL.
+const monthStart = `date_trunc('month', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`
+
+// Spend returns a user's usage in the current UTC day and month, for
+// budget checks.
+func (s *AIUsageStore) Spend(userID uuid.UUID) (models.AISpend, error) {
+	var sp models.AISpend
+	err := s.db.QueryRow(`
+		SELECT `+spendColumns+`
+		FROM ai_usage
+		WHERE user_id = $1 AND created_at >= `+monthStart,
+		userID).Scan(&sp.DailyTokens, &sp.DailyCostUSD, &sp.MonthlyTokens, &sp.MonthlyCostUSD)
+	if err != nil {
+		return sp, fmt.Errorf("ai spend: %w", err)
+	}
+	return sp, nil
+}
+
+// SpendByUser returns the current day and month usage of every user with
+// AI calls this month.
+func (s *AIUsageStore) SpendByUser() (map[uuid.UUID]models.AISpend, error) {
+	rows, err := s.db.Query(`
+		SELECT user_id, ` + spendColumns + `
+		FROM ai_usage
+		WHERE user_id IS NOT NULL AND created_at >= ` + monthStart + `
+		GROUP BY user_id`)
+	if err != nil {
+		return nil, fmt.Errorf("ai spend by user: %w", err)
+	}
+	defer rows.Close()
+
+	spend := make(map[uuid.UUID]models.AISpend)
+	for rows.Next() {
+		var id uuid.UUID
+		var sp models.AISpend
+		if err := rows.Scan(&id, &sp.DailyTokens, &sp.DailyCostUSD, &sp.MonthlyTokens, &sp.MonthlyCostUSD); err != nil {
+			return nil, fmt.Errorf("scan ai spend: %w", err)
+		}
+		spend[id] = sp
+	}
+	return spend, rows.Err()
+}
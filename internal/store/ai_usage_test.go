@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+func TestAIUsageStoreBreakdown(t *testing.T) {
+	db := testDB(t)
+	s := NewAIUsageStore(db)
+
+	// A unique provider name isolates this test's rows from other data.
+	provider := "test-" + uuid.New().String()[:8]
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM ai_usage WHERE provider = $1", provider)
+	})
+
+	cost := 0.25
+	rows := []*models.AIUsage{
+		{Task: "content", Provider: provider, Model: "m1", InputTokens: 100, OutputTokens: 50, LatencyMs: 200, CostUSD: &cost, Success: true},
+		{Task: "light", Provider: provider, Model: "m1", InputTokens: 10, OutputTokens: 5, LatencyMs: 100, CostUSD: &cost, Success: true},
+		{Task: "content", Provider: provider, Model: "unpriced", LatencyMs: 300, Success: false},
+	}
+	for _, r := range rows {
+		if err := s.Record(r); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	since := time.Now().Add(-time.Hour)
+	byProvider, err := s.Breakdown(UsageByProvider, since)
+	if err != nil {
+		t.Fatalf("Breakdown: %v", err)
+	}
+	var got *models.AIUsageSummary
+	for i := range byProvider {
+		if byProvider[i].Key == provider {
+			got = &byProvider[i]
+		}
+	}
+	if got == nil {
+		t.Fatalf("provider %q missing from breakdown", provider)
+	}
+	if got.Calls != 3 || got.Failed != 1 || got.Unpriced != 1 {
+		t.Errorf("calls/failed/unpriced: got %d/%d/%d, want 3/1/1", got.Calls, got.Failed, got.Unpriced)
+	}
+	if got.InputTokens != 110 || got.OutputTokens != 55 || got.TotalTokens() != 165 {
+		t.Errorf("tokens: got %d in / %d out", got.InputTokens, got.OutputTokens)
+	}
+	if got.CostUSD != 0.5 {
+		t.Errorf("cost: got %v, want 0.5", got.CostUSD)
+	}
+	if got.AvgLatencyMs != 200 {
+		t.Errorf("avg latency: got %d, want 200", got.AvgLatencyMs)
+	}
+
+	byModel, err := s.Breakdown(UsageByModel, since)
+	if err != nil {
+		t.Fatalf("Breakdown by model: %v", err)
+	}
+	modelGroups := 0
+	for _, m := range byModel {
+		if m.Key == provider+"/m1" || m.Key == provider+"/unpriced" {
+			modelGroups++
+		}
+	}
+	if modelGroups != 2 {
+		t.Errorf("expected 2 model groups for %q, got %d", provider, modelGroups)
+	}
+
+	for _, dim := range []UsageDimension{UsageByDay, UsageByUser, UsageByTask} {
+		if _, err := s.Breakdown(dim, since); err != nil {
+			t.Errorf("Breakdown(%s): %v", dim, err)
+		}
+	}
+	if _, err := s.Breakdown("bogus", since); err == nil {
+		t.Error("expected error for unknown dimension")
+	}
+
+	totals, err := s.Totals(since)
+	if err != nil {
+		t.Fatalf("Totals: %v", err)
+	}
+	if totals.Calls < 3 {
+		t.Errorf("totals: expected at least 3 calls, got %d", totals.Calls)
+	}
+}
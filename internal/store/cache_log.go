@@ -4,13 +4,16 @@
 
 // cache_log.go records cache invalidation events in the database for
 // audit and debugging purposes. Each entry captures what was invalidated,
-// when, and why (create/update/delete).
+// when, why (create/update/delete/purge), by whom, and how many cache
+// keys were removed.
 package store
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -25,12 +28,13 @@ func NewCacheLogStore(db *sql.DB) *CacheLogStore {
 	return &CacheLogStore{db: db}
 }
 
-// Log records a cache invalidation event.
-func (s *CacheLogStore) Log(entityType string, entityID uuid.UUID, action string) {
+// Log records a cache invalidation event. userID is nil for events not
+// triggered by a logged-in user.
+func (s *CacheLogStore) Log(entityType string, entityID uuid.UUID, action string, userID *uuid.UUID, keysAffected int) {
 	_, err := s.db.Exec(`
-		INSERT INTO cache_invalidation_log (entity_type, entity_id, action)
-		VALUES ($1, $2, $3)
-	`, entityType, entityID, action)
+		INSERT INTO cache_invalidation_log (entity_type, entity_id, action, user_id, keys_affected)
+		VALUES ($1, $2, $3, $4, $5)
+	`, entityType, entityID, action, userID, keysAffected)
 	if err != nil {
 		// Log but don't fail — cache logging is best-effort.
 		slog.Warn("failed to log cache invalidation",
@@ -45,6 +49,7 @@ func (s *CacheLogStore) Log(entityType string, entityID uuid.UUID, action string
 		"entity_type", entityType,
 		"entity_id", entityID,
 		"action", action,
+		"keys_affected", keysAffected,
 	)
 }
 
@@ -52,9 +57,11 @@ func (s *CacheLogStore) Log(entityType string, entityID uuid.UUID, action string
 // debugging. Limited to the specified count.
 func (s *CacheLogStore) RecentEntries(limit int) ([]CacheLogEntry, error) {
 	rows, err := s.db.Query(`
-		SELECT id, entity_type, entity_id, action, invalidated_at
-		FROM cache_invalidation_log
-		ORDER BY invalidated_at DESC
+		SELECT l.id, l.entity_type, l.entity_id, l.action, l.user_id,
+		       COALESCE(u.display_name, ''), l.keys_affected, l.invalidated_at
+		FROM cache_invalidation_log l
+		LEFT JOIN users u ON u.id = l.user_id
+		ORDER BY l.invalidated_at DESC
 		LIMIT $1
 	`, limit)
 	if err != nil {
@@ -65,7 +72,8 @@ func (s *CacheLogStore) RecentEntries(limit int) ([]CacheLogEntry, error) {
 	var entries []CacheLogEntry
 	for rows.Next() {
 		var e CacheLogEntry
-		if err := rows.Scan(&e.ID, &e.EntityType, &e.EntityID, &e.Action, &e.InvalidatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.EntityType, &e.EntityID, &e.Action, &e.UserID,
+			&e.UserName, &e.KeysAffected, &e.InvalidatedAt); err != nil {
 			return nil, fmt.Errorf("scan cache log: %w", err)
 		}
 		entries = append(entries, e)
@@ -73,11 +81,78 @@ func (s *CacheLogStore) RecentEntries(limit int) ([]CacheLogEntry, error) {
 	return entries, rows.Err()
 }
 
+// RecentByEntity returns invalidations grouped by entity, most recently
+// invalidated first. Each group carries the latest action and actor plus
+// totals over the group. Labels are resolved from the content and
+// templates tables; deleted entities have an empty label.
+func (s *CacheLogStore) RecentByEntity(limit int) ([]CacheLogGroup, error) {
+	rows, err := s.db.Query(`
+		SELECT l.entity_type, l.entity_id,
+		       COALESCE(MAX(c.title), MAX(t.name), ''),
+		       COUNT(*),
+		       SUM(l.keys_affected),
+		       (ARRAY_AGG(l.action ORDER BY l.invalidated_at DESC))[1],
+		       (ARRAY_AGG(COALESCE(u.display_name, '') ORDER BY l.invalidated_at DESC))[1],
+		       MAX(l.invalidated_at)
+		FROM cache_invalidation_log l
+		LEFT JOIN users u     ON u.id = l.user_id
+		LEFT JOIN content c   ON l.entity_type = 'content'  AND c.id = l.entity_id
+		LEFT JOIN templates t ON l.entity_type = 'template' AND t.id = l.entity_id
+		GROUP BY l.entity_type, l.entity_id
+		ORDER BY MAX(l.invalidated_at) DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query cache log groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []CacheLogGroup
+	for rows.Next() {
+		var g CacheLogGroup
+		if err := rows.Scan(&g.EntityType, &g.EntityID, &g.Label, &g.Count, &g.KeysAffected,
+			&g.LastAction, &g.LastUserName, &g.LastAt); err != nil {
+			return nil, fmt.Errorf("scan cache log group: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// Prune deletes log entries older than the retention period and returns
+// the number of rows removed.
+func (s *CacheLogStore) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM cache_invalidation_log
+		WHERE invalidated_at < $1
+	`, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("prune cache log: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
 // CacheLogEntry represents a single cache invalidation event.
 type CacheLogEntry struct {
 	ID            int64
 	EntityType    string
 	EntityID      uuid.UUID
 	Action        string
+	UserID        *uuid.UUID // Nil for system-triggered events
+	UserName      string     // Display name of UserID, empty if none
+	KeysAffected  int
 	InvalidatedAt string
 }
+
+// CacheLogGroup summarizes all invalidations of one entity.
+type CacheLogGroup struct {
+	EntityType   string
+	EntityID     uuid.UUID
+	Label        string // Content title or template name
+	Count        int
+	KeysAffected int
+	LastAction   string
+	LastUserName string
+	LastAt       time.Time
+}
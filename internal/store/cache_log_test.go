@@ -5,7 +5,9 @@
 package store
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -16,7 +18,7 @@ func TestCacheLogStoreLog(t *testing.T) {
 
 	// Log should not error (best-effort).
 	entityID := uuid.New()
-	s.Log("content", entityID, "update")
+	s.Log("content", entityID, "update", nil, 2)
 
 	// Clean up.
 	t.Cleanup(func() {
@@ -34,6 +36,16 @@ func TestCacheLogStoreLog(t *testing.T) {
 	if count != 1 {
 		t.Errorf("expected 1 log entry, got %d", count)
 	}
+
+	var keys int
+	if err := db.QueryRow(
+		"SELECT keys_affected FROM cache_invalidation_log WHERE entity_id = $1", entityID,
+	).Scan(&keys); err != nil {
+		t.Fatalf("query keys_affected: %v", err)
+	}
+	if keys != 2 {
+		t.Errorf("keys_affected: got %d, want 2", keys)
+	}
 }
 
 func TestCacheLogStoreRecentEntries(t *testing.T) {
@@ -43,8 +55,8 @@ func TestCacheLogStoreRecentEntries(t *testing.T) {
 	// Insert a few log entries.
 	id1 := uuid.New()
 	id2 := uuid.New()
-	s.Log("content", id1, "create")
-	s.Log("template", id2, "delete")
+	s.Log("content", id1, "create", nil, 0)
+	s.Log("template", id2, "delete", nil, 5)
 
 	t.Cleanup(func() {
 		db.Exec("DELETE FROM cache_invalidation_log WHERE entity_id IN ($1, $2)", id1, id2)
@@ -66,3 +78,74 @@ func TestCacheLogStoreRecentEntries(t *testing.T) {
 		}
 	}
 }
+
+func TestCacheLogStoreActorAndGroups(t *testing.T) {
+	db := testDB(t)
+	s := NewCacheLogStore(db)
+
+	var userID uuid.UUID
+	if err := db.QueryRow("SELECT id FROM users LIMIT 1").Scan(&userID); err != nil {
+		t.Skipf("no users in database: %v", err)
+	}
+
+	entityID := uuid.New()
+	s.Log("content", entityID, "update", nil, 2)
+	s.Log("content", entityID, "purge", &userID, 1)
+
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM cache_invalidation_log WHERE entity_id = $1", entityID)
+	})
+
+	groups, err := s.RecentByEntity(50)
+	if err != nil {
+		t.Fatalf("RecentByEntity: %v", err)
+	}
+
+	var found *CacheLogGroup
+	for i := range groups {
+		if groups[i].EntityID == entityID {
+			found = &groups[i]
+		}
+	}
+	if found == nil {
+		t.Fatal("expected a group for the logged entity")
+	}
+	if found.Count != 2 || found.KeysAffected != 3 {
+		t.Errorf("group totals: got count=%d keys=%d, want 2 and 3", found.Count, found.KeysAffected)
+	}
+	if found.LastAction != "purge" {
+		t.Errorf("LastAction: got %q, want %q", found.LastAction, "purge")
+	}
+	if found.LastUserName == "" {
+		t.Error("expected the actor's display name on the latest entry")
+	}
+}
+
+func TestCacheLogStorePrune(t *testing.T) {
+	db := testDB(t)
+	s := NewCacheLogStore(db)
+
+	oldID := uuid.New()
+	newID := uuid.New()
+	s.Log("content", oldID, "update", nil, 1)
+	s.Log("content", newID, "update", nil, 1)
+	db.Exec("UPDATE cache_invalidation_log SET invalidated_at = NOW() - INTERVAL '40 days' WHERE entity_id = $1", oldID)
+
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM cache_invalidation_log WHERE entity_id IN ($1, $2)", oldID, newID)
+	})
+
+	n, err := s.Prune(context.Background(), 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if n < 1 {
+		t.Errorf("expected at least 1 pruned row, got %d", n)
+	}
+
+	var remaining int
+	db.QueryRow("SELECT COUNT(*) FROM cache_invalidation_log WHERE entity_id IN ($1, $2)", oldID, newID).Scan(&remaining)
+	if remaining != 1 {
+		t.Errorf("expected only the recent entry to remain, got %d rows", remaining)
+	}
+}
@@ -68,6 +68,42 @@ func (s *ContentStore) ListByType(contentType models.ContentType) ([]models.Cont
 	return items, rows.Err()
 }
 
+// ContentFilter selects content for bulk operations and search.
+// Zero-valued fields don't filter.
+type ContentFilter struct {
+	Type            models.ContentType
+	Status          models.ContentStatus
+	CategoryID      *uuid.UUID
+	DefaultLanguage bool // Leave out translations
+}
+
+// ListFiltered returns the content items matching f, ordered by title.
+func (s *ContentStore) ListFiltered(f ContentFilter) ([]models.Content, error) {
+	rows, err := s.db.Query(`
+		SELECT `+contentColumns+`
+		FROM content
+		WHERE ($1 = '' OR type = $1)
+		  AND ($2 = '' OR status = $2)
+		  AND ($3::uuid IS NULL OR category_id = $3)
+		  AND (NOT $4 OR id NOT IN (SELECT translation_id FROM content_translations))
+		ORDER BY title
+	`, string(f.Type), string(f.Status), f.CategoryID, f.DefaultLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("list filtered content: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.Content
+	for rows.Next() {
+		c, err := scanContent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan content: %w", err)
+		}
+		items = append(items, *c)
+	}
+	return items, rows.Err()
+}
+
 // FindByID retrieves a content item by its UUID. Returns nil if not found.
 func (s *ContentStore) FindByID(id uuid.UUID) (*models.Content, error) {
 	row := s.db.QueryRow(`SELECT `+contentColumns+` FROM content WHERE id = $1`, id)
@@ -97,6 +133,17 @@ func (s *ContentStore) FindBySlug(slug string) (*models.Content, error) {
 	return c, nil
 }
 
+// SlugExists reports whether any content item, published or not, has the
+// given slug.
+func (s *ContentStore) SlugExists(slug string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM content WHERE slug = $1)`, slug).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check content slug: %w", err)
+	}
+	return exists, nil
+}
+
 // Create inserts a new content item and returns it with the generated ID.
 func (s *ContentStore) Create(c *models.Content) (*models.Content, error) {
 	// If publishing, set the published_at timestamp.
@@ -156,13 +203,15 @@ func (s *ContentStore) Delete(id uuid.UUID) error {
 	return nil
 }
 
-// ListPublishedByType returns all published content of the given type,
-// ordered by published date descending. Used for public page rendering.
+// ListPublishedByType returns all published content of the given type in
+// the site's default language (translations are left out), ordered by
+// published date descending. Used for public page rendering.
 func (s *ContentStore) ListPublishedByType(contentType models.ContentType) ([]models.Content, error) {
 	rows, err := s.db.Query(`
 		SELECT `+contentColumns+`
 		FROM content
 		WHERE type = $1 AND status = 'published'
+		  AND id NOT IN (SELECT translation_id FROM content_translations)
 		ORDER BY published_at DESC NULLS LAST
 	`, contentType)
 	if err != nil {
@@ -181,6 +230,164 @@ func (s *ContentStore) ListPublishedByType(contentType models.ContentType) ([]mo
 	return items, rows.Err()
 }
 
+// ListUsingMedia returns the published content that shows a media item,
+// either as its featured image or in its body (matched by storage key).
+func (s *ContentStore) ListUsingMedia(mediaID uuid.UUID, s3Key string) ([]models.Content, error) {
+	rows, err := s.db.Query(`
+		SELECT `+contentColumns+`
+		FROM content
+		WHERE status = 'published'
+		  AND (featured_image_id = $1 OR strpos(body, $2) > 0)
+	`, mediaID, s3Key)
+	if err != nil {
+		return nil, fmt.Errorf("list content using media: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.Content
+	for rows.Next() {
+		c, err := scanContent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan content: %w", err)
+		}
+		items = append(items, *c)
+	}
+	return items, rows.Err()
+}
+
+// ListByIDs returns the published content with the given IDs, keyed by ID.
+// Used to resolve search hits and related posts in one query.
+func (s *ContentStore) ListByIDs(ids []uuid.UUID) (map[uuid.UUID]*models.Content, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := ""
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		if i > 0 {
+			placeholders += ", "
+		}
+		placeholders += fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+contentColumns+`
+		FROM content
+		WHERE status = 'published' AND id IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list content by ids: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]*models.Content)
+	for rows.Next() {
+		c, err := scanContent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan content: %w", err)
+		}
+		result[c.ID] = c
+	}
+	return result, rows.Err()
+}
+
+// Page selects a page of results; Number starts at 1.
+type Page struct {
+	Number int
+	Size   int
+}
+
+// offset returns the number of rows before the page.
+func (p Page) offset() int {
+	if p.Number < 1 {
+		return 0
+	}
+	return (p.Number - 1) * p.Size
+}
+
+// SearchHit is a content item matching a search.
+type SearchHit struct {
+	Content models.Content
+	Rank    float64
+	// Snippet is an excerpt of the text with matches wrapped in <mark>.
+	// Apart from the marks it is plain text without < or &.
+	Snippet string
+}
+
+// SearchResults is one page of search hits and the total number of hits.
+type SearchResults struct {
+	Hits  []SearchHit
+	Total int
+}
+
+// searchHeadline configures ts_headline snippets.
+const searchHeadline = `StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=12, MaxFragments=2, FragmentDelimiter=" … "`
+
+// searchQuery binds the parsed query as q.query; $1 is the query text.
+const searchQuery = `WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query)`
+
+// searchWhere selects the hits of a search: $1 is the query text and $2
+// to $5 the ContentFilter fields.
+const searchWhere = `
+	WHERE ($1 = '' OR search_vector @@ q.query)
+	  AND ($2 = '' OR type = $2)
+	  AND ($3 = '' OR status = $3)
+	  AND ($4::uuid IS NULL OR category_id = $4)
+	  AND (NOT $5 OR id NOT IN (SELECT translation_id FROM content_translations))`
+
+// Search returns the content matching a web-style query ("quoted
+// phrases", OR, -excluded) and f, best match first. An empty query
+// matches everything, newest first, without snippets.
+func (s *ContentStore) Search(query string, f ContentFilter, page Page) (SearchResults, error) {
+	rows, err := s.db.Query(`
+		`+searchQuery+`
+		SELECT `+contentColumns+`,
+		       CASE WHEN $1 = '' THEN 0 ELSE ts_rank(search_vector, q.query) END AS rank,
+		       CASE WHEN $1 = '' THEN '' ELSE ts_headline('english',
+		           content_plain_text(coalesce(excerpt, '') || ' ' || body), q.query, '`+searchHeadline+`') END AS snippet,
+		       COUNT(*) OVER () AS total
+		FROM content, q`+searchWhere+`
+		ORDER BY rank DESC, coalesce(published_at, created_at) DESC
+		LIMIT $6 OFFSET $7
+	`, query, string(f.Type), string(f.Status), f.CategoryID, f.DefaultLanguage, page.Size, page.offset())
+	if err != nil {
+		return SearchResults{}, fmt.Errorf("search content: %w", err)
+	}
+	defer rows.Close()
+
+	var res SearchResults
+	for rows.Next() {
+		var h SearchHit
+		c := &h.Content
+		err := rows.Scan(
+			&c.ID, &c.Type, &c.Title, &c.Slug, &c.Body, &c.BodyFormat,
+			&c.Excerpt, &c.Status, &c.MetaDescription, &c.MetaKeywords,
+			&c.FeaturedImageID, &c.CategoryID, &c.AuthorID, &c.PublishedAt, &c.CreatedAt, &c.UpdatedAt,
+			&h.Rank, &h.Snippet, &res.Total,
+		)
+		if err != nil {
+			return SearchResults{}, fmt.Errorf("scan search hit: %w", err)
+		}
+		res.Hits = append(res.Hits, h)
+	}
+	if err := rows.Err(); err != nil {
+		return SearchResults{}, err
+	}
+
+	// Past the last page there are no rows to carry the total.
+	if len(res.Hits) == 0 && page.offset() > 0 {
+		if err := s.db.QueryRow(`
+			`+searchQuery+`
+			SELECT COUNT(*) FROM content, q`+searchWhere,
+			query, string(f.Type), string(f.Status), f.CategoryID, f.DefaultLanguage).Scan(&res.Total); err != nil {
+			return SearchResults{}, fmt.Errorf("count search hits: %w", err)
+		}
+	}
+	return res, nil
+}
+
 // CountByType returns the number of content items of the given type.
 func (s *ContentStore) CountByType(contentType models.ContentType) (int, error) {
 	var count int
@@ -6,6 +6,7 @@ package store
 
 import (
 	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -274,3 +275,77 @@ func TestContentStoreListPublishedByType(t *testing.T) {
 		t.Error("expected published post in list")
 	}
 }
+
+func TestContentStoreSearch(t *testing.T) {
+	db := testDB(t)
+	s := NewContentStore(db)
+	authorID := testAuthorID(t, db)
+
+	word := "zq" + uuid.NewString()[:8]
+	slugTitle := "test-search-title-" + uuid.NewString()[:8]
+	slugBody := "test-search-body-" + uuid.NewString()[:8]
+	slugDraft := "test-search-draft-" + uuid.NewString()[:8]
+	t.Cleanup(func() { cleanContent(t, db, slugTitle, slugBody, slugDraft) })
+
+	for _, c := range []*models.Content{
+		{Title: "About " + word, Slug: slugTitle, Body: "Nothing else here.", Status: models.ContentStatusPublished},
+		{Title: "Notes", Slug: slugBody, Body: "Some **bold** text about " + word + " and <script>x</script>.", Status: models.ContentStatusPublished},
+		{Title: "Draft " + word, Slug: slugDraft, Body: "draft", Status: models.ContentStatusDraft},
+	} {
+		c.Type = models.ContentTypePost
+		c.AuthorID = authorID
+		if _, err := s.Create(c); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	res, err := s.Search(word, ContentFilter{Status: models.ContentStatusPublished}, Page{Number: 1, Size: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if res.Total != 2 || len(res.Hits) != 2 {
+		t.Fatalf("Search: got %d hits of %d, want 2", len(res.Hits), res.Total)
+	}
+	// The title match outranks the body match.
+	if res.Hits[0].Content.Slug != slugTitle {
+		t.Errorf("first hit: got %q, want %q", res.Hits[0].Content.Slug, slugTitle)
+	}
+	snippet := res.Hits[1].Snippet
+	if !strings.Contains(snippet, "<mark>"+word+"</mark>") {
+		t.Errorf("snippet without mark: %q", snippet)
+	}
+	if strings.Contains(snippet, "**") || strings.Contains(snippet, "<script") {
+		t.Errorf("snippet keeps markup: %q", snippet)
+	}
+
+	// Pages past the first still report the total.
+	res, err = s.Search(word, ContentFilter{}, Page{Number: 2, Size: 2})
+	if err != nil {
+		t.Fatalf("Search page 2: %v", err)
+	}
+	if res.Total != 3 || len(res.Hits) != 1 {
+		t.Errorf("page 2: got %d hits of %d, want 1 of 3", len(res.Hits), res.Total)
+	}
+	res, err = s.Search(word, ContentFilter{}, Page{Number: 5, Size: 2})
+	if err != nil {
+		t.Fatalf("Search page 5: %v", err)
+	}
+	if res.Total != 3 || len(res.Hits) != 0 {
+		t.Errorf("page 5: got %d hits of %d, want 0 of 3", len(res.Hits), res.Total)
+	}
+
+	// An empty query lists everything matching the filters.
+	res, err = s.Search("", ContentFilter{Type: models.ContentTypePost, Status: models.ContentStatusDraft}, Page{Number: 1, Size: 1000})
+	if err != nil {
+		t.Fatalf("Search empty: %v", err)
+	}
+	found := false
+	for _, h := range res.Hits {
+		if h.Content.Slug == slugDraft {
+			found = h.Snippet == "" && h.Rank == 0
+		}
+	}
+	if !found {
+		t.Error("empty query: draft missing or ranked")
+	}
+}
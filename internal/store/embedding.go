@@ -0,0 +1,275 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// embedding.go stores content embeddings and finds the content nearest to
+// a vector. Similarity is computed by pgvector when the extension is
+// installed, otherwise by scanning the vectors in Go.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// EmbeddingStore handles content embedding persistence.
+type EmbeddingStore struct {
+	db *sql.DB
+
+	vectorOnce sync.Once
+	hasVector  bool // pgvector is installed; checked on first use
+}
+
+// NewEmbeddingStore creates a new EmbeddingStore.
+func NewEmbeddingStore(db *sql.DB) *EmbeddingStore {
+	return &EmbeddingStore{db: db}
+}
+
+// Neighbor is a content item and its cosine similarity to a query vector.
+type Neighbor struct {
+	ContentID uuid.UUID
+	Score     float64
+}
+
+// NeighborFilter narrows the content Nearest considers. Only published
+// content in the default language is ever returned.
+type NeighborFilter struct {
+	Type    models.ContentType // Empty for any type
+	Exclude uuid.UUID          // Content to leave out, e.g. the page itself
+}
+
+// HasVector reports whether similarity is computed by pgvector.
+func (s *EmbeddingStore) HasVector() bool {
+	s.vectorOnce.Do(func() {
+		err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector')`).Scan(&s.hasVector)
+		if err != nil {
+			s.hasVector = false
+		}
+	})
+	return s.hasVector
+}
+
+// SourceHash returns the model and source hash of a content item's
+// embedding, or empty strings if it has none.
+func (s *EmbeddingStore) SourceHash(contentID uuid.UUID) (model, hash string, err error) {
+	err = s.db.QueryRow(`
+		SELECT model, source_hash FROM content_embeddings WHERE content_id = $1`, contentID).Scan(&model, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("find embedding hash: %w", err)
+	}
+	return model, hash, nil
+}
+
+// Upsert stores the embedding of a content item, replacing any previous one.
+func (s *EmbeddingStore) Upsert(ctx context.Context, contentID uuid.UUID, model string, vec []float32, hash string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO content_embeddings (content_id, model, dims, embedding, source_hash)
+		VALUES ($1, $2, $3, $4::real[], $5)
+		ON CONFLICT (content_id) DO UPDATE
+		SET model = EXCLUDED.model, dims = EXCLUDED.dims, embedding = EXCLUDED.embedding,
+		    source_hash = EXCLUDED.source_hash, updated_at = NOW()`,
+		contentID, model, len(vec), formatVector(vec), hash)
+	if err != nil {
+		return fmt.Errorf("upsert embedding: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the embedding of a content item, e.g. when it is
+// unpublished.
+func (s *EmbeddingStore) Delete(ctx context.Context, contentID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM content_embeddings WHERE content_id = $1`, contentID)
+	if err != nil {
+		return fmt.Errorf("delete embedding: %w", err)
+	}
+	return nil
+}
+
+// Find returns a content item's embedding made with model, or nil.
+func (s *EmbeddingStore) Find(contentID uuid.UUID, model string) ([]float32, error) {
+	var text string
+	err := s.db.QueryRow(`
+		SELECT embedding::text FROM content_embeddings
+		WHERE content_id = $1 AND model = $2`, contentID, model).Scan(&text)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find embedding: %w", err)
+	}
+	return parseVector(text)
+}
+
+// ListMissing returns published content in the default language that has
+// no embedding made with model, up to limit.
+func (s *EmbeddingStore) ListMissing(model string, limit int) ([]uuid.UUID, error) {
+	rows, err := s.db.Query(`
+		SELECT c.id FROM content c
+		LEFT JOIN content_embeddings e ON e.content_id = c.id AND e.model = $1
+		WHERE c.status = 'published' AND e.content_id IS NULL
+		  AND c.id NOT IN (SELECT translation_id FROM content_translations)
+		ORDER BY c.published_at DESC NULLS LAST
+		LIMIT $2`, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list content missing embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan content id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// neighborWhere restricts neighbor queries to comparable vectors of
+// published content in the default language. $1 is the model, $2 the
+// dimensions, $3 the type filter and $4 the excluded ID.
+const neighborWhere = `
+	WHERE e.model = $1 AND e.dims = $2 AND c.status = 'published'
+	  AND ($3 = '' OR c.type = $3) AND c.id <> $4
+	  AND c.id NOT IN (SELECT translation_id FROM content_translations)`
+
+// Nearest returns up to limit content items most similar to vec among
+// those embedded with model, best first.
+func (s *EmbeddingStore) Nearest(ctx context.Context, model string, vec []float32, f NeighborFilter, limit int) ([]Neighbor, error) {
+	if len(vec) == 0 || limit <= 0 {
+		return nil, nil
+	}
+	if s.HasVector() {
+		return s.nearestVector(ctx, model, vec, f, limit)
+	}
+	return s.nearestScan(ctx, model, vec, f, limit)
+}
+
+// nearestVector ranks by pgvector's cosine distance. The column is cast
+// per row, so no vector index is used; this still avoids sending every
+// vector to the application.
+func (s *EmbeddingStore) nearestVector(ctx context.Context, model string, vec []float32, f NeighborFilter, limit int) ([]Neighbor, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.content_id, 1 - (e.embedding::vector <=> $5::real[]::vector) AS score
+		FROM content_embeddings e
+		JOIN content c ON c.id = e.content_id`+neighborWhere+`
+		ORDER BY score DESC
+		LIMIT $6`,
+		model, len(vec), string(f.Type), f.Exclude, formatVector(vec), limit)
+	if err != nil {
+		return nil, fmt.Errorf("find nearest content: %w", err)
+	}
+	defer rows.Close()
+
+	var list []Neighbor
+	for rows.Next() {
+		var n Neighbor
+		if err := rows.Scan(&n.ContentID, &n.Score); err != nil {
+			return nil, fmt.Errorf("scan neighbor: %w", err)
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+// nearestScan loads the comparable vectors and ranks them in Go.
+func (s *EmbeddingStore) nearestScan(ctx context.Context, model string, vec []float32, f NeighborFilter, limit int) ([]Neighbor, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.content_id, e.embedding::text
+		FROM content_embeddings e
+		JOIN content c ON c.id = e.content_id`+neighborWhere,
+		model, len(vec), string(f.Type), f.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("find nearest content: %w", err)
+	}
+	defer rows.Close()
+
+	var list []Neighbor
+	for rows.Next() {
+		var id uuid.UUID
+		var text string
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, fmt.Errorf("scan embedding: %w", err)
+		}
+		other, err := parseVector(text)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, Neighbor{ContentID: id, Score: Cosine(vec, other)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Score > list[j].Score })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// Cosine returns the cosine similarity of two vectors of equal length,
+// or 0 if either is zero.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// formatVector encodes a vector as a Postgres array literal.
+func formatVector(vec []float32) string {
+	var sb strings.Builder
+	sb.Grow(len(vec) * 10)
+	sb.WriteByte('{')
+	for i, x := range vec {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// parseVector decodes a Postgres array literal written by formatVector.
+func parseVector(text string) ([]float32, error) {
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "{"), "}")
+	if text == "" {
+		return nil, nil
+	}
+	parts := strings.Split(text, ",")
+	vec := make([]float32, len(parts))
+	for i, p := range parts {
+		x, err := strconv.ParseFloat(p, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse embedding: %w", err)
+		}
+		vec[i] = float32(x)
+	}
+	return vec, nil
+}
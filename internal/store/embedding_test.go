@@ -0,0 +1,139 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+func TestVectorLiteral(t *testing.T) {
+	vec := []float32{0.25, -1, 3.5e-7, 0}
+	text := formatVector(vec)
+	if text != "{0.25,-1,3.5e-07,0}" {
+		t.Errorf("formatVector: got %q", text)
+	}
+	got, err := parseVector(text)
+	if err != nil {
+		t.Fatalf("parseVector: %v", err)
+	}
+	for i := range vec {
+		if got[i] != vec[i] {
+			t.Fatalf("round trip: got %v, want %v", got, vec)
+		}
+	}
+	if v, err := parseVector("{}"); err != nil || v != nil {
+		t.Errorf("empty: got %v, %v", v, err)
+	}
+	if _, err := parseVector("{1,x}"); err == nil {
+		t.Error("invalid literal accepted")
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		a, b []float32
+		want float64
+	}{
+		{[]float32{1, 0}, []float32{2, 0}, 1},
+		{[]float32{1, 0}, []float32{0, 1}, 0},
+		{[]float32{1, 0}, []float32{-1, 0}, -1},
+		{[]float32{0, 0}, []float32{1, 0}, 0},
+		{[]float32{1, 0}, []float32{1, 0, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := Cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestEmbeddingStore(t *testing.T) {
+	db := testDB(t)
+	s := NewEmbeddingStore(db)
+	cs := NewContentStore(db)
+	authorID := testAuthorID(t, db)
+	ctx := context.Background()
+
+	prefix := "test-emb-" + uuid.NewString()[:8]
+	slugs := []string{prefix + "-a", prefix + "-b", prefix + "-c", prefix + "-draft"}
+	t.Cleanup(func() { cleanContent(t, db, slugs...) })
+
+	var items []*models.Content
+	for i, slug := range slugs {
+		status := models.ContentStatusPublished
+		if i == 3 {
+			status = models.ContentStatusDraft
+		}
+		c, err := cs.Create(&models.Content{
+			Type: models.ContentTypePost, Title: slug, Slug: slug, Body: "body",
+			Status: status, AuthorID: authorID,
+		})
+		if err != nil {
+			t.Fatalf("Create content: %v", err)
+		}
+		items = append(items, c)
+	}
+
+	// A unique model keeps other tests' vectors out of the results.
+	model := "test/" + prefix
+	vectors := [][]float32{{1, 0, 0}, {0.9, 0.1, 0}, {0, 0, 1}, {1, 0, 0}}
+	for i, c := range items {
+		if err := s.Upsert(ctx, c.ID, model, vectors[i], "h1"); err != nil {
+			t.Fatalf("Upsert: %v", err)
+		}
+	}
+	if err := s.Upsert(ctx, items[2].ID, model, []float32{0, 1, 0}, "h2"); err != nil {
+		t.Fatalf("Upsert again: %v", err)
+	}
+
+	if m, h, err := s.SourceHash(items[2].ID); err != nil || m != model || h != "h2" {
+		t.Errorf("SourceHash: %q, %q, %v", m, h, err)
+	}
+	if v, err := s.Find(items[1].ID, model); err != nil || len(v) != 3 || v[0] != 0.9 {
+		t.Errorf("Find: %v, %v", v, err)
+	}
+	if v, err := s.Find(items[1].ID, "other"); err != nil || v != nil {
+		t.Errorf("Find other model: %v, %v", v, err)
+	}
+
+	got, err := s.Nearest(ctx, model, []float32{1, 0, 0}, NeighborFilter{Exclude: items[0].ID}, 5)
+	if err != nil {
+		t.Fatalf("Nearest: %v", err)
+	}
+	// Drafts and the excluded item are left out; b is closer than c.
+	if len(got) != 2 || got[0].ContentID != items[1].ID || got[1].ContentID != items[2].ID {
+		t.Fatalf("Nearest: got %+v", got)
+	}
+	if got[0].Score < 0.99 || math.Abs(got[1].Score) > 1e-6 {
+		t.Errorf("Nearest scores: got %+v", got)
+	}
+
+	if err := s.Delete(ctx, items[1].ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if m, _, _ := s.SourceHash(items[1].ID); m != "" {
+		t.Error("embedding still stored after Delete")
+	}
+	missing, err := s.ListMissing(model, 1000)
+	if err != nil {
+		t.Fatalf("ListMissing: %v", err)
+	}
+	found := false
+	for _, id := range missing {
+		if id == items[3].ID {
+			t.Error("ListMissing returned a draft")
+		}
+		found = found || id == items[1].ID
+	}
+	if !found {
+		t.Error("ListMissing left out content without an embedding")
+	}
+}
@@ -6,6 +6,7 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -25,38 +26,53 @@ func NewMediaStore(db *sql.DB) *MediaStore {
 
 // mediaColumns lists the columns selected in media queries.
 const mediaColumns = `id, filename, original_name, content_type, size_bytes,
-	bucket, s3_key, thumb_s3_key, alt_text, uploader_id, created_at`
+	bucket, s3_key, thumb_s3_key, alt_text, caption, generation, uploader_id, created_at`
 
 // scanMedia scans a media row from the result set.
 func scanMedia(scanner interface{ Scan(...any) error }) (*models.Media, error) {
 	var m models.Media
+	var generation *string
 	err := scanner.Scan(
 		&m.ID, &m.Filename, &m.OriginalName, &m.ContentType, &m.SizeBytes,
-		&m.Bucket, &m.S3Key, &m.ThumbS3Key, &m.AltText, &m.UploaderID, &m.CreatedAt,
+		&m.Bucket, &m.S3Key, &m.ThumbS3Key, &m.AltText, &m.Caption, &generation, &m.UploaderID, &m.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if generation != nil {
+		m.Generation = &models.ImageGeneration{}
+		if err := json.Unmarshal([]byte(*generation), m.Generation); err != nil {
+			return nil, fmt.Errorf("decode media generation: %w", err)
+		}
+	}
 	return &m, nil
 }
 
 // Create inserts a new media record and returns it with the generated ID.
 func (s *MediaStore) Create(m *models.Media) (*models.Media, error) {
-	err := s.db.QueryRow(`
+	var generation *string
+	if m.Generation != nil {
+		b, err := json.Marshal(m.Generation)
+		if err != nil {
+			return nil, fmt.Errorf("encode media generation: %w", err)
+		}
+		generation = new(string)
+		*generation = string(b)
+	}
+
+	row := s.db.QueryRow(`
 		INSERT INTO media (filename, original_name, content_type, size_bytes,
-			bucket, s3_key, thumb_s3_key, alt_text, uploader_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			bucket, s3_key, thumb_s3_key, alt_text, caption, generation, uploader_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING `+mediaColumns,
 		m.Filename, m.OriginalName, m.ContentType, m.SizeBytes,
-		m.Bucket, m.S3Key, m.ThumbS3Key, m.AltText, m.UploaderID,
-	).Scan(
-		&m.ID, &m.Filename, &m.OriginalName, &m.ContentType, &m.SizeBytes,
-		&m.Bucket, &m.S3Key, &m.ThumbS3Key, &m.AltText, &m.UploaderID, &m.CreatedAt,
+		m.Bucket, m.S3Key, m.ThumbS3Key, m.AltText, m.Caption, generation, m.UploaderID,
 	)
+	created, err := scanMedia(row)
 	if err != nil {
 		return nil, fmt.Errorf("create media: %w", err)
 	}
-	return m, nil
+	return created, nil
 }
 
 // FindByID retrieves a single media record by its UUID.
@@ -122,6 +138,18 @@ func (s *MediaStore) UpdateThumbKey(id uuid.UUID, thumbKey *string) error {
 	return nil
 }
 
+// UpdateDescription sets the alt text and caption of a media item. Empty
+// strings clear them.
+func (s *MediaStore) UpdateDescription(id uuid.UUID, altText, caption string) error {
+	_, err := s.db.Exec(`
+		UPDATE media SET alt_text = NULLIF($2, ''), caption = NULLIF($3, '')
+		WHERE id = $1`, id, altText, caption)
+	if err != nil {
+		return fmt.Errorf("update media description: %w", err)
+	}
+	return nil
+}
+
 // FindByS3Keys returns media items matching the given S3 keys, keyed by s3_key.
 // Used for batch-resolving inline content images to their responsive variants.
 func (s *MediaStore) FindByS3Keys(keys []string) (map[string]*models.Media, error) {
@@ -0,0 +1,203 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// media_description.go stores AI-suggested alt text and captions. A
+// description moves from queued to proposed when its job finishes, and
+// from proposed to accepted or rejected when an editor reviews it.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// MediaDescriptionStore handles AI image description persistence.
+type MediaDescriptionStore struct {
+	db *sql.DB
+}
+
+// NewMediaDescriptionStore creates a new MediaDescriptionStore.
+func NewMediaDescriptionStore(db *sql.DB) *MediaDescriptionStore {
+	return &MediaDescriptionStore{db: db}
+}
+
+// describableTypes is the SQL list of image types a vision model accepts.
+const describableTypes = `('image/jpeg', 'image/png', 'image/webp', 'image/gif')`
+
+// descriptionSelect selects descriptions with their media's name, storage
+// keys and current alt text. Callers append WHERE/ORDER BY.
+const descriptionSelect = `
+	SELECT d.media_id, d.job_id, d.status, d.alt_text, d.caption, d.error,
+	       d.reviewed_by, d.created_at, d.updated_at,
+	       m.original_name, m.bucket, m.s3_key, m.thumb_s3_key, m.alt_text
+	FROM media_descriptions d
+	JOIN media m ON m.id = d.media_id`
+
+// scanDescription scans a row selected with descriptionSelect.
+func scanDescription(scanner interface{ Scan(...any) error }) (*models.MediaDescription, error) {
+	var d models.MediaDescription
+	err := scanner.Scan(
+		&d.MediaID, &d.JobID, &d.Status, &d.AltText, &d.Caption, &d.Error,
+		&d.ReviewedBy, &d.CreatedAt, &d.UpdatedAt,
+		&d.OriginalName, &d.Bucket, &d.S3Key, &d.ThumbS3Key, &d.MediaAlt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Queue creates or resets the description of a media item to queued.
+// Returns false if it is already queued, so the caller doesn't queue a
+// second job.
+func (s *MediaDescriptionStore) Queue(mediaID uuid.UUID) (bool, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO media_descriptions (media_id) VALUES ($1)
+		ON CONFLICT (media_id) DO UPDATE
+		SET status = 'queued', job_id = NULL, alt_text = '', caption = '', error = '',
+		    reviewed_by = NULL, updated_at = NOW()
+		WHERE media_descriptions.status <> 'queued'`, mediaID)
+	if err != nil {
+		return false, fmt.Errorf("queue media description: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SetJob records the job that generates a description.
+func (s *MediaDescriptionStore) SetJob(mediaID, jobID uuid.UUID) error {
+	_, err := s.db.Exec(`
+		UPDATE media_descriptions SET job_id = $2, updated_at = NOW()
+		WHERE media_id = $1`, mediaID, jobID)
+	if err != nil {
+		return fmt.Errorf("set media description job: %w", err)
+	}
+	return nil
+}
+
+// Find returns the description of a media item, or nil if there is none.
+func (s *MediaDescriptionStore) Find(mediaID uuid.UUID) (*models.MediaDescription, error) {
+	d, err := scanDescription(s.db.QueryRow(descriptionSelect+`
+		WHERE d.media_id = $1`, mediaID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find media description: %w", err)
+	}
+	return d, nil
+}
+
+// Propose stores the suggestion of a queued description for review.
+func (s *MediaDescriptionStore) Propose(ctx context.Context, mediaID uuid.UUID, altText, caption string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE media_descriptions
+		SET status = 'proposed', alt_text = $2, caption = $3, error = '', updated_at = NOW()
+		WHERE media_id = $1 AND status = 'queued'`, mediaID, altText, caption)
+	if err != nil {
+		return fmt.Errorf("propose media description: %w", err)
+	}
+	return nil
+}
+
+// Fail marks a description as failed with the reason.
+func (s *MediaDescriptionStore) Fail(ctx context.Context, mediaID uuid.UUID, errMsg string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE media_descriptions SET status = 'failed', error = $2, updated_at = NOW()
+		WHERE media_id = $1`, mediaID, errMsg)
+	if err != nil {
+		return fmt.Errorf("fail media description: %w", err)
+	}
+	return nil
+}
+
+// Decide moves a proposed description to accepted or rejected, storing
+// the text that was accepted. Returns false if it was not proposed, e.g.
+// because it was already reviewed.
+func (s *MediaDescriptionStore) Decide(mediaID uuid.UUID, status models.DescriptionStatus, altText, caption string, reviewer uuid.UUID) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE media_descriptions
+		SET status = $2, alt_text = $3, caption = $4, reviewed_by = $5, updated_at = NOW()
+		WHERE media_id = $1 AND status = 'proposed'`, mediaID, status, altText, caption, reviewer)
+	if err != nil {
+		return false, fmt.Errorf("decide media description: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListByStatus returns descriptions in a state, oldest first, up to limit.
+func (s *MediaDescriptionStore) ListByStatus(status models.DescriptionStatus, limit int) ([]models.MediaDescription, error) {
+	rows, err := s.db.Query(descriptionSelect+`
+		WHERE d.status = $1
+		ORDER BY d.updated_at, d.media_id
+		LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list media descriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var list []models.MediaDescription
+	for rows.Next() {
+		d, err := scanDescription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan media description: %w", err)
+		}
+		list = append(list, *d)
+	}
+	return list, rows.Err()
+}
+
+// Counts returns the number of descriptions per status.
+func (s *MediaDescriptionStore) Counts() (map[models.DescriptionStatus]int, error) {
+	rows, err := s.db.Query(`SELECT status, COUNT(*) FROM media_descriptions GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("count media descriptions: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[models.DescriptionStatus]int{}
+	for rows.Next() {
+		var st models.DescriptionStatus
+		var n int
+		if err := rows.Scan(&st, &n); err != nil {
+			return nil, fmt.Errorf("scan media description count: %w", err)
+		}
+		counts[st] = n
+	}
+	return counts, rows.Err()
+}
+
+// ListMissingAlt returns the IDs of images without alt text that have no
+// description queued or waiting for review, newest first, up to limit.
+func (s *MediaDescriptionStore) ListMissingAlt(limit int) ([]uuid.UUID, error) {
+	rows, err := s.db.Query(`
+		SELECT m.id FROM media m
+		LEFT JOIN media_descriptions d ON d.media_id = m.id
+		WHERE m.content_type IN `+describableTypes+`
+		  AND COALESCE(m.alt_text, '') = ''
+		  AND (d.status IS NULL OR d.status NOT IN ('queued', 'proposed'))
+		ORDER BY m.created_at DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list media missing alt text: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan media id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
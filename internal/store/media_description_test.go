@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+func TestMediaDescriptionStore(t *testing.T) {
+	db := testDB(t)
+	s := NewMediaDescriptionStore(db)
+	ms := NewMediaStore(db)
+	ctx := context.Background()
+	uploaderID := testAuthorID(t, db)
+
+	s3Key := "media/test/describe-" + uuid.NewString()[:8] + ".jpg"
+	t.Cleanup(func() { cleanMediaByKey(t, db, s3Key) })
+
+	m, err := ms.Create(&models.Media{
+		Filename: "d.jpg", OriginalName: "beach.jpg", ContentType: "image/jpeg",
+		SizeBytes: 1024, Bucket: "public", S3Key: s3Key, UploaderID: uploaderID,
+	})
+	if err != nil {
+		t.Fatalf("Create media: %v", err)
+	}
+
+	missing := func() bool {
+		ids, err := s.ListMissingAlt(1000)
+		if err != nil {
+			t.Fatalf("ListMissingAlt: %v", err)
+		}
+		for _, id := range ids {
+			if id == m.ID {
+				return true
+			}
+		}
+		return false
+	}
+	if !missing() {
+		t.Error("ListMissingAlt left out an image without alt text")
+	}
+
+	if ok, err := s.Queue(m.ID); err != nil || !ok {
+		t.Fatalf("Queue: %v, %v", ok, err)
+	}
+	if ok, _ := s.Queue(m.ID); ok {
+		t.Error("Queue queued an already queued description")
+	}
+	if missing() {
+		t.Error("ListMissingAlt returned an image with a queued description")
+	}
+
+	// Only a proposed description can be decided.
+	if ok, _ := s.Decide(m.ID, models.DescriptionAccepted, "x", "", uploaderID); ok {
+		t.Error("Decide accepted a queued description")
+	}
+	if err := s.Propose(ctx, m.ID, "A sandy beach at sunset", "Low tide."); err != nil {
+		t.Fatalf("Propose: %v", err)
+	}
+	d, err := s.Find(m.ID)
+	if err != nil || d == nil {
+		t.Fatalf("Find: %v, %v", d, err)
+	}
+	if d.Status != models.DescriptionProposed || d.AltText != "A sandy beach at sunset" || d.OriginalName != "beach.jpg" {
+		t.Errorf("Find: got %+v", d)
+	}
+	counts, err := s.Counts()
+	if err != nil || counts[models.DescriptionProposed] < 1 {
+		t.Errorf("Counts: %v, %v", counts, err)
+	}
+
+	if ok, err := s.Decide(m.ID, models.DescriptionAccepted, "A beach", "Low tide.", uploaderID); err != nil || !ok {
+		t.Fatalf("Decide: %v, %v", ok, err)
+	}
+	if ok, _ := s.Decide(m.ID, models.DescriptionRejected, "", "", uploaderID); ok {
+		t.Error("Decide changed an accepted description")
+	}
+
+	if err := ms.UpdateDescription(m.ID, "A beach", "Low tide."); err != nil {
+		t.Fatalf("UpdateDescription: %v", err)
+	}
+	got, _ := ms.FindByID(m.ID)
+	if got == nil || got.AltText == nil || *got.AltText != "A beach" || got.Caption == nil || *got.Caption != "Low tide." {
+		t.Errorf("UpdateDescription: got %+v", got)
+	}
+	if missing() {
+		t.Error("ListMissingAlt returned an image with alt text")
+	}
+
+	// A failed description can be queued again.
+	if err := s.Fail(ctx, m.ID, "boom"); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if ok, err := s.Queue(m.ID); err != nil || !ok {
+		t.Errorf("Queue after failure: %v, %v", ok, err)
+	}
+}
@@ -62,6 +62,10 @@ func TestMediaStoreCreateAndFind(t *testing.T) {
 		t.Errorf("s3_key: got %q, want %q", found.S3Key, s3Key)
 	}
 
+	if found.Generation != nil {
+		t.Errorf("generation of an upload: got %+v", found.Generation)
+	}
+
 	// Not found.
 	found, _ = s.FindByID(uuid.New())
 	if found != nil {
@@ -165,3 +169,39 @@ func TestMediaStoreCount(t *testing.T) {
 		t.Error("expected non-negative count")
 	}
 }
+
+func TestMediaStoreGeneration(t *testing.T) {
+	db := testDB(t)
+	s := NewMediaStore(db)
+
+	var uploaderID uuid.UUID
+	if err := db.QueryRow("SELECT id FROM users LIMIT 1").Scan(&uploaderID); err != nil {
+		t.Skip("no users in database")
+	}
+
+	s3Key := "media/test/" + uuid.NewString()[:8] + ".png"
+	t.Cleanup(func() { cleanMediaByKey(t, db, s3Key) })
+
+	sourceID := uuid.New()
+	created, err := s.Create(&models.Media{
+		Filename: "gen.png", OriginalName: "a-fox.png", ContentType: "image/png",
+		SizeBytes: 10, Bucket: "public", S3Key: s3Key, UploaderID: uploaderID,
+		Generation: &models.ImageGeneration{
+			Mode: models.GenerationEdit, Prompt: "add a hat", Provider: "openai",
+			SourceID: &sourceID, Aspect: "1:1", Style: "ink", Seed: 42,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	found, err := s.FindByID(created.ID)
+	if err != nil || found == nil {
+		t.Fatalf("FindByID: %v, %v", found, err)
+	}
+	g := found.Generation
+	if g == nil || g.Mode != models.GenerationEdit || g.Prompt != "add a hat" || g.Provider != "openai" ||
+		g.SourceID == nil || *g.SourceID != sourceID || g.Aspect != "1:1" || g.Seed != 42 {
+		t.Errorf("generation: got %+v", g)
+	}
+}
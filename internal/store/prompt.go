@@ -0,0 +1,149 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// prompt.go stores the versions of admin-editable AI system prompts.
+// Versions are only ever added: saving or rolling back a prompt creates
+// a new version, and the highest version of a task is the one in use.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// PromptStore handles prompt version persistence.
+type PromptStore struct {
+	db *sql.DB
+}
+
+// NewPromptStore creates a new PromptStore.
+func NewPromptStore(db *sql.DB) *PromptStore {
+	return &PromptStore{db: db}
+}
+
+// promptSelect selects prompt versions with their author's display name.
+// Callers append WHERE/ORDER BY.
+const promptSelect = `
+	SELECT p.id, p.task, p.version, p.body, p.note, p.created_by,
+	       COALESCE(u.display_name, ''), p.created_at
+	FROM prompts p
+	LEFT JOIN users u ON u.id = p.created_by`
+
+// scanPrompt scans a row selected with promptSelect.
+func scanPrompt(scanner interface{ Scan(...any) error }) (*models.Prompt, error) {
+	var p models.Prompt
+	err := scanner.Scan(&p.ID, &p.Task, &p.Version, &p.Body, &p.Note, &p.CreatedBy,
+		&p.CreatedByName, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Latest returns the version of a task's prompt in use, or nil if the
+// task has none.
+func (s *PromptStore) Latest(task string) (*models.Prompt, error) {
+	p, err := scanPrompt(s.db.QueryRow(promptSelect+`
+		WHERE p.task = $1
+		ORDER BY p.version DESC
+		LIMIT 1`, task))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find latest prompt: %w", err)
+	}
+	return p, nil
+}
+
+// FindVersion returns one version of a task's prompt, or nil if it
+// doesn't exist.
+func (s *PromptStore) FindVersion(task string, version int) (*models.Prompt, error) {
+	p, err := scanPrompt(s.db.QueryRow(promptSelect+`
+		WHERE p.task = $1 AND p.version = $2`, task, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find prompt version: %w", err)
+	}
+	return p, nil
+}
+
+// Versions returns every version of a task's prompt, newest first.
+func (s *PromptStore) Versions(task string) ([]models.Prompt, error) {
+	rows, err := s.db.Query(promptSelect+`
+		WHERE p.task = $1
+		ORDER BY p.version DESC`, task)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt versions: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.Prompt
+	for rows.Next() {
+		p, err := scanPrompt(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan prompt: %w", err)
+		}
+		items = append(items, *p)
+	}
+	return items, rows.Err()
+}
+
+// LatestVersions returns the version number in use for every task that
+// has a saved prompt.
+func (s *PromptStore) LatestVersions() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT task, MAX(version) FROM prompts GROUP BY task`)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt tasks: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[string]int)
+	for rows.Next() {
+		var task string
+		var v int
+		if err := rows.Scan(&task, &v); err != nil {
+			return nil, fmt.Errorf("scan prompt task: %w", err)
+		}
+		versions[task] = v
+	}
+	return versions, rows.Err()
+}
+
+// Create saves body as the next version of a task's prompt and returns it.
+func (s *PromptStore) Create(task, body, note string, createdBy *uuid.UUID) (*models.Prompt, error) {
+	var p models.Prompt
+	err := s.db.QueryRow(`
+		INSERT INTO prompts (task, version, body, note, created_by)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4
+		FROM prompts WHERE task = $1
+		RETURNING id, task, version, body, note, created_by, created_at`,
+		task, body, note, createdBy,
+	).Scan(&p.ID, &p.Task, &p.Version, &p.Body, &p.Note, &p.CreatedBy, &p.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("create prompt: %w", err)
+	}
+	return &p, nil
+}
+
+// SeedDefault saves body as version 1 of a task's prompt unless the task
+// already has one. Reports whether it was inserted.
+func (s *PromptStore) SeedDefault(task, body string) (bool, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO prompts (task, version, body, note)
+		VALUES ($1, 1, $2, 'Built-in default')
+		ON CONFLICT (task, version) DO NOTHING`, task, body)
+	if err != nil {
+		return false, fmt.Errorf("seed prompt: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
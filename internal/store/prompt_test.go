@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPromptStore(t *testing.T) {
+	db := testDB(t)
+	s := NewPromptStore(db)
+	authorID := testAuthorID(t, db)
+
+	task := "test_" + uuid.NewString()[:8]
+	t.Cleanup(func() { db.Exec("DELETE FROM prompts WHERE task = $1", task) })
+
+	if p, err := s.Latest(task); err != nil || p != nil {
+		t.Fatalf("Latest of an unknown task: %v, %v", p, err)
+	}
+
+	if added, err := s.SeedDefault(task, "default"); err != nil || !added {
+		t.Fatalf("SeedDefault: %v, %v", added, err)
+	}
+	if added, _ := s.SeedDefault(task, "other default"); added {
+		t.Error("SeedDefault seeded a task twice")
+	}
+
+	p, err := s.Create(task, "edited", "Shorter", &authorID)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if p.Version != 2 || p.Body != "edited" {
+		t.Errorf("Create: got %+v", p)
+	}
+
+	latest, err := s.Latest(task)
+	if err != nil || latest == nil || latest.Version != 2 || latest.CreatedByName == "" {
+		t.Fatalf("Latest: %+v, %v", latest, err)
+	}
+
+	v1, err := s.FindVersion(task, 1)
+	if err != nil || v1 == nil || v1.Body != "default" || v1.Note != "Built-in default" {
+		t.Fatalf("FindVersion: %+v, %v", v1, err)
+	}
+	if v, _ := s.FindVersion(task, 9); v != nil {
+		t.Errorf("FindVersion of a missing version: %+v", v)
+	}
+
+	versions, err := s.Versions(task)
+	if err != nil || len(versions) != 2 || versions[0].Version != 2 {
+		t.Fatalf("Versions: %+v, %v", versions, err)
+	}
+
+	all, err := s.LatestVersions()
+	if err != nil || all[task] != 2 {
+		t.Errorf("LatestVersions: %v, %v", all[task], err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+// translation.go stores the links between content items and their
+// translations. Translations are ordinary content rows; a link row gives
+// one its source and locale.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+// TranslationStore handles content translation links.
+type TranslationStore struct {
+	db *sql.DB
+}
+
+// NewTranslationStore creates a new TranslationStore.
+func NewTranslationStore(db *sql.DB) *TranslationStore {
+	return &TranslationStore{db: db}
+}
+
+// translationSelect selects links with their translation's title, slug
+// and status. Callers append WHERE/ORDER BY.
+const translationSelect = `
+	SELECT t.id, t.source_id, t.translation_id, t.locale, t.created_at,
+	       c.title, c.slug, c.status
+	FROM content_translations t
+	JOIN content c ON c.id = t.translation_id`
+
+// scanTranslation scans a row selected with translationSelect.
+func scanTranslation(scanner interface{ Scan(...any) error }) (*models.ContentTranslation, error) {
+	var t models.ContentTranslation
+	err := scanner.Scan(&t.ID, &t.SourceID, &t.TranslationID, &t.Locale, &t.CreatedAt,
+		&t.Title, &t.Slug, &t.Status)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Link records translationID as the translation of sourceID in locale.
+func (s *TranslationStore) Link(sourceID, translationID uuid.UUID, locale string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO content_translations (source_id, translation_id, locale)
+		VALUES ($1, $2, $3)`, sourceID, translationID, locale)
+	if err != nil {
+		return fmt.Errorf("link translation: %w", err)
+	}
+	return nil
+}
+
+// Unlink removes the link of a translation, leaving it as standalone
+// content in the default language.
+func (s *TranslationStore) Unlink(translationID uuid.UUID) error {
+	_, err := s.db.Exec(`DELETE FROM content_translations WHERE translation_id = $1`, translationID)
+	if err != nil {
+		return fmt.Errorf("unlink translation: %w", err)
+	}
+	return nil
+}
+
+// FindByTranslation returns the link of a translation, or nil if the
+// content is not a translation.
+func (s *TranslationStore) FindByTranslation(translationID uuid.UUID) (*models.ContentTranslation, error) {
+	t, err := scanTranslation(s.db.QueryRow(translationSelect+`
+		WHERE t.translation_id = $1`, translationID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find translation: %w", err)
+	}
+	return t, nil
+}
+
+// FindBySource returns the translation of a source in locale, or nil.
+func (s *TranslationStore) FindBySource(sourceID uuid.UUID, locale string) (*models.ContentTranslation, error) {
+	t, err := scanTranslation(s.db.QueryRow(translationSelect+`
+		WHERE t.source_id = $1 AND t.locale = $2`, sourceID, locale))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find translation by source: %w", err)
+	}
+	return t, nil
+}
+
+// ListBySource returns the translations of a source ordered by locale.
+func (s *TranslationStore) ListBySource(sourceID uuid.UUID) ([]models.ContentTranslation, error) {
+	rows, err := s.db.Query(translationSelect+`
+		WHERE t.source_id = $1
+		ORDER BY t.locale`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("list translations: %w", err)
+	}
+	defer rows.Close()
+
+	var list []models.ContentTranslation
+	for rows.Next() {
+		t, err := scanTranslation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan translation: %w", err)
+		}
+		list = append(list, *t)
+	}
+	return list, rows.Err()
+}
+
+// Locales returns the locale of every translation, keyed by content ID.
+// Used to badge translations in the admin content lists.
+func (s *TranslationStore) Locales() (map[uuid.UUID]string, error) {
+	rows, err := s.db.Query(`SELECT translation_id, locale FROM content_translations`)
+	if err != nil {
+		return nil, fmt.Errorf("list translation locales: %w", err)
+	}
+	defer rows.Close()
+
+	locales := map[uuid.UUID]string{}
+	for rows.Next() {
+		var id uuid.UUID
+		var locale string
+		if err := rows.Scan(&id, &locale); err != nil {
+			return nil, fmt.Errorf("scan translation locale: %w", err)
+		}
+		locales[id] = locale
+	}
+	return locales, rows.Err()
+}
+
+// ListPublished returns every published translation. Used by the cache
+// warmer, since ListPublishedByType leaves translations out.
+func (s *TranslationStore) ListPublished() ([]models.Content, error) {
+	rows, err := s.db.Query(`
+		SELECT ` + contentColumns + `
+		FROM content
+		WHERE status = 'published'
+		  AND id IN (SELECT translation_id FROM content_translations)
+		ORDER BY slug`)
+	if err != nil {
+		return nil, fmt.Errorf("list published translations: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.Content
+	for rows.Next() {
+		c, err := scanContent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan content: %w", err)
+		}
+		items = append(items, *c)
+	}
+	return items, rows.Err()
+}
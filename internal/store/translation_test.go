@@ -0,0 +1,89 @@
+// Copyright (c) 2026 Madalin Gabriel Ignisca <hi@madalin.me>
+// Copyright (c) 2026 Vlah Software House SRL <contact@vlah.sh>
+// All rights reserved. See LICENSE for details.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"yaaicms/internal/models"
+)
+
+func TestTranslationStore(t *testing.T) {
+	db := testDB(t)
+	s := NewTranslationStore(db)
+	cs := NewContentStore(db)
+	authorID := testAuthorID(t, db)
+
+	prefix := "test-tr-" + uuid.NewString()[:8]
+	srcSlug, deSlug := prefix+"-en", prefix+"-de"
+	t.Cleanup(func() { cleanContent(t, db, srcSlug, deSlug) })
+
+	create := func(slug string) *models.Content {
+		c, err := cs.Create(&models.Content{
+			Type: models.ContentTypePost, Title: slug, Slug: slug, Body: "body",
+			Status: models.ContentStatusPublished, AuthorID: authorID,
+		})
+		if err != nil {
+			t.Fatalf("Create content: %v", err)
+		}
+		return c
+	}
+	src, de := create(srcSlug), create(deSlug)
+
+	if err := s.Link(src.ID, de.ID, "de"); err != nil {
+		t.Fatalf("Link: %v", err)
+	}
+	if err := s.Link(src.ID, src.ID, "fr"); err == nil {
+		t.Error("Link accepted content as its own translation")
+	}
+
+	link, err := s.FindByTranslation(de.ID)
+	if err != nil || link == nil {
+		t.Fatalf("FindByTranslation: %v, %v", link, err)
+	}
+	if link.SourceID != src.ID || link.Locale != "de" || link.Slug != deSlug {
+		t.Errorf("FindByTranslation: got %+v", link)
+	}
+	if link, _ := s.FindByTranslation(src.ID); link != nil {
+		t.Error("FindByTranslation found a link for the source")
+	}
+	if link, err := s.FindBySource(src.ID, "de"); err != nil || link == nil || link.TranslationID != de.ID {
+		t.Errorf("FindBySource: %v, %v", link, err)
+	}
+	list, err := s.ListBySource(src.ID)
+	if err != nil || len(list) != 1 {
+		t.Errorf("ListBySource: %v, %v", list, err)
+	}
+
+	// Translations are left out of the listing of originals.
+	posts, _ := cs.ListPublishedByType(models.ContentTypePost)
+	for _, p := range posts {
+		if p.ID == de.ID {
+			t.Error("ListPublishedByType returned a translation")
+		}
+	}
+	published, _ := s.ListPublished()
+	found := false
+	for _, p := range published {
+		if p.ID == de.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("ListPublished did not return the translation")
+	}
+	if locales, _ := s.Locales(); locales[de.ID] != "de" {
+		t.Errorf("Locales: got %q", locales[de.ID])
+	}
+
+	if err := s.Unlink(de.ID); err != nil {
+		t.Fatalf("Unlink: %v", err)
+	}
+	if link, _ := s.FindByTranslation(de.ID); link != nil {
+		t.Error("link still present after Unlink")
+	}
+}